@@ -3,6 +3,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"inside-athletics/internal/audit"
+	"inside-athletics/internal/mailer"
+	"inside-athletics/internal/poststats"
 	"inside-athletics/internal/server"
 	"log"
 	"log/slog"
@@ -50,7 +53,34 @@ func main() {
 		}
 	}()
 
-	app := server.CreateApp(db)
+	// signing keys are shared across requests and refreshed in the background until shutdown
+	keysCtx, stopKeys := context.WithCancel(context.Background())
+	defer stopKeys()
+	keys, err := server.NewKeySet(keysCtx, server.LoadJWKSConfigFromEnv())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to create JWKS key set: %v\n", err)
+		os.Exit(1)
+	}
+
+	// popularity scores are recomputed in the background until shutdown
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go poststats.NewJob(db, poststats.LoadConfigFromEnv()).Run(jobsCtx)
+
+	app := server.CreateApp(db, server.NewSupabaseVerifier(keys, server.LoadVerifierConfigFromEnv()))
+	// a route that changes something without declaring who may call it would go unchecked
+	if err := server.CheckAccessDeclared(app.Api); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to start server: %v\n", err)
+		os.Exit(1)
+	}
+	// realtime messages from every instance are delivered to this one's streams until shutdown
+	go app.Realtime.Run(jobsCtx)
+	// digests are sent to users who are due one until shutdown
+	go mailer.NewDigestJob(app.Mailer).Run(jobsCtx)
+	// reply alerts queued by new comments are sent until shutdown
+	go app.Mailer.RunReplyAlerts(jobsCtx)
+	// audit log entries past their retention period are deleted until shutdown
+	go audit.NewPruneJob(db, audit.LoadConfigFromEnv()).Run(jobsCtx)
 
 	fmt.Fprintf(os.Stderr, "Access server on localhost:8080")
 	app.Server.Get("/", func(c *fiber.Ctx) error {
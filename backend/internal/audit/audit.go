@@ -0,0 +1,246 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"inside-athletics/internal/models"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// retentionSetting must be on for the append-only trigger on audit_logs to allow a delete. Only
+// Prune turns it on, inside its own transaction.
+const retentionSetting = "inside_athletics.audit_retention"
+
+// Target is the resource an audited operation changes and how to read its state.
+type Target struct {
+	// Resource is the resource type entries are recorded under. ResourceParam, when set, is the
+	// path parameter naming it instead, looked up in contentSnapshots.
+	Resource      string
+	ResourceParam string
+	// Snapshot selects the resource's state as one jsonb value, with its ID bound to ?
+	Snapshot string
+	// IDParam is the path parameter holding the resource's ID. Creates have none, so the ID is read
+	// from the "id" in the response.
+	IDParam string
+	// Action is recorded instead of the one the method implies
+	Action string
+	// AuthorField, when set, is the field of the snapshot holding the resource's author. Changes
+	// authors make to their own content aren't audited.
+	AuthorField string
+}
+
+func rowSnapshot(table string) string {
+	return "SELECT to_jsonb(t) FROM " + table + " t WHERE t.id = ?"
+}
+
+var (
+	roleSnapshot = `SELECT to_jsonb(t) || jsonb_build_object('permissions', (
+			SELECT COALESCE(jsonb_agg(p.resource || ':' || p.action ORDER BY p.resource, p.action), '[]')
+			FROM role_permissions rp JOIN permissions p ON p.id = rp.permission_id
+			WHERE rp.role_id = t.id
+		)) FROM roles t WHERE t.id = ?`
+	// scoped roles are listed as name:scope_type:scope_id
+	userRolesSnapshot = `SELECT jsonb_build_object('roles', COALESCE(jsonb_agg(
+			r.name || COALESCE(':' || ur.scope_type || ':' || ur.scope_id, '') ORDER BY r.name, ur.scope_type, ur.scope_id
+		) FILTER (WHERE r.id IS NOT NULL), '[]'))
+		FROM users u
+		LEFT JOIN user_roles ur ON ur.user_id = u.id
+		LEFT JOIN roles r ON r.id = ur.role_id
+		WHERE u.id = ?
+		GROUP BY u.id`
+	// a survey template with the questions of each of its versions
+	surveyTemplateSnapshot = `SELECT to_jsonb(t) || jsonb_build_object('versions', (
+			SELECT COALESCE(jsonb_agg(jsonb_build_object('version', v.version, 'questions', (
+				SELECT COALESCE(jsonb_agg(to_jsonb(q) - 'id' - 'version_id' ORDER BY q.position), '[]')
+				FROM survey_questions q WHERE q.version_id = v.id
+			)) ORDER BY v.version), '[]')
+			FROM survey_template_versions v WHERE v.template_id = t.id
+		)) FROM survey_templates t WHERE t.id = ?`
+)
+
+// contentSnapshots are the snapshots of each kind of content, by models.ReportableType.
+var contentSnapshots = map[string]Target{
+	string(models.ReportablePost):        {Resource: "post", Snapshot: rowSnapshot("posts"), AuthorField: "author_id"},
+	string(models.ReportablePremiumPost): {Resource: "premium_post", Snapshot: rowSnapshot("premium_posts"), AuthorField: "author_id"},
+	string(models.ReportableComment):     {Resource: "comment", Snapshot: rowSnapshot("comments"), AuthorField: "user_id"},
+}
+
+// crud returns the create, update and delete operations of a resource stored in table.
+func crud(resource, table, createPath, itemPath, updateMethod string) map[string]Target {
+	target := Target{Resource: resource, Snapshot: rowSnapshot(table)}
+	withID := target
+	withID.IDParam = "id"
+	return map[string]Target{
+		"POST " + createPath:          target,
+		updateMethod + " " + itemPath: withID,
+		"DELETE " + itemPath:          withID,
+	}
+}
+
+// Operations are the audited operations, keyed by "METHOD /path/template" as registered with huma.
+var Operations = func() map[string]Target {
+	ops := map[string]Target{
+		"POST /api/v1/role/":                       {Resource: "role", Snapshot: roleSnapshot},
+		"POST /api/v1/role/basic":                  {Resource: "role", Snapshot: roleSnapshot},
+		"PATCH /api/v1/role/{id}":                  {Resource: "role", Snapshot: roleSnapshot, IDParam: "id"},
+		"DELETE /api/v1/role/{id}":                 {Resource: "role", Snapshot: roleSnapshot, IDParam: "id"},
+		"POST /api/v1/user/{id}/roles":             {Resource: "user_roles", Snapshot: userRolesSnapshot, IDParam: "id", Action: "update"},
+		"DELETE /api/v1/user/{id}/roles/{role_id}": {Resource: "user_roles", Snapshot: userRolesSnapshot, IDParam: "id", Action: "update"},
+		"DELETE /api/v1/user/{id}":                 {Resource: "user", Snapshot: rowSnapshot("users"), IDParam: "id"},
+		"POST /api/v1/sanctions/":                  {Resource: "sanction", Snapshot: rowSnapshot("sanctions")},
+		"DELETE /api/v1/sanctions/{id}":            {Resource: "sanction", Snapshot: rowSnapshot("sanctions"), IDParam: "id", Action: "lift"},
+		"POST /api/v1/verification/{id}/review":    {Resource: "verification_request", Snapshot: rowSnapshot("verification_requests"), IDParam: "id", Action: "review"},
+		"POST /api/v1/survey/{id}/invalidate":      {Resource: "survey", Snapshot: rowSnapshot("surveys"), IDParam: "id", Action: "invalidate"},
+		"DELETE /api/v1/post/{id}":                 withID(contentSnapshots[string(models.ReportablePost)]),
+		"DELETE /api/v1/posts/premium/{id}":        withID(contentSnapshots[string(models.ReportablePremiumPost)]),
+		"DELETE /api/v1/comment/{id}":              withID(contentSnapshots[string(models.ReportableComment)]),
+		"POST /api/v1/report/queue/{content_type}/{content_id}/actions": {
+			ResourceParam: "content_type", IDParam: "content_id", Action: "moderate",
+		},
+		"POST /api/v1/survey/templates":               {Resource: "survey_template", Snapshot: surveyTemplateSnapshot},
+		"POST /api/v1/survey/templates/{id}/versions": {Resource: "survey_template", Snapshot: surveyTemplateSnapshot, IDParam: "id", Action: "publish"},
+	}
+	for _, group := range []map[string]Target{
+		crud("permission", "permissions", "/api/v1/permission/", "/api/v1/permission/{id}", "PATCH"),
+		crud("college", "colleges", "/api/v1/college", "/api/v1/college/{id}", "PUT"),
+		crud("sport", "sports", "/api/v1/sport/", "/api/v1/sport/{id}", "PATCH"),
+		crud("tag", "tags", "/api/v1/tag/", "/api/v1/tag/{id}", "PATCH"),
+	} {
+		for key, target := range group {
+			ops[key] = target
+		}
+	}
+	return ops
+}()
+
+func withID(target Target) Target {
+	target.IDParam = "id"
+	return target
+}
+
+// Resolve returns the target with its resource filled in from the path, and false when the
+// resource named in the path isn't one that can be audited.
+func (t Target) Resolve(param func(string) string) (Target, bool) {
+	if t.ResourceParam == "" {
+		return t, true
+	}
+	content, ok := contentSnapshots[param(t.ResourceParam)]
+	if !ok {
+		return t, false
+	}
+	content.IDParam, content.Action = t.IDParam, t.Action
+	// a moderator acting on content is audited even when it's their own
+	content.AuthorField = ""
+	return content, true
+}
+
+// ActionFor is the action recorded for a request with method to target.
+func ActionFor(method string, target Target) string {
+	if target.Action != "" {
+		return target.Action
+	}
+	switch method {
+	case "POST":
+		return "create"
+	case "DELETE":
+		return "delete"
+	default:
+		return "update"
+	}
+}
+
+// Change is a field's value before and after a change. Before is null for created resources and
+// After is null for deleted ones.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// ignoredFields change on every write, so they are left out of diffs.
+var ignoredFields = map[string]bool{"updated_at": true}
+
+// Diff returns the fields that differ between two snapshots, either of which may be nil.
+func Diff(before, after json.RawMessage) (map[string]Change, error) {
+	var b, a map[string]any
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &b); err != nil {
+			return nil, err
+		}
+	}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &a); err != nil {
+			return nil, err
+		}
+	}
+	changes := map[string]Change{}
+	for field, value := range b {
+		if !ignoredFields[field] && !reflect.DeepEqual(value, a[field]) {
+			changes[field] = Change{Before: value, After: a[field]}
+		}
+	}
+	for field, value := range a {
+		if _, seen := b[field]; !seen && !ignoredFields[field] && value != nil {
+			changes[field] = Change{After: value}
+		}
+	}
+	return changes, nil
+}
+
+// AuthoredBy reports whether the snapshot's field holds userID.
+func AuthoredBy(snapshot json.RawMessage, field string, userID uuid.UUID) bool {
+	var fields map[string]any
+	if field == "" || json.Unmarshal(snapshot, &fields) != nil {
+		return false
+	}
+	author, _ := fields[field].(string)
+	return author == userID.String()
+}
+
+// Store writes, reads and prunes the audit log.
+type Store struct {
+	db  *gorm.DB
+	cfg Config
+}
+
+func NewStore(db *gorm.DB, cfg Config) *Store {
+	return &Store{db: db, cfg: cfg}
+}
+
+// Snapshot returns target's state for id, or nil when it doesn't exist.
+func (s *Store) Snapshot(ctx context.Context, target Target, id uuid.UUID) (json.RawMessage, error) {
+	var snapshot []byte
+	err := s.db.WithContext(ctx).Raw(target.Snapshot, id).Row().Scan(&snapshot)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return snapshot, err
+}
+
+// Record appends entry to the audit log
+func (s *Store) Record(ctx context.Context, entry *models.AuditLog) error {
+	return s.db.WithContext(ctx).Create(entry).Error
+}
+
+// Prune deletes the entries older than the retention period and returns how many it deleted.
+func (s *Store) Prune(ctx context.Context) (int64, error) {
+	retention := s.cfg.Retention
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	var deleted int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config(?, 'on', true)", retentionSetting).Error; err != nil {
+			return err
+		}
+		res := tx.Where("created_at < ?", time.Now().Add(-retention)).Delete(&models.AuditLog{})
+		deleted = res.RowsAffected
+		return res.Error
+	})
+	return deleted, err
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Env keys for audit log config. Durations are in seconds.
+const (
+	EnvRetentionDays    = "AUDIT_RETENTION_DAYS"
+	EnvPruneIntervalSec = "AUDIT_PRUNE_INTERVAL_SECONDS"
+)
+
+const (
+	DefaultRetention     = 365 * 24 * time.Hour
+	DefaultPruneInterval = 24 * time.Hour
+)
+
+// Config controls how long audit log entries are kept.
+type Config struct {
+	// Retention is how long an entry is kept before the prune job deletes it.
+	Retention time.Duration
+	// PruneInterval is how often the prune job runs.
+	PruneInterval time.Duration
+}
+
+// DefaultConfig returns the config used when nothing is set in env.
+func DefaultConfig() Config {
+	return Config{
+		Retention:     DefaultRetention,
+		PruneInterval: DefaultPruneInterval,
+	}
+}
+
+// LoadConfigFromEnv returns the audit log config from env, falling back to DefaultConfig for
+// anything unset or invalid.
+func LoadConfigFromEnv() Config {
+	return Config{
+		Retention:     time.Duration(intFromEnv(EnvRetentionDays, int(DefaultRetention/(24*time.Hour)))) * 24 * time.Hour,
+		PruneInterval: time.Duration(intFromEnv(EnvPruneIntervalSec, int(DefaultPruneInterval/time.Second))) * time.Second,
+	}
+}
+
+func intFromEnv(key string, fallback int) int {
+	if s := os.Getenv(key); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			return n
+		}
+	}
+	return fallback
+}
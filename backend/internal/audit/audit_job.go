@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PruneJob periodically deletes audit log entries older than the retention period.
+type PruneJob struct {
+	store    *Store
+	interval time.Duration
+}
+
+func NewPruneJob(db *gorm.DB, cfg Config) *PruneJob {
+	interval := cfg.PruneInterval
+	if interval <= 0 {
+		interval = DefaultPruneInterval
+	}
+	return &PruneJob{store: NewStore(db, cfg), interval: interval}
+}
+
+// Run prunes right away and then every interval until ctx is done. Failed prunes are logged and
+// retried on the next tick.
+func (j *PruneJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := j.store.Prune(ctx); err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "Failed to prune audit log", "error", err)
+			}
+		} else if deleted > 0 {
+			slog.InfoContext(ctx, "Pruned audit log", "rows", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
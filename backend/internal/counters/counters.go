@@ -0,0 +1,26 @@
+package counters
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Add moves a counter column on the row with the given id by delta, never below 0. Call it with
+// the transaction that writes the like/comment being counted so the counter can't drift.
+func Add(tx *gorm.DB, table string, column string, id uuid.UUID, delta int) error {
+	return tx.Table(table).
+		Where("id = ?", id).
+		UpdateColumn(column, gorm.Expr("GREATEST(? + ?, 0)", clause.Column{Name: column}, delta)).
+		Error
+}
+
+// AddCommentLikes moves a comment's like_count by delta.
+func AddCommentLikes(tx *gorm.DB, commentID uuid.UUID, delta int) error {
+	return Add(tx, "comments", "like_count", commentID, delta)
+}
+
+// AddReplies moves a comment's reply_count by delta.
+func AddReplies(tx *gorm.DB, commentID uuid.UUID, delta int) error {
+	return Add(tx, "comments", "reply_count", commentID, delta)
+}
@@ -0,0 +1,138 @@
+package counters
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Drift is a row whose stored counter doesn't match the source table.
+type Drift struct {
+	Counter string    `json:"counter"`
+	ID      uuid.UUID `json:"id"`
+	Stored  int64     `json:"stored"`
+	Actual  int64     `json:"actual"`
+}
+
+// counter is one denormalized count and how to check and recompute it.
+type counter struct {
+	name string
+	// check selects id, stored and actual for every row where the two differ
+	check string
+	// fix recomputes the counter for the ids bound to ?
+	fix string
+}
+
+var allCounters = []counter{
+	{
+		name: "post_stats.like_count",
+		check: `
+			SELECT posts.id, COALESCE(ps.like_count, 0) AS stored, COALESCE(actual.total, 0) AS actual
+			FROM posts
+			LEFT JOIN post_stats AS ps ON ps.post_id = posts.id
+			LEFT JOIN (SELECT post_id, COUNT(*) AS total FROM post_likes GROUP BY post_id) AS actual ON actual.post_id = posts.id
+			WHERE COALESCE(ps.like_count, 0) <> COALESCE(actual.total, 0)`,
+		fix: `
+			INSERT INTO post_stats (post_id, like_count, updated_at)
+			SELECT posts.id, (SELECT COUNT(*) FROM post_likes WHERE post_likes.post_id = posts.id), NOW()
+			FROM posts
+			WHERE posts.id IN ?
+			ON CONFLICT (post_id) DO UPDATE SET like_count = EXCLUDED.like_count, updated_at = EXCLUDED.updated_at`,
+	},
+	{
+		name: "post_stats.comment_count",
+		check: `
+			SELECT posts.id, COALESCE(ps.comment_count, 0) AS stored, COALESCE(actual.total, 0) AS actual
+			FROM posts
+			LEFT JOIN post_stats AS ps ON ps.post_id = posts.id
+			LEFT JOIN (SELECT post_id, COUNT(*) AS total FROM comments GROUP BY post_id) AS actual ON actual.post_id = posts.id
+			WHERE COALESCE(ps.comment_count, 0) <> COALESCE(actual.total, 0)`,
+		fix: `
+			INSERT INTO post_stats (post_id, comment_count, updated_at)
+			SELECT posts.id, (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id), NOW()
+			FROM posts
+			WHERE posts.id IN ?
+			ON CONFLICT (post_id) DO UPDATE SET comment_count = EXCLUDED.comment_count, updated_at = EXCLUDED.updated_at`,
+	},
+	{
+		name: "comments.like_count",
+		check: `
+			SELECT comments.id, comments.like_count AS stored, COALESCE(actual.total, 0) AS actual
+			FROM comments
+			LEFT JOIN (SELECT comment_id, COUNT(*) AS total FROM comment_likes GROUP BY comment_id) AS actual ON actual.comment_id = comments.id
+			WHERE comments.like_count <> COALESCE(actual.total, 0)`,
+		fix: `
+			UPDATE comments
+			SET like_count = (SELECT COUNT(*) FROM comment_likes WHERE comment_likes.comment_id = comments.id)
+			WHERE comments.id IN ?`,
+	},
+	{
+		name: "comments.reply_count",
+		check: `
+			SELECT comments.id, comments.reply_count AS stored, COALESCE(actual.total, 0) AS actual
+			FROM comments
+			LEFT JOIN (
+				SELECT parent_comment_id, COUNT(*) AS total
+				FROM comments
+				WHERE parent_comment_id IS NOT NULL AND deleted_at IS NULL
+				GROUP BY parent_comment_id
+			) AS actual ON actual.parent_comment_id = comments.id
+			WHERE comments.reply_count <> COALESCE(actual.total, 0)`,
+		fix: `
+			UPDATE comments
+			SET reply_count = (
+				SELECT COUNT(*) FROM comments AS replies
+				WHERE replies.parent_comment_id = comments.id AND replies.deleted_at IS NULL
+			)
+			WHERE comments.id IN ?`,
+	},
+}
+
+// Check recalculates every counter from its source table and returns the rows that have drifted.
+// Nothing is written.
+func Check(ctx context.Context, db *gorm.DB) ([]Drift, error) {
+	return run(db.WithContext(ctx), false)
+}
+
+// Repair does the same as Check and also resets every drifted counter to its actual value, in one
+// transaction. The drift that was fixed is returned.
+func Repair(ctx context.Context, db *gorm.DB) ([]Drift, error) {
+	var drift []Drift
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		drift, err = run(tx, true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return drift, nil
+}
+
+func run(db *gorm.DB, fix bool) ([]Drift, error) {
+	drift := []Drift{}
+	for _, c := range allCounters {
+		var rows []Drift
+		if err := db.Raw(c.check).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		ids := make([]uuid.UUID, 0, len(rows))
+		for i := range rows {
+			rows[i].Counter = c.name
+			ids = append(ids, rows[i].ID)
+		}
+		drift = append(drift, rows...)
+
+		if fix {
+			if err := db.Exec(c.fix, ids).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+	return drift, nil
+}
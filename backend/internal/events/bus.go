@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+)
+
+// Event is something that happened in the app that other packages may react to. Services publish
+// events without knowing who listens; the event types are all defined in this package.
+type Event interface {
+	isEvent()
+}
+
+// Publisher is what services depend on to emit events.
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// Bus delivers each published event to the handlers subscribed to its type. A nil *Bus drops
+// every event, so services can be built without one.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[reflect.Type][]func(context.Context, Event) error
+}
+
+// NewBus creates a new Bus with no subscribers
+func NewBus() *Bus {
+	return &Bus{handlers: map[reflect.Type][]func(context.Context, Event) error{}}
+}
+
+// Subscribe registers handler for every event of type E published on bus.
+func Subscribe[E Event](bus *Bus, handler func(ctx context.Context, event E) error) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	t := reflect.TypeFor[E]()
+	bus.handlers[t] = append(bus.handlers[t], func(ctx context.Context, event Event) error {
+		return handler(ctx, event.(E))
+	})
+}
+
+// Publish runs the handlers subscribed to the event's type, in the order they subscribed. Events
+// are published after the change they describe is saved, so a failing handler is logged rather
+// than failing the request, and the remaining handlers still run.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	handlers := b.handlers[reflect.TypeOf(event)]
+	b.mu.RUnlock()
+	for _, handle := range handlers {
+		if err := handle(ctx, event); err != nil {
+			slog.ErrorContext(ctx, "event handler failed", "event", reflect.TypeOf(event).Name(), "error", err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"inside-athletics/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// CommentCreated is published after a comment or reply is saved.
+type CommentCreated struct {
+	CommentID       uuid.UUID
+	PostID          uuid.UUID
+	ParentCommentID *uuid.UUID
+	UserID          uuid.UUID
+	IsAnonymous     bool
+}
+
+// PostCreated is published after a post and its tags are saved.
+type PostCreated struct {
+	PostID      uuid.UUID
+	AuthorID    uuid.UUID
+	TagIDs      []uuid.UUID
+	IsAnonymous bool
+}
+
+// PostLiked is published after a user likes a post.
+type PostLiked struct {
+	PostID uuid.UUID
+	UserID uuid.UUID
+}
+
+// PostUnliked is published after a user removes their like from a post.
+type PostUnliked struct {
+	PostID uuid.UUID
+	UserID uuid.UUID
+}
+
+// CommentLiked is published after a user likes a comment.
+type CommentLiked struct {
+	CommentID uuid.UUID
+	UserID    uuid.UUID
+}
+
+// CommentUnliked is published after a user removes their like from a comment.
+type CommentUnliked struct {
+	CommentID uuid.UUID
+	UserID    uuid.UUID
+}
+
+// NotificationSent is published after a notification is created for a user, or grouped into one
+// of their unread notifications.
+type NotificationSent struct {
+	NotificationID uuid.UUID
+	UserID         uuid.UUID
+	Type           models.NotificationType
+}
+
+func (CommentCreated) isEvent()   {}
+func (PostCreated) isEvent()      {}
+func (PostLiked) isEvent()        {}
+func (PostUnliked) isEvent()      {}
+func (CommentLiked) isEvent()     {}
+func (CommentUnliked) isEvent()   {}
+func (NotificationSent) isEvent() {}
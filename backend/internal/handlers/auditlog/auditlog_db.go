@@ -0,0 +1,51 @@
+package auditlog
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AuditLogDB struct {
+	db *gorm.DB
+}
+
+// AuditLogKeyset pages the audit log newest first.
+var AuditLogKeyset = utils.Keyset{KeyColumn: "a.created_at", IDColumn: "a.id", Descending: true}
+
+func auditLogCursor(a *models.AuditLog) utils.Cursor[time.Time] {
+	return utils.Cursor[time.Time]{Key: a.CreatedAt, ID: a.ID}
+}
+
+// NewAuditLogDB creates a new AuditLogDB instance
+func NewAuditLogDB(db *gorm.DB) *AuditLogDB {
+	return &AuditLogDB{db: db}
+}
+
+// GetAuditLogs returns a page of the entries matching filter
+func (a *AuditLogDB) GetAuditLogs(page *utils.Page[time.Time], filter AuditLogFilter) ([]models.AuditLog, utils.PageCursors, error) {
+	query := a.db.Table("audit_logs AS a")
+	if filter.ActorID != nil {
+		query = query.Where("a.actor_id = ?", *filter.ActorID)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("a.resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != nil {
+		query = query.Where("a.resource_id = ?", *filter.ResourceID)
+	}
+	if filter.From != nil {
+		query = query.Where("a.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("a.created_at < ?", *filter.To)
+	}
+	var entries []models.AuditLog
+	if err := page.Apply(query).Find(&entries).Error; err != nil {
+		return nil, utils.PageCursors{}, err
+	}
+	entries, cursors := utils.Results(page, entries, auditLogCursor)
+	return entries, cursors, nil
+}
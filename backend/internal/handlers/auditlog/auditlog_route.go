@@ -0,0 +1,17 @@
+package auditlog
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
+	"github.com/danielgtaylor/huma/v2"
+	"gorm.io/gorm"
+)
+
+func Route(api huma.API, db *gorm.DB) {
+	auditLogService := NewAuditLogService(db)
+	{
+		grp := huma.NewGroup(api, "/api/v1/audit-logs")
+		huma.Get(grp, "/", auditLogService.GetAuditLogs, utils.Requires(utils.Permission(models.PermissionRead, "audit"))) // Search the audit log
+	}
+}
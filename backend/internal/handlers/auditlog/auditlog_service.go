@@ -0,0 +1,75 @@
+package auditlog
+
+import (
+	"context"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditLogService struct {
+	auditLogDB *AuditLogDB
+}
+
+// NewAuditLogService creates a new AuditLogService instance
+func NewAuditLogService(db *gorm.DB) *AuditLogService {
+	return &AuditLogService{auditLogDB: NewAuditLogDB(db)}
+}
+
+// GetAuditLogs searches the audit log, newest first.
+func (s *AuditLogService) GetAuditLogs(ctx context.Context, input *GetAuditLogsParams) (*utils.ResponseBody[GetAuditLogsResponse], error) {
+	var err error
+	filter := AuditLogFilter{ResourceType: input.ResourceType}
+	if filter.ActorID, err = optionalUUID(input.ActorID, "actor_id"); err != nil {
+		return nil, err
+	}
+	if filter.ResourceID, err = optionalUUID(input.ResourceID, "resource_id"); err != nil {
+		return nil, err
+	}
+	if filter.From, err = optionalTime(input.From, "from"); err != nil {
+		return nil, err
+	}
+	if filter.To, err = optionalTime(input.To, "to"); err != nil {
+		return nil, err
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, huma.Error400BadRequest("to must be after from")
+	}
+
+	page, err := utils.NewPage[time.Time](AuditLogKeyset, input.Cursor, input.Limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	entries, cursors, err := s.auditLogDB.GetAuditLogs(page, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[GetAuditLogsResponse]{
+		Body: &GetAuditLogsResponse{Entries: entries, PageCursors: cursors},
+	}, nil
+}
+
+func optionalUUID(value, name string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, huma.Error400BadRequest("Invalid " + name)
+	}
+	return &id, nil
+}
+
+func optionalTime(value, name string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, huma.Error400BadRequest("Invalid " + name + ", expected RFC 3339")
+	}
+	return &t, nil
+}
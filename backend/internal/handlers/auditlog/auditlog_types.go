@@ -0,0 +1,35 @@
+package auditlog
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GetAuditLogsParams defines the query parameters for searching the audit log
+type GetAuditLogsParams struct {
+	Limit        int    `query:"limit" default:"50" minimum:"1" maximum:"200" example:"50" doc:"Number of entries to return"`
+	Cursor       string `query:"cursor" default:"" doc:"next_cursor or prev_cursor from a previous page"`
+	ActorID      string `query:"actor_id" default:"" doc:"Only list changes made by this user"`
+	ResourceType string `query:"resource_type" default:"" example:"role" doc:"Only list changes to this type of resource"`
+	ResourceID   string `query:"resource_id" default:"" doc:"Only list changes to this resource"`
+	From         string `query:"from" default:"" example:"2026-06-01T00:00:00Z" doc:"Only list changes made at or after this time, in RFC 3339"`
+	To           string `query:"to" default:"" example:"2026-07-01T00:00:00Z" doc:"Only list changes made before this time, in RFC 3339"`
+}
+
+// AuditLogFilter narrows down the audit log. Unset fields don't filter.
+type AuditLogFilter struct {
+	ActorID      *uuid.UUID
+	ResourceType string
+	ResourceID   *uuid.UUID
+	From         *time.Time
+	To           *time.Time
+}
+
+// GetAuditLogsResponse defines the response for searching the audit log
+type GetAuditLogsResponse struct {
+	Entries []models.AuditLog `json:"entries" doc:"Newest first"`
+	utils.PageCursors
+}
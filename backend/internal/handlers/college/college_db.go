@@ -13,6 +13,13 @@ type CollegeDB struct {
 	db *gorm.DB
 }
 
+// collegeKeyset pages colleges alphabetically.
+var collegeKeyset = utils.Keyset{KeyColumn: "colleges.name", IDColumn: "colleges.id"}
+
+func collegeCursor(college *models.College) utils.Cursor[string] {
+	return utils.Cursor[string]{Key: college.Name, ID: college.ID}
+}
+
 /*
 *
 Here we are using GORM to interact with the database. This is an ORM (Object Relational Mapping)
@@ -24,15 +31,17 @@ func (c *CollegeDB) GetCollege(id uuid.UUID) (*models.College, error) {
 	return utils.HandleDBError(&college, dbResponse.Error) // helper function that maps GORM errors to Huma errors
 }
 
-func (c *CollegeDB) ListColleges(limit, offset int) (*[]models.College, error) {
+func (c *CollegeDB) ListColleges(page *utils.Page[string]) (*[]models.College, utils.PageCursors, error) {
 	var colleges []models.College
-	dbResponse := c.db.
-		Order("name ASC").
-		Limit(limit).
-		Offset(offset).
+	dbResponse := page.Apply(c.db.Model(&models.College{})).
 		Find(&colleges)
+	if dbResponse.Error != nil {
+		_, err := utils.HandleDBError(&colleges, dbResponse.Error)
+		return nil, utils.PageCursors{}, err
+	}
 
-	return utils.HandleDBError(&colleges, dbResponse.Error)
+	colleges, cursors := utils.Results(page, colleges, collegeCursor)
+	return &colleges, cursors, nil
 }
 
 // Creates a new college in the database
@@ -73,6 +82,8 @@ func (c *CollegeDB) DeleteCollege(id uuid.UUID) error {
 	return dbResponse.Error
 }
 
-func (c *CollegeDB) FuzzySearchFor(searchStr string, limit int) ([]models.College, error) {
-	return utils.FuzzySearchForDB(searchStr, limit, c.db, "name", models.College{})
+// Colleges are matched on name first, with city and state as weaker fallbacks.
+func (c *CollegeDB) FuzzySearchFor(input *utils.SearchParam) ([]models.College, int64, error) {
+	search := input.Search(utils.Column("name"), utils.WeightedColumn("city", 0.8), utils.WeightedColumn("state", 0.8))
+	return utils.FuzzySearchForDB[models.College](c.db, search)
 }
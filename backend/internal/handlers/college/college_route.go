@@ -1,7 +1,9 @@
 package college
 
 import (
+	"inside-athletics/internal/models"
 	"inside-athletics/internal/s3"
+	"inside-athletics/internal/utils"
 
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
@@ -22,9 +24,9 @@ func Route(api huma.API, db *gorm.DB, s3Svc *s3.Service) {
 		grp := huma.NewGroup(api, "/api/v1/college")
 		huma.Get(grp, "/", collegeService.ListColleges)
 		huma.Get(grp, "/{id}", collegeService.GetCollege)
-		huma.Post(grp, "", collegeService.CreateCollege)
-		huma.Put(grp, "/{id}", collegeService.UpdateCollege)
-		huma.Delete(grp, "/{id}", collegeService.DeleteCollege)
+		huma.Post(grp, "", collegeService.CreateCollege, utils.Requires(utils.Permission(models.PermissionCreate, "college")))
+		huma.Put(grp, "/{id}", collegeService.UpdateCollege, utils.Requires(utils.Permission(models.PermissionUpdate, "college").InScopes(utils.IsScope(models.RoleScopeCollege))))
+		huma.Delete(grp, "/{id}", collegeService.DeleteCollege, utils.Requires(utils.Permission(models.PermissionDelete, "college").InScopes(utils.IsScope(models.RoleScopeCollege))))
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/colleges")
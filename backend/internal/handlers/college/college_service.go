@@ -50,9 +50,12 @@ func (u *CollegeService) GetCollege(ctx context.Context, input *GetCollegeParams
 }
 
 func (u *CollegeService) ListColleges(ctx context.Context, input *ListCollegesParams) (*utils.ResponseBody[ListCollegesResponse], error) {
-	colleges, err := u.collegeDB.ListColleges(input.Limit, input.Offset)
-
 	respBody := &utils.ResponseBody[ListCollegesResponse]{}
+	page, err := utils.NewPage[string](collegeKeyset, input.Cursor, input.Limit, input.Offset)
+	if err != nil {
+		return respBody, err
+	}
+	colleges, cursors, err := u.collegeDB.ListColleges(page)
 	if err != nil {
 		return respBody, err
 	}
@@ -73,8 +76,9 @@ func (u *CollegeService) ListColleges(ctx context.Context, input *ListCollegesPa
 
 	return &utils.ResponseBody[ListCollegesResponse]{
 		Body: &ListCollegesResponse{
-			Colleges: responseColleges,
-			Total:    len(responseColleges),
+			Colleges:    responseColleges,
+			Total:       len(responseColleges),
+			PageCursors: cursors,
 		},
 	}, nil
 }
@@ -176,5 +180,5 @@ func (u *CollegeService) FuzzySearchForCollege(ctx context.Context, input *utils
 			Logo:         StringPtrOrNil(s3.ResolveKey(ctx, u.s3, college.Logo)),
 		}
 	}
-	return utils.FuzzySearchService(input, models.College{}, GetCollegeResponse{}, "name", u.collegeDB.db, toResponse)
+	return utils.FuzzySearchService(input, u.collegeDB.FuzzySearchFor, toResponse)
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/google/uuid"
 
 	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
 )
 
 type GetCollegeParams struct {
@@ -11,8 +12,9 @@ type GetCollegeParams struct {
 }
 
 type ListCollegesParams struct {
-	Limit  int `query:"limit" default:"200" example:"200" doc:"Maximum number of colleges to return"`
-	Offset int `query:"offset" default:"0" example:"0" doc:"Number of colleges to skip"`
+	Limit  int    `query:"limit" default:"200" example:"200" doc:"Maximum number of colleges to return"`
+	Cursor string `query:"cursor" default:"" example:"eyJrIjoiTm9ydGhlYXN0ZXJuIFVuaXZlcnNpdHkiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page. Takes precedence over offset"`
+	Offset int    `query:"offset" default:"0" example:"0" deprecated:"true" doc:"Number of colleges to skip. Deprecated: use cursor"`
 }
 
 type GetCollegeResponse struct {
@@ -29,6 +31,7 @@ type GetCollegeResponse struct {
 type ListCollegesResponse struct {
 	Colleges []GetCollegeResponse `json:"colleges" doc:"List of colleges"`
 	Total    int                  `json:"total" doc:"Total number of colleges returned"`
+	utils.PageCursors
 }
 
 type CreateCollegeRequest struct {
@@ -3,6 +3,7 @@ package collegefollow
 import (
 	"inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
@@ -13,12 +14,27 @@ type CollegeFollowDB struct {
 	db *gorm.DB
 }
 
-func (u *CollegeFollowDB) GetCollegeFollowsByUser(user_id uuid.UUID) (*[]uuid.UUID, error) {
-	var collegeIDs []uuid.UUID
-	dbResponse := u.db.Model(&models.CollegeFollow{}).
-		Where("user_id = ?", user_id).
-		Pluck("college_id", &collegeIDs)
-	return utils.HandleDBError(&collegeIDs, dbResponse.Error)
+// collegeFollowKeyset pages a user's followed colleges, most recently followed first.
+var collegeFollowKeyset = utils.Keyset{KeyColumn: "college_follows.created_at", IDColumn: "college_follows.id", Descending: true}
+
+func collegeFollowCursor(follow *models.CollegeFollow) utils.Cursor[time.Time] {
+	return utils.Cursor[time.Time]{Key: follow.CreatedAt, ID: follow.ID}
+}
+
+func (u *CollegeFollowDB) GetCollegeFollowsByUser(user_id uuid.UUID, page *utils.Page[time.Time]) (*[]uuid.UUID, utils.PageCursors, error) {
+	var follows []models.CollegeFollow
+	dbResponse := page.Apply(u.db.Model(&models.CollegeFollow{}).
+		Select("id", "college_id", "created_at").
+		Where("user_id = ?", user_id)).
+		Find(&follows)
+	if dbResponse.Error != nil {
+		_, err := utils.HandleDBError(&follows, dbResponse.Error)
+		return nil, utils.PageCursors{}, err
+	}
+
+	follows, cursors := utils.Results(page, follows, collegeFollowCursor)
+	collegeIDs := utils.MapList(follows, func(f models.CollegeFollow) uuid.UUID { return f.CollegeID })
+	return &collegeIDs, cursors, nil
 }
 
 func (u *CollegeFollowDB) GetFollowingUsersByCollege(college_id uuid.UUID) (*[]uuid.UUID, error) {
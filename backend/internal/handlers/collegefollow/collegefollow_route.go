@@ -1,6 +1,9 @@
 package collegefollow
 
 import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
@@ -11,9 +14,9 @@ func Route(api huma.API, db *gorm.DB) {
 	var collegeFollowService = &CollegeFollowService{collegefollowDB: collegeFollowDB}
 	{
 		grp := huma.NewGroup(api, "/api/v1/user/college")
-		huma.Post(grp, "/", collegeFollowService.CreateCollegeFollow)
+		huma.Post(grp, "/", collegeFollowService.CreateCollegeFollow, utils.Requires(utils.Permission(models.PermissionCreate, "collegefollow")))
 		huma.Get(grp, "/follows", collegeFollowService.GetCollegeFollowsByUser)
 		huma.Get(grp, "/{college_id}/users", collegeFollowService.GetFollowingUsersByCollege)
-		huma.Delete(grp, "/{id}", collegeFollowService.DeleteCollegeFollow)
+		huma.Delete(grp, "/{id}", collegeFollowService.DeleteCollegeFollow, utils.Requires(utils.Permission(models.PermissionDeleteOwn, "collegefollow")))
 	}
 }
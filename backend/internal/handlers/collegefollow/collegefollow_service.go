@@ -4,42 +4,25 @@ import (
 	"context"
 	"inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
-
-	"github.com/danielgtaylor/huma/v2"
-	"github.com/google/uuid"
+	"time"
 )
 
 type CollegeFollowService struct {
 	collegefollowDB *CollegeFollowDB
 }
 
-func (u *CollegeFollowService) getCurrentUserID(ctx context.Context) (uuid.UUID, error) {
-	rawID := ctx.Value("user_id")
-	if rawID == nil {
-		return uuid.Nil, huma.Error401Unauthorized("User not authenticated")
-	}
-
-	userID, ok := rawID.(string)
-	if !ok {
-		return uuid.Nil, huma.Error500InternalServerError("Invalid user ID in context")
-	}
-
-	parsedID, err := uuid.Parse(userID)
-	if err != nil {
-		return uuid.Nil, huma.Error400BadRequest("Invalid user ID", err)
-	}
-
-	return parsedID, nil
-}
-
 // Given a UserID, get all colleges that they follow
 func (u *CollegeFollowService) GetCollegeFollowsByUser(ctx context.Context, input *GetCollegeFollowsByUserParams) (*utils.ResponseBody[GetCollegeFollowsByUserResponse], error) {
-	userID, err := u.getCurrentUserID(ctx)
+	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	colleges, err := u.collegefollowDB.GetCollegeFollowsByUser(userID)
+	page, err := utils.NewPage[time.Time](collegeFollowKeyset, input.Cursor, input.Limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	colleges, cursors, err := u.collegefollowDB.GetCollegeFollowsByUser(userID, page)
 	if err != nil {
 		return nil, err
 	}
@@ -51,8 +34,9 @@ func (u *CollegeFollowService) GetCollegeFollowsByUser(ctx context.Context, inpu
 	}
 
 	response := &GetCollegeFollowsByUserResponse{
-		UserID:     userID,
-		CollegeIDs: *colleges,
+		UserID:      userID,
+		CollegeIDs:  *colleges,
+		PageCursors: cursors,
 	}
 
 	return &utils.ResponseBody[GetCollegeFollowsByUserResponse]{
@@ -82,7 +66,7 @@ func (u *CollegeFollowService) GetFollowingUsersByCollege(ctx context.Context, i
 
 // Given a college and a user, creates a college follow if doesn't already exist
 func (u *CollegeFollowService) CreateCollegeFollow(ctx context.Context, input *CreateCollegeFollowInput) (*utils.ResponseBody[CreateCollegeFollowResponse], error) {
-	userID, err := u.getCurrentUserID(ctx)
+	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +98,7 @@ func (u *CollegeFollowService) CreateCollegeFollow(ctx context.Context, input *C
 func (u *CollegeFollowService) DeleteCollegeFollow(ctx context.Context, input *DeleteCollegeFollowParams) (*utils.ResponseBody[DeleteCollegeFollowResponse], error) {
 	respBody := &utils.ResponseBody[DeleteCollegeFollowResponse]{}
 
-	userID, err := u.getCurrentUserID(ctx)
+	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -1,9 +1,15 @@
 package collegefollow
 
-import "github.com/google/uuid"
+import (
+	"inside-athletics/internal/utils"
+
+	"github.com/google/uuid"
+)
 
 // Given UserID, get all colleges that are followed
 type GetCollegeFollowsByUserParams struct {
+	Limit  int    `query:"limit" default:"100" example:"100" doc:"Number of followed colleges to return"`
+	Cursor string `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page"`
 }
 
 // Given college, get list of UserIDs that follow this college
@@ -14,6 +20,7 @@ type GetFollowingUsersByCollegeParams struct {
 type GetCollegeFollowsByUserResponse struct {
 	UserID    uuid.UUID   `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the user"`
 	CollegeIDs []uuid.UUID `json:"college_ids" example:"[\"123e4567-e89b-12d3-a456-426614174000\",\"123e4567-e89b-12d3-a456-426614174001\"]" doc:"The colleges the given user follows"`
+	utils.PageCursors
 }
 
 type GetFollowingUsersByCollegeResponse struct {
@@ -1,21 +1,38 @@
 package comment
 
 import (
+	"inside-athletics/internal/counters"
 	models "inside-athletics/internal/models"
+	"inside-athletics/internal/poststats"
 	"inside-athletics/internal/utils"
+	"slices"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// COMMENT_SELECT_QUERY selects comments with whether the user bound to ? has liked them. Like and
+// reply counts are columns on comments.
+const COMMENT_SELECT_QUERY = `comments.*,
+            EXISTS (SELECT 1 FROM comment_likes WHERE comment_likes.comment_id = comments.id AND comment_likes.user_id = ?) AS is_liked,
+            comments.reply_count > 0 AS has_replies`
+
 type CommentDB struct {
-	db *gorm.DB
+	db    *gorm.DB
+	stats *poststats.Store
 }
 
 // Creates a new CommentDB instance
 func NewCommentDB(db *gorm.DB) *CommentDB {
-	return &CommentDB{db: db}
+	return &CommentDB{db: db, stats: poststats.NewStore(db, poststats.LoadConfigFromEnv())}
+}
+
+// postTeam loads only the college and sport of a comment's post, to compare its commenter's with
+func postTeam(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "college_id", "sport_id")
 }
 
 // Retrieves a comment by its ID
@@ -23,21 +40,29 @@ func (c *CommentDB) GetCommentByID(id uuid.UUID, userID uuid.UUID) (*models.Comm
 	var comment models.Comment
 	dbResponse := c.db.
 		Model(&models.Comment{}).
-		Select(`comments.*,
-            (SELECT COUNT(*) FROM comment_likes WHERE comment_likes.comment_id = comments.id) AS like_count,
-            (SELECT COUNT(*) > 0 FROM comment_likes WHERE comment_likes.comment_id = comments.id AND comment_likes.user_id = ?) AS is_liked,
-            (SELECT COUNT(*) > 0 FROM comments AS replies WHERE replies.parent_comment_id = comments.id AND replies.deleted_at IS NULL) AS has_replies`,
-			userID).
+		Select(COMMENT_SELECT_QUERY, userID).
 		Preload("User").
+		Preload("Post", postTeam).
+		Scopes(utils.VisibleTo(utils.CommentsTable, userID)).
 		Where("id = ?", id).
 		First(&comment)
 	return utils.HandleDBError(&comment, dbResponse.Error)
 }
 
-// Creates a new comment in the database
+// Creates a new comment in the database and counts it in the post's stats and its parent's replies
 func (c *CommentDB) CreateComment(comment *models.Comment) (*models.Comment, error) {
-	dbResponse := c.db.Create(comment)
-	return utils.HandleDBError(comment, dbResponse.Error)
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		if comment.ParentCommentID != nil {
+			if err := counters.AddReplies(tx, *comment.ParentCommentID, 1); err != nil {
+				return err
+			}
+		}
+		return c.stats.Record(tx, comment.PostID, poststats.Comment, comment.CreatedAt, 1)
+	})
+	return utils.HandleDBError(comment, err)
 }
 
 // Retrieves top-level comments for a post
@@ -45,12 +70,10 @@ func (c *CommentDB) GetCommentsByPost(postID uuid.UUID, userID uuid.UUID) ([]mod
 	var comments []models.Comment
 	res := c.db.
 		Model(&models.Comment{}).
-		Select(`comments.*,
-            (SELECT COUNT(*) FROM comment_likes WHERE comment_likes.comment_id = comments.id) AS like_count,
-            (SELECT COUNT(*) > 0 FROM comment_likes WHERE comment_likes.comment_id = comments.id AND comment_likes.user_id = ?) AS is_liked,
-            (SELECT COUNT(*) > 0 FROM comments AS replies WHERE replies.parent_comment_id = comments.id AND replies.deleted_at IS NULL) AS has_replies`,
-			userID).
+		Select(COMMENT_SELECT_QUERY, userID).
 		Preload("User").
+		Preload("Post", postTeam).
+		Scopes(utils.VisibleTo(utils.CommentsTable, userID)).
 		Where("post_id = ? AND parent_comment_id IS NULL", postID).
 		Order("created_at ASC").
 		Find(&comments)
@@ -64,11 +87,10 @@ func (c *CommentDB) GetCommentsByPost(postID uuid.UUID, userID uuid.UUID) ([]mod
 func (c *CommentDB) GetReplies(commentID uuid.UUID, userID uuid.UUID) ([]models.Comment, error) {
 	var comments []models.Comment
 	res := c.db.
-		Select(`comments.*,
-            (SELECT COUNT(*) FROM comment_likes WHERE comment_likes.comment_id = comments.id) AS like_count,
-            (SELECT COUNT(*) > 0 FROM comment_likes WHERE comment_likes.comment_id = comments.id AND comment_likes.user_id = ?) AS is_liked`,
-			userID).
+		Select(COMMENT_SELECT_QUERY, userID).
 		Preload("User").
+		Preload("Post", postTeam).
+		Scopes(utils.VisibleTo(utils.CommentsTable, userID)).
 		Where("parent_comment_id = ?", commentID).
 		Order("created_at ASC").
 		Find(&comments)
@@ -82,10 +104,7 @@ func (c *CommentDB) GetReplies(commentID uuid.UUID, userID uuid.UUID) ([]models.
 func (c *CommentDB) UpdateComment(id uuid.UUID, updates UpdateCommentBody, userID uuid.UUID) (*models.Comment, error) {
 	dbResponse := c.db.Model(&models.Comment{}).
 		Model(&models.Comment{}).
-		Select(`comments.*,
-            (SELECT COUNT(*) FROM comment_likes WHERE comment_likes.comment_id = comments.id) AS like_count,
-            (SELECT COUNT(*) > 0 FROM comment_likes WHERE comment_likes.comment_id = comments.id AND comment_likes.user_id = ?) AS is_liked`,
-			userID).
+		Select(COMMENT_SELECT_QUERY, userID).
 		Preload("User").
 		Where("id = ?", id).
 		Updates(updates)
@@ -101,28 +120,52 @@ func (c *CommentDB) UpdateComment(id uuid.UUID, updates UpdateCommentBody, userI
 
 // Soft deletes a comment by ID
 func (c *CommentDB) DeleteComment(id uuid.UUID) error {
-	dbResponse := c.db.Delete(&models.Comment{}, "id = ?", id)
-	if dbResponse.Error != nil {
-		_, err := utils.HandleDBError((*models.Comment)(nil), dbResponse.Error)
+	var deleted models.Comment
+	var rowsAffected int64
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		dbResponse := tx.Clauses(clause.Returning{}).Delete(&deleted, "id = ?", id)
+		if dbResponse.Error != nil {
+			return dbResponse.Error
+		}
+		rowsAffected = dbResponse.RowsAffected
+		if rowsAffected == 0 {
+			return nil
+		}
+		if deleted.ParentCommentID != nil && deleted.DeletedAt == nil {
+			if err := counters.AddReplies(tx, *deleted.ParentCommentID, -1); err != nil {
+				return err
+			}
+		}
+		return c.stats.Record(tx, deleted.PostID, poststats.Comment, deleted.CreatedAt, -1)
+	})
+	if err != nil {
+		_, err := utils.HandleDBError((*models.Comment)(nil), err)
 		return err
 	}
-	if dbResponse.RowsAffected == 0 {
+	if rowsAffected == 0 {
 		return huma.Error404NotFound("Resource not found")
 	}
 	return nil
 }
 
-// IsUserPremium returns true when the user does not have the free "user" role.
-func (c *CommentDB) IsUserPremium(userID uuid.UUID) (bool, error) {
-	var count int64
-	err := c.db.Table("user_roles").
-		Joins("JOIN roles ON roles.id = user_roles.role_id").
-		Where("user_roles.user_id = ? AND roles.name = ?", userID, models.RoleUser).
-		Count(&count).Error
-	if err != nil {
-		return false, err
+// IsBlockedFromReplying returns true when the post's author or the parent comment's author
+// blocked the user.
+func (c *CommentDB) IsBlockedFromReplying(userID, postID uuid.UUID, parentCommentID *uuid.UUID) (bool, error) {
+	blocked, err := utils.BlockedByAuthor(c.db, utils.PostsTable, postID, userID)
+	if err != nil || blocked || parentCommentID == nil {
+		return blocked, err
 	}
-	return count == 0, nil
+	return utils.BlockedByAuthor(c.db, utils.CommentsTable, *parentCommentID, userID)
+}
+
+// CheckMentions returns a 403 when a user mentioned in description blocked the user.
+func (c *CommentDB) CheckMentions(userID uuid.UUID, description string) error {
+	return utils.CheckMentions(c.db, userID, description)
+}
+
+// IsUserPremium returns true when the user can read premium content.
+func (c *CommentDB) IsUserPremium(userID uuid.UUID) (bool, error) {
+	return utils.HasRole(c.db, userID, models.PremiumRoles...)
 }
 
 // IsPostWithinFirstViewedPosts returns true when the post is in the user's first N viewed posts.
@@ -142,3 +185,171 @@ func (c *CommentDB) IsPostWithinFirstViewedPosts(userID, postID uuid.UUID, maxPo
 	}
 	return count > 0, nil
 }
+
+// treeCursor is where to pick up a level of a comment tree: the replies to Parent (or the post's
+// top-level comments when Parent is nil) that come after After in Sort order. A branch that was
+// collapsed at the depth limit starts with no After.
+type treeCursor struct {
+	Sort   CommentSort      `json:"s"`
+	Parent *uuid.UUID       `json:"p,omitempty"`
+	After  *commentPosition `json:"a,omitempty"`
+}
+
+// commentPosition is a comment's place in every sort order.
+type commentPosition struct {
+	CreatedAt time.Time `json:"c"`
+	Likes     int64     `json:"l"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func positionOf(comment *models.Comment) *commentPosition {
+	return &commentPosition{CreatedAt: comment.CreatedAt, Likes: comment.LikeCount, ID: comment.ID}
+}
+
+// commentOrders is the ORDER BY for each sort, and the condition for a comment coming after the
+// position bound to @after_created_at, @after_likes and @after_id.
+var commentOrders = map[CommentSort]struct{ orderBy, after string }{
+	CommentSortOldest: {
+		orderBy: "comments.created_at ASC, comments.id ASC",
+		after:   "(comments.created_at, comments.id) > (@after_created_at, @after_id)",
+	},
+	CommentSortNewest: {
+		orderBy: "comments.created_at DESC, comments.id DESC",
+		after:   "(comments.created_at, comments.id) < (@after_created_at, @after_id)",
+	},
+	CommentSortTop: {
+		orderBy: "comments.like_count DESC, comments.created_at DESC, comments.id DESC",
+		after:   "(comments.like_count, comments.created_at, comments.id) < (@after_likes, @after_created_at, @after_id)",
+	},
+}
+
+// treeRow is a comment's place in the tree walked by GetCommentTree. Position is its place among
+// its siblings, starting at 1.
+type treeRow struct {
+	ID              uuid.UUID
+	ParentCommentID *uuid.UUID
+	Depth           int
+	Position        int
+}
+
+// commentNode is a comment in a tree with the replies that were loaded, and where to continue
+// them when some weren't.
+type commentNode struct {
+	Comment models.Comment
+	Replies []commentNode
+	More    *treeCursor
+}
+
+// GetCommentTree returns a page of one level of the post's comment tree (see treeCursor), with up to
+// depth-1 levels of replies under it and at most repliesLimit replies per comment, walked with one
+// recursive query. Branches cut short by a limit or by depth come with the cursor to continue them,
+// and the level itself with the cursor for its next page.
+func (c *CommentDB) GetCommentTree(postID uuid.UUID, cursor treeCursor, depth int, limit int, repliesLimit int, userID uuid.UUID) ([]commentNode, *treeCursor, error) {
+	order, ok := commentOrders[cursor.Sort]
+	if !ok {
+		return nil, nil, huma.Error400BadRequest("Invalid sort")
+	}
+	args := map[string]any{"post": postID, "depth": depth, "limit": limit, "replies_limit": repliesLimit, "visible": utils.CommentsTable.Visible(userID)}
+	level := "comments.parent_comment_id IS NULL"
+	if cursor.Parent != nil {
+		level = "comments.parent_comment_id = @parent"
+		args["parent"] = *cursor.Parent
+	}
+	if cursor.After != nil {
+		level += " AND " + order.after
+		args["after_created_at"] = cursor.After.CreatedAt
+		args["after_likes"] = cursor.After.Likes
+		args["after_id"] = cursor.After.ID
+	}
+
+	// Every level fetches one extra row to tell if it has more. That row is returned (so its
+	// position shows the level was cut short) but its replies aren't walked.
+	var rows []treeRow
+	if err := c.db.Raw(`
+		WITH RECURSIVE tree AS (
+			(
+				SELECT comments.id, comments.parent_comment_id, 1 AS depth,
+					ROW_NUMBER() OVER (ORDER BY `+order.orderBy+`) AS position
+				FROM comments
+				WHERE comments.post_id = @post AND comments.deleted_at IS NULL AND comments.hidden_at IS NULL AND @visible AND `+level+`
+				ORDER BY `+order.orderBy+`
+				LIMIT @limit + 1
+			)
+			UNION ALL
+			SELECT replies.id, replies.parent_comment_id, tree.depth + 1, replies.position
+			FROM tree
+			CROSS JOIN LATERAL (
+				SELECT comments.id, comments.parent_comment_id,
+					ROW_NUMBER() OVER (ORDER BY `+order.orderBy+`) AS position
+				FROM comments
+				WHERE comments.parent_comment_id = tree.id AND comments.deleted_at IS NULL AND comments.hidden_at IS NULL AND @visible
+				ORDER BY `+order.orderBy+`
+				LIMIT @replies_limit + 1
+			) AS replies
+			WHERE tree.depth < @depth
+				AND tree.position <= CASE WHEN tree.depth = 1 THEN @limit ELSE @replies_limit END
+		)
+		SELECT id, parent_comment_id, depth, position FROM tree`, args).Scan(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(rows) == 0 {
+		return []commentNode{}, nil, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	var comments []models.Comment
+	if err := c.db.
+		Model(&models.Comment{}).
+		Select(COMMENT_SELECT_QUERY, userID).
+		Preload("User").
+		Preload("Post", postTeam).
+		Where("id IN ?", ids).
+		Find(&comments).Error; err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[uuid.UUID]*models.Comment, len(comments))
+	for i := range comments {
+		byID[comments[i].ID] = &comments[i]
+	}
+
+	var top []treeRow
+	children := map[uuid.UUID][]treeRow{}
+	for _, row := range rows {
+		if row.Depth == 1 {
+			top = append(top, row)
+		} else {
+			children[*row.ParentCommentID] = append(children[*row.ParentCommentID], row)
+		}
+	}
+
+	var build func(level []treeRow, parent *uuid.UUID, pageSize int, d int) ([]commentNode, *treeCursor)
+	build = func(level []treeRow, parent *uuid.UUID, pageSize int, d int) ([]commentNode, *treeCursor) {
+		slices.SortFunc(level, func(a, b treeRow) int { return a.Position - b.Position })
+		nodes := make([]commentNode, 0, min(len(level), pageSize))
+		for _, row := range level[:min(len(level), pageSize)] {
+			comment, ok := byID[row.ID]
+			if !ok {
+				continue
+			}
+			node := commentNode{Comment: *comment}
+			if d < depth {
+				id := comment.ID
+				node.Replies, node.More = build(children[id], &id, repliesLimit, d+1)
+			} else if comment.ReplyCount > 0 {
+				node.More = &treeCursor{Sort: cursor.Sort, Parent: &comment.ID}
+			}
+			nodes = append(nodes, node)
+		}
+
+		var more *treeCursor
+		if len(level) > pageSize && len(nodes) > 0 {
+			more = &treeCursor{Sort: cursor.Sort, Parent: parent, After: positionOf(&nodes[len(nodes)-1].Comment)}
+		}
+		return nodes, more
+	}
+	nodes, next := build(top, cursor.Parent, limit, 1)
+	return nodes, next, nil
+}
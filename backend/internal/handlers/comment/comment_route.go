@@ -1,25 +1,39 @@
 package comment
 
 import (
+	"inside-athletics/internal/events"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
 
-func Route(api huma.API, db *gorm.DB) {
+func Route(api huma.API, db *gorm.DB, bus *events.Bus) {
 	commentDB := NewCommentDB(db)
 	commentService := &CommentService{
 		commentDB: commentDB,
+		events:    bus,
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/comment")
-		huma.Post(grp, "/", commentService.CreateComment)         // Create comment
-		huma.Get(grp, "/{id}", commentService.GetComment)         // Get comment by ID
-		huma.Get(grp, "/{id}/replies", commentService.GetReplies) // Get replies to comment
-		huma.Patch(grp, "/{id}", commentService.UpdateComment)    // Update comment
-		huma.Delete(grp, "/{id}", commentService.DeleteComment)   // Delete comment
+		huma.Post(grp, "/", commentService.CreateComment, utils.Requires(utils.Permission(models.PermissionCreate, "comment"))) // Create comment
+		huma.Get(grp, "/{id}", commentService.GetComment)                                                                       // Get comment by ID
+		huma.Get(grp, "/{id}/replies", commentService.GetReplies)                                                               // Get replies to comment
+		huma.Patch(grp, "/{id}", commentService.UpdateComment, utils.Requires(commentAccess(models.PermissionUpdate)))          // Update comment
+		huma.Delete(grp, "/{id}", commentService.DeleteComment, utils.Requires(commentAccess(models.PermissionDelete)))         // Delete comment
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/post")
-		huma.Get(grp, "/{post_id}/comments", commentService.GetCommentsByPost) // List comments by post
+		huma.Get(grp, "/{post_id}/comments", commentService.GetCommentsByPost)   // List comments by post
+		huma.Get(grp, "/{post_id}/comments/tree", commentService.GetCommentTree) // Comment tree by post, paged at every level
 	}
 }
+
+// commentAccess is what changing a comment requires: authors need the _own action, and moderators
+// scoped to the post's sport or college may act on it too.
+func commentAccess(action models.PermissionAction) utils.Access {
+	return utils.Permission(action, "comment").
+		OwnedBy(utils.AuthorOf(utils.CommentsTable)).
+		InScopes(utils.ScopesOfContent(utils.CommentsTable))
+}
@@ -2,6 +2,7 @@ package comment
 
 import (
 	"context"
+	"inside-athletics/internal/events"
 	models "inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
 
@@ -11,6 +12,7 @@ import (
 
 type CommentService struct {
 	commentDB *CommentDB
+	events    events.Publisher
 }
 
 const (
@@ -40,6 +42,18 @@ func (s *CommentService) enforceCommentVisibility(userID, postID uuid.UUID) erro
 	return nil
 }
 
+// enforceBlocks stops users replying to or mentioning someone who blocked them.
+func (s *CommentService) enforceBlocks(userID, postID uuid.UUID, parentCommentID *uuid.UUID, description string) error {
+	blocked, err := s.commentDB.IsBlockedFromReplying(userID, postID, parentCommentID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return huma.Error403Forbidden("You can't reply to a user who blocked you")
+	}
+	return s.commentDB.CheckMentions(userID, description)
+}
+
 // Creates a new comment.
 func (s *CommentService) CreateComment(ctx context.Context, input *CreateCommentInput) (*utils.ResponseBody[CreateCommentResponse], error) {
 	userID, err := utils.GetCurrentUserID(ctx)
@@ -56,6 +70,9 @@ func (s *CommentService) CreateComment(ctx context.Context, input *CreateComment
 			return nil, huma.Error400BadRequest("Replies only allowed to top-level comments; one layer of replies")
 		}
 	}
+	if err := s.enforceBlocks(userID, input.Body.PostID, input.Body.ParentCommentID, input.Body.Description); err != nil {
+		return nil, err
+	}
 
 	// Create the comment model
 	comment := &models.Comment{
@@ -71,6 +88,13 @@ func (s *CommentService) CreateComment(ctx context.Context, input *CreateComment
 	if err != nil {
 		return nil, err
 	}
+	s.events.Publish(ctx, events.CommentCreated{
+		CommentID:       created.ID,
+		PostID:          created.PostID,
+		ParentCommentID: created.ParentCommentID,
+		UserID:          userID,
+		IsAnonymous:     created.IsAnonymous,
+	})
 
 	// Convert the comment to a response
 	return &utils.ResponseBody[CreateCommentResponse]{
@@ -156,12 +180,49 @@ func (s *CommentService) GetReplies(ctx context.Context, input *GetReplyParams)
 	return &utils.ResponseBody[[]CommentResponse]{Body: &responses}, nil
 }
 
+// Retrieves a post's comments as a tree, or a further page of one of its levels given a cursor.
+func (s *CommentService) GetCommentTree(ctx context.Context, input *GetCommentTreeParams) (*utils.ResponseBody[GetCommentTreeResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.enforceCommentVisibility(userID, input.PostID); err != nil {
+		return nil, err
+	}
+
+	// a cursor picks up the level (and sort) it was made for
+	cursor := treeCursor{Sort: input.Sort}
+	decoded, err := utils.DecodeToken[treeCursor](input.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if decoded != nil {
+		cursor = *decoded
+	}
+
+	nodes, next, err := s.commentDB.GetCommentTree(input.PostID, cursor, input.Depth, input.Limit, input.RepliesLimit, userID)
+	if err != nil {
+		_, humaErr := utils.HandleDBError[GetCommentTreeResponse](nil, err)
+		return nil, humaErr
+	}
+
+	return &utils.ResponseBody[GetCommentTreeResponse]{
+		Body: &GetCommentTreeResponse{
+			Comments:   toCommentTree(nodes, userID),
+			NextCursor: encodeTreeCursor(next),
+		},
+	}, nil
+}
+
 // Updates a comment's description by ID.
 func (s *CommentService) UpdateComment(ctx context.Context, input *UpdateCommentInput) (*utils.ResponseBody[CommentResponse], error) {
 	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.commentDB.CheckMentions(userID, input.Body.Description); err != nil {
+		return nil, err
+	}
 	// Update the comment in the database
 	updated, err := s.commentDB.UpdateComment(input.ID, input.Body, userID)
 	if err != nil {
@@ -2,6 +2,7 @@ package comment
 
 import (
 	models "inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
 
 	"github.com/google/uuid"
 )
@@ -16,6 +17,38 @@ type GetCommentsByPostParams struct {
 	PostID uuid.UUID `path:"post_id" example:"550e8400-e29b-41d4-a716-446655440000" doc:"ID of the post"`
 }
 
+// CommentSort is the order comments are listed in at every level of a comment tree
+type CommentSort string
+
+const (
+	CommentSortOldest CommentSort = "oldest"
+	CommentSortNewest CommentSort = "newest"
+	CommentSortTop    CommentSort = "top" // most liked first, newest first between equal likes
+)
+
+// Defines parameters for getting the comment tree of a post
+type GetCommentTreeParams struct {
+	PostID       uuid.UUID   `path:"post_id" example:"550e8400-e29b-41d4-a716-446655440000" doc:"ID of the post"`
+	Sort         CommentSort `query:"sort" default:"oldest" enum:"oldest,newest,top" doc:"Order of the comments at every level. Ignored when a cursor is passed, the cursor keeps its own sort"`
+	Depth        int         `query:"depth" default:"2" minimum:"1" maximum:"10" example:"2" doc:"Number of levels to return. Deeper replies are collapsed and come with a replies_cursor"`
+	Limit        int         `query:"limit" default:"20" minimum:"1" maximum:"100" example:"20" doc:"Number of comments to return at the first level"`
+	RepliesLimit int         `query:"replies_limit" default:"3" minimum:"1" maximum:"50" example:"3" doc:"Number of replies to return under each comment"`
+	Cursor       string      `query:"cursor" default:"" example:"eyJzIjoib2xkZXN0In0" doc:"next_cursor, or a comment's replies_cursor, from a previous response"`
+}
+
+// CommentTreeNode is a comment with the first page of its replies
+type CommentTreeNode struct {
+	CommentResponse
+	Replies       []CommentTreeNode `json:"replies" doc:"Replies to this comment, in the tree's sort order"`
+	RepliesCursor *string           `json:"replies_cursor,omitempty" example:"eyJzIjoib2xkZXN0In0" doc:"Pass as cursor to load the replies that weren't returned. Left out when all of them were"`
+}
+
+// Defines the response for a comment tree
+type GetCommentTreeResponse struct {
+	Comments   []CommentTreeNode `json:"comments" doc:"The requested level of comments, each with its replies"`
+	NextCursor *string           `json:"next_cursor,omitempty" example:"eyJzIjoib2xkZXN0In0" doc:"Pass as cursor to get the next page of this level"`
+}
+
 // Defines parameters for getting replies to a comment
 type GetReplyParams struct {
 	ID uuid.UUID `path:"id" example:"550e8400-e29b-41d4-a716-446655440000" doc:"ID of parent comment to get replies for"`
@@ -23,16 +56,18 @@ type GetReplyParams struct {
 
 // Defines the response structure for a comment
 type CommentResponse struct {
-	ID                uuid.UUID    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000" doc:"ID of comment"`
-	User              *models.User `json:"user,omitempty" doc:"The user who commented; omitted for anonymous comments"`
-	IsAnonymous       bool         `json:"is_anonymous" doc:"True if posted as anonymous; frontend can show 'Anonymous' when user_id is omitted"`
-	ParentCommentID   *uuid.UUID   `json:"parent_comment_id" example:"550e8400-e29b-41d4-a716-446655440000" doc:"CommentID this comment is in response to"`
-	PostID            uuid.UUID    `json:"post_id" example:"550e8400-e29b-41d4-a716-446655440000" doc:"PostID of the post this comment is under"`
-	Description       string       `json:"description" example:"This is a helpful thread" maxLength:"1500" doc:"Content of the comment"`
-	LikeCount         int64        `json:"like_count" doc:"Number of total likes on comment" example:"20000" gorm:"type:int"`
-	IsLiked           bool         `json:"is_liked" doc:"If current user has liked this comment" example:"true" gorm:"type:bool"`
-	IsVerifiedAthlete bool         `json:"is_verified_athlete" doc:"If commenter is a verified athlete" example:"true" gorm:"type:bool"`
-	HasReplies        bool         `json:"has_replies" doc:"True if this comment has at least one reply" example:"true" gorm:"type:bool"`
+	ID                uuid.UUID          `json:"id" example:"550e8400-e29b-41d4-a716-446655440000" doc:"ID of comment"`
+	User              *models.User       `json:"user,omitempty" doc:"The user who commented; omitted for anonymous comments"`
+	IsAnonymous       bool               `json:"is_anonymous" doc:"True if posted as anonymous; frontend can show 'Anonymous' when user_id is omitted"`
+	ParentCommentID   *uuid.UUID         `json:"parent_comment_id" example:"550e8400-e29b-41d4-a716-446655440000" doc:"CommentID this comment is in response to"`
+	PostID            uuid.UUID          `json:"post_id" example:"550e8400-e29b-41d4-a716-446655440000" doc:"PostID of the post this comment is under"`
+	Description       string             `json:"description" example:"This is a helpful thread" maxLength:"1500" doc:"Content of the comment"`
+	LikeCount         int64              `json:"like_count" doc:"Number of total likes on comment" example:"20000" gorm:"type:int"`
+	IsLiked           bool               `json:"is_liked" doc:"If current user has liked this comment" example:"true" gorm:"type:bool"`
+	IsVerifiedAthlete bool               `json:"is_verified_athlete" doc:"If commenter is a verified athlete" example:"true" gorm:"type:bool"`
+	AuthorTrust       *utils.AuthorTrust `json:"author_trust" doc:"How far to trust the commenter on the post's college and sport"`
+	HasReplies        bool               `json:"has_replies" doc:"True if this comment has at least one reply" example:"true" gorm:"type:bool"`
+	ReplyCount        int64              `json:"reply_count" doc:"Number of replies to this comment" example:"3"`
 }
 
 type CreateCommentResponse struct {
@@ -90,7 +125,9 @@ func ToCommentResponse(c *models.Comment, id uuid.UUID) *CommentResponse {
 		LikeCount:         c.LikeCount,
 		IsLiked:           c.IsLiked,
 		IsVerifiedAthlete: c.User.Verified_Athlete_Status == models.VerifiedAthleteStatusVerified,
+		AuthorTrust:       utils.TrustOf(&c.User, c.Post.CollegeID, c.Post.SportID, user == nil),
 		HasReplies:        c.HasReplies,
+		ReplyCount:        c.ReplyCount,
 	}
 }
 
@@ -105,3 +142,24 @@ func ToCreateCommentResponse(c *models.Comment, id uuid.UUID) *CreateCommentResp
 		Description:     c.Description,
 	}
 }
+
+// Converts a tree of comments to CommentTreeNodes, hiding anonymous users the same way ToCommentResponse does.
+func toCommentTree(nodes []commentNode, id uuid.UUID) []CommentTreeNode {
+	tree := make([]CommentTreeNode, len(nodes))
+	for i := range nodes {
+		tree[i] = CommentTreeNode{
+			CommentResponse: *ToCommentResponse(&nodes[i].Comment, id),
+			Replies:         toCommentTree(nodes[i].Replies, id),
+			RepliesCursor:   encodeTreeCursor(nodes[i].More),
+		}
+	}
+	return tree
+}
+
+func encodeTreeCursor(cursor *treeCursor) *string {
+	if cursor == nil {
+		return nil
+	}
+	token := utils.EncodeToken(cursor)
+	return &token
+}
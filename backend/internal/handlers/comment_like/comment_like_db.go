@@ -1,6 +1,7 @@
 package comment_like
 
 import (
+	"inside-athletics/internal/counters"
 	"inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
 
@@ -20,24 +21,40 @@ func (u *CommentLikeDB) GetCommentLike(id uuid.UUID) (*models.CommentLike, error
 	return utils.HandleDBError(&like, dbResponse.Error)
 }
 
-// CreateCommentLike creates a new like on a comment in the database
+// CreateCommentLike creates a new like on a comment in the database and counts it on the comment
 func (u *CommentLikeDB) CreateCommentLike(commentLike *models.CommentLike) (*models.CommentLike, bool, error) {
-	dbResponse := u.db.Clauses(
-		clause.OnConflict{
-			Columns:   []clause.Column{{Name: "user_id"}, {Name: "comment_id"}},
-			DoNothing: true,
-		},
-		clause.Returning{},
-	).Create(commentLike)
-	if dbResponse.Error != nil {
-		return nil, false, dbResponse.Error
+	inserted := false
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		dbResponse := tx.Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "comment_id"}},
+				DoNothing: true,
+			},
+			clause.Returning{},
+		).Create(commentLike)
+		if dbResponse.Error != nil {
+			return dbResponse.Error
+		}
+		if dbResponse.RowsAffected == 0 {
+			return nil
+		}
+		inserted = true
+		return counters.AddCommentLikes(tx, commentLike.CommentID, 1)
+	})
+	if err != nil {
+		return nil, false, err
 	}
-	if dbResponse.RowsAffected == 0 {
+	if !inserted {
 		return nil, false, nil
 	}
 	return commentLike, true, nil
 }
 
+// IsBlockedByAuthor reports whether the comment's author blocked userID
+func (u *CommentLikeDB) IsBlockedByAuthor(commentID, userID uuid.UUID) (bool, error) {
+	return utils.BlockedByAuthor(u.db, utils.CommentsTable, commentID, userID)
+}
+
 // Permanently deletes a like by ID
 func (u *CommentLikeDB) DeleteCommentLike(commentID uuid.UUID, userID uuid.UUID) (CommentID uuid.UUID, err error) {
 	var like models.CommentLike
@@ -46,16 +63,25 @@ func (u *CommentLikeDB) DeleteCommentLike(commentID uuid.UUID, userID uuid.UUID)
 		_, handleErr := utils.HandleDBError(&like, err)
 		return uuid.Nil, handleErr
 	}
-	result := u.db.Delete(&like)
-	if result.Error != nil {
-		return uuid.Nil, result.Error
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&like)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return counters.AddCommentLikes(tx, like.CommentID, -1)
+	})
+	if err != nil {
+		return uuid.Nil, err
 	}
 	return like.CommentID, nil
 }
 
 // Retrieves like count for the comment and whether the given user has liked it. If userID is zero, liked is false.
 func (u *CommentLikeDB) GetCommentLikeInfo(commentID, userID uuid.UUID) (count int64, liked bool, err error) {
-	err = u.db.Model(&models.CommentLike{}).Where("comment_id = ?", commentID).Count(&count).Error
+	err = u.db.Model(&models.Comment{}).Select("like_count").Where("id = ?", commentID).Scan(&count).Error
 	if err != nil {
 		return 0, false, err
 	}
@@ -1,18 +1,22 @@
 package comment_like
 
 import (
+	"inside-athletics/internal/events"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
 
-func Route(api huma.API, db *gorm.DB) {
+func Route(api huma.API, db *gorm.DB, bus *events.Bus) {
 	var commentLikeDB = &CommentLikeDB{db: db}
-	var commentLikeService = &CommentLikeService{commentLikeDB}
+	var commentLikeService = &CommentLikeService{commentLikeDB: commentLikeDB, events: bus}
 	{
 		grp := huma.NewGroup(api, "/api/v1/comment/like")
-		huma.Post(grp, "", commentLikeService.CreateCommentLike)                    // Create like
-		huma.Get(grp, "/{id}", commentLikeService.GetCommentLike)                    // Get like by ID
-		huma.Delete(grp, "/{id}", commentLikeService.DeleteCommentLike)               // Delete like
-		huma.Get(grp, "/{comment_id}/likes", commentLikeService.GetCommentLikeInfo) // Like count and whether user liked
+		huma.Post(grp, "", commentLikeService.CreateCommentLike, utils.Requires(utils.Permission(models.PermissionCreate, "like")))        // Create like
+		huma.Get(grp, "/{id}", commentLikeService.GetCommentLike)                                                                          // Get like by ID
+		huma.Delete(grp, "/{id}", commentLikeService.DeleteCommentLike, utils.Requires(utils.Permission(models.PermissionDelete, "like"))) // Delete like
+		huma.Get(grp, "/{comment_id}/likes", commentLikeService.GetCommentLikeInfo)                                                        // Like count and whether user liked
 	}
-}
\ No newline at end of file
+}
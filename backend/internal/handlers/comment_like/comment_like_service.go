@@ -2,6 +2,7 @@ package comment_like
 
 import (
 	"context"
+	"inside-athletics/internal/events"
 	models "inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
 
@@ -10,6 +11,7 @@ import (
 
 type CommentLikeService struct {
 	commentLikeDB *CommentLikeDB
+	events        events.Publisher
 }
 
 // Retrieves a like by ID.
@@ -26,13 +28,21 @@ func (u *CommentLikeService) GetCommentLike(ctx context.Context, input *GetComme
 	}, nil
 }
 
-// Creates a like on a comment. Returns 409 if the user has already liked the comment.
+// Creates a like on a comment. Returns 409 if the user has already liked the comment and 403 if
+// its author blocked them.
 // Response includes total likes on the comment and liked=true for the requesting user.
 func (u *CommentLikeService) CreateCommentLike(ctx context.Context, input *CreateCommentLikeInput) (*utils.ResponseBody[CreateCommentLikeResponse], error) {
 	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
+	blocked, err := u.commentLikeDB.IsBlockedByAuthor(input.Body.CommentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, huma.Error403Forbidden("You can't like comments by a user who blocked you")
+	}
 	commentLike := &models.CommentLike{
 		UserID:    userID,
 		CommentID: input.Body.CommentID,
@@ -44,6 +54,7 @@ func (u *CommentLikeService) CreateCommentLike(ctx context.Context, input *Creat
 	if !inserted {
 		return nil, huma.Error409Conflict("User has already liked this comment")
 	}
+	u.events.Publish(ctx, events.CommentLiked{CommentID: created.CommentID, UserID: userID})
 	total, _, err := u.commentLikeDB.GetCommentLikeInfo(input.Body.CommentID, userID)
 	if err != nil {
 		return nil, err
@@ -67,6 +78,7 @@ func (u *CommentLikeService) DeleteCommentLike(ctx context.Context, input *Delet
 	if err != nil {
 		return nil, err
 	}
+	u.events.Publish(ctx, events.CommentUnliked{CommentID: commentID, UserID: userID})
 	total, liked, err := u.commentLikeDB.GetCommentLikeInfo(commentID, userID)
 	if err != nil {
 		return nil, err
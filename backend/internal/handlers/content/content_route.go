@@ -1,21 +1,25 @@
 package content
 
 import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
 	"inside-athletics/internal/s3"
+	"inside-athletics/internal/utils"
 
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
 
 // Registers premium content (S3 upload/download URL) routes; s3Service must be non-nil.
-func Route(api huma.API, db *gorm.DB, s3Service *s3.Service) {
+func Route(api huma.API, db *gorm.DB, s3Service *s3.Service, grants *permcache.Cache) {
 	if s3Service == nil {
 		return
 	}
-	svc := NewContentService(s3Service)
+	svc := NewContentService(s3Service, grants)
 	grp := huma.NewGroup(api, "/api/v1/content")
-	huma.Post(grp, "/upload-url", svc.GetUploadURL)
+	huma.Post(grp, "/upload-url", svc.GetUploadURL, utils.Requires(utils.Authenticated))
 	huma.Get(grp, "/download-url", svc.GetDownloadURL)
-	huma.Post(grp, "/confirm-upload", svc.ConfirmUpload)
-	huma.Delete(grp, "", svc.DeleteContent)
+	huma.Post(grp, "/confirm-upload", svc.ConfirmUpload, utils.Requires(utils.Authenticated))
+	huma.Delete(grp, "", svc.DeleteContent, utils.Requires(utils.Permission(models.PermissionDelete, "content").
+		OwnedByKey(utils.KeyUnder(s3.ContentPrefix)).WithIDQuery("key")))
 }
@@ -2,24 +2,42 @@ package content
 
 import (
 	"context"
+	"errors"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
 	"inside-athletics/internal/s3"
 	"inside-athletics/internal/utils"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"gorm.io/gorm"
 )
 
 // Holds S3 service for premium content upload/download URLs.
 type ContentService struct {
 	s3 *s3.Service
+	// grants decide who besides their owner may read ID documents
+	grants *permcache.Cache
 }
 
 // Returns a ContentService that uses the given S3 service.
-func NewContentService(s3Service *s3.Service) *ContentService {
-	return &ContentService{s3: s3Service}
+func NewContentService(s3Service *s3.Service, grants *permcache.Cache) *ContentService {
+	return &ContentService{s3: s3Service, grants: grants}
 }
 
-// Returns a presigned upload URL and key/expiry for the request body.
+// Returns a presigned upload URL and key/expiry for the request body. The key is put under the
+// user's own prefix, so users can't overwrite each other's objects.
 func (c *ContentService) GetUploadURL(ctx context.Context, input *GetUploadURLInput) (*utils.ResponseBody[s3.GetUploadURLResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := input.Body.Key
+	if prefix := s3.ContentPrefix(userID); !strings.HasPrefix(key, prefix) {
+		key = prefix + key
+	}
 	resp, err := c.s3.GetUploadURL(ctx, s3.GetUploadURLInput{
-		Key:      input.Body.Key,
+		Key:      key,
 		FileType: input.Body.FileType,
 		FileName: input.Body.FileName,
 	})
@@ -29,8 +47,34 @@ func (c *ContentService) GetUploadURL(ctx context.Context, input *GetUploadURLIn
 	return &utils.ResponseBody[s3.GetUploadURLResponse]{Body: resp}, nil
 }
 
+// checkReadable stops users reading other users' ID documents, unless they review verification
+// requests.
+func (c *ContentService) checkReadable(ctx context.Context, key string) error {
+	if !s3.IsVerificationKey(key) {
+		return nil
+	}
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(key, s3.VerificationPrefix(userID)) {
+		return nil
+	}
+	grants, err := c.grants.Get(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return huma.Error500InternalServerError("Unable to check permissions", err)
+	}
+	if grants == nil || !grants.Allows(models.PermissionRead, "verification") {
+		return huma.Error403Forbidden("You can't read another user's verification documents")
+	}
+	return nil
+}
+
 // Returns a presigned download URL for the given key.
 func (c *ContentService) GetDownloadURL(ctx context.Context, input *GetDownloadURLParams) (*utils.ResponseBody[s3.GetDownloadURLResponse], error) {
+	if err := c.checkReadable(ctx, input.Key); err != nil {
+		return nil, err
+	}
 	resp, err := c.s3.GetDownloadURL(ctx, input.Key)
 	if err != nil {
 		return nil, err
@@ -40,6 +84,9 @@ func (c *ContentService) GetDownloadURL(ctx context.Context, input *GetDownloadU
 
 // Confirms upload via HeadObject and returns download URL and size/metadata.
 func (c *ContentService) ConfirmUpload(ctx context.Context, input *ConfirmUploadInput) (*utils.ResponseBody[s3.ConfirmUploadResponse], error) {
+	if err := c.checkReadable(ctx, input.Body.Key); err != nil {
+		return nil, err
+	}
 	resp, err := c.s3.ConfirmUpload(ctx, input.Body.Key)
 	if err != nil {
 		return nil, err
@@ -47,7 +94,8 @@ func (c *ContentService) ConfirmUpload(ctx context.Context, input *ConfirmUpload
 	return &utils.ResponseBody[s3.ConfirmUploadResponse]{Body: resp}, nil
 }
 
-// Deletes the object at key from S3.
+// Deletes the object at key from S3. Users may delete what they uploaded; anything else needs the
+// delete permission on content.
 func (c *ContentService) DeleteContent(ctx context.Context, input *DeleteContentParams) (*utils.ResponseBody[DeleteContentResponse], error) {
 	if err := c.s3.DeleteObject(ctx, input.Key); err != nil {
 		return nil, err
@@ -2,7 +2,7 @@ package content
 
 // Request body for requesting a presigned upload URL.
 type GetUploadURLRequest struct {
-	Key      string `json:"key" required:"true" doc:"Full S3 object key, e.g. premium/image/content-123/photo.jpg"`
+	Key      string `json:"key" required:"true" doc:"S3 object key, e.g. premium/image/content-123/photo.jpg. It's put under content/<user id>/ unless it already is"`
 	FileType string `json:"fileType" required:"true" doc:"MIME type, e.g. image/jpeg, application/pdf"`
 	FileName string `json:"fileName" doc:"Optional; used as documentId in response (defaults to last segment of key)"`
 }
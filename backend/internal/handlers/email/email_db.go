@@ -0,0 +1,68 @@
+package email
+
+import (
+	"inside-athletics/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailDB struct {
+	db *gorm.DB
+}
+
+// NewEmailDB creates a new EmailDB instance
+func NewEmailDB(db *gorm.DB) *EmailDB {
+	return &EmailDB{db: db}
+}
+
+// GetPreferences returns the user's email preferences, creating the defaults if they have none yet.
+func (e *EmailDB) GetPreferences(userID uuid.UUID) (*models.EmailPreference, error) {
+	preferences := models.EmailPreference{UserID: userID}
+	if err := e.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&preferences).Error; err != nil {
+		return nil, err
+	}
+	if err := e.db.First(&preferences, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+// UpdatePreferences sets whichever of the user's lists are non-nil.
+func (e *EmailDB) UpdatePreferences(userID uuid.UUID, digest *bool, replyAlerts *bool) (*models.EmailPreference, error) {
+	preferences, err := e.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{}
+	if digest != nil {
+		updates["digest"] = *digest
+	}
+	if replyAlerts != nil {
+		updates["reply_alerts"] = *replyAlerts
+	}
+	if len(updates) == 0 {
+		return preferences, nil
+	}
+	if err := e.db.Model(preferences).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// Unsubscribe turns off lists for whoever holds token. Returns gorm.ErrRecordNotFound if no one does.
+func (e *EmailDB) Unsubscribe(token string, lists []models.EmailList) (*models.EmailPreference, error) {
+	var preferences models.EmailPreference
+	if err := e.db.First(&preferences, "unsubscribe_token = ?", token).Error; err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{}
+	for _, list := range lists {
+		updates[string(list)] = false
+	}
+	if err := e.db.Model(&preferences).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return &preferences, nil
+}
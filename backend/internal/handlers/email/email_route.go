@@ -0,0 +1,28 @@
+package email
+
+import (
+	"inside-athletics/internal/utils"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// UnsubscribePath is where unsubscribe links point. It's public, the token is the credential.
+const UnsubscribePath = "/api/v1/email/unsubscribe"
+
+func Route(api huma.API, db *gorm.DB) {
+	emailService := NewEmailService(db)
+	{
+		grp := huma.NewGroup(api, "/api/v1/email")
+		huma.Get(grp, "/preferences", emailService.GetPreferences)                                         // Get email settings
+		huma.Put(grp, "/preferences", emailService.UpdatePreferences, utils.Requires(utils.Authenticated)) // Update email settings
+	}
+}
+
+// RegisterUnsubscribeRoute registers the unsubscribe link handler on the raw Fiber app (bypassing Huma/auth).
+func RegisterUnsubscribeRoute(router *fiber.App, db *gorm.DB) {
+	svc := NewEmailService(db)
+	router.Get(UnsubscribePath, svc.Unsubscribe)
+	router.Post(UnsubscribePath, svc.Unsubscribe)
+}
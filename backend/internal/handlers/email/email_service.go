@@ -0,0 +1,75 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"inside-athletics/internal/utils"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type EmailService struct {
+	emailDB *EmailDB
+}
+
+// NewEmailService creates a new EmailService instance
+func NewEmailService(db *gorm.DB) *EmailService {
+	return &EmailService{emailDB: NewEmailDB(db)}
+}
+
+// GetPreferences returns which optional emails the current user gets
+func (s *EmailService) GetPreferences(ctx context.Context, input *struct{}) (*utils.ResponseBody[EmailPreferencesResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := s.emailDB.GetPreferences(userID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to get email preferences", err)
+	}
+	return &utils.ResponseBody[EmailPreferencesResponse]{
+		Body: toEmailPreferencesResponse(preferences),
+	}, nil
+}
+
+// UpdatePreferences turns optional emails on or off for the current user
+func (s *EmailService) UpdatePreferences(ctx context.Context, input *UpdateEmailPreferencesInput) (*utils.ResponseBody[EmailPreferencesResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := s.emailDB.UpdatePreferences(userID, input.Body.Digest, input.Body.ReplyAlerts)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to update email preferences", err)
+	}
+	return &utils.ResponseBody[EmailPreferencesResponse]{
+		Body: toEmailPreferencesResponse(preferences),
+	}, nil
+}
+
+// Unsubscribe is a raw Fiber handler (not Huma) for the links in optional emails. It takes the
+// holder of the token query param off the list query param ("all" when left out) without signing
+// in. Mail clients' one-click unsubscribe POSTs to the same URL.
+func (s *EmailService) Unsubscribe(c *fiber.Ctx) error {
+	token := c.Query("token")
+	lists, ok := listsByName[c.Query("list")]
+	if token == "" || !ok {
+		return c.Status(fiber.StatusBadRequest).SendString("invalid unsubscribe link")
+	}
+
+	if _, err := s.emailDB.Unsubscribe(token, lists); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).SendString("unsubscribe link not found")
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString("failed to unsubscribe")
+	}
+
+	names := make([]string, len(lists))
+	for i, list := range lists {
+		names[i] = listDescriptions[list]
+	}
+	return c.SendString("You've been unsubscribed from " + strings.Join(names, " and ") + ". You can turn emails back on in your settings.")
+}
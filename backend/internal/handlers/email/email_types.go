@@ -0,0 +1,34 @@
+package email
+
+import "inside-athletics/internal/models"
+
+// EmailPreferencesResponse is which optional emails the current user gets
+type EmailPreferencesResponse struct {
+	Digest      bool `json:"digest" doc:"Weekly digest of popular posts in followed tags, sports and colleges"`
+	ReplyAlerts bool `json:"reply_alerts" doc:"Emails when someone replies to one of your comments"`
+}
+
+// UpdateEmailPreferencesInput defines the request for changing email settings
+type UpdateEmailPreferencesInput struct {
+	Body struct {
+		Digest      *bool `json:"digest,omitempty" doc:"Left out keeps the current setting"`
+		ReplyAlerts *bool `json:"reply_alerts,omitempty" doc:"Left out keeps the current setting"`
+	}
+}
+
+func toEmailPreferencesResponse(p *models.EmailPreference) *EmailPreferencesResponse {
+	return &EmailPreferencesResponse{Digest: p.Digest, ReplyAlerts: p.ReplyAlerts}
+}
+
+// listsByName are the lists an unsubscribe link's list param can name
+var listsByName = map[string][]models.EmailList{
+	string(models.EmailListDigest):      {models.EmailListDigest},
+	string(models.EmailListReplyAlerts): {models.EmailListReplyAlerts},
+	"all":                               {models.EmailListDigest, models.EmailListReplyAlerts},
+	"":                                  {models.EmailListDigest, models.EmailListReplyAlerts},
+}
+
+var listDescriptions = map[models.EmailList]string{
+	models.EmailListDigest:      "the weekly digest",
+	models.EmailListReplyAlerts: "reply alerts",
+}
@@ -0,0 +1,297 @@
+package feed
+
+import (
+	"inside-athletics/internal/handlers/post"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/poststats"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FeedDB struct {
+	db    *gorm.DB
+	stats *poststats.Store
+}
+
+// Share of each page, in percent, that goes to trending and exploration posts. Followed posts get
+// the rest, and whatever a stream can't fill goes to the others.
+const (
+	trendingShare = 30
+	exploreShare  = 10
+	// posts scoring less than about one recent like are left to exploration
+	minTrendingScore = 1.0
+)
+
+// Each stream is paged by its own keyset over the candidates subquery (aliased c).
+var (
+	followedKeyset = utils.Keyset{KeyColumn: "c.created_at", IDColumn: "c.id", Descending: true}
+	trendingKeyset = utils.Keyset{KeyColumn: "c.trending", IDColumn: "c.id", Descending: true}
+	exploreKeyset  = utils.Keyset{KeyColumn: "c.explore", IDColumn: "c.id"}
+)
+
+// NewFeedDB creates a new FeedDB instance
+func NewFeedDB(db *gorm.DB) *FeedDB {
+	return &FeedDB{db: db, stats: poststats.NewStore(db, poststats.LoadConfigFromEnv())}
+}
+
+// feedCursor is how far a feed has got in each stream. Trending scores are taken as of AsOf and
+// exploration is shuffled with Seed, so every page of one feed sees the same order. Posts created
+// after AsOf are left for the next feed.
+type feedCursor struct {
+	AsOf     time.Time                `json:"at"`
+	Seed     string                   `json:"s"`
+	Followed *utils.Cursor[time.Time] `json:"f,omitempty"`
+	Trending *utils.Cursor[float64]   `json:"t,omitempty"`
+	Explore  *utils.Cursor[string]    `json:"e,omitempty"`
+}
+
+// decodeFeedCursor parses a feed's next_cursor. An empty token starts a new feed.
+func decodeFeedCursor(token string) (*feedCursor, error) {
+	cursor, err := utils.DecodeToken[feedCursor](token)
+	if err != nil {
+		return nil, err
+	}
+	if cursor == nil {
+		return &feedCursor{AsOf: time.Now().UTC(), Seed: uuid.NewString()}, nil
+	}
+	if cursor.AsOf.IsZero() || cursor.Seed == "" {
+		return nil, huma.Error400BadRequest("Invalid cursor")
+	}
+	return cursor, nil
+}
+
+// candidate is a post or premium post that can go into the feed, with its key in every stream.
+type candidate struct {
+	ID        uuid.UUID
+	Premium   bool
+	CreatedAt time.Time
+	Trending  float64
+	Explore   string
+}
+
+// feedEntry is a post placed in the feed, loaded with everything the response needs.
+type feedEntry struct {
+	Source      Source
+	Post        *models.Post
+	PremiumPost *models.PremiumPost
+}
+
+// GetFeed returns the page of the user's feed after cursor, and the cursor for the page after it
+// (nil on the last page). Premium posts are only included when premium is set.
+func (f *FeedDB) GetFeed(userID uuid.UUID, premium bool, cursor *feedCursor, limit int) ([]feedEntry, *feedCursor, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	streams := make([][]candidate, 3)
+	var err error
+	if streams[0], err = fetch(f.stream(userID, premium, cursor).Where("c.followed"), followedKeyset, cursor.Followed, limit); err != nil {
+		return nil, nil, err
+	}
+	if streams[1], err = fetch(f.stream(userID, premium, cursor).Where("NOT c.followed AND c.trending >= ?", minTrendingScore), trendingKeyset, cursor.Trending, limit); err != nil {
+		return nil, nil, err
+	}
+	if streams[2], err = fetch(f.stream(userID, premium, cursor).Where("NOT c.followed AND c.trending < ?", minTrendingScore), exploreKeyset, cursor.Explore, limit); err != nil {
+		return nil, nil, err
+	}
+
+	explore := limit * exploreShare / 100
+	trending := limit * trendingShare / 100
+	picked, taken := mix(limit, []int{limit - trending - explore, trending, explore}, streams)
+
+	entries, err := f.load(userID, picked)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next := *cursor
+	hasMore := false
+	for i, rows := range streams {
+		if taken[i] < len(rows) || len(rows) > limit {
+			hasMore = true
+		}
+		if taken[i] == 0 {
+			continue
+		}
+		last := rows[taken[i]-1]
+		switch i {
+		case 0:
+			next.Followed = &utils.Cursor[time.Time]{Key: last.CreatedAt, ID: last.ID}
+		case 1:
+			next.Trending = &utils.Cursor[float64]{Key: last.Trending, ID: last.ID}
+		case 2:
+			next.Explore = &utils.Cursor[string]{Key: last.Explore, ID: last.ID}
+		}
+	}
+	if !hasMore {
+		return entries, nil, nil
+	}
+	return entries, &next, nil
+}
+
+// fetch reads up to limit+1 candidates of one stream, starting after pos.
+func fetch[K any](db *gorm.DB, keyset utils.Keyset, pos *utils.Cursor[K], limit int) ([]candidate, error) {
+	var rows []candidate
+	if err := utils.NewPageAt(keyset, pos, limit).Apply(db).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// pick is a candidate placed in the feed and the stream it came from.
+type pick struct {
+	candidate
+	source Source
+}
+
+var streamSources = []Source{SourceFollowed, SourceTrending, SourceExplore}
+
+// mix interleaves the streams into a page of up to limit posts. Each stream first gets its share of
+// the page, then what is left is filled from the streams that still have posts. taken is how many
+// rows were used up from each stream, including duplicates that were skipped.
+func mix(limit int, shares []int, streams [][]candidate) (picked []pick, taken []int) {
+	taken = make([]int, len(streams))
+	counts := make([]int, len(streams))
+	seen := map[uuid.UUID]bool{}
+
+	next := func(i int) bool {
+		for taken[i] < len(streams[i]) {
+			c := streams[i][taken[i]]
+			taken[i]++
+			if !seen[c.ID] {
+				seen[c.ID] = true
+				counts[i]++
+				picked = append(picked, pick{candidate: c, source: streamSources[i]})
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, capped := range []bool{true, false} {
+		for progress := true; progress && len(picked) < limit; {
+			progress = false
+			for i := range streams {
+				if len(picked) >= limit || (capped && counts[i] >= shares[i]) {
+					continue
+				}
+				if next(i) {
+					progress = true
+				}
+			}
+		}
+	}
+	return picked, taken
+}
+
+// stream selects the feed's candidates as the table c: posts (and premium posts) the user hasn't
+// viewed, with whether the user follows them, their trending score and their place in exploration.
+func (f *FeedDB) stream(userID uuid.UUID, premium bool, cursor *feedCursor) *gorm.DB {
+	posts := f.db.Table("posts").
+		Select("posts.id, FALSE AS premium, posts.created_at, (?) AS followed, ? AS trending, MD5(CAST(? AS text) || posts.id::text) AS explore",
+			followedBy("posts", "post", userID), f.stats.TrendingScoreAt("ps", cursor.AsOf), cursor.Seed).
+		Joins("LEFT JOIN post_stats AS ps ON ps.post_id = posts.id").
+		Where("posts.deleted_at IS NULL AND posts.created_at <= ?", cursor.AsOf).
+		Scopes(utils.VisibleTo(utils.PostsTable, userID)).
+		Where("NOT EXISTS (SELECT 1 FROM viewed_posts vp WHERE vp.user_id = ? AND vp.post_id = posts.id AND vp.deleted_at IS NULL)", userID)
+	if !premium {
+		return f.db.Table("(?) AS c", posts)
+	}
+
+	// premium posts have no stats or views, they are either followed or explored
+	premiumPosts := f.db.Table("premium_posts").
+		Select("premium_posts.id, TRUE AS premium, premium_posts.created_at, (?) AS followed, 0::float8 AS trending, MD5(CAST(? AS text) || premium_posts.id::text) AS explore",
+			followedBy("premium_posts", "premium_post", userID), cursor.Seed).
+		Where("premium_posts.deleted_at IS NULL AND premium_posts.created_at <= ?", cursor.AsOf).
+		Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID))
+	return f.db.Table("((?) UNION ALL (?)) AS c", posts, premiumPosts)
+}
+
+// followedBy is whether the row of table is in a tag, sport or college the user follows.
+// postableType is the table's type in tag_posts.
+func followedBy(table string, postableType string, userID uuid.UUID) clause.Expr {
+	return gorm.Expr(`EXISTS (
+				SELECT 1
+				FROM tag_follows tf
+				JOIN tag_posts tp ON tp.tag_id = tf.tag_id
+				WHERE tf.user_id = ? AND tp.postable_id = `+table+`.id AND tp.postable_type = ?
+			) OR EXISTS (
+				SELECT 1 FROM sport_follows sf WHERE sf.user_id = ? AND sf.sport_id = `+table+`.sport_id
+			) OR EXISTS (
+				SELECT 1 FROM college_follows cf WHERE cf.user_id = ? AND cf.college_id = `+table+`.college_id
+			)`, userID, postableType, userID, userID)
+}
+
+// load reads the picked posts and premium posts, in feed order. Posts deleted since they were
+// picked are dropped.
+func (f *FeedDB) load(userID uuid.UUID, picked []pick) ([]feedEntry, error) {
+	var postIDs, premiumIDs []uuid.UUID
+	for _, p := range picked {
+		if p.Premium {
+			premiumIDs = append(premiumIDs, p.ID)
+		} else {
+			postIDs = append(postIDs, p.ID)
+		}
+	}
+
+	var posts []models.Post
+	if len(postIDs) > 0 {
+		if err := f.db.
+			Table("posts").
+			Select(post.POST_SELECT_QUERY, userID).
+			Preload("Author").
+			Preload("Sport", "id IS NOT NULL").
+			Preload("College", "id IS NOT NULL").
+			Preload("Tags", func(db *gorm.DB) *gorm.DB {
+				return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'post'")
+			}).
+			Where("posts.id IN ? AND posts.deleted_at IS NULL", postIDs).
+			Find(&posts).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var premiumPosts []models.PremiumPost
+	if len(premiumIDs) > 0 {
+		if err := f.db.
+			Model(&models.PremiumPost{}).
+			Preload("Author").
+			Preload("Sport", "id IS NOT NULL").
+			Preload("College", "id IS NOT NULL").
+			Preload("Media").
+			Preload("Tags", func(db *gorm.DB) *gorm.DB {
+				return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'premium_post'")
+			}).
+			Where("premium_posts.id IN ?", premiumIDs).
+			Find(&premiumPosts).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	postsByID := make(map[uuid.UUID]*models.Post, len(posts))
+	for i := range posts {
+		postsByID[posts[i].ID] = &posts[i]
+	}
+	premiumByID := make(map[uuid.UUID]*models.PremiumPost, len(premiumPosts))
+	for i := range premiumPosts {
+		premiumByID[premiumPosts[i].ID] = &premiumPosts[i]
+	}
+
+	entries := make([]feedEntry, 0, len(picked))
+	for _, p := range picked {
+		entry := feedEntry{Source: p.source}
+		if p.Premium {
+			entry.PremiumPost = premiumByID[p.ID]
+		} else {
+			entry.Post = postsByID[p.ID]
+		}
+		if entry.Post != nil || entry.PremiumPost != nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
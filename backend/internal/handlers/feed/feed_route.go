@@ -0,0 +1,16 @@
+package feed
+
+import (
+	"inside-athletics/internal/s3"
+
+	"github.com/danielgtaylor/huma/v2"
+	"gorm.io/gorm"
+)
+
+func Route(api huma.API, db *gorm.DB, s3Svc *s3.Service) {
+	feedService := NewFeedService(db, s3Svc)
+	{
+		grp := huma.NewGroup(api, "/api/v1/feed")
+		huma.Get(grp, "/", feedService.GetFeed) // Read the current user's personalized feed
+	}
+}
@@ -0,0 +1,87 @@
+package feed
+
+import (
+	"context"
+	"inside-athletics/internal/handlers/post"
+	premiumpost "inside-athletics/internal/handlers/premium_post"
+	"inside-athletics/internal/handlers/utility"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/s3"
+	"inside-athletics/internal/utils"
+
+	"github.com/danielgtaylor/huma/v2"
+	"gorm.io/gorm"
+)
+
+type FeedService struct {
+	feedDB    *FeedDB
+	utilityDB *utility.UtilityDB
+	s3        *s3.Service
+}
+
+// NewFeedService creates a new FeedService instance
+func NewFeedService(db *gorm.DB, s3Svc *s3.Service) *FeedService {
+	return &FeedService{
+		feedDB:    NewFeedDB(db),
+		utilityDB: utility.NewUtilityDB(db),
+		s3:        s3Svc,
+	}
+}
+
+// GetFeed returns the current user's "for you" feed: posts from the tags, sports and colleges they
+// follow, mixed with trending posts and a few random ones to explore, skipping what they've viewed.
+func (s *FeedService) GetFeed(ctx context.Context, input *GetFeedParams) (*utils.ResponseBody[GetFeedResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := decodeFeedCursor(input.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	hasPremium, err := s.utilityDB.UserHasPremium(userID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to check premium access")
+	}
+
+	entries, next, err := s.feedDB.GetFeed(userID, hasPremium, cursor, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]FeedItem, 0, len(entries))
+	for _, entry := range entries {
+		item := FeedItem{Source: entry.Source}
+		if entry.Post != nil {
+			entry.Post.Author.ProfilePicture = s3.ResolveKey(ctx, s.s3, entry.Post.Author.ProfilePicture)
+			item.Post = post.ToPostResponse(entry.Post, userID)
+		} else {
+			s.resolvePremiumKeys(ctx, entry.PremiumPost)
+			item.PremiumPost = premiumpost.ToPremiumPostResponse(entry.PremiumPost)
+		}
+		items = append(items, item)
+	}
+
+	response := &GetFeedResponse{Items: items}
+	if next != nil {
+		token := utils.EncodeToken(next)
+		response.NextCursor = &token
+	}
+	return &utils.ResponseBody[GetFeedResponse]{
+		Body: response,
+	}, nil
+}
+
+// resolvePremiumKeys replaces a premium post's media and author picture keys with presigned URLs.
+func (s *FeedService) resolvePremiumKeys(ctx context.Context, p *models.PremiumPost) {
+	if p.Media != nil {
+		if url := s3.ResolveKey(ctx, s.s3, p.Media.S3Key); url != "" {
+			p.Media.S3Key = url
+		}
+	}
+	if p.Author.ProfilePicture != "" {
+		if url := s3.ResolveKey(ctx, s.s3, p.Author.ProfilePicture); url != "" {
+			p.Author.ProfilePicture = url
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package feed
+
+import (
+	"inside-athletics/internal/handlers/post"
+	premiumpost "inside-athletics/internal/handlers/premium_post"
+)
+
+// Source is the part of the feed an item was picked from.
+type Source string
+
+const (
+	SourceFollowed Source = "followed" // in a tag, sport or college the user follows
+	SourceTrending Source = "trending" // not followed, but with a high trending score
+	SourceExplore  Source = "explore"  // everything else, in a random order per feed
+)
+
+// GetFeedParams defines the query parameters for the personalized feed
+type GetFeedParams struct {
+	Limit  int    `query:"limit" default:"20" minimum:"1" maximum:"100" example:"20" doc:"Number of items to return"`
+	Cursor string `query:"cursor" default:"" example:"eyJhdCI6IjIwMjYtMDUtMDFUMTI6MDA6MDBaIiwicyI6IjNmMmEifQ" doc:"next_cursor from the previous page. Leave empty to start a new feed"`
+}
+
+// FeedItem is one entry of the feed. Exactly one of Post and PremiumPost is set.
+type FeedItem struct {
+	Source      Source                           `json:"source" enum:"followed,trending,explore" example:"followed" doc:"Why the post is in the feed"`
+	Post        *post.PostResponse               `json:"post,omitempty"`
+	PremiumPost *premiumpost.PremiumPostResponse `json:"premium_post,omitempty" doc:"Only returned to premium users"`
+}
+
+// GetFeedResponse defines the response for the personalized feed
+type GetFeedResponse struct {
+	Items      []FeedItem `json:"items" doc:"Followed, trending and a few exploration posts, mixed, without posts the user has already viewed"`
+	NextCursor *string    `json:"next_cursor,omitempty" example:"eyJhdCI6IjIwMjYtMDUtMDFUMTI6MDA6MDBaIiwicyI6IjNmMmEifQ" doc:"Pass as cursor to get the next page. Left out on the last page"`
+}
@@ -1,16 +1,19 @@
 package health
 
 import (
+	"inside-athletics/internal/permcache"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
 
-func Route(api huma.API, db *gorm.DB) {
-	var healthDB = &HealthDB{db}              // create object storing all database level functions for health
-	var healthService = &HealthService{healthDB} // create object with health functionality
+func Route(api huma.API, db *gorm.DB, grants *permcache.Cache) {
+	var healthDB = &HealthDB{db}                         // create object storing all database level functions for health
+	var healthService = &HealthService{healthDB, grants} // create object with health functionality
 	{
 		grp := huma.NewGroup(api, "/api/v1/health")
 		huma.Get(grp, "/", healthService.Health)
 		huma.Get(grp, "/healthcheck", healthService.CheckHealth)
+		huma.Get(grp, "/permission-cache", healthService.PermissionCacheStats) // hit rate of the permission cache
 	}
 }
@@ -2,6 +2,7 @@ package health
 
 import (
 	"context"
+	"inside-athletics/internal/permcache"
 	"inside-athletics/internal/utils"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -9,6 +10,7 @@ import (
 
 type HealthService struct {
 	healthDB *HealthDB
+	grants   *permcache.Cache
 }
 
 func (h *HealthService) CheckHealth(ctx context.Context, input *utils.EmptyInput) (*utils.ResponseBody[HealthResponse], error) {
@@ -30,3 +32,10 @@ func (h *HealthService) Health(ctx context.Context, input *utils.EmptyInput) (*u
 		},
 	}, nil
 }
+
+// PermissionCacheStats reports how often permission checks were answered from the cache.
+func (h *HealthService) PermissionCacheStats(ctx context.Context, input *utils.EmptyInput) (*utils.ResponseBody[PermissionCacheResponse], error) {
+	return &utils.ResponseBody[PermissionCacheResponse]{
+		Body: &PermissionCacheResponse{Stats: h.grants.Stats()},
+	}, nil
+}
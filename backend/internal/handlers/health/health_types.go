@@ -1,5 +1,11 @@
 package health
 
+import "inside-athletics/internal/permcache"
+
 type HealthResponse struct {
 	Message string `json:"message" example:"Healthy!" doc:"Message to display"`
 }
+
+type PermissionCacheResponse struct {
+	permcache.Stats
+}
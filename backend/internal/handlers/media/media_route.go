@@ -1,6 +1,9 @@
 package media
 
 import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
@@ -9,8 +12,8 @@ func Route(api huma.API, db *gorm.DB) {
 	mediaService := NewMediaService(db)
 	{
 		grp := huma.NewGroup(api, "/api/v1/media")
-		huma.Post(grp, "/", mediaService.CreateMedia)       // Add media
-		huma.Get(grp, "/{id}", mediaService.GetMedia)       // Get media by id
-		huma.Delete(grp, "/{id}", mediaService.DeleteMedia) // Delete media
+		huma.Post(grp, "/", mediaService.CreateMedia, utils.Requires(utils.Permission(models.PermissionCreate, "media")))       // Add media
+		huma.Get(grp, "/{id}", mediaService.GetMedia)                                                                           // Get media by id
+		huma.Delete(grp, "/{id}", mediaService.DeleteMedia, utils.Requires(utils.Permission(models.PermissionDelete, "media"))) // Delete media
 	}
 }
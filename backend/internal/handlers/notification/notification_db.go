@@ -0,0 +1,212 @@
+package notification
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationDB struct {
+	db *gorm.DB
+}
+
+// NotificationKeyset pages notifications most recently active first. Grouping bumps a notification's
+// updated_at, so it moves back to the top when someone else joins it.
+var NotificationKeyset = utils.Keyset{KeyColumn: "notifications.updated_at", IDColumn: "notifications.id", Descending: true}
+
+// NotificationCursor is a notification's position in NotificationKeyset.
+func NotificationCursor(n *models.Notification) utils.Cursor[time.Time] {
+	return utils.Cursor[time.Time]{Key: n.UpdatedAt, ID: n.ID}
+}
+
+// NewNotificationDB creates a new NotificationDB instance
+func NewNotificationDB(db *gorm.DB) *NotificationDB {
+	return &NotificationDB{db: db}
+}
+
+// Sent is a notification that was created or grouped into, and who it's for.
+type Sent struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Type   models.NotificationType
+}
+
+// notify adds actorID to an unread notification of type t for every recipient selected by
+// recipients, creating one where there is none. recipients must select user_id, subject_id and
+// post_id. The actor is never notified of their own activity, and recipients who turned t off or
+// muted the actor are skipped, as is all activity by shadow-banned actors. When anonymous is set
+// the actor is counted but not shown.
+func (n *NotificationDB) notify(t models.NotificationType, recipients *gorm.DB, actorID uuid.UUID, anonymous bool) ([]Sent, error) {
+	// a shadow-banned actor's activity is only shown to them, so nobody is notified of it
+	if banned, err := utils.IsShadowBanned(n.db, actorID); err != nil || banned {
+		return nil, err
+	}
+	shownActor := &actorID
+	if anonymous {
+		shownActor = nil
+	}
+	var sent []Sent
+	err := n.db.Raw(`
+		WITH grouped AS (
+			INSERT INTO notifications (user_id, type, subject_id, post_id, actor_id, actor_count, created_at, updated_at)
+			SELECT r.user_id, CAST(@type AS varchar), r.subject_id, r.post_id, CAST(@shown_actor AS uuid), 1, NOW(), NOW()
+			FROM (?) AS r
+			WHERE r.user_id <> @actor
+				AND NOT EXISTS (
+					SELECT 1 FROM notification_preferences np
+					WHERE np.user_id = r.user_id AND np.type = @type AND NOT np.enabled
+				)
+				AND NOT EXISTS (
+					SELECT 1 FROM user_blocks ub
+					WHERE ub.user_id = r.user_id AND ub.target_id = @actor
+				)
+			ON CONFLICT (user_id, type, subject_id) WHERE read_at IS NULL DO UPDATE SET
+				actor_id = EXCLUDED.actor_id,
+				post_id = EXCLUDED.post_id,
+				updated_at = EXCLUDED.updated_at,
+				actor_count = notifications.actor_count + CASE WHEN EXISTS (
+					SELECT 1 FROM notification_actors na
+					WHERE na.notification_id = notifications.id AND na.actor_id = @actor
+				) THEN 0 ELSE 1 END
+			RETURNING id, user_id, type
+		), counted AS (
+			INSERT INTO notification_actors (notification_id, actor_id, created_at)
+			SELECT id, CAST(@actor AS uuid), NOW() FROM grouped
+			ON CONFLICT DO NOTHING
+		)
+		SELECT id, user_id, type FROM grouped`,
+		recipients,
+		map[string]any{"type": t, "actor": actorID, "shown_actor": shownActor},
+	).Scan(&sent).Error
+	return sent, err
+}
+
+// NotifyPostLiked notifies the post's author that actorID liked it.
+func (n *NotificationDB) NotifyPostLiked(postID uuid.UUID, actorID uuid.UUID) ([]Sent, error) {
+	recipients := n.db.Table("posts").
+		Select("posts.author_id AS user_id, posts.id AS subject_id, posts.id AS post_id").
+		Where("posts.id = ? AND posts.deleted_at IS NULL", postID)
+	return n.notify(models.NotificationPostLike, recipients, actorID, false)
+}
+
+// NotifyCommentLiked notifies the comment's author that actorID liked it.
+func (n *NotificationDB) NotifyCommentLiked(commentID uuid.UUID, actorID uuid.UUID) ([]Sent, error) {
+	recipients := n.db.Table("comments").
+		Select("comments.user_id, comments.id AS subject_id, comments.post_id").
+		Where("comments.id = ? AND comments.deleted_at IS NULL", commentID)
+	return n.notify(models.NotificationCommentLike, recipients, actorID, false)
+}
+
+// NotifyCommentCreated notifies the author of the parent comment about a reply, or the post's
+// author about a top-level comment.
+func (n *NotificationDB) NotifyCommentCreated(postID uuid.UUID, parentCommentID *uuid.UUID, actorID uuid.UUID, anonymous bool) ([]Sent, error) {
+	if parentCommentID != nil {
+		recipients := n.db.Table("comments").
+			Select("comments.user_id, comments.id AS subject_id, comments.post_id").
+			Where("comments.id = ? AND comments.deleted_at IS NULL", *parentCommentID)
+		return n.notify(models.NotificationCommentReply, recipients, actorID, anonymous)
+	}
+	recipients := n.db.Table("posts").
+		Select("posts.author_id AS user_id, posts.id AS subject_id, posts.id AS post_id").
+		Where("posts.id = ? AND posts.deleted_at IS NULL", postID)
+	return n.notify(models.NotificationPostComment, recipients, actorID, anonymous)
+}
+
+// NotifyPostCreated notifies the followers of the post's tags. A user following several of the
+// tags gets one notification, grouped under the first of those tags.
+func (n *NotificationDB) NotifyPostCreated(postID uuid.UUID, tagIDs []uuid.UUID, actorID uuid.UUID, anonymous bool) ([]Sent, error) {
+	if len(tagIDs) == 0 {
+		return nil, nil
+	}
+	recipients := n.db.Table("tag_follows AS tf").
+		Select("DISTINCT ON (tf.user_id) tf.user_id, tf.tag_id AS subject_id, CAST(? AS uuid) AS post_id", postID).
+		Where("tf.tag_id IN ? AND tf.deleted_at IS NULL", tagIDs).
+		Order("tf.user_id, tf.tag_id")
+	return n.notify(models.NotificationTagPost, recipients, actorID, anonymous)
+}
+
+// GetNotifications returns a page of the user's notifications with their latest actor, and how many
+// of all their notifications are unread.
+func (n *NotificationDB) GetNotifications(userID uuid.UUID, unreadOnly bool, page *utils.Page[time.Time]) ([]models.Notification, utils.PageCursors, int64, error) {
+	query := n.db.Model(&models.Notification{}).
+		Preload("Actor").
+		Where("notifications.user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("notifications.read_at IS NULL")
+	}
+	var notifications []models.Notification
+	if err := page.Apply(query).Find(&notifications).Error; err != nil {
+		return nil, utils.PageCursors{}, 0, err
+	}
+
+	unread, err := n.UnreadCount(userID)
+	if err != nil {
+		return nil, utils.PageCursors{}, 0, err
+	}
+	notifications, cursors := utils.Results(page, notifications, NotificationCursor)
+	return notifications, cursors, unread, nil
+}
+
+// UnreadCount returns how many of the user's notifications are unread.
+func (n *NotificationDB) UnreadCount(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := n.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks one of the user's notifications read, without moving it in the list. Activity after
+// this starts a new notification.
+func (n *NotificationDB) MarkRead(id uuid.UUID, userID uuid.UUID) (*models.Notification, error) {
+	if err := n.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		UpdateColumn("read_at", time.Now()).Error; err != nil {
+		return nil, err
+	}
+	var notification models.Notification
+	dbResponse := n.db.Preload("Actor").Where("id = ? AND user_id = ?", id, userID).First(&notification)
+	return utils.HandleDBError(&notification, dbResponse.Error)
+}
+
+// MarkAllRead marks all the user's unread notifications read and returns how many there were.
+func (n *NotificationDB) MarkAllRead(userID uuid.UUID) (int64, error) {
+	dbResponse := n.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		UpdateColumn("read_at", time.Now())
+	return dbResponse.RowsAffected, dbResponse.Error
+}
+
+// GetPreferences returns whether each notification type is on for the user.
+func (n *NotificationDB) GetPreferences(userID uuid.UUID) (map[models.NotificationType]bool, error) {
+	var rows []models.NotificationPreference
+	if err := n.db.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	preferences := make(map[models.NotificationType]bool, len(models.NotificationTypes))
+	for _, t := range models.NotificationTypes {
+		preferences[t] = true
+	}
+	for _, row := range rows {
+		preferences[row.Type] = row.Enabled
+	}
+	return preferences, nil
+}
+
+// SetPreferences saves the given types' settings for the user, leaving the other types as they were.
+func (n *NotificationDB) SetPreferences(userID uuid.UUID, preferences []models.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	for i := range preferences {
+		preferences[i].UserID = userID
+	}
+	return n.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled"}),
+	}).Create(&preferences).Error
+}
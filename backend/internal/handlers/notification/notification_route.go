@@ -0,0 +1,22 @@
+package notification
+
+import (
+	"inside-athletics/internal/events"
+	"inside-athletics/internal/utils"
+
+	"github.com/danielgtaylor/huma/v2"
+	"gorm.io/gorm"
+)
+
+func Route(api huma.API, db *gorm.DB, bus *events.Bus) {
+	notificationService := NewNotificationService(db, bus)
+	notificationService.Subscribe()
+	{
+		grp := huma.NewGroup(api, "/api/v1/notifications")
+		huma.Get(grp, "/", notificationService.GetNotifications)                                                  // List the current user's notifications
+		huma.Post(grp, "/read", notificationService.MarkAllRead, utils.Requires(utils.Authenticated))             // Mark all notifications read
+		huma.Post(grp, "/{id}/read", notificationService.MarkRead, utils.Requires(utils.Authenticated))           // Mark a notification read
+		huma.Get(grp, "/preferences", notificationService.GetPreferences)                                         // Get notification settings
+		huma.Put(grp, "/preferences", notificationService.UpdatePreferences, utils.Requires(utils.Authenticated)) // Update notification settings
+	}
+}
@@ -0,0 +1,144 @@
+package notification
+
+import (
+	"context"
+	"inside-athletics/internal/events"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"gorm.io/gorm"
+)
+
+type NotificationService struct {
+	notificationDB *NotificationDB
+	bus            *events.Bus
+}
+
+// NewNotificationService creates a new NotificationService instance that reacts to, and publishes,
+// events on bus
+func NewNotificationService(db *gorm.DB, bus *events.Bus) *NotificationService {
+	return &NotificationService{notificationDB: NewNotificationDB(db), bus: bus}
+}
+
+// Subscribe registers the handlers that turn likes, comments and tagged posts into notifications.
+func (s *NotificationService) Subscribe() {
+	events.Subscribe(s.bus, func(ctx context.Context, e events.PostLiked) error {
+		sent, err := s.notificationDB.NotifyPostLiked(e.PostID, e.UserID)
+		return s.publish(ctx, sent, err)
+	})
+	events.Subscribe(s.bus, func(ctx context.Context, e events.CommentLiked) error {
+		sent, err := s.notificationDB.NotifyCommentLiked(e.CommentID, e.UserID)
+		return s.publish(ctx, sent, err)
+	})
+	events.Subscribe(s.bus, func(ctx context.Context, e events.CommentCreated) error {
+		sent, err := s.notificationDB.NotifyCommentCreated(e.PostID, e.ParentCommentID, e.UserID, e.IsAnonymous)
+		return s.publish(ctx, sent, err)
+	})
+	events.Subscribe(s.bus, func(ctx context.Context, e events.PostCreated) error {
+		sent, err := s.notificationDB.NotifyPostCreated(e.PostID, e.TagIDs, e.AuthorID, e.IsAnonymous)
+		return s.publish(ctx, sent, err)
+	})
+}
+
+// publish publishes NotificationSent for each notification a Notify call sent, unless it failed.
+func (s *NotificationService) publish(ctx context.Context, sent []Sent, err error) error {
+	if err != nil {
+		return err
+	}
+	for _, n := range sent {
+		s.bus.Publish(ctx, events.NotificationSent{NotificationID: n.ID, UserID: n.UserID, Type: n.Type})
+	}
+	return nil
+}
+
+// GetNotifications lists the current user's notifications, most recently active first
+func (s *NotificationService) GetNotifications(ctx context.Context, input *GetNotificationsParams) (*utils.ResponseBody[GetNotificationsResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := utils.NewPage[time.Time](NotificationKeyset, input.Cursor, input.Limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	notifications, cursors, unread, err := s.notificationDB.GetNotifications(userID, input.UnreadOnly, page)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]NotificationResponse, 0, len(notifications))
+	for i := range notifications {
+		responses = append(responses, ToNotificationResponse(&notifications[i]))
+	}
+	return &utils.ResponseBody[GetNotificationsResponse]{
+		Body: &GetNotificationsResponse{
+			Notifications: responses,
+			UnreadCount:   unread,
+			PageCursors:   cursors,
+		},
+	}, nil
+}
+
+// MarkRead marks one of the current user's notifications read. Returns 404 for other users' notifications.
+func (s *NotificationService) MarkRead(ctx context.Context, input *MarkNotificationReadParams) (*utils.ResponseBody[NotificationResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	notification, err := s.notificationDB.MarkRead(input.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	response := ToNotificationResponse(notification)
+	return &utils.ResponseBody[NotificationResponse]{
+		Body: &response,
+	}, nil
+}
+
+// MarkAllRead marks all the current user's notifications read
+func (s *NotificationService) MarkAllRead(ctx context.Context, input *struct{}) (*utils.ResponseBody[MarkAllReadResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.notificationDB.MarkAllRead(userID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to mark notifications read", err)
+	}
+	return &utils.ResponseBody[MarkAllReadResponse]{
+		Body: &MarkAllReadResponse{Updated: updated},
+	}, nil
+}
+
+// GetPreferences returns the current user's setting for every notification type
+func (s *NotificationService) GetPreferences(ctx context.Context, input *struct{}) (*utils.ResponseBody[NotificationPreferencesResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := s.notificationDB.GetPreferences(userID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to get notification preferences", err)
+	}
+	return &utils.ResponseBody[NotificationPreferencesResponse]{
+		Body: toPreferencesResponse(preferences),
+	}, nil
+}
+
+// UpdatePreferences turns notification types on or off for the current user
+func (s *NotificationService) UpdatePreferences(ctx context.Context, input *UpdateNotificationPreferencesInput) (*utils.ResponseBody[NotificationPreferencesResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]models.NotificationPreference, 0, len(input.Body.Preferences))
+	for _, p := range input.Body.Preferences {
+		rows = append(rows, models.NotificationPreference{Type: p.Type, Enabled: p.Enabled})
+	}
+	if err := s.notificationDB.SetPreferences(userID, rows); err != nil {
+		return nil, huma.Error500InternalServerError("Failed to update notification preferences", err)
+	}
+	return s.GetPreferences(ctx, nil)
+}
@@ -0,0 +1,111 @@
+package notification
+
+import (
+	"fmt"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GetNotificationsParams defines the query parameters for listing the current user's notifications
+type GetNotificationsParams struct {
+	Limit      int    `query:"limit" default:"20" minimum:"1" maximum:"100" example:"20" doc:"Number of notifications to return"`
+	Cursor     string `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page"`
+	UnreadOnly bool   `query:"unread_only" default:"false" doc:"Only return unread notifications"`
+}
+
+// NotificationResponse is a notification with the message to show for it
+type NotificationResponse struct {
+	ID            uuid.UUID               `json:"id"`
+	Type          models.NotificationType `json:"type" enum:"post_like,comment_like,post_comment,comment_reply,tag_post" example:"post_like"`
+	SubjectID     uuid.UUID               `json:"subject_id" doc:"The post, comment or tag the notification is about, depending on type"`
+	PostID        *uuid.UUID              `json:"post_id,omitempty" doc:"The post to open for the notification"`
+	ActorID       *uuid.UUID              `json:"actor_id,omitempty" doc:"The latest user to act. Left out when they were anonymous"`
+	ActorUsername *string                 `json:"actor_username,omitempty" example:"suliproathelete"`
+	ActorCount    int64                   `json:"actor_count" example:"5" doc:"How many different users are grouped into the notification"`
+	Message       string                  `json:"message" example:"suliproathelete and 4 others liked your post"`
+	Read          bool                    `json:"read"`
+	CreatedAt     time.Time               `json:"created_at"`
+	UpdatedAt     time.Time               `json:"updated_at" doc:"When the latest user was grouped in"`
+}
+
+// GetNotificationsResponse defines the response for listing notifications
+type GetNotificationsResponse struct {
+	Notifications []NotificationResponse `json:"notifications" doc:"Most recently active first"`
+	UnreadCount   int64                  `json:"unread_count" example:"3" doc:"Unread notifications in total, not just on this page"`
+	utils.PageCursors
+}
+
+// MarkNotificationReadParams defines the path parameters for marking a notification read
+type MarkNotificationReadParams struct {
+	ID uuid.UUID `path:"id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the notification"`
+}
+
+// MarkAllReadResponse defines the response for marking every notification read
+type MarkAllReadResponse struct {
+	Updated int64 `json:"updated" example:"3" doc:"Number of notifications that were unread"`
+}
+
+// NotificationPreferenceBody is whether one type of notification is on
+type NotificationPreferenceBody struct {
+	Type    models.NotificationType `json:"type" enum:"post_like,comment_like,post_comment,comment_reply,tag_post" example:"tag_post"`
+	Enabled bool                    `json:"enabled"`
+}
+
+// NotificationPreferencesResponse lists the setting for every notification type
+type NotificationPreferencesResponse struct {
+	Preferences []NotificationPreferenceBody `json:"preferences"`
+}
+
+// UpdateNotificationPreferencesInput defines the request for changing notification settings
+type UpdateNotificationPreferencesInput struct {
+	Body struct {
+		Preferences []NotificationPreferenceBody `json:"preferences" doc:"Types left out keep their current setting"`
+	}
+}
+
+var actions = map[models.NotificationType]string{
+	models.NotificationPostLike:     "liked your post",
+	models.NotificationCommentLike:  "liked your comment",
+	models.NotificationPostComment:  "commented on your post",
+	models.NotificationCommentReply: "replied to your comment",
+	models.NotificationTagPost:      "posted in a tag you follow",
+}
+
+// ToNotificationResponse converts a notification, loaded with its actor, to a response
+func ToNotificationResponse(n *models.Notification) NotificationResponse {
+	response := NotificationResponse{
+		ID:         n.ID,
+		Type:       n.Type,
+		SubjectID:  n.SubjectID,
+		PostID:     n.PostID,
+		ActorID:    n.ActorID,
+		ActorCount: n.ActorCount,
+		Read:       n.ReadAt != nil,
+		CreatedAt:  n.CreatedAt,
+		UpdatedAt:  n.UpdatedAt,
+	}
+	who := "Someone"
+	if n.Actor != nil {
+		response.ActorUsername = &n.Actor.Username
+		who = n.Actor.Username
+	}
+	switch others := n.ActorCount - 1; {
+	case others == 1:
+		who += " and 1 other"
+	case others > 1:
+		who += fmt.Sprintf(" and %d others", others)
+	}
+	response.Message = who + " " + actions[n.Type]
+	return response
+}
+
+func toPreferencesResponse(preferences map[models.NotificationType]bool) *NotificationPreferencesResponse {
+	response := &NotificationPreferencesResponse{Preferences: make([]NotificationPreferenceBody, 0, len(models.NotificationTypes))}
+	for _, t := range models.NotificationTypes {
+		response.Preferences = append(response.Preferences, NotificationPreferenceBody{Type: t, Enabled: preferences[t]})
+	}
+	return response
+}
@@ -1,19 +1,23 @@
 package permission
 
 import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
 
-func Route(api huma.API, db *gorm.DB) {
-	permissionService := NewPermissionService(db)
+func Route(api huma.API, db *gorm.DB, grants *permcache.Cache) {
+	permissionService := NewPermissionService(db, grants)
 
 	{
 		grp := huma.NewGroup(api, "/api/v1/permission")
-		huma.Post(grp, "/", permissionService.CreatePermission)
+		huma.Post(grp, "/", permissionService.CreatePermission, utils.Requires(utils.Permission(models.PermissionCreate, "permission")))
 		huma.Get(grp, "/{id}", permissionService.GetPermissionByID)
-		huma.Patch(grp, "/{id}", permissionService.UpdatePermission)
-		huma.Delete(grp, "/{id}", permissionService.DeletePermission)
+		huma.Patch(grp, "/{id}", permissionService.UpdatePermission, utils.Requires(utils.Permission(models.PermissionUpdate, "permission")))
+		huma.Delete(grp, "/{id}", permissionService.DeletePermission, utils.Requires(utils.Permission(models.PermissionDelete, "permission")))
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/permissions")
@@ -3,6 +3,7 @@ package permission
 import (
 	"context"
 	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
 	"inside-athletics/internal/utils"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -12,11 +13,14 @@ import (
 
 type PermissionService struct {
 	permissionDB *PermissionDB
+	// grants are invalidated when a permission roles grant changes
+	grants *permcache.Cache
 }
 
-func NewPermissionService(db *gorm.DB) *PermissionService {
+func NewPermissionService(db *gorm.DB, grants *permcache.Cache) *PermissionService {
 	return &PermissionService{
 		permissionDB: NewPermissionDB(db),
+		grants:       grants,
 	}
 }
 
@@ -87,6 +91,7 @@ func (p *PermissionService) UpdatePermission(ctx context.Context, input *struct
 	if err != nil {
 		return nil, err
 	}
+	p.grants.InvalidateAll(ctx)
 
 	return &utils.ResponseBody[PermissionResponse]{
 		Body: ToPermissionResponse(updated),
@@ -102,6 +107,7 @@ func (p *PermissionService) DeletePermission(ctx context.Context, input *DeleteP
 	if err := p.permissionDB.DeletePermission(input.ID); err != nil {
 		return nil, err
 	}
+	p.grants.InvalidateAll(ctx)
 
 	return &utils.ResponseBody[PermissionResponse]{
 		Body: ToPermissionResponse(perm),
@@ -2,11 +2,11 @@ package post
 
 import (
 	"errors"
-	"fmt"
 	models "inside-athletics/internal/models"
+	"inside-athletics/internal/poststats"
 	"inside-athletics/internal/utils"
 	"math"
-	"strings"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
@@ -15,7 +15,8 @@ import (
 )
 
 type PostDB struct {
-	db *gorm.DB
+	db    *gorm.DB
+	stats *poststats.Store
 }
 
 var (
@@ -23,16 +24,31 @@ var (
 	ErrFreePostViewLimitReached     = errors.New("free-tier post view limit reached")
 )
 
+// POST_SELECT_QUERY selects posts with their like/comment counters (kept in post_stats) and
+// whether the user bound to ? has liked them.
 const (
 	POST_SELECT_QUERY string = `posts.*,
-            (SELECT COUNT(*) FROM post_likes WHERE post_likes.post_id = posts.id) AS like_count,
-            (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) AS comment_count,
-            (SELECT COUNT(*) > 0 FROM post_likes WHERE post_likes.post_id = posts.id AND post_likes.user_id = ?) AS is_liked`
+            COALESCE((SELECT post_stats.like_count FROM post_stats WHERE post_stats.post_id = posts.id), 0) AS like_count,
+            COALESCE((SELECT post_stats.comment_count FROM post_stats WHERE post_stats.post_id = posts.id), 0) AS comment_count,
+            EXISTS (SELECT 1 FROM post_likes WHERE post_likes.post_id = posts.id AND post_likes.user_id = ?) AS is_liked`
 )
 
+// PostKeyset pages post lists newest first.
+var PostKeyset = utils.Keyset{KeyColumn: "posts.created_at", IDColumn: "posts.id", Descending: true}
+
+// PostCursor is a post's position in PostKeyset.
+func PostCursor(post *models.Post) utils.Cursor[time.Time] {
+	return utils.Cursor[time.Time]{Key: post.CreatedAt, ID: post.ID}
+}
+
 // NewPostDB creates a new PostDB instance
 func NewPostDB(db *gorm.DB) *PostDB {
-	return &PostDB{db: db}
+	return &PostDB{db: db, stats: poststats.NewStore(db, poststats.LoadConfigFromEnv())}
+}
+
+// CheckMentions returns a 403 when a user mentioned in texts blocked the user
+func (s *PostDB) CheckMentions(userID uuid.UUID, texts ...string) error {
+	return utils.CheckMentions(s.db, userID, texts...)
 }
 
 // CreatePost creates a new post in the database
@@ -115,8 +131,10 @@ func (s *PostDB) HasUserViewedPost(userID, postID uuid.UUID) (bool, error) {
 
 // RecordPostView records that the user viewed the post (idempotent: safe to call if already viewed).
 func (s *PostDB) RecordPostView(userID, postID uuid.UUID) error {
-	v := &models.ViewedPost{UserID: userID, PostID: postID}
-	return s.db.Where("user_id = ? AND post_id = ?", userID, postID).FirstOrCreate(v).Error
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+		DoNothing: true,
+	}).Create(&models.ViewedPost{UserID: userID, PostID: postID}).Error
 }
 
 // RecordPostViewIfAllowed records a new post view while enforcing the user's distinct-view cap atomically.
@@ -204,25 +222,30 @@ func (s *PostDB) GetPostByID(id uuid.UUID, userID uuid.UUID) (*models.Post, erro
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'post'")
 		}).
+		Scopes(utils.VisibleTo(utils.PostsTable, userID)).
 		First(&post, "posts.id = ?", id)
 
 	return utils.HandleDBError(&post, dbResponse.Error)
 }
 
-// GetPostsBySportID retrieves all posts with the given sport ID
-func (s *PostDB) GetPostsBySportID(limit, offset int, sportID uuid.UUID, userID uuid.UUID) ([]models.Post, int64, error) {
+// GetPostsBySportID retrieves all posts with the given sport ID, only those by verified athletes
+// when verifiedOnly is set
+func (s *PostDB) GetPostsBySportID(page *utils.Page[time.Time], sportID uuid.UUID, verifiedOnly bool, userID uuid.UUID) ([]models.Post, utils.PageCursors, int64, error) {
 	var posts []models.Post
 	var total int64
 
 	// Count total matching posts
-	if err := s.db.Model(&models.Post{}).
-		Where("sport_id = ?", sportID).
-		Count(&total).Error; err != nil {
-		return nil, 0, err
+	if page.CountTotal() {
+		if err := s.db.Model(&models.Post{}).
+			Scopes(utils.VisibleTo(utils.PostsTable, userID), utils.VerifiedAuthors(utils.PostsTable, verifiedOnly)).
+			Where("sport_id = ?", sportID).
+			Count(&total).Error; err != nil {
+			return nil, utils.PageCursors{}, 0, err
+		}
 	}
 
 	// Get paginated results
-	if err := s.db.
+	if err := page.Apply(s.db.
 		Table("posts").
 		Select(POST_SELECT_QUERY,
 			userID).
@@ -232,30 +255,33 @@ func (s *PostDB) GetPostsBySportID(limit, offset int, sportID uuid.UUID, userID
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'post'")
 		}).
-		Where("sport_id = ?", sportID).
-		Limit(limit).
-		Offset(offset).
+		Scopes(utils.VisibleTo(utils.PostsTable, userID), utils.VerifiedAuthors(utils.PostsTable, verifiedOnly)).
+		Where("sport_id = ?", sportID)).
 		Find(&posts).Error; err != nil {
-		return nil, 0, err
+		return nil, utils.PageCursors{}, 0, err
 	}
 
-	return posts, total, nil
+	posts, cursors := utils.Results(page, posts, PostCursor)
+	return posts, cursors, total, nil
 }
 
 // GetPostByAuthorID retrieves a post by its author ID
-func (s *PostDB) GetPostsByAuthorID(limit, offset int, authorID uuid.UUID, userID uuid.UUID) ([]models.Post, int64, error) {
+func (s *PostDB) GetPostsByAuthorID(page *utils.Page[time.Time], authorID uuid.UUID, userID uuid.UUID) ([]models.Post, utils.PageCursors, int64, error) {
 	var posts []models.Post
 	var total int64
 
 	// Count total matching posts
-	if err := s.db.Model(&models.Post{}).
-		Where("author_id = ?", authorID).
-		Count(&total).Error; err != nil {
-		return nil, 0, err
+	if page.CountTotal() {
+		if err := s.db.Model(&models.Post{}).
+			Scopes(utils.VisibleTo(utils.PostsTable, userID)).
+			Where("author_id = ?", authorID).
+			Count(&total).Error; err != nil {
+			return nil, utils.PageCursors{}, 0, err
+		}
 	}
 
 	// Get paginated results
-	if err := s.db.
+	if err := page.Apply(s.db.
 		Table("posts").
 		Select(POST_SELECT_QUERY,
 			userID).
@@ -265,14 +291,14 @@ func (s *PostDB) GetPostsByAuthorID(limit, offset int, authorID uuid.UUID, userI
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'post'")
 		}).
-		Where("author_id = ?", authorID).
-		Limit(limit).
-		Offset(offset).
+		Scopes(utils.VisibleTo(utils.PostsTable, userID)).
+		Where("author_id = ?", authorID)).
 		Find(&posts).Error; err != nil {
-		return nil, 0, err
+		return nil, utils.PageCursors{}, 0, err
 	}
 
-	return posts, total, nil
+	posts, cursors := utils.Results(page, posts, PostCursor)
+	return posts, cursors, total, nil
 }
 
 // DeletePost soft deletes a post by ID
@@ -288,18 +314,21 @@ func (p *PostDB) DeletePost(id uuid.UUID) error {
 	return nil
 }
 
-// GetAllPosts retrieves all posts with pagination
-func (p *PostDB) GetAllPosts(limit int, offset int, userID uuid.UUID) ([]models.Post, int, error) {
+// GetAllPosts retrieves all posts, newest first, one page at a time. Only posts by verified
+// athletes are retrieved when verifiedOnly is set.
+func (p *PostDB) GetAllPosts(page *utils.Page[time.Time], verifiedOnly bool, userID uuid.UUID) ([]models.Post, utils.PageCursors, int, error) {
 	var posts []models.Post
 	var total int64
 
 	// Get total count
-	if err := p.db.Model(&models.Post{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+	if page.CountTotal() {
+		if err := p.db.Model(&models.Post{}).Scopes(utils.VisibleTo(utils.PostsTable, userID), utils.VerifiedAuthors(utils.PostsTable, verifiedOnly)).Count(&total).Error; err != nil {
+			return nil, utils.PageCursors{}, 0, err
+		}
 	}
 
 	// Get paginated posts
-	dbResponse := p.db.
+	dbResponse := page.Apply(p.db.
 		Table("posts").
 		Select(POST_SELECT_QUERY,
 			userID).
@@ -309,16 +338,18 @@ func (p *PostDB) GetAllPosts(limit int, offset int, userID uuid.UUID) ([]models.
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'post'")
 		}).
-		Limit(limit).
-		Offset(offset).
+		Scopes(utils.VisibleTo(utils.PostsTable, userID), utils.VerifiedAuthors(utils.PostsTable, verifiedOnly))).
 		Find(&posts)
 	if dbResponse.Error != nil {
-		return nil, 0, dbResponse.Error
+		return nil, utils.PageCursors{}, 0, dbResponse.Error
 	}
 
-	return posts, int(total), nil
+	posts, cursors := utils.Results(page, posts, PostCursor)
+	return posts, cursors, int(total), nil
 }
 
+// GetPopularPosts ranks posts by their precomputed stats (see poststats) plus boosts for what the
+// user follows and for posts younger than windowHours. Total is only counted for the first page.
 func (p *PostDB) GetPopularPosts(limit int, offset int, windowHours int, userID uuid.UUID) ([]models.Post, int, error) {
 	var posts []models.Post
 	var total int64
@@ -333,31 +364,56 @@ func (p *PostDB) GetPopularPosts(limit int, offset int, windowHours int, userID
 		windowHours = 72
 	}
 	windowHours = min(windowHours, 24*30)
-	recencyWindow := float64(windowHours)
 
-	if err := p.db.Model(&models.Post{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+	if offset == 0 {
+		if err := p.db.Model(&models.Post{}).Scopes(utils.VisibleTo(utils.PostsTable, userID)).Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
 	}
 
-	dbResponse := p.db.
+	dbResponse := p.popularityQuery(userID, windowHours).
+		Order("popularity_score DESC").
+		Order("comment_count DESC").
+		Order("like_count DESC").
+		Order("posts.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&posts)
+	if dbResponse.Error != nil {
+		return nil, 0, dbResponse.Error
+	}
+
+	for i := range posts {
+		posts[i].PopularityScore = math.Round(posts[i].PopularityScore*100) / 100
+	}
+
+	return posts, int(total), nil
+}
+
+// popularityQuery selects posts with their stats, whether userID liked them and their
+// popularity_score for userID, with everything a post response needs preloaded.
+func (p *PostDB) popularityQuery(userID uuid.UUID, windowHours int) *gorm.DB {
+	weights := p.stats.Config().Weights
+	return p.db.
 		Table("posts").
 		Select(`
 			posts.*,
-			COALESCE(all_likes.total_likes, 0) AS like_count,
-			COALESCE(all_comments.total_comments, 0) AS comment_count,
-			COALESCE(user_likes.is_liked, false) AS is_liked,
+			COALESCE(ps.like_count, 0) AS like_count,
+			COALESCE(ps.comment_count, 0) AS comment_count,
+			EXISTS (
+				SELECT 1 FROM post_likes WHERE post_likes.post_id = posts.id AND post_likes.user_id = ?
+			) AS is_liked,
 			(
-				COALESCE(recent_comments.recent_comments, 0) * 8.0 +
-				COALESCE(all_comments.total_comments, 0) * 2.0 +
-				COALESCE(recent_likes.recent_likes, 0) * 3.0 +
-				COALESCE(all_likes.total_likes, 0) * 1.0 +
+				? +
+				COALESCE(ps.comment_count, 0) * ? +
+				COALESCE(ps.like_count, 0) * ? +
 				CASE
 					WHEN EXISTS (
 						SELECT 1
 						FROM tag_follows tf
 						JOIN tag_posts tp_sub ON tp_sub.tag_id = tf.tag_id
 						WHERE tf.user_id = ? AND tp_sub.postable_id = posts.id AND tp_sub.postable_type = 'post'
-					) THEN 12.0
+					) THEN ?
 					ELSE 0.0
 				END +
 				CASE
@@ -365,7 +421,7 @@ func (p *PostDB) GetPopularPosts(limit int, offset int, windowHours int, userID
 						SELECT 1
 						FROM sport_follows sf
 						WHERE sf.user_id = ? AND sf.sport_id = posts.sport_id
-					) THEN 4.0
+					) THEN ?
 					ELSE 0.0
 				END +
 				CASE
@@ -373,67 +429,51 @@ func (p *PostDB) GetPopularPosts(limit int, offset int, windowHours int, userID
 						SELECT 1
 						FROM college_follows cf
 						WHERE cf.user_id = ? AND cf.college_id = posts.college_id
-					) THEN 2.0
+					) THEN ?
 					ELSE 0.0
 				END +
-				GREATEST(0.0, ? - (EXTRACT(EPOCH FROM (NOW() - posts.created_at)) / 3600.0)) * 0.15
+				GREATEST(0.0, ? - (EXTRACT(EPOCH FROM (NOW() - posts.created_at)) / 3600.0)) * ?
 			) AS popularity_score`,
-			userID, userID, userID, recencyWindow).
-		Joins(`
-			LEFT JOIN (
-				SELECT post_id, COUNT(*) AS total_likes
-				FROM post_likes
-				GROUP BY post_id
-			) AS all_likes ON all_likes.post_id = posts.id`).
-		Joins(`
-			LEFT JOIN (
-				SELECT post_id, COUNT(*) AS total_comments
-				FROM comments
-				GROUP BY post_id
-			) AS all_comments ON all_comments.post_id = posts.id`).
-		Joins(`
-			LEFT JOIN (
-				SELECT post_id, COUNT(*) AS recent_likes
-				FROM post_likes
-				WHERE created_at >= NOW() - (? * INTERVAL '1 hour')
-				GROUP BY post_id
-			) AS recent_likes ON recent_likes.post_id = posts.id`, windowHours).
-		Joins(`
-			LEFT JOIN (
-				SELECT post_id, COUNT(*) AS recent_comments
-				FROM comments
-				WHERE created_at >= NOW() - (? * INTERVAL '1 hour')
-				GROUP BY post_id
-			) AS recent_comments ON recent_comments.post_id = posts.id`, windowHours).
-		Joins(`
-			LEFT JOIN (
-				SELECT post_id, true AS is_liked
-				FROM post_likes
-				WHERE user_id = ?
-				GROUP BY post_id
-			) AS user_likes ON user_likes.post_id = posts.id`, userID).
+			userID,
+			p.stats.TrendingScore("ps"),
+			weights.Comment,
+			weights.Like,
+			userID, weights.TagFollow,
+			userID, weights.SportFollow,
+			userID, weights.CollegeFollow,
+			float64(windowHours), weights.RecencyPerHour).
+		Joins("LEFT JOIN post_stats AS ps ON ps.post_id = posts.id").
 		Preload("Author").
 		Preload("Sport", "id IS NOT NULL").
 		Preload("College", "id IS NOT NULL").
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'post'")
 		}).
+		Scopes(utils.VisibleTo(utils.PostsTable, userID))
+}
+
+// GetDigestPosts returns the most popular posts created since since in tags, sports or colleges
+// userID follows, leaving out the user's own posts.
+func (p *PostDB) GetDigestPosts(userID uuid.UUID, since time.Time, limit int) ([]models.Post, error) {
+	var posts []models.Post
+	windowHours := int(math.Ceil(time.Since(since).Hours()))
+	err := p.popularityQuery(userID, max(windowHours, 1)).
+		Where("posts.created_at >= ? AND posts.author_id <> ?", since, userID).
+		Where(`EXISTS (
+				SELECT 1
+				FROM tag_follows tf
+				JOIN tag_posts tp_f ON tp_f.tag_id = tf.tag_id
+				WHERE tf.user_id = ? AND tp_f.postable_id = posts.id AND tp_f.postable_type = 'post'
+			) OR EXISTS (
+				SELECT 1 FROM sport_follows sf WHERE sf.user_id = ? AND sf.sport_id = posts.sport_id
+			) OR EXISTS (
+				SELECT 1 FROM college_follows cf WHERE cf.user_id = ? AND cf.college_id = posts.college_id
+			)`, userID, userID, userID).
 		Order("popularity_score DESC").
-		Order("comment_count DESC").
-		Order("like_count DESC").
 		Order("posts.created_at DESC").
 		Limit(limit).
-		Offset(offset).
-		Find(&posts)
-	if dbResponse.Error != nil {
-		return nil, 0, dbResponse.Error
-	}
-
-	for i := range posts {
-		posts[i].PopularityScore = math.Round(posts[i].PopularityScore*100) / 100
-	}
-
-	return posts, int(total), nil
+		Find(&posts).Error
+	return posts, err
 }
 
 func min(a, b int) int {
@@ -465,89 +505,59 @@ func (p *PostDB) UpdatePost(id uuid.UUID, updates UpdatePostRequest, userID uuid
 	return utils.HandleDBError(&updatedPost, dbResponse.Error)
 }
 
-func (p *PostDB) FuzzySearchForPost(userID uuid.UUID, searchStr string, limit int, offset int) ([]models.Post, int64, error) {
+// SearchPosts runs a full-text search over post titles, content and tag names, also matching titles that
+// are only a close (typo) match for the search string. Filters narrow the results down further.
+// Results are ordered by text rank, then title similarity, and carry a highlighted snippet and title.
+func (p *PostDB) SearchPosts(userID uuid.UUID, text *utils.TextSearch, fuzzy *utils.FuzzySearch, filters utils.PostFilters, limit int, offset int) ([]models.Post, int64, error) {
 	var posts []models.Post
 	var total int64
 
-	selectQuery, whereQuery, orderQuery := utils.FuzzySearchByQueries("title", searchStr)
-	selectQuery = POST_SELECT_QUERY + "," + selectQuery
-	if err := p.db.
-		Model(&models.Post{}).
-		Select(selectQuery,
-			userID).
-		Where(whereQuery).
+	matches := clause.Expr{SQL: "(? OR ?)", Vars: []any{text.Matches(), fuzzy.Matches()}}
+
+	if err := filters.Apply(p.db.Model(&models.Post{}).Scopes(utils.VisibleTo(utils.PostsTable, userID)).Where(matches), "posts", "post").
+		Count(&total).Error; err != nil {
+		return posts, 0, err
+	}
+	if total == 0 {
+		return []models.Post{}, 0, nil
+	}
+
+	if err := filters.Apply(p.db.Model(&models.Post{}).Scopes(utils.VisibleTo(utils.PostsTable, userID)), "posts", "post").
+		Select(POST_SELECT_QUERY+`,
+            ? AS snippet,
+            ? AS title_highlight`,
+			userID,
+			text.Headline("posts.content", utils.SnippetHeadlineOptions),
+			text.Headline("posts.title", utils.HighlightHeadlineOptions)).
+		Where(matches).
 		Preload("Author").
 		Preload("Sport", "id IS NOT NULL").
 		Preload("College", "id IS NOT NULL").
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'post'")
 		}).
-		Order(orderQuery).
-		Count(&total).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "? DESC, ? DESC, posts.created_at DESC", Vars: []any{text.Rank(), fuzzy.Score()}}}).
 		Limit(limit).
 		Offset(offset).
 		Find(&posts).Error; err != nil {
 		return posts, 0, err
 	}
 
-	if total == 0 {
-		return []models.Post{}, 0, nil
-	}
-
 	return posts, total, nil
 }
 
-func (p *PostDB) FilterPosts(userId uuid.UUID, colleges []uuid.UUID, sports []uuid.UUID, tags []uuid.UUID, limit int, offset int) ([]models.Post, int64, error) {
+// FilterPosts returns posts in any of the given colleges, sports or tags, newest first
+func (p *PostDB) FilterPosts(userId uuid.UUID, filters utils.PostFilters, limit int, offset int) ([]models.Post, int64, error) {
 	var posts []models.Post
 	var total int64
 
-	filters := make([]string, 0)
-	if len(colleges) > 0 {
-		mappedColleges := utils.MapList(colleges, func(c uuid.UUID) string { return fmt.Sprintf("posts.college_id = '%s'", c.String()) })
-		collegeQuery := strings.Join(mappedColleges, " OR ")
-		filters = append(filters, collegeQuery)
-	}
-
-	if len(sports) > 0 {
-		mappedSports := utils.MapList(sports, func(c uuid.UUID) string { return fmt.Sprintf("posts.sport_id = '%s'", c.String()) })
-		sportQuery := strings.Join(mappedSports, " OR ")
-		filters = append(filters, sportQuery)
-	}
-
-	if len(tags) > 0 {
-		mappedTags := utils.MapList(tags, func(c uuid.UUID) string { return fmt.Sprintf("tag_posts.tag_id = '%s'", c.String()) })
-		tagQuery := strings.Join(mappedTags, " OR ")
-		filters = append(filters, tagQuery)
-	}
-
-	whereQuery := strings.Join(filters, " OR ")
-
-	// get the count
-	countResult := p.db.Model(&models.Post{}).Where(whereQuery)
-
-	if len(tags) > 0 {
-		countResult = countResult.Joins("JOIN tag_posts ON posts.id = tag_posts.postable_id AND tag_posts.postable_type = 'post'")
-	}
-
-	countResult = countResult.
-		Group("posts.id").
-		Count(&total)
-
-	if countResult.Error != nil {
-		return nil, 0, countResult.Error
-	}
-
-	results := p.db.
-		Table("posts").
-		Select(POST_SELECT_QUERY, userId)
-
-	if len(tags) > 0 {
-		results = results.Joins("JOIN tag_posts ON posts.id = tag_posts.postable_id AND tag_posts.postable_type = 'post'")
+	if err := filters.Apply(p.db.Model(&models.Post{}).Scopes(utils.VisibleTo(utils.PostsTable, userId)), "posts", "post").
+		Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
-	if err := results.
-		Where(whereQuery).
-		Group("posts.id").
+	if err := filters.Apply(p.db.Model(&models.Post{}).Scopes(utils.VisibleTo(utils.PostsTable, userId)), "posts", "post").
+		Select(POST_SELECT_QUERY, userId).
 		Preload("Author", "id IS NOT NULL").
 		Preload("Sport", "id IS NOT NULL").
 		Preload("College", "id IS NOT NULL").
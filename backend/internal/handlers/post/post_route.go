@@ -1,22 +1,25 @@
 package post
 
 import (
+	"inside-athletics/internal/events"
 	"inside-athletics/internal/handlers/user"
+	"inside-athletics/internal/models"
 	"inside-athletics/internal/s3"
+	"inside-athletics/internal/utils"
 
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
 
-func Route(api huma.API, db *gorm.DB, s3Svc *s3.Service) {
+func Route(api huma.API, db *gorm.DB, s3Svc *s3.Service, bus *events.Bus) {
 	userDB := user.NewUserDB(db)
-	postService := NewPostService(db, userDB, s3Svc)
+	postService := NewPostService(db, userDB, s3Svc, bus)
 	{
 		grp := huma.NewGroup(api, "/api/v1/post")
-		huma.Post(grp, "/", postService.CreatePost)       // Create post
-		huma.Get(grp, "/{id}", postService.GetPostByID)   // Read post by ID
-		huma.Patch(grp, "/{id}", postService.UpdatePost)  // Update post
-		huma.Delete(grp, "/{id}", postService.DeletePost) // Delete post
+		huma.Post(grp, "/", postService.CreatePost, utils.Requires(utils.Permission(models.PermissionCreate, "post"))) // Create post
+		huma.Get(grp, "/{id}", postService.GetPostByID)                                                                // Read post by ID
+		huma.Patch(grp, "/{id}", postService.UpdatePost, utils.Requires(postAccess(models.PermissionUpdate)))          // Update post
+		huma.Delete(grp, "/{id}", postService.DeletePost, utils.Requires(postAccess(models.PermissionDelete)))         // Delete post
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/posts")
@@ -24,7 +27,15 @@ func Route(api huma.API, db *gorm.DB, s3Svc *s3.Service) {
 		huma.Get(grp, "/popular", postService.GetPopularPosts)                 // Read popular posts
 		huma.Get(grp, "/by-sport/{sport_id}", postService.GetPostBySportID)    // Read posts by sport id
 		huma.Get(grp, "/by-author/{author_id}", postService.GetPostByAuthorID) // Read posts by author id
-		huma.Get(grp, "/search", postService.FuzzySearchForPost)               // Find all posts based on title for given search string
+		huma.Get(grp, "/search", postService.SearchPosts)                      // Full-text search over post titles, content and tags
 		huma.Get(grp, "/filter", postService.FilterPosts)                      // Filter for posts based on college, sport, and tags
 	}
 }
+
+// postAccess is what changing a post requires: authors need the _own action, and moderators
+// scoped to the post's sport or college may act on it too.
+func postAccess(action models.PermissionAction) utils.Access {
+	return utils.Permission(action, "post").
+		OwnedBy(utils.AuthorOf(utils.PostsTable)).
+		InScopes(utils.ScopesOfContent(utils.PostsTable))
+}
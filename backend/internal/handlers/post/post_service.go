@@ -4,13 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"inside-athletics/internal/events"
 	"inside-athletics/internal/handlers/tagpost"
 	"inside-athletics/internal/handlers/user"
 	models "inside-athletics/internal/models"
 	"inside-athletics/internal/s3"
 	"inside-athletics/internal/utils"
-	"regexp"
-	"strings"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
@@ -33,15 +33,17 @@ type PostService struct {
 	tagPostDB *tagpost.TagPostDB
 	userDB    *user.UserDB
 	s3        *s3.Service
+	events    events.Publisher
 }
 
 // NewPostService creates a new PostService instance.
-func NewPostService(db *gorm.DB, userDB *user.UserDB, s3Svc *s3.Service) *PostService {
+func NewPostService(db *gorm.DB, userDB *user.UserDB, s3Svc *s3.Service, publisher events.Publisher) *PostService {
 	return &PostService{
 		postDB:    NewPostDB(db),
 		tagPostDB: tagpost.NewTagPostDB(db),
 		userDB:    userDB,
 		s3:        s3Svc,
+		events:    publisher,
 	}
 }
 
@@ -56,21 +58,22 @@ func (s *PostService) CreatePost(ctx context.Context, input *struct{ Body Create
 		return nil, err
 	}
 
-	isFree, err := s.userDB.HasRole(id, models.RoleUser)
+	// users keep the free "user" role when they subscribe, so the free tier is everyone without premium
+	hasPremium, err := s.userDB.HasRole(id, models.PremiumRoles...)
 	if err != nil {
 		return nil, err
 	}
-	if isFree {
-		return s.createPost(id, input, true)
-	}
-
-	return s.createPost(id, input, false)
+	return s.createPost(ctx, id, input, !hasPremium)
 }
 
-func (s *PostService) createPost(id uuid.UUID, input *struct{ Body CreatePostRequest }, enforceFreeTierLimit bool) (*utils.ResponseBody[CreatePostResponse], error) {
+func (s *PostService) createPost(ctx context.Context, id uuid.UUID, input *struct{ Body CreatePostRequest }, enforceFreeTierLimit bool) (*utils.ResponseBody[CreatePostResponse], error) {
 	if len(input.Body.Tags) == 0 && input.Body.SportId == nil && input.Body.CollegeId == nil {
 		return nil, huma.Error400BadRequest("Need to have at least a single tag on a post")
 	}
+	err := s.postDB.CheckMentions(id, input.Body.Title, input.Body.Content)
+	if err != nil {
+		return nil, err
+	}
 	post := &models.Post{
 		AuthorID:    id,
 		SportID:     input.Body.SportId,
@@ -80,10 +83,7 @@ func (s *PostService) createPost(id uuid.UUID, input *struct{ Body CreatePostReq
 		IsAnonymous: input.Body.IsAnonymous,
 	}
 
-	var (
-		createdPost *models.Post
-		err         error
-	)
+	var createdPost *models.Post
 	if enforceFreeTierLimit {
 		createdPost, err = s.postDB.CreatePostWithAuthorLimit(post, input.Body.Tags, FreeUserMaxPosts)
 	} else {
@@ -97,6 +97,16 @@ func (s *PostService) createPost(id uuid.UUID, input *struct{ Body CreatePostReq
 		}
 		return nil, err
 	}
+	tagIDs := make([]uuid.UUID, 0, len(input.Body.Tags))
+	for _, tag := range input.Body.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	s.events.Publish(ctx, events.PostCreated{
+		PostID:      createdPost.ID,
+		AuthorID:    id,
+		TagIDs:      tagIDs,
+		IsAnonymous: createdPost.IsAnonymous,
+	})
 
 	return &utils.ResponseBody[CreatePostResponse]{
 		Body: ToCreatePostResponse(createdPost, id),
@@ -109,7 +119,11 @@ func (s *PostService) GetAllPosts(ctx context.Context, input *GetAllPostsParams)
 	if err != nil {
 		return nil, err
 	}
-	posts, total, err := s.postDB.GetAllPosts(input.Limit, input.Offset, userID)
+	page, err := utils.NewPage[time.Time](PostKeyset, input.Cursor, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	posts, cursors, total, err := s.postDB.GetAllPosts(page, input.VerifiedOnly, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -122,8 +136,9 @@ func (s *PostService) GetAllPosts(ctx context.Context, input *GetAllPostsParams)
 
 	return &utils.ResponseBody[GetAllPostsResponse]{
 		Body: &GetAllPostsResponse{
-			Posts: postResponses,
-			Total: int(total),
+			Posts:       postResponses,
+			Total:       int(total),
+			PageCursors: cursors,
 		},
 	}, nil
 }
@@ -162,6 +177,9 @@ func (s *PostService) UpdatePost(ctx context.Context, input *struct {
 	if err != nil {
 		return nil, err
 	}
+	if err := s.postDB.CheckMentions(userID, utils.SetTexts(input.Body.Title, input.Body.Content)...); err != nil {
+		return nil, err
+	}
 	updatedPost, err := s.postDB.UpdatePost(input.ID, input.Body, userID)
 	if err != nil {
 		return nil, err
@@ -183,17 +201,20 @@ func (s *PostService) GetPostByID(ctx context.Context, input *GetPostByIDParams)
 		return nil, err
 	}
 
-	isFree, err := s.userDB.HasRole(userID, models.RoleUser)
+	hasPremium, err := s.userDB.HasRole(userID, models.PremiumRoles...)
 	if err != nil {
 		return nil, err
 	}
-	if isFree {
+	if !hasPremium {
 		if err := s.postDB.RecordPostViewIfAllowed(userID, input.ID, FreeUserMaxPostViews); err != nil {
 			if errors.Is(err, ErrFreePostViewLimitReached) {
 				return nil, huma.Error403Forbidden(freePostViewLimitMessage)
 			}
 			return nil, err
 		}
+	} else if err := s.postDB.RecordPostView(userID, input.ID); err != nil {
+		// premium views aren't capped, they are only recorded so the feed can skip them
+		return nil, err
 	}
 
 	s.resolvePostKeys(ctx, post)
@@ -207,7 +228,11 @@ func (s *PostService) GetPostBySportID(ctx context.Context, input *GetPostsBySpo
 	if err != nil {
 		return nil, err
 	}
-	posts, total, err := s.postDB.GetPostsBySportID(input.Limit, input.Offset, input.SportId, userID)
+	page, err := utils.NewPage[time.Time](PostKeyset, input.Cursor, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	posts, cursors, total, err := s.postDB.GetPostsBySportID(page, input.SportId, input.VerifiedOnly, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -220,8 +245,9 @@ func (s *PostService) GetPostBySportID(ctx context.Context, input *GetPostsBySpo
 
 	return &utils.ResponseBody[GetPostsBySportIDResponse]{
 		Body: &GetPostsBySportIDResponse{
-			Posts: postResponses,
-			Total: int(total),
+			Posts:       postResponses,
+			Total:       int(total),
+			PageCursors: cursors,
 		},
 	}, nil
 }
@@ -231,7 +257,11 @@ func (s *PostService) GetPostByAuthorID(ctx context.Context, input *GetPostsByAu
 	if err != nil {
 		return nil, err
 	}
-	posts, total, err := s.postDB.GetPostsByAuthorID(input.Limit, input.Offset, input.AuthorID, userID)
+	page, err := utils.NewPage[time.Time](PostKeyset, input.Cursor, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	posts, cursors, total, err := s.postDB.GetPostsByAuthorID(page, input.AuthorID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -244,8 +274,9 @@ func (s *PostService) GetPostByAuthorID(ctx context.Context, input *GetPostsByAu
 
 	return &utils.ResponseBody[GetPostsByAuthorIDResponse]{
 		Body: &GetPostsByAuthorIDResponse{
-			Posts: postResponses,
-			Total: int(total),
+			Posts:       postResponses,
+			Total:       int(total),
+			PageCursors: cursors,
 		},
 	}, nil
 }
@@ -272,13 +303,19 @@ func (s *PostService) DeletePost(ctx context.Context, input *struct {
 	}, err
 }
 
-func (s *PostService) FuzzySearchForPost(ctx context.Context, input *GetSearchParam) (*utils.ResponseBody[GetSearchResponse], error) {
+func (s *PostService) SearchPosts(ctx context.Context, input *GetSearchParam) (*utils.ResponseBody[GetSearchResponse], error) {
 	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return utils.HandleDBError(&utils.ResponseBody[GetSearchResponse]{}, err)
 	}
-	searchStr := input.SearchStr
-	posts, total, err := s.postDB.FuzzySearchForPost(userID, searchStr, input.Limit, input.Offset)
+	filters, err := utils.ParsePostFilters(input.CollegeIds, input.SportIds, input.TagIds)
+	if err != nil {
+		return nil, err
+	}
+	filters.VerifiedOnly = input.VerifiedOnly
+	text := utils.NewTextSearch(input.SearchStr, "posts.search_vector")
+	fuzzy := utils.NewFuzzySearch(input.SearchStr, utils.Column("posts.title")).WithThreshold(input.Threshold)
+	posts, total, err := s.postDB.SearchPosts(userID, text, fuzzy, filters, input.Limit, input.Offset)
 	if err != nil {
 		return utils.HandleDBError(&utils.ResponseBody[GetSearchResponse]{}, err)
 	}
@@ -301,39 +338,13 @@ func (s *PostService) FilterPosts(ctx context.Context, input *GetFilterPostsPara
 	if err != nil {
 		return nil, err
 	}
-	uuidPattern := `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`
-	fullPattern := fmt.Sprintf(`^%s(?:,%s)*$`, uuidPattern, uuidPattern)
-	re := regexp.MustCompile(fullPattern)
-
-	// input validation
-	if input.CollegeIds != "" && !re.MatchString(input.CollegeIds) {
-		return nil, huma.Error400BadRequest("Expected comma seperated list of uuids with no spaces for college input uuid,uuid")
-	}
-	if input.SportIds != "" && !re.MatchString(input.SportIds) {
-		return nil, huma.Error400BadRequest("Expected comma seperated list of uuids with no spaces for sport input uuid,uuid")
-	}
-	if input.TagIds != "" && !re.MatchString(input.TagIds) {
-		return nil, huma.Error400BadRequest("Expected comma seperated list of uuids with no spaces for tag input uuid,uuid")
-	}
-	mapUUID := func(id string) uuid.UUID {
-		parsedId, _ := uuid.Parse(id)
-		return parsedId
-	}
-
-	collegeIds := []uuid.UUID{}
-	sportIds := []uuid.UUID{}
-	tagIds := []uuid.UUID{}
-	if input.CollegeIds != "" {
-		collegeIds = utils.MapList(strings.Split(input.CollegeIds, ","), mapUUID)
-	}
-	if input.SportIds != "" {
-		sportIds = utils.MapList(strings.Split(input.SportIds, ","), mapUUID)
-	}
-	if input.TagIds != "" {
-		tagIds = utils.MapList(strings.Split(input.TagIds, ","), mapUUID)
+	filters, err := utils.ParsePostFilters(input.CollegeIds, input.SportIds, input.TagIds)
+	if err != nil {
+		return nil, err
 	}
+	filters.VerifiedOnly = input.VerifiedOnly
 
-	posts, total, err := s.postDB.FilterPosts(userID, collegeIds, sportIds, tagIds, input.Limit, input.Offset)
+	posts, total, err := s.postDB.FilterPosts(userID, filters, input.Limit, input.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ import (
 
 	"inside-athletics/internal/handlers/user"
 	models "inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
 
 	"github.com/google/uuid"
 )
@@ -37,19 +38,22 @@ type CreatePostResponse struct {
 
 // PostResponse defines the response structure for a post
 type PostResponse struct {
-	ID                uuid.UUID            `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ID                uuid.UUID             `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	Author            *user.GetUserResponse `json:"author"`
-	Sport             *models.Sport        `json:"sport" type:"sport"`
-	College           *models.College `json:"college" type:"college"`
-	Tags              []models.Tag    `json:"tags" type:"tag"`
-	Title             string          `json:"title" example:"Looking for thoughts on NEU Fencing!" gorm:"type:varchar(100);not null" validate:"required,min=1,max=100"`
-	Content           string          `json:"content" example:"My name is Bob Joe and I am a rising senior who just got into NEU. What is the fencing program like? Are they competitive?" gorm:"type:varchar(5000);not null" validate:"required,min=1,max=5000"`
-	LikeCount         int64           `json:"like_count,omitempty" example:"20000" gorm:"type:int"`
-	CommentCount      int64           `json:"comment_count,omitempty" example:"20" gorm:"type:int"`
-	IsLiked           bool            `json:"is_liked,omitempty" example:"true" gorm:"type:bool"`
-	IsAnonymous       bool            `json:"is_anonymous"`
-	IsVerifiedAthlete bool            `json:"is_verified_athlete"`
-	PopularityScore   float64         `json:"popularity_score,omitempty" example:"42.5"`
+	Sport             *models.Sport         `json:"sport" type:"sport"`
+	College           *models.College       `json:"college" type:"college"`
+	Tags              []models.Tag          `json:"tags" type:"tag"`
+	Title             string                `json:"title" example:"Looking for thoughts on NEU Fencing!" gorm:"type:varchar(100);not null" validate:"required,min=1,max=100"`
+	Content           string                `json:"content" example:"My name is Bob Joe and I am a rising senior who just got into NEU. What is the fencing program like? Are they competitive?" gorm:"type:varchar(5000);not null" validate:"required,min=1,max=5000"`
+	LikeCount         int64                 `json:"like_count,omitempty" example:"20000" gorm:"type:int"`
+	CommentCount      int64                 `json:"comment_count,omitempty" example:"20" gorm:"type:int"`
+	IsLiked           bool                  `json:"is_liked,omitempty" example:"true" gorm:"type:bool"`
+	IsAnonymous       bool                  `json:"is_anonymous"`
+	IsVerifiedAthlete bool                  `json:"is_verified_athlete"`
+	AuthorTrust       *utils.AuthorTrust    `json:"author_trust" doc:"How far to trust the author on this post's college and sport"`
+	PopularityScore   float64               `json:"popularity_score,omitempty" example:"42.5"`
+	Snippet           string                `json:"snippet,omitempty" example:"What is the <mark>fencing</mark> program like?" doc:"HTML escaped content excerpt with search matches wrapped in <mark> tags, only set on search results"`
+	TitleHighlight    string                `json:"title_highlight,omitempty" example:"Looking for thoughts on NEU <mark>Fencing</mark>!" doc:"HTML escaped title with search matches wrapped in <mark> tags, only set on search results"`
 }
 
 // GetPostByIDParams defines parameters for getting a post by ID
@@ -72,23 +76,28 @@ type GetPostByTagIDResponse struct {
 type GetPostsByAuthorIDParams struct {
 	AuthorID uuid.UUID `path:"author_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the author"`
 	Limit    int       `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
-	Offset   int       `query:"offset" default:"0" example:"0" doc:"Number of posts to skip"`
+	Cursor   string    `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page. Takes precedence over offset"`
+	Offset   int       `query:"offset" default:"0" example:"0" deprecated:"true" doc:"Number of posts to skip. Deprecated: use cursor"`
 }
 
 type GetPostsByAuthorIDResponse struct {
 	Posts []PostResponse `json:"posts" doc:"List of posts for the author"`
-	Total int            `json:"total" example:"25" doc:"Total number of posts for this author"`
+	Total int            `json:"total" example:"25" doc:"Total number of posts for this author. Not counted for pages requested with a cursor (0)"`
+	utils.PageCursors
 }
 
 type GetPostsBySportIDParams struct {
-	SportId uuid.UUID `path:"sport_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000" doc:"Sport ID to filter posts"`
-	Limit   int       `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
-	Offset  int       `query:"offset" default:"0" example:"0" doc:"Number of posts to skip"`
+	SportId      uuid.UUID `path:"sport_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000" doc:"Sport ID to filter posts"`
+	Limit        int       `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
+	Cursor       string    `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page. Takes precedence over offset"`
+	Offset       int       `query:"offset" default:"0" example:"0" deprecated:"true" doc:"Number of posts to skip. Deprecated: use cursor"`
+	VerifiedOnly bool      `query:"verified_only" default:"false" example:"true" doc:"Only return posts by verified athletes"`
 }
 
 type GetPostsBySportIDResponse struct {
 	Posts []PostResponse `json:"posts" doc:"List of posts for the sport"`
-	Total int            `json:"total" example:"25" doc:"Total number of posts for this sport"`
+	Total int            `json:"total" example:"25" doc:"Total number of posts for this sport. Not counted for pages requested with a cursor (0)"`
+	utils.PageCursors
 }
 
 type GetPostsByCollegeIDParams struct {
@@ -104,25 +113,28 @@ type GetPostsByCollegeIDResponse struct {
 
 // GetAllPostsParams defines query parameters for getting all posts
 type GetAllPostsParams struct {
-	Limit  int `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
-	Offset int `query:"offset" default:"0" example:"0" doc:"Number of posts to skip"`
+	Limit        int    `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
+	Cursor       string `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page. Takes precedence over offset"`
+	Offset       int    `query:"offset" default:"0" example:"0" deprecated:"true" doc:"Number of posts to skip. Deprecated: use cursor"`
+	VerifiedOnly bool   `query:"verified_only" default:"false" example:"true" doc:"Only return posts by verified athletes"`
 }
 
 // GetAllPostsResponse defines the response for getting all posts
 type GetAllPostsResponse struct {
 	Posts []PostResponse `json:"posts" doc:"List of posts"`
-	Total int            `json:"total" example:"100" doc:"Total number of posts"`
+	Total int            `json:"total" example:"100" doc:"Total number of posts. Not counted for pages requested with a cursor (0)"`
+	utils.PageCursors
 }
 
 type GetPopularPostsParams struct {
 	Limit       int `query:"limit" default:"20" example:"20" doc:"Number of posts to return"`
 	Offset      int `query:"offset" default:"0" example:"0" doc:"Number of posts to skip"`
-	WindowHours int `query:"window_hours" default:"72" example:"72" doc:"Rolling window used for rapid-engagement scoring"`
+	WindowHours int `query:"window_hours" default:"72" example:"72" doc:"Posts younger than this many hours get a recency boost"`
 }
 
 type GetPopularPostsResponse struct {
 	Posts []PostResponse `json:"posts" doc:"List of popular posts"`
-	Total int            `json:"total" example:"100" doc:"Total number of posts considered"`
+	Total int            `json:"total" example:"100" doc:"Total number of posts considered. Only counted for the first page (offset 0)"`
 }
 
 // UpdatePostRequest defines the request body for updating a post (all fields optional)
@@ -167,7 +179,10 @@ func ToPostResponse(post *models.Post, id uuid.UUID) *PostResponse {
 		LikeCount:         post.LikeCount,
 		CommentCount:      post.CommentCount,
 		PopularityScore:   post.PopularityScore,
+		Snippet:           post.Snippet,
+		TitleHighlight:    post.TitleHighlight,
 		IsVerifiedAthlete: post.Author.Verified_Athlete_Status == models.VerifiedAthleteStatusVerified,
+		AuthorTrust:       utils.TrustOf(&post.Author, post.CollegeID, post.SportID, author == nil),
 	}
 }
 
@@ -196,9 +211,14 @@ type DeletePostResponse struct {
 }
 
 type GetSearchParam struct {
-	SearchStr string `query:"search_str" binding:"required" example:"Northeastern University" doc:"String to fuzzy search posts/tags/colleges/sports on"`
-	Limit     int    `query:"limit" default:"20" example:"10" doc:"Cap on the number of posts to return"`
-	Offset    int    `query:"offset" default:"0" example:"8" doc:"Number of entries to skip for pagination"`
+	SearchStr    string  `query:"search_str" binding:"required" example:"fencing recruiting" doc:"Text to search post titles, content and tag names for. Supports \"quoted phrases\", or, and -excluded words"`
+	CollegeIds   string  `query:"college_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of college_ids to filter by"`
+	SportIds     string  `query:"sport_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of sport_ids to filter by"`
+	TagIds       string  `query:"tag_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of tag_ids to filter by"`
+	Limit        int     `query:"limit" default:"20" example:"10" doc:"Cap on the number of posts to return"`
+	Offset       int     `query:"offset" default:"0" example:"8" doc:"Number of entries to skip for pagination"`
+	Threshold    float64 `query:"threshold" default:"0.3" minimum:"0" maximum:"1" example:"0.3" doc:"Minimum title similarity (0-1) for a post that does not match the text search to still be returned"`
+	VerifiedOnly bool    `query:"verified_only" default:"false" example:"true" doc:"Only return posts by verified athletes"`
 }
 
 type GetSearchResponse struct {
@@ -207,9 +227,10 @@ type GetSearchResponse struct {
 }
 
 type GetFilterPostsParams struct {
-	CollegeIds string `query:"college_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of college_ids to filter by"`
-	SportIds   string `query:"sport_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of sport_ids to filter by"`
-	TagIds     string `query:"tag_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of tag_ids to filter by"`
-	Limit      int    `query:"limit" default:"20" example:"20" doc:"Number of posts to return when filtering"`
-	Offset     int    `query:"offset" default:"0" example:"8" doc:"Number of entries in the database to offset by"`
+	CollegeIds   string `query:"college_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of college_ids to filter by"`
+	SportIds     string `query:"sport_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of sport_ids to filter by"`
+	TagIds       string `query:"tag_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of tag_ids to filter by"`
+	Limit        int    `query:"limit" default:"20" example:"20" doc:"Number of posts to return when filtering"`
+	Offset       int    `query:"offset" default:"0" example:"8" doc:"Number of entries in the database to offset by"`
+	VerifiedOnly bool   `query:"verified_only" default:"false" example:"true" doc:"Only return posts by verified athletes"`
 }
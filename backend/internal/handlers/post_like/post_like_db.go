@@ -2,6 +2,7 @@ package post_like
 
 import (
 	"inside-athletics/internal/models"
+	"inside-athletics/internal/poststats"
 	"inside-athletics/internal/utils"
 
 	"github.com/google/uuid"
@@ -10,7 +11,13 @@ import (
 )
 
 type PostLikeDB struct {
-	db *gorm.DB
+	db    *gorm.DB
+	stats *poststats.Store
+}
+
+// Creates a new PostLikeDB instance
+func NewPostLikeDB(db *gorm.DB) *PostLikeDB {
+	return &PostLikeDB{db: db, stats: poststats.NewStore(db, poststats.LoadConfigFromEnv())}
 }
 
 // Retrieves a post like given an ID
@@ -20,24 +27,40 @@ func (u *PostLikeDB) GetPostLike(id uuid.UUID) (*models.PostLike, error) {
 	return utils.HandleDBError(&like, dbResponse.Error)
 }
 
-// Creates a new like on a post in the database
+// Creates a new like on a post in the database and counts it in the post's stats
 func (u *PostLikeDB) CreatePostLike(postLike *models.PostLike) (*models.PostLike, bool, error) {
-	dbResponse := u.db.Clauses(
-		clause.OnConflict{
-			Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
-			DoNothing: true,
-		},
-		clause.Returning{},
-	).Create(postLike)
-	if dbResponse.Error != nil {
-		return nil, false, dbResponse.Error
+	inserted := false
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		dbResponse := tx.Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+				DoNothing: true,
+			},
+			clause.Returning{},
+		).Create(postLike)
+		if dbResponse.Error != nil {
+			return dbResponse.Error
+		}
+		if dbResponse.RowsAffected == 0 {
+			return nil
+		}
+		inserted = true
+		return u.stats.Record(tx, postLike.PostID, poststats.Like, postLike.CreatedAt, 1)
+	})
+	if err != nil {
+		return nil, false, err
 	}
-	if dbResponse.RowsAffected == 0 {
+	if !inserted {
 		return nil, false, nil
 	}
 	return postLike, true, nil
 }
 
+// IsBlockedByAuthor reports whether the post's author blocked userID
+func (u *PostLikeDB) IsBlockedByAuthor(postID, userID uuid.UUID) (bool, error) {
+	return utils.BlockedByAuthor(u.db, utils.PostsTable, postID, userID)
+}
+
 // / Permanently deletes a like by ID
 func (u *PostLikeDB) DeletePostLike(PostID uuid.UUID, userID uuid.UUID) (postID uuid.UUID, err error) {
 	var like models.PostLike
@@ -46,16 +69,25 @@ func (u *PostLikeDB) DeletePostLike(PostID uuid.UUID, userID uuid.UUID) (postID
 		_, handleErr := utils.HandleDBError(&like, err)
 		return uuid.Nil, handleErr
 	}
-	result := u.db.Delete(&like)
-	if result.Error != nil {
-		return uuid.Nil, result.Error
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&like)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return u.stats.Record(tx, like.PostID, poststats.Like, like.CreatedAt, -1)
+	})
+	if err != nil {
+		return uuid.Nil, err
 	}
 	return like.PostID, nil
 }
 
 // Returns like count for the post and whether the given user has liked it. If userID is zero, liked is false.
 func (u *PostLikeDB) GetPostLikeInfo(postID, userID uuid.UUID) (count int64, liked bool, err error) {
-	err = u.db.Model(&models.PostLike{}).Where("post_id = ?", postID).Count(&count).Error
+	err = u.db.Model(&models.PostStats{}).Select("like_count").Where("post_id = ?", postID).Scan(&count).Error
 	if err != nil {
 		return 0, false, err
 	}
@@ -1,18 +1,22 @@
 package post_like
 
 import (
+	"inside-athletics/internal/events"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
 
-func Route(api huma.API, db *gorm.DB) {
-	var postLikeDB = &PostLikeDB{db: db}
-	var postLikeService = &PostLikeService{postLikeDB}
+func Route(api huma.API, db *gorm.DB, bus *events.Bus) {
+	var postLikeDB = NewPostLikeDB(db)
+	var postLikeService = &PostLikeService{postLikeDB: postLikeDB, events: bus}
 	{
 		grp := huma.NewGroup(api, "/api/v1/post/like")
-		huma.Post(grp, "", postLikeService.CreatePostLike)                // Create like
-		huma.Get(grp, "/{id}", postLikeService.GetPostLike)                // Get like by ID
-		huma.Delete(grp, "/{id}", postLikeService.DeletePostLike)          // Delete like
-		huma.Get(grp, "/{post_id}/likes", postLikeService.GetPostLikeInfo) // Like count and whether user liked
+		huma.Post(grp, "", postLikeService.CreatePostLike, utils.Requires(utils.Permission(models.PermissionCreate, "like")))        // Create like
+		huma.Get(grp, "/{id}", postLikeService.GetPostLike)                                                                          // Get like by ID
+		huma.Delete(grp, "/{id}", postLikeService.DeletePostLike, utils.Requires(utils.Permission(models.PermissionDelete, "like"))) // Delete like
+		huma.Get(grp, "/{post_id}/likes", postLikeService.GetPostLikeInfo)                                                           // Like count and whether user liked
 	}
 }
@@ -2,6 +2,7 @@ package post_like
 
 import (
 	"context"
+	"inside-athletics/internal/events"
 	models "inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
 
@@ -10,6 +11,7 @@ import (
 
 type PostLikeService struct {
 	postLikeDB *PostLikeDB
+	events     events.Publisher
 }
 
 // Retrieves a like by ID.
@@ -26,13 +28,21 @@ func (u *PostLikeService) GetPostLike(ctx context.Context, input *GetPostLikePar
 	}, nil
 }
 
-// Creates a like on a post. Returns 409 if the user has already liked the post.
+// Creates a like on a post. Returns 409 if the user has already liked the post and 403 if its
+// author blocked them.
 // Response includes total likes on the post and liked=true for the requesting user.
 func (u *PostLikeService) CreatePostLike(ctx context.Context, input *CreatePostLikeInput) (*utils.ResponseBody[CreatePostLikeResponse], error) {
 	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
+	blocked, err := u.postLikeDB.IsBlockedByAuthor(input.Body.PostID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, huma.Error403Forbidden("You can't like posts by a user who blocked you")
+	}
 	postLike := &models.PostLike{
 		UserID: userID,
 		PostID: input.Body.PostID,
@@ -44,6 +54,7 @@ func (u *PostLikeService) CreatePostLike(ctx context.Context, input *CreatePostL
 	if !inserted {
 		return nil, huma.Error409Conflict("User has already liked this post")
 	}
+	u.events.Publish(ctx, events.PostLiked{PostID: created.PostID, UserID: userID})
 	total, _, err := u.postLikeDB.GetPostLikeInfo(input.Body.PostID, userID)
 	if err != nil {
 		return nil, err
@@ -67,6 +78,7 @@ func (u *PostLikeService) DeletePostLike(ctx context.Context, input *DeletePostL
 	if err != nil {
 		return nil, err
 	}
+	u.events.Publish(ctx, events.PostUnliked{PostID: postID, UserID: userID})
 	total, liked, err := u.postLikeDB.GetPostLikeInfo(postID, userID)
 	if err != nil {
 		return nil, err
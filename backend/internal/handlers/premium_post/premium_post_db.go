@@ -1,25 +1,37 @@
 package premiumpost
 
 import (
-	"fmt"
 	"inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
-	"strings"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PremiumPostDB struct {
 	db *gorm.DB
 }
 
+// premiumPostKeyset pages premium post lists newest first.
+var premiumPostKeyset = utils.Keyset{KeyColumn: "premium_posts.created_at", IDColumn: "premium_posts.id", Descending: true}
+
+func premiumPostCursor(post *models.PremiumPost) utils.Cursor[time.Time] {
+	return utils.Cursor[time.Time]{Key: post.CreatedAt, ID: post.ID}
+}
+
 // Create a new PremiumPostDB instance
 func NewPremiumPostDB(db *gorm.DB) *PremiumPostDB {
 	return &PremiumPostDB{db: db}
 }
 
+// CheckMentions returns a 403 when a user mentioned in texts blocked the user
+func (s *PremiumPostDB) CheckMentions(userID uuid.UUID, texts ...string) error {
+	return utils.CheckMentions(s.db, userID, texts...)
+}
+
 // CreatePremiumPost creates a new premium post in the database
 func (s *PremiumPostDB) CreatePremiumPost(premiumPost *models.PremiumPost) (*models.PremiumPost, error) {
 	dbResponse := s.db.Create(premiumPost)
@@ -33,49 +45,53 @@ func (s *PremiumPostDB) CreatePremiumPost(premiumPost *models.PremiumPost) (*mod
 	return result, nil
 }
 
-// GetAllPremiumPosts returns all premium posts in the database
-func (s *PremiumPostDB) GetAllPremiumPosts(limit, offset int) ([]models.PremiumPost, int64, error) {
+// GetAllPremiumPosts returns all premium posts in the database, only those by verified athletes
+// when verifiedOnly is set
+func (s *PremiumPostDB) GetAllPremiumPosts(page *utils.Page[time.Time], verifiedOnly bool, userID uuid.UUID) ([]models.PremiumPost, utils.PageCursors, int64, error) {
 	var posts []models.PremiumPost
 	var total int64
 
 	// Get total count
-	if err := s.db.Model(&models.PremiumPost{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+	if page.CountTotal() {
+		if err := s.db.Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID), utils.VerifiedAuthors(utils.PremiumPostsTable, verifiedOnly)).Count(&total).Error; err != nil {
+			return nil, utils.PageCursors{}, 0, err
+		}
 	}
 
 	// Get paginated posts
-	if err := s.db.
-		Model(&models.PremiumPost{}).
+	if err := page.Apply(s.db.
+		Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID), utils.VerifiedAuthors(utils.PremiumPostsTable, verifiedOnly)).
 		Preload("Author").
 		Preload("Sport", "id IS NOT NULL").
 		Preload("College", "id IS NOT NULL").
 		Preload("Media").
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'premium_post'")
-		}).
-		Limit(limit).
-		Offset(offset).
+		})).
 		Find(&posts).Error; err != nil {
-		return nil, 0, err
+		return nil, utils.PageCursors{}, 0, err
 	}
 
-	return posts, total, nil
+	posts, cursors := utils.Results(page, posts, premiumPostCursor)
+	return posts, cursors, total, nil
 }
 
 // GetPremiumPostsByAuthorID returns all premium posts related to a given author
-func (s *PremiumPostDB) GetPremiumPostsByAuthorID(limit, offset int, authorID uuid.UUID) ([]models.PremiumPost, int64, error) {
+func (s *PremiumPostDB) GetPremiumPostsByAuthorID(page *utils.Page[time.Time], authorID uuid.UUID, userID uuid.UUID) ([]models.PremiumPost, utils.PageCursors, int64, error) {
 	var posts []models.PremiumPost
 	var total int64
 
 	// check if there are actually premium posts where the given author is the author
-	if err := s.db.Model(&models.PremiumPost{}).
-		Where("author_id = ?", authorID).
-		Count(&total).Error; err != nil {
-		return nil, 0, err
+	if page.CountTotal() {
+		if err := s.db.Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID)).
+			Where("author_id = ?", authorID).
+			Count(&total).Error; err != nil {
+			return nil, utils.PageCursors{}, 0, err
+		}
 	}
 
-	if err := s.db.
-		Model(&models.PremiumPost{}).
+	if err := page.Apply(s.db.
+		Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID)).
 		Preload("Author").
 		Preload("Sport", "id IS NOT NULL").
 		Preload("College", "id IS NOT NULL").
@@ -83,29 +99,30 @@ func (s *PremiumPostDB) GetPremiumPostsByAuthorID(limit, offset int, authorID uu
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'premium_post'")
 		}).
-		Where("author_id = ?", authorID).
-		Limit(limit).
-		Offset(offset).
+		Where("author_id = ?", authorID)).
 		Find(&posts).Error; err != nil {
-		return nil, 0, err
+		return nil, utils.PageCursors{}, 0, err
 	}
 
-	return posts, total, nil
+	posts, cursors := utils.Results(page, posts, premiumPostCursor)
+	return posts, cursors, total, nil
 }
 
 // GetPremiumPostsBySportID returns all premium posts related to a given sport
-func (s *PremiumPostDB) GetPremiumPostsBySportID(limit, offset int, sportID uuid.UUID) ([]models.PremiumPost, int64, error) {
+func (s *PremiumPostDB) GetPremiumPostsBySportID(page *utils.Page[time.Time], sportID uuid.UUID, verifiedOnly bool, userID uuid.UUID) ([]models.PremiumPost, utils.PageCursors, int64, error) {
 	var posts []models.PremiumPost
 	var total int64
 
-	if err := s.db.Model(&models.PremiumPost{}).
-		Where("sport_id = ?", sportID).
-		Count(&total).Error; err != nil {
-		return nil, 0, err
+	if page.CountTotal() {
+		if err := s.db.Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID), utils.VerifiedAuthors(utils.PremiumPostsTable, verifiedOnly)).
+			Where("sport_id = ?", sportID).
+			Count(&total).Error; err != nil {
+			return nil, utils.PageCursors{}, 0, err
+		}
 	}
 
-	if err := s.db.
-		Model(&models.PremiumPost{}).
+	if err := page.Apply(s.db.
+		Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID), utils.VerifiedAuthors(utils.PremiumPostsTable, verifiedOnly)).
 		Preload("Author").
 		Preload("Sport", "id IS NOT NULL").
 		Preload("College", "id IS NOT NULL").
@@ -113,29 +130,30 @@ func (s *PremiumPostDB) GetPremiumPostsBySportID(limit, offset int, sportID uuid
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'premium_post'")
 		}).
-		Where("sport_id = ?", sportID).
-		Limit(limit).
-		Offset(offset).
+		Where("sport_id = ?", sportID)).
 		Find(&posts).Error; err != nil {
-		return nil, 0, err
+		return nil, utils.PageCursors{}, 0, err
 	}
 
-	return posts, total, nil
+	posts, cursors := utils.Results(page, posts, premiumPostCursor)
+	return posts, cursors, total, nil
 }
 
 // GetPremiumPostsByCollegeID returns all premium posts related to a given college
-func (s *PremiumPostDB) GetPremiumPostsByCollegeID(limit, offset int, collegeID uuid.UUID) ([]models.PremiumPost, int64, error) {
+func (s *PremiumPostDB) GetPremiumPostsByCollegeID(page *utils.Page[time.Time], collegeID uuid.UUID, verifiedOnly bool, userID uuid.UUID) ([]models.PremiumPost, utils.PageCursors, int64, error) {
 	var posts []models.PremiumPost
 	var total int64
 
-	if err := s.db.Model(&models.PremiumPost{}).
-		Where("college_id = ?", collegeID).
-		Count(&total).Error; err != nil {
-		return nil, 0, err
+	if page.CountTotal() {
+		if err := s.db.Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID), utils.VerifiedAuthors(utils.PremiumPostsTable, verifiedOnly)).
+			Where("college_id = ?", collegeID).
+			Count(&total).Error; err != nil {
+			return nil, utils.PageCursors{}, 0, err
+		}
 	}
 
-	if err := s.db.
-		Model(&models.PremiumPost{}).
+	if err := page.Apply(s.db.
+		Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID), utils.VerifiedAuthors(utils.PremiumPostsTable, verifiedOnly)).
 		Preload("Author").
 		Preload("Sport", "id IS NOT NULL").
 		Preload("College", "id IS NOT NULL").
@@ -143,32 +161,33 @@ func (s *PremiumPostDB) GetPremiumPostsByCollegeID(limit, offset int, collegeID
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'premium_post'")
 		}).
-		Where("college_id = ?", collegeID).
-		Limit(limit).
-		Offset(offset).
+		Where("college_id = ?", collegeID)).
 		Find(&posts).Error; err != nil {
-		return nil, 0, err
+		return nil, utils.PageCursors{}, 0, err
 	}
 
-	return posts, total, nil
+	posts, cursors := utils.Results(page, posts, premiumPostCursor)
+	return posts, cursors, total, nil
 }
 
 // GetPremiumPostsByTagID returns all premium posts related to a given tag
-func (s *PremiumPostDB) GetPremiumPostsByTagID(limit, offset int, tagID uuid.UUID) ([]models.PremiumPost, int64, error) {
+func (s *PremiumPostDB) GetPremiumPostsByTagID(page *utils.Page[time.Time], tagID uuid.UUID, verifiedOnly bool, userID uuid.UUID) ([]models.PremiumPost, utils.PageCursors, int64, error) {
 	var posts []models.PremiumPost
 	var total int64
 
-	base := s.db.Model(&models.PremiumPost{}).
+	base := s.db.Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID), utils.VerifiedAuthors(utils.PremiumPostsTable, verifiedOnly)).
 		Joins("JOIN tag_posts tp ON tp.postable_id = premium_posts.id AND tp.postable_type = 'premium_post'").
 		Where("tp.tag_id = ?", tagID)
 
 	// if this jointable count is 0, there are no premium posts with the given tag
-	if err := base.Count(&total).Error; err != nil {
-		return nil, 0, err
+	if page.CountTotal() {
+		if err := base.Count(&total).Error; err != nil {
+			return nil, utils.PageCursors{}, 0, err
+		}
 	}
 
-	if err := s.db.
-		Model(&models.PremiumPost{}).
+	if err := page.Apply(s.db.
+		Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID), utils.VerifiedAuthors(utils.PremiumPostsTable, verifiedOnly)).
 		Joins("JOIN tag_posts tp ON tp.postable_id = premium_posts.id AND tp.postable_type = 'premium_post'").
 		Where("tp.tag_id = ?", tagID).
 		Preload("Author").
@@ -177,26 +196,24 @@ func (s *PremiumPostDB) GetPremiumPostsByTagID(limit, offset int, tagID uuid.UUI
 		Preload("Media").
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'premium_post'")
-		}).
-		Limit(limit).
-		Offset(offset).
+		})).
 		Find(&posts).Error; err != nil {
-		return nil, 0, err
+		return nil, utils.PageCursors{}, 0, err
 	}
 
-	return posts, total, nil
+	posts, cursors := utils.Results(page, posts, premiumPostCursor)
+	return posts, cursors, total, nil
 }
 
-// FuzzySearchForPremiumPost returns premium posts whose title fuzzy-matches the given search string
-func (s *PremiumPostDB) FuzzySearchForPremiumPost(searchStr string, limit, offset int) ([]models.PremiumPost, int64, error) {
+// SearchPremiumPosts runs a full-text search over premium post titles, content and tag names, also matching
+// titles that are only a close (typo) match. Results are ordered by text rank, then title similarity.
+func (s *PremiumPostDB) SearchPremiumPosts(text *utils.TextSearch, fuzzy *utils.FuzzySearch, filters utils.PostFilters, limit, offset int, userID uuid.UUID) ([]models.PremiumPost, int64, error) {
 	var posts []models.PremiumPost
 	var total int64
 
-	selectQuery, whereQuery, orderQuery := utils.FuzzySearchByQueries("title", searchStr)
+	matches := clause.Expr{SQL: "(? OR ?)", Vars: []any{text.Matches(), fuzzy.Matches()}}
 
-	if err := s.db.Model(&models.PremiumPost{}).
-		Select("premium_posts.*, "+selectQuery).
-		Where(whereQuery).
+	if err := filters.Apply(s.db.Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID)).Where(matches), "premium_posts", "premium_post").
 		Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -205,10 +222,11 @@ func (s *PremiumPostDB) FuzzySearchForPremiumPost(searchStr string, limit, offse
 		return []models.PremiumPost{}, 0, nil
 	}
 
-	if err := s.db.
-		Model(&models.PremiumPost{}).
-		Select("premium_posts.*, "+selectQuery).
-		Where(whereQuery).
+	if err := filters.Apply(s.db.Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID)), "premium_posts", "premium_post").
+		Select("premium_posts.*, ? AS snippet, ? AS title_highlight",
+			text.Headline("premium_posts.content", utils.SnippetHeadlineOptions),
+			text.Headline("premium_posts.title", utils.HighlightHeadlineOptions)).
+		Where(matches).
 		Preload("Author").
 		Preload("Sport", "id IS NOT NULL").
 		Preload("College", "id IS NOT NULL").
@@ -216,7 +234,7 @@ func (s *PremiumPostDB) FuzzySearchForPremiumPost(searchStr string, limit, offse
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags AS t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'premium_post'")
 		}).
-		Order(orderQuery).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "? DESC, ? DESC, premium_posts.created_at DESC", Vars: []any{text.Rank(), fuzzy.Score()}}}).
 		Limit(limit).
 		Offset(offset).
 		Find(&posts).Error; err != nil {
@@ -226,47 +244,17 @@ func (s *PremiumPostDB) FuzzySearchForPremiumPost(searchStr string, limit, offse
 	return posts, total, nil
 }
 
-// FilterPremiumPosts returns premium posts filtered by the given college, sport, and tag IDs
-func (s *PremiumPostDB) FilterPremiumPosts(colleges []uuid.UUID, sports []uuid.UUID, tags []uuid.UUID, limit, offset int) ([]models.PremiumPost, int64, error) {
+// FilterPremiumPosts returns premium posts in any of the given colleges, sports or tags
+func (s *PremiumPostDB) FilterPremiumPosts(filters utils.PostFilters, limit, offset int, userID uuid.UUID) ([]models.PremiumPost, int64, error) {
 	var posts []models.PremiumPost
 	var total int64
 
-	filters := make([]string, 0)
-	if len(colleges) > 0 {
-		mappedColleges := utils.MapList(colleges, func(c uuid.UUID) string {
-			return fmt.Sprintf("premium_posts.college_id = '%s'", c.String())
-		})
-		filters = append(filters, strings.Join(mappedColleges, " OR "))
-	}
-	if len(sports) > 0 {
-		mappedSports := utils.MapList(sports, func(c uuid.UUID) string {
-			return fmt.Sprintf("premium_posts.sport_id = '%s'", c.String())
-		})
-		filters = append(filters, strings.Join(mappedSports, " OR "))
-	}
-	if len(tags) > 0 {
-		mappedTags := utils.MapList(tags, func(c uuid.UUID) string {
-			return fmt.Sprintf("tag_posts.tag_id = '%s'", c.String())
-		})
-		filters = append(filters, strings.Join(mappedTags, " OR "))
-	}
-
-	whereQuery := strings.Join(filters, " OR ")
-
-	if err := s.db.
-		Model(&models.PremiumPost{}).
-		Joins("JOIN tag_posts ON premium_posts.id = tag_posts.postable_id AND tag_posts.postable_type = 'premium_post'").
-		Where(whereQuery).
-		Group("premium_posts.id").
+	if err := filters.Apply(s.db.Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID)), "premium_posts", "premium_post").
 		Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := s.db.
-		Model(&models.PremiumPost{}).
-		Joins("JOIN tag_posts ON premium_posts.id = tag_posts.postable_id AND tag_posts.postable_type = 'premium_post'").
-		Where(whereQuery).
-		Group("premium_posts.id").
+	if err := filters.Apply(s.db.Model(&models.PremiumPost{}).Scopes(utils.VisibleTo(utils.PremiumPostsTable, userID)), "premium_posts", "premium_post").
 		Preload("Author").
 		Preload("Sport", "id IS NOT NULL").
 		Preload("College", "id IS NOT NULL").
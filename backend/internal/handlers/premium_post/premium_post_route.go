@@ -1,7 +1,9 @@
 package premiumpost
 
 import (
+	"inside-athletics/internal/models"
 	"inside-athletics/internal/s3"
+	"inside-athletics/internal/utils"
 
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
@@ -11,18 +13,26 @@ func Route(api huma.API, db *gorm.DB, s3Svc *s3.Service) {
 	var premiumPostService = NewPremiumPostService(db, s3Svc)
 	{
 		grp := huma.NewGroup(api, "/api/v1/post/premium")
-		huma.Post(grp, "/", premiumPostService.CreatePremiumPost) // Create post
+		huma.Post(grp, "/", premiumPostService.CreatePremiumPost, utils.Requires(utils.Permission(models.PermissionCreate, "premiumpost"))) // Create post
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/posts/premium")
-		huma.Get(grp, "/", premiumPostService.GetAllPremiumPosts)                                // Get all PremiumPosts in db
-		huma.Get(grp, "/by-author/{author_id}", premiumPostService.GetPremiumPostsByAuthorID)    // Get all premium posts created by this author
-		huma.Get(grp, "/by-sport/{sport_id}", premiumPostService.GetPremiumPostsBySportID)       // Get all premium posts tagged to this sport
-		huma.Get(grp, "/by-college/{college_id}", premiumPostService.GetPremiumPostsByCollegeID) // Get all premium posts tagged to this college
-		huma.Get(grp, "/by-tag/{tag_id}", premiumPostService.GetPremiumPostsByTagID)             // Get all premium posts tagged to this tag
-		huma.Get(grp, "/search", premiumPostService.FuzzySearchForPremiumPost)                   // Fuzzy search premium posts by title
-		huma.Get(grp, "/filter", premiumPostService.FilterPremiumPosts)                          // Filter premium posts by college, sport, and tags
-		huma.Patch(grp, "/{id}", premiumPostService.UpdatePremiumPost)                           // Update post
-		huma.Delete(grp, "/{id}", premiumPostService.DeletePremiumPost)                          // Delete post
+		huma.Get(grp, "/", premiumPostService.GetAllPremiumPosts)                                                                   // Get all PremiumPosts in db
+		huma.Get(grp, "/by-author/{author_id}", premiumPostService.GetPremiumPostsByAuthorID)                                       // Get all premium posts created by this author
+		huma.Get(grp, "/by-sport/{sport_id}", premiumPostService.GetPremiumPostsBySportID)                                          // Get all premium posts tagged to this sport
+		huma.Get(grp, "/by-college/{college_id}", premiumPostService.GetPremiumPostsByCollegeID)                                    // Get all premium posts tagged to this college
+		huma.Get(grp, "/by-tag/{tag_id}", premiumPostService.GetPremiumPostsByTagID)                                                // Get all premium posts tagged to this tag
+		huma.Get(grp, "/search", premiumPostService.SearchPremiumPosts)                                                             // Full-text search premium posts by title, content, and tags
+		huma.Get(grp, "/filter", premiumPostService.FilterPremiumPosts)                                                             // Filter premium posts by college, sport, and tags
+		huma.Patch(grp, "/{id}", premiumPostService.UpdatePremiumPost, utils.Requires(premiumPostAccess(models.PermissionUpdate)))  // Update post
+		huma.Delete(grp, "/{id}", premiumPostService.DeletePremiumPost, utils.Requires(premiumPostAccess(models.PermissionDelete))) // Delete post
 	}
 }
+
+// premiumPostAccess is what changing a premium post requires: authors need the _own action, and
+// moderators scoped to the post's sport or college may act on it too.
+func premiumPostAccess(action models.PermissionAction) utils.Access {
+	return utils.Permission(action, "premiumpost").
+		OwnedBy(utils.AuthorOf(utils.PremiumPostsTable)).
+		InScopes(utils.ScopesOfContent(utils.PremiumPostsTable))
+}
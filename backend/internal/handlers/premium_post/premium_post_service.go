@@ -6,8 +6,7 @@ import (
 	"inside-athletics/internal/models"
 	"inside-athletics/internal/s3"
 	"inside-athletics/internal/utils"
-	"regexp"
-	"strings"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
@@ -54,6 +53,9 @@ func (s *PremiumPostService) CreatePremiumPost(ctx context.Context, input *struc
 	if len(input.Body.Tags) == 0 && input.Body.SportID == nil && input.Body.CollegeID == nil {
 		return nil, huma.Error400BadRequest("Need to have at least a single tag on a post")
 	}
+	if err := s.premiumPostDB.CheckMentions(id, input.Body.Title, input.Body.Content); err != nil {
+		return nil, err
+	}
 
 	premiumPost := &models.PremiumPost{
 		AuthorID:  id,
@@ -81,7 +83,15 @@ func (s *PremiumPostService) CreatePremiumPost(ctx context.Context, input *struc
 
 // GetAllPremiumPosts returns all premium posts
 func (s *PremiumPostService) GetAllPremiumPosts(ctx context.Context, input *GetAllPremiumPostsParams) (*utils.ResponseBody[GetAllPremiumPostsResponse], error) {
-	posts, total, err := s.premiumPostDB.GetAllPremiumPosts(input.Limit, input.Offset)
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := utils.NewPage[time.Time](premiumPostKeyset, input.Cursor, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	posts, cursors, total, err := s.premiumPostDB.GetAllPremiumPosts(page, input.VerifiedOnly, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -94,15 +104,24 @@ func (s *PremiumPostService) GetAllPremiumPosts(ctx context.Context, input *GetA
 
 	return &utils.ResponseBody[GetAllPremiumPostsResponse]{
 		Body: &GetAllPremiumPostsResponse{
-			Posts: postResponses,
-			Total: int(total),
+			Posts:       postResponses,
+			Total:       int(total),
+			PageCursors: cursors,
 		},
 	}, nil
 }
 
 // GetPremiumPostsByAuthorID returns all premium posts related to a given author
 func (s *PremiumPostService) GetPremiumPostsByAuthorID(ctx context.Context, input *GetPremiumPostsByAuthorIDParams) (*utils.ResponseBody[GetPremiumPostsByAuthorIDResponse], error) {
-	posts, total, err := s.premiumPostDB.GetPremiumPostsByAuthorID(input.Limit, input.Offset, input.AuthorID)
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := utils.NewPage[time.Time](premiumPostKeyset, input.Cursor, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	posts, cursors, total, err := s.premiumPostDB.GetPremiumPostsByAuthorID(page, input.AuthorID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -115,15 +134,24 @@ func (s *PremiumPostService) GetPremiumPostsByAuthorID(ctx context.Context, inpu
 
 	return &utils.ResponseBody[GetPremiumPostsByAuthorIDResponse]{
 		Body: &GetPremiumPostsByAuthorIDResponse{
-			Posts: postResponses,
-			Total: int(total),
+			Posts:       postResponses,
+			Total:       int(total),
+			PageCursors: cursors,
 		},
 	}, nil
 }
 
 // GetPremiumPostsBySportID returns all premium posts related to a given sport
 func (s *PremiumPostService) GetPremiumPostsBySportID(ctx context.Context, input *GetPremiumPostsBySportIDParams) (*utils.ResponseBody[GetPremiumPostsBySportIDResponse], error) {
-	posts, total, err := s.premiumPostDB.GetPremiumPostsBySportID(input.Limit, input.Offset, input.SportID)
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := utils.NewPage[time.Time](premiumPostKeyset, input.Cursor, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	posts, cursors, total, err := s.premiumPostDB.GetPremiumPostsBySportID(page, input.SportID, input.VerifiedOnly, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -136,15 +164,24 @@ func (s *PremiumPostService) GetPremiumPostsBySportID(ctx context.Context, input
 
 	return &utils.ResponseBody[GetPremiumPostsBySportIDResponse]{
 		Body: &GetPremiumPostsBySportIDResponse{
-			Posts: postResponses,
-			Total: int(total),
+			Posts:       postResponses,
+			Total:       int(total),
+			PageCursors: cursors,
 		},
 	}, nil
 }
 
 // GetPremiumPostsByCollegeID returns all premium posts related to a given college
 func (s *PremiumPostService) GetPremiumPostsByCollegeID(ctx context.Context, input *GetPremiumPostsByCollegeIDParams) (*utils.ResponseBody[GetPremiumPostsByCollegeIDResponse], error) {
-	posts, total, err := s.premiumPostDB.GetPremiumPostsByCollegeID(input.Limit, input.Offset, input.CollegeID)
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := utils.NewPage[time.Time](premiumPostKeyset, input.Cursor, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	posts, cursors, total, err := s.premiumPostDB.GetPremiumPostsByCollegeID(page, input.CollegeID, input.VerifiedOnly, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -157,15 +194,24 @@ func (s *PremiumPostService) GetPremiumPostsByCollegeID(ctx context.Context, inp
 
 	return &utils.ResponseBody[GetPremiumPostsByCollegeIDResponse]{
 		Body: &GetPremiumPostsByCollegeIDResponse{
-			Posts: postResponses,
-			Total: int(total),
+			Posts:       postResponses,
+			Total:       int(total),
+			PageCursors: cursors,
 		},
 	}, nil
 }
 
 // GetPremiumPostsByTagID returns all premium posts related to a given tag
 func (s *PremiumPostService) GetPremiumPostsByTagID(ctx context.Context, input *GetPremiumPostsByTagIDParams) (*utils.ResponseBody[GetPremiumPostsByTagIDResponse], error) {
-	posts, total, err := s.premiumPostDB.GetPremiumPostsByTagID(input.Limit, input.Offset, input.TagID)
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := utils.NewPage[time.Time](premiumPostKeyset, input.Cursor, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	posts, cursors, total, err := s.premiumPostDB.GetPremiumPostsByTagID(page, input.TagID, input.VerifiedOnly, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -178,15 +224,27 @@ func (s *PremiumPostService) GetPremiumPostsByTagID(ctx context.Context, input *
 
 	return &utils.ResponseBody[GetPremiumPostsByTagIDResponse]{
 		Body: &GetPremiumPostsByTagIDResponse{
-			Posts: postResponses,
-			Total: int(total),
+			Posts:       postResponses,
+			Total:       int(total),
+			PageCursors: cursors,
 		},
 	}, nil
 }
 
-// FuzzySearchForPremiumPost searches premium posts by title using fuzzy matching
-func (s *PremiumPostService) FuzzySearchForPremiumPost(ctx context.Context, input *GetSearchPremiumPostParam) (*utils.ResponseBody[GetSearchPremiumPostResponse], error) {
-	posts, total, err := s.premiumPostDB.FuzzySearchForPremiumPost(input.SearchStr, input.Limit, input.Offset)
+// SearchPremiumPosts full-text searches premium posts, optionally narrowed by college, sport, and tag IDs
+func (s *PremiumPostService) SearchPremiumPosts(ctx context.Context, input *GetSearchPremiumPostParam) (*utils.ResponseBody[GetSearchPremiumPostResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filters, err := utils.ParsePostFilters(input.CollegeIds, input.SportIds, input.TagIds)
+	if err != nil {
+		return nil, err
+	}
+	filters.VerifiedOnly = input.VerifiedOnly
+	text := utils.NewTextSearch(input.SearchStr, "premium_posts.search_vector")
+	fuzzy := utils.NewFuzzySearch(input.SearchStr, utils.Column("premium_posts.title")).WithThreshold(input.Threshold)
+	posts, total, err := s.premiumPostDB.SearchPremiumPosts(text, fuzzy, filters, input.Limit, input.Offset, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -207,39 +265,17 @@ func (s *PremiumPostService) FuzzySearchForPremiumPost(ctx context.Context, inpu
 
 // FilterPremiumPosts filters premium posts by college, sport, and tag IDs
 func (s *PremiumPostService) FilterPremiumPosts(ctx context.Context, input *GetFilterPremiumPostsParams) (*utils.ResponseBody[GetFilterPremiumPostsResponse], error) {
-	uuidPattern := `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`
-	fullPattern := fmt.Sprintf(`^%s(?:,%s)*$`, uuidPattern, uuidPattern)
-	re := regexp.MustCompile(fullPattern)
-
-	if input.CollegeIds != "" && !re.MatchString(input.CollegeIds) {
-		return nil, huma.Error400BadRequest("Expected comma separated list of uuids with no spaces for college input uuid,uuid")
-	}
-	if input.SportIds != "" && !re.MatchString(input.SportIds) {
-		return nil, huma.Error400BadRequest("Expected comma separated list of uuids with no spaces for sport input uuid,uuid")
-	}
-	if input.TagIds != "" && !re.MatchString(input.TagIds) {
-		return nil, huma.Error400BadRequest("Expected comma separated list of uuids with no spaces for tag input uuid,uuid")
-	}
-
-	mapUUID := func(id string) uuid.UUID {
-		parsedId, _ := uuid.Parse(id)
-		return parsedId
-	}
-
-	collegeIds := []uuid.UUID{}
-	sportIds := []uuid.UUID{}
-	tagIds := []uuid.UUID{}
-	if input.CollegeIds != "" {
-		collegeIds = utils.MapList(strings.Split(input.CollegeIds, ","), mapUUID)
-	}
-	if input.SportIds != "" {
-		sportIds = utils.MapList(strings.Split(input.SportIds, ","), mapUUID)
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	if input.TagIds != "" {
-		tagIds = utils.MapList(strings.Split(input.TagIds, ","), mapUUID)
+	filters, err := utils.ParsePostFilters(input.CollegeIds, input.SportIds, input.TagIds)
+	if err != nil {
+		return nil, err
 	}
+	filters.VerifiedOnly = input.VerifiedOnly
 
-	posts, total, err := s.premiumPostDB.FilterPremiumPosts(collegeIds, sportIds, tagIds, input.Limit, input.Offset)
+	posts, total, err := s.premiumPostDB.FilterPremiumPosts(filters, input.Limit, input.Offset, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -267,6 +303,9 @@ func (s *PremiumPostService) UpdatePremiumPost(ctx context.Context, input *struc
 	if err != nil {
 		return nil, err
 	}
+	if err := s.premiumPostDB.CheckMentions(userID, utils.SetTexts(input.Body.Title, input.Body.Content)...); err != nil {
+		return nil, err
+	}
 
 	updatedPost, err := s.premiumPostDB.UpdatePremiumPost(input.ID, input.Body, userID)
 	if err != nil {
@@ -2,6 +2,7 @@ package premiumpost
 
 import (
 	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
 
 	"github.com/google/uuid"
 )
@@ -16,94 +17,111 @@ import (
 
 // Retrieve all posts
 type GetAllPremiumPostsParams struct {
-	Limit  int `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
-	Offset int `query:"offset" default:"0" example:"0" doc:"Number of posts to skip"`
+	Limit        int    `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
+	Cursor       string `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page. Takes precedence over offset"`
+	Offset       int    `query:"offset" default:"0" example:"0" deprecated:"true" doc:"Number of posts to skip. Deprecated: use cursor"`
+	VerifiedOnly bool   `query:"verified_only" default:"false" example:"true" doc:"Only return premium posts by verified athletes"`
 }
 
 // Given an AuthorID, return all posts that the author has posted (with pagination)
 type GetPremiumPostsByAuthorIDParams struct {
 	AuthorID uuid.UUID `path:"author_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000" doc:"Author ID to filter posts"`
 	Limit    int       `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
-	Offset   int       `query:"offset" default:"0" example:"0" doc:"Number of posts to skip"`
+	Cursor   string    `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page. Takes precedence over offset"`
+	Offset   int       `query:"offset" default:"0" example:"0" deprecated:"true" doc:"Number of posts to skip. Deprecated: use cursor"`
 }
 
 // Given a SportID, return all posts related to the sport (with pagnination)
 type GetPremiumPostsBySportIDParams struct {
-	SportID uuid.UUID `path:"sport_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000" doc:"Sport ID to filter posts"`
-	Limit   int       `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
-	Offset  int       `query:"offset" default:"0" example:"0" doc:"Number of posts to skip"`
+	SportID      uuid.UUID `path:"sport_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000" doc:"Sport ID to filter posts"`
+	Limit        int       `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
+	Cursor       string    `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page. Takes precedence over offset"`
+	Offset       int       `query:"offset" default:"0" example:"0" deprecated:"true" doc:"Number of posts to skip. Deprecated: use cursor"`
+	VerifiedOnly bool      `query:"verified_only" default:"false" example:"true" doc:"Only return premium posts by verified athletes"`
 }
 
 // Given a CollegeID, return all posts related to the college (with pagination)
 type GetPremiumPostsByCollegeIDParams struct {
-	CollegeID uuid.UUID `path:"college_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000" doc:"College ID to filter posts"`
-	Limit     int       `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
-	Offset    int       `query:"offset" default:"0" example:"0" doc:"Number of posts to skip"`
+	CollegeID    uuid.UUID `path:"college_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000" doc:"College ID to filter posts"`
+	Limit        int       `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
+	Cursor       string    `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page. Takes precedence over offset"`
+	Offset       int       `query:"offset" default:"0" example:"0" deprecated:"true" doc:"Number of posts to skip. Deprecated: use cursor"`
+	VerifiedOnly bool      `query:"verified_only" default:"false" example:"true" doc:"Only return premium posts by verified athletes"`
 }
 
 // Given a TagID, return all posts related to the tag (with pagination)
 type GetPremiumPostsByTagIDParams struct {
-	TagID  uuid.UUID `path:"tag_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000" doc:"Tag ID to filter posts"`
-	Limit  int       `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
-	Offset int       `query:"offset" default:"0" example:"0" doc:"Number of posts to skip"`
+	TagID        uuid.UUID `path:"tag_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000" doc:"Tag ID to filter posts"`
+	Limit        int       `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
+	Cursor       string    `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page. Takes precedence over offset"`
+	Offset       int       `query:"offset" default:"0" example:"0" deprecated:"true" doc:"Number of posts to skip. Deprecated: use cursor"`
+	VerifiedOnly bool      `query:"verified_only" default:"false" example:"true" doc:"Only return premium posts by verified athletes"`
 }
 
 type PremiumPostResponse struct {
-	ID             uuid.UUID              `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	Author         *models.User           `json:"author" type:"user"`
-	Sport          *models.Sport          `json:"sport" type:"sport"`
-	College        *models.College        `json:"college" type:"college"`
-	Tags           []models.Tag           `json:"tags" type:"tag"`
-	Title          string                 `json:"title" example:"Looking for thoughts on NEU Fencing!" gorm:"type:varchar(100);not null" validate:"required,min=1,max=100"`
-	Content        string                 `json:"content" example:"My name is Bob Joe and I am a rising senior who just got into NEU. What is the fencing program like? Are they competitive?" gorm:"type:varchar(5000);not null" validate:"required,min=1,max=5000"`
-	MediaID        *uuid.UUID             `json:"media_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
-	Media          *models.Media          `json:"media,omitempty"`
+	ID             uuid.UUID          `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Author         *models.User       `json:"author" type:"user"`
+	AuthorTrust    *utils.AuthorTrust `json:"author_trust" doc:"How far to trust the author on this premium post's college and sport"`
+	Sport          *models.Sport      `json:"sport" type:"sport"`
+	College        *models.College    `json:"college" type:"college"`
+	Tags           []models.Tag       `json:"tags" type:"tag"`
+	Title          string             `json:"title" example:"Looking for thoughts on NEU Fencing!" gorm:"type:varchar(100);not null" validate:"required,min=1,max=100"`
+	Content        string             `json:"content" example:"My name is Bob Joe and I am a rising senior who just got into NEU. What is the fencing program like? Are they competitive?" gorm:"type:varchar(5000);not null" validate:"required,min=1,max=5000"`
+	MediaID        *uuid.UUID         `json:"media_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Media          *models.Media      `json:"media,omitempty"`
+	Snippet        string             `json:"snippet,omitempty" example:"What is the <mark>fencing</mark> program like?" doc:"HTML escaped content excerpt with search matches wrapped in <mark> tags, only set on search results"`
+	TitleHighlight string             `json:"title_highlight,omitempty" example:"Looking for thoughts on NEU <mark>Fencing</mark>!" doc:"HTML escaped title with search matches wrapped in <mark> tags, only set on search results"`
 }
 
 type GetAllPremiumPostsResponse struct {
 	Posts []PremiumPostResponse `json:"posts" doc:"List of premium posts"`
-	Total int                   `json:"total" example:"100" doc:"Total number of premium posts"`
+	Total int                   `json:"total" example:"100" doc:"Total number of premium posts. Not counted for pages requested with a cursor (0)"`
+	utils.PageCursors
 }
 
 type GetPremiumPostsByAuthorIDResponse struct {
 	Posts []PremiumPostResponse `json:"posts" doc:"List of premium posts for the author"`
-	Total int                   `json:"total" example:"25" doc:"Total number of premium posts for this author"`
+	Total int                   `json:"total" example:"25" doc:"Total number of premium posts for this author. Not counted for pages requested with a cursor (0)"`
+	utils.PageCursors
 }
 
 type GetPremiumPostsBySportIDResponse struct {
 	Posts []PremiumPostResponse `json:"posts" doc:"List of premium posts for the sport"`
-	Total int                   `json:"total" example:"25" doc:"Total number of premium posts for this sport"`
+	Total int                   `json:"total" example:"25" doc:"Total number of premium posts for this sport. Not counted for pages requested with a cursor (0)"`
+	utils.PageCursors
 }
 
 type GetPremiumPostsByCollegeIDResponse struct {
 	Posts []PremiumPostResponse `json:"posts" doc:"List of premium posts for the college"`
-	Total int                   `json:"total" example:"25" doc:"Total number of premium posts for this college"`
+	Total int                   `json:"total" example:"25" doc:"Total number of premium posts for this college. Not counted for pages requested with a cursor (0)"`
+	utils.PageCursors
 }
 
 type GetPremiumPostsByTagIDResponse struct {
 	Posts []PremiumPostResponse `json:"posts" doc:"List of premium posts for the tag"`
-	Total int                   `json:"total" example:"25" doc:"Total number of premium posts for this tag"`
+	Total int                   `json:"total" example:"25" doc:"Total number of premium posts for this tag. Not counted for pages requested with a cursor (0)"`
+	utils.PageCursors
 }
 
 type CreatePremiumPostParams struct {
-	SportID        *uuid.UUID             `json:"sport_id,omitempty" gorm:"type:uuid;default:null"`
-	CollegeID      *uuid.UUID             `json:"college_id,omitempty" gorm:"type:uuid;default:null"`
-	Tags           []uuid.UUID            `json:"tag" type:"tag"`
-	Title          string                 `json:"title" example:"Looking for thoughts on NEU Fencing!" gorm:"type:varchar(100);not null" validate:"required,min=1,max=100"`
-	Content        string                 `json:"content" example:"My name is Bob Joe and I am a rising senior who just got into NEU. What is the fencing program like? Are they competitive?" gorm:"type:varchar(5000);not null" validate:"required,min=1,max=5000"`
-	MediaID        *uuid.UUID             `json:"media_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	SportID   *uuid.UUID  `json:"sport_id,omitempty" gorm:"type:uuid;default:null"`
+	CollegeID *uuid.UUID  `json:"college_id,omitempty" gorm:"type:uuid;default:null"`
+	Tags      []uuid.UUID `json:"tag" type:"tag"`
+	Title     string      `json:"title" example:"Looking for thoughts on NEU Fencing!" gorm:"type:varchar(100);not null" validate:"required,min=1,max=100"`
+	Content   string      `json:"content" example:"My name is Bob Joe and I am a rising senior who just got into NEU. What is the fencing program like? Are they competitive?" gorm:"type:varchar(5000);not null" validate:"required,min=1,max=5000"`
+	MediaID   *uuid.UUID  `json:"media_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
 }
 
 type CreatePremiumPostResponse struct {
-	ID             uuid.UUID              `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	AuthorID       *uuid.UUID             `json:"author" type:"uuid"`
-	SportID        *uuid.UUID             `json:"sport_id" gorm:"type:uuid;default:null"`
-	CollegeID      *uuid.UUID             `json:"college_id" gorm:"type:uuid;default:null"`
-	Tags           []models.Tag           `json:"tag" type:"tag"`
-	Title          string                 `json:"title" example:"Looking for thoughts on NEU Fencing!" gorm:"type:varchar(100);not null" validate:"required,min=1,max=100"`
-	Content        string                 `json:"content" example:"My name is Bob Joe and I am a rising senior who just got into NEU. What is the fencing program like? Are they competitive?" gorm:"type:varchar(5000);not null" validate:"required,min=1,max=5000"`
-	MediaID        *uuid.UUID             `json:"media_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
-	Media          *models.Media          `json:"media,omitempty"`
+	ID        uuid.UUID     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	AuthorID  *uuid.UUID    `json:"author" type:"uuid"`
+	SportID   *uuid.UUID    `json:"sport_id" gorm:"type:uuid;default:null"`
+	CollegeID *uuid.UUID    `json:"college_id" gorm:"type:uuid;default:null"`
+	Tags      []models.Tag  `json:"tag" type:"tag"`
+	Title     string        `json:"title" example:"Looking for thoughts on NEU Fencing!" gorm:"type:varchar(100);not null" validate:"required,min=1,max=100"`
+	Content   string        `json:"content" example:"My name is Bob Joe and I am a rising senior who just got into NEU. What is the fencing program like? Are they competitive?" gorm:"type:varchar(5000);not null" validate:"required,min=1,max=5000"`
+	MediaID   *uuid.UUID    `json:"media_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Media     *models.Media `json:"media,omitempty"`
 }
 
 // ToPremiumPostResponse converts a PremiumPost model to a premiumPostResponse
@@ -128,22 +146,30 @@ func ToCreatePremiumPostResponse(post *models.PremiumPost, id uuid.UUID) *Create
 
 func ToPremiumPostResponse(post *models.PremiumPost) *PremiumPostResponse {
 	return &PremiumPostResponse{
-		ID:      post.ID,
-		Author:  &post.Author,
-		Sport:   post.Sport,
-		College: post.College,
-		Tags:    post.Tags,
-		Title:   post.Title,
-		Content: post.Content,
-		MediaID: post.MediaID,
-		Media:   post.Media,
+		ID:             post.ID,
+		Author:         &post.Author,
+		AuthorTrust:    utils.TrustOf(&post.Author, post.CollegeID, post.SportID, false),
+		Sport:          post.Sport,
+		College:        post.College,
+		Tags:           post.Tags,
+		Title:          post.Title,
+		Content:        post.Content,
+		MediaID:        post.MediaID,
+		Media:          post.Media,
+		Snippet:        post.Snippet,
+		TitleHighlight: post.TitleHighlight,
 	}
 }
 
 type GetSearchPremiumPostParam struct {
-	SearchStr string `query:"search_str" binding:"required" example:"Northeastern University" doc:"String to fuzzy search premium posts on"`
-	Limit     int    `query:"limit" default:"20" example:"10" doc:"Cap on the number of posts to return"`
-	Offset    int    `query:"offset" default:"0" example:"8" doc:"Number of entries to skip for pagination"`
+	SearchStr    string  `query:"search_str" binding:"required" example:"fencing recruiting" doc:"Text to search premium post titles, content and tag names for. Supports \"quoted phrases\", or, and -excluded words"`
+	CollegeIds   string  `query:"college_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of college_ids to filter by"`
+	SportIds     string  `query:"sport_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of sport_ids to filter by"`
+	TagIds       string  `query:"tag_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of tag_ids to filter by"`
+	Limit        int     `query:"limit" default:"20" example:"10" doc:"Cap on the number of posts to return"`
+	Offset       int     `query:"offset" default:"0" example:"8" doc:"Number of entries to skip for pagination"`
+	Threshold    float64 `query:"threshold" default:"0.3" minimum:"0" maximum:"1" example:"0.3" doc:"Minimum title similarity (0-1) for a premium post that does not match the text search to still be returned"`
+	VerifiedOnly bool    `query:"verified_only" default:"false" example:"true" doc:"Only return premium posts by verified athletes"`
 }
 
 type GetSearchPremiumPostResponse struct {
@@ -152,11 +178,12 @@ type GetSearchPremiumPostResponse struct {
 }
 
 type GetFilterPremiumPostsParams struct {
-	CollegeIds string `query:"college_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of college_ids to filter by"`
-	SportIds   string `query:"sport_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of sport_ids to filter by"`
-	TagIds     string `query:"tag_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of tag_ids to filter by"`
-	Limit      int    `query:"limit" default:"20" example:"20" doc:"Number of posts to return when filtering"`
-	Offset     int    `query:"offset" default:"0" example:"8" doc:"Number of entries in the database to offset by"`
+	CollegeIds   string `query:"college_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of college_ids to filter by"`
+	SportIds     string `query:"sport_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of sport_ids to filter by"`
+	TagIds       string `query:"tag_ids" default:"" example:"98d830a4-3ddd-441f-a8b8-12d99b597894,98d830a4-3ddd-441f-a8b8-12d99b597894" doc:"Comma seperated list of tag_ids to filter by"`
+	Limit        int    `query:"limit" default:"20" example:"20" doc:"Number of posts to return when filtering"`
+	Offset       int    `query:"offset" default:"0" example:"8" doc:"Number of entries in the database to offset by"`
+	VerifiedOnly bool   `query:"verified_only" default:"false" example:"true" doc:"Only return premium posts by verified athletes"`
 }
 
 type GetFilterPremiumPostsResponse struct {
@@ -165,9 +192,9 @@ type GetFilterPremiumPostsResponse struct {
 }
 
 type UpdatePremiumPostRequest struct {
-	Title   *string     `json:"title,omitempty" minLength:"1" maxLength:"100"`
-	Content *string     `json:"content,omitempty" minLength:"1" maxLength:"5000"`
-	MediaID *uuid.UUID  `json:"media_id,omitempty"`
+	Title   *string    `json:"title,omitempty" minLength:"1" maxLength:"100"`
+	Content *string    `json:"content,omitempty" minLength:"1" maxLength:"5000"`
+	MediaID *uuid.UUID `json:"media_id,omitempty"`
 }
 
 type DeletePremiumPostRequest struct {
@@ -0,0 +1,51 @@
+package report
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Env keys for report config. Durations are in days.
+const (
+	EnvHideThreshold  = "REPORT_HIDE_THRESHOLD"
+	EnvSuspensionDays = "REPORT_SUSPENSION_DAYS"
+)
+
+const (
+	DefaultHideThreshold = 3
+	DefaultSuspension    = 7 * 24 * time.Hour
+)
+
+// Config controls when reported content is hidden and how long authors are suspended for.
+type Config struct {
+	// HideThreshold is how many open reports hide an item until a moderator reviews it.
+	HideThreshold int64
+	// Suspension is how long a suspend action lasts when the moderator doesn't give a length.
+	Suspension time.Duration
+}
+
+// DefaultConfig returns the config used when nothing is set in env.
+func DefaultConfig() Config {
+	return Config{
+		HideThreshold: DefaultHideThreshold,
+		Suspension:    DefaultSuspension,
+	}
+}
+
+// LoadConfigFromEnv returns the report config from env, falling back to DefaultConfig for anything unset or invalid.
+func LoadConfigFromEnv() Config {
+	return Config{
+		HideThreshold: int64(intFromEnv(EnvHideThreshold, DefaultHideThreshold)),
+		Suspension:    time.Duration(intFromEnv(EnvSuspensionDays, int(DefaultSuspension/(24*time.Hour)))) * 24 * time.Hour,
+	}
+}
+
+func intFromEnv(key string, fallback int) int {
+	if s := os.Getenv(key); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			return n
+		}
+	}
+	return fallback
+}
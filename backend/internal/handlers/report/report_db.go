@@ -0,0 +1,434 @@
+package report
+
+import (
+	"errors"
+	"inside-athletics/internal/handlers/comment"
+	"inside-athletics/internal/handlers/post"
+	premiumpost "inside-athletics/internal/handlers/premium_post"
+	"inside-athletics/internal/handlers/sanction"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReportDB struct {
+	db  *gorm.DB
+	cfg Config
+}
+
+// QueueKeyset pages the moderation queue by when each item was first reported, oldest first.
+var QueueKeyset = utils.Keyset{KeyColumn: "q.first_reported_at", IDColumn: "q.content_id"}
+
+// ActionKeyset pages the moderation audit trail newest first.
+var ActionKeyset = utils.Keyset{KeyColumn: "moderation_actions.created_at", IDColumn: "moderation_actions.id", Descending: true}
+
+// recentReports is how many of an item's open reports are shown in the queue.
+const recentReports = 5
+
+// reportable is where each kind of reportable content is stored.
+type reportable struct {
+	table        string
+	authorColumn string
+	titleColumn  string
+	bodyColumn   string
+	postColumn   string
+}
+
+var reportables = map[models.ReportableType]reportable{
+	models.ReportablePost:        {table: "posts", authorColumn: "author_id", titleColumn: "title", bodyColumn: "content", postColumn: "NULL"},
+	models.ReportablePremiumPost: {table: "premium_posts", authorColumn: "author_id", titleColumn: "title", bodyColumn: "content", postColumn: "NULL"},
+	models.ReportableComment:     {table: "comments", authorColumn: "user_id", titleColumn: "NULL", bodyColumn: "description", postColumn: "post_id"},
+}
+
+// contentTables are the utils tables of each kind of reportable content.
+var contentTables = map[models.ReportableType]utils.ContentTable{
+	models.ReportablePost:        utils.PostsTable,
+	models.ReportablePremiumPost: utils.PremiumPostsTable,
+	models.ReportableComment:     utils.CommentsTable,
+}
+
+// content is a reported item, read whether or not it is hidden or deleted.
+type content struct {
+	ID        uuid.UUID
+	AuthorID  uuid.UUID
+	Title     *string
+	Body      string
+	PostID    *uuid.UUID
+	CreatedAt time.Time
+	HiddenAt  *time.Time
+	DeletedAt *time.Time
+}
+
+// NewReportDB creates a new ReportDB instance
+func NewReportDB(db *gorm.DB, cfg Config) *ReportDB {
+	return &ReportDB{db: db, cfg: cfg}
+}
+
+// GetContentScopes returns the sport and college a reported item belongs to.
+func (r *ReportDB) GetContentScopes(contentType models.ReportableType, id uuid.UUID) ([]models.RoleScope, error) {
+	table, ok := contentTables[contentType]
+	if !ok {
+		return nil, huma.Error400BadRequest("Invalid content type")
+	}
+	scopes, err := utils.ContentScopes(r.db, table, id)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Unable to check permissions", err)
+	}
+	return scopes, nil
+}
+
+// getContent reads reported items of one type by ID, including hidden and deleted ones.
+func getContent(db *gorm.DB, contentType models.ReportableType, ids []uuid.UUID) ([]content, error) {
+	t, ok := reportables[contentType]
+	if !ok {
+		return nil, huma.Error400BadRequest("Invalid content type")
+	}
+	var rows []content
+	err := db.Table(t.table).
+		Select("id, "+t.authorColumn+" AS author_id, "+t.titleColumn+" AS title, "+t.bodyColumn+" AS body, "+t.postColumn+" AS post_id, created_at, hidden_at, deleted_at").
+		Where("id IN ?", ids).
+		Find(&rows).Error
+	return rows, err
+}
+
+// GetContentAuthor returns the author of a post, premium post or comment that users can see.
+func (r *ReportDB) GetContentAuthor(contentType models.ReportableType, id uuid.UUID) (uuid.UUID, error) {
+	rows, err := getContent(r.db, contentType, []uuid.UUID{id})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if len(rows) == 0 || rows[0].DeletedAt != nil {
+		return uuid.Nil, huma.Error404NotFound("Resource not found")
+	}
+	return rows[0].AuthorID, nil
+}
+
+// CreateReport saves a report and hides the reported item once it has reached the hide threshold
+// of open reports. A user can only report an item once.
+func (r *ReportDB) CreateReport(report *models.Report) (*models.Report, error) {
+	t := reportables[report.ContentType]
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(report).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			UPDATE `+t.table+` SET hidden_at = NOW()
+			WHERE id = ? AND hidden_at IS NULL AND (
+				SELECT COUNT(*) FROM reports WHERE content_type = ? AND content_id = ? AND status = ?
+			) >= ?`,
+			report.ContentID, report.ContentType, report.ContentID, models.ReportStatusOpen, r.cfg.HideThreshold).Error
+	})
+	return utils.HandleDBError(report, err)
+}
+
+// queueRow is a reported item in the moderation queue.
+type queueRow struct {
+	ContentType     models.ReportableType
+	ContentID       uuid.UUID
+	ReportCount     int64
+	FirstReportedAt time.Time
+	LastReportedAt  time.Time
+}
+
+func queueCursor(row *queueRow) utils.Cursor[time.Time] {
+	return utils.Cursor[time.Time]{Key: row.FirstReportedAt, ID: row.ContentID}
+}
+
+// reportedInScopes is a condition on reports leaving out those about content outside of every
+// scope. Comments are in the scope of their post.
+func reportedInScopes(scopes []models.RoleScope) clause.Expr {
+	var sportIDs, collegeIDs []uuid.UUID
+	for _, scope := range scopes {
+		if scope.Type == models.RoleScopeSport {
+			sportIDs = append(sportIDs, scope.ID)
+		} else {
+			collegeIDs = append(collegeIDs, scope.ID)
+		}
+	}
+	return gorm.Expr(`EXISTS (
+		SELECT 1 FROM (
+			SELECT sport_id, college_id FROM posts WHERE reports.content_type = ? AND id = reports.content_id
+			UNION ALL
+			SELECT sport_id, college_id FROM premium_posts WHERE reports.content_type = ? AND id = reports.content_id
+			UNION ALL
+			SELECT p.sport_id, p.college_id FROM comments c JOIN posts p ON p.id = c.post_id
+			WHERE reports.content_type = ? AND c.id = reports.content_id
+		) AS reported
+		WHERE reported.sport_id IN ? OR reported.college_id IN ?
+	)`, models.ReportablePost, models.ReportablePremiumPost, models.ReportableComment, sportIDs, collegeIDs)
+}
+
+// GetQueue returns a page of the items with open reports, each with its reports, content and
+// author history. contentType limits the queue to one kind of content when it is set, and scopes
+// to content in one of them unless it is nil.
+func (r *ReportDB) GetQueue(page *utils.Page[time.Time], contentType models.ReportableType, scopes []models.RoleScope) ([]QueueItem, utils.PageCursors, int64, error) {
+	groups := r.db.Table("reports").
+		Select("content_type, content_id, COUNT(*) AS report_count, MIN(created_at) AS first_reported_at, MAX(created_at) AS last_reported_at").
+		Where("status = ?", models.ReportStatusOpen).
+		Group("content_type, content_id")
+	if contentType != "" {
+		groups = groups.Where("content_type = ?", contentType)
+	}
+	if scopes != nil {
+		groups = groups.Where(reportedInScopes(scopes))
+	}
+
+	var total int64
+	if page.CountTotal() {
+		if err := r.db.Table("(?) AS q", groups).Count(&total).Error; err != nil {
+			return nil, utils.PageCursors{}, 0, err
+		}
+	}
+
+	var rows []queueRow
+	if err := page.Apply(r.db.Table("(?) AS q", groups)).Find(&rows).Error; err != nil {
+		return nil, utils.PageCursors{}, 0, err
+	}
+	rows, cursors := utils.Results(page, rows, queueCursor)
+	if len(rows) == 0 {
+		return []QueueItem{}, cursors, total, nil
+	}
+
+	items := make([]QueueItem, len(rows))
+	byKey := make(map[models.ReportableType]map[uuid.UUID]*QueueItem)
+	idsByType := make(map[models.ReportableType][]uuid.UUID)
+	contentIDs := make([]uuid.UUID, 0, len(rows))
+	for i, row := range rows {
+		items[i] = QueueItem{
+			ContentType:     row.ContentType,
+			ContentID:       row.ContentID,
+			ReportCount:     row.ReportCount,
+			FirstReportedAt: row.FirstReportedAt,
+			LastReportedAt:  row.LastReportedAt,
+			Reasons:         []ReasonCount{},
+			Reports:         []QueueReport{},
+		}
+		if byKey[row.ContentType] == nil {
+			byKey[row.ContentType] = map[uuid.UUID]*QueueItem{}
+		}
+		byKey[row.ContentType][row.ContentID] = &items[i]
+		idsByType[row.ContentType] = append(idsByType[row.ContentType], row.ContentID)
+		contentIDs = append(contentIDs, row.ContentID)
+	}
+
+	var reasons []struct {
+		ContentType models.ReportableType
+		ContentID   uuid.UUID
+		Reason      models.ReportReason
+		Count       int64
+	}
+	if err := r.db.Table("reports").
+		Select("content_type, content_id, reason, COUNT(*) AS count").
+		Where("status = ? AND content_id IN ?", models.ReportStatusOpen, contentIDs).
+		Group("content_type, content_id, reason").
+		Order("count DESC, reason").
+		Scan(&reasons).Error; err != nil {
+		return nil, utils.PageCursors{}, 0, err
+	}
+	for _, reason := range reasons {
+		if item := byKey[reason.ContentType][reason.ContentID]; item != nil {
+			item.Reasons = append(item.Reasons, ReasonCount{Reason: reason.Reason, Count: reason.Count})
+		}
+	}
+
+	var reports []models.Report
+	if err := r.db.Raw(`
+		SELECT * FROM (
+			SELECT reports.*, ROW_NUMBER() OVER (PARTITION BY content_type, content_id ORDER BY created_at DESC, id DESC) AS n
+			FROM reports
+			WHERE status = ? AND content_id IN ?
+		) AS recent
+		WHERE n <= ?
+		ORDER BY created_at DESC, id DESC`, models.ReportStatusOpen, contentIDs, recentReports).
+		Scan(&reports).Error; err != nil {
+		return nil, utils.PageCursors{}, 0, err
+	}
+	for _, report := range reports {
+		if item := byKey[report.ContentType][report.ContentID]; item != nil {
+			item.Reports = append(item.Reports, QueueReport{
+				ID:         report.ID,
+				ReporterID: report.ReporterID,
+				Reason:     report.Reason,
+				Details:    report.Details,
+				CreatedAt:  report.CreatedAt,
+			})
+		}
+	}
+
+	authors := map[uuid.UUID][]*QueueItem{}
+	for contentType, ids := range idsByType {
+		rows, err := getContent(r.db, contentType, ids)
+		if err != nil {
+			return nil, utils.PageCursors{}, 0, err
+		}
+		for _, row := range rows {
+			item := byKey[contentType][row.ID]
+			item.Content = &ReportedContent{
+				Title:     row.Title,
+				Body:      row.Body,
+				PostID:    row.PostID,
+				CreatedAt: row.CreatedAt,
+				Hidden:    row.HiddenAt != nil,
+				Deleted:   row.DeletedAt != nil,
+			}
+			authors[row.AuthorID] = append(authors[row.AuthorID], item)
+		}
+	}
+
+	authorIDs := make([]uuid.UUID, 0, len(authors))
+	for id := range authors {
+		authorIDs = append(authorIDs, id)
+	}
+	histories, err := r.GetAuthorHistories(authorIDs)
+	if err != nil {
+		return nil, utils.PageCursors{}, 0, err
+	}
+	for _, history := range histories {
+		for _, item := range authors[history.ID] {
+			item.Author = &history
+		}
+	}
+	return items, cursors, total, nil
+}
+
+// GetAuthorHistories returns the moderation history and account status of each of the users.
+func (r *ReportDB) GetAuthorHistories(userIDs []uuid.UUID) ([]AuthorHistory, error) {
+	var histories []AuthorHistory
+	err := r.db.Table("users").
+		Select(`users.id, users.username,
+			COUNT(*) FILTER (WHERE ma.action = ?) AS removals,
+			COUNT(*) FILTER (WHERE ma.action = ?) AS warnings,
+			COUNT(*) FILTER (WHERE ma.action = ?) AS suspensions`,
+			models.ModerationRemove, models.ModerationWarn, models.ModerationSuspend).
+		Joins("LEFT JOIN moderation_actions ma ON ma.target_user_id = users.id").
+		Where("users.id IN ?", userIDs).
+		Group("users.id").
+		Scan(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	inForce, err := sanction.NewSanctionDB(r.db).GetInForce(userIDs...)
+	if err != nil {
+		return nil, err
+	}
+	byUser := map[uuid.UUID][]models.Sanction{}
+	for _, s := range inForce {
+		byUser[s.UserID] = append(byUser[s.UserID], s)
+	}
+	for i := range histories {
+		state := sanction.StateOf(byUser[histories[i].ID])
+		histories[i].Status, histories[i].StatusUntil = state.Status, state.Until
+	}
+	return histories, nil
+}
+
+// TakeAction resolves the open reports on an item and records what the moderator did in the audit
+// trail. Dismissing shows the item again if it was hidden; every other action deletes it, and
+// suspend also sanctions its author with a suspension until suspendUntil.
+func (r *ReportDB) TakeAction(moderatorID uuid.UUID, contentType models.ReportableType, contentID uuid.UUID, action models.ModerationActionType, note *string, suspendUntil time.Time) (*models.ModerationAction, error) {
+	rows, err := getContent(r.db, contentType, []uuid.UUID{contentID})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, huma.Error404NotFound("Resource not found")
+	}
+	item := rows[0]
+
+	record := &models.ModerationAction{
+		ModeratorID:  &moderatorID,
+		Action:       action,
+		ContentType:  contentType,
+		ContentID:    contentID,
+		TargetUserID: &item.AuthorID,
+		Note:         note,
+	}
+	status := models.ReportStatusActioned
+	if action == models.ModerationDismiss {
+		status = models.ReportStatusDismissed
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if action == models.ModerationDismiss {
+			if err := tx.Table(reportables[contentType].table).Where("id = ?", contentID).Update("hidden_at", nil).Error; err != nil {
+				return err
+			}
+		} else if item.DeletedAt == nil {
+			if err := deleteContent(tx, contentType, contentID); err != nil {
+				return err
+			}
+		}
+
+		if action == models.ModerationSuspend {
+			reason := "Suspended for reported " + strings.ReplaceAll(string(contentType), "_", " ")
+			if note != nil {
+				reason = *note
+			}
+			if _, err := sanction.NewSanctionDB(tx).CreateSanction(&models.Sanction{
+				UserID:     item.AuthorID,
+				Type:       models.SanctionSuspend,
+				Reason:     reason,
+				ExpiresAt:  &suspendUntil,
+				IssuedByID: &moderatorID,
+			}); err != nil {
+				return err
+			}
+			record.SuspendedUntil = &suspendUntil
+		}
+
+		resolved := tx.Model(&models.Report{}).
+			Where("content_type = ? AND content_id = ? AND status = ?", contentType, contentID, models.ReportStatusOpen).
+			Updates(map[string]any{"status": status, "resolved_at": time.Now(), "resolved_by_id": moderatorID})
+		if resolved.Error != nil {
+			return resolved.Error
+		}
+		record.ReportsResolved = resolved.RowsAffected
+		return tx.Create(record).Error
+	})
+	if err != nil {
+		var statusErr huma.StatusError
+		if errors.As(err, &statusErr) {
+			return nil, err
+		}
+		return utils.HandleDBError(record, err)
+	}
+	return record, nil
+}
+
+// deleteContent deletes a reported item the same way its author would, so counters and stats
+// stay right.
+func deleteContent(tx *gorm.DB, contentType models.ReportableType, id uuid.UUID) error {
+	switch contentType {
+	case models.ReportablePost:
+		return post.NewPostDB(tx).DeletePost(id)
+	case models.ReportablePremiumPost:
+		return premiumpost.NewPremiumPostDB(tx).DeletePremiumPost(id)
+	case models.ReportableComment:
+		return comment.NewCommentDB(tx).DeleteComment(id)
+	}
+	return huma.Error400BadRequest("Invalid content type")
+}
+
+// GetActions returns a page of the moderation audit trail, only actions against targetUserID when
+// it is set.
+func (r *ReportDB) GetActions(page *utils.Page[time.Time], targetUserID *uuid.UUID) ([]models.ModerationAction, utils.PageCursors, error) {
+	query := r.db.Model(&models.ModerationAction{})
+	if targetUserID != nil {
+		query = query.Where("target_user_id = ?", *targetUserID)
+	}
+	var actions []models.ModerationAction
+	if err := page.Apply(query).Find(&actions).Error; err != nil {
+		return nil, utils.PageCursors{}, err
+	}
+	actions, cursors := utils.Results(page, actions, func(a *models.ModerationAction) utils.Cursor[time.Time] {
+		return utils.Cursor[time.Time]{Key: a.CreatedAt, ID: a.ID}
+	})
+	return actions, cursors, nil
+}
@@ -0,0 +1,21 @@
+package report
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
+	"inside-athletics/internal/utils"
+
+	"github.com/danielgtaylor/huma/v2"
+	"gorm.io/gorm"
+)
+
+func Route(api huma.API, db *gorm.DB, grants *permcache.Cache) {
+	reportService := NewReportService(db, LoadConfigFromEnv(), grants)
+	{
+		grp := huma.NewGroup(api, "/api/v1/report")
+		huma.Post(grp, "/", reportService.CreateReport, utils.Requires(utils.Permission(models.PermissionCreate, "report")))              // Report a post, premium post or comment
+		huma.Get(grp, "/queue", reportService.GetQueue, utils.Requires(utils.Permission(models.PermissionReview, "report").InAnyScope())) // List reported items for moderators
+		huma.Post(grp, "/queue/{content_type}/{content_id}/actions", reportService.TakeAction, utils.Requires(utils.Authenticated))       // Dismiss, remove, warn or suspend
+		huma.Get(grp, "/actions", reportService.GetActions, utils.Requires(utils.Permission(models.PermissionReview, "report")))          // List the moderation audit trail
+	}
+}
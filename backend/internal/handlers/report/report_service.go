@@ -0,0 +1,156 @@
+package report
+
+import (
+	"context"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReportService struct {
+	reportDB *ReportDB
+	cfg      Config
+	// grants are invalidated when a moderation action suspends the author
+	grants *permcache.Cache
+}
+
+// NewReportService creates a new ReportService instance
+func NewReportService(db *gorm.DB, cfg Config, grants *permcache.Cache) *ReportService {
+	return &ReportService{reportDB: NewReportDB(db, cfg), cfg: cfg, grants: grants}
+}
+
+// requirePermission returns the current user if one of their roles grants the action on reports.
+// Taking an action checks it here, since the action is in the body where the permission
+// middleware can't see it. Roles scoped to a sport or college only count when the content is in
+// one of scopes.
+func (s *ReportService) requirePermission(ctx context.Context, action models.PermissionAction, scopes ...models.RoleScope) (uuid.UUID, error) {
+	userID, grants, err := s.currentGrants(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !grants.Allows(action, "report", scopes...) {
+		return uuid.Nil, huma.Error403Forbidden("Insufficient permissions")
+	}
+	return userID, nil
+}
+
+// currentGrants returns the current user and their grants.
+func (s *ReportService) currentGrants(ctx context.Context) (uuid.UUID, *permcache.Grants, error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	grants, err := s.grants.Get(ctx, userID)
+	if err != nil {
+		return uuid.Nil, nil, huma.Error500InternalServerError("Unable to check permissions", err)
+	}
+	return userID, grants, nil
+}
+
+// CreateReport reports a post, premium post or comment for moderators to review
+func (s *ReportService) CreateReport(ctx context.Context, input *CreateReportInput) (*utils.ResponseBody[ReportResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	authorID, err := s.reportDB.GetContentAuthor(input.Body.ContentType, input.Body.ContentID)
+	if err != nil {
+		return nil, err
+	}
+	if authorID == userID {
+		return nil, huma.Error400BadRequest("You can't report your own content")
+	}
+
+	report, err := s.reportDB.CreateReport(&models.Report{
+		ReporterID:  userID,
+		ContentType: input.Body.ContentType,
+		ContentID:   input.Body.ContentID,
+		Reason:      input.Body.Reason,
+		Details:     input.Body.Details,
+		Status:      models.ReportStatusOpen,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[ReportResponse]{Body: toReportResponse(report)}, nil
+}
+
+// GetQueue lists reported items with open reports, longest waiting first. Moderators of a sport or
+// college only see the items in it.
+func (s *ReportService) GetQueue(ctx context.Context, input *GetQueueParams) (*utils.ResponseBody[GetQueueResponse], error) {
+	_, grants, err := s.currentGrants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the route lets moderators scoped anywhere in, so narrow the queue down to their scopes
+	everywhere, scopes := grants.Scopes(models.PermissionReview, "report")
+	if everywhere {
+		scopes = nil
+	} else if len(scopes) == 0 {
+		return nil, huma.Error403Forbidden("Insufficient permissions")
+	}
+	page, err := utils.NewPage[time.Time](QueueKeyset, input.Cursor, input.Limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	items, cursors, total, err := s.reportDB.GetQueue(page, input.ContentType, scopes)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[GetQueueResponse]{
+		Body: &GetQueueResponse{Items: items, Total: total, PageCursors: cursors},
+	}, nil
+}
+
+// TakeAction dismisses a reported item, removes it, or removes it and warns or suspends its author
+func (s *ReportService) TakeAction(ctx context.Context, input *TakeActionInput) (*utils.ResponseBody[models.ModerationAction], error) {
+	scopes, err := s.reportDB.GetContentScopes(input.ContentType, input.ContentID)
+	if err != nil {
+		return nil, err
+	}
+	moderatorID, err := s.requirePermission(ctx, models.PermissionAction(input.Body.Action), scopes...)
+	if err != nil {
+		return nil, err
+	}
+	suspension := s.cfg.Suspension
+	if input.Body.SuspendDays > 0 {
+		suspension = time.Duration(input.Body.SuspendDays) * 24 * time.Hour
+	}
+
+	action, err := s.reportDB.TakeAction(moderatorID, input.ContentType, input.ContentID, input.Body.Action, input.Body.Note, time.Now().Add(suspension))
+	if err != nil {
+		return nil, err
+	}
+	if action.SuspendedUntil != nil && action.TargetUserID != nil {
+		s.grants.Invalidate(ctx, *action.TargetUserID)
+	}
+	return &utils.ResponseBody[models.ModerationAction]{Body: action}, nil
+}
+
+// GetActions lists the moderation audit trail, newest first
+func (s *ReportService) GetActions(ctx context.Context, input *GetActionsParams) (*utils.ResponseBody[GetActionsResponse], error) {
+	var targetUserID *uuid.UUID
+	if input.TargetUserID != "" {
+		id, err := uuid.Parse(input.TargetUserID)
+		if err != nil {
+			return nil, huma.Error400BadRequest("Invalid target_user_id")
+		}
+		targetUserID = &id
+	}
+	page, err := utils.NewPage[time.Time](ActionKeyset, input.Cursor, input.Limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	actions, cursors, err := s.reportDB.GetActions(page, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[GetActionsResponse]{
+		Body: &GetActionsResponse{Actions: actions, PageCursors: cursors},
+	}, nil
+}
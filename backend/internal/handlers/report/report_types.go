@@ -0,0 +1,129 @@
+package report
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateReportInput defines the request for reporting a post, premium post or comment
+type CreateReportInput struct {
+	Body struct {
+		ContentType models.ReportableType `json:"content_type" enum:"post,premium_post,comment" example:"post"`
+		ContentID   uuid.UUID             `json:"content_id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the reported post, premium post or comment"`
+		Reason      models.ReportReason   `json:"reason" enum:"spam,harassment,hate_speech,misinformation,inappropriate,other" example:"spam"`
+		Details     *string               `json:"details,omitempty" maxLength:"1000" example:"Same link posted in every thread" doc:"Anything the moderators should know"`
+	}
+}
+
+// ReportResponse is a report as the reporter sees it
+type ReportResponse struct {
+	ID          uuid.UUID             `json:"id"`
+	ContentType models.ReportableType `json:"content_type" example:"post"`
+	ContentID   uuid.UUID             `json:"content_id"`
+	Reason      models.ReportReason   `json:"reason" example:"spam"`
+	Details     *string               `json:"details,omitempty"`
+	Status      models.ReportStatus   `json:"status" example:"open"`
+	CreatedAt   time.Time             `json:"created_at"`
+}
+
+// GetQueueParams defines the query parameters for the moderation queue
+type GetQueueParams struct {
+	Limit       int                   `query:"limit" default:"20" minimum:"1" maximum:"100" example:"20" doc:"Number of reported items to return"`
+	Cursor      string                `query:"cursor" default:"" doc:"next_cursor or prev_cursor from a previous page"`
+	ContentType models.ReportableType `query:"content_type" enum:"post,premium_post,comment" doc:"Only list one kind of content"`
+}
+
+// ReasonCount is how many open reports gave a reason
+type ReasonCount struct {
+	Reason models.ReportReason `json:"reason" example:"spam"`
+	Count  int64               `json:"count" example:"2"`
+}
+
+// QueueReport is one of the open reports on a queued item
+type QueueReport struct {
+	ID         uuid.UUID           `json:"id"`
+	ReporterID uuid.UUID           `json:"reporter_id"`
+	Reason     models.ReportReason `json:"reason" example:"spam"`
+	Details    *string             `json:"details,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// ReportedContent is the reported item as it was posted, whether or not it is hidden or deleted
+type ReportedContent struct {
+	Title     *string    `json:"title,omitempty" doc:"Left out for comments"`
+	Body      string     `json:"body"`
+	PostID    *uuid.UUID `json:"post_id,omitempty" doc:"The post a comment is on"`
+	CreatedAt time.Time  `json:"created_at"`
+	Hidden    bool       `json:"hidden" doc:"Hidden from everyone but moderators until it is reviewed"`
+	Deleted   bool       `json:"deleted" doc:"Deleted by its author since it was reported"`
+}
+
+// AuthorHistory is the author of reported content and what moderators have done about them before
+type AuthorHistory struct {
+	ID          uuid.UUID            `json:"id"`
+	Username    string               `json:"username" example:"suliproathelete"`
+	Removals    int64                `json:"removals" example:"1" doc:"Content removed without a warning"`
+	Warnings    int64                `json:"warnings" example:"1"`
+	Suspensions int64                `json:"suspensions" example:"0"`
+	Status      models.AccountStatus `json:"status" enum:"active,suspended,banned,shadow_banned" example:"active"`
+	StatusUntil *time.Time           `json:"status_until,omitempty" doc:"When the status ends by itself"`
+}
+
+// QueueItem is a reported item with its open reports
+type QueueItem struct {
+	ContentType     models.ReportableType `json:"content_type" example:"post"`
+	ContentID       uuid.UUID             `json:"content_id"`
+	ReportCount     int64                 `json:"report_count" example:"3"`
+	FirstReportedAt time.Time             `json:"first_reported_at"`
+	LastReportedAt  time.Time             `json:"last_reported_at"`
+	Reasons         []ReasonCount         `json:"reasons" doc:"Most given first"`
+	Reports         []QueueReport         `json:"reports" doc:"The most recent open reports, newest first"`
+	Content         *ReportedContent      `json:"content,omitempty" doc:"Left out if the item no longer exists"`
+	Author          *AuthorHistory        `json:"author,omitempty"`
+}
+
+// GetQueueResponse defines the response for the moderation queue
+type GetQueueResponse struct {
+	Items []QueueItem `json:"items" doc:"Longest waiting first"`
+	Total int64       `json:"total" doc:"Items in the queue. Only counted on the first page"`
+	utils.PageCursors
+}
+
+// TakeActionInput defines the request for acting on a reported item
+type TakeActionInput struct {
+	ContentType models.ReportableType `path:"content_type" enum:"post,premium_post,comment" example:"post"`
+	ContentID   uuid.UUID             `path:"content_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Body        struct {
+		Action      models.ModerationActionType `json:"action" enum:"dismiss,remove,warn,suspend" example:"remove" doc:"Every action but dismiss deletes the content"`
+		Note        *string                     `json:"note,omitempty" maxLength:"1000" example:"Repeated spam"`
+		SuspendDays int                         `json:"suspend_days,omitempty" minimum:"0" maximum:"365" example:"7" doc:"How long a suspend lasts. Defaults to the server's suspension length"`
+	}
+}
+
+// GetActionsParams defines the query parameters for listing the moderation audit trail
+type GetActionsParams struct {
+	Limit        int    `query:"limit" default:"20" minimum:"1" maximum:"100" example:"20" doc:"Number of actions to return"`
+	Cursor       string `query:"cursor" default:"" doc:"next_cursor or prev_cursor from a previous page"`
+	TargetUserID string `query:"target_user_id" default:"" doc:"Only list actions against this author"`
+}
+
+// GetActionsResponse defines the response for listing moderation actions
+type GetActionsResponse struct {
+	Actions []models.ModerationAction `json:"actions" doc:"Newest first"`
+	utils.PageCursors
+}
+
+func toReportResponse(r *models.Report) *ReportResponse {
+	return &ReportResponse{
+		ID:          r.ID,
+		ContentType: r.ContentType,
+		ContentID:   r.ContentID,
+		Reason:      r.Reason,
+		Details:     r.Details,
+		Status:      r.Status,
+		CreatedAt:   r.CreatedAt,
+	}
+}
@@ -1,20 +1,24 @@
 package role
 
 import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
 
-func Route(api huma.API, db *gorm.DB) {
-	roleService := NewRoleService(db)
+func Route(api huma.API, db *gorm.DB, grants *permcache.Cache) {
+	roleService := NewRoleService(db, grants)
 
 	{
 		grp := huma.NewGroup(api, "/api/v1/role")
-		huma.Post(grp, "/", roleService.CreateRole)
-		huma.Post(grp, "/basic", roleService.CreateRoleNameOnly)
+		huma.Post(grp, "/", roleService.CreateRole, utils.Requires(utils.Permission(models.PermissionCreate, "role")))
+		huma.Post(grp, "/basic", roleService.CreateRoleNameOnly, utils.Requires(utils.Permission(models.PermissionCreate, "role")))
 		huma.Get(grp, "/{id}", roleService.GetRoleByID)
-		huma.Patch(grp, "/{id}", roleService.UpdateRole)
-		huma.Delete(grp, "/{id}", roleService.DeleteRole)
+		huma.Patch(grp, "/{id}", roleService.UpdateRole, utils.Requires(utils.Permission(models.PermissionUpdate, "role")))
+		huma.Delete(grp, "/{id}", roleService.DeleteRole, utils.Requires(utils.Permission(models.PermissionDelete, "role")))
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/roles")
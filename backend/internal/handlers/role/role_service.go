@@ -3,6 +3,7 @@ package role
 import (
 	"context"
 	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
 	"inside-athletics/internal/utils"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -12,11 +13,14 @@ import (
 
 type RoleService struct {
 	roleDB *RoleDB
+	// grants are invalidated when a role's permissions change
+	grants *permcache.Cache
 }
 
-func NewRoleService(db *gorm.DB) *RoleService {
+func NewRoleService(db *gorm.DB, grants *permcache.Cache) *RoleService {
 	return &RoleService{
 		roleDB: NewRoleDB(db),
+		grants: grants,
 	}
 }
 
@@ -122,6 +126,9 @@ func (r *RoleService) UpdateRole(ctx context.Context, input *struct {
 	if err != nil {
 		return nil, err
 	}
+	if input.Body.Permissions != nil {
+		r.grants.InvalidateAll(ctx)
+	}
 
 	return &utils.ResponseBody[RoleResponse]{
 		Body: toRoleResponse(updated),
@@ -137,6 +144,7 @@ func (r *RoleService) DeleteRole(ctx context.Context, input *DeleteRoleRequest)
 	if err := r.roleDB.DeleteRole(input.ID); err != nil {
 		return nil, err
 	}
+	r.grants.InvalidateAll(ctx)
 
 	return &utils.ResponseBody[RoleResponse]{
 		Body: toRoleResponse(role),
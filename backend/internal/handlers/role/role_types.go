@@ -26,6 +26,7 @@ type RoleResponse struct {
 	ID          uuid.UUID                       `json:"id" example:"1" doc:"ID of the role"`
 	Name        models.RoleName                 `json:"name" example:"admin" doc:"Name of the role"`
 	Permissions []permission.PermissionResponse `json:"permissions,omitempty" doc:"Permissions attached to the role"`
+	Scope       *models.RoleScope               `json:"scope,omitempty" doc:"The sport or college a user's role is limited to, when it is"`
 }
 
 type GetRoleByIDParams struct {
@@ -0,0 +1,100 @@
+package sanction
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SanctionDB struct {
+	db *gorm.DB
+}
+
+// SanctionKeyset pages sanctions newest first.
+var SanctionKeyset = utils.Keyset{KeyColumn: "s.created_at", IDColumn: "s.id", Descending: true}
+
+func sanctionCursor(s *models.Sanction) utils.Cursor[time.Time] {
+	return utils.Cursor[time.Time]{Key: s.CreatedAt, ID: s.ID}
+}
+
+// NewSanctionDB creates a new SanctionDB instance
+func NewSanctionDB(db *gorm.DB) *SanctionDB {
+	return &SanctionDB{db: db}
+}
+
+// GetInForce returns the sanctions in force on each of the users.
+func (s *SanctionDB) GetInForce(userIDs ...uuid.UUID) ([]models.Sanction, error) {
+	var sanctions []models.Sanction
+	err := s.db.Table("sanctions AS s").
+		Where("s.user_id IN ? AND "+utils.SanctionInForce, userIDs).
+		Order("s.created_at DESC").
+		Find(&sanctions).Error
+	return sanctions, err
+}
+
+// GetAccountState returns the user's account status. Users without a row yet are active.
+func (s *SanctionDB) GetAccountState(userID uuid.UUID) (*AccountState, error) {
+	inForce, err := s.GetInForce(userID)
+	if err != nil {
+		return nil, err
+	}
+	return StateOf(inForce), nil
+}
+
+// CreateSanction puts a sanction on a user
+func (s *SanctionDB) CreateSanction(sanction *models.Sanction) (*models.Sanction, error) {
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id = ?", sanction.UserID).Count(&count).Error; err != nil {
+		return utils.HandleDBError(sanction, err)
+	}
+	if count == 0 {
+		return nil, huma.Error404NotFound("User not found")
+	}
+	return utils.HandleDBError(sanction, s.db.Create(sanction).Error)
+}
+
+// LiftSanction ends a sanction before it expires. A sanction can only be lifted once.
+func (s *SanctionDB) LiftSanction(id uuid.UUID, liftedByID uuid.UUID, reason *string) (*models.Sanction, error) {
+	var sanction models.Sanction
+	if err := s.db.First(&sanction, "id = ?", id).Error; err != nil {
+		return utils.HandleDBError(&sanction, err)
+	}
+	if sanction.LiftedAt != nil {
+		return nil, huma.Error409Conflict("Sanction already lifted")
+	}
+
+	now := time.Now()
+	dbResponse := s.db.Model(&sanction).
+		Where("lifted_at IS NULL").
+		Updates(map[string]any{"lifted_at": now, "lifted_by_id": liftedByID, "lift_reason": reason})
+	if dbResponse.Error != nil {
+		return utils.HandleDBError(&sanction, dbResponse.Error)
+	}
+	if dbResponse.RowsAffected == 0 {
+		return nil, huma.Error409Conflict("Sanction already lifted")
+	}
+	sanction.LiftedAt, sanction.LiftedByID, sanction.LiftReason = &now, &liftedByID, reason
+	return &sanction, nil
+}
+
+// GetSanctions returns a page of sanctions, only userID's when it is set and only those in force
+// when inForceOnly is set.
+func (s *SanctionDB) GetSanctions(page *utils.Page[time.Time], userID *uuid.UUID, inForceOnly bool) ([]models.Sanction, utils.PageCursors, error) {
+	query := s.db.Table("sanctions AS s")
+	if userID != nil {
+		query = query.Where("s.user_id = ?", *userID)
+	}
+	if inForceOnly {
+		query = query.Where(utils.SanctionInForce)
+	}
+	var sanctions []models.Sanction
+	if err := page.Apply(query).Find(&sanctions).Error; err != nil {
+		return nil, utils.PageCursors{}, err
+	}
+	sanctions, cursors := utils.Results(page, sanctions, sanctionCursor)
+	return sanctions, cursors, nil
+}
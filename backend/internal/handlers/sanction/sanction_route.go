@@ -0,0 +1,21 @@
+package sanction
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
+	"inside-athletics/internal/utils"
+
+	"github.com/danielgtaylor/huma/v2"
+	"gorm.io/gorm"
+)
+
+func Route(api huma.API, db *gorm.DB, grants *permcache.Cache) {
+	sanctionService := NewSanctionService(db, grants)
+	{
+		grp := huma.NewGroup(api, "/api/v1/sanctions")
+		huma.Post(grp, "/", sanctionService.CreateSanction, utils.Requires(utils.Permission(models.PermissionCreate, "sanction")))              // Suspend, ban or shadow-ban a user
+		huma.Get(grp, "/", sanctionService.GetSanctions, utils.Requires(utils.Permission(models.PermissionRead, "sanction")))                   // List sanctions
+		huma.Get(grp, "/users/{user_id}", sanctionService.GetAccountState, utils.Requires(utils.Permission(models.PermissionRead, "sanction"))) // Get a user's account status
+		huma.Delete(grp, "/{id}", sanctionService.LiftSanction, utils.Requires(utils.Permission(models.PermissionDelete, "sanction")))          // Lift a sanction
+	}
+}
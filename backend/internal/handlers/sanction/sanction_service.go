@@ -0,0 +1,100 @@
+package sanction
+
+import (
+	"context"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SanctionService struct {
+	sanctionDB *SanctionDB
+	// grants are invalidated when a user's sanctions change, since they carry the account status
+	grants *permcache.Cache
+}
+
+// NewSanctionService creates a new SanctionService instance
+func NewSanctionService(db *gorm.DB, grants *permcache.Cache) *SanctionService {
+	return &SanctionService{sanctionDB: NewSanctionDB(db), grants: grants}
+}
+
+// CreateSanction suspends, bans or shadow-bans a user
+func (s *SanctionService) CreateSanction(ctx context.Context, input *CreateSanctionInput) (*utils.ResponseBody[models.Sanction], error) {
+	issuerID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if input.Body.UserID == issuerID {
+		return nil, huma.Error400BadRequest("You can't sanction yourself")
+	}
+	if input.Body.ExpiresAt == nil && input.Body.Type == models.SanctionSuspend {
+		return nil, huma.Error400BadRequest("Suspensions need an expires_at")
+	}
+	if input.Body.ExpiresAt != nil && !input.Body.ExpiresAt.After(time.Now()) {
+		return nil, huma.Error400BadRequest("expires_at must be in the future")
+	}
+
+	sanction, err := s.sanctionDB.CreateSanction(&models.Sanction{
+		UserID:     input.Body.UserID,
+		Type:       input.Body.Type,
+		Reason:     input.Body.Reason,
+		ExpiresAt:  input.Body.ExpiresAt,
+		IssuedByID: &issuerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.grants.Invalidate(ctx, sanction.UserID)
+	return &utils.ResponseBody[models.Sanction]{Body: sanction}, nil
+}
+
+// LiftSanction ends a sanction before it expires
+func (s *SanctionService) LiftSanction(ctx context.Context, input *LiftSanctionInput) (*utils.ResponseBody[models.Sanction], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sanction, err := s.sanctionDB.LiftSanction(input.ID, userID, input.Body.Reason)
+	if err != nil {
+		return nil, err
+	}
+	s.grants.Invalidate(ctx, sanction.UserID)
+	return &utils.ResponseBody[models.Sanction]{Body: sanction}, nil
+}
+
+// GetSanctions lists sanctions, newest first.
+func (s *SanctionService) GetSanctions(ctx context.Context, input *GetSanctionsParams) (*utils.ResponseBody[GetSanctionsResponse], error) {
+	var userID *uuid.UUID
+	if input.UserID != "" {
+		id, err := uuid.Parse(input.UserID)
+		if err != nil {
+			return nil, huma.Error400BadRequest("Invalid user_id")
+		}
+		userID = &id
+	}
+	page, err := utils.NewPage[time.Time](SanctionKeyset, input.Cursor, input.Limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	sanctions, cursors, err := s.sanctionDB.GetSanctions(page, userID, input.InForceOnly)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[GetSanctionsResponse]{
+		Body: &GetSanctionsResponse{Sanctions: sanctions, PageCursors: cursors},
+	}, nil
+}
+
+// GetAccountState returns a user's account status with the sanctions behind it
+func (s *SanctionService) GetAccountState(ctx context.Context, input *GetAccountStateParams) (*utils.ResponseBody[AccountState], error) {
+	state, err := s.sanctionDB.GetAccountState(input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[AccountState]{Body: state}, nil
+}
@@ -0,0 +1,88 @@
+package sanction
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateSanctionInput defines the request for sanctioning a user
+type CreateSanctionInput struct {
+	Body struct {
+		UserID    uuid.UUID           `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+		Type      models.SanctionType `json:"type" enum:"suspend,ban,shadow_ban" example:"suspend"`
+		Reason    string              `json:"reason" minLength:"1" maxLength:"1000" example:"Harassing other users in comments"`
+		ExpiresAt *time.Time          `json:"expires_at,omitempty" doc:"When the sanction ends. Required for suspensions; bans and shadow bans without one last until they are lifted"`
+	}
+}
+
+// LiftSanctionInput defines the request for lifting a sanction early
+type LiftSanctionInput struct {
+	ID   uuid.UUID `path:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Body struct {
+		Reason *string `json:"reason,omitempty" maxLength:"1000" example:"Appeal accepted"`
+	}
+}
+
+// GetSanctionsParams defines the query parameters for listing sanctions
+type GetSanctionsParams struct {
+	Limit       int    `query:"limit" default:"20" minimum:"1" maximum:"100" example:"20" doc:"Number of sanctions to return"`
+	Cursor      string `query:"cursor" default:"" doc:"next_cursor or prev_cursor from a previous page"`
+	UserID      string `query:"user_id" default:"" doc:"Only list this user's sanctions"`
+	InForceOnly bool   `query:"in_force_only" default:"false" doc:"Leave out sanctions that expired or were lifted"`
+}
+
+// GetSanctionsResponse defines the response for listing sanctions
+type GetSanctionsResponse struct {
+	Sanctions []models.Sanction `json:"sanctions" doc:"Newest first"`
+	utils.PageCursors
+}
+
+// GetAccountStateParams defines the path parameters for reading a user's account status
+type GetAccountStateParams struct {
+	UserID uuid.UUID `path:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
+
+// AccountState is a user's account status and the sanctions in force behind it. A ban outranks a
+// suspension, which outranks a shadow ban.
+type AccountState struct {
+	Status models.AccountStatus `json:"status" enum:"active,suspended,banned,shadow_banned" example:"suspended"`
+	// Until is left out when the status lasts until it is lifted, and for active accounts
+	Until     *time.Time        `json:"until,omitempty" doc:"When the status ends by itself"`
+	Sanctions []models.Sanction `json:"sanctions" doc:"Every sanction in force"`
+}
+
+// statusBySanction is the account status each sanction puts a user in, strongest first.
+var statusBySanction = []struct {
+	sanction models.SanctionType
+	status   models.AccountStatus
+}{
+	{models.SanctionBan, models.AccountBanned},
+	{models.SanctionSuspend, models.AccountSuspended},
+	{models.SanctionShadowBan, models.AccountShadowBanned},
+}
+
+// StateOf works out a user's account state from their sanctions in force.
+func StateOf(inForce []models.Sanction) *AccountState {
+	state := &AccountState{Status: models.AccountActive, Sanctions: inForce}
+	for _, s := range statusBySanction {
+		found := false
+		var until *time.Time
+		for i := range inForce {
+			if inForce[i].Type != s.sanction {
+				continue
+			}
+			if expires := inForce[i].ExpiresAt; !found || (until != nil && (expires == nil || expires.After(*until))) {
+				until = expires
+			}
+			found = true
+		}
+		if found {
+			state.Status, state.Until = s.status, until
+			return state
+		}
+	}
+	return state
+}
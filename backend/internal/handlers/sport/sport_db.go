@@ -12,6 +12,13 @@ type SportDB struct {
 	db *gorm.DB
 }
 
+// sportKeyset pages sports alphabetically.
+var sportKeyset = utils.Keyset{KeyColumn: "sports.name", IDColumn: "sports.id"}
+
+func sportCursor(sport *models.Sport) utils.Cursor[string] {
+	return utils.Cursor[string]{Key: sport.Name, ID: sport.ID}
+}
+
 // NewSportDB creates a new SportDB instance
 func NewSportDB(db *gorm.DB) *SportDB {
 	return &SportDB{db: db}
@@ -44,22 +51,25 @@ func (s *SportDB) GetSportByName(name string) (*models.Sport, error) {
 	return utils.HandleDBError(&sport, dbResponse.Error)
 }
 
-// GetAllSports retrieves all sports with optional pagination
-func (s *SportDB) GetAllSports(limit, offset int) ([]models.Sport, int64, error) {
+// GetAllSports retrieves all sports, alphabetically, one page at a time
+func (s *SportDB) GetAllSports(page *utils.Page[string]) ([]models.Sport, utils.PageCursors, int64, error) {
 	var sports []models.Sport
 	var total int64
 
 	// Get total count
-	if err := s.db.Model(&models.Sport{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+	if page.CountTotal() {
+		if err := s.db.Model(&models.Sport{}).Count(&total).Error; err != nil {
+			return nil, utils.PageCursors{}, 0, err
+		}
 	}
 
 	// Get paginated results
-	if err := s.db.Limit(limit).Offset(offset).Find(&sports).Error; err != nil {
-		return nil, 0, err
+	if err := page.Apply(s.db.Model(&models.Sport{})).Find(&sports).Error; err != nil {
+		return nil, utils.PageCursors{}, 0, err
 	}
 
-	return sports, total, nil
+	sports, cursors := utils.Results(page, sports, sportCursor)
+	return sports, cursors, total, nil
 }
 
 // UpdateSport updates an existing sport by ID
@@ -88,6 +98,6 @@ func (s *SportDB) DeleteSport(id uuid.UUID) error {
 	return nil
 }
 
-func (s *SportDB) FuzzySearchFor(searchStr string, limit int) ([]models.Sport, error) {
-	return utils.FuzzySearchForDB(searchStr, limit, s.db, "name", models.Sport{})
+func (s *SportDB) FuzzySearchFor(input *utils.SearchParam) ([]models.Sport, int64, error) {
+	return utils.FuzzySearchForDB[models.Sport](s.db, input.Search(utils.Column("name")))
 }
@@ -1,6 +1,9 @@
 package sport
 
 import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
@@ -10,11 +13,11 @@ func Route(api huma.API, db *gorm.DB) {
 
 	{
 		grp := huma.NewGroup(api, "/api/v1/sport")
-		huma.Post(grp, "/", sportService.CreateSport)                 // Create sport
-		huma.Get(grp, "/by-name/{name}", sportService.GetSportByName) // Read sport by name
-		huma.Get(grp, "/{id}", sportService.GetSportByID)             // Read sport by ID
-		huma.Patch(grp, "/{id}", sportService.UpdateSport)            // Update sport
-		huma.Delete(grp, "/{id}", sportService.DeleteSport)           // Delete sport
+		huma.Post(grp, "/", sportService.CreateSport, utils.Requires(utils.Permission(models.PermissionCreate, "sport")))                                                      // Create sport
+		huma.Get(grp, "/by-name/{name}", sportService.GetSportByName)                                                                                                          // Read sport by name
+		huma.Get(grp, "/{id}", sportService.GetSportByID)                                                                                                                      // Read sport by ID
+		huma.Patch(grp, "/{id}", sportService.UpdateSport, utils.Requires(utils.Permission(models.PermissionUpdate, "sport").InScopes(utils.IsScope(models.RoleScopeSport))))  // Update sport
+		huma.Delete(grp, "/{id}", sportService.DeleteSport, utils.Requires(utils.Permission(models.PermissionDelete, "sport").InScopes(utils.IsScope(models.RoleScopeSport)))) // Delete sport
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/sports")
@@ -2,7 +2,6 @@ package sport
 
 import (
 	"context"
-	"inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -52,7 +51,11 @@ func (s *SportService) GetSportByName(ctx context.Context, input *GetSportByName
 }
 
 func (s *SportService) GetAllSports(ctx context.Context, input *GetAllSportsParams) (*utils.ResponseBody[GetAllSportsResponse], error) {
-	sports, total, err := s.sportDB.GetAllSports(input.Limit, input.Offset)
+	page, err := utils.NewPage[string](sportKeyset, input.Cursor, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	sports, cursors, total, err := s.sportDB.GetAllSports(page)
 	if err != nil {
 		return nil, err
 	}
@@ -64,8 +67,9 @@ func (s *SportService) GetAllSports(ctx context.Context, input *GetAllSportsPara
 
 	return &utils.ResponseBody[GetAllSportsResponse]{
 		Body: &GetAllSportsResponse{
-			Sports: sportResponses,
-			Total:  int(total),
+			Sports:      sportResponses,
+			Total:       int(total),
+			PageCursors: cursors,
 		},
 	}, nil
 }
@@ -122,5 +126,5 @@ func (s *SportService) DeleteSport(ctx context.Context, input *DeleteSportReques
 }
 
 func (s *SportService) FuzzySearchFor(ctx context.Context, input *utils.SearchParam) (*utils.ResponseBody[utils.SearchResults[*SportResponse]], error) {
-	return utils.FuzzySearchService(input, models.Sport{}, SportResponse{}, "name", s.sportDB.db, ToSportResponse)
+	return utils.FuzzySearchService(input, s.sportDB.FuzzySearchFor, ToSportResponse)
 }
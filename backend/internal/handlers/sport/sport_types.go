@@ -2,6 +2,7 @@ package sport
 
 import (
 	models "inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
 
 	"github.com/google/uuid"
 )
@@ -19,14 +20,16 @@ type CreateSportRequest struct {
 
 // GetAllSportsParams defines query parameters for getting all sports
 type GetAllSportsParams struct {
-	Limit  int `query:"limit" default:"50" example:"50" doc:"Number of sports to return"`
-	Offset int `query:"offset" default:"0" example:"0" doc:"Number of sports to skip"`
+	Limit  int    `query:"limit" default:"50" example:"50" doc:"Number of sports to return"`
+	Cursor string `query:"cursor" default:"" example:"eyJrIjoiSG9ja2V5IiwiaWQiOiIxMjNlNDU2Ny1lODliLTEyZDMtYTQ1Ni00MjY2MTQxNzQwMDAifQ" doc:"next_cursor or prev_cursor from a previous page. Takes precedence over offset"`
+	Offset int    `query:"offset" default:"0" example:"0" deprecated:"true" doc:"Number of sports to skip. Deprecated: use cursor"`
 }
 
 // GetAllSportsResponse defines the response for getting all sports
 type GetAllSportsResponse struct {
 	Sports []SportResponse `json:"sports" doc:"List of sports"`
-	Total  int             `json:"total" example:"25" doc:"Total number of sports"`
+	Total  int             `json:"total" example:"25" doc:"Total number of sports. Not counted for pages requested with a cursor (0)"`
+	utils.PageCursors
 }
 
 // GetSportByIDParams defines parameters for getting a sport by ID
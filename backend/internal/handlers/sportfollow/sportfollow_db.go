@@ -3,6 +3,7 @@ package sportfollow
 import (
 	"inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
@@ -13,12 +14,27 @@ type SportFollowDB struct {
 	db *gorm.DB
 }
 
-func (u *SportFollowDB) GetSportFollowsByUser(user_id uuid.UUID) (*[]uuid.UUID, error) {
-	var sportIDs []uuid.UUID
-	dbResponse := u.db.Model(&models.SportFollow{}).
-		Where("user_id = ?", user_id).
-		Pluck("sport_id", &sportIDs)
-	return utils.HandleDBError(&sportIDs, dbResponse.Error)
+// sportFollowKeyset pages a user's followed sports, most recently followed first.
+var sportFollowKeyset = utils.Keyset{KeyColumn: "sport_follows.created_at", IDColumn: "sport_follows.id", Descending: true}
+
+func sportFollowCursor(follow *models.SportFollow) utils.Cursor[time.Time] {
+	return utils.Cursor[time.Time]{Key: follow.CreatedAt, ID: follow.ID}
+}
+
+func (u *SportFollowDB) GetSportFollowsByUser(user_id uuid.UUID, page *utils.Page[time.Time]) (*[]uuid.UUID, utils.PageCursors, error) {
+	var follows []models.SportFollow
+	dbResponse := page.Apply(u.db.Model(&models.SportFollow{}).
+		Select("id", "sport_id", "created_at").
+		Where("user_id = ?", user_id)).
+		Find(&follows)
+	if dbResponse.Error != nil {
+		_, err := utils.HandleDBError(&follows, dbResponse.Error)
+		return nil, utils.PageCursors{}, err
+	}
+
+	follows, cursors := utils.Results(page, follows, sportFollowCursor)
+	sportIDs := utils.MapList(follows, func(f models.SportFollow) uuid.UUID { return f.SportID })
+	return &sportIDs, cursors, nil
 }
 
 func (u *SportFollowDB) GetFollowingUsersBySport(sport_id uuid.UUID) (*[]uuid.UUID, error) {
@@ -1,6 +1,9 @@
 package sportfollow
 
 import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
@@ -11,9 +14,9 @@ func Route(api huma.API, db *gorm.DB) {
 	var sportFollowService = &SportFollowService{sportfollowDB: sportFollowDB}
 	{
 		grp := huma.NewGroup(api, "/api/v1/user/sport")
-		huma.Post(grp, "/", sportFollowService.CreateSportFollow)
+		huma.Post(grp, "/", sportFollowService.CreateSportFollow, utils.Requires(utils.Permission(models.PermissionCreate, "sportfollow")))
 		huma.Get(grp, "/follows", sportFollowService.GetSportFollowsByUser)
 		huma.Get(grp, "/{sport_id}/users", sportFollowService.GetFollowingUsersBySport)
-		huma.Delete(grp, "/{id}", sportFollowService.DeleteSportFollow)
+		huma.Delete(grp, "/{id}", sportFollowService.DeleteSportFollow, utils.Requires(utils.Permission(models.PermissionDelete, "sportfollow").OwnedBy(utils.OwnedBy("sport_follows", "user_id"))))
 	}
 }
@@ -4,49 +4,33 @@ import (
 	"context"
 	"inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
-
-	"github.com/danielgtaylor/huma/v2"
-	"github.com/google/uuid"
+	"time"
 )
 
 type SportFollowService struct {
 	sportfollowDB *SportFollowDB
 }
 
-func (u *SportFollowService) getCurrentUserID(ctx context.Context) (uuid.UUID, error) {
-	rawID := ctx.Value("user_id")
-	if rawID == nil {
-		return uuid.Nil, huma.Error401Unauthorized("User not authenticated")
-	}
-
-	userID, ok := rawID.(string)
-	if !ok {
-		return uuid.Nil, huma.Error500InternalServerError("Invalid user ID in context")
-	}
-
-	parsedID, err := uuid.Parse(userID)
-	if err != nil {
-		return uuid.Nil, huma.Error400BadRequest("Invalid user ID", err)
-	}
-
-	return parsedID, nil
-}
-
 // Given a UserID, get all the sports that they follow
 func (u *SportFollowService) GetSportFollowsByUser(ctx context.Context, input *GetSportFollowsByUserParams) (*utils.ResponseBody[GetSportFollowsByUserResponse], error) {
-	userID, err := u.getCurrentUserID(ctx)
+	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
-	sports, err := u.sportfollowDB.GetSportFollowsByUser(userID)
 	respBody := &utils.ResponseBody[GetSportFollowsByUserResponse]{}
+	page, err := utils.NewPage[time.Time](sportFollowKeyset, input.Cursor, input.Limit, 0)
+	if err != nil {
+		return respBody, err
+	}
+	sports, cursors, err := u.sportfollowDB.GetSportFollowsByUser(userID, page)
 	if err != nil {
 		return respBody, err
 	}
 
 	response := &GetSportFollowsByUserResponse{
-		UserID:   userID,
-		SportIDs: *sports,
+		UserID:      userID,
+		SportIDs:    *sports,
+		PageCursors: cursors,
 	}
 
 	return &utils.ResponseBody[GetSportFollowsByUserResponse]{
@@ -76,11 +60,11 @@ func (u *SportFollowService) GetFollowingUsersBySport(ctx context.Context, input
 
 // Given a sport and a user, creates a sport follow if doesn't already exist
 func (u *SportFollowService) CreateSportFollow(ctx context.Context, input *CreateSportFollowInput) (*utils.ResponseBody[CreateSportFollowResponse], error) {
-	userID, err := u.getCurrentUserID(ctx)
+	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	sportfollow := &models.SportFollow{
 		SportID: input.Body.SportID,
 		UserID:  userID,
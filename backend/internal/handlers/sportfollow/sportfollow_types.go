@@ -1,9 +1,15 @@
 package sportfollow
 
-import "github.com/google/uuid"
+import (
+	"inside-athletics/internal/utils"
+
+	"github.com/google/uuid"
+)
 
 // Given UserID, get all sports that are followed
 type GetSportFollowsByUserParams struct {
+	Limit  int    `query:"limit" default:"100" example:"100" doc:"Number of followed sports to return"`
+	Cursor string `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page"`
 }
 
 // Given sport, get list of UserIDs that follow this sport
@@ -14,6 +20,7 @@ type GetFollowingUsersBySportParams struct {
 type GetSportFollowsByUserResponse struct {
 	UserID   uuid.UUID   `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the user"`
 	SportIDs []uuid.UUID `json:"sport_ids" example:"[\"123e4567-e89b-12d3-a456-426614174000\",\"123e4567-e89b-12d3-a456-426614174001\"]" doc:"The sports the given user follows"`
+	utils.PageCursors
 }
 
 type GetFollowingUsersBySportResponse struct {
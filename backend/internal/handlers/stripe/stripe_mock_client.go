@@ -219,6 +219,9 @@ func (m *MockStripeClient) CreateSession(p *stripego.CheckoutSessionParams) (*st
 		Status:  stripego.CheckoutSessionStatus("open"),
 		Created: time.Now().Unix(),
 	}
+	if p.Customer != nil {
+		sess.Customer = &stripego.Customer{ID: *p.Customer}
+	}
 	m.sessions[sess.ID] = sess
 	return sess, nil
 }
@@ -1,14 +1,23 @@
 package stripe
 
 import (
+	"inside-athletics/internal/mailer"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 // RegisterWebhookRoute registers the Stripe webhook on the raw Fiber app (bypassing Huma/auth).
-func RegisterWebhookRoute(router *fiber.App, db *gorm.DB) {
+// Receipts for paid invoices are sent with m, and grants are invalidated when a subscription changes
+// a user's roles.
+func RegisterWebhookRoute(router *fiber.App, db *gorm.DB, m *mailer.Mailer, grants *permcache.Cache) {
 	svc := NewStripeService(db)
+	svc.mailer = m
+	svc.grants = grants
 	router.Post("/api/v1/stripe/webhook", svc.HandleWebhook)
 }
 
@@ -23,17 +32,17 @@ func Route(api huma.API, db *gorm.DB) {
 func registerRoutes(api huma.API, svc *StripeService) {
 	{
 		grp := huma.NewGroup(api, "/api/v1/stripe_product")
-		huma.Post(grp, "/", svc.CreateStripeProduct)
+		huma.Post(grp, "/", svc.CreateStripeProduct, utils.Requires(utils.Permission(models.PermissionCreate, "stripe_product")))
 		huma.Get(grp, "/{id}", svc.GetStripeProductByID)
-		huma.Patch(grp, "/{id}", svc.UpdateStripeProduct)
-		huma.Delete(grp, "/{id}", svc.ArchiveStripeProduct)
+		huma.Patch(grp, "/{id}", svc.UpdateStripeProduct, utils.Requires(utils.Permission(models.PermissionUpdate, "stripe_product")))
+		huma.Delete(grp, "/{id}", svc.ArchiveStripeProduct, utils.Requires(utils.Permission(models.PermissionDelete, "stripe_product")))
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/stripe_price")
-		huma.Post(grp, "/", svc.CreateStripePrice)
+		huma.Post(grp, "/", svc.CreateStripePrice, utils.Requires(utils.Permission(models.PermissionCreate, "stripe_price")))
 		huma.Get(grp, "/{id}", svc.GetStripePriceByID)
-		huma.Patch(grp, "/{id}", svc.UpdateStripePrice)
-		huma.Delete(grp, "/{id}", svc.ArchiveStripePrice)
+		huma.Patch(grp, "/{id}", svc.UpdateStripePrice, utils.Requires(utils.Permission(models.PermissionUpdate, "stripe_price")))
+		huma.Delete(grp, "/{id}", svc.ArchiveStripePrice, utils.Requires(utils.Permission(models.PermissionDelete, "stripe_price")))
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/stripe_products")
@@ -45,17 +54,23 @@ func registerRoutes(api huma.API, svc *StripeService) {
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/stripe_customers")
-		huma.Post(grp, "/", svc.RegisterStripeCustomer)
+		huma.Post(grp, "/", svc.RegisterStripeCustomer, utils.Requires(utils.Authenticated))
 		huma.Get(grp, "/{id}", svc.GetStripeCustomer)
 		huma.Get(grp, "/email/{email}", svc.GetStripeCustomerByEmail)
-		huma.Patch(grp, "/{id}", svc.UpdateStripeCustomer)
-		huma.Delete(grp, "/{id}", svc.DeleteStripeCustomer)
+		huma.Patch(grp, "/{id}", svc.UpdateStripeCustomer, utils.Requires(customerAccess(models.PermissionUpdate)))
+		huma.Delete(grp, "/{id}", svc.DeleteStripeCustomer, utils.Requires(customerAccess(models.PermissionDelete)))
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/checkout/sessions")
-		huma.Post(grp, "/", svc.CreateStripeCheckoutSession)
+		huma.Post(grp, "/", svc.CreateStripeCheckoutSession, utils.Requires(utils.Authenticated))
 		huma.Get(grp, "/", svc.GetAllStripeSessions)
 		huma.Get(grp, "/{id}", svc.GetStripeCheckoutSessionByID)
-		huma.Delete(grp, "/{id}", svc.DeleteStripeCheckoutSession)
+		huma.Delete(grp, "/{id}", svc.DeleteStripeCheckoutSession, utils.Requires(utils.Authenticated))
 	}
 }
+
+// customerAccess lets users act on the Stripe customer they were given at checkout, and staff with
+// action on any customer.
+func customerAccess(action models.PermissionAction) utils.Access {
+	return utils.Permission(action, "stripe_customer").OwnedByKey(utils.UserKey("stripe_customer_id"))
+}
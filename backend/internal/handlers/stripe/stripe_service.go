@@ -3,7 +3,10 @@ package stripe
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"inside-athletics/internal/mailer"
 	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
 	"inside-athletics/internal/utils"
 	"os"
 	"time"
@@ -22,6 +25,10 @@ import (
 type StripeService struct {
 	db     *gorm.DB
 	client StripeClient
+	// mailer sends receipts for paid invoices; nil sends none
+	mailer *mailer.Mailer
+	// grants are invalidated when a subscription adds or removes premium_user; nil invalidates none
+	grants *permcache.Cache
 }
 
 func NewStripeService(db *gorm.DB) *StripeService {
@@ -579,6 +586,13 @@ func (s *StripeService) CreateStripeCheckoutSession(
 	if err != nil {
 		return nil, huma.Error422UnprocessableEntity("user_id is not a valid UUID.")
 	}
+	currentUserID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if userID != currentUserID {
+		return nil, huma.Error403Forbidden("You can only check out for yourself")
+	}
 
 	// Look up user and get or create their Stripe customer
 	var user models.User
@@ -676,7 +690,7 @@ func (s *StripeService) HandleWebhook(c *fiber.Ctx) error {
 		if err := json.Unmarshal(event.Data.Raw, &sess); err != nil {
 			return c.Status(fiber.StatusBadRequest).SendString("failed to parse session")
 		}
-		if err := s.handleCheckoutCompleted(&sess); err != nil {
+		if err := s.handleCheckoutCompleted(c.UserContext(), &sess); err != nil {
 			return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
 		}
 
@@ -694,7 +708,16 @@ func (s *StripeService) HandleWebhook(c *fiber.Ctx) error {
 		if err := json.Unmarshal(event.Data.Raw, &sub); err != nil {
 			return c.Status(fiber.StatusBadRequest).SendString("failed to parse subscription")
 		}
-		if err := s.handleSubscriptionDeleted(&sub); err != nil {
+		if err := s.handleSubscriptionDeleted(c.UserContext(), &sub); err != nil {
+			return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+		}
+
+	case "invoice.paid":
+		var inv stripego.Invoice
+		if err := json.Unmarshal(event.Data.Raw, &inv); err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString("failed to parse invoice")
+		}
+		if err := s.handleInvoicePaid(c.UserContext(), &inv); err != nil {
 			return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
 		}
 
@@ -711,7 +734,7 @@ func (s *StripeService) HandleWebhook(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusOK)
 }
 
-func (s *StripeService) handleCheckoutCompleted(sess *stripego.CheckoutSession) error {
+func (s *StripeService) handleCheckoutCompleted(ctx context.Context, sess *stripego.CheckoutSession) error {
 	if sess.Customer == nil || sess.Subscription == nil {
 		return nil
 	}
@@ -748,7 +771,7 @@ func (s *StripeService) handleCheckoutCompleted(sess *stripego.CheckoutSession)
 		return err
 	}
 
-	return s.grantPremiumRole(user.ID)
+	return s.grantPremiumRole(ctx, user.ID)
 }
 
 func (s *StripeService) handleSubscriptionUpdated(sub *stripego.Subscription) error {
@@ -773,7 +796,7 @@ func (s *StripeService) handleSubscriptionUpdated(sub *stripego.Subscription) er
 		Updates(updates).Error
 }
 
-func (s *StripeService) handleSubscriptionDeleted(sub *stripego.Subscription) error {
+func (s *StripeService) handleSubscriptionDeleted(ctx context.Context, sub *stripego.Subscription) error {
 	if sub.Customer == nil {
 		return nil
 	}
@@ -793,7 +816,30 @@ func (s *StripeService) handleSubscriptionDeleted(sub *stripego.Subscription) er
 		return err
 	}
 
-	return s.revokePremiumRole(user.ID)
+	return s.revokePremiumRole(ctx, user.ID)
+}
+
+// handleInvoicePaid emails the user a receipt. Failing to send one fails the webhook, so Stripe retries it.
+func (s *StripeService) handleInvoicePaid(ctx context.Context, inv *stripego.Invoice) error {
+	if inv.Customer == nil || inv.AmountPaid == 0 {
+		return nil
+	}
+
+	var user models.User
+	if err := s.db.First(&user, "stripe_customer_id = ?", inv.Customer.ID).Error; err != nil {
+		// customers that aren't ours get no receipt; anything else is retried
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return s.mailer.SendReceipt(ctx, user, mailer.Receipt{
+		Amount:     inv.AmountPaid,
+		Currency:   string(inv.Currency),
+		Number:     inv.Number,
+		InvoiceURL: inv.HostedInvoiceURL,
+	})
 }
 
 func (s *StripeService) handlePaymentFailed(inv *stripego.Invoice) error {
@@ -811,38 +857,35 @@ func (s *StripeService) handlePaymentFailed(inv *stripego.Invoice) error {
 		Update("status", models.SubscriptionStatusPastDue).Error
 }
 
-func (s *StripeService) grantPremiumRole(userID uuid.UUID) error {
+// grantPremiumRole gives the user premium_user alongside whatever roles they already have.
+func (s *StripeService) grantPremiumRole(ctx context.Context, userID uuid.UUID) error {
 	var premiumRole models.Role
 	if err := s.db.Where("name = ?", models.RolePremiumUser).First(&premiumRole).Error; err != nil {
 		return fmt.Errorf("premium_user role not found: %w", err)
 	}
-	// Upsert: replaces any existing role (e.g. "user") with premium_user.
-	// Admins/moderators already satisfy hasPremium so we skip them.
-	return s.db.Exec(`
+	if err := s.db.Exec(`
 		INSERT INTO user_roles (user_id, role_id)
 		VALUES (?, ?)
-		ON CONFLICT (user_id) DO UPDATE SET role_id = EXCLUDED.role_id
-		WHERE user_roles.role_id NOT IN (
-			SELECT id FROM roles WHERE name IN ('admin', 'moderator')
-		)`,
+		ON CONFLICT (user_id, role_id) WHERE scope_id IS NULL DO NOTHING`,
 		userID, premiumRole.ID,
-	).Error
+	).Error; err != nil {
+		return err
+	}
+	s.grants.Invalidate(ctx, userID)
+	return nil
 }
 
-func (s *StripeService) revokePremiumRole(userID uuid.UUID) error {
-	var premiumRole models.Role
-	if err := s.db.Where("name = ?", models.RolePremiumUser).First(&premiumRole).Error; err != nil {
-		return nil
-	}
-	var userRole models.Role
-	if err := s.db.Where("name = ?", models.RoleUser).First(&userRole).Error; err != nil {
-		return fmt.Errorf("user role not found: %w", err)
+// revokePremiumRole takes premium_user away, leaving the user's other roles alone.
+func (s *StripeService) revokePremiumRole(ctx context.Context, userID uuid.UUID) error {
+	if err := s.db.Exec(`
+		DELETE FROM user_roles
+		WHERE user_id = ? AND role_id IN (SELECT id FROM roles WHERE name = ?)`,
+		userID, models.RolePremiumUser,
+	).Error; err != nil {
+		return err
 	}
-	// Only downgrade if they specifically have the premium_user role.
-	return s.db.Exec(
-		"UPDATE user_roles SET role_id = ? WHERE user_id = ? AND role_id = ?",
-		userRole.ID, userID, premiumRole.ID,
-	).Error
+	s.grants.Invalidate(ctx, userID)
+	return nil
 }
 
 func (s *StripeService) GetStripeCheckoutSessionByID(ctx context.Context, input *GetStripeCheckoutSessionParams) (*utils.ResponseBody[StripeCheckoutSessionResponse], error) {
@@ -876,6 +919,14 @@ func (s *StripeService) DeleteStripeCheckoutSession(
 		return nil, huma.Error422UnprocessableEntity("session id cannot be empty")
 	}
 
+	existing, err := s.client.GetSession(input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSessionOwner(ctx, existing); err != nil {
+		return nil, err
+	}
+
 	stripeSession, err := s.client.ExpireSession(input.ID)
 	if err != nil {
 		return nil, err
@@ -894,6 +945,26 @@ func (s *StripeService) DeleteStripeCheckoutSession(
 	}, nil
 }
 
+// checkSessionOwner returns a 403 unless the checkout session is for the current user's Stripe
+// customer.
+func (s *StripeService) checkSessionOwner(ctx context.Context, sess *stripego.CheckoutSession) error {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return err
+	}
+	if sess.Customer == nil {
+		return huma.Error403Forbidden("You can only cancel your own checkout sessions")
+	}
+	owned, err := utils.UserKey("stripe_customer_id")(s.db, userID, sess.Customer.ID)
+	if err != nil {
+		return huma.Error500InternalServerError("Unable to check the checkout session", err)
+	}
+	if !owned {
+		return huma.Error403Forbidden("You can only cancel your own checkout sessions")
+	}
+	return nil
+}
+
 func (s *StripeService) GetAllStripeSessions(
 	ctx context.Context, input *GetAllStripeSessionsRequest,
 ) (*utils.ResponseBody[[]*StripeCheckoutSessionResponse], error) {
@@ -1,11 +1,15 @@
 package survey
 
 import (
+	"fmt"
 	models "inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
+	"time"
 
+	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SurveyDB struct {
@@ -16,28 +20,65 @@ func NewSurveyDB(db *gorm.DB) *SurveyDB {
 	return &SurveyDB{db: db}
 }
 
-// CreateSurvey creates a new survey response in the database
-func (s *SurveyDB) CreateSurvey(req CreateSurveyRequest) (*models.Survey, error) {
+// resubmitColumns are the columns submitting a survey again overwrites
+var resubmitColumns = []string{
+	"template_version_id",
+	"updated_at",
+}
+
+// IsVerifiedAthleteOf reports whether the user is a verified athlete of the sport at the college.
+func (s *SurveyDB) IsVerifiedAthleteOf(userID, collegeID, sportID uuid.UUID) (bool, error) {
+	var count int64
+	err := s.db.Model(&models.User{}).
+		Where("id = ? AND verified_athlete_status = ? AND college_id = ? AND sport_id = ?",
+			userID, models.VerifiedAthleteStatusVerified, collegeID, sportID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SubmitSurvey saves userID's answers to a template version for the request's program. A user has
+// one response per program: submitting again replaces its answers, and brings it back if it was
+// deleted. Whether it was invalidated is kept.
+func (s *SurveyDB) SubmitSurvey(userID uuid.UUID, req CreateSurveyRequest, answers []models.SurveyAnswer) (*models.Survey, error) {
 	survey := models.Survey{
-		UserID:                     req.UserID,
-		CollegeID:                  req.CollegeID,
-		SportID:                    req.SportID,
-		PlayerDev:                  req.PlayerDev,
-		AcademicsAthleticsPriority: req.AcademicsAthleticsPriority,
-		AcademicCareerResources:    req.AcademicCareerResources,
-		MentalHealthPriority:       req.MentalHealthPriority,
-		Environment:                req.Environment,
-		Culture:                    req.Culture,
-		Transparency:               req.Transparency,
-	}
-	dbResponse := s.db.Create(&survey)
-	return utils.HandleDBError(&survey, dbResponse.Error)
-}
-
-// GetSurveyByID retrieves a single survey by its ID
+		UserID:            userID,
+		CollegeID:         req.CollegeID,
+		SportID:           req.SportID,
+		TemplateVersionID: req.TemplateVersionID,
+	}
+	updates := append(clause.AssignmentColumns(resubmitColumns),
+		clause.Assignment{Column: clause.Column{Name: "deleted_at"}, Value: nil})
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "college_id"}, {Name: "sport_id"}},
+			DoUpdates: updates,
+		}).Create(&survey).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("survey_id = ?", survey.ID).Delete(&models.SurveyAnswer{}).Error; err != nil {
+			return err
+		}
+		for i := range answers {
+			answers[i].SurveyID = survey.ID
+		}
+		return tx.Create(&answers).Error
+	})
+	if err != nil {
+		return utils.HandleDBError(&survey, err)
+	}
+	return utils.HandleDBError(s.GetSurveyByID(survey.ID))
+}
+
+// withAnswers preloads what converting surveys to responses needs
+func withAnswers(db *gorm.DB) *gorm.DB {
+	return db.Preload("User").Preload("Answers.Question")
+}
+
+// GetSurveyByID retrieves a single survey by its ID, with its answers
 func (s *SurveyDB) GetSurveyByID(id uuid.UUID) (*models.Survey, error) {
 	var survey models.Survey
-	result := s.db.First(&survey, id)
+	result := s.db.Scopes(withAnswers).First(&survey, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -54,7 +95,7 @@ func (s *SurveyDB) GetSurveysByUserID(userID uuid.UUID, limit, offset int) ([]mo
 	if err := q.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-	if err := q.Limit(limit).Offset(offset).Find(&surveys).Error; err != nil {
+	if err := q.Scopes(withAnswers).Limit(limit).Offset(offset).Find(&surveys).Error; err != nil {
 		return nil, 0, err
 	}
 	return surveys, total, nil
@@ -72,34 +113,338 @@ func (s *SurveyDB) DeleteSurvey(id uuid.UUID) error {
 	return nil
 }
 
-// GetAverageRatings returns average scores for each rating field,
-// optionally filtered by sportID and/or collegeID, grouped by both.
-func (s *SurveyDB) GetAverageRatings(sportID, collegeID uuid.UUID) ([]AverageRatingsRow, error) {
-	q := s.db.Model(&models.Survey{}).
-		Select(`
-			sport_id,
-			college_id,
-			AVG(player_dev)                       AS player_dev,
-			AVG(academics_athletics_priority)     AS academics_athletics_priority,
-			AVG(academic_career_resources)        AS academic_career_resources,
-			AVG(mental_health_priority)           AS mental_health_priority,
-			AVG(environment)                      AS environment,
-			AVG(culture)                          AS culture,
-			AVG(transparency)                     AS transparency,
-			COUNT(*)                              AS response_count
-		`).
-		Group("sport_id, college_id")
+// InvalidateSurvey leaves a survey out of the averages, recording who did it and why. A survey can
+// only be invalidated once.
+func (s *SurveyDB) InvalidateSurvey(id, adminID uuid.UUID, reason string) (*models.Survey, error) {
+	dbResponse := s.db.Model(&models.Survey{}).
+		Where("id = ? AND invalidated_at IS NULL", id).
+		Updates(map[string]any{
+			"invalidated_at":      time.Now(),
+			"invalidated_by_id":   adminID,
+			"invalidation_reason": reason,
+		})
+	if dbResponse.Error != nil {
+		return utils.HandleDBError((*models.Survey)(nil), dbResponse.Error)
+	}
+	survey, err := utils.HandleDBError(s.GetSurveyByID(id))
+	if err != nil {
+		return nil, err
+	}
+	if dbResponse.RowsAffected == 0 {
+		return nil, huma.Error409Conflict("Survey already invalidated")
+	}
+	return survey, nil
+}
+
+// latestVersion restricts survey_template_versions to each template's latest version
+const latestVersion = `version = (SELECT MAX(lv.version) FROM survey_template_versions lv
+	WHERE lv.template_id = survey_template_versions.template_id)`
+
+// GetSurveyTemplates returns every template, by name, with only its latest version and its questions.
+func (s *SurveyDB) GetSurveyTemplates() ([]models.SurveyTemplate, error) {
+	var templates []models.SurveyTemplate
+	err := s.db.
+		Preload("Versions", latestVersion).
+		Preload("Versions.Questions").
+		Order("name").
+		Find(&templates).Error
+	return templates, err
+}
+
+// GetSurveyTemplateVersion returns a template and one of its versions with its questions, or its
+// latest version when version is 0, along with the number of its latest version.
+func (s *SurveyDB) GetSurveyTemplateVersion(templateID uuid.UUID, version int) (*models.SurveyTemplate, *models.SurveyTemplateVersion, int, error) {
+	var template models.SurveyTemplate
+	if _, err := utils.HandleDBError(&template, s.db.First(&template, "id = ?", templateID).Error); err != nil {
+		return nil, nil, 0, err
+	}
+	latest, err := latestVersionOf(s.db, templateID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if version == 0 {
+		version = latest
+	}
+	var found models.SurveyTemplateVersion
+	err = s.db.Preload("Questions").First(&found, "template_id = ? AND version = ?", templateID, version).Error
+	if _, err := utils.HandleDBError(&found, err); err != nil {
+		return nil, nil, 0, err
+	}
+	return &template, &found, latest, nil
+}
+
+// GetTemplateVersionByID returns a template version with its questions, along with the number of
+// its template's latest version.
+func (s *SurveyDB) GetTemplateVersionByID(id uuid.UUID) (*models.SurveyTemplateVersion, int, error) {
+	var version models.SurveyTemplateVersion
+	if _, err := utils.HandleDBError(&version, s.db.Preload("Questions").First(&version, "id = ?", id).Error); err != nil {
+		return nil, 0, err
+	}
+	latest, err := latestVersionOf(s.db, version.TemplateID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &version, latest, nil
+}
+
+func latestVersionOf(db *gorm.DB, templateID uuid.UUID) (int, error) {
+	var latest int
+	err := db.Model(&models.SurveyTemplateVersion{}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("template_id = ?", templateID).
+		Scan(&latest).Error
+	return latest, err
+}
+
+// CreateSurveyTemplate saves a new template with the version's questions as its version 1.
+func (s *SurveyDB) CreateSurveyTemplate(template *models.SurveyTemplate, version *models.SurveyTemplateVersion) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(template).Error; err != nil {
+			return err
+		}
+		version.TemplateID = template.ID
+		version.Version = 1
+		return tx.Create(version).Error
+	})
+	_, err = utils.HandleDBError(template, err)
+	return err
+}
+
+// PublishSurveyTemplateVersion saves the version's questions as the template's next version. The
+// template is locked so versions published at the same time get consecutive numbers.
+func (s *SurveyDB) PublishSurveyTemplateVersion(templateID uuid.UUID, version *models.SurveyTemplateVersion) (*models.SurveyTemplate, error) {
+	var template models.SurveyTemplate
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&template, "id = ?", templateID).Error; err != nil {
+			return err
+		}
+		latest, err := latestVersionOf(tx, templateID)
+		if err != nil {
+			return err
+		}
+		version.TemplateID = templateID
+		version.Version = latest + 1
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+		return tx.Model(&template).Update("updated_at", time.Now()).Error
+	})
+	return utils.HandleDBError(&template, err)
+}
+
+// GetQuestionKeyTypes returns the types questions with the keys were given in every template
+// version, by key.
+func (s *SurveyDB) GetQuestionKeyTypes(keys []string) (map[string]models.SurveyQuestionType, error) {
+	var rows []struct {
+		Key  string
+		Type models.SurveyQuestionType
+	}
+	err := s.db.Model(&models.SurveyQuestion{}).
+		Distinct("key", "type").
+		Where("key IN ?", keys).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]models.SurveyQuestionType, len(rows))
+	for _, row := range rows {
+		types[row.Key] = row.Type
+	}
+	return types, nil
+}
+
+// The averages and analytics match answers to questions by key, so answers to a question carried
+// over between template versions, or shared by templates, are aggregated together.
+var (
+	// scaleAnswers joins a row per answer to a scale question to each survey s, as a.scale and q
+	scaleAnswers = "JOIN survey_answers a ON a.survey_id = s.id AND a.scale IS NOT NULL JOIN survey_questions q ON q.id = a.question_id"
+	// choiceAnswers joins a row per answer to a multiple choice question to each survey s, as
+	// a.choice and q
+	choiceAnswers = "JOIN survey_answers a ON a.survey_id = s.id AND a.choice IS NOT NULL JOIN survey_questions q ON q.id = a.question_id"
+	// overallRating joins the mean of each survey s's scale answers, as o.overall
+	overallRating = "LEFT JOIN LATERAL (SELECT AVG(oa.scale) AS overall FROM survey_answers oa WHERE oa.survey_id = s.id) o ON true"
+	// academicYearOf is the year the academic year survey s was last edited in started
+	academicYearOf = fmt.Sprintf("EXTRACT(YEAR FROM s.updated_at - INTERVAL '%d months')::int", academicYearStart-time.January)
+)
+
+// verifiedRespondent is whether survey s was filled in by a verified athlete of the team it rates
+const verifiedRespondent = `EXISTS (SELECT 1 FROM users vu WHERE vu.id = s.user_id AND vu.verified_athlete_status = ?
+	AND vu.college_id = s.college_id AND vu.sport_id = s.sport_id)`
 
+// responses starts a query on the surveys s that are aggregated, which aren't deleted or invalidated
+func (s *SurveyDB) responses() *gorm.DB {
+	return s.db.Table("surveys s").Where("s.deleted_at IS NULL AND s.invalidated_at IS NULL")
+}
+
+// averaged starts a query on the surveys averaged for GetAverageRatings
+func (s *SurveyDB) averaged(sportID, collegeID uuid.UUID, verifiedOnly bool) *gorm.DB {
+	q := s.responses()
 	if sportID != uuid.Nil {
-		q = q.Where("sport_id = ?", sportID)
+		q = q.Where("s.sport_id = ?", sportID)
 	}
 	if collegeID != uuid.Nil {
-		q = q.Where("college_id = ?", collegeID)
+		q = q.Where("s.college_id = ?", collegeID)
+	}
+	if verifiedOnly {
+		q = q.Where(verifiedRespondent, models.VerifiedAthleteStatusVerified)
 	}
+	return q
+}
 
+// GetAverageRatings returns each program's response counts, overall rating and average answer to
+// each scale question, optionally filtered by sportID and/or collegeID.
+// Invalidated surveys are left out.
+// Only surveys by verified athletes of the team are averaged when verifiedOnly is set.
+func (s *SurveyDB) GetAverageRatings(sportID, collegeID uuid.UUID, verifiedOnly bool) ([]AverageRatingsRow, error) {
 	var rows []AverageRatingsRow
-	if err := q.Scan(&rows).Error; err != nil {
+	err := s.averaged(sportID, collegeID, verifiedOnly).
+		Select(`
+			s.sport_id,
+			s.college_id,
+			COUNT(*)                              AS response_count,
+			COUNT(*) FILTER (WHERE `+verifiedRespondent+`) AS verified_response_count,
+			COALESCE(AVG(o.overall), 0)           AS overall
+		`, models.VerifiedAthleteStatusVerified).
+		Joins(overallRating).
+		Group("s.sport_id, s.college_id").
+		Scan(&rows).Error
+	if err != nil {
 		return nil, err
 	}
+
+	var means []QuestionAverage
+	err = s.averaged(sportID, collegeID, verifiedOnly).
+		Select("s.sport_id, s.college_id, q.key, AVG(a.scale) AS mean, COUNT(*) AS response_count").
+		Joins(scaleAnswers).
+		Group("s.sport_id, s.college_id, q.key").
+		Order("MIN(q.position), q.key").
+		Scan(&means).Error
+	if err != nil {
+		return nil, err
+	}
+	type program struct{ sportID, collegeID uuid.UUID }
+	byProgram := make(map[program][]QuestionAverage, len(rows))
+	for _, mean := range means {
+		key := program{mean.SportID, mean.CollegeID}
+		byProgram[key] = append(byProgram[key], mean)
+	}
+	for i := range rows {
+		rows[i].Questions = byProgram[program{rows[i].SportID, rows[i].CollegeID}]
+		if rows[i].Questions == nil {
+			rows[i].Questions = []QuestionAverage{}
+		}
+	}
 	return rows, nil
 }
+
+// GetSportOveralls returns the average overall rating of each of the sports, for smoothing its
+// programs' averages towards. Only surveys by verified athletes of the team count when
+// verifiedOnly is set.
+func (s *SurveyDB) GetSportOveralls(sportIDs []uuid.UUID, verifiedOnly bool) (map[uuid.UUID]float64, error) {
+	var rows []struct {
+		SportID uuid.UUID
+		Overall float64
+	}
+	q := s.responses().
+		Select("s.sport_id, COALESCE(AVG(o.overall), 0) AS overall").
+		Joins(overallRating).
+		Where("s.sport_id IN ?", sportIDs).
+		Group("s.sport_id")
+	if verifiedOnly {
+		q = q.Where(verifiedRespondent, models.VerifiedAthleteStatusVerified)
+	}
+	if err := q.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	overalls := make(map[uuid.UUID]float64, len(rows))
+	for _, row := range rows {
+		overalls[row.SportID] = row.Overall
+	}
+	return overalls, nil
+}
+
+// GetCollegeDivision returns the division of the college.
+func (s *SurveyDB) GetCollegeDivision(collegeID uuid.UUID) (models.Division, error) {
+	var college models.College
+	if _, err := utils.HandleDBError(&college, s.db.Select("id", "division_rank").First(&college, "id = ?", collegeID).Error); err != nil {
+		return 0, err
+	}
+	return college.DivisionRank, nil
+}
+
+// ratings starts a query on the answers to scale questions given to the sport that analytics
+// count, with a row per answer
+func (s *SurveyDB) ratings(sportID uuid.UUID) *gorm.DB {
+	return s.responses().
+		Joins(scaleAnswers).
+		Where("s.sport_id = ?", sportID)
+}
+
+// GetScaleQuestionKeys returns the keys of the scale questions answered for the sport, in the
+// order they're asked.
+func (s *SurveyDB) GetScaleQuestionKeys(sportID uuid.UUID) ([]string, error) {
+	var keys []string
+	err := s.ratings(sportID).
+		Select("q.key").
+		Group("q.key").
+		Order("MIN(q.position), q.key").
+		Scan(&keys).Error
+	return keys, err
+}
+
+// GetScoreCounts counts the answers of each score to each scale question given to the sport, at
+// collegeID if it's set, and at colleges in division if it's set.
+func (s *SurveyDB) GetScoreCounts(sportID uuid.UUID, collegeID *uuid.UUID, division *models.Division) ([]scoreCount, error) {
+	q := s.ratings(sportID).
+		Select("q.key AS dimension, a.scale AS score, COUNT(*) AS count").
+		Group("q.key, a.scale")
+	if collegeID != nil {
+		q = q.Where("s.college_id = ?", *collegeID)
+	}
+	if division != nil {
+		q = q.Joins("JOIN colleges c ON c.id = s.college_id").Where("c.division_rank = ?", *division)
+	}
+	var counts []scoreCount
+	err := q.Scan(&counts).Error
+	return counts, err
+}
+
+// GetTrendScoreCounts counts the answers of each score to each scale question given to the sport
+// at the college in each academic year, oldest first.
+func (s *SurveyDB) GetTrendScoreCounts(sportID, collegeID uuid.UUID) ([]scoreCount, error) {
+	var counts []scoreCount
+	err := s.ratings(sportID).
+		Select(academicYearOf+" AS start_year, q.key AS dimension, a.scale AS score, COUNT(*) AS count").
+		Where("s.college_id = ?", collegeID).
+		Group("start_year, q.key, a.scale").
+		Order("start_year").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// GetYearResponseCounts counts the responses for the sport at the college in each academic year,
+// oldest first.
+func (s *SurveyDB) GetYearResponseCounts(sportID, collegeID uuid.UUID) ([]yearCount, error) {
+	var counts []yearCount
+	err := s.responses().
+		Select(academicYearOf+" AS start_year, COUNT(*) AS count").
+		Where("s.sport_id = ? AND s.college_id = ?", sportID, collegeID).
+		Group("start_year").
+		Order("start_year").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// GetChoiceCounts counts the answers picking each option of each multiple choice question given to
+// the sport at the college, in the order the questions are asked.
+func (s *SurveyDB) GetChoiceCounts(sportID, collegeID uuid.UUID) ([]choiceCount, error) {
+	var counts []choiceCount
+	err := s.responses().
+		Joins(choiceAnswers).
+		Select("q.key, a.choice, COUNT(*) AS count").
+		Where("s.sport_id = ? AND s.college_id = ?", sportID, collegeID).
+		Group("q.key, a.choice").
+		Order("MIN(q.position), q.key, a.choice").
+		Scan(&counts).Error
+	return counts, err
+}
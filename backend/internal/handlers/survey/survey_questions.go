@@ -0,0 +1,131 @@
+package survey
+
+import (
+	"fmt"
+	"inside-athletics/internal/models"
+	"slices"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ValidateQuestions checks the questions of a new template version: keys are unique, and only
+// multiple choice questions have options, at least two distinct ones.
+func ValidateQuestions(questions []QuestionInput) error {
+	keys := make(map[string]bool, len(questions))
+	for _, q := range questions {
+		if keys[q.Key] {
+			return huma.Error422UnprocessableEntity(fmt.Sprintf("Question key %s is used more than once", q.Key))
+		}
+		keys[q.Key] = true
+
+		if q.Type != models.SurveyQuestionChoice {
+			if len(q.Options) > 0 {
+				return huma.Error422UnprocessableEntity(fmt.Sprintf("Question %s can't have options: only multiple choice questions do", q.Key))
+			}
+			continue
+		}
+		if len(q.Options) < 2 {
+			return huma.Error422UnprocessableEntity(fmt.Sprintf("Multiple choice question %s needs at least two options", q.Key))
+		}
+		options := make(map[string]bool, len(q.Options))
+		for _, option := range q.Options {
+			if strings.TrimSpace(option) == "" || len(option) > 200 {
+				return huma.Error422UnprocessableEntity(fmt.Sprintf("Options of question %s must be 1 to 200 characters", q.Key))
+			}
+			if options[option] {
+				return huma.Error422UnprocessableEntity(fmt.Sprintf("Question %s has the option %q more than once", q.Key, option))
+			}
+			options[option] = true
+		}
+	}
+	return nil
+}
+
+// CheckQuestionKeyTypes checks that questions keep the type their keys were given in earlier
+// template versions, so their answers can be aggregated together.
+func CheckQuestionKeyTypes(questions []QuestionInput, existing map[string]models.SurveyQuestionType) error {
+	for _, q := range questions {
+		if t, ok := existing[q.Key]; ok && t != q.Type {
+			return huma.Error422UnprocessableEntity(fmt.Sprintf("Question key %s is already used for %s questions; use a new key for a %s question", q.Key, t, q.Type))
+		}
+	}
+	return nil
+}
+
+// toQuestions converts the questions of a new template version to models, positioned in order.
+func toQuestions(questions []QuestionInput) []models.SurveyQuestion {
+	result := make([]models.SurveyQuestion, len(questions))
+	for i, q := range questions {
+		result[i] = models.SurveyQuestion{
+			Key:      q.Key,
+			Position: i + 1,
+			Type:     q.Type,
+			Prompt:   q.Prompt,
+			Required: q.Required,
+			Options:  q.Options,
+		}
+	}
+	return result
+}
+
+// AnswersFor checks answers against a template version's questions and converts them to models.
+// Each answer must be to one of the questions, in the field matching its type, and every required
+// question must be answered.
+func AnswersFor(questions []models.SurveyQuestion, answers []AnswerInput) ([]models.SurveyAnswer, error) {
+	byKey := make(map[string]models.SurveyQuestion, len(questions))
+	for _, q := range questions {
+		byKey[q.Key] = q
+	}
+	answered := make(map[string]bool, len(answers))
+	result := make([]models.SurveyAnswer, 0, len(answers))
+	for _, a := range answers {
+		q, ok := byKey[a.QuestionKey]
+		if !ok {
+			return nil, huma.Error422UnprocessableEntity(fmt.Sprintf("This version of the survey has no question %s", a.QuestionKey))
+		}
+		if answered[q.Key] {
+			return nil, huma.Error422UnprocessableEntity(fmt.Sprintf("Question %s is answered more than once", q.Key))
+		}
+		answered[q.Key] = true
+		if err := checkAnswer(q, a); err != nil {
+			return nil, err
+		}
+		result = append(result, models.SurveyAnswer{
+			QuestionID: q.ID,
+			Scale:      a.Scale,
+			Choice:     a.Choice,
+			Text:       a.Text,
+		})
+	}
+	for _, q := range questions {
+		if q.Required && !answered[q.Key] {
+			return nil, huma.Error422UnprocessableEntity(fmt.Sprintf("Question %s must be answered", q.Key))
+		}
+	}
+	return result, nil
+}
+
+// checkAnswer checks that only the field matching the question's type is set, with a valid value.
+func checkAnswer(q models.SurveyQuestion, a AnswerInput) error {
+	var valid bool
+	switch q.Type {
+	case models.SurveyQuestionScale:
+		valid = a.Scale != nil && a.Choice == nil && a.Text == nil && *a.Scale >= minRating && *a.Scale <= maxRating
+	case models.SurveyQuestionChoice:
+		valid = a.Choice != nil && a.Scale == nil && a.Text == nil && slices.Contains(q.Options, *a.Choice)
+	case models.SurveyQuestionText:
+		valid = a.Text != nil && a.Scale == nil && a.Choice == nil && strings.TrimSpace(*a.Text) != ""
+	}
+	if valid {
+		return nil
+	}
+	switch q.Type {
+	case models.SurveyQuestionScale:
+		return huma.Error422UnprocessableEntity(fmt.Sprintf("Question %s must be answered with a scale from %d to %d", q.Key, minRating, maxRating))
+	case models.SurveyQuestionChoice:
+		return huma.Error422UnprocessableEntity(fmt.Sprintf("Question %s must be answered with a choice of %s", q.Key, strings.Join(q.Options, ", ")))
+	default:
+		return huma.Error422UnprocessableEntity(fmt.Sprintf("Question %s must be answered with text", q.Key))
+	}
+}
@@ -1,6 +1,9 @@
 package survey
 
 import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
@@ -10,9 +13,15 @@ func Route(api huma.API, db *gorm.DB) {
 
 	{
 		grp := huma.NewGroup(api, "/api/v1/survey")
-		huma.Post(grp, "/", svc.CreateSurvey)                          // POST   /api/v1/survey/          — submit a survey
-		huma.Delete(grp, "/{id}", svc.DeleteSurvey)                    // DELETE /api/v1/survey/{id}      — delete a survey
-		huma.Get(grp, "/user/{user_id}", svc.GetSurveysByUser)         // GET    /api/v1/survey/user/{id} — own user's surveys
-		huma.Get(grp, "/averages", svc.GetAverageRatings)              // GET    /api/v1/survey/averages  — averages (sport/college filters)
+		huma.Post(grp, "/", svc.CreateSurvey, utils.Requires(utils.Permission(models.PermissionCreate, "survey")))                                                    // POST   /api/v1/survey/          — submit or edit a survey
+		huma.Delete(grp, "/{id}", svc.DeleteSurvey, utils.Requires(utils.Permission(models.PermissionDelete, "survey").OwnedBy(utils.OwnedBy("surveys", "user_id")))) // DELETE /api/v1/survey/{id}      — delete a survey
+		huma.Post(grp, "/{id}/invalidate", svc.InvalidateSurvey, utils.Requires(utils.Permission(models.PermissionInvalidate, "survey")))                             // POST   /api/v1/survey/{id}/invalidate — leave out of the averages
+		huma.Get(grp, "/user/{user_id}", svc.GetSurveysByUser)                                                                                                        // GET    /api/v1/survey/user/{id} — own user's surveys
+		huma.Get(grp, "/averages", svc.GetAverageRatings)                                                                                                             // GET    /api/v1/survey/averages  — averages (sport/college filters)
+		huma.Get(grp, "/analytics", svc.GetSurveyAnalytics)                                                                                                           // GET    /api/v1/survey/analytics — one program's distributions, baselines and trends
+		huma.Get(grp, "/templates", svc.GetSurveyTemplates)                                                                                                           // GET    /api/v1/survey/templates — every template at its latest version
+		huma.Get(grp, "/templates/{id}", svc.GetSurveyTemplate)                                                                                                       // GET    /api/v1/survey/templates/{id} — a version of a template
+		huma.Post(grp, "/templates", svc.CreateSurveyTemplate, utils.Requires(utils.Permission(models.PermissionCreate, "survey_template")))                          // POST   /api/v1/survey/templates — define a template
+		huma.Post(grp, "/templates/{id}/versions", svc.PublishSurveyTemplateVersion, utils.Requires(utils.Permission(models.PermissionUpdate, "survey_template")))    // POST   /api/v1/survey/templates/{id}/versions — publish new questions
 	}
-}
\ No newline at end of file
+}
@@ -2,9 +2,12 @@ package survey
 
 import (
 	"context"
+	"inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
+	"sort"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -18,17 +21,35 @@ func NewSurveyService(db *gorm.DB) *SurveyService {
 	}
 }
 
+// CreateSurvey submits the current user's answers to the latest version of a survey template for a
+// program, or edits the response they already submitted. Only verified athletes of the sport at the
+// college can rate it.
 func (s *SurveyService) CreateSurvey(ctx context.Context, input *struct{ Body CreateSurveyRequest }) (*utils.ResponseBody[SurveyResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
 	b := input.Body
-	if err := validateRatings(b.PlayerDev, b.AcademicsAthleticsPriority, b.AcademicCareerResources, b.MentalHealthPriority, b.Environment, b.Culture, b.Transparency); err != nil {
+	version, latest, err := s.surveyDB.GetTemplateVersionByID(b.TemplateVersionID)
+	if err != nil {
 		return nil, err
 	}
-
-	created, err := s.surveyDB.CreateSurvey(b)
+	if version.Version != latest {
+		return nil, huma.Error409Conflict("A newer version of this survey was published; answer that one instead")
+	}
+	answers, err := AnswersFor(version.Questions, b.Answers)
 	if err != nil {
 		return nil, err
 	}
-	survey, err := utils.HandleDBError(created, nil)
+	verified, err := s.surveyDB.IsVerifiedAthleteOf(userID, b.CollegeID, b.SportID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Unable to check your verification", err)
+	}
+	if !verified {
+		return nil, huma.Error403Forbidden("Only athletes verified for this sport at this college can rate it")
+	}
+
+	survey, err := s.surveyDB.SubmitSurvey(userID, b, answers)
 	if err != nil {
 		return nil, err
 	}
@@ -54,6 +75,22 @@ func (s *SurveyService) DeleteSurvey(ctx context.Context, input *DeleteSurveyReq
 	}, nil
 }
 
+// InvalidateSurvey leaves a suspicious survey out of the averages. The survey is kept, and its
+// respondent can still see and edit it.
+func (s *SurveyService) InvalidateSurvey(ctx context.Context, input *InvalidateSurveyInput) (*utils.ResponseBody[SurveyResponse], error) {
+	adminID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	survey, err := s.surveyDB.InvalidateSurvey(input.ID, adminID, input.Body.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[SurveyResponse]{
+		Body: ToSurveyResponse(survey),
+	}, nil
+}
+
 func (s *SurveyService) GetSurveysByUser(ctx context.Context, input *GetSurveysByUserParams) (*utils.ResponseBody[GetSurveysByUserResponse], error) {
 	surveys, total, err := s.surveyDB.GetSurveysByUserID(input.UserID, input.Limit, input.Offset)
 	if err != nil {
@@ -73,11 +110,111 @@ func (s *SurveyService) GetSurveysByUser(ctx context.Context, input *GetSurveysB
 	}, nil
 }
 
+// GetSurveyTemplates lists the survey templates at their latest versions.
+func (s *SurveyService) GetSurveyTemplates(ctx context.Context, input *struct{}) (*utils.ResponseBody[GetSurveyTemplatesResponse], error) {
+	templates, err := s.surveyDB.GetSurveyTemplates()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]SurveyTemplateResponse, 0, len(templates))
+	for i := range templates {
+		// every template is created with a version
+		latest := &templates[i].Versions[0]
+		responses = append(responses, *ToSurveyTemplateResponse(&templates[i], latest, latest.Version))
+	}
+	return &utils.ResponseBody[GetSurveyTemplatesResponse]{
+		Body: &GetSurveyTemplatesResponse{Templates: responses},
+	}, nil
+}
+
+// GetSurveyTemplate gets a version of a survey template, by default its latest.
+func (s *SurveyService) GetSurveyTemplate(ctx context.Context, input *GetSurveyTemplateParams) (*utils.ResponseBody[SurveyTemplateResponse], error) {
+	template, version, latest, err := s.surveyDB.GetSurveyTemplateVersion(input.ID, input.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[SurveyTemplateResponse]{
+		Body: ToSurveyTemplateResponse(template, version, latest),
+	}, nil
+}
+
+// CreateSurveyTemplate defines a survey template, publishing its questions as version 1.
+func (s *SurveyService) CreateSurveyTemplate(ctx context.Context, input *CreateSurveyTemplateInput) (*utils.ResponseBody[SurveyTemplateResponse], error) {
+	adminID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.newVersion(adminID, input.Body.Questions)
+	if err != nil {
+		return nil, err
+	}
+	template := &models.SurveyTemplate{
+		Name:        input.Body.Name,
+		Description: input.Body.Description,
+	}
+	if err := s.surveyDB.CreateSurveyTemplate(template, version); err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[SurveyTemplateResponse]{
+		Body: ToSurveyTemplateResponse(template, version, version.Version),
+	}, nil
+}
+
+// PublishSurveyTemplateVersion publishes new questions for a survey template as its next version.
+// Earlier versions are kept unchanged for the surveys that answered them.
+func (s *SurveyService) PublishSurveyTemplateVersion(ctx context.Context, input *PublishSurveyTemplateVersionInput) (*utils.ResponseBody[SurveyTemplateResponse], error) {
+	adminID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.newVersion(adminID, input.Body.Questions)
+	if err != nil {
+		return nil, err
+	}
+	template, err := s.surveyDB.PublishSurveyTemplateVersion(input.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[SurveyTemplateResponse]{
+		Body: ToSurveyTemplateResponse(template, version, version.Version),
+	}, nil
+}
+
+// newVersion validates the questions of a new template version by adminID.
+func (s *SurveyService) newVersion(adminID uuid.UUID, questions []QuestionInput) (*models.SurveyTemplateVersion, error) {
+	if err := ValidateQuestions(questions); err != nil {
+		return nil, err
+	}
+	keys := utils.MapList(questions, func(q QuestionInput) string { return q.Key })
+	existing, err := s.surveyDB.GetQuestionKeyTypes(keys)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Unable to check the question keys", err)
+	}
+	if err := CheckQuestionKeyTypes(questions, existing); err != nil {
+		return nil, err
+	}
+	return &models.SurveyTemplateVersion{
+		CreatedByID: &adminID,
+		Questions:   toQuestions(questions),
+	}, nil
+}
+
+// GetAverageRatings averages each program's ratings, ranked by their overall rating smoothed
+// towards their sport's, so programs with a couple of responses don't top the rankings.
 func (s *SurveyService) GetAverageRatings(ctx context.Context, input *GetAverageRatingsParams) (*utils.ResponseBody[AverageRatingsResponse], error) {
-	rows, err := s.surveyDB.GetAverageRatings(input.SportID, input.CollegeID)
+	rows, err := s.surveyDB.GetAverageRatings(input.SportID, input.CollegeID, input.VerifiedOnly)
+	if err != nil {
+		return nil, err
+	}
+	sportIDs := utils.MapList(rows, func(row AverageRatingsRow) uuid.UUID { return row.SportID })
+	priors, err := s.surveyDB.GetSportOveralls(sportIDs, input.VerifiedOnly)
 	if err != nil {
 		return nil, err
 	}
+	for i := range rows {
+		rows[i].SmoothedOverall = Smooth(rows[i].Overall, rows[i].ResponseCount, priors[rows[i].SportID], input.PriorWeight)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].SmoothedOverall > rows[j].SmoothedOverall })
 
 	return &utils.ResponseBody[AverageRatingsResponse]{
 		Body: &AverageRatingsResponse{
@@ -86,21 +223,118 @@ func (s *SurveyService) GetAverageRatings(ctx context.Context, input *GetAverage
 	}, nil
 }
 
-// validateRatings ensures all 7 rating fields are within the 1–5 range
-func validateRatings(vals ...int32) error {
-	names := []string{
-		"player_dev",
-		"academics_athletics_priority",
-		"academic_career_resources",
-		"mental_health_priority",
-		"environment",
-		"culture",
-		"transparency",
-	}
-	for i, v := range vals {
-		if v < 1 || v > 5 {
-			return huma.Error422UnprocessableEntity(names[i] + " must be between 1 and 5")
+// GetSurveyAnalytics describes how a program was rated on each scale question: the spread of its
+// ratings, how sure its mean is, its mean smoothed towards the sport's, how it compares with the
+// sport and the college's division, and how it changed each academic year. Answers to questions
+// with the same key are counted together, whichever template version they were given in.
+func (s *SurveyService) GetSurveyAnalytics(ctx context.Context, input *GetSurveyAnalyticsParams) (*utils.ResponseBody[SurveyAnalyticsResponse], error) {
+	division, err := s.surveyDB.GetCollegeDivision(input.CollegeID)
+	if err != nil {
+		return nil, err
+	}
+	dimensions, err := s.surveyDB.GetScaleQuestionKeys(input.SportID)
+	if err != nil {
+		return nil, err
+	}
+	programCounts, err := s.surveyDB.GetScoreCounts(input.SportID, &input.CollegeID, nil)
+	if err != nil {
+		return nil, err
+	}
+	sportCounts, err := s.surveyDB.GetScoreCounts(input.SportID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	divisionCounts, err := s.surveyDB.GetScoreCounts(input.SportID, nil, &division)
+	if err != nil {
+		return nil, err
+	}
+	trendCounts, err := s.surveyDB.GetTrendScoreCounts(input.SportID, input.CollegeID)
+	if err != nil {
+		return nil, err
+	}
+	yearCounts, err := s.surveyDB.GetYearResponseCounts(input.SportID, input.CollegeID)
+	if err != nil {
+		return nil, err
+	}
+	choiceCounts, err := s.surveyDB.GetChoiceCounts(input.SportID, input.CollegeID)
+	if err != nil {
+		return nil, err
+	}
+
+	program, sport, inDivision := histograms(programCounts), histograms(sportCounts), histograms(divisionCounts)
+	response := &SurveyAnalyticsResponse{
+		SportID:    input.SportID,
+		CollegeID:  input.CollegeID,
+		Division:   division,
+		Dimensions: make([]DimensionAnalytics, 0, len(dimensions)),
+		Choices:    choices(choiceCounts),
+		Trends:     trends(yearCounts, trendCounts),
+	}
+	for _, year := range yearCounts {
+		response.ResponseCount += year.Count
+	}
+	for _, dimension := range dimensions {
+		h := program[dimension]
+		low, high := h.ConfidenceInterval()
+		response.Dimensions = append(response.Dimensions, DimensionAnalytics{
+			Dimension:    dimension,
+			Histogram:    h,
+			Mean:         h.Mean(),
+			StdDev:       h.StdDev(),
+			CILow:        low,
+			CIHigh:       high,
+			SmoothedMean: Smooth(h.Mean(), h.Count(), sport[dimension].Mean(), input.PriorWeight),
+			Sport:        BaselineStats{Mean: sport[dimension].Mean(), ResponseCount: sport[dimension].Count()},
+			Division:     BaselineStats{Mean: inDivision[dimension].Mean(), ResponseCount: inDivision[dimension].Count()},
+		})
+	}
+	return &utils.ResponseBody[SurveyAnalyticsResponse]{Body: response}, nil
+}
+
+// histograms collects score counts into a histogram per dimension.
+func histograms(counts []scoreCount) map[string]Histogram {
+	byDimension := make(map[string]Histogram)
+	for _, c := range counts {
+		h := byDimension[c.Dimension]
+		h.Add(c.Score, c.Count)
+		byDimension[c.Dimension] = h
+	}
+	return byDimension
+}
+
+// trends collects the response counts and score counts of each academic year, both ordered by
+// year, into each year's means.
+func trends(years []yearCount, counts []scoreCount) []AcademicYearTrend {
+	result := make([]AcademicYearTrend, 0, len(years))
+	start := 0
+	for _, year := range years {
+		end := start
+		for end < len(counts) && counts[end].StartYear == year.StartYear {
+			end++
+		}
+		byDimension := histograms(counts[start:end])
+		trend := AcademicYearTrend{
+			AcademicYear:  AcademicYear(year.StartYear),
+			ResponseCount: year.Count,
+			Means:         make(map[string]float64, len(byDimension)),
 		}
+		for dimension, h := range byDimension {
+			trend.Means[dimension] = h.Mean()
+		}
+		result = append(result, trend)
+		start = end
+	}
+	return result
+}
+
+// choices collects option counts ordered by question into a count per question.
+func choices(counts []choiceCount) []ChoiceAnalytics {
+	result := []ChoiceAnalytics{}
+	for _, c := range counts {
+		if len(result) == 0 || result[len(result)-1].Key != c.Key {
+			result = append(result, ChoiceAnalytics{Key: c.Key, Counts: map[string]int64{}})
+		}
+		result[len(result)-1].Counts[c.Choice] = c.Count
 	}
-	return nil
-}
\ No newline at end of file
+	return result
+}
@@ -0,0 +1,91 @@
+package survey
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	minRating = 1
+	maxRating = 5
+	// z95 is the z-score of a 95% confidence interval
+	z95 = 1.96
+)
+
+// Histogram counts the responses giving each rating: index 0 is the number of 1s, index 4 of 5s.
+type Histogram [maxRating]int64
+
+// Add counts n responses giving rating, ignoring ratings outside 1-5.
+func (h *Histogram) Add(rating int32, n int64) {
+	if rating >= minRating && rating <= maxRating {
+		h[rating-minRating] += n
+	}
+}
+
+// Count is the number of responses.
+func (h Histogram) Count() int64 {
+	var n int64
+	for _, c := range h {
+		n += c
+	}
+	return n
+}
+
+// Mean is the average rating, or 0 without responses.
+func (h Histogram) Mean() float64 {
+	n := h.Count()
+	if n == 0 {
+		return 0
+	}
+	var sum float64
+	for i, c := range h {
+		sum += float64(int64(i+minRating) * c)
+	}
+	return sum / float64(n)
+}
+
+// StdDev is the sample standard deviation of the ratings, or 0 with fewer than two responses.
+func (h Histogram) StdDev() float64 {
+	n := h.Count()
+	if n < 2 {
+		return 0
+	}
+	mean := h.Mean()
+	var squares float64
+	for i, c := range h {
+		d := float64(i+minRating) - mean
+		squares += d * d * float64(c)
+	}
+	return math.Sqrt(squares / float64(n-1))
+}
+
+// ConfidenceInterval is the 95% confidence interval of the mean, within 1-5. With fewer than two
+// responses nothing is known about the spread, so it's the whole scale.
+func (h Histogram) ConfidenceInterval() (low, high float64) {
+	n := h.Count()
+	if n < 2 {
+		return minRating, maxRating
+	}
+	margin := z95 * h.StdDev() / math.Sqrt(float64(n))
+	mean := h.Mean()
+	return math.Max(minRating, mean-margin), math.Min(maxRating, mean+margin)
+}
+
+// Smooth is the Bayesian average of n responses averaging mean, starting from weight imaginary
+// responses averaging prior. Programs with few responses stay close to prior, so a couple of
+// glowing reviews don't top the rankings.
+func Smooth(mean float64, n int64, prior float64, weight float64) float64 {
+	if float64(n)+weight == 0 {
+		return prior
+	}
+	return (mean*float64(n) + prior*weight) / (float64(n) + weight)
+}
+
+// academicYearStart is the month academic years start in
+const academicYearStart = time.August
+
+// AcademicYear names the academic year starting in startYear, e.g. "2025-26".
+func AcademicYear(startYear int) string {
+	return fmt.Sprintf("%d-%02d", startYear, (startYear+1)%100)
+}
@@ -2,22 +2,28 @@ package survey
 
 import (
 	models "inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"sort"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// CreateSurveyRequest defines the request body for submitting a survey
+// CreateSurveyRequest defines the request body for submitting a survey. Submitting again for the
+// same college and sport edits your response.
 type CreateSurveyRequest struct {
-	UserID                    uuid.UUID `json:"user_id" binding:"required" doc:"ID of the user submitting the survey"`
-	CollegeID                 uuid.UUID `json:"college_id" binding:"required" doc:"ID of the college being rated"`
-	SportID                   uuid.UUID `json:"sport_id" binding:"required" doc:"ID of the sport program being rated"`
-	PlayerDev                 int32     `json:"player_dev" binding:"required,min=1,max=5" example:"4" doc:"Player development rating (1–5)"`
-	AcademicsAthleticsPriority int32   `json:"academics_athletics_priority" binding:"required,min=1,max=5" example:"3" doc:"Academics vs athletics priority rating (1–5)"`
-	AcademicCareerResources   int32     `json:"academic_career_resources" binding:"required,min=1,max=5" example:"4" doc:"Academic/career resources rating (1–5)"`
-	MentalHealthPriority      int32     `json:"mental_health_priority" binding:"required,min=1,max=5" example:"3" doc:"Mental health priority rating (1–5)"`
-	Environment               int32     `json:"environment" binding:"required,min=1,max=5" example:"5" doc:"Environment rating (1–5)"`
-	Culture                   int32     `json:"culture" binding:"required,min=1,max=5" example:"4" doc:"Culture rating (1–5)"`
-	Transparency              int32     `json:"transparency" binding:"required,min=1,max=5" example:"3" doc:"Transparency rating (1–5)"`
+	CollegeID         uuid.UUID     `json:"college_id" binding:"required" doc:"ID of the college being rated"`
+	SportID           uuid.UUID     `json:"sport_id" binding:"required" doc:"ID of the sport program being rated"`
+	TemplateVersionID uuid.UUID     `json:"template_version_id" doc:"ID of the latest version of the survey template being answered"`
+	Answers           []AnswerInput `json:"answers" minItems:"1" doc:"Answers to the version's questions. Every required question must be answered"`
+}
+
+// AnswerInput is the answer to one question, set in the field matching the question's type
+type AnswerInput struct {
+	QuestionKey string  `json:"question_key" minLength:"1" maxLength:"50" example:"culture" doc:"Key of the question being answered"`
+	Scale       *int32  `json:"scale,omitempty" minimum:"1" maximum:"5" example:"4" doc:"Rating from 1 to 5, for scale questions"`
+	Choice      *string `json:"choice,omitempty" maxLength:"200" doc:"One of the question's options, for multiple choice questions"`
+	Text        *string `json:"text,omitempty" maxLength:"2000" doc:"Free text, for text questions"`
 }
 
 // DeleteSurveyRequest defines the path parameter for deleting a survey
@@ -25,6 +31,14 @@ type DeleteSurveyRequest struct {
 	ID uuid.UUID `path:"id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the survey to delete"`
 }
 
+// InvalidateSurveyInput defines the request for leaving a suspicious survey out of the averages
+type InvalidateSurveyInput struct {
+	ID   uuid.UUID `path:"id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the survey to invalidate"`
+	Body struct {
+		Reason string `json:"reason" minLength:"1" maxLength:"1000" example:"Submitted from an account created to inflate the ratings" doc:"Why the survey was invalidated"`
+	}
+}
+
 // GetSurveysByUserParams defines path + query params for listing a user's surveys
 type GetSurveysByUserParams struct {
 	UserID uuid.UUID `path:"user_id" binding:"required" doc:"ID of the user"`
@@ -32,25 +46,64 @@ type GetSurveysByUserParams struct {
 	Offset int       `query:"offset" default:"0" example:"0" doc:"Number of surveys to skip"`
 }
 
+// QuestionInput defines a question of a new survey template version
+type QuestionInput struct {
+	Key      string                    `json:"key" pattern:"^[a-z][a-z0-9_]*$" maxLength:"50" example:"culture" doc:"Identifies the question across versions and templates, so its answers are aggregated together. A key keeps the type it was first given"`
+	Type     models.SurveyQuestionType `json:"type" enum:"scale,multiple_choice,text" example:"scale"`
+	Prompt   string                    `json:"prompt" minLength:"1" maxLength:"500" example:"How good is the team culture?"`
+	Required bool                      `json:"required" required:"false" default:"true" doc:"Whether the question must be answered"`
+	Options  []string                  `json:"options,omitempty" maxItems:"20" doc:"The answers a multiple choice question allows; at least two, and only for multiple choice questions"`
+}
+
+// CreateSurveyTemplateInput defines the request for defining a survey template, published as version 1
+type CreateSurveyTemplateInput struct {
+	Body struct {
+		Name        string          `json:"name" minLength:"1" maxLength:"100" example:"Athlete Experience"`
+		Description *string         `json:"description,omitempty" maxLength:"1000" example:"How athletes rate their program"`
+		Questions   []QuestionInput `json:"questions" minItems:"1" maxItems:"50" doc:"The questions, in the order they're asked"`
+	}
+}
+
+// PublishSurveyTemplateVersionInput defines the request for publishing a new version of a template
+type PublishSurveyTemplateVersionInput struct {
+	ID   uuid.UUID `path:"id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the survey template"`
+	Body struct {
+		Questions []QuestionInput `json:"questions" minItems:"1" maxItems:"50" doc:"The questions, in the order they're asked. Keep the keys of questions carried over from earlier versions so they're aggregated across versions"`
+	}
+}
+
+// GetSurveyTemplateParams defines the template version to get
+type GetSurveyTemplateParams struct {
+	ID      uuid.UUID `path:"id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the survey template"`
+	Version int       `query:"version" minimum:"0" example:"2" doc:"Version to get; the latest when left out" required:"false"`
+}
+
 // GetAverageRatingsParams defines optional query filters for the averages endpoint
 type GetAverageRatingsParams struct {
-	SportID   uuid.UUID `query:"sport_id" doc:"Filter by sport ID" required:"false"`
-	CollegeID uuid.UUID `query:"college_id" doc:"Filter by college ID" required:"false"`
+	SportID      uuid.UUID `query:"sport_id" doc:"Filter by sport ID" required:"false"`
+	CollegeID    uuid.UUID `query:"college_id" doc:"Filter by college ID" required:"false"`
+	VerifiedOnly bool      `query:"verified_only" default:"false" doc:"Only average surveys by verified athletes of the sport at the college" required:"false"`
+	PriorWeight  float64   `query:"prior_weight" default:"5" minimum:"0" maximum:"100" example:"5" doc:"How many responses at the sport's average each program's smoothed score starts from" required:"false"`
+}
+
+// GetSurveyAnalyticsParams defines the program to get survey analytics for
+type GetSurveyAnalyticsParams struct {
+	SportID     uuid.UUID `query:"sport_id" required:"true" doc:"ID of the sport program"`
+	CollegeID   uuid.UUID `query:"college_id" required:"true" doc:"ID of the college"`
+	PriorWeight float64   `query:"prior_weight" default:"5" minimum:"0" maximum:"100" example:"5" doc:"How many responses at the sport's average the smoothed means start from"`
 }
 
 // SurveyResponse defines the response structure for a single survey
 type SurveyResponse struct {
-	ID                        uuid.UUID `json:"id" doc:"Survey ID"`
-	UserID                    uuid.UUID `json:"user_id" doc:"User ID"`
-	CollegeID                 uuid.UUID `json:"college_id" doc:"College ID"`
-	SportID                   uuid.UUID `json:"sport_id" doc:"Sport ID"`
-	PlayerDev                 int32     `json:"player_dev" doc:"Player development rating"`
-	AcademicsAthleticsPriority int32   `json:"academics_athletics_priority" doc:"Academics vs athletics priority rating"`
-	AcademicCareerResources   int32     `json:"academic_career_resources" doc:"Academic/career resources rating"`
-	MentalHealthPriority      int32     `json:"mental_health_priority" doc:"Mental health priority rating"`
-	Environment               int32     `json:"environment" doc:"Environment rating"`
-	Culture                   int32     `json:"culture" doc:"Culture rating"`
-	Transparency              int32     `json:"transparency" doc:"Transparency rating"`
+	ID                 uuid.UUID          `json:"id" doc:"Survey ID"`
+	UserID             uuid.UUID          `json:"user_id" doc:"User ID"`
+	CollegeID          uuid.UUID          `json:"college_id" doc:"College ID"`
+	SportID            uuid.UUID          `json:"sport_id" doc:"Sport ID"`
+	TemplateVersionID  uuid.UUID          `json:"template_version_id" doc:"ID of the survey template version answered"`
+	Answers            []AnswerResponse   `json:"answers" doc:"Answers, in the order the questions are asked"`
+	AuthorTrust        *utils.AuthorTrust `json:"author_trust" doc:"How far to trust the respondent on the college and sport they rated"`
+	InvalidatedAt      *time.Time         `json:"invalidated_at,omitempty" doc:"When an admin invalidated the survey, leaving it out of the averages"`
+	InvalidationReason *string            `json:"invalidation_reason,omitempty" doc:"Why the survey was invalidated"`
 }
 
 // GetSurveysByUserResponse wraps a paginated list of the user's surveys
@@ -59,18 +112,25 @@ type GetSurveysByUserResponse struct {
 	Total   int              `json:"total" doc:"Total number of surveys submitted by this user"`
 }
 
-// AverageRatingsRow is the raw DB scan target for the averages query
+// AverageRatingsRow is a program's averages. The counts and overall are the raw DB scan target for
+// the averages query.
 type AverageRatingsRow struct {
-	SportID                    uuid.UUID `json:"sport_id"                    gorm:"column:sport_id"`
-	CollegeID                  uuid.UUID `json:"college_id"                  gorm:"column:college_id"`
-	PlayerDev                  float64   `json:"player_dev"                  gorm:"column:player_dev"`
-	AcademicsAthleticsPriority float64   `json:"academics_athletics_priority" gorm:"column:academics_athletics_priority"`
-	AcademicCareerResources    float64   `json:"academic_career_resources"   gorm:"column:academic_career_resources"`
-	MentalHealthPriority       float64   `json:"mental_health_priority"      gorm:"column:mental_health_priority"`
-	Environment                float64   `json:"environment"                 gorm:"column:environment"`
-	Culture                    float64   `json:"culture"                     gorm:"column:culture"`
-	Transparency               float64   `json:"transparency"                gorm:"column:transparency"`
-	ResponseCount              int64     `json:"response_count"              gorm:"column:response_count"`
+	SportID               uuid.UUID         `json:"sport_id"                gorm:"column:sport_id"`
+	CollegeID             uuid.UUID         `json:"college_id"              gorm:"column:college_id"`
+	ResponseCount         int64             `json:"response_count"          gorm:"column:response_count"`
+	VerifiedResponseCount int64             `json:"verified_response_count" gorm:"column:verified_response_count" doc:"Number of the responses by verified athletes of the sport at the college"`
+	Overall               float64           `json:"overall"                 gorm:"column:overall" doc:"Average of each response's mean scale answer"`
+	SmoothedOverall       float64           `json:"smoothed_overall"        gorm:"-" doc:"Overall pulled towards the sport's average in proportion to how few responses there are. Averages are ranked by it"`
+	Questions             []QuestionAverage `json:"questions"               gorm:"-" doc:"Average answer to each scale question, by key, whichever template version it was answered in"`
+}
+
+// QuestionAverage is the average answer a program was given to a scale question
+type QuestionAverage struct {
+	SportID       uuid.UUID `json:"-"`
+	CollegeID     uuid.UUID `json:"-"`
+	Key           string    `json:"key" example:"culture"`
+	Mean          float64   `json:"mean" example:"4.2"`
+	ResponseCount int64     `json:"response_count" example:"12"`
 }
 
 // AverageRatingsResponse wraps the list of grouped averages
@@ -78,19 +138,139 @@ type AverageRatingsResponse struct {
 	Averages []AverageRatingsRow `json:"averages" doc:"Average ratings grouped by sport and college"`
 }
 
-// ToSurveyResponse converts a Survey model to a SurveyResponse
+// BaselineStats is how a wider group of programs answered a scale question
+type BaselineStats struct {
+	Mean          float64 `json:"mean" example:"3.8"`
+	ResponseCount int64   `json:"response_count" example:"240"`
+}
+
+// DimensionAnalytics describes how a program was rated on one scale question
+type DimensionAnalytics struct {
+	Dimension    string        `json:"dimension" example:"culture" doc:"Key of the scale question"`
+	Histogram    Histogram     `json:"histogram" doc:"Number of responses giving each rating, from 1 to 5"`
+	Mean         float64       `json:"mean" example:"4.2"`
+	StdDev       float64       `json:"std_dev" example:"0.8" doc:"Sample standard deviation; 0 with fewer than two responses"`
+	CILow        float64       `json:"ci_low" example:"3.9" doc:"Low end of the 95% confidence interval of the mean"`
+	CIHigh       float64       `json:"ci_high" example:"4.5" doc:"High end of the 95% confidence interval of the mean"`
+	SmoothedMean float64       `json:"smoothed_mean" example:"4.1" doc:"Mean pulled towards the sport's mean in proportion to how few responses there are"`
+	Sport        BaselineStats `json:"sport" doc:"The sport at every college"`
+	Division     BaselineStats `json:"division" doc:"The sport at colleges in the college's division"`
+}
+
+// AcademicYearTrend is how a program was rated in one academic year, which starts in August
+type AcademicYearTrend struct {
+	AcademicYear  string             `json:"academic_year" example:"2025-26"`
+	ResponseCount int64              `json:"response_count" example:"12"`
+	Means         map[string]float64 `json:"means" doc:"Mean answer to each scale question answered that year, by key"`
+}
+
+// ChoiceAnalytics counts how often a program's respondents picked each option of a multiple choice question
+type ChoiceAnalytics struct {
+	Key    string           `json:"key" example:"position_group"`
+	Counts map[string]int64 `json:"counts" doc:"Number of responses picking each option"`
+}
+
+// SurveyAnalyticsResponse describes how a program was rated, leaving out invalidated surveys.
+// Questions are matched by key, so answers from every template version are counted together.
+type SurveyAnalyticsResponse struct {
+	SportID       uuid.UUID            `json:"sport_id"`
+	CollegeID     uuid.UUID            `json:"college_id"`
+	Division      models.Division      `json:"division" example:"1" doc:"The college's division"`
+	ResponseCount int64                `json:"response_count" example:"12"`
+	Dimensions    []DimensionAnalytics `json:"dimensions" doc:"A dimension per scale question answered for the sport, in the order they're asked"`
+	Choices       []ChoiceAnalytics    `json:"choices" doc:"A count per multiple choice question answered for the program"`
+	Trends        []AcademicYearTrend  `json:"trends" doc:"Oldest first, by when surveys were last edited. Years without responses are left out"`
+}
+
+// AnswerResponse is a survey's answer to one question
+type AnswerResponse struct {
+	QuestionKey string                    `json:"question_key" example:"culture"`
+	Type        models.SurveyQuestionType `json:"type" example:"scale"`
+	Scale       *int32                    `json:"scale,omitempty" example:"4"`
+	Choice      *string                   `json:"choice,omitempty"`
+	Text        *string                   `json:"text,omitempty"`
+}
+
+// SurveyTemplateResponse is a version of a survey template, with its questions in the order they're asked
+type SurveyTemplateResponse struct {
+	ID            uuid.UUID               `json:"id" doc:"ID of the template"`
+	Name          string                  `json:"name" example:"Athlete Experience"`
+	Description   *string                 `json:"description,omitempty"`
+	VersionID     uuid.UUID               `json:"version_id" doc:"ID of the version, which surveys answering it are submitted with"`
+	Version       int                     `json:"version" example:"2"`
+	LatestVersion int                     `json:"latest_version" example:"2" doc:"Only the latest version can be answered"`
+	PublishedAt   time.Time               `json:"published_at" doc:"When the version was published"`
+	Questions     []models.SurveyQuestion `json:"questions"`
+}
+
+// GetSurveyTemplatesResponse lists the survey templates
+type GetSurveyTemplatesResponse struct {
+	Templates []SurveyTemplateResponse `json:"templates" doc:"Every template at its latest version"`
+}
+
+// scoreCount is the number of surveys giving a rating on a dimension, in an academic year for trends
+type scoreCount struct {
+	StartYear int
+	Dimension string
+	Score     int32
+	Count     int64
+}
+
+// choiceCount is the number of surveys picking an option of a multiple choice question
+type choiceCount struct {
+	Key    string
+	Choice string
+	Count  int64
+}
+
+// yearCount is the number of surveys last edited in an academic year
+type yearCount struct {
+	StartYear int
+	Count     int64
+}
+
+// ToSurveyResponse converts a Survey model to a SurveyResponse. Answers must be loaded with their questions.
 func ToSurveyResponse(m *models.Survey) *SurveyResponse {
+	answers := make([]models.SurveyAnswer, len(m.Answers))
+	copy(answers, m.Answers)
+	sort.SliceStable(answers, func(i, j int) bool { return answers[i].Question.Position < answers[j].Question.Position })
 	return &SurveyResponse{
-		ID:                        m.ID,
-		UserID:                    m.UserID,
-		CollegeID:                 m.CollegeID,
-		SportID:                   m.SportID,
-		PlayerDev:                 m.PlayerDev,
-		AcademicsAthleticsPriority: m.AcademicsAthleticsPriority,
-		AcademicCareerResources:   m.AcademicCareerResources,
-		MentalHealthPriority:      m.MentalHealthPriority,
-		Environment:               m.Environment,
-		Culture:                   m.Culture,
-		Transparency:              m.Transparency,
+		ID:                 m.ID,
+		UserID:             m.UserID,
+		CollegeID:          m.CollegeID,
+		SportID:            m.SportID,
+		TemplateVersionID:  m.TemplateVersionID,
+		Answers:            utils.MapList(answers, toAnswerResponse),
+		AuthorTrust:        utils.TrustOf(&m.User, &m.CollegeID, &m.SportID, false),
+		InvalidatedAt:      m.InvalidatedAt,
+		InvalidationReason: m.InvalidationReason,
+	}
+}
+
+func toAnswerResponse(a models.SurveyAnswer) AnswerResponse {
+	return AnswerResponse{
+		QuestionKey: a.Question.Key,
+		Type:        a.Question.Type,
+		Scale:       a.Scale,
+		Choice:      a.Choice,
+		Text:        a.Text,
+	}
+}
+
+// ToSurveyTemplateResponse converts a template version to a SurveyTemplateResponse. Its questions
+// must be loaded.
+func ToSurveyTemplateResponse(template *models.SurveyTemplate, version *models.SurveyTemplateVersion, latest int) *SurveyTemplateResponse {
+	questions := make([]models.SurveyQuestion, len(version.Questions))
+	copy(questions, version.Questions)
+	sort.SliceStable(questions, func(i, j int) bool { return questions[i].Position < questions[j].Position })
+	return &SurveyTemplateResponse{
+		ID:            template.ID,
+		Name:          template.Name,
+		Description:   template.Description,
+		VersionID:     version.ID,
+		Version:       version.Version,
+		LatestVersion: latest,
+		PublishedAt:   version.CreatedAt,
+		Questions:     questions,
 	}
-}
\ No newline at end of file
+}
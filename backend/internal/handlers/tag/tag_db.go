@@ -1,8 +1,10 @@
 package tag
 
 import (
+	"inside-athletics/internal/handlers/post"
 	"inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
@@ -13,38 +15,49 @@ type TagDB struct {
 	db *gorm.DB
 }
 
-func (u *TagDB) ListTags(limit int, offset int) (*[]models.Tag, error) {
+// tagKeyset pages tags alphabetically.
+var tagKeyset = utils.Keyset{KeyColumn: "tags.name", IDColumn: "tags.id"}
+
+func tagCursor(tag *models.Tag) utils.Cursor[string] {
+	return utils.Cursor[string]{Key: tag.Name, ID: tag.ID}
+}
+
+func (u *TagDB) ListTags(page *utils.Page[string]) (*[]models.Tag, utils.PageCursors, error) {
 	var tags []models.Tag
-	dbResponse := u.db.
-		Model(&models.Tag{}).
-		Order("name ASC").
-		Limit(limit).
-		Offset(offset).
+	dbResponse := page.Apply(u.db.
+		Model(&models.Tag{})).
 		Find(&tags)
-	return utils.HandleDBError(&tags, dbResponse.Error)
+	if dbResponse.Error != nil {
+		_, err := utils.HandleDBError(&tags, dbResponse.Error)
+		return nil, utils.PageCursors{}, err
+	}
+
+	tags, cursors := utils.Results(page, tags, tagCursor)
+	return &tags, cursors, nil
 }
 
-func (u *TagDB) GetPostsByTag(tag_id uuid.UUID, limit int, offset int, userID uuid.UUID) (*[]models.Post, error) {
+func (u *TagDB) GetPostsByTag(tag_id uuid.UUID, page *utils.Page[time.Time], userID uuid.UUID) (*[]models.Post, utils.PageCursors, error) {
 	var posts []models.Post
-	dbResponse := u.db.
+	dbResponse := page.Apply(u.db.
 		Table("posts").
-		Select(`posts.*,
-            (SELECT COUNT(*) FROM post_likes WHERE post_likes.post_id = posts.id) AS like_count,
-            (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) AS comment_count,
-            (SELECT COUNT(*) > 0 FROM post_likes WHERE post_likes.post_id = posts.id AND post_likes.user_id = ?) AS is_liked`,
-			userID).
+		Select(post.POST_SELECT_QUERY, userID).
 		Joins("JOIN tag_posts tp ON tp.postable_id = posts.id AND tp.postable_type = 'post'").
 		Where("tp.tag_id = ?", tag_id).
+		Scopes(utils.VisibleTo(utils.PostsTable, userID)).
 		Preload("Author").
 		Preload("Sport", "id IS NOT NULL").
 		Preload("College", "id IS NOT NULL").
 		Preload("Tags", func(db *gorm.DB) *gorm.DB {
 			return db.Table("tags t").Joins("JOIN tag_posts tp ON tp.tag_id = t.id AND tp.postable_type = 'post'")
-		}).
-		Limit(limit).
-		Offset(offset).
+		})).
 		Find(&posts)
-	return utils.HandleDBError(&posts, dbResponse.Error)
+	if dbResponse.Error != nil {
+		_, err := utils.HandleDBError(&posts, dbResponse.Error)
+		return nil, utils.PageCursors{}, err
+	}
+
+	posts, cursors := utils.Results(page, posts, post.PostCursor)
+	return &posts, cursors, nil
 }
 
 func (u *TagDB) GetTagByName(name string) (*models.Tag, error) {
@@ -99,6 +112,6 @@ func (u *TagDB) DeleteTag(id uuid.UUID) error {
 	return nil
 }
 
-func (u *TagDB) FuzzySearchFor(searchStr string, limit int) ([]models.Tag, error) {
-	return utils.FuzzySearchForDB(searchStr, limit, u.db, "name", models.Tag{})
+func (u *TagDB) FuzzySearchFor(input *utils.SearchParam) ([]models.Tag, int64, error) {
+	return utils.FuzzySearchForDB[models.Tag](u.db, input.Search(utils.Column("name")))
 }
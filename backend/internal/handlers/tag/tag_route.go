@@ -2,7 +2,9 @@ package tag
 
 import (
 	"inside-athletics/internal/handlers/tagpost"
+	"inside-athletics/internal/models"
 	"inside-athletics/internal/s3"
+	"inside-athletics/internal/utils"
 
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
@@ -15,13 +17,13 @@ func Route(api huma.API, db *gorm.DB, s3Svc *s3.Service) {
 	{
 		grp := huma.NewGroup(api, "/api/v1/tag")
 		huma.Get(grp, "", tagService.ListTags)
-		huma.Post(grp, "/", tagService.CreateTag)
+		huma.Post(grp, "/", tagService.CreateTag, utils.Requires(utils.Permission(models.PermissionCreate, "tag")))
 		huma.Get(grp, "/name/{name}", tagService.GetTagByName)
 		huma.Get(grp, "/{id}", tagService.GetTagById)
 		huma.Get(grp, "/{tag_id}/posts", tagService.GetPostsByTag)
 		huma.Get(grp, "/type/{type}", tagService.GetTagsByType)
-		huma.Patch(grp, "/{id}", tagService.UpdateTag)
-		huma.Delete(grp, "/{id}", tagService.DeleteTag)
+		huma.Patch(grp, "/{id}", tagService.UpdateTag, utils.Requires(utils.Permission(models.PermissionUpdate, "tag")))
+		huma.Delete(grp, "/{id}", tagService.DeleteTag, utils.Requires(utils.Permission(models.PermissionDelete, "tag")))
 	}
 	{
 		grp := huma.NewGroup(api, "/api/v1/tags")
@@ -8,6 +8,7 @@ import (
 	"inside-athletics/internal/models"
 	"inside-athletics/internal/s3"
 	"inside-athletics/internal/utils"
+	"time"
 )
 
 type TagService struct {
@@ -17,14 +18,19 @@ type TagService struct {
 }
 
 func (u *TagService) ListTags(ctx context.Context, input *ListTagsParams) (*utils.ResponseBody[ListTagsResponse], error) {
-	tags, err := u.tagDB.ListTags(input.Limit, input.Offset)
 	respBody := &utils.ResponseBody[ListTagsResponse]{}
+	page, err := utils.NewPage[string](tagKeyset, input.Cursor, input.Limit, input.Offset)
+	if err != nil {
+		return respBody, err
+	}
+	tags, cursors, err := u.tagDB.ListTags(page)
 	if err != nil {
 		return respBody, err
 	}
 
 	response := &ListTagsResponse{
-		Tags: make([]GetTagResponse, 0, len(*tags)),
+		Tags:        make([]GetTagResponse, 0, len(*tags)),
+		PageCursors: cursors,
 	}
 	for _, tag := range *tags {
 		response.Tags = append(response.Tags, GetTagResponse{
@@ -106,8 +112,12 @@ func (u *TagService) GetPostsByTag(ctx context.Context, input *GetPostsByTagPara
 	if err != nil {
 		return nil, err
 	}
-	posts, err := u.tagDB.GetPostsByTag(input.TagID, input.Limit, input.Offset, userID)
 	respBody := &utils.ResponseBody[GetPostsByTagResponse]{}
+	page, err := utils.NewPage[time.Time](post.PostKeyset, input.Cursor, input.Limit, input.Offset)
+	if err != nil {
+		return respBody, err
+	}
+	posts, cursors, err := u.tagDB.GetPostsByTag(input.TagID, page, userID)
 
 	if err != nil {
 		return respBody, err
@@ -128,7 +138,8 @@ func (u *TagService) GetPostsByTag(ctx context.Context, input *GetPostsByTagPara
 	}
 
 	response := &GetPostsByTagResponse{
-		Posts: postResponses,
+		Posts:       postResponses,
+		PageCursors: cursors,
 	}
 
 	return &utils.ResponseBody[GetPostsByTagResponse]{
@@ -194,7 +205,7 @@ func (u *TagService) DeleteTag(ctx context.Context, input *GetTagByIDParams) (*u
 }
 
 func (u *TagService) FuzzySearchFor(ctx context.Context, input *utils.SearchParam) (*utils.ResponseBody[utils.SearchResults[*GetTagResponse]], error) {
-	return utils.FuzzySearchService(input, models.Tag{}, GetTagResponse{}, "name", u.tagDB.db, getTagResponse)
+	return utils.FuzzySearchService(input, u.tagDB.FuzzySearchFor, getTagResponse)
 }
 
 func getTagResponse(tag *models.Tag) *GetTagResponse {
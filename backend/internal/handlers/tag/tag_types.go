@@ -4,6 +4,7 @@ package tag
 import (
 	"inside-athletics/internal/handlers/post"
 	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
 
 	"github.com/google/uuid"
 )
@@ -12,10 +13,12 @@ import (
 type GetPostsByTagParam struct {
 	TagID  uuid.UUID `path:"tag_id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the Tag"`
 	Limit  int       `query:"limit" default:"50" example:"50" doc:"Number of posts to return"`
-	Offset int       `query:"offset" default:"0" example:"0" doc:"Number of posts to skip"`
+	Cursor string    `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page. Takes precedence over offset"`
+	Offset int       `query:"offset" default:"0" example:"0" deprecated:"true" doc:"Number of posts to skip. Deprecated: use cursor"`
 }
 type GetPostsByTagResponse struct {
 	Posts []post.PostResponse `json:"post_ids" doc:"The post ids associated with a tag"`
+	utils.PageCursors
 }
 
 type GetTagByIDParams struct {
@@ -31,8 +34,9 @@ type GetTagsByTypeParams struct {
 }
 
 type ListTagsParams struct {
-	Limit  int `query:"limit" default:"100" example:"100" doc:"Number of tags to return"`
-	Offset int `query:"offset" default:"0" example:"0" doc:"Number of tags to skip"`
+	Limit  int    `query:"limit" default:"100" example:"100" doc:"Number of tags to return"`
+	Cursor string `query:"cursor" default:"" example:"eyJrIjoiSG9ja2V5IiwiaWQiOiIxMjNlNDU2Ny1lODliLTEyZDMtYTQ1Ni00MjY2MTQxNzQwMDAifQ" doc:"next_cursor or prev_cursor from a previous page. Takes precedence over offset"`
+	Offset int    `query:"offset" default:"0" example:"0" deprecated:"true" doc:"Number of tags to skip. Deprecated: use cursor"`
 }
 
 type GetPostsByTagParams struct {
@@ -47,6 +51,7 @@ type GetTagResponse struct {
 
 type ListTagsResponse struct {
 	Tags []GetTagResponse `json:"tags" doc:"List of tags"`
+	utils.PageCursors
 }
 
 type CreateTagInput struct {
@@ -3,6 +3,7 @@ package tagfollow
 import (
 	"inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
@@ -13,12 +14,27 @@ type TagFollowDB struct {
 	db *gorm.DB
 }
 
-func (u *TagFollowDB) GetTagFollowsByUser(user_id uuid.UUID) (*[]uuid.UUID, error) {
-	var tagIDs []uuid.UUID
-	dbResponse := u.db.Model(&models.TagFollow{}).
-		Where("user_id = ?", user_id).
-		Pluck("tag_id", &tagIDs)
-	return utils.HandleDBError(&tagIDs, dbResponse.Error)
+// tagFollowKeyset pages a user's followed tags, most recently followed first.
+var tagFollowKeyset = utils.Keyset{KeyColumn: "tag_follows.created_at", IDColumn: "tag_follows.id", Descending: true}
+
+func tagFollowCursor(follow *models.TagFollow) utils.Cursor[time.Time] {
+	return utils.Cursor[time.Time]{Key: follow.CreatedAt, ID: follow.ID}
+}
+
+func (u *TagFollowDB) GetTagFollowsByUser(user_id uuid.UUID, page *utils.Page[time.Time]) (*[]uuid.UUID, utils.PageCursors, error) {
+	var follows []models.TagFollow
+	dbResponse := page.Apply(u.db.Model(&models.TagFollow{}).
+		Select("id", "tag_id", "created_at").
+		Where("user_id = ?", user_id)).
+		Find(&follows)
+	if dbResponse.Error != nil {
+		_, err := utils.HandleDBError(&follows, dbResponse.Error)
+		return nil, utils.PageCursors{}, err
+	}
+
+	follows, cursors := utils.Results(page, follows, tagFollowCursor)
+	tagIDs := utils.MapList(follows, func(f models.TagFollow) uuid.UUID { return f.TagID })
+	return &tagIDs, cursors, nil
 }
 
 func (u *TagFollowDB) GetFollowingUsersByTag(tag_id uuid.UUID) (*[]uuid.UUID, error) {
@@ -1,6 +1,9 @@
 package tagfollow
 
 import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
@@ -11,10 +14,10 @@ func Route(api huma.API, db *gorm.DB) {
 	var tagFollowService = &TagFollowService{tagFollowDB}
 	{
 		grp := huma.NewGroup(api, "/api/v1/user/tag")
-		huma.Post(grp, "/", tagFollowService.CreateTagFollow)
+		huma.Post(grp, "/", tagFollowService.CreateTagFollow, utils.Requires(utils.Permission(models.PermissionCreate, "tagfollow")))
 		huma.Get(grp, "/follows", tagFollowService.GetTagFollowsByUser)
 		huma.Get(grp, "/{tag_id}/users", tagFollowService.GetFollowingUsersByTag)
-		huma.Delete(grp, "/tag/{tag_id}", tagFollowService.DeleteTagFollowByTag)
-		huma.Delete(grp, "/{id}", tagFollowService.DeleteTagFollow)
+		huma.Delete(grp, "/tag/{tag_id}", tagFollowService.DeleteTagFollowByTag, utils.Requires(utils.Permission(models.PermissionDeleteOwn, "tagfollow")))
+		huma.Delete(grp, "/{id}", tagFollowService.DeleteTagFollow, utils.Requires(utils.Permission(models.PermissionDelete, "tagfollow").OwnedBy(utils.OwnedBy("tag_follows", "user_id"))))
 	}
 }
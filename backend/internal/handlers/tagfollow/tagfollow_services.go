@@ -4,51 +4,35 @@ import (
 	"context"
 	"inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
-
-	"github.com/danielgtaylor/huma/v2"
-	"github.com/google/uuid"
+	"time"
 )
 
 type TagFollowService struct {
 	tagfollowDB *TagFollowDB
 }
 
-func (u *TagFollowService) getCurrentUserID(ctx context.Context) (uuid.UUID, error) {
-	rawID := ctx.Value("user_id")
-	if rawID == nil {
-		return uuid.Nil, huma.Error401Unauthorized("User not authenticated")
-	}
-
-	userID, ok := rawID.(string)
-	if !ok {
-		return uuid.Nil, huma.Error500InternalServerError("Invalid user ID in context")
-	}
-
-	parsedID, err := uuid.Parse(userID)
-	if err != nil {
-		return uuid.Nil, huma.Error400BadRequest("Invalid user ID", err)
-	}
-
-	return parsedID, nil
-}
-
 // Given a UserID, get all the tags that they follow
 func (u *TagFollowService) GetTagFollowsByUser(ctx context.Context, input *GetTagFollowsByUserParams) (*utils.ResponseBody[GetTagFollowsByUserResponse], error) {
-	userID, err := u.getCurrentUserID(ctx)
+	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	tags, err := u.tagfollowDB.GetTagFollowsByUser(userID)
 	respBody := &utils.ResponseBody[GetTagFollowsByUserResponse]{}
+	page, err := utils.NewPage[time.Time](tagFollowKeyset, input.Cursor, input.Limit, 0)
+	if err != nil {
+		return respBody, err
+	}
+	tags, cursors, err := u.tagfollowDB.GetTagFollowsByUser(userID, page)
 
 	if err != nil {
 		return respBody, err
 	}
 
 	response := &GetTagFollowsByUserResponse{
-		UserID: userID,
-		TagIDs: *tags,
+		UserID:      userID,
+		TagIDs:      *tags,
+		PageCursors: cursors,
 	}
 
 	return &utils.ResponseBody[GetTagFollowsByUserResponse]{
@@ -78,7 +62,7 @@ func (u *TagFollowService) GetFollowingUsersByTag(ctx context.Context, input *Ge
 
 // Given a tag and a user, creates a tag follow if doesn't already exist
 func (u *TagFollowService) CreateTagFollow(ctx context.Context, input *CreateTagFollowInput) (*utils.ResponseBody[CreateTagFollowResponse], error) {
-	userID, err := u.getCurrentUserID(ctx)
+	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -126,7 +110,7 @@ func (u *TagFollowService) DeleteTagFollow(ctx context.Context, input *DeleteTag
 // Soft deletes tag follow by tag id for current user.
 func (u *TagFollowService) DeleteTagFollowByTag(ctx context.Context, input *DeleteTagFollowByTagParams) (*utils.ResponseBody[DeleteTagFollowResponse], error) {
 	respBody := &utils.ResponseBody[DeleteTagFollowResponse]{}
-	userID, err := u.getCurrentUserID(ctx)
+	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return respBody, err
 	}
@@ -1,9 +1,15 @@
 package tagfollow
 
-import "github.com/google/uuid"
+import (
+	"inside-athletics/internal/utils"
+
+	"github.com/google/uuid"
+)
 
 // Given UserID, get all tags that are followed
 type GetTagFollowsByUserParams struct {
+	Limit  int    `query:"limit" default:"100" example:"100" doc:"Number of followed tags to return"`
+	Cursor string `query:"cursor" default:"" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"next_cursor or prev_cursor from a previous page"`
 }
 
 // Given tag, get list of UserIDs that follow this tag
@@ -14,6 +20,7 @@ type GetFollowingUsersByTagParams struct {
 type GetTagFollowsByUserResponse struct {
 	UserID uuid.UUID   `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the user"`
 	TagIDs []uuid.UUID `json:"tag_ids" example:"[\"123e4567-e89b-12d3-a456-426614174000\",\"123e4567-e89b-12d3-a456-426614174001\"]" doc:"The tags the given user follows"`
+	utils.PageCursors
 }
 
 type GetFollowingUsersByTagResponse struct {
@@ -1,6 +1,9 @@
 package tagpost
 
 import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
 )
@@ -10,9 +13,9 @@ func Route(api huma.API, db *gorm.DB) {
 	var tagService = &TagPostService{tagpostDB}
 	{
 		grp := huma.NewGroup(api, "/api/v1/post/tag")
-		huma.Post(grp, "/", tagService.CreateTagPost)
+		huma.Post(grp, "/", tagService.CreateTagPost, utils.Requires(utils.Permission(models.PermissionCreate, "post")))
 		huma.Get(grp, "/{id}", tagService.GetTagPostById)
-		huma.Patch(grp, "/{id}", tagService.UpdateTagPost)
-		huma.Delete(grp, "/{id}", tagService.DeleteTagPost)
+		huma.Patch(grp, "/{id}", tagService.UpdateTagPost, utils.Requires(utils.Permission(models.PermissionUpdate, "post")))
+		huma.Delete(grp, "/{id}", tagService.DeleteTagPost, utils.Requires(utils.Permission(models.PermissionDelete, "post")))
 	}
 }
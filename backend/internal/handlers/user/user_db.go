@@ -37,19 +37,56 @@ func (u *UserDB) CreateUser(user *models.User) (*models.User, error) {
 	return utils.HandleDBError(user, dbResponse.Error)
 }
 
-// This function creates a link between a user and a role in the user_roles table
+// This function creates a link between a user and a role in the user_roles table, limited to scope
+// when it is set. Users can hold any number of roles, and the same role in several scopes.
 // We use FirstOrCreate to avoid duplicate entries if the user already has the role
-func (u *UserDB) AddUserRole(userID, roleID uuid.UUID) error {
+func (u *UserDB) AddUserRole(userID, roleID uuid.UUID, scope *models.RoleScope) error {
 	userRole := models.UserRole{
 		UserID: userID,
 		RoleID: roleID,
 	}
-	if err := u.db.Where("user_id = ? AND role_id = ?", userID, roleID).FirstOrCreate(&userRole).Error; err != nil {
+	if scope != nil {
+		userRole.ScopeType, userRole.ScopeID = &scope.Type, &scope.ID
+	}
+	if err := userRoleQuery(u.db, userID, roleID, scope).FirstOrCreate(&userRole).Error; err != nil {
 		return huma.Error500InternalServerError("Failed to assign role to user", err)
 	}
 	return nil
 }
 
+// RemoveUserRole takes a role in scope, or the unscoped role when scope is nil, away from a user.
+func (u *UserDB) RemoveUserRole(userID, roleID uuid.UUID, scope *models.RoleScope) error {
+	dbResponse := userRoleQuery(u.db, userID, roleID, scope).Delete(&models.UserRole{})
+	if dbResponse.Error != nil {
+		return huma.Error500InternalServerError("Failed to remove role from user", dbResponse.Error)
+	}
+	if dbResponse.RowsAffected == 0 {
+		return huma.Error404NotFound("User doesn't have this role")
+	}
+	return nil
+}
+
+func userRoleQuery(db *gorm.DB, userID, roleID uuid.UUID, scope *models.RoleScope) *gorm.DB {
+	query := db.Where("user_id = ? AND role_id = ?", userID, roleID)
+	if scope == nil {
+		return query.Where("scope_id IS NULL")
+	}
+	return query.Where("scope_type = ? AND scope_id = ?", scope.Type, scope.ID)
+}
+
+// ScopeExists reports whether the sport or college a role is scoped to exists.
+func (u *UserDB) ScopeExists(scope models.RoleScope) (bool, error) {
+	var count int64
+	table := "sports"
+	if scope.Type == models.RoleScopeCollege {
+		table = "colleges"
+	}
+	if err := u.db.Table(table).Where("id = ?", scope.ID).Count(&count).Error; err != nil {
+		return false, huma.Error500InternalServerError("Failed to check role scope", err)
+	}
+	return count > 0, nil
+}
+
 func (u *UserDB) GetAllRolesForUser(userID uuid.UUID) (*[]models.Role, error) {
 	var userRoles []models.Role
 	err := u.db.Joins("JOIN user_roles ON user_roles.role_id = roles.id").
@@ -62,23 +99,27 @@ func (u *UserDB) GetAllRolesForUser(userID uuid.UUID) (*[]models.Role, error) {
 }
 
 type rolePermissionRow struct {
+	UserRoleID         uuid.UUID                `gorm:"column:user_role_id"`
 	RoleID             uuid.UUID                `gorm:"column:role_id"`
 	RoleName           models.RoleName          `gorm:"column:role_name"`
+	ScopeType          *models.RoleScopeType    `gorm:"column:scope_type"`
+	ScopeID            *uuid.UUID               `gorm:"column:scope_id"`
 	PermissionID       *uuid.UUID               `gorm:"column:permission_id"`
 	PermissionAction   *models.PermissionAction `gorm:"column:permission_action"`
 	PermissionResource *string                  `gorm:"column:permission_resource"`
 }
 
 // GetRolesWithPermissionsForUser returns the roles and permissions for a user using a single join query.
+// A role the user holds in several scopes is returned once per scope.
 func (u *UserDB) GetRolesWithPermissionsForUser(userID uuid.UUID) (*[]role.RoleResponse, error) {
 	var rows []rolePermissionRow
 	err := u.db.Table("user_roles").
-		Select("roles.id as role_id, roles.name as role_name, permissions.id as permission_id, permissions.action as permission_action, permissions.resource as permission_resource").
+		Select("user_roles.id as user_role_id, roles.id as role_id, roles.name as role_name, user_roles.scope_type, user_roles.scope_id, permissions.id as permission_id, permissions.action as permission_action, permissions.resource as permission_resource").
 		Joins("JOIN roles ON roles.id = user_roles.role_id").
 		Joins("LEFT JOIN role_permissions rp ON rp.role_id = roles.id").
 		Joins("LEFT JOIN permissions ON permissions.id = rp.permission_id").
 		Where("user_roles.user_id = ?", userID).
-		Order("roles.name ASC").
+		Order("roles.name ASC, user_roles.scope_type ASC NULLS FIRST, user_roles.scope_id ASC").
 		Scan(&rows).Error
 	if err != nil {
 		return nil, huma.Error500InternalServerError("Failed to get user roles", err)
@@ -92,28 +133,29 @@ func (u *UserDB) GetRolesWithPermissionsForUser(userID uuid.UUID) (*[]role.RoleR
 	permSeen := make(map[uuid.UUID]map[uuid.UUID]struct{}, len(rows))
 
 	for _, row := range rows {
-		r, ok := roleMap[row.RoleID]
+		r, ok := roleMap[row.UserRoleID]
 		if !ok {
 			r = &role.RoleResponse{
 				ID:          row.RoleID,
 				Name:        row.RoleName,
 				Permissions: nil,
+				Scope:       models.UserRole{ScopeType: row.ScopeType, ScopeID: row.ScopeID}.Scope(),
 			}
-			roleMap[row.RoleID] = r
-			roleOrder = append(roleOrder, row.RoleID)
+			roleMap[row.UserRoleID] = r
+			roleOrder = append(roleOrder, row.UserRoleID)
 		}
 
 		if row.PermissionID == nil || row.PermissionAction == nil || row.PermissionResource == nil {
 			continue
 		}
 
-		if _, ok := permSeen[row.RoleID]; !ok {
-			permSeen[row.RoleID] = make(map[uuid.UUID]struct{})
+		if _, ok := permSeen[row.UserRoleID]; !ok {
+			permSeen[row.UserRoleID] = make(map[uuid.UUID]struct{})
 		}
-		if _, ok := permSeen[row.RoleID][*row.PermissionID]; ok {
+		if _, ok := permSeen[row.UserRoleID][*row.PermissionID]; ok {
 			continue
 		}
-		permSeen[row.RoleID][*row.PermissionID] = struct{}{}
+		permSeen[row.UserRoleID][*row.PermissionID] = struct{}{}
 
 		r.Permissions = append(r.Permissions, permission.PermissionResponse{
 			ID:       *row.PermissionID,
@@ -147,16 +189,13 @@ func (u *UserDB) UpdateUser(id uuid.UUID, updates UpdateUserBody) (*models.User,
 	return &updatedUser, nil
 }
 
-func (u *UserDB) HasRole(userID uuid.UUID, roleName models.RoleName) (bool, error) {
-	var count int64
-	err := u.db.Table("user_roles").
-		Joins("JOIN roles ON roles.id = user_roles.role_id").
-		Where("user_roles.user_id = ? AND roles.name = ?", userID, roleName).
-		Count(&count).Error
+// HasRole reports whether the user holds any of roleNames without a scope.
+func (u *UserDB) HasRole(userID uuid.UUID, roleNames ...models.RoleName) (bool, error) {
+	hasRole, err := utils.HasRole(u.db, userID, roleNames...)
 	if err != nil {
 		return false, huma.Error500InternalServerError("Failed to check user role", err)
 	}
-	return count > 0, nil
+	return hasRole, nil
 }
 
 func (u *UserDB) DeleteUser(id uuid.UUID) error {
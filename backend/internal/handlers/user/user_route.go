@@ -2,7 +2,10 @@ package user
 
 import (
 	"inside-athletics/internal/handlers/role"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
 	"inside-athletics/internal/s3"
+	"inside-athletics/internal/utils"
 
 	"github.com/danielgtaylor/huma/v2"
 	"gorm.io/gorm"
@@ -16,17 +19,18 @@ Groups together all of the User routes. Huma is a wrapper here that automaticall
  2. maps the response to the correct response type (if no error 200, 201, etc.) if error it will use the Huma
     error status code
 */
-func Route(api huma.API, db *gorm.DB, s3Svc *s3.Service) {
+func Route(api huma.API, db *gorm.DB, s3Svc *s3.Service, grants *permcache.Cache) {
 	var userDB = NewUserDB(db)
-	var roleDB = role.NewRoleDB(db)                       // create object storing all database level functions for user
-	var userService = &UserService{userDB, roleDB, s3Svc} // create object with user functionality
+	var roleDB = role.NewRoleDB(db)                               // create object storing all database level functions for user
+	var userService = &UserService{userDB, roleDB, s3Svc, grants} // create object with user functionality
 	{
 		grp := huma.NewGroup(api, "/api/v1/user")
 		huma.Get(grp, "/current", userService.GetCurrentUser)
-		huma.Post(grp, "", userService.CreateUser)
+		huma.Post(grp, "", userService.CreateUser, utils.Requires(utils.Authenticated))
 		huma.Get(grp, "/{id}", userService.GetUser)
-		huma.Patch(grp, "", userService.UpdateUser)
-		huma.Delete(grp, "/{id}", userService.DeleteUser)
-		huma.Post(grp, "/{id}/roles", userService.AssignRole)
+		huma.Patch(grp, "", userService.UpdateUser, utils.Requires(utils.Authenticated))
+		huma.Delete(grp, "/{id}", userService.DeleteUser, utils.Requires(utils.Permission(models.PermissionDelete, "user").OwnedBy(utils.Self)))
+		huma.Post(grp, "/{id}/roles", userService.AssignRole, utils.Requires(utils.Permission(models.PermissionCreate, "user")))
+		huma.Delete(grp, "/{id}/roles/{role_id}", userService.RemoveRole, utils.Requires(utils.Permission(models.PermissionDelete, "user")))
 	}
 }
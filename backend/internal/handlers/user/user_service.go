@@ -4,6 +4,7 @@ import (
 	"context"
 	"inside-athletics/internal/handlers/role"
 	models "inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
 	"inside-athletics/internal/s3"
 	"inside-athletics/internal/utils"
 
@@ -15,6 +16,8 @@ type UserService struct {
 	userDB *UserDB
 	roleDB *role.RoleDB
 	s3     *s3.Service
+	// grants are invalidated when a user's roles change
+	grants *permcache.Cache
 }
 
 /*
@@ -50,7 +53,7 @@ func (u *UserService) GetUser(ctx context.Context, input *GetUserParams) (*utils
 func (u *UserService) GetCurrentUser(ctx context.Context, input *utils.EmptyInput) (*utils.ResponseBody[GetUserResponse], error) {
 	respBody := &utils.ResponseBody[GetUserResponse]{}
 
-	userID, err := u.getCurrentUserID(ctx)
+	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return respBody, err
 	}
@@ -73,7 +76,7 @@ func (u *UserService) GetCurrentUser(ctx context.Context, input *utils.EmptyInpu
 func (u *UserService) CreateUser(ctx context.Context, input *CreateUserInput) (*utils.ResponseBody[CreateUserResponse], error) {
 	respBody := &utils.ResponseBody[CreateUserResponse]{}
 
-	currentUserID, err := u.getCurrentUserID(ctx)
+	currentUserID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return respBody, err
 	}
@@ -90,11 +93,8 @@ func (u *UserService) CreateUser(ctx context.Context, input *CreateUserInput) (*
 		Email:                   input.Body.Email,
 		Username:                input.Body.Username,
 		Bio:                     input.Body.Bio,
-		SportID:                 input.Body.SportID,
 		Expected_Grad_Year:      input.Body.ExpectedGradYear,
-		Verified_Athlete_Status: input.Body.VerifiedAthleteStatus,
-		CollegeID:               input.Body.CollegeID,
-		Division:                input.Body.Division,
+		Verified_Athlete_Status: models.VerifiedAthleteStatusNone,
 	}
 
 	createdUser, err := u.userDB.CreateUser(user)
@@ -102,7 +102,7 @@ func (u *UserService) CreateUser(ctx context.Context, input *CreateUserInput) (*
 		return respBody, err
 	}
 
-	if err := u.userDB.AddUserRole(createdUser.ID, roleID); err != nil {
+	if err := u.userDB.AddUserRole(createdUser.ID, roleID, nil); err != nil {
 		return respBody, err
 	}
 
@@ -120,7 +120,7 @@ func (u *UserService) CreateUser(ctx context.Context, input *CreateUserInput) (*
 
 func (u *UserService) UpdateUser(ctx context.Context, input *UpdateUserInput) (*utils.ResponseBody[UpdateUserResponse], error) {
 	respBody := &utils.ResponseBody[UpdateUserResponse]{}
-	currentUserID, err := u.getCurrentUserID(ctx)
+	currentUserID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
 		return respBody, err
 	}
@@ -175,6 +175,7 @@ func (u *UserService) DeleteUser(ctx context.Context, input *GetUserParams) (*ut
 	if err != nil {
 		return respBody, err
 	}
+	u.grants.Invalidate(ctx, input.ID)
 
 	respBody.Body = &DeleteUserResponse{
 		ID: input.ID,
@@ -183,6 +184,8 @@ func (u *UserService) DeleteUser(ctx context.Context, input *GetUserParams) (*ut
 	return respBody, nil
 }
 
+// AssignRole gives a user another role, optionally limited to one sport or college. Their
+// permissions are the union of their roles.
 func (u *UserService) AssignRole(ctx context.Context, input *AssignRoleInput) (*utils.ResponseBody[AssignRoleResponse], error) {
 	if input.Body.RoleID == uuid.Nil {
 		return nil, huma.Error422UnprocessableEntity("role_id cannot be empty")
@@ -198,36 +201,81 @@ func (u *UserService) AssignRole(ctx context.Context, input *AssignRoleInput) (*
 		return nil, err
 	}
 
-	if err := u.userDB.AddUserRole(input.ID, input.Body.RoleID); err != nil {
+	if scope := input.Body.Scope; scope != nil {
+		exists, err := u.userDB.ScopeExists(*scope)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, huma.Error404NotFound("No " + string(scope.Type) + " to scope the role to")
+		}
+	}
+
+	if err := u.userDB.AddUserRole(input.ID, input.Body.RoleID, input.Body.Scope); err != nil {
 		return nil, err
 	}
+	u.grants.Invalidate(ctx, input.ID)
 
 	return &utils.ResponseBody[AssignRoleResponse]{
 		Body: &AssignRoleResponse{
 			UserID: input.ID,
 			Role: UserRoleResponse{
-				ID:   role.ID,
-				Name: role.Name,
+				ID:    role.ID,
+				Name:  role.Name,
+				Scope: input.Body.Scope,
 			},
 		},
 	}, nil
 }
 
-func (u *UserService) getCurrentUserID(ctx context.Context) (uuid.UUID, error) {
-	rawID := ctx.Value("user_id")
-	if rawID == nil {
-		return uuid.Nil, huma.Error401Unauthorized("User not authenticated")
+// RemoveRole takes one of a user's roles away. Nobody can remove their own roles, so admins can't
+// lock themselves out.
+func (u *UserService) RemoveRole(ctx context.Context, input *RemoveRoleInput) (*utils.ResponseBody[RemoveRoleResponse], error) {
+	currentUserID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-
-	userID, ok := rawID.(string)
-	if !ok {
-		return uuid.Nil, huma.Error500InternalServerError("Invalid user ID in context")
+	if input.ID == currentUserID {
+		return nil, huma.Error400BadRequest("You can't remove your own roles")
+	}
+	scope, err := removedScope(input)
+	if err != nil {
+		return nil, err
 	}
 
-	parsedID, err := uuid.Parse(userID)
+	role, err := u.roleDB.GetRoleByID(input.RoleID)
 	if err != nil {
-		return uuid.Nil, huma.Error400BadRequest("Invalid user ID", err)
+		return nil, err
+	}
+	if err := u.userDB.RemoveUserRole(input.ID, input.RoleID, scope); err != nil {
+		return nil, err
 	}
+	u.grants.Invalidate(ctx, input.ID)
+
+	return &utils.ResponseBody[RemoveRoleResponse]{
+		Body: &RemoveRoleResponse{
+			UserID: input.ID,
+			Role: UserRoleResponse{
+				ID:    role.ID,
+				Name:  role.Name,
+				Scope: scope,
+			},
+		},
+	}, nil
+}
 
-	return parsedID, nil
+// removedScope is the scope of the role to remove, or nil for the unscoped one.
+func removedScope(r *RemoveRoleInput) (*models.RoleScope, error) {
+	if r.ScopeType == "" && r.ScopeID == "" {
+		return nil, nil
+	}
+	scopeType := models.RoleScopeType(r.ScopeType)
+	if scopeType != models.RoleScopeSport && scopeType != models.RoleScopeCollege {
+		return nil, huma.Error400BadRequest("scope_type must be sport or college")
+	}
+	id, err := uuid.Parse(r.ScopeID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("Invalid scope_id")
+	}
+	return &models.RoleScope{Type: scopeType, ID: id}, nil
 }
@@ -28,25 +28,24 @@ type GetUserResponse struct {
 }
 
 type UserRoleResponse struct {
-	ID   uuid.UUID       `json:"id" example:"1" doc:"ID of the role"`
-	Name models.RoleName `json:"name" example:"user" doc:"Name of the role"`
+	ID    uuid.UUID         `json:"id" example:"1" doc:"ID of the role"`
+	Name  models.RoleName   `json:"name" example:"user" doc:"Name of the role"`
+	Scope *models.RoleScope `json:"scope,omitempty" doc:"The sport or college the role is limited to, when it is"`
 }
 
 type CreateUserInput struct {
 	Body CreateUserBody
 }
 
+// CreateUserBody is a new user's profile. Athletes are verified, with their sport, college and
+// division, through a verification request instead.
 type CreateUserBody struct {
-	FirstName             string                       `json:"first_name" example:"Suli" doc:"The first name of a user"`
-	LastName              string                       `json:"last_name" example:"Suli" doc:"The last name of a user"`
-	Email                 string                       `json:"email" example:"suli123@email.com" doc:"The email of a user"`
-	Username              string                       `json:"username" example:"suliproathlete" doc:"The username of a user"`
-	Bio                   *string                      `json:"bio,omitempty" example:"My name is Suli and I'm a pro athlete" doc:"The bio of a user"`
-	SportID               *uuid.UUID                   `json:"sport,omitempty" example:"[\"hockey\",\"soccer\"]" doc:"The sport(s) the user is interested in"`
-	ExpectedGradYear      uint                         `json:"expected_grad_year,omitempty" example:"2027" doc:"The user's grad year"`
-	VerifiedAthleteStatus models.VerifiedAthleteStatus `json:"verified_athlete_status" example:"pending" doc:"Verification status for the athlete"`
-	CollegeID             *uuid.UUID                   `json:"college,omitempty" doc:"The college of a user"`
-	Division              *models.Division             `json:"division,omitempty" example:"1" doc:"The division of their college"`
+	FirstName        string  `json:"first_name" example:"Suli" doc:"The first name of a user"`
+	LastName         string  `json:"last_name" example:"Suli" doc:"The last name of a user"`
+	Email            string  `json:"email" example:"suli123@email.com" doc:"The email of a user"`
+	Username         string  `json:"username" example:"suliproathlete" doc:"The username of a user"`
+	Bio              *string `json:"bio,omitempty" example:"My name is Suli and I'm a pro athlete" doc:"The bio of a user"`
+	ExpectedGradYear uint    `json:"expected_grad_year,omitempty" example:"2027" doc:"The user's grad year"`
 }
 
 type CreateUserResponse struct {
@@ -59,17 +58,15 @@ type UpdateUserInput struct {
 	Body UpdateUserBody
 }
 
+// UpdateUserBody is the profile fields users change themselves. The verification status, sport,
+// college and division only change when a verification request is approved.
 type UpdateUserBody struct {
-	FirstName             *string                       `json:"first_name,omitempty" example:"Suli" doc:"The first name of a user"`
-	LastName              *string                       `json:"last_name,omitempty" example:"Suli" doc:"The last name of a user"`
-	Email                 *string                       `json:"email,omitempty" example:"suli123@email.com" doc:"The email of a user"`
-	Username              *string                       `json:"username,omitempty" example:"suliproathlete" doc:"The username of a user"`
-	Bio                   *string                       `json:"bio,omitempty" example:"My name is Suli and I'm a pro athlete" doc:"The bio of a user"`
-	SportID               *uuid.UUID                    `json:"sport,omitempty" example:"[\"hockey\",\"soccer\"]" doc:"The sport(s) the user is interested in" gorm:"type:jsonb;serializer:json"`
-	ExpectedGradYear      *uint                         `json:"expected_grad_year,omitempty" example:"2027" doc:"The user's grad year"`
-	VerifiedAthleteStatus *models.VerifiedAthleteStatus `json:"verified_athlete_status,omitempty" example:"pending" doc:"Verification status for the athlete"`
-	CollegeID             *uuid.UUID                    `json:"college,omitempty" doc:"The college of a user"`
-	Division              *models.Division              `json:"division,omitempty" example:"1" doc:"The division of their college"`
+	FirstName        *string `json:"first_name,omitempty" example:"Suli" doc:"The first name of a user"`
+	LastName         *string `json:"last_name,omitempty" example:"Suli" doc:"The last name of a user"`
+	Email            *string `json:"email,omitempty" example:"suli123@email.com" doc:"The email of a user"`
+	Username         *string `json:"username,omitempty" example:"suliproathlete" doc:"The username of a user"`
+	Bio              *string `json:"bio,omitempty" example:"My name is Suli and I'm a pro athlete" doc:"The bio of a user"`
+	ExpectedGradYear *uint   `json:"expected_grad_year,omitempty" example:"2027" doc:"The user's grad year"`
 }
 
 type UpdateUserResponse = GetUserResponse
@@ -84,10 +81,23 @@ type AssignRoleInput struct {
 }
 
 type AssignRoleRequest struct {
-	RoleID uuid.UUID `json:"role_id" example:"1" doc:"ID of the role to assign"`
+	RoleID uuid.UUID         `json:"role_id" example:"1" doc:"ID of the role to assign"`
+	Scope  *models.RoleScope `json:"scope,omitempty" doc:"Limits the role to one sport or college. Left out, the role applies everywhere"`
 }
 
 type AssignRoleResponse struct {
 	UserID uuid.UUID        `json:"user_id" example:"1" doc:"ID of the user"`
 	Role   UserRoleResponse `json:"role" doc:"Assigned role"`
 }
+
+type RemoveRoleInput struct {
+	ID        uuid.UUID `path:"id" maxLength:"36" example:"1" doc:"ID of the user"`
+	RoleID    uuid.UUID `path:"role_id" maxLength:"36" example:"1" doc:"ID of the role to remove"`
+	ScopeType string    `query:"scope_type" default:"" example:"sport" doc:"sport or college, to remove the role in that scope instead of the unscoped one"`
+	ScopeID   string    `query:"scope_id" default:"" doc:"ID of the sport or college the role is scoped to"`
+}
+
+type RemoveRoleResponse struct {
+	UserID uuid.UUID        `json:"user_id" example:"1" doc:"ID of the user"`
+	Role   UserRoleResponse `json:"role" doc:"Removed role"`
+}
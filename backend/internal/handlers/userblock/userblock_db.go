@@ -0,0 +1,76 @@
+package userblock
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserBlockDB struct {
+	db *gorm.DB
+}
+
+// userBlockKeyset pages a user's mutes and blocks, most recently changed first.
+var userBlockKeyset = utils.Keyset{KeyColumn: "user_blocks.updated_at", IDColumn: "user_blocks.id", Descending: true}
+
+func userBlockCursor(block *models.UserBlock) utils.Cursor[time.Time] {
+	return utils.Cursor[time.Time]{Key: block.UpdatedAt, ID: block.ID}
+}
+
+// NewUserBlockDB creates a new UserBlockDB instance
+func NewUserBlockDB(db *gorm.DB) *UserBlockDB {
+	return &UserBlockDB{db: db}
+}
+
+// UpsertUserBlock mutes or blocks the target, replacing a mute or block already on them
+func (u *UserBlockDB) UpsertUserBlock(block *models.UserBlock) (*models.UserBlock, error) {
+	var count int64
+	if err := u.db.Model(&models.User{}).Where("id = ? AND deleted_at IS NULL", block.TargetID).Count(&count).Error; err != nil {
+		return utils.HandleDBError(block, err)
+	}
+	if count == 0 {
+		return nil, huma.Error404NotFound("User not found")
+	}
+
+	dbResponse := u.db.Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "target_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"type", "updated_at"}),
+		},
+		clause.Returning{},
+	).Create(block)
+	return utils.HandleDBError(block, dbResponse.Error)
+}
+
+// GetUserBlocks returns a page of the user's mutes and blocks, only those of blockType when it is set
+func (u *UserBlockDB) GetUserBlocks(userID uuid.UUID, blockType *models.UserBlockType, page *utils.Page[time.Time]) ([]models.UserBlock, utils.PageCursors, error) {
+	query := u.db.Model(&models.UserBlock{}).Where("user_id = ?", userID)
+	if blockType != nil {
+		query = query.Where("type = ?", *blockType)
+	}
+	var blocks []models.UserBlock
+	if err := page.Apply(query).Find(&blocks).Error; err != nil {
+		_, err = utils.HandleDBError(&blocks, err)
+		return nil, utils.PageCursors{}, err
+	}
+	blocks, cursors := utils.Results(page, blocks, userBlockCursor)
+	return blocks, cursors, nil
+}
+
+// DeleteUserBlock unmutes or unblocks the target
+func (u *UserBlockDB) DeleteUserBlock(userID uuid.UUID, targetID uuid.UUID) error {
+	dbResponse := u.db.Delete(&models.UserBlock{}, "user_id = ? AND target_id = ?", userID, targetID)
+	if dbResponse.Error != nil {
+		_, err := utils.HandleDBError(&models.UserBlock{}, dbResponse.Error)
+		return err
+	}
+	if dbResponse.RowsAffected == 0 {
+		return huma.Error404NotFound("Resource not found")
+	}
+	return nil
+}
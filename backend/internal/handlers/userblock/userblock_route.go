@@ -0,0 +1,19 @@
+package userblock
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
+	"github.com/danielgtaylor/huma/v2"
+	"gorm.io/gorm"
+)
+
+func Route(api huma.API, db *gorm.DB) {
+	userBlockService := NewUserBlockService(db)
+	{
+		grp := huma.NewGroup(api, "/api/v1/user/blocks")
+		huma.Post(grp, "/", userBlockService.CreateUserBlock, utils.Requires(utils.Permission(models.PermissionCreate, "userblock")))               // Mute or block a user
+		huma.Get(grp, "/", userBlockService.GetUserBlocks)                                                                                          // List muted and blocked users
+		huma.Delete(grp, "/{user_id}", userBlockService.DeleteUserBlock, utils.Requires(utils.Permission(models.PermissionDeleteOwn, "userblock"))) // Unmute or unblock a user
+	}
+}
@@ -0,0 +1,80 @@
+package userblock
+
+import (
+	"context"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"gorm.io/gorm"
+)
+
+type UserBlockService struct {
+	userBlockDB *UserBlockDB
+}
+
+// NewUserBlockService creates a new UserBlockService instance
+func NewUserBlockService(db *gorm.DB) *UserBlockService {
+	return &UserBlockService{userBlockDB: NewUserBlockDB(db)}
+}
+
+// CreateUserBlock mutes or blocks a user for the current user. Muting a blocked user turns the
+// block back into a mute.
+func (s *UserBlockService) CreateUserBlock(ctx context.Context, input *CreateUserBlockInput) (*utils.ResponseBody[models.UserBlock], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if input.Body.UserID == userID {
+		return nil, huma.Error400BadRequest("You can't mute or block yourself")
+	}
+
+	block, err := s.userBlockDB.UpsertUserBlock(&models.UserBlock{
+		UserID:   userID,
+		TargetID: input.Body.UserID,
+		Type:     input.Body.Type,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[models.UserBlock]{Body: block}, nil
+}
+
+// GetUserBlocks lists the users the current user muted or blocked
+func (s *UserBlockService) GetUserBlocks(ctx context.Context, input *GetUserBlocksParams) (*utils.ResponseBody[GetUserBlocksResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var blockType *models.UserBlockType
+	if input.Type != "" {
+		t := models.UserBlockType(input.Type)
+		blockType = &t
+	}
+	page, err := utils.NewPage[time.Time](userBlockKeyset, input.Cursor, input.Limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	blocks, cursors, err := s.userBlockDB.GetUserBlocks(userID, blockType, page)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[GetUserBlocksResponse]{
+		Body: &GetUserBlocksResponse{Blocks: blocks, PageCursors: cursors},
+	}, nil
+}
+
+// DeleteUserBlock unmutes or unblocks a user for the current user
+func (s *UserBlockService) DeleteUserBlock(ctx context.Context, input *DeleteUserBlockParams) (*utils.ResponseBody[DeleteUserBlockResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userBlockDB.DeleteUserBlock(userID, input.UserID); err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[DeleteUserBlockResponse]{
+		Body: &DeleteUserBlockResponse{Message: "User was unblocked successfully"},
+	}, nil
+}
@@ -0,0 +1,39 @@
+package userblock
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// CreateUserBlockInput defines the request for muting or blocking a user
+type CreateUserBlockInput struct {
+	Body struct {
+		UserID uuid.UUID            `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"The user to mute or block"`
+		Type   models.UserBlockType `json:"type" enum:"mute,block" example:"mute" doc:"mute hides their posts and comments; block also stops them replying to, liking or mentioning you"`
+	}
+}
+
+// GetUserBlocksParams defines the query parameters for listing the users you muted or blocked
+type GetUserBlocksParams struct {
+	Limit  int    `query:"limit" default:"20" minimum:"1" maximum:"100" example:"20" doc:"Number of users to return"`
+	Cursor string `query:"cursor" default:"" doc:"next_cursor or prev_cursor from a previous page"`
+	Type   string `query:"type" default:"" enum:",mute,block" doc:"Only list mutes or only list blocks"`
+}
+
+// GetUserBlocksResponse defines the response for listing the users you muted or blocked
+type GetUserBlocksResponse struct {
+	Blocks []models.UserBlock `json:"blocks" doc:"Most recent first"`
+	utils.PageCursors
+}
+
+// DeleteUserBlockParams defines the path parameters for unmuting or unblocking a user
+type DeleteUserBlockParams struct {
+	UserID uuid.UUID `path:"user_id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"The user to unmute or unblock"`
+}
+
+// DeleteUserBlockResponse defines the response for unmuting or unblocking a user
+type DeleteUserBlockResponse struct {
+	Message string `json:"message" example:"User was unblocked successfully" doc:"Message to display"`
+}
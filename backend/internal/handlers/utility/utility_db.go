@@ -2,6 +2,7 @@ package utility
 
 import (
 	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -15,21 +16,12 @@ func NewUtilityDB(db *gorm.DB) *UtilityDB {
 	return &UtilityDB{db: db}
 }
 
+// UserHasPremium reports whether the user can read premium content, through a subscription or a
+// staff role.
 func (u *UtilityDB) UserHasPremium(userID uuid.UUID) (bool, error) {
-	var count int64
-	privilegedRoles := []models.RoleName{models.RolePremiumUser, models.RoleAdmin, models.RoleModerator}
-	err := u.db.Table("user_roles").
-		Joins("JOIN roles ON roles.id = user_roles.role_id").
-		Where("user_roles.user_id = ? AND roles.name IN ?", userID, privilegedRoles).
-		Count(&count).Error
-	return count > 0, err
+	return utils.HasRole(u.db, userID, models.PremiumRoles...)
 }
 
 func (u *UtilityDB) UserIsAdmin(userID uuid.UUID) (bool, error) {
-	var count int64
-	err := u.db.Table("user_roles").
-		Joins("JOIN roles ON roles.id = user_roles.role_id").
-		Where("user_roles.user_id = ? AND roles.name = ?", userID, models.RoleAdmin).
-		Count(&count).Error
-	return count > 0, err
+	return utils.HasRole(u.db, userID, models.RoleAdmin)
 }
@@ -5,7 +5,6 @@ import (
 	"inside-athletics/internal/utils"
 
 	"github.com/danielgtaylor/huma/v2"
-	"github.com/google/uuid"
 )
 
 type UtilityService struct {
@@ -13,14 +12,9 @@ type UtilityService struct {
 }
 
 func (s *UtilityService) GetAccessCheck(ctx context.Context, input *utils.EmptyInput) (*utils.ResponseBody[AccessCheckResponse], error) {
-	rawID := ctx.Value("user_id")
-	if rawID == nil {
-		return nil, huma.Error401Unauthorized("User not authenticated")
-	}
-
-	userID, err := uuid.Parse(rawID.(string))
+	userID, err := utils.GetCurrentUserID(ctx)
 	if err != nil {
-		return nil, huma.Error400BadRequest("Invalid user ID", err)
+		return nil, err
 	}
 
 	hasPremium, err := s.utilityDB.UserHasPremium(userID)
@@ -0,0 +1,149 @@
+package verification
+
+import (
+	"errors"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VerificationDB struct {
+	db *gorm.DB
+}
+
+// QueueKeyset pages verification requests oldest first, so the longest waiting are reviewed first.
+var QueueKeyset = utils.Keyset{KeyColumn: "vr.created_at", IDColumn: "vr.id"}
+
+// NewVerificationDB creates a new VerificationDB instance
+func NewVerificationDB(db *gorm.DB) *VerificationDB {
+	return &VerificationDB{db: db}
+}
+
+// TeamDivision returns the division of the team of the sport at the college, which is the
+// college's. It returns gorm.ErrRecordNotFound when either doesn't exist.
+func (v *VerificationDB) TeamDivision(sportID, collegeID uuid.UUID) (models.Division, error) {
+	return teamDivision(v.db, sportID, collegeID)
+}
+
+func teamDivision(db *gorm.DB, sportID, collegeID uuid.UUID) (models.Division, error) {
+	var college models.College
+	err := db.Select("division_rank").
+		Where("EXISTS (SELECT 1 FROM sports WHERE id = ?)", sportID).
+		First(&college, "id = ?", collegeID).Error
+	return college.DivisionRank, err
+}
+
+// HasPendingRequest reports whether the user has a request waiting for review.
+func (v *VerificationDB) HasPendingRequest(userID uuid.UUID) (bool, error) {
+	var count int64
+	err := v.db.Model(&models.VerificationRequest{}).
+		Where("user_id = ? AND status = ?", userID, models.VerificationPending).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreateRequest files a pending request and marks its user as pending verification. Users who are
+// already verified stay verified until their new request is approved or rejected.
+func (v *VerificationDB) CreateRequest(request *models.VerificationRequest) (*models.VerificationRequest, error) {
+	request.Status = models.VerificationPending
+	err := v.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(request).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ? AND verified_athlete_status = ?", request.UserID, models.VerifiedAthleteStatusNone).
+			Update("verified_athlete_status", models.VerifiedAthleteStatusPending).Error
+	})
+	return utils.HandleDBError(request, err)
+}
+
+// GetRequestsByUser returns the user's requests, newest first.
+func (v *VerificationDB) GetRequestsByUser(userID uuid.UUID) ([]models.VerificationRequest, error) {
+	var requests []models.VerificationRequest
+	err := v.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+// GetQueue returns a page of requests with status, with their applicants.
+func (v *VerificationDB) GetQueue(page *utils.Page[time.Time], status models.VerificationStatus) ([]verificationQueueRow, utils.PageCursors, error) {
+	var rows []verificationQueueRow
+	query := v.db.Table("verification_requests AS vr").
+		Select("vr.*, u.first_name, u.last_name, u.email, u.username").
+		Joins("JOIN users u ON u.id = vr.user_id").
+		Where("vr.status = ?", status)
+	if err := page.Apply(query).Find(&rows).Error; err != nil {
+		return nil, utils.PageCursors{}, err
+	}
+	rows, cursors := utils.Results(page, rows, (*verificationQueueRow).cursor)
+	return rows, cursors, nil
+}
+
+// Review approves or rejects a pending request. Approving makes its user a verified athlete of the
+// request's team, in the college's division as it is now, and the user's own validation runs again
+// on the result. Rejecting leaves users who were already verified as they were.
+func (v *VerificationDB) Review(id, reviewerID uuid.UUID, decision ReviewDecision, reason *string) (*models.VerificationRequest, error) {
+	var request models.VerificationRequest
+	err := v.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&request, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if request.UserID == reviewerID {
+			return huma.Error400BadRequest("You can't review your own verification request")
+		}
+		if request.Status != models.VerificationPending {
+			return huma.Error409Conflict("Verification request already reviewed")
+		}
+
+		now := time.Now()
+		request.Status = models.VerificationRejected
+		if decision == DecisionApprove {
+			request.Status = models.VerificationApproved
+		}
+		request.ReviewedAt, request.ReviewedByID, request.ReviewReason = &now, &reviewerID, reason
+		dbResponse := tx.Model(&request).
+			Where("status = ?", models.VerificationPending).
+			Select("status", "reviewed_at", "reviewed_by_id", "review_reason").
+			Updates(&request)
+		if dbResponse.Error != nil {
+			return dbResponse.Error
+		}
+		if dbResponse.RowsAffected == 0 {
+			return huma.Error409Conflict("Verification request already reviewed")
+		}
+
+		var user models.User
+		if err := tx.First(&user, "id = ?", request.UserID).Error; err != nil {
+			return err
+		}
+		if decision == DecisionReject {
+			if user.Verified_Athlete_Status != models.VerifiedAthleteStatusPending {
+				return nil
+			}
+			return tx.Model(&user).Update("verified_athlete_status", models.VerifiedAthleteStatusNone).Error
+		}
+		division, err := teamDivision(tx, request.SportID, request.CollegeID)
+		if err != nil {
+			return err
+		}
+		user.Verified_Athlete_Status = models.VerifiedAthleteStatusVerified
+		user.SportID, user.CollegeID, user.Division = &request.SportID, &request.CollegeID, &division
+		if err := user.BeforeSave(tx); err != nil {
+			return huma.Error422UnprocessableEntity("Approving would leave the user invalid: " + err.Error())
+		}
+		return tx.Model(&user).
+			Select("verified_athlete_status", "sport_id", "college_id", "division").
+			Updates(&user).Error
+	})
+	if err != nil {
+		var statusErr huma.StatusError
+		if errors.As(err, &statusErr) {
+			return nil, err
+		}
+		return utils.HandleDBError(&request, err)
+	}
+	return &request, nil
+}
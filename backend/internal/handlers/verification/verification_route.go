@@ -0,0 +1,26 @@
+package verification
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/s3"
+	"inside-athletics/internal/utils"
+
+	"github.com/danielgtaylor/huma/v2"
+	"gorm.io/gorm"
+)
+
+// Route registers the athlete verification routes. ID documents can only be uploaded when s3Svc
+// is non-nil.
+func Route(api huma.API, db *gorm.DB, s3Svc *s3.Service) {
+	verificationService := NewVerificationService(db, s3Svc)
+	{
+		grp := huma.NewGroup(api, "/api/v1/verification")
+		if s3Svc != nil {
+			huma.Post(grp, "/upload-url", verificationService.GetDocumentUploadURL, utils.Requires(utils.Authenticated)) // Get a URL to upload an ID document to
+		}
+		huma.Post(grp, "/", verificationService.CreateRequest, utils.Requires(utils.Authenticated))                                                  // Ask to be verified as an athlete
+		huma.Get(grp, "/", verificationService.GetRequests)                                                                                          // List your verification requests
+		huma.Get(grp, "/queue", verificationService.GetQueue, utils.Requires(utils.Permission(models.PermissionRead, "verification")))               // List requests for admins to review
+		huma.Post(grp, "/{id}/review", verificationService.ReviewRequest, utils.Requires(utils.Permission(models.PermissionReview, "verification"))) // Approve or reject a request
+	}
+}
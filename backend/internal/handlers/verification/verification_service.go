@@ -0,0 +1,184 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/s3"
+	"inside-athletics/internal/utils"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VerificationService struct {
+	verificationDB *VerificationDB
+	// s3 holds uploaded ID documents; nil when S3 isn't configured, and documents can't be uploaded
+	s3 *s3.Service
+}
+
+// NewVerificationService creates a new VerificationService instance
+func NewVerificationService(db *gorm.DB, s3Svc *s3.Service) *VerificationService {
+	return &VerificationService{verificationDB: NewVerificationDB(db), s3: s3Svc}
+}
+
+// GetDocumentUploadURL returns a presigned URL to upload an ID document to, and the key to send
+// with the verification request.
+func (v *VerificationService) GetDocumentUploadURL(ctx context.Context, input *GetDocumentUploadURLInput) (*utils.ResponseBody[s3.GetUploadURLResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := s3.VerificationPrefix(userID) + uuid.NewString() + "/" + path.Base(input.Body.FileName)
+	resp, err := v.s3.GetUploadURL(ctx, s3.GetUploadURLInput{
+		Key:      key,
+		FileType: input.Body.FileType,
+		FileName: input.Body.FileName,
+	})
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Unable to create upload URL", err)
+	}
+	return &utils.ResponseBody[s3.GetUploadURLResponse]{Body: resp}, nil
+}
+
+// CreateRequest asks for the current user to be verified as an athlete of a team. It needs at
+// least one piece of evidence, and the user can't have another request waiting for review.
+func (v *VerificationService) CreateRequest(ctx context.Context, input *CreateVerificationRequestInput) (*utils.ResponseBody[models.VerificationRequest], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body := input.Body
+	if body.RosterURL == nil && body.TeamEmail == nil && body.DocumentKey == nil {
+		return nil, huma.Error422UnprocessableEntity("Include a roster_url, team_email or document_key as evidence")
+	}
+	if err := v.checkDocument(ctx, userID, body.DocumentKey); err != nil {
+		return nil, err
+	}
+
+	division, err := v.verificationDB.TeamDivision(body.SportID, body.CollegeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, huma.Error404NotFound("Sport or college not found")
+	}
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Unable to check the sport and college", err)
+	}
+	pending, err := v.verificationDB.HasPendingRequest(userID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Unable to check your verification requests", err)
+	}
+	if pending {
+		return nil, huma.Error409Conflict("You already have a verification request waiting for review")
+	}
+
+	request, err := v.verificationDB.CreateRequest(&models.VerificationRequest{
+		UserID:      userID,
+		SportID:     body.SportID,
+		CollegeID:   body.CollegeID,
+		Division:    division,
+		RosterURL:   body.RosterURL,
+		TeamEmail:   body.TeamEmail,
+		DocumentKey: body.DocumentKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[models.VerificationRequest]{Body: request}, nil
+}
+
+// checkDocument checks a document key is one of the user's and was uploaded.
+func (v *VerificationService) checkDocument(ctx context.Context, userID uuid.UUID, key *string) error {
+	if key == nil {
+		return nil
+	}
+	if v.s3 == nil {
+		return huma.Error400BadRequest("Documents can't be uploaded right now; send a roster_url or team_email instead")
+	}
+	if !strings.HasPrefix(*key, s3.VerificationPrefix(userID)) {
+		return huma.Error400BadRequest("document_key must be from /verification/upload-url")
+	}
+	if _, err := v.s3.ConfirmUpload(ctx, *key); err != nil {
+		return huma.Error400BadRequest("The document hasn't been uploaded", err)
+	}
+	return nil
+}
+
+// GetRequests lists the current user's verification requests, newest first, so they can see why
+// one was rejected.
+func (v *VerificationService) GetRequests(ctx context.Context, input *utils.EmptyInput) (*utils.ResponseBody[GetVerificationRequestsResponse], error) {
+	userID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := v.verificationDB.GetRequestsByUser(userID)
+	if err != nil {
+		_, err = utils.HandleDBError(&requests, err)
+		return nil, err
+	}
+	return &utils.ResponseBody[GetVerificationRequestsResponse]{
+		Body: &GetVerificationRequestsResponse{Requests: requests},
+	}, nil
+}
+
+// GetQueue lists verification requests for admins to review, oldest first, with a link to each
+// uploaded document.
+func (v *VerificationService) GetQueue(ctx context.Context, input *GetVerificationQueueParams) (*utils.ResponseBody[GetVerificationQueueResponse], error) {
+	page, err := utils.NewPage[time.Time](QueueKeyset, input.Cursor, input.Limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	rows, cursors, err := v.verificationDB.GetQueue(page, input.Status)
+	if err != nil {
+		_, err = utils.HandleDBError(&rows, err)
+		return nil, err
+	}
+
+	items := make([]VerificationQueueItem, 0, len(rows))
+	for _, row := range rows {
+		item := VerificationQueueItem{
+			VerificationRequest: row.VerificationRequest,
+			Applicant: VerificationApplicant{
+				ID:        row.UserID,
+				FirstName: row.FirstName,
+				LastName:  row.LastName,
+				Email:     row.Email,
+				Username:  row.Username,
+			},
+		}
+		if row.DocumentKey != nil {
+			if url := s3.ResolveKey(ctx, v.s3, *row.DocumentKey); url != "" {
+				item.DocumentURL = &url
+			}
+		}
+		items = append(items, item)
+	}
+	return &utils.ResponseBody[GetVerificationQueueResponse]{
+		Body: &GetVerificationQueueResponse{Requests: items, PageCursors: cursors},
+	}, nil
+}
+
+// ReviewRequest approves or rejects a verification request. Rejections need a reason, which the
+// athlete sees, and nobody can review their own request.
+func (v *VerificationService) ReviewRequest(ctx context.Context, input *ReviewVerificationRequestInput) (*utils.ResponseBody[models.VerificationRequest], error) {
+	reviewerID, err := utils.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reason := input.Body.Reason
+	if reason != nil && strings.TrimSpace(*reason) == "" {
+		reason = nil
+	}
+	if input.Body.Decision == DecisionReject && reason == nil {
+		return nil, huma.Error422UnprocessableEntity("A reason is required to reject a request")
+	}
+
+	request, err := v.verificationDB.Review(input.ID, reviewerID, input.Body.Decision, reason)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.ResponseBody[models.VerificationRequest]{Body: request}, nil
+}
@@ -0,0 +1,92 @@
+package verification
+
+import (
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewDecision is what an admin decides about a verification request
+type ReviewDecision string
+
+const (
+	DecisionApprove ReviewDecision = "approve"
+	DecisionReject  ReviewDecision = "reject"
+)
+
+// GetDocumentUploadURLInput defines the request for a presigned URL to upload an ID document to
+type GetDocumentUploadURLInput struct {
+	Body struct {
+		FileType string `json:"file_type" enum:"image/jpeg,image/png,application/pdf" example:"image/jpeg" doc:"MIME type of the document"`
+		FileName string `json:"file_name" minLength:"1" maxLength:"100" example:"student-id.jpg"`
+	}
+}
+
+// CreateVerificationRequestInput defines the request for asking to be verified as an athlete
+type CreateVerificationRequestInput struct {
+	Body struct {
+		SportID     uuid.UUID `json:"sport_id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"The sport the athlete plays"`
+		CollegeID   uuid.UUID `json:"college_id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"The college the athlete plays for"`
+		RosterURL   *string   `json:"roster_url,omitempty" format:"uri" maxLength:"500" example:"https://nuhuskies.com/sports/womens-soccer/roster" doc:"The team's roster with the athlete on it"`
+		TeamEmail   *string   `json:"team_email,omitempty" format:"email" maxLength:"100" example:"suli@northeastern.edu" doc:"An email address from the athlete's college or team"`
+		DocumentKey *string   `json:"document_key,omitempty" maxLength:"500" doc:"key from /verification/upload-url of an uploaded ID document"`
+	}
+}
+
+// GetVerificationQueueParams defines the query parameters for the review queue
+type GetVerificationQueueParams struct {
+	Limit  int                       `query:"limit" default:"20" minimum:"1" maximum:"100" example:"20" doc:"Number of requests to return"`
+	Cursor string                    `query:"cursor" default:"" doc:"next_cursor or prev_cursor from a previous page"`
+	Status models.VerificationStatus `query:"status" default:"pending" enum:"pending,approved,rejected" doc:"Which requests to list"`
+}
+
+// VerificationApplicant is the user asking to be verified
+type VerificationApplicant struct {
+	ID        uuid.UUID `json:"id"`
+	FirstName string    `json:"first_name" example:"Suli"`
+	LastName  string    `json:"last_name" example:"Test"`
+	Email     string    `json:"email" example:"suli123@email.com"`
+	Username  string    `json:"username" example:"suliproathelete"`
+}
+
+// VerificationQueueItem is a verification request as admins review it
+type VerificationQueueItem struct {
+	models.VerificationRequest
+	Applicant   VerificationApplicant `json:"applicant"`
+	DocumentURL *string               `json:"document_url,omitempty" doc:"Presigned download URL for the ID document"`
+}
+
+// GetVerificationQueueResponse defines the response for the review queue
+type GetVerificationQueueResponse struct {
+	Requests []VerificationQueueItem `json:"requests" doc:"Oldest first"`
+	utils.PageCursors
+}
+
+// GetVerificationRequestsResponse defines the response for listing the current user's requests
+type GetVerificationRequestsResponse struct {
+	Requests []models.VerificationRequest `json:"requests" doc:"Newest first"`
+}
+
+// ReviewVerificationRequestInput defines the request for approving or rejecting a request
+type ReviewVerificationRequestInput struct {
+	ID   uuid.UUID `path:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Body struct {
+		Decision ReviewDecision `json:"decision" enum:"approve,reject" example:"reject"`
+		Reason   *string        `json:"reason,omitempty" maxLength:"1000" example:"The roster doesn't list this athlete" doc:"Shown to the athlete. Required to reject a request"`
+	}
+}
+
+// verificationQueueRow is a request joined with its applicant
+type verificationQueueRow struct {
+	models.VerificationRequest
+	FirstName string
+	LastName  string
+	Email     string
+	Username  string
+}
+
+func (r *verificationQueueRow) cursor() utils.Cursor[time.Time] {
+	return utils.Cursor[time.Time]{Key: r.CreatedAt, ID: r.ID}
+}
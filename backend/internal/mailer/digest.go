@@ -0,0 +1,185 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inside-athletics/internal/models"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// digestBatchSize is how many due users are claimed at a time.
+const digestBatchSize = 100
+
+// maxDigestErrors is how many errors SendDigests returns in full; any more are only counted.
+const maxDigestErrors = 10
+
+// SendDigests emails every user who is due a digest the most popular new posts in the tags,
+// sports and colleges they follow, and returns how many digests were sent. Users with nothing new
+// aren't emailed but still wait a full DigestPeriod before they're checked again. Digests that
+// fail to send are released once the run is over, and the run stops early when a whole batch
+// fails, since mail is likely down.
+func (m *Mailer) SendDigests(ctx context.Context) (int, error) {
+	// anyone who follows something can get a digest, so make sure they have preferences
+	if err := m.db.WithContext(ctx).Exec(`
+		INSERT INTO email_preferences (user_id, created_at, updated_at)
+		SELECT f.user_id, NOW(), NOW()
+		FROM (
+			SELECT user_id FROM tag_follows
+			UNION SELECT user_id FROM sport_follows
+			UNION SELECT user_id FROM college_follows
+		) f
+		ON CONFLICT DO NOTHING`).Error; err != nil {
+		return 0, err
+	}
+
+	sent, failures := 0, 0
+	var errs []error
+	record := func(err error) {
+		failures++
+		if len(errs) < maxDigestErrors {
+			errs = append(errs, err)
+		}
+	}
+
+	// failed digests are held until the end, so the run doesn't claim them again
+	var failed []dueDigest
+	for {
+		due, err := m.claimDigests(ctx)
+		if err != nil {
+			record(err)
+			break
+		}
+		batchFailures := 0
+		for _, d := range due {
+			ok, err := m.sendDigest(ctx, d)
+			if err != nil {
+				record(err)
+				failed = append(failed, d)
+				batchFailures++
+			} else if ok {
+				sent++
+			}
+		}
+		if len(due) < digestBatchSize || batchFailures == len(due) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	releaseCtx := context.WithoutCancel(ctx)
+	for _, d := range failed {
+		if err := m.release(releaseCtx, d.UserID, "last_digest_at", d.Previous); err != nil {
+			record(err)
+		}
+	}
+	if failures > len(errs) {
+		errs = append(errs, fmt.Errorf("and %d more digest errors", failures-len(errs)))
+	}
+	return sent, errors.Join(errs...)
+}
+
+type dueDigest struct {
+	UserID           uuid.UUID
+	UnsubscribeToken string
+	Previous         *time.Time
+}
+
+// claimDigests claims up to digestBatchSize users whose last digest was at least DigestPeriod ago.
+// Rows locked by another instance are skipped, so each digest is sent once.
+func (m *Mailer) claimDigests(ctx context.Context) ([]dueDigest, error) {
+	var due []dueDigest
+	err := m.db.WithContext(ctx).Raw(`
+		WITH due AS (
+			SELECT user_id, last_digest_at
+			FROM email_preferences
+			WHERE digest AND (last_digest_at IS NULL OR last_digest_at <= ?)
+				AND user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)
+			ORDER BY user_id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE email_preferences ep
+		SET last_digest_at = NOW()
+		FROM due
+		WHERE ep.user_id = due.user_id
+		RETURNING ep.user_id, ep.unsubscribe_token, due.last_digest_at AS previous`,
+		time.Now().Add(-m.cfg.DigestPeriod), digestBatchSize).
+		Scan(&due).Error
+	return due, err
+}
+
+// sendDigest sends d's digest if there is anything new to put in it.
+func (m *Mailer) sendDigest(ctx context.Context, d dueDigest) (bool, error) {
+	var user models.User
+	if err := m.db.WithContext(ctx).First(&user, "id = ?", d.UserID).Error; err != nil {
+		return false, err
+	}
+
+	since := time.Now().Add(-m.cfg.DigestPeriod)
+	if d.Previous != nil && d.Previous.After(since) {
+		since = *d.Previous
+	}
+	posts, err := m.posts.GetDigestPosts(d.UserID, since, m.cfg.DigestSize)
+	if err != nil || len(posts) == 0 {
+		return false, err
+	}
+
+	digest := Digest{Posts: make([]DigestPost, len(posts))}
+	for i, p := range posts {
+		digest.Posts[i] = DigestPost{
+			Title:        p.Title,
+			Excerpt:      excerpt(p.Content, 200),
+			URL:          m.postURL(p.ID),
+			LikeCount:    p.LikeCount,
+			CommentCount: p.CommentCount,
+		}
+	}
+	email, err := DigestEmail(m.recipient(user, d.UnsubscribeToken, models.EmailListDigest), digest)
+	if err != nil {
+		return false, err
+	}
+	if err := m.sender.Send(ctx, email); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DigestJob periodically sends digests to users who are due one.
+type DigestJob struct {
+	mailer   *Mailer
+	interval time.Duration
+}
+
+func NewDigestJob(m *Mailer) *DigestJob {
+	interval := m.cfg.DigestInterval
+	if interval <= 0 {
+		interval = DefaultDigestInterval
+	}
+	return &DigestJob{mailer: m, interval: interval}
+}
+
+// Run sends due digests right away and then every interval until ctx is done. Digests that fail
+// to send are logged and retried on the next tick.
+func (j *DigestJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := j.mailer.SendDigests(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Failed to send some digests", "error", err)
+		}
+		if sent > 0 {
+			slog.InfoContext(ctx, "Sent digests", "count", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
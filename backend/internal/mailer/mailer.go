@@ -0,0 +1,203 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inside-athletics/internal/events"
+	"inside-athletics/internal/handlers/email"
+	"inside-athletics/internal/handlers/post"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// replyAlertQueueSize is how many reply alerts can wait to be sent before new ones are dropped.
+const replyAlertQueueSize = 1000
+
+// Mailer renders and sends every email the platform sends. Receipts always go out; digests and
+// reply alerts respect the recipient's email_preferences.
+type Mailer struct {
+	db     *gorm.DB
+	sender Sender
+	cfg    Config
+	posts  *post.PostDB
+	// replyAlerts are the replies waiting for RunReplyAlerts to email the parent's author about
+	replyAlerts chan replyAlert
+}
+
+type replyAlert struct {
+	ParentID uuid.UUID
+	ReplyID  uuid.UUID
+}
+
+func New(db *gorm.DB, sender Sender, cfg Config) *Mailer {
+	return &Mailer{db: db, sender: sender, cfg: cfg, posts: post.NewPostDB(db), replyAlerts: make(chan replyAlert, replyAlertQueueSize)}
+}
+
+// NewFromEnv creates a Mailer from LoadConfigFromEnv. If the configured sender can't be created
+// mail is logged instead, so a bad mail config never stops the server.
+func NewFromEnv(db *gorm.DB) *Mailer {
+	cfg := LoadConfigFromEnv()
+	sender, err := NewSender(cfg)
+	if err != nil {
+		slog.Error("Failed to create mail sender, logging mail instead", "error", err)
+		sender = NewLogSender()
+	}
+	return New(db, sender, cfg)
+}
+
+// Subscribe registers the handler that emails users when someone replies to their comment. The
+// handler only queues the alert, so a slow mail server doesn't hold up creating the comment;
+// RunReplyAlerts sends them.
+func (m *Mailer) Subscribe(bus *events.Bus) {
+	events.Subscribe(bus, func(ctx context.Context, e events.CommentCreated) error {
+		if e.ParentCommentID == nil {
+			return nil
+		}
+		select {
+		case m.replyAlerts <- replyAlert{ParentID: *e.ParentCommentID, ReplyID: e.CommentID}:
+			return nil
+		default:
+			return errors.New("reply alert queue is full, dropping the alert")
+		}
+	})
+}
+
+// RunReplyAlerts sends the reply alerts Subscribe queues until ctx is done. Alerts that fail to
+// send are logged, and alerts still queued when ctx is done are dropped.
+func (m *Mailer) RunReplyAlerts(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case alert := <-m.replyAlerts:
+			if err := m.SendReplyAlert(ctx, alert.ParentID, alert.ReplyID); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "Failed to send reply alert", "error", err, "comment_id", alert.ReplyID)
+			}
+		}
+	}
+}
+
+// SendReceipt emails user a receipt for a subscription payment. A nil Mailer sends nothing.
+func (m *Mailer) SendReceipt(ctx context.Context, user models.User, r Receipt) error {
+	if m == nil {
+		return nil
+	}
+	email, err := ReceiptEmail(Recipient{Email: user.Email, FirstName: user.FirstName}, r)
+	if err != nil {
+		return err
+	}
+	return m.sender.Send(ctx, email)
+}
+
+// SendReplyAlert emails the author of parentID about the reply replyID, unless they replied to
+// themselves, muted the replier, turned reply alerts off or were sent one less than
+// ReplyAlertCooldown ago.
+func (m *Mailer) SendReplyAlert(ctx context.Context, parentID uuid.UUID, replyID uuid.UUID) error {
+	var parent, reply models.Comment
+	if err := m.db.WithContext(ctx).Preload("User").First(&parent, "id = ?", parentID).Error; err != nil {
+		return err
+	}
+	if err := m.db.WithContext(ctx).Preload("User").Preload("Post").First(&reply, "id = ?", replyID).Error; err != nil {
+		return err
+	}
+	if parent.UserID == reply.UserID || parent.User.DeletedAt != nil {
+		return nil
+	}
+	// replies from shadow-banned users are only shown to them
+	if banned, err := utils.IsShadowBanned(m.db.WithContext(ctx), reply.UserID); err != nil || banned {
+		return err
+	}
+	if muted, err := utils.HasMuted(m.db.WithContext(ctx), parent.UserID, reply.UserID); err != nil || muted {
+		return err
+	}
+
+	token, previous, ok, err := m.claim(ctx, parent.UserID, "last_reply_alert_at", "reply_alerts", m.cfg.ReplyAlertCooldown)
+	if err != nil || !ok {
+		return err
+	}
+
+	replier := "Someone"
+	if !reply.IsAnonymous {
+		replier = reply.User.Username
+	}
+	email, err := ReplyAlertEmail(m.recipient(parent.User, token, models.EmailListReplyAlerts), ReplyAlert{
+		ReplierName: replier,
+		Excerpt:     excerpt(reply.Description, 280),
+		PostTitle:   reply.Post.Title,
+		PostURL:     m.postURL(reply.PostID),
+	})
+	if err == nil {
+		err = m.sender.Send(ctx, email)
+	}
+	if err != nil {
+		return errors.Join(err, m.release(ctx, parent.UserID, "last_reply_alert_at", previous))
+	}
+	return nil
+}
+
+// ensurePreferences creates default email preferences for userIDs that don't have any yet.
+func (m *Mailer) ensurePreferences(ctx context.Context, userIDs ...uuid.UUID) error {
+	prefs := make([]models.EmailPreference, len(userIDs))
+	for i, id := range userIDs {
+		prefs[i] = models.EmailPreference{UserID: id}
+	}
+	return m.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&prefs).Error
+}
+
+// claim marks an optional email as sent to userID by setting column to now, if list is enabled
+// and column is older than cooldown. It returns the user's unsubscribe token and column's old value
+// so the claim can be released if sending fails. Claiming first means two instances can't both send.
+func (m *Mailer) claim(ctx context.Context, userID uuid.UUID, column string, list string, cooldown time.Duration) (string, *time.Time, bool, error) {
+	if err := m.ensurePreferences(ctx, userID); err != nil {
+		return "", nil, false, err
+	}
+
+	var claimed struct {
+		UnsubscribeToken string
+		Previous         *time.Time
+	}
+	result := m.db.WithContext(ctx).Raw(fmt.Sprintf(`
+		WITH old AS (
+			SELECT user_id, %[1]s AS previous FROM email_preferences WHERE user_id = ? FOR UPDATE
+		)
+		UPDATE email_preferences ep
+		SET %[1]s = NOW()
+		FROM old
+		WHERE ep.user_id = old.user_id AND ep.%[2]s AND (old.previous IS NULL OR old.previous <= ?)
+		RETURNING ep.unsubscribe_token, old.previous`, column, list),
+		userID, time.Now().Add(-cooldown)).
+		Scan(&claimed)
+	if result.Error != nil {
+		return "", nil, false, result.Error
+	}
+	return claimed.UnsubscribeToken, claimed.Previous, result.RowsAffected > 0, nil
+}
+
+// release undoes a claim whose email couldn't be sent.
+func (m *Mailer) release(ctx context.Context, userID uuid.UUID, column string, previous *time.Time) error {
+	return m.db.WithContext(ctx).Model(&models.EmailPreference{}).
+		Where("user_id = ?", userID).
+		UpdateColumn(column, previous).Error
+}
+
+func (m *Mailer) recipient(user models.User, token string, list models.EmailList) Recipient {
+	return Recipient{Email: user.Email, FirstName: user.FirstName, UnsubscribeURL: m.UnsubscribeURL(token, list)}
+}
+
+// UnsubscribeURL is the link that takes the holder of token off list without signing in.
+func (m *Mailer) UnsubscribeURL(token string, list models.EmailList) string {
+	query := url.Values{"token": {token}, "list": {string(list)}}
+	return m.cfg.APIBaseURL + email.UnsubscribePath + "?" + query.Encode()
+}
+
+func (m *Mailer) postURL(postID uuid.UUID) string {
+	return m.cfg.AppBaseURL + "/posts/" + postID.String()
+}
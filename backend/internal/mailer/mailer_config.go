@@ -0,0 +1,129 @@
+package mailer
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Env keys for mail config. Durations are in seconds.
+const (
+	EnvSender                = "MAIL_SENDER"
+	EnvFrom                  = "MAIL_FROM"
+	EnvFileDir               = "MAIL_FILE_DIR"
+	EnvSMTPHost              = "SMTP_HOST"
+	EnvSMTPPort              = "SMTP_PORT"
+	EnvSMTPUsername          = "SMTP_USERNAME"
+	EnvSMTPPassword          = "SMTP_PASSWORD"
+	EnvAPIBaseURL            = "API_BASE_URL"
+	EnvAppBaseURL            = "APP_BASE_URL"
+	EnvDigestPeriodSec       = "DIGEST_PERIOD_SECONDS"
+	EnvDigestIntervalSec     = "DIGEST_CHECK_INTERVAL_SECONDS"
+	EnvDigestSize            = "DIGEST_SIZE"
+	EnvReplyAlertCooldownSec = "REPLY_ALERT_COOLDOWN_SECONDS"
+)
+
+// Sender kinds accepted in MAIL_SENDER.
+const (
+	SenderSMTP = "smtp"
+	SenderFile = "file"
+	SenderLog  = "log"
+)
+
+const (
+	DefaultSender             = SenderLog
+	DefaultFrom               = "Inside Athletics <no-reply@insideathletics.app>"
+	DefaultFileDir            = "mail"
+	DefaultSMTPPort           = 587
+	DefaultAPIBaseURL         = "http://localhost:8080"
+	DefaultAppBaseURL         = "http://localhost:3000"
+	DefaultDigestPeriod       = 7 * 24 * time.Hour
+	DefaultDigestInterval     = time.Hour
+	DefaultDigestSize         = 5
+	DefaultReplyAlertCooldown = time.Hour
+)
+
+// Config controls how email is sent and how often optional email goes out.
+type Config struct {
+	// Sender is which Sender delivers mail: SenderSMTP, SenderFile or SenderLog.
+	Sender string
+	// From is the From header of every email.
+	From string
+	// FileDir is where SenderFile writes .eml files.
+	FileDir string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+
+	// APIBaseURL is where unsubscribe links point. AppBaseURL is where links to posts point.
+	APIBaseURL string
+	AppBaseURL string
+
+	// DigestPeriod is how long a user waits between digests. DigestInterval is how often the digest
+	// job looks for users who are due one.
+	DigestPeriod   time.Duration
+	DigestInterval time.Duration
+	// DigestSize is the most posts one digest lists.
+	DigestSize int
+	// ReplyAlertCooldown is the least time between two reply alerts to the same user. Replies
+	// in between are left to in-app notifications.
+	ReplyAlertCooldown time.Duration
+}
+
+// DefaultConfig returns the config used when nothing is set in env.
+func DefaultConfig() Config {
+	return Config{
+		Sender:             DefaultSender,
+		From:               DefaultFrom,
+		FileDir:            DefaultFileDir,
+		SMTPPort:           DefaultSMTPPort,
+		APIBaseURL:         DefaultAPIBaseURL,
+		AppBaseURL:         DefaultAppBaseURL,
+		DigestPeriod:       DefaultDigestPeriod,
+		DigestInterval:     DefaultDigestInterval,
+		DigestSize:         DefaultDigestSize,
+		ReplyAlertCooldown: DefaultReplyAlertCooldown,
+	}
+}
+
+// LoadConfigFromEnv returns the mail config from env, falling back to DefaultConfig for anything unset or invalid.
+func LoadConfigFromEnv() Config {
+	return Config{
+		Sender:             stringFromEnv(EnvSender, DefaultSender),
+		From:               stringFromEnv(EnvFrom, DefaultFrom),
+		FileDir:            stringFromEnv(EnvFileDir, DefaultFileDir),
+		SMTPHost:           os.Getenv(EnvSMTPHost),
+		SMTPPort:           intFromEnv(EnvSMTPPort, DefaultSMTPPort),
+		SMTPUsername:       os.Getenv(EnvSMTPUsername),
+		SMTPPassword:       os.Getenv(EnvSMTPPassword),
+		APIBaseURL:         strings.TrimRight(stringFromEnv(EnvAPIBaseURL, DefaultAPIBaseURL), "/"),
+		AppBaseURL:         strings.TrimRight(stringFromEnv(EnvAppBaseURL, DefaultAppBaseURL), "/"),
+		DigestPeriod:       durationFromEnv(EnvDigestPeriodSec, DefaultDigestPeriod),
+		DigestInterval:     durationFromEnv(EnvDigestIntervalSec, DefaultDigestInterval),
+		DigestSize:         intFromEnv(EnvDigestSize, DefaultDigestSize),
+		ReplyAlertCooldown: durationFromEnv(EnvReplyAlertCooldownSec, DefaultReplyAlertCooldown),
+	}
+}
+
+func stringFromEnv(key string, fallback string) string {
+	if s := os.Getenv(key); s != "" {
+		return s
+	}
+	return fallback
+}
+
+func intFromEnv(key string, fallback int) int {
+	if s := os.Getenv(key); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			return n
+		}
+	}
+	return fallback
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	return time.Duration(intFromEnv(key, int(fallback.Seconds()))) * time.Second
+}
@@ -0,0 +1,223 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Email is one rendered email to one recipient.
+type Email struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// Headers are extra headers such as List-Unsubscribe.
+	Headers map[string]string
+}
+
+// Sender delivers rendered emails.
+type Sender interface {
+	Send(ctx context.Context, email Email) error
+}
+
+// NewSender returns the Sender cfg.Sender names.
+func NewSender(cfg Config) (Sender, error) {
+	switch cfg.Sender {
+	case SenderSMTP:
+		return NewSMTPSender(cfg)
+	case SenderFile:
+		return NewFileSender(cfg.From, cfg.FileDir), nil
+	case SenderLog:
+		return NewLogSender(), nil
+	default:
+		return nil, fmt.Errorf("unknown mail sender %q", cfg.Sender)
+	}
+}
+
+// Message returns email as a multipart/alternative MIME message from from.
+func (e Email) Message(from string) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", e.Text},
+		{"text/html; charset=utf-8", e.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{
+		"From":         from,
+		"To":           e.To,
+		"Subject":      mime.QEncoding.Encode("utf-8", e.Subject),
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"MIME-Version": "1.0",
+		"Content-Type": "multipart/alternative; boundary=" + strconv.Quote(parts.Boundary()),
+	}
+	for k, v := range e.Headers {
+		headers[k] = v
+	}
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var msg bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&msg, "%s: %s\r\n", k, headers[k])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// SMTPSender sends email through an SMTP server, upgrading to TLS when the server offers it.
+type SMTPSender struct {
+	from string
+	host string
+	addr string
+	auth smtp.Auth
+}
+
+func NewSMTPSender(cfg Config) (*SMTPSender, error) {
+	if cfg.SMTPHost == "" {
+		return nil, errors.New("SMTP_HOST is required to send mail over SMTP")
+	}
+	s := &SMTPSender{
+		from: cfg.From,
+		host: cfg.SMTPHost,
+		addr: net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+	}
+	if cfg.SMTPUsername != "" {
+		s.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return s, nil
+}
+
+func (s *SMTPSender) Send(ctx context.Context, email Email) error {
+	from, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	msg, err := email.Message(s.from)
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if s.auth != nil {
+		if err := client.Auth(s.auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(email.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// FileSender writes each email to its own .eml file in a directory instead of sending it, for
+// local development.
+type FileSender struct {
+	from string
+	dir  string
+}
+
+func NewFileSender(from string, dir string) *FileSender {
+	return &FileSender{from: from, dir: dir}
+}
+
+func (s *FileSender) Send(_ context.Context, email Email) error {
+	msg, err := email.Message(s.from)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+	return os.WriteFile(filepath.Join(s.dir, name), msg, 0o644)
+}
+
+// LogSender logs emails instead of sending them. It's the default so nothing is emailed unless
+// a real sender is configured.
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(ctx context.Context, email Email) error {
+	slog.InfoContext(ctx, "Email not sent, MAIL_SENDER is log", "to", email.To, "subject", email.Subject, "text", email.Text)
+	return nil
+}
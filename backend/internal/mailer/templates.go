@@ -0,0 +1,146 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+const (
+	templateReceipt = "receipt"
+	templateReply   = "reply"
+	templateDigest  = "digest"
+)
+
+// emailTemplate is the HTML and text version of one kind of email, each wrapped in the shared layout.
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var templates = mustParseTemplates(templateReceipt, templateReply, templateDigest)
+
+func mustParseTemplates(names ...string) map[string]emailTemplate {
+	parsed := make(map[string]emailTemplate, len(names))
+	for _, name := range names {
+		html := htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html"))
+		text := texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/layout.txt", "templates/"+name+".txt"))
+		parsed[name] = emailTemplate{html: html, text: text}
+	}
+	return parsed
+}
+
+// Recipient is who an email is for. Emails with an UnsubscribeURL are optional: they link to it in
+// the footer and in a List-Unsubscribe header.
+type Recipient struct {
+	Email          string
+	FirstName      string
+	UnsubscribeURL string
+}
+
+// Receipt is a successful subscription payment. Amount is in the currency's smallest unit.
+type Receipt struct {
+	Amount     int64
+	Currency   string
+	Number     string
+	InvoiceURL string
+}
+
+// ReplyAlert is a reply to one of the recipient's comments.
+type ReplyAlert struct {
+	ReplierName string
+	Excerpt     string
+	PostTitle   string
+	PostURL     string
+}
+
+// Digest is the popular posts the recipient hasn't been emailed about yet.
+type Digest struct {
+	Posts []DigestPost
+}
+
+type DigestPost struct {
+	Title        string
+	Excerpt      string
+	URL          string
+	LikeCount    int64
+	CommentCount int64
+}
+
+func ReceiptEmail(to Recipient, r Receipt) (Email, error) {
+	amount := formatAmount(r.Amount, r.Currency)
+	return render(templateReceipt, to, "Your Inside Athletics receipt", struct {
+		Recipient  Recipient
+		Amount     string
+		Number     string
+		InvoiceURL string
+	}{to, amount, r.Number, r.InvoiceURL})
+}
+
+func ReplyAlertEmail(to Recipient, a ReplyAlert) (Email, error) {
+	return render(templateReply, to, a.ReplierName+" replied to your comment", struct {
+		Recipient Recipient
+		ReplyAlert
+	}{to, a})
+}
+
+func DigestEmail(to Recipient, d Digest) (Email, error) {
+	if len(d.Posts) == 0 {
+		return Email{}, errors.New("a digest needs at least one post")
+	}
+	subject := "Popular this week: " + d.Posts[0].Title
+	return render(templateDigest, to, subject, struct {
+		Recipient Recipient
+		Digest
+	}{to, d})
+}
+
+func render(name string, to Recipient, subject string, data any) (Email, error) {
+	tmpl := templates[name]
+	var html, text bytes.Buffer
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return Email{}, fmt.Errorf("failed to render %s email: %w", name, err)
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return Email{}, fmt.Errorf("failed to render %s email: %w", name, err)
+	}
+
+	email := Email{To: to.Email, Subject: subject, HTML: html.String(), Text: text.String()}
+	if to.UnsubscribeURL != "" {
+		email.Headers = map[string]string{
+			"List-Unsubscribe":      "<" + to.UnsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
+	}
+	return email, nil
+}
+
+// formatAmount formats an amount in a currency's smallest unit, e.g. 999 usd as $9.99.
+func formatAmount(amount int64, currency string) string {
+	value := fmt.Sprintf("%d.%02d", amount/100, amount%100)
+	if strings.EqualFold(currency, "usd") {
+		return "$" + value
+	}
+	return value + " " + strings.ToUpper(currency)
+}
+
+// excerpt shortens s to at most n runes, cutting at a word boundary where it can.
+func excerpt(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	cut := string(runes[:n])
+	if i := strings.LastIndex(cut, " "); i > n/2 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
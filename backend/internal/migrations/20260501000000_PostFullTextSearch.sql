@@ -0,0 +1,80 @@
+-- Full-text search over post and premium post titles, content and tag names.
+ALTER TABLE "public"."posts" ADD COLUMN "search_vector" tsvector NULL;
+ALTER TABLE "public"."premium_posts" ADD COLUMN "search_vector" tsvector NULL;
+
+-- Builds a post's search document: title (weight A), tag names (B) and content (C).
+CREATE OR REPLACE FUNCTION post_search_vector(p_id uuid, p_type text, p_title text, p_content text)
+RETURNS tsvector LANGUAGE sql STABLE AS $$
+  SELECT setweight(to_tsvector('english', coalesce(p_title, '')), 'A') ||
+         setweight(to_tsvector('english', coalesce((
+           SELECT string_agg(t.name, ' ')
+           FROM tag_posts tp
+           JOIN tags t ON t.id = tp.tag_id
+           WHERE tp.postable_id = p_id AND tp.postable_type = p_type AND tp.deleted_at IS NULL
+         ), '')), 'B') ||
+         setweight(to_tsvector('english', coalesce(p_content, '')), 'C')
+$$;
+
+-- Keeps search_vector in sync when a post is written. TG_ARGV[0] is the tag_posts postable_type.
+CREATE OR REPLACE FUNCTION posts_search_vector_trigger() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  NEW.search_vector := post_search_vector(NEW.id, TG_ARGV[0], NEW.title, NEW.content);
+  RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER posts_search_vector_update
+  BEFORE INSERT OR UPDATE OF title, content ON "public"."posts"
+  FOR EACH ROW EXECUTE FUNCTION posts_search_vector_trigger('post');
+
+CREATE TRIGGER premium_posts_search_vector_update
+  BEFORE INSERT OR UPDATE OF title, content ON "public"."premium_posts"
+  FOR EACH ROW EXECUTE FUNCTION posts_search_vector_trigger('premium_post');
+
+-- Re-indexes a single post or premium post after its tags change.
+CREATE OR REPLACE FUNCTION refresh_post_search_vector(p_id uuid, p_type text) RETURNS void LANGUAGE plpgsql AS $$
+BEGIN
+  IF p_type = 'post' THEN
+    UPDATE posts SET search_vector = post_search_vector(id, p_type, title, content) WHERE id = p_id;
+  ELSIF p_type = 'premium_post' THEN
+    UPDATE premium_posts SET search_vector = post_search_vector(id, p_type, title, content) WHERE id = p_id;
+  END IF;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION tag_posts_search_vector_trigger() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  IF TG_OP IN ('UPDATE', 'DELETE') THEN
+    PERFORM refresh_post_search_vector(OLD.postable_id, OLD.postable_type);
+  END IF;
+  IF TG_OP IN ('INSERT', 'UPDATE') THEN
+    PERFORM refresh_post_search_vector(NEW.postable_id, NEW.postable_type);
+  END IF;
+  RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER tag_posts_search_vector_update
+  AFTER INSERT OR UPDATE OR DELETE ON "public"."tag_posts"
+  FOR EACH ROW EXECUTE FUNCTION tag_posts_search_vector_trigger();
+
+-- Renaming a tag re-indexes every post it is attached to.
+CREATE OR REPLACE FUNCTION tags_search_vector_trigger() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  PERFORM refresh_post_search_vector(tp.postable_id, tp.postable_type)
+  FROM tag_posts tp
+  WHERE tp.tag_id = NEW.id;
+  RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER tags_search_vector_update
+  AFTER UPDATE OF name ON "public"."tags"
+  FOR EACH ROW EXECUTE FUNCTION tags_search_vector_trigger();
+
+-- Backfill existing rows
+UPDATE "public"."posts" SET search_vector = post_search_vector(id, 'post', title, content);
+UPDATE "public"."premium_posts" SET search_vector = post_search_vector(id, 'premium_post', title, content);
+
+CREATE INDEX "idx_posts_search_vector" ON "public"."posts" USING gin ("search_vector");
+CREATE INDEX "idx_premium_posts_search_vector" ON "public"."premium_posts" USING gin ("search_vector");
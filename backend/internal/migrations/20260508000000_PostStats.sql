@@ -0,0 +1,33 @@
+-- Precomputed per-post engagement, maintained by the like/comment services and the trending job.
+CREATE TABLE "public"."post_stats" (
+  "post_id" uuid NOT NULL,
+  "like_count" bigint NOT NULL DEFAULT 0,
+  "comment_count" bigint NOT NULL DEFAULT 0,
+  "trending_score" double precision NOT NULL DEFAULT 0,
+  "scored_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("post_id"),
+  CONSTRAINT "fk_post_stats_post" FOREIGN KEY ("post_id") REFERENCES "public"."posts" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+
+-- Backfill with the default weights (like 3.0, comment 8.0) and half-life (24 hours). The trending
+-- job re-bases scores on the configured values on its first run.
+INSERT INTO "public"."post_stats" ("post_id", "like_count", "comment_count", "trending_score", "scored_at", "updated_at")
+SELECT posts.id,
+  COALESCE(likes.total, 0),
+  COALESCE(comments.total, 0),
+  COALESCE(likes.trending, 0) * 3.0 + COALESCE(comments.trending, 0) * 8.0,
+  now(),
+  now()
+FROM "public"."posts" AS posts
+LEFT JOIN (
+  SELECT post_id, COUNT(*) AS total, SUM(POWER(0.5, LEAST(EXTRACT(EPOCH FROM (now() - created_at))::float8 / 86400, 64))) AS trending
+  FROM "public"."post_likes"
+  GROUP BY post_id
+) AS likes ON likes.post_id = posts.id
+LEFT JOIN (
+  SELECT post_id, COUNT(*) AS total, SUM(POWER(0.5, LEAST(EXTRACT(EPOCH FROM (now() - created_at))::float8 / 86400, 64))) AS trending
+  FROM "public"."comments"
+  GROUP BY post_id
+) AS comments ON comments.post_id = posts.id
+WHERE posts.deleted_at IS NULL;
@@ -0,0 +1,9 @@
+-- Like and reply counters on comments, maintained by the comment and comment_like DBs.
+ALTER TABLE "public"."comments"
+  ADD COLUMN "like_count" bigint NOT NULL DEFAULT 0,
+  ADD COLUMN "reply_count" bigint NOT NULL DEFAULT 0;
+
+-- Backfill from the source tables.
+UPDATE "public"."comments" SET
+  "like_count" = (SELECT COUNT(*) FROM "public"."comment_likes" WHERE comment_likes.comment_id = comments.id),
+  "reply_count" = (SELECT COUNT(*) FROM "public"."comments" AS replies WHERE replies.parent_comment_id = comments.id AND replies.deleted_at IS NULL);
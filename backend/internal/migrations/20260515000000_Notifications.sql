@@ -0,0 +1,38 @@
+-- Create "notifications" table
+CREATE TABLE "public"."notifications" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  "user_id" uuid NOT NULL,
+  "type" character varying(32) NOT NULL,
+  "subject_id" uuid NOT NULL,
+  "post_id" uuid NULL,
+  "actor_id" uuid NULL,
+  "actor_count" bigint NOT NULL DEFAULT 1,
+  "read_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_notifications_actor" FOREIGN KEY ("actor_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE SET NULL,
+  CONSTRAINT "fk_notifications_post" FOREIGN KEY ("post_id") REFERENCES "public"."posts" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "fk_notifications_user" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "idx_notifications_unread_group" to table: "notifications"
+CREATE UNIQUE INDEX "idx_notifications_unread_group" ON "public"."notifications" ("user_id", "type", "subject_id") WHERE (read_at IS NULL);
+-- Create index "idx_notifications_user_updated" to table: "notifications"
+CREATE INDEX "idx_notifications_user_updated" ON "public"."notifications" ("user_id", "updated_at" DESC);
+-- Create "notification_actors" table
+CREATE TABLE "public"."notification_actors" (
+  "notification_id" uuid NOT NULL,
+  "actor_id" uuid NOT NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("notification_id", "actor_id"),
+  CONSTRAINT "fk_notification_actors_actor" FOREIGN KEY ("actor_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "fk_notification_actors_notification" FOREIGN KEY ("notification_id") REFERENCES "public"."notifications" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create "notification_preferences" table
+CREATE TABLE "public"."notification_preferences" (
+  "user_id" uuid NOT NULL,
+  "type" character varying(32) NOT NULL,
+  "enabled" boolean NOT NULL,
+  PRIMARY KEY ("user_id", "type"),
+  CONSTRAINT "fk_notification_preferences_user" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
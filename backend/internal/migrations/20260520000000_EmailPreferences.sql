@@ -0,0 +1,15 @@
+-- Create "email_preferences" table
+CREATE TABLE "public"."email_preferences" (
+  "user_id" uuid NOT NULL,
+  "unsubscribe_token" character varying(64) NOT NULL DEFAULT replace(((gen_random_uuid())::text || (gen_random_uuid())::text), '-'::text, ''::text),
+  "digest" boolean NOT NULL DEFAULT true,
+  "reply_alerts" boolean NOT NULL DEFAULT true,
+  "last_digest_at" timestamptz NULL,
+  "last_reply_alert_at" timestamptz NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("user_id"),
+  CONSTRAINT "fk_email_preferences_user" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "idx_email_preferences_unsubscribe_token" to table: "email_preferences"
+CREATE UNIQUE INDEX "idx_email_preferences_unsubscribe_token" ON "public"."email_preferences" ("unsubscribe_token");
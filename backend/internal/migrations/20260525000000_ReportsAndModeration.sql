@@ -0,0 +1,73 @@
+-- Modify "users" table
+ALTER TABLE "public"."users" ADD COLUMN "suspended_until" timestamptz NULL;
+-- Modify "posts" table
+ALTER TABLE "public"."posts" ADD COLUMN "hidden_at" timestamptz NULL;
+-- Modify "premium_posts" table
+ALTER TABLE "public"."premium_posts" ADD COLUMN "hidden_at" timestamptz NULL;
+-- Modify "comments" table
+ALTER TABLE "public"."comments" ADD COLUMN "hidden_at" timestamptz NULL;
+-- Create "reports" table
+CREATE TABLE "public"."reports" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  "reporter_id" uuid NOT NULL,
+  "content_type" character varying(20) NOT NULL,
+  "content_id" uuid NOT NULL,
+  "reason" character varying(32) NOT NULL,
+  "details" character varying(1000) NULL,
+  "status" character varying(20) NOT NULL DEFAULT 'open',
+  "resolved_at" timestamptz NULL,
+  "resolved_by_id" uuid NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_reports_reporter" FOREIGN KEY ("reporter_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "fk_reports_resolved_by" FOREIGN KEY ("resolved_by_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE SET NULL
+);
+-- Create index "idx_reports_content" to table: "reports"
+CREATE INDEX "idx_reports_content" ON "public"."reports" ("content_type", "content_id", "status");
+-- Create index "idx_reports_reporter_content" to table: "reports"
+CREATE UNIQUE INDEX "idx_reports_reporter_content" ON "public"."reports" ("reporter_id", "content_type", "content_id");
+-- Create "moderation_actions" table
+CREATE TABLE "public"."moderation_actions" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "created_at" timestamptz NULL,
+  "moderator_id" uuid NULL,
+  "action" character varying(20) NOT NULL,
+  "content_type" character varying(20) NOT NULL,
+  "content_id" uuid NOT NULL,
+  "target_user_id" uuid NULL,
+  "note" character varying(1000) NULL,
+  "suspended_until" timestamptz NULL,
+  "reports_resolved" bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_moderation_actions_moderator" FOREIGN KEY ("moderator_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE SET NULL,
+  CONSTRAINT "fk_moderation_actions_target_user" FOREIGN KEY ("target_user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE SET NULL
+);
+-- Create index "idx_moderation_actions_target_created" to table: "moderation_actions"
+CREATE INDEX "idx_moderation_actions_target_created" ON "public"."moderation_actions" ("target_user_id", "created_at" DESC);
+
+-- Seed permissions for reports and moderation
+INSERT INTO "public"."permissions" ("action", "resource") VALUES
+  ('create', 'report'),
+  ('review', 'report'),
+  ('dismiss', 'report'),
+  ('remove', 'report'),
+  ('warn', 'report'),
+  ('suspend', 'report')
+ON CONFLICT DO NOTHING;
+
+-- Anyone can report content
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'report' AND p."action" = 'create'
+WHERE r."name" IN ('user', 'premium_user')
+ON CONFLICT DO NOTHING;
+
+-- Moderators and admins can also work the moderation queue
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'report'
+WHERE r."name" IN ('moderator', 'admin')
+ON CONFLICT DO NOTHING;
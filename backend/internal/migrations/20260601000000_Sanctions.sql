@@ -0,0 +1,44 @@
+-- Create "sanctions" table
+CREATE TABLE "public"."sanctions" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  "user_id" uuid NOT NULL,
+  "type" character varying(20) NOT NULL,
+  "reason" character varying(1000) NOT NULL,
+  "expires_at" timestamptz NULL,
+  "issued_by_id" uuid NULL,
+  "lifted_at" timestamptz NULL,
+  "lifted_by_id" uuid NULL,
+  "lift_reason" character varying(1000) NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_sanctions_user" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "fk_sanctions_issued_by" FOREIGN KEY ("issued_by_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE SET NULL,
+  CONSTRAINT "fk_sanctions_lifted_by" FOREIGN KEY ("lifted_by_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE SET NULL
+);
+-- Create index "idx_sanctions_user_type" to table: "sanctions"
+CREATE INDEX "idx_sanctions_user_type" ON "public"."sanctions" ("user_id", "type");
+
+-- Carry over suspensions made from the moderation queue
+INSERT INTO "public"."sanctions" ("created_at", "updated_at", "user_id", "type", "reason", "expires_at")
+SELECT NOW(), NOW(), "id", 'suspend', 'Suspended from the moderation queue', "suspended_until"
+FROM "public"."users"
+WHERE "suspended_until" > NOW();
+
+-- Modify "users" table
+ALTER TABLE "public"."users" DROP COLUMN "suspended_until";
+
+-- Seed permissions for sanctions
+INSERT INTO "public"."permissions" ("action", "resource") VALUES
+  ('create', 'sanction'),
+  ('read', 'sanction'),
+  ('delete', 'sanction')
+ON CONFLICT DO NOTHING;
+
+-- Only admins apply, read and lift sanctions
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'sanction'
+WHERE r."name" = 'admin'
+ON CONFLICT DO NOTHING;
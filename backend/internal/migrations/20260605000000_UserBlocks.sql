@@ -0,0 +1,36 @@
+-- Create "user_blocks" table
+CREATE TABLE "public"."user_blocks" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  "user_id" uuid NOT NULL,
+  "target_id" uuid NOT NULL,
+  "type" character varying(10) NOT NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_user_blocks_user" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "fk_user_blocks_target" FOREIGN KEY ("target_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "idx_user_blocks_user_target" to table: "user_blocks"
+CREATE UNIQUE INDEX "idx_user_blocks_user_target" ON "public"."user_blocks" ("user_id", "target_id");
+
+-- Seed permissions for blocks
+INSERT INTO "public"."permissions" ("action", "resource") VALUES
+  ('create', 'userblock'),
+  ('delete', 'userblock'),
+  ('delete_own', 'userblock')
+ON CONFLICT DO NOTHING;
+
+-- Every user can mute, block and undo their own
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'userblock' AND p."action" IN ('create', 'delete_own')
+WHERE r."name" IN ('user', 'premium_user', 'moderator')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'userblock'
+WHERE r."name" = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,50 @@
+-- Create "audit_logs" table
+CREATE TABLE "public"."audit_logs" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "created_at" timestamptz NOT NULL,
+  "actor_id" uuid NULL,
+  "action" character varying(20) NOT NULL,
+  "method" character varying(10) NOT NULL,
+  "path" character varying(500) NOT NULL,
+  "resource_type" character varying(50) NOT NULL,
+  "resource_id" uuid NULL,
+  "changes" jsonb NOT NULL DEFAULT '{}',
+  "status" bigint NOT NULL,
+  "request_id" character varying(100) NOT NULL,
+  "ip" character varying(64) NOT NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_audit_logs_created_at" to table: "audit_logs"
+CREATE INDEX "idx_audit_logs_created_at" ON "public"."audit_logs" ("created_at");
+-- Create index "idx_audit_logs_actor_id" to table: "audit_logs"
+CREATE INDEX "idx_audit_logs_actor_id" ON "public"."audit_logs" ("actor_id");
+-- Create index "idx_audit_logs_resource" to table: "audit_logs"
+CREATE INDEX "idx_audit_logs_resource" ON "public"."audit_logs" ("resource_type", "resource_id");
+
+-- The audit log is append-only: rows are never updated, and only the retention job, which turns
+-- on inside_athletics.audit_retention for its transaction, deletes them
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  IF TG_OP = 'DELETE' AND current_setting('inside_athletics.audit_retention', true) = 'on' THEN
+    RETURN OLD;
+  END IF;
+  RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$;
+
+CREATE TRIGGER audit_logs_append_only
+  BEFORE UPDATE OR DELETE ON audit_logs
+  FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+-- Seed permissions for the audit log
+INSERT INTO "public"."permissions" ("action", "resource") VALUES
+  ('read', 'audit')
+ON CONFLICT DO NOTHING;
+
+-- Only admins read the audit log
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'audit'
+WHERE r."name" = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,24 @@
+-- Users can hold any number of roles, so drop the one-role-per-user constraint
+ALTER TABLE "public"."user_roles" DROP CONSTRAINT IF EXISTS "uni_user_roles_user_id";
+
+-- Give each assignment its own ID, so the same role can be held in several scopes
+ALTER TABLE "public"."user_roles" DROP CONSTRAINT "user_roles_pkey";
+ALTER TABLE "public"."user_roles"
+  ADD COLUMN "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  ADD COLUMN "scope_type" character varying(10) NULL,
+  ADD COLUMN "scope_id" uuid NULL,
+  ADD PRIMARY KEY ("id");
+
+-- Create index "idx_user_roles_unscoped" to table: "user_roles"
+CREATE UNIQUE INDEX "idx_user_roles_unscoped" ON "public"."user_roles" ("user_id", "role_id") WHERE (scope_id IS NULL);
+-- Create index "idx_user_roles_scoped" to table: "user_roles"
+CREATE UNIQUE INDEX "idx_user_roles_scoped" ON "public"."user_roles" ("user_id", "role_id", "scope_type", "scope_id") WHERE (scope_id IS NOT NULL);
+
+-- Subscribing used to swap the user role for premium_user. Give subscribers the user role back, so
+-- premium_user only adds to it and cancelling just takes premium_user away
+INSERT INTO "public"."user_roles" ("user_id", "role_id")
+SELECT ur."user_id", r."id"
+FROM "public"."user_roles" ur
+JOIN "public"."roles" premium ON premium."id" = ur."role_id" AND premium."name" = 'premium_user'
+JOIN "public"."roles" r ON r."name" = 'user'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,31 @@
+-- Seed permissions for surveys and media, which are now checked like every other resource
+INSERT INTO "public"."permissions" ("action", "resource") VALUES
+  ('create', 'survey'),
+  ('delete', 'survey'),
+  ('delete_own', 'survey'),
+  ('create', 'media'),
+  ('delete', 'media')
+ON CONFLICT DO NOTHING;
+
+-- Every user can submit surveys and delete their own
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'survey' AND p."action" IN ('create', 'delete_own')
+WHERE r."name" IN ('user', 'premium_user', 'moderator')
+ON CONFLICT DO NOTHING;
+
+-- Media is attached to premium posts, which staff write
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'media'
+WHERE r."name" = 'moderator'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" IN ('survey', 'media')
+WHERE r."name" = 'admin'
+ON CONFLICT DO NOTHING;
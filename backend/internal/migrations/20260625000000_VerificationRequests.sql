@@ -0,0 +1,40 @@
+-- Create "verification_requests" table
+CREATE TABLE "public"."verification_requests" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  "user_id" uuid NOT NULL,
+  "sport_id" uuid NOT NULL,
+  "college_id" uuid NOT NULL,
+  "division" bigint NOT NULL,
+  "roster_url" character varying(500) NULL,
+  "team_email" character varying(100) NULL,
+  "document_key" character varying(500) NULL,
+  "status" character varying(10) NOT NULL,
+  "reviewed_at" timestamptz NULL,
+  "reviewed_by_id" uuid NULL,
+  "review_reason" character varying(1000) NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_verification_requests_user" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "fk_verification_requests_sport" FOREIGN KEY ("sport_id") REFERENCES "public"."sports" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "fk_verification_requests_college" FOREIGN KEY ("college_id") REFERENCES "public"."colleges" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "fk_verification_requests_reviewed_by" FOREIGN KEY ("reviewed_by_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE SET NULL
+);
+-- Create index "idx_verification_requests_pending" to table: "verification_requests"
+CREATE UNIQUE INDEX "idx_verification_requests_pending" ON "public"."verification_requests" ("user_id") WHERE ((status)::text = 'pending'::text);
+-- Create index "idx_verification_requests_status" to table: "verification_requests"
+CREATE INDEX "idx_verification_requests_status" ON "public"."verification_requests" ("status");
+
+-- Seed permissions for verification requests
+INSERT INTO "public"."permissions" ("action", "resource") VALUES
+  ('read', 'verification'),
+  ('review', 'verification')
+ON CONFLICT DO NOTHING;
+
+-- Only admins see the review queue and approve or reject requests
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'verification'
+WHERE r."name" = 'admin'
+ON CONFLICT DO NOTHING;
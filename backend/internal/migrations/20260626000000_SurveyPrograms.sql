@@ -0,0 +1,30 @@
+-- Modify "surveys" table
+ALTER TABLE "public"."surveys" ADD COLUMN "invalidated_at" timestamptz NULL, ADD COLUMN "invalidated_by_id" uuid NULL, ADD COLUMN "invalidation_reason" character varying(1000) NULL, ADD CONSTRAINT "fk_surveys_invalidated_by" FOREIGN KEY ("invalidated_by_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE SET NULL;
+-- Create index "idx_surveys_invalidated_at" to table: "surveys"
+CREATE INDEX "idx_surveys_invalidated_at" ON "public"."surveys" ("invalidated_at");
+
+-- Keep one response per user and program: the latest one, preferring responses that weren't deleted
+DELETE FROM "public"."surveys" WHERE "id" IN (
+  SELECT "id" FROM (
+    SELECT "id", ROW_NUMBER() OVER (
+      PARTITION BY "user_id", "college_id", "sport_id"
+      ORDER BY "deleted_at" IS NULL DESC, "updated_at" DESC NULLS LAST, "id"
+    ) AS "n"
+    FROM "public"."surveys"
+  ) "ranked"
+  WHERE "n" > 1
+);
+-- Create index "idx_surveys_program" to table: "surveys"
+CREATE UNIQUE INDEX "idx_surveys_program" ON "public"."surveys" ("user_id", "college_id", "sport_id");
+
+-- Seed the permission to invalidate survey responses, which only admins have
+INSERT INTO "public"."permissions" ("action", "resource") VALUES
+  ('invalidate', 'survey')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'survey' AND p."action" = 'invalidate'
+WHERE r."name" = 'admin'
+ON CONFLICT DO NOTHING;
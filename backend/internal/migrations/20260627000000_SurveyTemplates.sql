@@ -0,0 +1,116 @@
+-- Create "survey_templates" table
+CREATE TABLE "public"."survey_templates" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  "name" character varying(100) NOT NULL,
+  "description" character varying(1000) NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_survey_templates_name" to table: "survey_templates"
+CREATE UNIQUE INDEX "idx_survey_templates_name" ON "public"."survey_templates" ("name");
+-- Create "survey_template_versions" table
+CREATE TABLE "public"."survey_template_versions" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "created_at" timestamptz NULL,
+  "template_id" uuid NOT NULL,
+  "version" bigint NOT NULL,
+  "created_by_id" uuid NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_survey_template_versions_created_by" FOREIGN KEY ("created_by_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE SET NULL,
+  CONSTRAINT "fk_survey_templates_versions" FOREIGN KEY ("template_id") REFERENCES "public"."survey_templates" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "idx_survey_template_versions_number" to table: "survey_template_versions"
+CREATE UNIQUE INDEX "idx_survey_template_versions_number" ON "public"."survey_template_versions" ("template_id", "version");
+-- Create "survey_questions" table
+CREATE TABLE "public"."survey_questions" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "version_id" uuid NOT NULL,
+  "key" character varying(50) NOT NULL,
+  "position" bigint NOT NULL,
+  "type" character varying(20) NOT NULL,
+  "prompt" character varying(500) NOT NULL,
+  "required" boolean NOT NULL DEFAULT true,
+  "options" jsonb NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_survey_template_versions_questions" FOREIGN KEY ("version_id") REFERENCES "public"."survey_template_versions" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "idx_survey_questions_version_key" to table: "survey_questions"
+CREATE UNIQUE INDEX "idx_survey_questions_version_key" ON "public"."survey_questions" ("version_id", "key");
+-- Create index "idx_survey_questions_key" to table: "survey_questions"
+CREATE INDEX "idx_survey_questions_key" ON "public"."survey_questions" ("key");
+-- Create "survey_answers" table
+CREATE TABLE "public"."survey_answers" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "survey_id" uuid NOT NULL,
+  "question_id" uuid NOT NULL,
+  "scale" smallint NULL,
+  "choice" character varying(200) NULL,
+  "text" character varying(2000) NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_survey_answers_question" FOREIGN KEY ("question_id") REFERENCES "public"."survey_questions" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_surveys_answers" FOREIGN KEY ("survey_id") REFERENCES "public"."surveys" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "idx_survey_answers_question" to table: "survey_answers"
+CREATE UNIQUE INDEX "idx_survey_answers_question" ON "public"."survey_answers" ("survey_id", "question_id");
+-- Create index "idx_survey_answers_question_id" to table: "survey_answers"
+CREATE INDEX "idx_survey_answers_question_id" ON "public"."survey_answers" ("question_id");
+
+-- The seven ratings surveys had until now become version 1 of the default template, with the
+-- columns' names as the questions' keys
+INSERT INTO "public"."survey_templates" ("created_at", "updated_at", "name", "description") VALUES
+  (now(), now(), 'Athlete Experience', 'How athletes rate their program');
+INSERT INTO "public"."survey_template_versions" ("created_at", "template_id", "version")
+SELECT now(), t."id", 1 FROM "public"."survey_templates" t WHERE t."name" = 'Athlete Experience';
+INSERT INTO "public"."survey_questions" ("version_id", "key", "position", "type", "prompt", "required")
+SELECT v."id", q."key", q."position", 'scale', q."prompt", true
+FROM "public"."survey_template_versions" v
+JOIN "public"."survey_templates" t ON t."id" = v."template_id" AND t."name" = 'Athlete Experience'
+CROSS JOIN (VALUES
+  ('player_dev', 1, 'How well does the program develop its players?'),
+  ('academics_athletics_priority', 2, 'How well does the program balance academics and athletics?'),
+  ('academic_career_resources', 3, 'How good are the academic and career resources available to athletes?'),
+  ('mental_health_priority', 4, 'How much does the program prioritize mental health?'),
+  ('environment', 5, 'How good is the team environment?'),
+  ('culture', 6, 'How good is the team culture?'),
+  ('transparency', 7, 'How transparent are the coaches and staff?')
+) AS q("key", "position", "prompt")
+WHERE v."version" = 1;
+
+-- Modify "surveys" table
+ALTER TABLE "public"."surveys" ADD COLUMN "template_version_id" uuid NULL;
+UPDATE "public"."surveys" SET "template_version_id" = (
+  SELECT v."id" FROM "public"."survey_template_versions" v
+  JOIN "public"."survey_templates" t ON t."id" = v."template_id"
+  WHERE t."name" = 'Athlete Experience' AND v."version" = 1
+);
+-- Move every survey's ratings into answers to the default template's questions
+INSERT INTO "public"."survey_answers" ("survey_id", "question_id", "scale")
+SELECT s."id", q."id", CASE q."key"
+  WHEN 'player_dev' THEN s."player_dev"
+  WHEN 'academics_athletics_priority' THEN s."academics_athletics_priority"
+  WHEN 'academic_career_resources' THEN s."academic_career_resources"
+  WHEN 'mental_health_priority' THEN s."mental_health_priority"
+  WHEN 'environment' THEN s."environment"
+  WHEN 'culture' THEN s."culture"
+  WHEN 'transparency' THEN s."transparency"
+END
+FROM "public"."surveys" s
+JOIN "public"."survey_questions" q ON q."version_id" = s."template_version_id";
+-- Modify "surveys" table
+ALTER TABLE "public"."surveys" DROP COLUMN "player_dev", DROP COLUMN "academics_athletics_priority", DROP COLUMN "academic_career_resources", DROP COLUMN "mental_health_priority", DROP COLUMN "environment", DROP COLUMN "culture", DROP COLUMN "transparency", ALTER COLUMN "template_version_id" SET NOT NULL, ADD CONSTRAINT "fk_surveys_template_version" FOREIGN KEY ("template_version_id") REFERENCES "public"."survey_template_versions" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION;
+-- Create index "idx_surveys_template_version_id" to table: "surveys"
+CREATE INDEX "idx_surveys_template_version_id" ON "public"."surveys" ("template_version_id");
+
+-- Seed the permissions to define survey templates and publish new versions, which only admins have
+INSERT INTO "public"."permissions" ("action", "resource") VALUES
+  ('create', 'survey_template'),
+  ('update', 'survey_template')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'survey_template'
+WHERE r."name" = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,39 @@
+-- Seed permissions for tags and the platform's Stripe products, prices and customers
+INSERT INTO "public"."permissions" ("action", "resource") VALUES
+  ('create', 'tag'),
+  ('update', 'tag'),
+  ('delete', 'tag'),
+  ('create', 'stripe_product'),
+  ('update', 'stripe_product'),
+  ('delete', 'stripe_product'),
+  ('create', 'stripe_price'),
+  ('update', 'stripe_price'),
+  ('delete', 'stripe_price'),
+  ('update', 'stripe_customer'),
+  ('delete', 'stripe_customer'),
+  ('update_own', 'stripe_customer'),
+  ('delete_own', 'stripe_customer')
+ON CONFLICT DO NOTHING;
+
+-- Every user can manage the Stripe customer they were given at checkout
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'stripe_customer' AND p."action" IN ('update_own', 'delete_own')
+WHERE r."name" IN ('user', 'premium_user', 'moderator')
+ON CONFLICT DO NOTHING;
+
+-- Moderators curate tags
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'tag'
+WHERE r."name" = 'moderator'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" IN ('tag', 'stripe_product', 'stripe_price', 'stripe_customer')
+WHERE r."name" = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,20 @@
+-- Seed permissions for deleting uploaded S3 content
+INSERT INTO "public"."permissions" ("action", "resource") VALUES
+  ('delete', 'content'),
+  ('delete_own', 'content')
+ON CONFLICT DO NOTHING;
+
+-- Every user can delete what they uploaded
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'content' AND p."action" = 'delete_own'
+WHERE r."name" IN ('user', 'premium_user', 'moderator')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT r."id", p."id"
+FROM "public"."roles" r
+JOIN "public"."permissions" p ON p."resource" = 'content'
+WHERE r."name" = 'admin'
+ON CONFLICT DO NOTHING;
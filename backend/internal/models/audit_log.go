@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog is one privileged or destructive change made through the API. Rows are never updated
+// and are only deleted once they are older than the retention period.
+type AuditLog struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null;index"`
+
+	// no foreign key -> entries outlive the users they mention
+	ActorID *uuid.UUID `json:"actor_id,omitempty" gorm:"type:uuid;index"`
+
+	Action string `json:"action" example:"update" doc:"create, update or delete, or what a moderator did" gorm:"type:varchar(20);not null"`
+	Method string `json:"method" example:"PATCH" gorm:"type:varchar(10);not null"`
+	Path   string `json:"path" example:"/api/v1/role/123e4567-e89b-12d3-a456-426614174000" gorm:"type:varchar(500);not null"`
+
+	ResourceType string     `json:"resource_type" example:"role" gorm:"type:varchar(50);not null;index:idx_audit_logs_resource,priority:1"`
+	ResourceID   *uuid.UUID `json:"resource_id,omitempty" gorm:"type:uuid;index:idx_audit_logs_resource,priority:2"`
+
+	// Changes maps each field that changed to its value before and after, see audit.Change
+	Changes json.RawMessage `json:"changes" gorm:"type:jsonb;not null;default:'{}'"`
+
+	Status    int    `json:"status" example:"200" gorm:"not null"`
+	RequestID string `json:"request_id" example:"3f2b1c9e-8a4d-4e2f-9b7a-1c2d3e4f5a6b" gorm:"type:varchar(100);not null"`
+	IP        string `json:"ip" example:"203.0.113.7" gorm:"type:varchar(64);not null"`
+}
@@ -29,8 +29,14 @@ type Comment struct {
 
 	Description string `json:"description" example:"This is a helpful thread" maxLength:"1500" doc:"Content of the comment" gorm:"type:varchar(3000);not null"`
 
+	// set while the comment has enough open reports to be hidden pending review
+	HiddenAt *time.Time `json:"-"`
+
+	// counters kept up to date by the comment and comment_like DBs -> never written by gorm
+	LikeCount  int64 `json:"like_count" gorm:"not null;default:0;<-:false"`
+	ReplyCount int64 `json:"reply_count" gorm:"not null;default:0;<-:false"`
+
 	// only used for db queries -> ignored during migrations
-	LikeCount  int64 `json:"like_count" gorm:"column:like_count;->;-:migration"`
-	IsLiked    bool  `json:"is_liked" gorm:"column:is_liked;->;-:migration"`
-	HasReplies bool  `json:"has_replies" gorm:"column:has_replies;->;-:migration"`
+	IsLiked    bool `json:"is_liked" gorm:"column:is_liked;->;-:migration"`
+	HasReplies bool `json:"has_replies" gorm:"column:has_replies;->;-:migration"`
 }
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailList is a kind of optional email, named after its email_preferences column. Receipts and
+// other transactional emails aren't on a list and always go out.
+type EmailList string
+
+const (
+	EmailListDigest      EmailList = "digest"
+	EmailListReplyAlerts EmailList = "reply_alerts"
+)
+
+// EmailPreference is which optional emails a user gets, and the token that unsubscribes them
+// without signing in. Rows are created the first time a user could get an optional email.
+type EmailPreference struct {
+	UserID           uuid.UUID  `json:"user_id" gorm:"primaryKey;type:uuid"`
+	User             User       `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	UnsubscribeToken string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex;default:replace(gen_random_uuid()::text || gen_random_uuid()::text, '-', '')"`
+	Digest           bool       `json:"digest" gorm:"not null;default:true"`
+	ReplyAlerts      bool       `json:"reply_alerts" gorm:"not null;default:true"`
+	LastDigestAt     *time.Time `json:"last_digest_at"`
+	LastReplyAlertAt *time.Time `json:"last_reply_alert_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
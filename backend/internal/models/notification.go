@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationType string
+
+const (
+	NotificationPostLike     NotificationType = "post_like"     // subject is the liked post
+	NotificationCommentLike  NotificationType = "comment_like"  // subject is the liked comment
+	NotificationPostComment  NotificationType = "post_comment"  // subject is the post commented on
+	NotificationCommentReply NotificationType = "comment_reply" // subject is the comment replied to
+	NotificationTagPost      NotificationType = "tag_post"      // subject is the followed tag posted in
+)
+
+// NotificationTypes lists every notification type, in the order preferences are shown.
+var NotificationTypes = []NotificationType{
+	NotificationPostLike,
+	NotificationCommentLike,
+	NotificationPostComment,
+	NotificationCommentReply,
+	NotificationTagPost,
+}
+
+// A Notification tells a user that others acted on their content or posted in a tag they follow.
+// Activity on the same subject is grouped while the notification is unread: another like on a post
+// adds to the existing "liked your post" notification rather than creating a new one.
+type Notification struct {
+	ID        uuid.UUID        `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at" gorm:"index:idx_notifications_user_updated,priority:2,sort:desc"`
+	UserID    uuid.UUID        `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_notifications_unread_group,priority:1,where:read_at IS NULL;index:idx_notifications_user_updated,priority:1"`
+	User      User             `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	Type      NotificationType `json:"type" gorm:"type:varchar(32);not null;uniqueIndex:idx_notifications_unread_group,priority:2"`
+	SubjectID uuid.UUID        `json:"subject_id" gorm:"type:uuid;not null;uniqueIndex:idx_notifications_unread_group,priority:3"`
+	PostID    *uuid.UUID       `json:"post_id" gorm:"type:uuid"` // the post to open, when there is one
+	Post      *Post            `json:"-" gorm:"foreignKey:PostID;references:ID;constraint:OnDelete:CASCADE"`
+	// The latest actor, left empty when they acted anonymously
+	ActorID    *uuid.UUID `json:"actor_id" gorm:"type:uuid"`
+	Actor      *User      `json:"-" gorm:"foreignKey:ActorID;references:ID;constraint:OnDelete:SET NULL"`
+	ActorCount int64      `json:"actor_count" gorm:"not null;default:1"`
+	ReadAt     *time.Time `json:"read_at"`
+}
+
+// NotificationActor records each distinct user grouped into a notification, so repeated activity
+// by one user is only counted once.
+type NotificationActor struct {
+	NotificationID uuid.UUID    `json:"notification_id" gorm:"primaryKey;type:uuid"`
+	Notification   Notification `json:"-" gorm:"foreignKey:NotificationID;references:ID;constraint:OnDelete:CASCADE"`
+	ActorID        uuid.UUID    `json:"actor_id" gorm:"primaryKey;type:uuid"`
+	Actor          User         `json:"-" gorm:"foreignKey:ActorID;references:ID;constraint:OnDelete:CASCADE"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// NotificationPreference turns one type of notification on or off for a user. Types without a
+// row are on.
+type NotificationPreference struct {
+	UserID  uuid.UUID        `json:"user_id" gorm:"primaryKey;type:uuid"`
+	User    User             `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	Type    NotificationType `json:"type" gorm:"primaryKey;type:varchar(32)"`
+	Enabled bool             `json:"enabled" gorm:"not null"`
+}
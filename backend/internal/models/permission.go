@@ -12,10 +12,21 @@ type PermissionAction string
 
 const (
 	PermissionCreate    PermissionAction = "create"
+	PermissionRead      PermissionAction = "read"
 	PermissionUpdate    PermissionAction = "update"
 	PermissionDelete    PermissionAction = "delete"
 	PermissionUpdateOwn PermissionAction = "update_own"
 	PermissionDeleteOwn PermissionAction = "delete_own"
+
+	// moderation actions on the "report" resource, see ModerationActionType
+	PermissionReview  PermissionAction = "review"
+	PermissionDismiss PermissionAction = PermissionAction(ModerationDismiss)
+	PermissionRemove  PermissionAction = PermissionAction(ModerationRemove)
+	PermissionWarn    PermissionAction = PermissionAction(ModerationWarn)
+	PermissionSuspend PermissionAction = PermissionAction(ModerationSuspend)
+
+	// leaves a survey response out of the averages without deleting it
+	PermissionInvalidate PermissionAction = "invalidate"
 )
 
 var (
@@ -27,10 +38,17 @@ var (
 func IsValidPermissionAction(action PermissionAction) bool {
 	switch action {
 	case PermissionCreate,
+		PermissionRead,
 		PermissionUpdate,
 		PermissionDelete,
 		PermissionUpdateOwn,
-		PermissionDeleteOwn:
+		PermissionDeleteOwn,
+		PermissionReview,
+		PermissionDismiss,
+		PermissionRemove,
+		PermissionWarn,
+		PermissionSuspend,
+		PermissionInvalidate:
 		return true
 	default:
 		return false
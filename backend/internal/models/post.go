@@ -23,10 +23,17 @@ type Post struct {
 	Title       string         `json:"title" example:"Looking for thoughts on NEU Fencing!" gorm:"type:varchar(100);not null" validate:"required,min=1,max=100"`
 	Content     string         `json:"content" example:"My name is Bob Joe and I am a rising senior who just got into NEU. What is the fencing program like? Are they competitive?" gorm:"type:varchar(5000);not null" validate:"required,min=1,max=5000"`
 	IsAnonymous bool           `json:"isAnonymous" gorm:"default:false"`
+	// set while the post has enough open reports to be hidden pending review
+	HiddenAt *time.Time `json:"-"`
+
+	// maintained by database triggers from title, content and tag names -> never read or written by gorm
+	SearchVector string `json:"-" gorm:"type:tsvector;index:idx_posts_search_vector,type:gin;->:false;<-:false"`
 
 	// only used for db queries -> ignored for migrations
 	LikeCount       int64   `json:"like_count" gorm:"column:like_count;->;-:migration"`
 	CommentCount    int64   `json:"comment_count" gorm:"column:comment_count;->;-:migration"`
 	IsLiked         bool    `json:"is_liked" gorm:"column:is_liked;->;-:migration"`
 	PopularityScore float64 `json:"popularity_score" gorm:"column:popularity_score;->;-:migration"`
+	Snippet         string  `json:"snippet,omitempty" gorm:"column:snippet;->;-:migration"`
+	TitleHighlight  string  `json:"title_highlight,omitempty" gorm:"column:title_highlight;->;-:migration"`
 }
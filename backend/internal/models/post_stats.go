@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostStats holds a post's precomputed engagement. Rows are updated alongside every like and
+// comment and recomputed in bulk by the trending job (see internal/poststats).
+type PostStats struct {
+	PostID       uuid.UUID `json:"post_id" gorm:"primaryKey;type:uuid"`
+	Post         Post      `json:"-" gorm:"foreignKey:PostID;references:ID;constraint:OnDelete:CASCADE"`
+	LikeCount    int64     `json:"like_count" gorm:"not null;default:0"`
+	CommentCount int64     `json:"comment_count" gorm:"not null;default:0"`
+	// Likes and comments weighted and decayed by age, as of ScoredAt
+	TrendingScore float64   `json:"trending_score" gorm:"type:double precision;not null;default:0"`
+	ScoredAt      time.Time `json:"scored_at" gorm:"not null;default:now()"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (PostStats) TableName() string {
+	return "post_stats"
+}
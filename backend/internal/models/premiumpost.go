@@ -26,6 +26,16 @@ type PremiumPost struct {
 	Title   string `json:"title" example:"Looking for thoughts on NEU Fencing!" gorm:"type:varchar(100);not null" validate:"required,min=1,max=100"`
 	Content string `json:"content" example:"My name is Bob Joe and I am a rising senior who just got into NEU. What is the fencing program like? Are they competitive?" gorm:"type:varchar(5000);not null" validate:"required,min=1,max=5000"`
 
+	// set while the premium post has enough open reports to be hidden pending review
+	HiddenAt *time.Time `json:"-"`
+
 	MediaID *uuid.UUID `json:"media_id,omitempty" gorm:"type:uuid;default:null"`
 	Media   *Media     `json:"media,omitempty" gorm:"foreignKey:MediaID;references:ID;constraint:OnDelete:SET NULL"`
+
+	// maintained by database triggers from title, content and tag names -> never read or written by gorm
+	SearchVector string `json:"-" gorm:"type:tsvector;index:idx_premium_posts_search_vector,type:gin;->:false;<-:false"`
+
+	// only used for db queries -> ignored for migrations
+	Snippet        string `json:"snippet,omitempty" gorm:"column:snippet;->;-:migration"`
+	TitleHighlight string `json:"title_highlight,omitempty" gorm:"column:title_highlight;->;-:migration"`
 }
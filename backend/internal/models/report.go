@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportableType is a kind of content that can be reported
+type ReportableType string
+
+const (
+	ReportablePost        ReportableType = "post"
+	ReportablePremiumPost ReportableType = "premium_post"
+	ReportableComment     ReportableType = "comment"
+)
+
+type ReportReason string
+
+const (
+	ReportReasonSpam           ReportReason = "spam"
+	ReportReasonHarassment     ReportReason = "harassment"
+	ReportReasonHateSpeech     ReportReason = "hate_speech"
+	ReportReasonMisinformation ReportReason = "misinformation"
+	ReportReasonInappropriate  ReportReason = "inappropriate"
+	ReportReasonOther          ReportReason = "other"
+)
+
+type ReportStatus string
+
+const (
+	// ReportStatusOpen reports are waiting in the moderation queue
+	ReportStatusOpen ReportStatus = "open"
+	// ReportStatusDismissed reports were reviewed and nothing was done
+	ReportStatusDismissed ReportStatus = "dismissed"
+	// ReportStatusActioned reports were reviewed and the content was removed
+	ReportStatusActioned ReportStatus = "actioned"
+)
+
+// Report is one user flagging one post, premium post or comment. A user can report an item once.
+type Report struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ReporterID uuid.UUID `json:"reporter_id" gorm:"type:uuid;not null;uniqueIndex:idx_reports_reporter_content"`
+	Reporter   User      `json:"-" gorm:"foreignKey:ReporterID;references:ID;constraint:OnDelete:CASCADE"`
+
+	// the reported item -> polymorphic like tag_posts, so there is no foreign key
+	ContentType ReportableType `json:"content_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_reports_reporter_content;index:idx_reports_content"`
+	ContentID   uuid.UUID      `json:"content_id" gorm:"type:uuid;not null;uniqueIndex:idx_reports_reporter_content;index:idx_reports_content"`
+
+	Reason  ReportReason `json:"reason" gorm:"type:varchar(32);not null"`
+	Details *string      `json:"details,omitempty" gorm:"type:varchar(1000)"`
+	Status  ReportStatus `json:"status" gorm:"type:varchar(20);not null;default:'open';index:idx_reports_content"`
+
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	ResolvedByID *uuid.UUID `json:"resolved_by_id,omitempty" gorm:"type:uuid"`
+	ResolvedBy   *User      `json:"-" gorm:"foreignKey:ResolvedByID;references:ID;constraint:OnDelete:SET NULL"`
+}
+
+// ModerationActionType is what a moderator did about reported content. Each one is also the
+// permission action on the "report" resource that allows it.
+type ModerationActionType string
+
+const (
+	// ModerationDismiss closes the reports and shows the content again if it was hidden
+	ModerationDismiss ModerationActionType = "dismiss"
+	// ModerationRemove deletes the content
+	ModerationRemove ModerationActionType = "remove"
+	// ModerationWarn deletes the content and records a warning against its author
+	ModerationWarn ModerationActionType = "warn"
+	// ModerationSuspend deletes the content and suspends its author
+	ModerationSuspend ModerationActionType = "suspend"
+)
+
+// ModerationAction is the audit trail of moderation: one row per action a moderator took. Rows
+// are never updated or deleted.
+type ModerationAction struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_moderation_actions_target_created,sort:desc,priority:2"`
+
+	ModeratorID *uuid.UUID `json:"moderator_id" gorm:"type:uuid"`
+	Moderator   *User      `json:"-" gorm:"foreignKey:ModeratorID;references:ID;constraint:OnDelete:SET NULL"`
+
+	Action      ModerationActionType `json:"action" gorm:"type:varchar(20);not null"`
+	ContentType ReportableType       `json:"content_type" gorm:"type:varchar(20);not null"`
+	ContentID   uuid.UUID            `json:"content_id" gorm:"type:uuid;not null"`
+
+	// TargetUserID is the content's author
+	TargetUserID *uuid.UUID `json:"target_user_id" gorm:"type:uuid;index:idx_moderation_actions_target_created,priority:1"`
+	TargetUser   *User      `json:"-" gorm:"foreignKey:TargetUserID;references:ID;constraint:OnDelete:SET NULL"`
+
+	Note            *string    `json:"note,omitempty" gorm:"type:varchar(1000)"`
+	SuspendedUntil  *time.Time `json:"suspended_until,omitempty"`
+	ReportsResolved int64      `json:"reports_resolved" gorm:"not null;default:0"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SanctionType is a restriction put on a user's account
+type SanctionType string
+
+const (
+	// SanctionSuspend stops the user posting, commenting, liking or reporting until it expires
+	SanctionSuspend SanctionType = "suspend"
+	// SanctionBan stops the user using the API at all
+	SanctionBan SanctionType = "ban"
+	// SanctionShadowBan hides everything the user posts from everyone but them. They aren't told.
+	SanctionShadowBan SanctionType = "shadow_ban"
+)
+
+// AccountStatus is what a user's sanctions in force allow them to do
+type AccountStatus string
+
+const (
+	AccountActive       AccountStatus = "active"
+	AccountSuspended    AccountStatus = "suspended"
+	AccountBanned       AccountStatus = "banned"
+	AccountShadowBanned AccountStatus = "shadow_banned"
+)
+
+// Sanction is one restriction put on a user, by an admin or by a moderator acting on reports. It
+// is in force from when it was created until it expires or is lifted. Lifted sanctions are kept
+// as the user's history.
+type Sanction struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index:idx_sanctions_user_type,priority:1"`
+	User   User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+
+	Type   SanctionType `json:"type" gorm:"type:varchar(20);not null;index:idx_sanctions_user_type,priority:2"`
+	Reason string       `json:"reason" gorm:"type:varchar(1000);not null"`
+	// ExpiresAt is nil for sanctions that last until they are lifted
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	IssuedByID *uuid.UUID `json:"issued_by_id,omitempty" gorm:"type:uuid"`
+	IssuedBy   *User      `json:"-" gorm:"foreignKey:IssuedByID;references:ID;constraint:OnDelete:SET NULL"`
+
+	LiftedAt   *time.Time `json:"lifted_at,omitempty"`
+	LiftedByID *uuid.UUID `json:"lifted_by_id,omitempty" gorm:"type:uuid"`
+	LiftedBy   *User      `json:"-" gorm:"foreignKey:LiftedByID;references:ID;constraint:OnDelete:SET NULL"`
+	LiftReason *string    `json:"lift_reason,omitempty" gorm:"type:varchar(1000)"`
+}
+
+// InForce reports whether the sanction applies at t.
+func (s *Sanction) InForce(t time.Time) bool {
+	return s.LiftedAt == nil && (s.ExpiresAt == nil || s.ExpiresAt.After(t))
+}
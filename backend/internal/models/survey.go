@@ -7,7 +7,8 @@ import (
 	"gorm.io/gorm"
 )
 
-// Survey represents an athlete's survey response for a sport program at a college
+// Survey represents an athlete's survey response for a sport program at a college. Each user
+// has one response per program, which they edit by submitting again.
 type Survey struct {
 	ID        uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	CreatedAt time.Time      `json:"created_at"`
@@ -15,21 +16,24 @@ type Survey struct {
 	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 
 	// Foreign keys
-	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index" validate:"required"`
-	CollegeID uuid.UUID `json:"college_id" gorm:"type:uuid;not null;index" validate:"required"`
-	SportID   uuid.UUID `json:"sport_id" gorm:"type:uuid;not null;index" validate:"required"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_surveys_program" validate:"required"`
+	CollegeID uuid.UUID `json:"college_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_surveys_program" validate:"required"`
+	SportID   uuid.UUID `json:"sport_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_surveys_program" validate:"required"`
 
 	// Associations
 	User    User    `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	College College `json:"college,omitempty" gorm:"foreignKey:CollegeID"`
 	Sport   Sport   `json:"sport,omitempty" gorm:"foreignKey:SportID"`
 
-	// Ratings (1–5)
-	PlayerDev                  int32 `json:"player_dev" gorm:"type:smallint;not null" validate:"required,min=1,max=5"`
-	AcademicsAthleticsPriority int32 `json:"academics_athletics_priority" gorm:"type:smallint;not null" validate:"required,min=1,max=5"`
-	AcademicCareerResources    int32 `json:"academic_career_resources" gorm:"type:smallint;not null" validate:"required,min=1,max=5"`
-	MentalHealthPriority       int32 `json:"mental_health_priority" gorm:"type:smallint;not null" validate:"required,min=1,max=5"`
-	Environment                int32 `json:"environment" gorm:"type:smallint;not null" validate:"required,min=1,max=5"`
-	Culture                    int32 `json:"culture" gorm:"type:smallint;not null" validate:"required,min=1,max=5"`
-	Transparency               int32 `json:"transparency" gorm:"type:smallint;not null" validate:"required,min=1,max=5"`
-}
\ No newline at end of file
+	// the template version the survey was answered with, and its answers to the version's questions
+	TemplateVersionID uuid.UUID              `json:"template_version_id" gorm:"type:uuid;not null;index"`
+	TemplateVersion   *SurveyTemplateVersion `json:"-" gorm:"foreignKey:TemplateVersionID;references:ID"`
+	Answers           []SurveyAnswer         `json:"answers,omitempty" gorm:"foreignKey:SurveyID;constraint:OnDelete:CASCADE"`
+
+	// set by an admin on a suspicious response, which is then left out of the averages. Editing
+	// the response doesn't clear it.
+	InvalidatedAt      *time.Time `json:"invalidated_at,omitempty" gorm:"index"`
+	InvalidatedByID    *uuid.UUID `json:"invalidated_by_id,omitempty" gorm:"type:uuid"`
+	InvalidatedBy      *User      `json:"-" gorm:"foreignKey:InvalidatedByID;references:ID;constraint:OnDelete:SET NULL"`
+	InvalidationReason *string    `json:"invalidation_reason,omitempty" gorm:"type:varchar(1000)"`
+}
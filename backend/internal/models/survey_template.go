@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SurveyQuestionType is how a survey question is answered
+type SurveyQuestionType string
+
+const (
+	// SurveyQuestionScale is answered with a rating from 1 to 5
+	SurveyQuestionScale SurveyQuestionType = "scale"
+	// SurveyQuestionChoice is answered with one of the question's options
+	SurveyQuestionChoice SurveyQuestionType = "multiple_choice"
+	// SurveyQuestionText is answered in the athlete's own words
+	SurveyQuestionText SurveyQuestionType = "text"
+)
+
+// SurveyTemplate is a set of questions athletes answer about a program, defined by admins. Its
+// questions are changed by publishing a new version, so answers always match the questions they
+// were given for.
+type SurveyTemplate struct {
+	ID          uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Name        string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex"`
+	Description *string   `json:"description,omitempty" gorm:"type:varchar(1000)"`
+
+	Versions []SurveyTemplateVersion `json:"-" gorm:"foreignKey:TemplateID;constraint:OnDelete:CASCADE"`
+}
+
+// SurveyTemplateVersion is one published, unchangeable set of a template's questions. Versions are
+// numbered from 1; the highest is the one new surveys are given.
+type SurveyTemplateVersion struct {
+	ID         uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CreatedAt  time.Time `json:"created_at"`
+	TemplateID uuid.UUID `json:"template_id" gorm:"type:uuid;not null;uniqueIndex:idx_survey_template_versions_number"`
+	Version    int       `json:"version" gorm:"not null;uniqueIndex:idx_survey_template_versions_number"`
+
+	CreatedByID *uuid.UUID `json:"created_by_id,omitempty" gorm:"type:uuid"`
+	CreatedBy   *User      `json:"-" gorm:"foreignKey:CreatedByID;references:ID;constraint:OnDelete:SET NULL"`
+
+	Questions []SurveyQuestion `json:"questions" gorm:"foreignKey:VersionID;constraint:OnDelete:CASCADE"`
+}
+
+// SurveyQuestion is a question in a template version. Questions with the same key are the same
+// question, in any version of any template, and are aggregated together; a key always has the
+// same type.
+type SurveyQuestion struct {
+	ID        uuid.UUID          `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	VersionID uuid.UUID          `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_survey_questions_version_key"`
+	Key       string             `json:"key" gorm:"type:varchar(50);not null;uniqueIndex:idx_survey_questions_version_key;index"`
+	Position  int                `json:"position" gorm:"not null"`
+	Type      SurveyQuestionType `json:"type" gorm:"type:varchar(20);not null"`
+	Prompt    string             `json:"prompt" gorm:"type:varchar(500);not null"`
+	Required  bool               `json:"required" gorm:"not null;default:true"`
+	// Options are the answers a multiple choice question allows
+	Options []string `json:"options,omitempty" gorm:"type:jsonb;serializer:json"`
+}
+
+// SurveyAnswer is a survey's answer to one question. Exactly one of Scale, Choice and Text is set,
+// matching the question's type.
+type SurveyAnswer struct {
+	ID         uuid.UUID      `json:"-" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	SurveyID   uuid.UUID      `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_survey_answers_question"`
+	QuestionID uuid.UUID      `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_survey_answers_question;index"`
+	Question   SurveyQuestion `json:"-" gorm:"foreignKey:QuestionID;references:ID"`
+	Scale      *int32         `json:"scale,omitempty" gorm:"type:smallint"`
+	Choice     *string        `json:"choice,omitempty" gorm:"type:varchar(200)"`
+	Text       *string        `json:"text,omitempty" gorm:"type:varchar(2000)"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserBlockType is how strongly a user shuts out another
+type UserBlockType string
+
+const (
+	// UserBlockMute hides the target's posts and comments from the user
+	UserBlockMute UserBlockType = "mute"
+	// UserBlockBlock also stops the target replying to, liking or mentioning the user
+	UserBlockBlock UserBlockType = "block"
+)
+
+// UserBlock is one user muting or blocking another. A user has at most one per target; blocking a
+// muted user turns the mute into a block.
+type UserBlock struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID uuid.UUID `json:"user_id" doc:"The user doing the muting or blocking" gorm:"type:uuid;not null;uniqueIndex:idx_user_blocks_user_target,priority:1"`
+	User   User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+
+	TargetID uuid.UUID `json:"target_id" doc:"The muted or blocked user" gorm:"type:uuid;not null;uniqueIndex:idx_user_blocks_user_target,priority:2"`
+	Target   User      `json:"-" gorm:"foreignKey:TargetID;references:ID;constraint:OnDelete:CASCADE"`
+
+	Type UserBlockType `json:"type" gorm:"type:varchar(10);not null"`
+}
@@ -2,9 +2,42 @@ package models
 
 import "github.com/google/uuid"
 
+// RoleScopeType is what a scoped role is limited to.
+type RoleScopeType string
+
+const (
+	RoleScopeSport   RoleScopeType = "sport"
+	RoleScopeCollege RoleScopeType = "college"
+)
+
+// RoleScope limits a role to one sport or college, such as a moderator of one sport.
+type RoleScope struct {
+	Type RoleScopeType `json:"type" enum:"sport,college" example:"sport"`
+	ID   uuid.UUID     `json:"id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the sport or college"`
+}
+
+// PremiumRoles are the roles that can read premium content. Only premium_user comes from a
+// subscription; staff keep access whatever their subscription.
+var PremiumRoles = []RoleName{RolePremiumUser, RoleAdmin, RoleModerator}
+
+// UserRole gives a user a role. A user may hold any number of roles, and their permissions are
+// the union of them. A role with a scope only grants its permissions on things in that scope.
 type UserRole struct {
-	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
-    RoleID uuid.UUID `json:"role_id" gorm:"type:uuid;primaryKey"`
-    User   User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
-    Role   Role      `json:"-" gorm:"foreignKey:RoleID;references:ID;constraint:OnDelete:CASCADE"`
+	ID     uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_roles_unscoped,where:scope_id IS NULL;uniqueIndex:idx_user_roles_scoped,priority:1,where:scope_id IS NOT NULL"`
+	RoleID uuid.UUID `json:"role_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_roles_unscoped,where:scope_id IS NULL;uniqueIndex:idx_user_roles_scoped,priority:2,where:scope_id IS NOT NULL"`
+	User   User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	Role   Role      `json:"-" gorm:"foreignKey:RoleID;references:ID;constraint:OnDelete:CASCADE"`
+
+	// both are set for a scoped role, neither for one that applies everywhere
+	ScopeType *RoleScopeType `json:"scope_type,omitempty" gorm:"type:varchar(10);uniqueIndex:idx_user_roles_scoped,priority:3,where:scope_id IS NOT NULL"`
+	ScopeID   *uuid.UUID     `json:"scope_id,omitempty" gorm:"type:uuid;uniqueIndex:idx_user_roles_scoped,priority:4,where:scope_id IS NOT NULL"`
+}
+
+// Scope is the role's scope, or nil when it applies everywhere.
+func (ur UserRole) Scope() *RoleScope {
+	if ur.ScopeType == nil || ur.ScopeID == nil {
+		return nil
+	}
+	return &RoleScope{Type: *ur.ScopeType, ID: *ur.ScopeID}
 }
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VerificationStatus is where a verification request is in review
+type VerificationStatus string
+
+const (
+	VerificationPending  VerificationStatus = "pending"
+	VerificationApproved VerificationStatus = "approved"
+	VerificationRejected VerificationStatus = "rejected"
+)
+
+// VerificationRequest is an athlete asking to be verified as playing a sport at a college, with
+// the evidence an admin reviews it on. Approving it is the only way a user becomes a verified
+// athlete. A user has at most one pending request; reviewed ones are kept as their history.
+type VerificationRequest struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_verification_requests_pending,where:status = 'pending'"`
+	User   User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+
+	// the team the athlete says they're on, which is copied to the user when the request is approved
+	SportID   uuid.UUID `json:"sport_id" gorm:"type:uuid;not null"`
+	Sport     Sport     `json:"-" gorm:"foreignKey:SportID;references:ID;constraint:OnDelete:CASCADE"`
+	CollegeID uuid.UUID `json:"college_id" gorm:"type:uuid;not null"`
+	College   College   `json:"-" gorm:"foreignKey:CollegeID;references:ID;constraint:OnDelete:CASCADE"`
+	// Division is the college's when the request was made; approving uses the college's current one
+	Division Division `json:"division" gorm:"type:uint;not null"`
+
+	// evidence; at least one is set
+	RosterURL *string `json:"roster_url,omitempty" gorm:"type:varchar(500)"`
+	TeamEmail *string `json:"team_email,omitempty" gorm:"type:varchar(100)"`
+	// DocumentKey is the S3 key of an uploaded ID document
+	DocumentKey *string `json:"document_key,omitempty" gorm:"type:varchar(500)"`
+
+	Status       VerificationStatus `json:"status" gorm:"type:varchar(10);not null;index"`
+	ReviewedAt   *time.Time         `json:"reviewed_at,omitempty"`
+	ReviewedByID *uuid.UUID         `json:"reviewed_by_id,omitempty" gorm:"type:uuid"`
+	ReviewedBy   *User              `json:"-" gorm:"foreignKey:ReviewedByID;references:ID;constraint:OnDelete:SET NULL"`
+	ReviewReason *string            `json:"review_reason,omitempty" gorm:"type:varchar(1000)"`
+}
@@ -7,7 +7,8 @@ import (
 	"gorm.io/gorm"
 )
 
-// ViewedPost records that a user (free tier) has viewed a post for limit enforcement.
+// ViewedPost records that a user has viewed a post. Free tier views are capped (see post.FreeUserMaxPostViews),
+// and the feed skips posts the user has already viewed.
 type ViewedPost struct {
 	ID        uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	UserID    uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_viewed_posts_user_post"`
@@ -0,0 +1,87 @@
+package permcache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryEntry struct {
+	grants    *Grants
+	expiresAt time.Time
+}
+
+// Memory is a Backend that keeps grants in this process. Once it holds maxEntries users it drops
+// the expired ones, and everything if none have expired.
+type Memory struct {
+	mu         sync.Mutex
+	entries    map[uuid.UUID]memoryEntry
+	maxEntries int
+}
+
+func NewMemory(maxEntries int) *Memory {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Memory{entries: map[uuid.UUID]memoryEntry{}, maxEntries: maxEntries}
+}
+
+func (m *Memory) Get(_ context.Context, userID uuid.UUID) (*Grants, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[userID]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(m.entries, userID)
+		return nil, false, nil
+	}
+	return entry.grants, true, nil
+}
+
+func (m *Memory) Set(_ context.Context, userID uuid.UUID, grants *Grants, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[userID]; !ok && len(m.entries) >= m.maxEntries {
+		m.evict()
+	}
+	m.entries[userID] = memoryEntry{grants: grants, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// evict drops the expired entries, or every entry when none have expired. Callers hold mu.
+func (m *Memory) evict() {
+	now := time.Now()
+	for userID, entry := range m.entries {
+		if now.After(entry.expiresAt) {
+			delete(m.entries, userID)
+		}
+	}
+	if len(m.entries) >= m.maxEntries {
+		clear(m.entries)
+	}
+}
+
+func (m *Memory) Delete(_ context.Context, userID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, userID)
+	return nil
+}
+
+func (m *Memory) Clear(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(m.entries)
+	return nil
+}
+
+// Len is how many users' grants are held, expired ones included until they're dropped.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
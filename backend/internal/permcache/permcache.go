@@ -0,0 +1,192 @@
+package permcache
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"inside-athletics/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Grant is one permission one of a user's roles gives them. Scope is nil for roles that apply
+// everywhere.
+type Grant struct {
+	Action   models.PermissionAction `json:"action"`
+	Resource string                  `json:"resource"`
+	Scope    *models.RoleScope       `json:"scope,omitempty"`
+}
+
+// Grants are every permission a user's roles give them, and the status their sanctions put their
+// account in.
+type Grants struct {
+	Grants []Grant              `json:"grants"`
+	Status models.AccountStatus `json:"status"`
+	// StatusUntil is when Status ends by itself, nil when it lasts until it is lifted
+	StatusUntil *time.Time `json:"status_until,omitempty"`
+}
+
+// Restriction returns why the user's account can't make a request, or "" when it can: banned users
+// can't do anything, and suspended users can only read. Shadow-banned users carry on as normal so
+// they can't tell. Nil grants, for users that don't exist yet, have no restriction.
+func (g *Grants) Restriction(read bool) string {
+	if g == nil {
+		return ""
+	}
+	switch g.Status {
+	case models.AccountBanned:
+		return "Account banned" + g.untilMessage()
+	case models.AccountSuspended:
+		if read {
+			return ""
+		}
+		return "Account suspended" + g.untilMessage()
+	}
+	return ""
+}
+
+func (g *Grants) untilMessage() string {
+	if g.StatusUntil == nil {
+		return ""
+	}
+	return " until " + g.StatusUntil.UTC().Format(time.RFC3339)
+}
+
+// Allows reports whether the grants include action on resource, everywhere or in one of scopes.
+// It answers the same as utils.HasPermission.
+func (g *Grants) Allows(action models.PermissionAction, resource string, scopes ...models.RoleScope) bool {
+	for _, grant := range g.Grants {
+		if grant.Action != action || grant.Resource != resource {
+			continue
+		}
+		if grant.Scope == nil || slices.Contains(scopes, *grant.Scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scopes returns whether the grants include action on resource everywhere, and otherwise the
+// scopes they include it in. It answers the same as utils.PermissionScopes.
+func (g *Grants) Scopes(action models.PermissionAction, resource string) (bool, []models.RoleScope) {
+	var scopes []models.RoleScope
+	for _, grant := range g.Grants {
+		if grant.Action != action || grant.Resource != resource {
+			continue
+		}
+		if grant.Scope == nil {
+			return true, nil
+		}
+		scopes = append(scopes, *grant.Scope)
+	}
+	return false, scopes
+}
+
+// Backend stores grants by user. Memory keeps them in this process; a shared backend would let
+// every instance see the others' invalidations.
+type Backend interface {
+	// Get returns the user's grants, and false when there are none or they've expired.
+	Get(ctx context.Context, userID uuid.UUID) (*Grants, bool, error)
+	Set(ctx context.Context, userID uuid.UUID, grants *Grants, ttl time.Duration) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+	// Clear deletes every user's grants.
+	Clear(ctx context.Context) error
+}
+
+// LoadFunc loads a user's grants from the database. It returns gorm.ErrRecordNotFound for users
+// that don't exist, which aren't cached.
+type LoadFunc func(ctx context.Context, userID uuid.UUID) (*Grants, error)
+
+// Cache resolves users' grants through a Backend, loading them on a miss. A nil Cache is valid and
+// invalidates nothing, for services built without one.
+type Cache struct {
+	load    LoadFunc
+	backend Backend
+	cfg     Config
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func New(load LoadFunc, backend Backend, cfg Config) *Cache {
+	return &Cache{load: load, backend: backend, cfg: cfg}
+}
+
+// Get returns the user's grants from the backend, or loads and caches them. A backend that fails
+// is logged and skipped, so requests are still authorized against the database.
+func (c *Cache) Get(ctx context.Context, userID uuid.UUID) (*Grants, error) {
+	grants, ok, err := c.backend.Get(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to read cached permissions", "error", err, "user_id", userID)
+	}
+	if ok {
+		c.hits.Add(1)
+		return grants, nil
+	}
+
+	c.misses.Add(1)
+	grants, err = c.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.backend.Set(ctx, userID, grants, c.ttlFor(grants)); err != nil {
+		slog.ErrorContext(ctx, "Failed to cache permissions", "error", err, "user_id", userID)
+	}
+	return grants, nil
+}
+
+// ttlFor is how long grants are cached: the configured TTL, or until the account's status ends by
+// itself if that's sooner.
+func (c *Cache) ttlFor(grants *Grants) time.Duration {
+	ttl := c.cfg.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if grants.StatusUntil != nil {
+		ttl = min(ttl, time.Until(*grants.StatusUntil))
+	}
+	return ttl
+}
+
+// Invalidate drops the user's grants, after their roles or sanctions change.
+func (c *Cache) Invalidate(ctx context.Context, userID uuid.UUID) {
+	if c == nil {
+		return
+	}
+	if err := c.backend.Delete(ctx, userID); err != nil {
+		slog.ErrorContext(ctx, "Failed to invalidate cached permissions", "error", err, "user_id", userID)
+	}
+}
+
+// InvalidateAll drops everyone's grants, after a role's or a permission's grants change.
+func (c *Cache) InvalidateAll(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	if err := c.backend.Clear(ctx); err != nil {
+		slog.ErrorContext(ctx, "Failed to invalidate cached permissions", "error", err)
+	}
+}
+
+// Stats are the cache's lookups since it was created. HitRate is 0 before the first one.
+type Stats struct {
+	Hits    int64   `json:"hits" doc:"Lookups answered from the cache"`
+	Misses  int64   `json:"misses" doc:"Lookups that loaded the user's permissions from the database"`
+	HitRate float64 `json:"hit_rate" example:"0.92" doc:"Share of lookups answered from the cache"`
+}
+
+// Stats returns the lookups so far, or none for a nil cache.
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	hits, misses := c.hits.Load(), c.misses.Load()
+	stats := Stats{Hits: hits, Misses: misses}
+	if total := hits + misses; total > 0 {
+		stats.HitRate = float64(hits) / float64(total)
+	}
+	return stats
+}
@@ -0,0 +1,53 @@
+package permcache
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Env keys for permission cache config. Durations are in seconds.
+const (
+	EnvTTLSec     = "PERMISSION_CACHE_TTL_SECONDS"
+	EnvMaxEntries = "PERMISSION_CACHE_MAX_ENTRIES"
+)
+
+const (
+	DefaultTTL        = time.Minute
+	DefaultMaxEntries = 10000
+)
+
+// Config controls how long grants are cached and how many users' grants are kept.
+type Config struct {
+	// TTL is how long a user's grants are used before they're loaded again. Changes made through
+	// the API invalidate them straight away; the TTL bounds how stale they get otherwise.
+	TTL time.Duration
+	// MaxEntries is how many users' grants the in-memory backend keeps.
+	MaxEntries int
+}
+
+// DefaultConfig returns the config used when nothing is set in env.
+func DefaultConfig() Config {
+	return Config{
+		TTL:        DefaultTTL,
+		MaxEntries: DefaultMaxEntries,
+	}
+}
+
+// LoadConfigFromEnv returns the permission cache config from env, falling back to DefaultConfig for
+// anything unset or invalid.
+func LoadConfigFromEnv() Config {
+	return Config{
+		TTL:        time.Duration(intFromEnv(EnvTTLSec, int(DefaultTTL.Seconds()))) * time.Second,
+		MaxEntries: intFromEnv(EnvMaxEntries, DefaultMaxEntries),
+	}
+}
+
+func intFromEnv(key string, fallback int) int {
+	if s := os.Getenv(key); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			return n
+		}
+	}
+	return fallback
+}
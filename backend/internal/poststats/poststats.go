@@ -0,0 +1,175 @@
+package poststats
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Activity is a kind of engagement counted in post_stats.
+type Activity int
+
+const (
+	Like Activity = iota
+	Comment
+)
+
+// scoreTolerance is how far, relative to the score, a stored trending score decayed to now may be
+// from the recomputed one before Refresh rewrites it. It absorbs floating point noise in the decay.
+const scoreTolerance = 1e-6
+
+// refreshLockID is the Postgres advisory lock held while refreshing, so only one app instance
+// recomputes post_stats at a time.
+const refreshLockID int64 = 0x706f7374737461
+
+// Store keeps the post_stats table up to date.
+type Store struct {
+	db  *gorm.DB
+	cfg Config
+}
+
+func NewStore(db *gorm.DB, cfg Config) *Store {
+	return &Store{db: db, cfg: cfg}
+}
+
+// Config returns the config the store scores posts with.
+func (s *Store) Config() Config {
+	return s.cfg
+}
+
+// decayed is the SQL for how much of a score from the time in column is left now, with halfLife
+// being the placeholder for the half-life in seconds. The exponent is capped so activity from long
+// ago rounds to almost nothing instead of underflowing.
+func decayed(column string, halfLife string) string {
+	return decayedAt("NOW()", column, halfLife)
+}
+
+// decayedAt is decayed as of the time in now instead of the current time.
+func decayedAt(now string, column string, halfLife string) string {
+	return "POWER(0.5, LEAST(EXTRACT(EPOCH FROM (" + now + " - " + column + "))::float8 / " + halfLife + ", 64))"
+}
+
+// TrendingScore is the expression for a post's trending score as of now, given the post_stats table
+// alias. Scores are stored as of scored_at and decayed on read, so they stay comparable between refreshes.
+func (s *Store) TrendingScore(alias string) clause.Expr {
+	return clause.Expr{
+		SQL:  "COALESCE(" + alias + ".trending_score * " + decayed(alias+".scored_at", "?") + ", 0)",
+		Vars: []any{s.cfg.halfLifeSeconds()},
+	}
+}
+
+// TrendingScoreAt is a post's trending score as it was at a fixed time: the stored score decayed to
+// at, minus what likes and comments made after at added to it. Lists that page by trending score use
+// it so the scores a cursor was built from neither keep decaying nor pick up new activity between
+// pages. Likes and comments removed since at still lower the score, as does a Refresh re-basing it.
+func (s *Store) TrendingScoreAt(alias string, at time.Time) clause.Expr {
+	asOf := "CAST(? AS timestamptz)"
+	since := func(table string) string {
+		return "? * COALESCE((SELECT SUM(" + decayedAt(asOf, "activity.created_at", "?") + ") FROM " + table +
+			" AS activity WHERE activity.post_id = " + alias + ".post_id AND activity.created_at > " + asOf + "), 0)"
+	}
+	halfLife := s.cfg.halfLifeSeconds()
+	return clause.Expr{
+		SQL: "GREATEST(COALESCE(" + alias + ".trending_score * " + decayedAt(asOf, alias+".scored_at", "?") + ", 0)" +
+			" - " + since("post_likes") + " - " + since("comments") + ", 0)",
+		Vars: []any{
+			at, halfLife,
+			s.cfg.Weights.TrendingLike, at, halfLife, at,
+			s.cfg.Weights.TrendingComment, at, halfLife, at,
+		},
+	}
+}
+
+// Record applies a like or comment being added (delta 1) or removed (delta -1) to the post's stats.
+// at is when the like or comment was made, so removing one takes back what is left of its trending
+// score. Pass the transaction the like or comment is written in so the two can't drift apart.
+func (s *Store) Record(tx *gorm.DB, postID uuid.UUID, activity Activity, at time.Time, delta int) error {
+	if tx == nil {
+		tx = s.db
+	}
+	likes, comments, weight := 0, 0, s.cfg.Weights.TrendingLike
+	if activity == Comment {
+		comments, weight = delta, s.cfg.Weights.TrendingComment
+	} else {
+		likes = delta
+	}
+
+	return tx.Exec(`
+		INSERT INTO post_stats (post_id, like_count, comment_count, trending_score, scored_at, updated_at)
+		VALUES (@post_id, GREATEST(@likes, 0), GREATEST(@comments, 0), GREATEST(@score * `+decayed("CAST(@at AS timestamptz)", "@half_life")+`, 0), NOW(), NOW())
+		ON CONFLICT (post_id) DO UPDATE SET
+			like_count = GREATEST(post_stats.like_count + @likes, 0),
+			comment_count = GREATEST(post_stats.comment_count + @comments, 0),
+			trending_score = GREATEST(post_stats.trending_score * `+decayed("post_stats.scored_at", "@half_life")+` + @score * `+decayed("CAST(@at AS timestamptz)", "@half_life")+`, 0),
+			scored_at = NOW(),
+			updated_at = NOW()`,
+		map[string]any{
+			"post_id":   postID,
+			"likes":     likes,
+			"comments":  comments,
+			"score":     float64(delta) * weight,
+			"at":        at,
+			"half_life": s.cfg.halfLifeSeconds(),
+		}).Error
+}
+
+// Refresh recomputes every post's stats from post_likes and comments. It corrects any drift from
+// writes that skipped Record (cascading deletes, manual fixes) and re-bases trending scores on the
+// current weights. Stored scores decay on read, so a row is only rewritten when its counts or its
+// score as of now actually changed. Returns the number of rows written, or 0 if another instance is
+// already refreshing.
+func (s *Store) Refresh(ctx context.Context) (int64, error) {
+	var written int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", refreshLockID).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		result := tx.Exec(`
+			INSERT INTO post_stats (post_id, like_count, comment_count, trending_score, scored_at, updated_at)
+			SELECT posts.id,
+				COALESCE(likes.total, 0),
+				COALESCE(comments.total, 0),
+				COALESCE(likes.trending, 0) * @like_weight + COALESCE(comments.trending, 0) * @comment_weight,
+				NOW(),
+				NOW()
+			FROM posts
+			LEFT JOIN (
+				SELECT post_id, COUNT(*) AS total, SUM(`+decayed("created_at", "@half_life")+`) AS trending
+				FROM post_likes
+				GROUP BY post_id
+			) AS likes ON likes.post_id = posts.id
+			LEFT JOIN (
+				SELECT post_id, COUNT(*) AS total, SUM(`+decayed("created_at", "@half_life")+`) AS trending
+				FROM comments
+				GROUP BY post_id
+			) AS comments ON comments.post_id = posts.id
+			WHERE posts.deleted_at IS NULL
+			ON CONFLICT (post_id) DO UPDATE SET
+				like_count = EXCLUDED.like_count,
+				comment_count = EXCLUDED.comment_count,
+				trending_score = EXCLUDED.trending_score,
+				scored_at = EXCLUDED.scored_at,
+				updated_at = EXCLUDED.updated_at
+			WHERE post_stats.like_count <> EXCLUDED.like_count
+				OR post_stats.comment_count <> EXCLUDED.comment_count
+				OR ABS(post_stats.trending_score * `+decayed("post_stats.scored_at", "@half_life")+` - EXCLUDED.trending_score)
+					> @tolerance * GREATEST(ABS(EXCLUDED.trending_score), 1)`,
+			map[string]any{
+				"like_weight":    s.cfg.Weights.TrendingLike,
+				"comment_weight": s.cfg.Weights.TrendingComment,
+				"half_life":      s.cfg.halfLifeSeconds(),
+				"tolerance":      scoreTolerance,
+			})
+		written = result.RowsAffected
+		return result.Error
+	})
+	return written, err
+}
@@ -0,0 +1,118 @@
+package poststats
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Env keys for popularity config. Weights are plain numbers, durations are in seconds.
+const (
+	EnvTrendingCommentWeight = "POPULARITY_TRENDING_COMMENT_WEIGHT"
+	EnvTrendingLikeWeight    = "POPULARITY_TRENDING_LIKE_WEIGHT"
+	EnvCommentWeight         = "POPULARITY_COMMENT_WEIGHT"
+	EnvLikeWeight            = "POPULARITY_LIKE_WEIGHT"
+	EnvTagFollowWeight       = "POPULARITY_TAG_FOLLOW_WEIGHT"
+	EnvSportFollowWeight     = "POPULARITY_SPORT_FOLLOW_WEIGHT"
+	EnvCollegeFollowWeight   = "POPULARITY_COLLEGE_FOLLOW_WEIGHT"
+	EnvRecencyWeight         = "POPULARITY_RECENCY_WEIGHT_PER_HOUR"
+	EnvTrendingHalfLifeSec   = "POPULARITY_TRENDING_HALF_LIFE_SECONDS"
+	EnvRefreshIntervalSec    = "POPULARITY_REFRESH_INTERVAL_SECONDS"
+)
+
+const (
+	DefaultTrendingHalfLife = 24 * time.Hour
+	DefaultRefreshInterval  = 10 * time.Minute
+)
+
+// Weights are how much each signal adds to a post's popularity score.
+type Weights struct {
+	// TrendingComment and TrendingLike are added to the trending score per comment/like.
+	// Their contribution halves every TrendingHalfLife.
+	TrendingComment float64
+	TrendingLike    float64
+	// Comment and Like are added per comment/like over the post's lifetime.
+	Comment float64
+	Like    float64
+	// Per-user boosts for posts in a tag, sport or college the user follows.
+	TagFollow     float64
+	SportFollow   float64
+	CollegeFollow float64
+	// RecencyPerHour is added for every hour a post is younger than the request's window.
+	RecencyPerHour float64
+}
+
+// DefaultWeights are the weights popular posts were ranked with before they became configurable.
+var DefaultWeights = Weights{
+	TrendingComment: 8.0,
+	TrendingLike:    3.0,
+	Comment:         2.0,
+	Like:            1.0,
+	TagFollow:       12.0,
+	SportFollow:     4.0,
+	CollegeFollow:   2.0,
+	RecencyPerHour:  0.15,
+}
+
+// Config controls how post popularity is scored and how often the trending job runs.
+type Config struct {
+	Weights Weights
+	// TrendingHalfLife is how long it takes a like's or comment's share of the trending score to halve.
+	TrendingHalfLife time.Duration
+	// RefreshInterval is how often the trending job recomputes post_stats from likes and comments.
+	RefreshInterval time.Duration
+}
+
+// DefaultConfig returns the config used when nothing is set in env.
+func DefaultConfig() Config {
+	return Config{
+		Weights:          DefaultWeights,
+		TrendingHalfLife: DefaultTrendingHalfLife,
+		RefreshInterval:  DefaultRefreshInterval,
+	}
+}
+
+// LoadConfigFromEnv returns the popularity config from env, falling back to DefaultConfig for anything unset or invalid.
+func LoadConfigFromEnv() Config {
+	w := DefaultWeights
+	return Config{
+		Weights: Weights{
+			TrendingComment: floatFromEnv(EnvTrendingCommentWeight, w.TrendingComment),
+			TrendingLike:    floatFromEnv(EnvTrendingLikeWeight, w.TrendingLike),
+			Comment:         floatFromEnv(EnvCommentWeight, w.Comment),
+			Like:            floatFromEnv(EnvLikeWeight, w.Like),
+			TagFollow:       floatFromEnv(EnvTagFollowWeight, w.TagFollow),
+			SportFollow:     floatFromEnv(EnvSportFollowWeight, w.SportFollow),
+			CollegeFollow:   floatFromEnv(EnvCollegeFollowWeight, w.CollegeFollow),
+			RecencyPerHour:  floatFromEnv(EnvRecencyWeight, w.RecencyPerHour),
+		},
+		TrendingHalfLife: durationFromEnv(EnvTrendingHalfLifeSec, DefaultTrendingHalfLife),
+		RefreshInterval:  durationFromEnv(EnvRefreshIntervalSec, DefaultRefreshInterval),
+	}
+}
+
+// halfLifeSeconds is TrendingHalfLife in seconds, never 0 so it is always safe to divide by.
+func (c Config) halfLifeSeconds() float64 {
+	if c.TrendingHalfLife <= 0 {
+		return DefaultTrendingHalfLife.Seconds()
+	}
+	return c.TrendingHalfLife.Seconds()
+}
+
+func floatFromEnv(key string, fallback float64) float64 {
+	if s := os.Getenv(key); s != "" {
+		if f, err := strconv.ParseFloat(s, 64); err == nil && f >= 0 {
+			return f
+		}
+	}
+	return fallback
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	if s := os.Getenv(key); s != "" {
+		if sec, err := strconv.Atoi(s); err == nil && sec > 0 {
+			return time.Duration(sec) * time.Second
+		}
+	}
+	return fallback
+}
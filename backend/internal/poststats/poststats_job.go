@@ -0,0 +1,48 @@
+package poststats
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Job periodically recomputes post_stats so trending scores keep decaying for posts nobody is
+// interacting with and counts can't drift for long.
+type Job struct {
+	store    *Store
+	interval time.Duration
+}
+
+func NewJob(db *gorm.DB, cfg Config) *Job {
+	interval := cfg.RefreshInterval
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	return &Job{store: NewStore(db, cfg), interval: interval}
+}
+
+// Run refreshes post_stats right away and then every interval until ctx is done. Failed refreshes
+// are logged and retried on the next tick.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		if written, err := j.store.Refresh(ctx); err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "Failed to refresh post stats", "error", err)
+			}
+		} else {
+			slog.InfoContext(ctx, "Refreshed post stats", "rows", written, "duration", time.Since(start))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"inside-athletics/internal/events"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Events sent on each topic.
+const (
+	EventCommentCreated   = "comment.created"   // on a post's comments topic
+	EventPostLikes        = "post.likes"        // on a post's likes topic, with the post's like count
+	EventCommentLikes     = "comment.likes"     // on a post's likes topic, with one of its comments' like count
+	EventNotificationSent = "notification.sent" // on a user's notifications topic
+)
+
+// PostCommentsTopic carries new comments and replies on a post.
+func PostCommentsTopic(postID uuid.UUID) string {
+	return "post:" + postID.String() + ":comments"
+}
+
+// PostLikesTopic carries like counts for a post and its comments.
+func PostLikesTopic(postID uuid.UUID) string {
+	return "post:" + postID.String() + ":likes"
+}
+
+// NotificationsTopic carries a user's new notifications. Streams can only subscribe to their own.
+func NotificationsTopic(userID uuid.UUID) string {
+	return "user:" + userID.String() + ":notifications"
+}
+
+// CommentCreatedData is sent when a comment is posted. Who wrote it is left out so anonymous
+// comments stay anonymous; clients fetch the comment to show it.
+type CommentCreatedData struct {
+	CommentID       uuid.UUID  `json:"comment_id"`
+	PostID          uuid.UUID  `json:"post_id"`
+	ParentCommentID *uuid.UUID `json:"parent_comment_id,omitempty"`
+}
+
+// PostLikesData is a post's like count after a like or unlike.
+type PostLikesData struct {
+	PostID    uuid.UUID `json:"post_id"`
+	LikeCount int64     `json:"like_count"`
+}
+
+// CommentLikesData is a comment's like count after a like or unlike.
+type CommentLikesData struct {
+	CommentID uuid.UUID `json:"comment_id"`
+	PostID    uuid.UUID `json:"post_id"`
+	LikeCount int64     `json:"like_count"`
+}
+
+// NotificationSentData points at a notification that is new, or has more activity grouped into it.
+type NotificationSentData struct {
+	NotificationID uuid.UUID               `json:"notification_id"`
+	Type           models.NotificationType `json:"type"`
+}
+
+// Bridge forwards comment, like and notification events from bus to their topics through pub.
+// Like counts are read back from the counters the like services just updated. Comments by
+// shadow-banned users are only shown to them, so they aren't streamed to anyone.
+func Bridge(bus *events.Bus, db *gorm.DB, pub Publisher) {
+	events.Subscribe(bus, func(ctx context.Context, e events.CommentCreated) error {
+		if banned, err := utils.IsShadowBanned(db.WithContext(ctx), e.UserID); err != nil || banned {
+			return err
+		}
+		return publish(ctx, pub, PostCommentsTopic(e.PostID), EventCommentCreated, CommentCreatedData{
+			CommentID:       e.CommentID,
+			PostID:          e.PostID,
+			ParentCommentID: e.ParentCommentID,
+		})
+	})
+	events.Subscribe(bus, func(ctx context.Context, e events.PostLiked) error {
+		return publishPostLikes(ctx, db, pub, e.PostID)
+	})
+	events.Subscribe(bus, func(ctx context.Context, e events.PostUnliked) error {
+		return publishPostLikes(ctx, db, pub, e.PostID)
+	})
+	events.Subscribe(bus, func(ctx context.Context, e events.CommentLiked) error {
+		return publishCommentLikes(ctx, db, pub, e.CommentID)
+	})
+	events.Subscribe(bus, func(ctx context.Context, e events.CommentUnliked) error {
+		return publishCommentLikes(ctx, db, pub, e.CommentID)
+	})
+	events.Subscribe(bus, func(ctx context.Context, e events.NotificationSent) error {
+		return publish(ctx, pub, NotificationsTopic(e.UserID), EventNotificationSent, NotificationSentData{
+			NotificationID: e.NotificationID,
+			Type:           e.Type,
+		})
+	})
+}
+
+func publishPostLikes(ctx context.Context, db *gorm.DB, pub Publisher, postID uuid.UUID) error {
+	var count int64
+	if err := db.WithContext(ctx).Model(&models.PostStats{}).Select("like_count").Where("post_id = ?", postID).Scan(&count).Error; err != nil {
+		return err
+	}
+	return publish(ctx, pub, PostLikesTopic(postID), EventPostLikes, PostLikesData{PostID: postID, LikeCount: count})
+}
+
+func publishCommentLikes(ctx context.Context, db *gorm.DB, pub Publisher, commentID uuid.UUID) error {
+	var comment models.Comment
+	if err := db.WithContext(ctx).Select("id", "post_id", "like_count").Where("id = ?", commentID).Take(&comment).Error; err != nil {
+		return err
+	}
+	return publish(ctx, pub, PostLikesTopic(comment.PostID), EventCommentLikes, CommentLikesData{
+		CommentID: comment.ID,
+		PostID:    comment.PostID,
+		LikeCount: comment.LikeCount,
+	})
+}
+
+func publish(ctx context.Context, pub Publisher, topic string, event string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return pub.Publish(ctx, Message{Topic: topic, Event: event, Data: raw})
+}
@@ -0,0 +1,143 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// ErrSlowConsumer is why a subscription was dropped when its buffer filled up.
+var ErrSlowConsumer = errors.New("realtime: subscriber fell too far behind")
+
+// Message is one update on a topic. Data is kept small (IDs and counts); clients fetch anything
+// else through the API, which applies the usual access checks.
+type Message struct {
+	Topic string          `json:"topic"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// Publisher sends messages to the subscribers of their topic.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// Hub fans messages out to this instance's subscribers. On its own it only reaches this instance;
+// PGBroker carries messages between instances and delivers them to each one's Hub.
+type Hub struct {
+	mu         sync.RWMutex
+	topics     map[string]map[*Subscription]struct{}
+	bufferSize int
+}
+
+// NewHub creates a new Hub with no subscribers
+func NewHub(cfg Config) *Hub {
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Hub{topics: map[string]map[*Subscription]struct{}{}, bufferSize: bufferSize}
+}
+
+// Subscription receives the messages published on its topics until it's closed or dropped.
+type Subscription struct {
+	hub      *Hub
+	topics   []string
+	messages chan Message
+	done     chan struct{}
+	once     sync.Once
+	err      error
+}
+
+// Subscribe starts a subscription to topics. It must be closed when the subscriber goes away.
+func (h *Hub) Subscribe(topics ...string) *Subscription {
+	sub := &Subscription{
+		hub:      h,
+		topics:   topics,
+		messages: make(chan Message, h.bufferSize),
+		done:     make(chan struct{}),
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = map[*Subscription]struct{}{}
+		}
+		h.topics[topic][sub] = struct{}{}
+	}
+	return sub
+}
+
+// Publish delivers msg to this instance's subscribers only.
+func (h *Hub) Publish(ctx context.Context, msg Message) error {
+	h.Deliver(msg)
+	return nil
+}
+
+// Deliver hands msg to every subscription on its topic without waiting on any of them. A
+// subscription whose buffer is full is dropped with ErrSlowConsumer rather than holding up the
+// others; its client reconnects and refetches what it missed.
+func (h *Hub) Deliver(msg Message) {
+	var slow []*Subscription
+	h.mu.RLock()
+	for sub := range h.topics[msg.Topic] {
+		select {
+		case sub.messages <- msg:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range slow {
+		sub.end(ErrSlowConsumer)
+	}
+}
+
+// Subscribers returns how many subscriptions are listening to topic.
+func (h *Hub) Subscribers(topic string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.topics[topic])
+}
+
+// Messages returns the subscription's messages, oldest first.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Done is closed when the subscription ends, either closed or dropped.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the subscription was dropped, once Done is closed. It is nil after Close.
+func (s *Subscription) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Close ends the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.end(nil)
+}
+
+func (s *Subscription) end(err error) {
+	s.once.Do(func() {
+		s.hub.mu.Lock()
+		for _, topic := range s.topics {
+			delete(s.hub.topics[topic], s)
+			if len(s.hub.topics[topic]) == 0 {
+				delete(s.hub.topics, topic)
+			}
+		}
+		s.hub.mu.Unlock()
+		s.err = err
+		close(s.done)
+	})
+}
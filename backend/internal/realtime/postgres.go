@@ -0,0 +1,115 @@
+package realtime
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+// pgChannel is the Postgres channel every instance listens on.
+const pgChannel = "realtime"
+
+// maxPayload is below Postgres' 8000 byte limit on a NOTIFY payload.
+const maxPayload = 7900
+
+const listenRetry = 5 * time.Second
+
+// ErrPayloadTooLarge is returned for messages that don't fit in a NOTIFY.
+var ErrPayloadTooLarge = errors.New("realtime: message too large to publish")
+
+// PGBroker carries messages between server instances with Postgres LISTEN/NOTIFY. Publish sends a
+// NOTIFY and Run delivers every notification, including this instance's own, to the local Hub, so
+// a message reaches the same subscribers whichever instance published it.
+type PGBroker struct {
+	db        *gorm.DB
+	hub       *Hub
+	listening chan struct{}
+	once      sync.Once
+}
+
+// NewPGBroker creates a new PGBroker delivering to hub. Nothing is delivered until Run is started.
+func NewPGBroker(db *gorm.DB, hub *Hub) *PGBroker {
+	return &PGBroker{db: db, hub: hub, listening: make(chan struct{})}
+}
+
+// Publish sends msg to the subscribers of its topic on every instance.
+func (b *PGBroker) Publish(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxPayload {
+		return ErrPayloadTooLarge
+	}
+	return b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", pgChannel, string(payload)).Error
+}
+
+// Listening is closed once Run is first listening for notifications.
+func (b *PGBroker) Listening() <-chan struct{} {
+	return b.listening
+}
+
+// Run listens for messages and delivers them to the Hub until ctx is done. It holds one connection
+// from the pool for as long as it runs, and reconnects if the connection is lost. Messages
+// published while it's reconnecting are missed.
+func (b *PGBroker) Run(ctx context.Context) {
+	for {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.ErrorContext(ctx, "Realtime listener stopped, reconnecting", "error", err, "retry", listenRetry)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetry):
+		}
+	}
+}
+
+func (b *PGBroker) listen(ctx context.Context) error {
+	sqlDB, err := b.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("realtime: LISTEN needs a pgx connection, got %T", driverConn)
+		}
+		pgConn := stdConn.Conn()
+		if _, err := pgConn.Exec(ctx, "LISTEN "+pgChannel); err != nil {
+			return err
+		}
+		b.once.Do(func() { close(b.listening) })
+
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				// the connection is still subscribed, so it can't go back to the pool
+				return fmt.Errorf("%w: %w", driver.ErrBadConn, err)
+			}
+			var msg Message
+			if err := json.Unmarshal([]byte(notification.Payload), &msg); err != nil {
+				slog.ErrorContext(ctx, "Dropped malformed realtime message", "error", err)
+				continue
+			}
+			b.hub.Deliver(msg)
+		}
+	})
+}
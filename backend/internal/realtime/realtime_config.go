@@ -0,0 +1,57 @@
+package realtime
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Env keys for realtime config. Durations are in seconds.
+const (
+	EnvBufferSize   = "REALTIME_BUFFER_SIZE"
+	EnvHeartbeatSec = "REALTIME_HEARTBEAT_SECONDS"
+	EnvMaxTopics    = "REALTIME_MAX_TOPICS"
+)
+
+const (
+	DefaultBufferSize = 64
+	DefaultHeartbeat  = 25 * time.Second
+	DefaultMaxTopics  = 20
+)
+
+// Config controls how much a stream may fall behind and how it's kept open.
+type Config struct {
+	// BufferSize is how many messages a stream can have waiting before it's dropped as too slow.
+	BufferSize int
+	// Heartbeat is how often an idle stream is pinged, so proxies keep it open and dead clients are noticed.
+	Heartbeat time.Duration
+	// MaxTopics is how many topics one stream can subscribe to.
+	MaxTopics int
+}
+
+// DefaultConfig returns the config used when nothing is set in env.
+func DefaultConfig() Config {
+	return Config{
+		BufferSize: DefaultBufferSize,
+		Heartbeat:  DefaultHeartbeat,
+		MaxTopics:  DefaultMaxTopics,
+	}
+}
+
+// LoadConfigFromEnv returns the realtime config from env, falling back to DefaultConfig for anything unset or invalid.
+func LoadConfigFromEnv() Config {
+	return Config{
+		BufferSize: intFromEnv(EnvBufferSize, DefaultBufferSize),
+		Heartbeat:  time.Duration(intFromEnv(EnvHeartbeatSec, int(DefaultHeartbeat.Seconds()))) * time.Second,
+		MaxTopics:  intFromEnv(EnvMaxTopics, DefaultMaxTopics),
+	}
+}
+
+func intFromEnv(key string, fallback int) int {
+	if s := os.Getenv(key); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			return n
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,161 @@
+package realtime
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"inside-athletics/internal/permcache"
+	"inside-athletics/internal/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StreamPath is where clients open their Server-Sent Events stream.
+const StreamPath = "/api/v1/realtime/stream"
+
+// RegisterRoute adds the realtime stream to router. It is a plain Fiber route, not a Huma one,
+// so it sits behind AuthMiddleware like the rest of the API but not the permission middleware,
+// and checks the account status in grants itself.
+func RegisterRoute(router *fiber.App, hub *Hub, grants *permcache.Cache, cfg Config) {
+	router.Get(StreamPath, streamHandler(hub, grants, cfg))
+}
+
+// streamHandler serves a Server-Sent Events stream of the topics in the "topics" query param, a
+// comma separated list of "notifications", "post:{id}:comments" and "post:{id}:likes". Every
+// message is sent as an event named after Message.Event with the Message as its data. A stream
+// that falls too far behind gets an "overflow" event and is closed. Banned and suspended users
+// can't open one.
+func streamHandler(hub *Hub, grants *permcache.Cache, cfg Config) fiber.Handler {
+	heartbeat := cfg.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeat
+	}
+	maxTopics := cfg.MaxTopics
+	if maxTopics <= 0 {
+		maxTopics = DefaultMaxTopics
+	}
+
+	return func(c *fiber.Ctx) error {
+		principal, ok := utils.PrincipalFromContext(c.UserContext())
+		if !ok {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "User not authenticated",
+			})
+		}
+		userGrants, err := grants.Get(c.UserContext(), principal.UserID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Unable to check account status",
+			})
+		}
+		// a live stream isn't a read suspended users may still make
+		if msg := userGrants.Restriction(false); msg != "" {
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"error": msg,
+			})
+		}
+		topics, err := resolveTopics(c.Query("topics"), principal.UserID, maxTopics)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+		c.Set("X-Accel-Buffering", "no")
+
+		sub := hub.Subscribe(topics...)
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer sub.Close()
+			ticker := time.NewTicker(heartbeat)
+			defer ticker.Stop()
+
+			ready, _ := json.Marshal(map[string][]string{"topics": topics})
+			if writeEvent(w, "ready", ready) != nil {
+				return
+			}
+			for {
+				select {
+				case msg := <-sub.Messages():
+					data, err := json.Marshal(msg)
+					if err != nil || writeEvent(w, msg.Event, data) != nil {
+						return
+					}
+				case <-ticker.C:
+					// a comment line, ignored by clients; fails once the client has gone
+					if _, err := w.WriteString(": ping\n\n"); err != nil || w.Flush() != nil {
+						return
+					}
+				case <-sub.Done():
+					overflow, _ := json.Marshal(map[string]string{"error": sub.Err().Error()})
+					_ = writeEvent(w, "overflow", overflow)
+					return
+				}
+			}
+		})
+		return nil
+	}
+}
+
+func writeEvent(w *bufio.Writer, event string, data []byte) error {
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// resolveTopics turns the requested topics into hub topics. "notifications" is always the current
+// user's own.
+func resolveTopics(query string, userID uuid.UUID, maxTopics int) ([]string, error) {
+	var topics []string
+	seen := map[string]bool{}
+	for _, requested := range strings.Split(query, ",") {
+		requested = strings.TrimSpace(requested)
+		if requested == "" {
+			continue
+		}
+		topic, err := resolveTopic(requested, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+	if len(topics) == 0 {
+		return nil, errors.New("At least one topic is required")
+	}
+	if len(topics) > maxTopics {
+		return nil, fmt.Errorf("At most %d topics can be subscribed to", maxTopics)
+	}
+	return topics, nil
+}
+
+func resolveTopic(requested string, userID uuid.UUID) (string, error) {
+	if requested == "notifications" {
+		return NotificationsTopic(userID), nil
+	}
+	parts := strings.Split(requested, ":")
+	if len(parts) == 3 && parts[0] == "post" {
+		postID, err := uuid.Parse(parts[1])
+		if err != nil {
+			return "", fmt.Errorf("Invalid post ID in topic %q", requested)
+		}
+		switch parts[2] {
+		case "comments":
+			return PostCommentsTopic(postID), nil
+		case "likes":
+			return PostLikesTopic(postID), nil
+		}
+	}
+	return "", fmt.Errorf("Unknown topic %q", requested)
+}
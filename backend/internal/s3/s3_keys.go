@@ -0,0 +1,26 @@
+package s3
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// VerificationRoot holds every user's ID documents. Only the user and admins reviewing
+// verification requests may read them.
+const VerificationRoot = "verification/"
+
+// Returns where a user's uploads through the content routes go. Users own the keys under it.
+func ContentPrefix(userID uuid.UUID) string {
+	return "content/" + userID.String() + "/"
+}
+
+// Returns where a user's ID documents for verification requests go.
+func VerificationPrefix(userID uuid.UUID) string {
+	return VerificationRoot + userID.String() + "/"
+}
+
+// Reports whether key is an ID document for a verification request.
+func IsVerificationKey(key string) bool {
+	return strings.HasPrefix(key, VerificationRoot)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"inside-athletics/internal/audit"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humafiber"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// humaContext is embedded under another name, as huma.Context has a Context method.
+type humaContext = huma.Context
+
+// auditContext copies the response body as the handler writes it, so creates can be audited by
+// the ID they respond with.
+type auditContext struct {
+	humaContext
+	body bytes.Buffer
+}
+
+func (c *auditContext) BodyWriter() io.Writer {
+	return io.MultiWriter(c.humaContext.BodyWriter(), &c.body)
+}
+
+// Unwrap lets humafiber.Unwrap reach the fiber context underneath.
+func (c *auditContext) Unwrap() huma.Context {
+	return c.humaContext
+}
+
+// AuditHumaMiddleware writes an audit log entry for every successful request to an operation in
+// audit.Operations, with the resource's state before and after. It runs after
+// PermissionHumaMiddleware, so denied requests aren't recorded. Failing to write an entry is
+// logged but doesn't fail the request, which has already been handled.
+func AuditHumaMiddleware(db *gorm.DB) func(huma.Context, func(huma.Context)) {
+	store := audit.NewStore(db, audit.LoadConfigFromEnv())
+	return func(ctx huma.Context, next func(huma.Context)) {
+		op := ctx.Operation()
+		if op == nil {
+			next(ctx)
+			return
+		}
+		target, ok := audit.Operations[op.Method+" "+op.Path]
+		if ok {
+			target, ok = target.Resolve(ctx.Param)
+		}
+		if !ok {
+			next(ctx)
+			return
+		}
+
+		var resourceID *uuid.UUID
+		var before json.RawMessage
+		if target.IDParam != "" {
+			if id, err := uuid.Parse(ctx.Param(target.IDParam)); err == nil {
+				resourceID = &id
+				if before, err = store.Snapshot(ctx.Context(), target, id); err != nil {
+					slog.ErrorContext(ctx.Context(), "Failed to snapshot audited resource", "error", err, "resource", target.Resource)
+				}
+			}
+		}
+
+		recorded := &auditContext{humaContext: ctx}
+		next(recorded)
+		if recorded.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		var actorID *uuid.UUID
+		if principal, ok := utils.PrincipalFromContext(ctx.Context()); ok {
+			actorID = &principal.UserID
+			if audit.AuthoredBy(before, target.AuthorField, principal.UserID) {
+				return
+			}
+		}
+		if resourceID == nil {
+			resourceID = respondedID(recorded.body.Bytes())
+		}
+		if err := writeAuditEntry(ctx, store, target, resourceID, actorID, before, recorded.Status()); err != nil {
+			slog.ErrorContext(ctx.Context(), "Failed to write audit log entry", "error", err, "path", ctx.URL().Path)
+		}
+	}
+}
+
+func writeAuditEntry(ctx huma.Context, store *audit.Store, target audit.Target, resourceID, actorID *uuid.UUID, before json.RawMessage, status int) error {
+	var after json.RawMessage
+	if resourceID != nil {
+		var err error
+		if after, err = store.Snapshot(ctx.Context(), target, *resourceID); err != nil {
+			return err
+		}
+	}
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		return err
+	}
+	rawChanges, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	fiberCtx := humafiber.Unwrap(ctx)
+	return store.Record(ctx.Context(), &models.AuditLog{
+		ActorID:      actorID,
+		Action:       audit.ActionFor(ctx.Method(), target),
+		Method:       ctx.Method(),
+		Path:         ctx.URL().Path,
+		ResourceType: target.Resource,
+		ResourceID:   resourceID,
+		Changes:      rawChanges,
+		Status:       status,
+		// fiber reuses its buffers once the request is done, so both are copied
+		RequestID: strings.Clone(fmt.Sprint(fiberCtx.Locals("requestid"))),
+		IP:        strings.Clone(fiberCtx.IP()),
+	})
+}
+
+// respondedID is the top-level "id" of a JSON response body, if it has one.
+func respondedID(body []byte) *uuid.UUID {
+	var response struct {
+		ID uuid.UUID `json:"id"`
+	}
+	if json.Unmarshal(body, &response) != nil || response.ID == uuid.Nil {
+		return nil
+	}
+	return &response.ID
+}
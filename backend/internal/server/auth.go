@@ -1,79 +1,69 @@
 package server
 
 import (
-	"context"
+	"errors"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 
-	"github.com/MicahParks/keyfunc/v3"
+	"inside-athletics/internal/utils"
+
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-// contextKey is a private type to avoid collisions in context keys.
-type contextKey string
-
-// AuthMiddleware verifies the JWT, injects user ID into context, and returns an error response when auth fails.
-func AuthMiddleware(c *fiber.Ctx) error {
-	env := os.Getenv("APP_ENV")
-	var jwksURL string
-	if env == "production" {
-		jwksURL = "https://zhhniddxrmfqqracjrlc.supabase.co/auth/v1/.well-known/jwks.json"
-	} else {
-		jwksURL = "http://localhost:54321/auth/v1/.well-known/jwks.json"
-	}
+// AuthMiddleware returns a handler that verifies the JWT with the given verifier, stores the
+// caller's utils.Principal in the user context, and returns an error response when auth fails. A 503 is returned when no
+// signing keys could be loaded, so clients can tell an outage apart from a bad token.
+func AuthMiddleware(verifier TokenVerifier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authHeader := c.Get("Authorization")
+		if authHeader == "" {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Authorization header not in request",
+			})
+		}
 
-	bgContext := context.Background()
+		headerComponents := strings.Split(authHeader, " ")
+		if len(headerComponents) != 2 || headerComponents[0] != "Bearer" {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Bearer not included in Authorization header",
+			})
+		}
 
-	key, err := keyfunc.NewDefaultCtx(bgContext, []string{jwksURL})
-	if err != nil {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unable to Verify JWT",
-		})
-	}
+		token := headerComponents[1]
 
-	authHeader := c.Get("Authorization")
-	if authHeader == "" {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization header not in request",
-		})
-	}
+		claims, err := verifier.Verify(c.UserContext(), token)
+		if err != nil {
+			log.Print(err)
+			if errors.Is(err, ErrKeysUnavailable) {
+				return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{
+					"error": "Unable to load signing keys",
+				})
+			}
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Token is not valid",
+			})
+		}
 
-	headerComponents := strings.Split(authHeader, " ")
-	if len(headerComponents) != 2 || headerComponents[0] != "Bearer" {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Bearer not included in Authorization header",
-		})
-	}
+		userID, err := uuid.Parse(claims.Subject)
+		if err != nil {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Token subject is not a valid user ID",
+			})
+		}
 
-	token := headerComponents[1]
+		principal := &utils.Principal{
+			UserID:     userID,
+			Email:      claims.Email,
+			SessionID:  claims.SessionID,
+			AuthMethod: claims.AuthMethod(),
+		}
+		if claims.ExpiresAt != nil {
+			principal.ExpiresAt = claims.ExpiresAt.Time
+		}
 
-	parsed, parseErr := jwt.Parse(token, key.Keyfunc, jwt.WithValidMethods([]string{"ES256"}))
-	if parseErr != nil || !parsed.Valid {
-		log.Print(parseErr)
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Token is not valid",
-		})
+		c.SetUserContext(utils.WithPrincipal(c.UserContext(), principal))
+		return c.Next()
 	}
-
-	claims, ok := parsed.Claims.(jwt.MapClaims)
-	if !ok {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Unable to extract claims",
-		})
-	}
-
-	userID, ok := claims["sub"].(string)
-	if !ok {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Unable to extract user ID",
-		})
-	}
-
-	c.Locals("user_id", userID)
-	ctx := context.WithValue(c.UserContext(), contextKey("user_id"), userID)
-	c.SetUserContext(ctx)
-	return c.Next()
 }
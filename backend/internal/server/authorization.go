@@ -1,13 +1,14 @@
 package server
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
-	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
+	"inside-athletics/internal/utils"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
@@ -19,249 +20,184 @@ var (
 	errUnsupportedResource = errors.New("unsupported resource for ownership check")
 )
 
-var resourceByPathPrefix = map[string]string{
-	"user/tag":      "tagfollow",
-	"user/sport":    "sportfollow",
-	"user/college":  "collegefollow",
-	"post/premium":  "premiumpost",
-	"posts/premium": "premiumpost",
-}
-
-func PermissionHumaMiddleware(api huma.API, db *gorm.DB) func(huma.Context, func(huma.Context)) {
+// PermissionHumaMiddleware enforces the access each operation declared with utils.Requires,
+// against the user's grants in cache. Operations that didn't declare any are reads, which any
+// caller may make; CheckAccessDeclared makes sure of that at startup. Banned and suspended users
+// are stopped before any of that, by the account status cached with their grants.
+func PermissionHumaMiddleware(api huma.API, db *gorm.DB, cache *permcache.Cache) func(huma.Context, func(huma.Context)) {
 	return func(ctx huma.Context, next func(huma.Context)) {
-		switch ctx.Method() {
-		case http.MethodGet, http.MethodHead, http.MethodOptions:
-			next(ctx)
-			return
+		principal, ok := utils.PrincipalFromContext(ctx.Context())
+		// grants stay nil for users that don't exist yet, who can only call routes that need no
+		// permission, like signing up
+		var grants *permcache.Grants
+		if ok {
+			var err error
+			grants, err = cache.Get(ctx.Context(), principal.UserID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				_ = huma.WriteErr(api, ctx, http.StatusInternalServerError, "Unable to check permissions")
+				return
+			}
+			if msg := grants.Restriction(isRead(ctx.Method())); msg != "" {
+				_ = huma.WriteErr(api, ctx, http.StatusForbidden, msg)
+				return
+			}
 		}
 
-		path := ctx.URL().Path
-		if strings.HasPrefix(path, "/docs") || strings.HasPrefix(path, "/openapi.yaml") || path == "/" {
+		access, declared := utils.AccessOf(ctx.Operation())
+		if !declared {
 			next(ctx)
 			return
 		}
-
-		userID, ok := getUserIDFromContext(ctx.Context())
-		if !ok || userID == "" {
+		if !ok {
 			_ = huma.WriteErr(api, ctx, http.StatusUnauthorized, "User not authenticated")
 			return
 		}
-
-		parsedUserID, err := uuid.Parse(userID)
-		if err != nil {
-			_ = huma.WriteErr(api, ctx, http.StatusUnauthorized, "Invalid user ID")
-			return
-		}
-
-		action, resource := resolveResourceAndAction(ctx.Method(), path, userID, ctx.Param("id"))
-		if action == "" || resource == "" {
-			next(ctx)
-			return
-		}
-		if resource == "user" && action == models.PermissionCreate {
+		if access.Action == "" {
 			next(ctx)
 			return
 		}
-		// PATCH/PUT /api/v1/user updates the authenticated user's own profile.
-		// Allow this even when role_permissions does not explicitly seed user:update_own.
-		if resource == "user" && action == models.PermissionUpdateOwn {
-			next(ctx)
+		if grants == nil {
+			_ = huma.WriteErr(api, ctx, http.StatusUnauthorized, "User not found")
 			return
 		}
 
-		// DELETE /api/v1/user/tag/tag/{tag_id} unfollows by tag id (not tag_follow row id).
-		// Ownership is enforced in the handler via current user + tag_id; require delete_own on tagfollow.
-		if resource == "tagfollow" && action == models.PermissionDelete && strings.Contains(path, "/user/tag/tag/") {
-			allowed, status, msg := authorizeByPermission(db, parsedUserID, models.PermissionDeleteOwn, resource)
-			if !allowed {
-				_ = huma.WriteErr(api, ctx, status, msg)
-				return
-			}
-			next(ctx)
-			return
-		}
-
-		// DELETE /api/v1/user/college/{college_id} unfollows by college id (not college_follow row id).
-		// Ownership is enforced in the handler via current user + college_id; require delete_own on collegefollow.
-		if resource == "collegefollow" && action == models.PermissionDelete {
-			allowed, status, msg := authorizeByPermission(db, parsedUserID, models.PermissionDeleteOwn, resource)
-			if !allowed {
-				_ = huma.WriteErr(api, ctx, status, msg)
-				return
-			}
-			next(ctx)
-			return
-		}
-
-		if (action == models.PermissionUpdate || action == models.PermissionDelete) &&
-			(resource == "post" || resource == "comment" || resource == "tagfollow" || resource == "premiumpost" || resource == "sportfollow" || resource == "collegefollow") &&
-			ctx.Param("id") != "" {
-			owned, err := IsOwnerOfResource(db, parsedUserID, ctx.Param("id"), resource)
-			if err != nil {
-				status := http.StatusInternalServerError
-				if errors.Is(err, errInvalidResourceID) || errors.Is(err, errUnsupportedResource) {
-					status = http.StatusBadRequest
-				}
-				_ = huma.WriteErr(api, ctx, status, err.Error())
-				return
-			}
-			if owned {
-				if action == models.PermissionDelete {
-					action = models.PermissionDeleteOwn
-				} else {
-					action = models.PermissionUpdateOwn
-				}
-			}
-		}
-
-		allowed, status, msg := authorizeByPermission(db, parsedUserID, action, resource)
+		allowed, status, msg := authorize(db, grants, principal.UserID, access, access.ResourceID(ctx))
 		if !allowed {
 			_ = huma.WriteErr(api, ctx, status, msg)
 			return
 		}
-
 		next(ctx)
 	}
 }
 
-func authorizeByPermission(db *gorm.DB, userID uuid.UUID, action models.PermissionAction, resource string) (bool, int, string) {
-	authDB := NewAuthorizationDB(db)
-
-	if err := authDB.UserExists(userID); err != nil {
-		return false, http.StatusUnauthorized, "User not found"
+// authorize checks access against the user's grants, on the resource with the ID in the path.
+// Owners of the resource need the _own action, and roles scoped to the resource's sports and
+// colleges are tried after the user's other roles.
+func authorize(db *gorm.DB, grants *permcache.Grants, userID uuid.UUID, access utils.Access, resourceID string) (bool, int, string) {
+	action := access.Action
+	var id uuid.UUID
+	if access.Owner != nil || access.Scopes != nil {
+		parsed, err := uuid.Parse(resourceID)
+		if err != nil {
+			return false, http.StatusBadRequest, errInvalidResourceID.Error()
+		}
+		id = parsed
+	}
+	if access.Owner != nil || access.KeyOwner != nil {
+		var owned bool
+		var err error
+		if access.Owner != nil {
+			owned, err = access.Owner(db, userID, id)
+		} else {
+			owned, err = access.KeyOwner(db, userID, resourceID)
+		}
+		if err != nil {
+			return false, http.StatusInternalServerError, "unable to check ownership"
+		}
+		if owned {
+			action = access.OwnAction()
+		}
 	}
 
-	hasPermission, err := authDB.UserHasPermission(userID, action, resource)
+	if grants.Allows(action, access.Resource) {
+		return true, 0, ""
+	}
+	if access.AnyScope {
+		if _, scopes := grants.Scopes(action, access.Resource); len(scopes) > 0 {
+			return true, 0, ""
+		}
+	}
+	if access.Scopes == nil {
+		return false, http.StatusForbidden, "Insufficient permissions"
+	}
+	// roles scoped to a sport or college only count when the resource is in their scope
+	scopes, err := access.Scopes(db, id)
 	if err != nil {
 		return false, http.StatusInternalServerError, "Unable to check permissions"
 	}
-
-	if !hasPermission {
+	if len(scopes) == 0 || !grants.Allows(action, access.Resource, scopes...) {
 		return false, http.StatusForbidden, "Insufficient permissions"
 	}
-
 	return true, 0, ""
 }
 
-func IsOwnerOfResource(db *gorm.DB, userID uuid.UUID, resourceID, resource string) (bool, error) {
-	parsedResourceID, err := uuid.Parse(resourceID)
-	if err != nil {
-		return false, errInvalidResourceID
-	}
-
-	var count int64
-	switch resource {
-	case "post":
-		err = db.Table("posts").
-			Where("id = ? AND author_id = ?", parsedResourceID, userID).
-			Count(&count).Error
-	case "comment":
-		err = db.Table("comments").
-			Where("id = ? AND user_id = ?", parsedResourceID, userID).
-			Count(&count).Error
-	case "tagfollow":
-		err = db.Table("tag_follows").
-			Where("id = ? AND user_id = ?", parsedResourceID, userID).
-			Count(&count).Error
-	case "sportfollow":
-		err = db.Table("sport_follows").
-			Where("id = ? AND user_id = ?", parsedResourceID, userID).
-			Count(&count).Error
-	case "collegefollow":
-		err = db.Table("college_follows").
-			Where("id = ? AND user_id = ?", parsedResourceID, userID).
-			Count(&count).Error
-	case "premiumpost":
-		err = db.Table("premium_posts").
-			Where("id = ? AND author_id = ?", parsedResourceID, userID).
-			Count(&count).Error
-	default:
-		return false, errUnsupportedResource
+// mutatingMethods are the methods every operation must declare its access for.
+var mutatingMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// CheckAccessDeclared returns an error naming every operation registered on api that changes
+// something without declaring the access it requires, so a route can't go unchecked by mistake.
+func CheckAccessDeclared(api huma.API) error {
+	var undeclared []string
+	for path, item := range api.OpenAPI().Paths {
+		for _, method := range mutatingMethods {
+			op := operationFor(item, method)
+			if op == nil {
+				continue
+			}
+			if _, ok := utils.AccessOf(op); !ok {
+				undeclared = append(undeclared, method+" "+path)
+			}
+		}
 	}
-
-	if err != nil {
-		return false, fmt.Errorf("unable to check ownership: %w", err)
+	if len(undeclared) == 0 {
+		return nil
 	}
-	return count > 0, nil
-}
-
-func IsOwnerOfPostOrComment(db *gorm.DB, userID uuid.UUID, resourceID, resource string) (bool, error) {
-	return IsOwnerOfResource(db, userID, resourceID, resource)
+	slices.Sort(undeclared)
+	return fmt.Errorf("operations without declared access: %s", strings.Join(undeclared, ", "))
 }
 
-func resolveResourceAndAction(method, path, userID, pathID string) (models.PermissionAction, string) {
-	action := models.PermissionAction("")
+func operationFor(item *huma.PathItem, method string) *huma.Operation {
 	switch method {
 	case http.MethodPost:
-		action = models.PermissionCreate
-	case http.MethodPut, http.MethodPatch:
-		action = models.PermissionUpdate
+		return item.Post
+	case http.MethodPut:
+		return item.Put
+	case http.MethodPatch:
+		return item.Patch
 	case http.MethodDelete:
-		action = models.PermissionDelete
-	default:
-		return "", ""
-	}
-
-	resource := resolveResourceFromPath(path)
-	if resource == "" {
-		return "", ""
+		return item.Delete
 	}
+	return nil
+}
 
-	// Special-case: if the user is acting on their own resource, use the *_own permissions.
-	// PATCH/PUT /api/v1/user has no path id and always refers to the authenticated user.
-	if resource == "user" && (method == http.MethodPut || method == http.MethodPatch || method == http.MethodDelete) {
-		if (method == http.MethodPut || method == http.MethodPatch) && pathID == "" {
-			action = models.PermissionUpdateOwn
-			return action, resource
-		}
-		if userID != "" && pathID != "" && pathID == userID {
-			if method == http.MethodDelete {
-				action = models.PermissionDeleteOwn
-			} else {
-				action = models.PermissionUpdateOwn
-			}
-		}
+// isRead reports whether method only reads, which suspended users may still do.
+func isRead(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
 	}
-
-	return action, resource
+	return false
 }
 
-// change this to map so that you can just change the map if you need to update
-var resourceByPathSegment = map[string]string{
-	"user":          "user",
-	"users":         "user",
-	"post":          "post",
-	"posts":         "post",
-	"sport":         "sport",
-	"sports":        "sport",
-	"college":       "college",
-	"colleges":      "college",
-	"role":          "role",
-	"roles":         "role",
-	"permission":    "permission",
-	"permissions":   "permission",
-	"premium_post":  "premium_post",
-	"premium_posts": "premium_post",
+// resourceOwners resolve the owners of the resources IsOwnerOfResource can check.
+var resourceOwners = map[string]utils.OwnerFunc{
+	"post":          utils.AuthorOf(utils.PostsTable),
+	"premiumpost":   utils.AuthorOf(utils.PremiumPostsTable),
+	"comment":       utils.AuthorOf(utils.CommentsTable),
+	"tagfollow":     utils.OwnedBy("tag_follows", "user_id"),
+	"sportfollow":   utils.OwnedBy("sport_follows", "user_id"),
+	"collegefollow": utils.OwnedBy("college_follows", "user_id"),
 }
 
-func resolveResourceFromPath(path string) string {
-	path = strings.Trim(strings.TrimPrefix(path, "/api/v1/"), "/")
-	for prefix, resource := range resourceByPathPrefix {
-		if path == prefix || strings.HasPrefix(path, prefix+"/") {
-			return resource
-		}
+// IsOwnerOfResource reports whether the user owns the resource with resourceID, by the resource's
+// name. Routes declare their owners with utils.Access instead.
+func IsOwnerOfResource(db *gorm.DB, userID uuid.UUID, resourceID, resource string) (bool, error) {
+	parsedResourceID, err := uuid.Parse(resourceID)
+	if err != nil {
+		return false, errInvalidResourceID
+	}
+	owner, ok := resourceOwners[resource]
+	if !ok {
+		return false, errUnsupportedResource
 	}
 
-	segment := strings.SplitN(path, "/", 2)[0]
-	return resourceByPathSegment[segment]
+	owned, err := owner(db, userID, parsedResourceID)
+	if err != nil {
+		return false, fmt.Errorf("unable to check ownership: %w", err)
+	}
+	return owned, nil
 }
 
-func getUserIDFromContext(ctx context.Context) (string, bool) {
-	if v, ok := ctx.Value("user_id").(string); ok && v != "" {
-		return v, true
-	}
-	if v, ok := ctx.Value(contextKey("user_id")).(string); ok && v != "" {
-		return v, true
-	}
-	return "", false
+func IsOwnerOfPostOrComment(db *gorm.DB, userID uuid.UUID, resourceID, resource string) (bool, error) {
+	return IsOwnerOfResource(db, userID, resourceID, resource)
 }
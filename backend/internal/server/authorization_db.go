@@ -1,7 +1,12 @@
 package server
 
 import (
+	"context"
+
+	"inside-athletics/internal/handlers/sanction"
 	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
+	"inside-athletics/internal/utils"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -21,15 +26,47 @@ func (a *AuthorizationDB) UserExists(id uuid.UUID) error {
 	return a.db.Select("id").First(&user, "id = ?", id).Error
 }
 
-func (a *AuthorizationDB) UserHasPermission(userID uuid.UUID, action models.PermissionAction, resource string) (bool, error) {
-	var count int64
-	err := a.db.Table("user_roles").
+// UserHasPermission reports whether the union of the user's roles grants action on resource. Scoped
+// roles only count for resources in one of scopes.
+func (a *AuthorizationDB) UserHasPermission(userID uuid.UUID, action models.PermissionAction, resource string, scopes ...models.RoleScope) (bool, error) {
+	return utils.HasPermission(a.db, userID, action, resource, scopes...)
+}
+
+// UserGrants loads every permission the user's roles give them and their account status, for the
+// permission cache. It returns gorm.ErrRecordNotFound for users that don't exist.
+func (a *AuthorizationDB) UserGrants(ctx context.Context, userID uuid.UUID) (*permcache.Grants, error) {
+	if err := a.db.WithContext(ctx).Select("id").First(&models.User{}, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Action    models.PermissionAction
+		Resource  string
+		ScopeType *models.RoleScopeType
+		ScopeID   *uuid.UUID
+	}
+	if err := a.db.WithContext(ctx).Table("user_roles").
+		Select("p.action, p.resource, user_roles.scope_type, user_roles.scope_id").
 		Joins("JOIN role_permissions rp ON rp.role_id = user_roles.role_id").
 		Joins("JOIN permissions p ON p.id = rp.permission_id").
-		Where("user_roles.user_id = ? AND p.action = ? AND p.resource = ?", userID, action, resource).
-		Count(&count).Error
+		Where("user_roles.user_id = ?", userID).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	grants := &permcache.Grants{Grants: make([]permcache.Grant, 0, len(rows))}
+	for _, row := range rows {
+		grant := permcache.Grant{Action: row.Action, Resource: row.Resource}
+		if row.ScopeType != nil && row.ScopeID != nil {
+			grant.Scope = &models.RoleScope{Type: *row.ScopeType, ID: *row.ScopeID}
+		}
+		grants.Grants = append(grants.Grants, grant)
+	}
+
+	state, err := sanction.NewSanctionDB(a.db.WithContext(ctx)).GetAccountState(userID)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	return count > 0, nil
+	grants.Status, grants.StatusUntil = state.Status, state.Until
+	return grants, nil
 }
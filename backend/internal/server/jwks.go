@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/time/rate"
+)
+
+// Env keys for JWKS config.
+const (
+	EnvJWKSURL                  = "SUPABASE_JWKS_URL"
+	EnvJWKSRefreshSec           = "JWKS_REFRESH_INTERVAL_SECONDS"
+	EnvJWKSUnknownKIDRefreshSec = "JWKS_UNKNOWN_KID_REFRESH_SECONDS"
+)
+
+const (
+	productionJWKSURL = "https://zhhniddxrmfqqracjrlc.supabase.co/auth/v1/.well-known/jwks.json"
+	localJWKSURL      = "http://localhost:54321/auth/v1/.well-known/jwks.json"
+
+	DefaultJWKSRefreshInterval           = time.Hour
+	DefaultJWKSUnknownKIDRefreshInterval = 5 * time.Minute
+	DefaultJWKSHTTPTimeout               = 10 * time.Second
+)
+
+// JWKSConfig controls where signing keys are fetched from and how often they are refreshed.
+type JWKSConfig struct {
+	URL string
+	// RefreshInterval is how often the key set is refreshed in the background.
+	RefreshInterval time.Duration
+	// UnknownKIDRefreshInterval is the minimum time between refreshes triggered by a token with an unknown kid.
+	UnknownKIDRefreshInterval time.Duration
+	HTTPTimeout               time.Duration
+}
+
+// LoadJWKSConfigFromEnv returns the JWKS config from env, falling back to the Supabase URL for APP_ENV.
+func LoadJWKSConfigFromEnv() JWKSConfig {
+	cfg := JWKSConfig{
+		URL:                       os.Getenv(EnvJWKSURL),
+		RefreshInterval:           durationFromEnv(EnvJWKSRefreshSec, DefaultJWKSRefreshInterval),
+		UnknownKIDRefreshInterval: durationFromEnv(EnvJWKSUnknownKIDRefreshSec, DefaultJWKSUnknownKIDRefreshInterval),
+		HTTPTimeout:               DefaultJWKSHTTPTimeout,
+	}
+	if cfg.URL == "" {
+		if os.Getenv("APP_ENV") == "production" {
+			cfg.URL = productionJWKSURL
+		} else {
+			cfg.URL = localJWKSURL
+		}
+	}
+	return cfg
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	if s := os.Getenv(key); s != "" {
+		if sec, err := strconv.Atoi(s); err == nil && sec > 0 {
+			return time.Duration(sec) * time.Second
+		}
+	}
+	return fallback
+}
+
+// KeySet is a long-lived, shared cache of JWKS signing keys. Keys are refreshed in the
+// background and whenever a token references a kid that is not in the cache yet.
+type KeySet struct {
+	keyfunc keyfunc.Keyfunc
+}
+
+// NewKeySet creates a KeySet for the given config. The first fetch is allowed to fail so the
+// server can start while the identity provider is down; ctx ends the background refresh.
+func NewKeySet(ctx context.Context, cfg JWKSConfig) (*KeySet, error) {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = DefaultJWKSRefreshInterval
+	}
+	if cfg.UnknownKIDRefreshInterval <= 0 {
+		cfg.UnknownKIDRefreshInterval = DefaultJWKSUnknownKIDRefreshInterval
+	}
+	if cfg.HTTPTimeout <= 0 {
+		cfg.HTTPTimeout = DefaultJWKSHTTPTimeout
+	}
+
+	kf, err := keyfunc.NewDefaultOverrideCtx(ctx, []string{cfg.URL}, keyfunc.Override{
+		HTTPTimeout:     cfg.HTTPTimeout,
+		RefreshInterval: cfg.RefreshInterval,
+		// Also bounds the refresh request itself, so it has to be at least the HTTP timeout.
+		RateLimitWaitMax:  cfg.HTTPTimeout,
+		RefreshUnknownKID: rate.NewLimiter(rate.Every(cfg.UnknownKIDRefreshInterval), 1),
+		RefreshErrorHandlerFunc: func(u string) func(ctx context.Context, err error) {
+			return func(ctx context.Context, err error) {
+				slog.ErrorContext(ctx, "Failed to refresh JWKS", "error", err, "url", u)
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{keyfunc: kf}, nil
+}
+
+// Keyfunc resolves the verification key for a token, refreshing the cache on an unknown kid.
+func (k *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	return k.keyfunc.Keyfunc(token)
+}
+
+// Available reports whether at least one signing key is currently cached.
+func (k *KeySet) Available(ctx context.Context) bool {
+	keys, err := k.keyfunc.Storage().KeyReadAll(ctx)
+	return err == nil && len(keys) > 0
+}
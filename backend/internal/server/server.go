@@ -3,18 +3,25 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"inside-athletics/internal/events"
+	"inside-athletics/internal/handlers/auditlog"
 	"inside-athletics/internal/handlers/college"
 	"inside-athletics/internal/handlers/collegefollow"
 	"inside-athletics/internal/handlers/comment"
 	"inside-athletics/internal/handlers/comment_like"
 	"inside-athletics/internal/handlers/content"
+	"inside-athletics/internal/handlers/email"
+	"inside-athletics/internal/handlers/feed"
 	"inside-athletics/internal/handlers/health"
 	"inside-athletics/internal/handlers/media"
+	"inside-athletics/internal/handlers/notification"
 	"inside-athletics/internal/handlers/permission"
 	"inside-athletics/internal/handlers/post"
 	"inside-athletics/internal/handlers/post_like"
 	premiumpost "inside-athletics/internal/handlers/premium_post"
+	"inside-athletics/internal/handlers/report"
 	"inside-athletics/internal/handlers/role"
+	"inside-athletics/internal/handlers/sanction"
 	"inside-athletics/internal/handlers/sport"
 	"inside-athletics/internal/handlers/sportfollow"
 	"inside-athletics/internal/handlers/stripe"
@@ -23,7 +30,12 @@ import (
 	"inside-athletics/internal/handlers/tagfollow"
 	"inside-athletics/internal/handlers/tagpost"
 	"inside-athletics/internal/handlers/user"
+	"inside-athletics/internal/handlers/userblock"
 	"inside-athletics/internal/handlers/utility"
+	"inside-athletics/internal/handlers/verification"
+	"inside-athletics/internal/mailer"
+	"inside-athletics/internal/permcache"
+	"inside-athletics/internal/realtime"
 	"inside-athletics/internal/s3"
 	"strings"
 
@@ -45,14 +57,38 @@ import (
 type App struct {
 	Server *fiber.App
 	Api    huma.API
+	// Realtime carries realtime messages between instances. Its Run must be started for any
+	// stream on this instance to receive messages.
+	Realtime *realtime.PGBroker
+	// Mailer sends every email. Digests only go out while a DigestJob built from it is running,
+	// and reply alerts while its RunReplyAlerts is.
+	Mailer *mailer.Mailer
 }
 
 type RouteFN func(api huma.API, db *gorm.DB)
 
 // CreateApp initializes the Fiber app and returns the assembled App (server + Huma API).
-func CreateApp(db *gorm.DB) *App {
+// Every request outside the public paths is authenticated with the given verifier.
+func CreateApp(db *gorm.DB, verifier TokenVerifier) *App {
+	app := NewApp(verifier)
+	bus, grants := CreateRoutes(db, app.Api)
+	app.Mailer = mailer.NewFromEnv(db)
+	app.Mailer.Subscribe(bus)
+	stripe.Route(app.Api, db)
+	stripe.RegisterWebhookRoute(app.Server, db, app.Mailer, grants)
+	email.RegisterUnsubscribeRoute(app.Server, db)
+
+	realtimeCfg := realtime.LoadConfigFromEnv()
+	hub := realtime.NewHub(realtimeCfg)
+	app.Realtime = realtime.NewPGBroker(db, hub)
+	realtime.Bridge(bus, db, app.Realtime)
+	realtime.RegisterRoute(app.Server, hub, grants, realtimeCfg)
+	return app
+}
 
-	router := setupApp()
+// NewApp builds the Fiber server and Huma API with all middleware but no routes.
+func NewApp(verifier TokenVerifier) *App {
+	router := setupApp(verifier)
 	config := huma.DefaultConfig("Inside Athletics API", "1.0.0")
 	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
 		"Authorization": {
@@ -68,24 +104,37 @@ func CreateApp(db *gorm.DB) *App {
 	}
 
 	var api = humafiber.New(router, config)
-	CreateRoutes(db, api)
-	stripe.Route(api, db)
-	stripe.RegisterWebhookRoute(router, db)
 	return &App{
 		Server: router,
 		Api:    api,
 	}
 }
 
-// CreateRoutes registers all core route groups on the given Huma API (stripe excluded).
-func CreateRoutes(db *gorm.DB, api huma.API) {
-	api.UseMiddleware(PermissionHumaMiddleware(api, db))
-	routeGroups := [...]RouteFN{survey.Route, media.Route, health.Route, sport.Route, role.Route, permission.Route, collegefollow.Route, tagfollow.Route, sportfollow.Route, tagpost.Route, comment.Route, comment_like.Route, post_like.Route, comment.Route}
+// CreateRoutes registers all core route groups on the given Huma API (stripe excluded) and returns
+// the event bus their services publish on, and the cache of users' permissions that services
+// changing them invalidate.
+func CreateRoutes(db *gorm.DB, api huma.API) (*events.Bus, *permcache.Cache) {
+	grantsCfg := permcache.LoadConfigFromEnv()
+	grants := permcache.New(NewAuthorizationDB(db).UserGrants, permcache.NewMemory(grantsCfg.MaxEntries), grantsCfg)
+	api.UseMiddleware(PermissionHumaMiddleware(api, db, grants))
+	api.UseMiddleware(AuditHumaMiddleware(db))
+	routeGroups := [...]RouteFN{survey.Route, media.Route, sport.Route, collegefollow.Route, tagfollow.Route, sportfollow.Route, tagpost.Route, email.Route, userblock.Route, auditlog.Route}
 	for _, fn := range routeGroups {
 		fn(api, db)
 	}
 
 	utility.Route(api, db)
+	health.Route(api, db, grants)
+	role.Route(api, db, grants)
+	permission.Route(api, db, grants)
+	report.Route(api, db, grants)
+	sanction.Route(api, db, grants)
+
+	bus := events.NewBus()
+	notification.Route(api, db, bus)
+	comment.Route(api, db, bus)
+	comment_like.Route(api, db, bus)
+	post_like.Route(api, db, bus)
 
 	var s3Svc *s3.Service
 	if s3Cfg, ok := s3.LoadConfigFromEnv(); ok {
@@ -95,15 +144,18 @@ func CreateRoutes(db *gorm.DB, api huma.API) {
 	}
 
 	college.Route(api, db, s3Svc)
-	user.Route(api, db, s3Svc)
-	post.Route(api, db, s3Svc)
+	user.Route(api, db, s3Svc, grants)
+	verification.Route(api, db, s3Svc)
+	post.Route(api, db, s3Svc, bus)
 	tag.Route(api, db, s3Svc)
-	content.Route(api, db, s3Svc)
+	content.Route(api, db, s3Svc, grants)
 	premiumpost.Route(api, db, s3Svc)
+	feed.Route(api, db, s3Svc)
+	return bus, grants
 }
 
 // setupApp initializes the Fiber app with middleware and returns the configured instance.
-func setupApp() *fiber.App {
+func setupApp(verifier TokenVerifier) *fiber.App {
 	app := fiber.New(fiber.Config{
 		JSONEncoder: json.Marshal,
 		JSONDecoder: json.Unmarshal,
@@ -114,14 +166,19 @@ func setupApp() *fiber.App {
 		Format: "[${time}] ${ip}:${port} ${pid} ${locals:requestid} ${status} - ${latency} ${method} ${path}\n",
 	}))
 
-	app.Use(skip.New(AuthMiddleware, func(ctx *fiber.Ctx) bool {
+	app.Use(skip.New(AuthMiddleware(verifier), func(ctx *fiber.Ctx) bool {
 		return strings.HasPrefix(ctx.Path(), "/docs") ||
 			strings.HasPrefix(ctx.Path(), "/openapi.yaml") ||
 			ctx.Path() == "/" ||
-			ctx.Path() == "/api/v1/stripe/webhook"
+			ctx.Path() == "/api/v1/stripe/webhook" ||
+			ctx.Path() == email.UnsubscribePath
 	}))
 	app.Use(favicon.New())
 	app.Use(compress.New(compress.Config{
+		// compressing would buffer the realtime stream
+		Next: func(ctx *fiber.Ctx) bool {
+			return ctx.Path() == realtime.StreamPath
+		},
 		Level: compress.LevelBestSpeed,
 	}))
 
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Env keys for token validation config.
+const (
+	EnvJWTIssuer   = "SUPABASE_JWT_ISSUER"
+	EnvJWTAudience = "SUPABASE_JWT_AUDIENCE"
+)
+
+const (
+	productionJWTIssuer = "https://zhhniddxrmfqqracjrlc.supabase.co/auth/v1"
+	localJWTIssuer      = "http://127.0.0.1:54321/auth/v1"
+	DefaultJWTAudience  = "authenticated"
+)
+
+// ErrKeysUnavailable is returned when a token can't be checked because no signing keys are loaded.
+var ErrKeysUnavailable = errors.New("signing keys unavailable")
+
+// Claims are the access token claims issued by Supabase auth.
+type Claims struct {
+	jwt.RegisteredClaims
+	Email     string                `json:"email,omitempty"`
+	Role      string                `json:"role,omitempty"`
+	SessionID string                `json:"session_id,omitempty"`
+	AMR       []AuthMethodReference `json:"amr,omitempty"`
+}
+
+// AuthMethodReference is one entry of the amr claim, recording how the session was authenticated.
+type AuthMethodReference struct {
+	Method    string `json:"method"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// AuthMethod returns the method the session was first authenticated with, if known.
+func (c *Claims) AuthMethod() string {
+	if len(c.AMR) == 0 {
+		return ""
+	}
+	return c.AMR[0].Method
+}
+
+// VerifierConfig is the issuer and audience a token must carry. An empty value skips that check.
+type VerifierConfig struct {
+	Issuer   string
+	Audience string
+}
+
+// LoadVerifierConfigFromEnv returns the verifier config from env, falling back to the Supabase issuer for APP_ENV.
+func LoadVerifierConfigFromEnv() VerifierConfig {
+	cfg := VerifierConfig{
+		Issuer:   os.Getenv(EnvJWTIssuer),
+		Audience: os.Getenv(EnvJWTAudience),
+	}
+	if cfg.Issuer == "" {
+		if os.Getenv("APP_ENV") == "production" {
+			cfg.Issuer = productionJWTIssuer
+		} else {
+			cfg.Issuer = localJWTIssuer
+		}
+	}
+	if cfg.Audience == "" {
+		cfg.Audience = DefaultJWTAudience
+	}
+	return cfg
+}
+
+// TokenVerifier verifies a bearer token and returns its claims.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*Claims, error)
+}
+
+// KeyProvider resolves the public key used to verify a token.
+type KeyProvider interface {
+	Keyfunc(token *jwt.Token) (any, error)
+	// Available reports whether any keys are loaded, so outages can be told apart from bad tokens.
+	Available(ctx context.Context) bool
+}
+
+// SupabaseVerifier verifies ES256 tokens signed by Supabase auth.
+type SupabaseVerifier struct {
+	keys    KeyProvider
+	options []jwt.ParserOption
+}
+
+func NewSupabaseVerifier(keys KeyProvider, cfg VerifierConfig) *SupabaseVerifier {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"ES256"}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	return &SupabaseVerifier{keys: keys, options: options}
+}
+
+// Verify checks the token signature, expiry, issuer and audience.
+func (v *SupabaseVerifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, v.keys.Keyfunc, v.options...)
+	if err != nil || !parsed.Valid {
+		if !v.keys.Available(ctx) {
+			return nil, fmt.Errorf("%w: %v", ErrKeysUnavailable, err)
+		}
+		return nil, err
+	}
+	return claims, nil
+}
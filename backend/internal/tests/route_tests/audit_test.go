@@ -0,0 +1,96 @@
+package routeTests
+
+import (
+	"encoding/json"
+	"inside-athletics/internal/audit"
+	"inside-athletics/internal/handlers/auditlog"
+	"inside-athletics/internal/models"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func getAuditLogs(t *testing.T, testDB *TestDatabase, header string, query url.Values) []models.AuditLog {
+	t.Helper()
+	resp := testDB.API.Get("/api/v1/audit-logs/?"+query.Encode(), header)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 searching the audit log, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var result auditlog.GetAuditLogsResponse
+	DecodeTo(&result, resp)
+	return result.Entries
+}
+
+func TestPrivilegedChangesAreAudited(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	author, post := seedUserAndPost(t, testDB, "audit-author")
+	authorHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, []permissionSpec{
+		{Action: models.PermissionDeleteOwn, Resource: "post"},
+	}, author.ID)
+	admin := newCommentTestUser(uuid.New(), "audit-admin")
+	if err := testDB.DB.Create(&admin).Error; err != nil {
+		t.Fatalf("create admin: %v", err)
+	}
+	adminHeader := authHeaderWithPermissionsGivenUser(t, testDB.DB, []permissionSpec{
+		{Action: models.PermissionUpdate, Resource: "sport"},
+		{Action: models.PermissionDelete, Resource: "post"},
+		{Action: models.PermissionRead, Resource: "audit"},
+	}, admin.ID)
+
+	if resp := testDB.API.Patch("/api/v1/sport/"+SoccerID.String(), map[string]any{"name": "Football"}, adminHeader); resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 renaming the sport, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := testDB.API.Delete("/api/v1/post/"+post.ID.String(), adminHeader); resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 deleting the post, got %d: %s", resp.Code, resp.Body.String())
+	}
+	ownPost := models.Post{AuthorID: author.ID, SportID: &SoccerID, Title: "Mine", Content: "Deleting this myself"}
+	if err := testDB.DB.Create(&ownPost).Error; err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	if resp := testDB.API.Delete("/api/v1/post/"+ownPost.ID.String(), authorHeader); resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 deleting their own post, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	if resp := testDB.API.Get("/api/v1/audit-logs/", authorHeader); resp.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a user searching the audit log, got %d", resp.Code)
+	}
+
+	entries := getAuditLogs(t, testDB, adminHeader, url.Values{"actor_id": {admin.ID.String()}, "resource_type": {"sport"}})
+	if len(entries) != 1 || entries[0].Action != "update" || entries[0].ResourceID == nil || *entries[0].ResourceID != SoccerID {
+		t.Fatalf("expected one update to the sport, got %+v", entries)
+	}
+	var changes map[string]audit.Change
+	if err := json.Unmarshal(entries[0].Changes, &changes); err != nil {
+		t.Fatalf("decode changes: %v", err)
+	}
+	if len(changes) != 1 || changes["name"].Before != "Soccer" || changes["name"].After != "Football" {
+		t.Fatalf("expected only the name change, got %+v", changes)
+	}
+	if entries[0].RequestID == "" || entries[0].IP == "" {
+		t.Fatalf("expected the request ID and IP to be recorded, got %+v", entries[0])
+	}
+
+	entries = getAuditLogs(t, testDB, adminHeader, url.Values{"resource_type": {"post"}})
+	if len(entries) != 1 || entries[0].Action != "delete" || *entries[0].ResourceID != post.ID || *entries[0].ActorID != admin.ID {
+		t.Fatalf("expected only the moderator's delete to be audited, got %+v", entries)
+	}
+	if entries = getAuditLogs(t, testDB, adminHeader, url.Values{"from": {"2999-01-01T00:00:00Z"}}); len(entries) != 0 {
+		t.Fatalf("expected nothing from the future, got %d entries", len(entries))
+	}
+
+	if err := testDB.DB.Exec("UPDATE audit_logs SET action = 'create'").Error; err == nil {
+		t.Fatal("expected audit log entries to be immutable")
+	}
+	if err := testDB.DB.Exec("DELETE FROM audit_logs").Error; err == nil {
+		t.Fatal("expected audit log entries to only be deleted by pruning")
+	}
+	cfg := audit.DefaultConfig()
+	cfg.Retention = 1
+	if deleted, err := audit.NewStore(testDB.DB, cfg).Prune(t.Context()); err != nil || deleted != 2 {
+		t.Fatalf("expected pruning to delete both entries, got %d: %v", deleted, err)
+	}
+}
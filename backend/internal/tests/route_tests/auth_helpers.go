@@ -36,7 +36,7 @@ func seedUserWithRoleAndPermissions(t *testing.T, db *gorm.DB, roleName models.R
 	for _, perm := range perms {
 		ensurePermissionForRole(t, db, roleID, perm.Action, perm.Resource)
 	}
-	return userID, "Authorization: Bearer " + userID.String()
+	return userID, authHeaderFor(userID.String())
 }
 
 func authHeaderWithPermissions(t *testing.T, db *gorm.DB, perms []permissionSpec) string {
@@ -54,7 +54,7 @@ func authHeaderWithPermissionsGivenUserForRole(t *testing.T, db *gorm.DB, roleNa
 	for _, perm := range perms {
 		ensurePermissionForRole(t, db, roleID, perm.Action, perm.Resource)
 	}
-	return "Authorization: Bearer " + userID.String()
+	return authHeaderFor(userID.String())
 }
 
 func ensurePermissionForRole(t *testing.T, db *gorm.DB, roleID uuid.UUID, action models.PermissionAction, resource string) {
@@ -92,8 +92,9 @@ func assignRoleToUser(t *testing.T, db *gorm.DB, userID, roleID uuid.UUID) {
 		RoleID: roleID,
 	}
 	if err := db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "user_id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"role_id"}),
+		Columns:     []clause.Column{{Name: "user_id"}, {Name: "role_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "scope_id IS NULL"}}},
+		DoNothing:   true,
 	}).Create(&userRole).Error; err != nil {
 		t.Fatalf("failed to assign role to user: %v", err)
 	}
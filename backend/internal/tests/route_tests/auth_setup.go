@@ -0,0 +1,54 @@
+package routeTests
+
+import (
+	"io"
+	"net/http"
+
+	unitTests "inside-athletics/internal/tests/unit_tests"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Signs the tokens for every route test; the real auth middleware verifies them.
+var testIssuer = unitTests.NewTokenIssuer()
+
+// Returns an Authorization header with a valid token for the given user.
+func authHeaderFor(userID string, opts ...unitTests.TokenOption) string {
+	return "Authorization: Bearer " + testIssuer.Token(userID, opts...)
+}
+
+/*
+*
+Wraps the Fiber backed Huma API so test requests run through the full
+Fiber middleware stack (auth included). fiber.App.Test times out after
+1s by default, which is too tight for requests hitting the test DB.
+*/
+type fiberTestAPI struct {
+	huma.API
+	app *fiber.App
+}
+
+func (a fiberTestAPI) Adapter() huma.Adapter {
+	return fiberTestAdapter{Adapter: a.API.Adapter(), app: a.app}
+}
+
+type fiberTestAdapter struct {
+	huma.Adapter
+	app *fiber.App
+}
+
+func (a fiberTestAdapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resp, err := a.app.Test(r, -1)
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+	for k, v := range resp.Header {
+		for _, item := range v {
+			w.Header().Add(k, item)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
@@ -0,0 +1,42 @@
+package routeTests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	unitTests "inside-athletics/internal/tests/unit_tests"
+
+	"github.com/google/uuid"
+)
+
+func TestAuthMiddleware_RejectsBadTokens(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
+
+	otherIssuer := unitTests.NewTokenIssuer()
+	userID := uuid.NewString()
+
+	tests := []struct {
+		name   string
+		header []any
+		want   int
+	}{
+		{name: "valid token", header: []any{authHeaderFor(userID)}, want: http.StatusOK},
+		{name: "missing header", header: nil, want: http.StatusUnauthorized},
+		{name: "raw user id", header: []any{"Authorization: Bearer " + userID}, want: http.StatusUnauthorized},
+		{name: "expired token", header: []any{authHeaderFor(userID, unitTests.WithExpiry(time.Now().Add(-time.Minute)))}, want: http.StatusUnauthorized},
+		{name: "wrong issuer", header: []any{authHeaderFor(userID, unitTests.WithIssuer("https://evil.example.com/auth/v1"))}, want: http.StatusUnauthorized},
+		{name: "wrong audience", header: []any{authHeaderFor(userID, unitTests.WithAudience("anon"))}, want: http.StatusUnauthorized},
+		{name: "signed by another issuer", header: []any{"Authorization: Bearer " + otherIssuer.Token(userID)}, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		resp := api.Get("/api/v1/health/", tt.header...)
+		if resp.Code != tt.want {
+			t.Fatalf("%s: expected %d, got %d: %s", tt.name, tt.want, resp.Code, resp.Body.String())
+		}
+	}
+}
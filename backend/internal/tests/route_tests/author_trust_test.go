@@ -0,0 +1,77 @@
+package routeTests
+
+import (
+	"inside-athletics/internal/handlers/post"
+	"inside-athletics/internal/models"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestVerifiedAuthorsOnlyPosts(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	addCollegeAndSport(t, testDB)
+
+	athlete := newCommentTestUser(uuid.New(), "trust-athlete")
+	division := models.DivisionI
+	athlete.Verified_Athlete_Status = models.VerifiedAthleteStatusVerified
+	athlete.CollegeID, athlete.SportID, athlete.Division = &NortheasternID, &SoccerID, &division
+	fan := newCommentTestUser(uuid.New(), "trust-fan")
+	for _, user := range []*models.User{&athlete, &fan} {
+		if err := testDB.DB.Create(user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	_, viewerHeader := seedApplicant(t, testDB, "trust-viewer")
+
+	posts := map[string]*models.Post{
+		"named":     {AuthorID: athlete.ID, CollegeID: &NortheasternID, SportID: &SoccerID, Title: "On the team", Content: "Ask me anything"},
+		"anonymous": {AuthorID: athlete.ID, CollegeID: &NortheasternID, SportID: &SoccerID, Title: "Anonymous", Content: "Honest take", IsAnonymous: true},
+		"fan":       {AuthorID: fan.ID, CollegeID: &NortheasternID, SportID: &SoccerID, Title: "From the stands", Content: "Go Huskies"},
+	}
+	for _, p := range posts {
+		if err := testDB.DB.Create(p).Error; err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+	}
+
+	resp := testDB.API.Get("/api/v1/posts/?verified_only=true", viewerHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 listing verified authors' posts, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var result post.GetAllPostsResponse
+	DecodeTo(&result, resp)
+	if result.Total != 2 || len(result.Posts) != 2 {
+		t.Fatalf("expected only the athlete's 2 posts, got %d: %+v", result.Total, result.Posts)
+	}
+	for _, p := range result.Posts {
+		trust := p.AuthorTrust
+		if trust == nil || !trust.IsVerifiedAthlete {
+			t.Fatalf("expected post %s to be by a verified athlete, got %+v", p.ID, trust)
+		}
+		switch p.ID {
+		case posts["named"].ID:
+			if !trust.MatchesCollege || !trust.MatchesSport || trust.CollegeID == nil || *trust.CollegeID != NortheasternID {
+				t.Fatalf("expected the athlete's team to match their post's, got %+v", trust)
+			}
+		case posts["anonymous"].ID:
+			if p.Author != nil || trust.CollegeID != nil || trust.SportID != nil || trust.MatchesCollege || trust.MatchesSport {
+				t.Fatalf("expected nothing identifying on an anonymous post, got %+v %+v", p.Author, trust)
+			}
+		default:
+			t.Fatalf("expected only the athlete's posts, got %s", p.Title)
+		}
+	}
+
+	resp = testDB.API.Get("/api/v1/posts/filter?college_ids="+NortheasternID.String()+"&verified_only=true", viewerHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 filtering verified authors' posts, got %d: %s", resp.Code, resp.Body.String())
+	}
+	DecodeTo(&result, resp)
+	if result.Total != 2 {
+		t.Fatalf("expected the college filter to keep only the athlete's posts, got %d", result.Total)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"inside-athletics/internal/models"
 	"inside-athletics/internal/utils"
 	"net/http"
+	"net/url"
 	"testing"
 )
 
@@ -436,3 +437,81 @@ func TestCollegeSearch(t *testing.T) {
 		t.Fatalf("Expected highest ranking college to be Erm University got %s", searchResult.Results[0].Name)
 	}
 }
+
+func TestCollegeSearchMatchesCityAndState(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
+
+	_, authHeader := seedUserWithRoleAndPermissions(t, testDB.DB, models.RoleAdmin, []permissionSpec{})
+
+	colleges := []models.College{
+		{Name: "Northeastern University", State: "Massachusetts", City: "Boston", Website: "https://www.northeastern.edu", DivisionRank: models.DivisionI},
+		{Name: "Boston College", State: "Massachusetts", City: "Chestnut Hill", Website: "https://www.bc.edu", DivisionRank: models.DivisionI},
+		{Name: "Stanford University", State: "California", City: "Stanford", Website: "https://www.stanford.edu", DivisionRank: models.DivisionI},
+	}
+	if err := testDB.DB.Create(&colleges).Error; err != nil {
+		t.Fatalf("Unable to add colleges to table: %s", err.Error())
+	}
+
+	resp := api.Get("/api/v1/colleges/search?search_str=boston", authHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status ok got %d, Error Details: %s", resp.Code, resp.Body.String())
+	}
+	var searchResult utils.SearchResults[*h.GetCollegeResponse]
+	DecodeTo(&searchResult, resp)
+
+	if searchResult.Total != 2 || len(searchResult.Results) != 2 {
+		t.Fatalf("Expected 2 colleges matching boston, got %d (total %d)", len(searchResult.Results), searchResult.Total)
+	}
+	// a name match outranks the weaker city match
+	if searchResult.Results[0].Name != "Boston College" {
+		t.Fatalf("Expected Boston College to rank first got %s", searchResult.Results[0].Name)
+	}
+
+	resp = api.Get("/api/v1/colleges/search?search_str=boston&limit=1&offset=1", authHeader)
+	DecodeTo(&searchResult, resp)
+	if searchResult.Total != 2 || len(searchResult.Results) != 1 || searchResult.Results[0].Name != "Northeastern University" {
+		t.Fatalf("Expected second page to hold Northeastern University, got %+v (total %d)", searchResult.Results, searchResult.Total)
+	}
+}
+
+func TestCollegeSearchMaliciousInput(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
+
+	_, authHeader := seedUserWithRoleAndPermissions(t, testDB.DB, models.RoleAdmin, []permissionSpec{})
+
+	college := models.College{Name: "Erm University", State: "Ohio", City: "Columbus", Website: "erm.edu", DivisionRank: models.DivisionI}
+	if err := testDB.DB.Create(&college).Error; err != nil {
+		t.Fatalf("Unable to add college to table: %s", err.Error())
+	}
+
+	inputs := []string{
+		"'; DROP TABLE colleges; --",
+		"erm', name) >= 0 OR '1'='1",
+		"erm') UNION SELECT * FROM users --",
+		`\\'; SELECT pg_sleep(5); --`,
+	}
+	for _, input := range inputs {
+		resp := api.Get("/api/v1/colleges/search?search_str="+url.QueryEscape(input), authHeader)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("Expected status ok for %q got %d, Error Details: %s", input, resp.Code, resp.Body.String())
+		}
+		var searchResult utils.SearchResults[*h.GetCollegeResponse]
+		DecodeTo(&searchResult, resp)
+		for _, result := range searchResult.Results {
+			if result.Name != college.Name {
+				t.Fatalf("Unexpected result %q for %q", result.Name, input)
+			}
+		}
+	}
+
+	var count int64
+	if err := testDB.DB.Model(&models.College{}).Count(&count).Error; err != nil || count != 1 {
+		t.Fatalf("Expected colleges table to be intact, got count %d err %v", count, err)
+	}
+}
@@ -104,6 +104,7 @@ func TestGetCommentLikeInfo(t *testing.T) {
 	if err := testDB.DB.Create(&models.CommentLike{UserID: user.ID, CommentID: comment.ID}).Error; err != nil {
 		t.Fatalf("create like: %v", err)
 	}
+	testDB.RepairCounters(t)
 
 	authHeader := authHeaderWithPermissionsGivenUser(t, testDB.DB, []permissionSpec{
 		{Action: models.PermissionCreate, Resource: "like"},
@@ -5,8 +5,11 @@ import (
 	"inside-athletics/internal/handlers/post"
 	"inside-athletics/internal/models"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -23,6 +26,13 @@ func newCommentTestUser(id uuid.UUID, unique string) models.User {
 	}
 }
 
+// commenterHeader signs in as the user with the user role, which may comment and change their own
+// comments.
+func commenterHeader(t *testing.T, testDB *TestDatabase, userID uuid.UUID) string {
+	t.Helper()
+	return authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, userID)
+}
+
 // seedUserAndPost creates a User and a Post (with that user as author) for comment tests
 func seedUserAndPost(t *testing.T, testDB *TestDatabase, unique string) (models.User, models.Post) {
 	t.Helper()
@@ -65,7 +75,7 @@ func TestCreateComment(t *testing.T) {
 		"is_anonymous": false,
 	}
 
-	resp := api.Post("/api/v1/comment/", body, "Authorization: Bearer "+user.ID.String())
+	resp := api.Post("/api/v1/comment/", body, commenterHeader(t, testDB, user.ID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -94,7 +104,7 @@ func TestCreateCommentAnonymous(t *testing.T) {
 		"is_anonymous": true,
 	}
 
-	resp := api.Post("/api/v1/comment/", body, "Authorization: Bearer "+user.ID.String())
+	resp := api.Post("/api/v1/comment/", body, commenterHeader(t, testDB, user.ID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -121,7 +131,7 @@ func TestGetComment(t *testing.T) {
 		t.Fatalf("failed to create comment: %v", err)
 	}
 
-	resp := api.Get("/api/v1/comment/"+created.ID.String(), "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/comment/"+created.ID.String(), authHeaderFor(mockUUID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -135,9 +145,9 @@ func TestGetComment(t *testing.T) {
 	}
 
 	// Free-tier users must have accessed the post before viewing its comments.
-	_ = api.Get("/api/v1/post/"+post.ID.String(), "Authorization: Bearer "+user.ID.String())
+	_ = api.Get("/api/v1/post/"+post.ID.String(), authHeaderFor(user.ID.String()))
 
-	resp2 := api.Get("/api/v1/comment/"+created.ID.String(), "Authorization: Bearer "+user.ID.String())
+	resp2 := api.Get("/api/v1/comment/"+created.ID.String(), authHeaderFor(user.ID.String()))
 	if resp2.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp2.Code, resp2.Body.String())
 	}
@@ -167,8 +177,9 @@ func TestGetCommentWithLikes(t *testing.T) {
 
 	l1 := &models.CommentLike{UserID: user.ID, CommentID: c.ID}
 	testDB.DB.Create(&l1)
+	testDB.RepairCounters(t)
 
-	resp := api.Get("/api/v1/comment/"+created.ID.String(), "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/comment/"+created.ID.String(), authHeaderFor(mockUUID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -182,9 +193,9 @@ func TestGetCommentWithLikes(t *testing.T) {
 	}
 
 	// Free-tier users must have accessed the post before viewing its comments.
-	_ = api.Get("/api/v1/post/"+post.ID.String(), "Authorization: Bearer "+user.ID.String())
+	_ = api.Get("/api/v1/post/"+post.ID.String(), authHeaderFor(user.ID.String()))
 
-	resp2 := api.Get("/api/v1/comment/"+created.ID.String(), "Authorization: Bearer "+user.ID.String())
+	resp2 := api.Get("/api/v1/comment/"+created.ID.String(), authHeaderFor(user.ID.String()))
 	if resp2.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp2.Code, resp2.Body.String())
 	}
@@ -221,7 +232,7 @@ func TestGetCommentsByPost(t *testing.T) {
 		}
 	}
 
-	resp := api.Get("/api/v1/post/"+post.ID.String()+"/comments", "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/post/"+post.ID.String()+"/comments", authHeaderFor(mockUUID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -251,7 +262,7 @@ func TestGetReplies(t *testing.T) {
 		t.Fatalf("failed to create reply: %v", err)
 	}
 
-	resp := api.Get("/api/v1/comment/"+createdParent.ID.String()+"/replies", "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/comment/"+createdParent.ID.String()+"/replies", authHeaderFor(mockUUID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -280,7 +291,15 @@ func TestUpdateComment(t *testing.T) {
 	}
 
 	updateBody := map[string]any{"description": "Updated"}
-	resp := api.Patch("/api/v1/comment/"+created.ID.String(), updateBody, "Authorization: Bearer "+mockUUID)
+	other := newCommentTestUser(uuid.New(), "update-comment-other")
+	if err := testDB.DB.Create(&other).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if code := api.Patch("/api/v1/comment/"+created.ID.String(), updateBody, commenterHeader(t, testDB, other.ID)).Code; code != http.StatusForbidden {
+		t.Fatalf("expected status 403 updating someone else's comment, got %d", code)
+	}
+
+	resp := api.Patch("/api/v1/comment/"+created.ID.String(), updateBody, commenterHeader(t, testDB, user.ID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -305,12 +324,12 @@ func TestDeleteComment(t *testing.T) {
 		t.Fatalf("failed to create comment: %v", err)
 	}
 
-	resp := api.Delete("/api/v1/comment/"+created.ID.String(), "Authorization: Bearer "+mockUUID)
+	resp := api.Delete("/api/v1/comment/"+created.ID.String(), commenterHeader(t, testDB, user.ID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
 
-	getResp := api.Get("/api/v1/comment/"+created.ID.String(), "Authorization: Bearer "+mockUUID)
+	getResp := api.Get("/api/v1/comment/"+created.ID.String(), authHeaderFor(mockUUID))
 	if getResp.Code != http.StatusNotFound {
 		t.Errorf("expected 404 after delete, got %d", getResp.Code)
 	}
@@ -348,9 +367,9 @@ func TestGetCommentsByPost_HasReplies(t *testing.T) {
 	}
 
 	// Free-tier users must have accessed the post before viewing its comments.
-	_ = api.Get("/api/v1/post/"+post.ID.String(), "Authorization: Bearer "+user.ID.String())
+	_ = api.Get("/api/v1/post/"+post.ID.String(), authHeaderFor(user.ID.String()))
 
-	resp := api.Get("/api/v1/post/"+post.ID.String()+"/comments", "Authorization: Bearer "+user.ID.String())
+	resp := api.Get("/api/v1/post/"+post.ID.String()+"/comments", authHeaderFor(user.ID.String()))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -384,9 +403,9 @@ func TestGetCommentsByPost_NoReplies(t *testing.T) {
 		t.Fatalf("failed to create comment: %v", err)
 	}
 
-	_ = api.Get("/api/v1/post/"+post.ID.String(), "Authorization: Bearer "+user.ID.String())
+	_ = api.Get("/api/v1/post/"+post.ID.String(), authHeaderFor(user.ID.String()))
 
-	resp := api.Get("/api/v1/post/"+post.ID.String()+"/comments", "Authorization: Bearer "+user.ID.String())
+	resp := api.Get("/api/v1/post/"+post.ID.String()+"/comments", authHeaderFor(user.ID.String()))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -431,11 +450,11 @@ func TestGetCommentsByPost_DeletedReplyNotCounted(t *testing.T) {
 	}
 
 	// Delete the reply via the API.
-	_ = api.Delete("/api/v1/comment/"+reply.ID.String(), "Authorization: Bearer "+mockUUID)
+	_ = api.Delete("/api/v1/comment/"+reply.ID.String(), commenterHeader(t, testDB, user.ID))
 
-	_ = api.Get("/api/v1/post/"+post.ID.String(), "Authorization: Bearer "+user.ID.String())
+	_ = api.Get("/api/v1/post/"+post.ID.String(), authHeaderFor(user.ID.String()))
 
-	resp := api.Get("/api/v1/post/"+post.ID.String()+"/comments", "Authorization: Bearer "+user.ID.String())
+	resp := api.Get("/api/v1/post/"+post.ID.String()+"/comments", authHeaderFor(user.ID.String()))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -470,8 +489,8 @@ func TestGetCommentByID_HasReplies(t *testing.T) {
 	}
 
 	// Before reply: has_replies should be false.
-	_ = api.Get("/api/v1/post/"+post.ID.String(), "Authorization: Bearer "+user.ID.String())
-	resp := api.Get("/api/v1/comment/"+parent.ID.String(), "Authorization: Bearer "+user.ID.String())
+	_ = api.Get("/api/v1/post/"+post.ID.String(), authHeaderFor(user.ID.String()))
+	resp := api.Get("/api/v1/comment/"+parent.ID.String(), authHeaderFor(user.ID.String()))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -492,7 +511,7 @@ func TestGetCommentByID_HasReplies(t *testing.T) {
 	}
 
 	// After reply: has_replies should be true.
-	resp2 := api.Get("/api/v1/comment/"+parent.ID.String(), "Authorization: Bearer "+user.ID.String())
+	resp2 := api.Get("/api/v1/comment/"+parent.ID.String(), authHeaderFor(user.ID.String()))
 	if resp2.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", resp2.Code, resp2.Body.String())
 	}
@@ -527,7 +546,7 @@ func TestCreateReplyToReplyReturns400(t *testing.T) {
 		"description":       "Reply to reply",
 		"is_anonymous":      false,
 	}
-	resp := api.Post("/api/v1/comment/", body, "Authorization: Bearer "+user.ID.String())
+	resp := api.Post("/api/v1/comment/", body, commenterHeader(t, testDB, user.ID))
 	if resp.Code != http.StatusBadRequest {
 		t.Errorf("expected 400 for reply-to-reply (one layer only), got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -579,7 +598,7 @@ func TestFreeUserGetCommentsByPostLimitedToFirstThreeViewedPosts(t *testing.T) {
 		}
 	}
 
-	authHeader := "Authorization: Bearer " + viewer.ID.String()
+	authHeader := authHeaderFor(viewer.ID.String())
 	// Record four viewed posts for the free user.
 	for i := range 4 {
 		_ = api.Get("/api/v1/post/"+postIDs[i].String(), authHeader)
@@ -647,7 +666,7 @@ func TestFreeUserGetCommentBlockedOutsideFirstThreeViewedPosts(t *testing.T) {
 		comments = append(comments, *c)
 	}
 
-	authHeader := "Authorization: Bearer " + viewer.ID.String()
+	authHeader := authHeaderFor(viewer.ID.String())
 	for i := 0; i < 4; i++ {
 		_ = api.Get("/api/v1/post/"+postIDs[i].String(), authHeader)
 	}
@@ -712,7 +731,7 @@ func TestPremiumUserCanViewCommentsOutsideFirstThreeViewedPosts(t *testing.T) {
 		comments = append(comments, *c)
 	}
 
-	authHeader := "Authorization: Bearer " + viewer.ID.String()
+	authHeader := authHeaderFor(viewer.ID.String())
 	// Even after viewing four posts, premium users should not be blocked.
 	for i := range 4 {
 		_ = api.Get("/api/v1/post/"+postIDs[i].String(), authHeader)
@@ -729,3 +748,142 @@ func TestPremiumUserCanViewCommentsOutsideFirstThreeViewedPosts(t *testing.T) {
 		t.Fatalf("expected 200 for premium user single comment on 4th viewed post, got %d: %s", respSingle.Code, respSingle.Body.String())
 	}
 }
+
+// seedTreeComment writes a comment straight to the DB, minutes after base. Call testDB.RepairCounters
+// once the tree is seeded.
+func seedTreeComment(t *testing.T, testDB *TestDatabase, user models.User, post models.Post, parent *models.Comment, base time.Time, minutes int) models.Comment {
+	t.Helper()
+	c := models.Comment{
+		UserID:      user.ID,
+		PostID:      post.ID,
+		Description: "comment at " + strconv.Itoa(minutes),
+		CreatedAt:   base.Add(time.Duration(minutes) * time.Minute),
+	}
+	if parent != nil {
+		c.ParentCommentID = &parent.ID
+	}
+	if err := testDB.DB.Create(&c).Error; err != nil {
+		t.Fatalf("failed to create comment: %v", err)
+	}
+	return c
+}
+
+func getCommentTree(t *testing.T, testDB *TestDatabase, user models.User, post models.Post, query string) comment.GetCommentTreeResponse {
+	t.Helper()
+	resp := testDB.API.Get("/api/v1/post/"+post.ID.String()+"/comments/tree?"+query, authHeaderFor(user.ID.String()))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var result comment.GetCommentTreeResponse
+	DecodeTo(&result, resp)
+	return result
+}
+
+func treeIDs(nodes []comment.CommentTreeNode) []uuid.UUID {
+	ids := make([]uuid.UUID, len(nodes))
+	for i := range nodes {
+		ids[i] = nodes[i].ID
+	}
+	return ids
+}
+
+func TestGetCommentTreePagesEveryLevel(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	user, post := seedUserAndPost(t, testDB, "comment-tree")
+
+	base := time.Now().Add(-time.Hour)
+	first := seedTreeComment(t, testDB, user, post, nil, base, 0)
+	second := seedTreeComment(t, testDB, user, post, nil, base, 1)
+	third := seedTreeComment(t, testDB, user, post, nil, base, 2)
+	replyA := seedTreeComment(t, testDB, user, post, &first, base, 3)
+	replyB := seedTreeComment(t, testDB, user, post, &first, base, 4)
+	replyC := seedTreeComment(t, testDB, user, post, &first, base, 5)
+	nested := seedTreeComment(t, testDB, user, post, &replyA, base, 6)
+	testDB.RepairCounters(t)
+
+	tree := getCommentTree(t, testDB, user, post, "limit=2&replies_limit=2&depth=2")
+	if got := treeIDs(tree.Comments); !slices.Equal(got, []uuid.UUID{first.ID, second.ID}) {
+		t.Fatalf("expected the two oldest top-level comments, got %v", got)
+	}
+	if tree.NextCursor == nil {
+		t.Fatal("expected a next cursor for the third top-level comment")
+	}
+	top := tree.Comments[0]
+	if got := treeIDs(top.Replies); !slices.Equal(got, []uuid.UUID{replyA.ID, replyB.ID}) {
+		t.Fatalf("expected the first two replies, got %v", got)
+	}
+	if top.RepliesCursor == nil || tree.Comments[1].RepliesCursor != nil {
+		t.Fatal("expected a replies cursor only where replies were left out")
+	}
+	// replyA is at the depth limit, so its own reply is collapsed behind a cursor
+	collapsed := top.Replies[0]
+	if len(collapsed.Replies) != 0 || collapsed.RepliesCursor == nil || top.Replies[1].RepliesCursor != nil {
+		t.Fatalf("expected only the reply with replies to be collapsed, got %+v", top.Replies)
+	}
+
+	more := getCommentTree(t, testDB, user, post, "replies_limit=2&depth=2&cursor="+url.QueryEscape(*top.RepliesCursor))
+	if got := treeIDs(more.Comments); !slices.Equal(got, []uuid.UUID{replyC.ID}) || more.NextCursor != nil {
+		t.Fatalf("expected the last reply and no more, got %v", got)
+	}
+
+	expanded := getCommentTree(t, testDB, user, post, "cursor="+url.QueryEscape(*collapsed.RepliesCursor))
+	if got := treeIDs(expanded.Comments); !slices.Equal(got, []uuid.UUID{nested.ID}) {
+		t.Fatalf("expected the collapsed reply, got %v", got)
+	}
+
+	next := getCommentTree(t, testDB, user, post, "limit=2&cursor="+url.QueryEscape(*tree.NextCursor))
+	if got := treeIDs(next.Comments); !slices.Equal(got, []uuid.UUID{third.ID}) || next.NextCursor != nil {
+		t.Fatalf("expected the last top-level comment and no more, got %v", got)
+	}
+
+	resp := testDB.API.Get("/api/v1/post/"+post.ID.String()+"/comments/tree?cursor=not-a-cursor", authHeaderFor(user.ID.String()))
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for a bad cursor, got %d", resp.Code)
+	}
+}
+
+func TestGetCommentTreeSortModes(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	user, post := seedUserAndPost(t, testDB, "comment-tree-sort")
+
+	base := time.Now().Add(-time.Hour)
+	oldest := seedTreeComment(t, testDB, user, post, nil, base, 0)
+	liked := seedTreeComment(t, testDB, user, post, nil, base, 1)
+	newest := seedTreeComment(t, testDB, user, post, nil, base, 2)
+	for i := 0; i < 2; i++ {
+		liker := newCommentTestUser(uuid.New(), "comment-tree-liker-"+strconv.Itoa(i))
+		if err := testDB.DB.Create(&liker).Error; err != nil {
+			t.Fatalf("failed to create liker: %v", err)
+		}
+		if err := testDB.DB.Create(&models.CommentLike{UserID: liker.ID, CommentID: liked.ID}).Error; err != nil {
+			t.Fatalf("failed to like comment: %v", err)
+		}
+	}
+	testDB.RepairCounters(t)
+
+	cases := map[string][]uuid.UUID{
+		"oldest": {oldest.ID, liked.ID, newest.ID},
+		"newest": {newest.ID, liked.ID, oldest.ID},
+		"top":    {liked.ID, newest.ID, oldest.ID},
+	}
+	for sort, want := range cases {
+		tree := getCommentTree(t, testDB, user, post, "sort="+sort)
+		if got := treeIDs(tree.Comments); !slices.Equal(got, want) {
+			t.Errorf("sort=%s: expected %v, got %v", sort, want, got)
+		}
+	}
+
+	// a cursor keeps the sort it was made with
+	page := getCommentTree(t, testDB, user, post, "sort=top&limit=1")
+	if page.NextCursor == nil {
+		t.Fatal("expected a next cursor")
+	}
+	page = getCommentTree(t, testDB, user, post, "sort=oldest&limit=1&cursor="+url.QueryEscape(*page.NextCursor))
+	if got := treeIDs(page.Comments); !slices.Equal(got, []uuid.UUID{newest.ID}) {
+		t.Fatalf("expected the second most liked comment, got %v", got)
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"inside-athletics/internal/s3"
+	"net/http"
 	"net/url"
 	"strings"
 	"testing"
+
+	"github.com/google/uuid"
 )
 
 // Tests POST /api/v1/content/confirm-upload with mock S3.
@@ -19,7 +22,7 @@ func TestConfirmUpload(t *testing.T) {
 	if err != nil {
 		t.Fatalf("marshal request body: %v", err)
 	}
-	resp := api.Post("/api/v1/content/confirm-upload", "Authorization: Bearer mock-token", "Content-Type: application/json", bytes.NewReader(jsonBody))
+	resp := api.Post("/api/v1/content/confirm-upload", authHeaderFor(uuid.NewString()), "Content-Type: application/json", bytes.NewReader(jsonBody))
 	if resp.Code != 200 {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -45,7 +48,7 @@ func TestDeleteContent(t *testing.T) {
 	api := RegisterContentTestAPI(t)
 
 	key := "premium/pdf/user-1/doc.pdf"
-	resp := api.Delete("/api/v1/content?key="+url.QueryEscape(key), "Authorization: Bearer mock-token")
+	resp := api.Delete("/api/v1/content?key="+url.QueryEscape(key), authHeaderFor(uuid.NewString()))
 	if resp.Code != 200 {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -57,3 +60,67 @@ func TestDeleteContent(t *testing.T) {
 		t.Errorf("message: want deleted, got %s", out.Message)
 	}
 }
+
+// Users can delete what they uploaded, but not other users' content or ID documents.
+func TestDeleteContentOfAnotherUser(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+
+	owner, ownerHeader := seedApplicant(t, testDB, "contentowner")
+	_, otherHeader := seedApplicant(t, testDB, "contentother")
+	photo := s3.ContentPrefix(owner.ID) + "premium/photo.jpg"
+
+	for _, key := range []string{photo, s3.VerificationPrefix(owner.ID) + "id.pdf"} {
+		resp := testDB.API.Delete("/api/v1/content?key="+url.QueryEscape(key), otherHeader)
+		if resp.Code != http.StatusForbidden {
+			t.Errorf("expected status 403 deleting %s, got %d: %s", key, resp.Code, resp.Body.String())
+		}
+	}
+	resp := testDB.API.Delete("/api/v1/content?key="+url.QueryEscape(photo), ownerHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 deleting your own content, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+// ID documents can only be read by their owner and admins reviewing verification requests.
+func TestDownloadURLForVerificationDocuments(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+
+	owner, ownerHeader := seedApplicant(t, testDB, "documentowner")
+	_, otherHeader := seedApplicant(t, testDB, "documentother")
+	adminHeader := seedVerificationAdmin(t, testDB, "documentadmin")
+	key := "/api/v1/content/download-url?key=" + url.QueryEscape(s3.VerificationPrefix(owner.ID)+"id.pdf")
+
+	if resp := testDB.API.Get(key, otherHeader); resp.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 for another user, got %d: %s", resp.Code, resp.Body.String())
+	}
+	for _, header := range []string{ownerHeader, adminHeader} {
+		if resp := testDB.API.Get(key, header); resp.Code != http.StatusOK {
+			t.Errorf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+		}
+	}
+}
+
+// Uploads go under the user's own prefix.
+func TestUploadURLIsUnderOwnPrefix(t *testing.T) {
+	t.Parallel()
+	api := RegisterContentTestAPI(t)
+
+	userID := uuid.New()
+	resp := api.Post("/api/v1/content/upload-url", authHeaderFor(userID.String()), map[string]string{
+		"key":      s3.VerificationPrefix(uuid.New()) + "id.pdf",
+		"fileType": "application/pdf",
+		"fileName": "id.pdf",
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var out s3.GetUploadURLResponse
+	DecodeTo(&out, resp)
+	if !strings.HasPrefix(out.Key, s3.ContentPrefix(userID)) {
+		t.Errorf("expected the key under %s, got %s", s3.ContentPrefix(userID), out.Key)
+	}
+}
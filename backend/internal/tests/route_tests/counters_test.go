@@ -0,0 +1,162 @@
+package routeTests
+
+import (
+	"context"
+	"fmt"
+	"inside-athletics/internal/counters"
+	"inside-athletics/internal/handlers/comment"
+	"inside-athletics/internal/handlers/post"
+	"inside-athletics/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRepairCountersReportsAndFixesDrift(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	user, post, c := seedUserPostAndComment(t, testDB, "counter-drift")
+
+	// written around the DB layer, so none of these are counted yet
+	if err := testDB.DB.Create(&models.PostLike{UserID: user.ID, PostID: post.ID}).Error; err != nil {
+		t.Fatalf("create post like: %v", err)
+	}
+	if err := testDB.DB.Create(&models.CommentLike{UserID: user.ID, CommentID: c.ID}).Error; err != nil {
+		t.Fatalf("create comment like: %v", err)
+	}
+	if err := testDB.DB.Create(&models.Comment{UserID: user.ID, PostID: post.ID, ParentCommentID: &c.ID, Description: "Reply"}).Error; err != nil {
+		t.Fatalf("create reply: %v", err)
+	}
+
+	drift, err := counters.Check(context.Background(), testDB.DB)
+	if err != nil {
+		t.Fatalf("check counters: %v", err)
+	}
+	want := map[string]counters.Drift{
+		"post_stats.like_count":    {ID: post.ID, Stored: 0, Actual: 1},
+		"post_stats.comment_count": {ID: post.ID, Stored: 0, Actual: 2},
+		"comments.like_count":      {ID: c.ID, Stored: 0, Actual: 1},
+		"comments.reply_count":     {ID: c.ID, Stored: 0, Actual: 1},
+	}
+	if len(drift) != len(want) {
+		t.Fatalf("expected %d drifted counters, got %+v", len(want), drift)
+	}
+	for _, d := range drift {
+		w, ok := want[d.Counter]
+		if !ok || d.ID != w.ID || d.Stored != w.Stored || d.Actual != w.Actual {
+			t.Errorf("unexpected drift %+v", d)
+		}
+	}
+
+	// checking doesn't write anything
+	if again, err := counters.Check(context.Background(), testDB.DB); err != nil || len(again) != len(want) {
+		t.Fatalf("expected check to leave the drift in place, got %d (%v)", len(again), err)
+	}
+
+	fixed, err := counters.Repair(context.Background(), testDB.DB)
+	if err != nil {
+		t.Fatalf("repair counters: %v", err)
+	}
+	if len(fixed) != len(want) {
+		t.Fatalf("expected repair to report %d counters, got %+v", len(want), fixed)
+	}
+	if after, err := counters.Check(context.Background(), testDB.DB); err != nil || len(after) != 0 {
+		t.Fatalf("expected no drift after repair, got %+v (%v)", after, err)
+	}
+
+	var repaired models.Comment
+	if err := testDB.DB.First(&repaired, "id = ?", c.ID).Error; err != nil {
+		t.Fatalf("load comment: %v", err)
+	}
+	if repaired.LikeCount != 1 || repaired.ReplyCount != 1 {
+		t.Fatalf("expected the comment's counters to be repaired, got %d likes and %d replies", repaired.LikeCount, repaired.ReplyCount)
+	}
+}
+
+// Read paths from before the counters, kept here to benchmark against.
+const (
+	legacyPostSelect = `posts.*,
+            (SELECT COUNT(*) FROM post_likes WHERE post_likes.post_id = posts.id) AS like_count,
+            (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) AS comment_count,
+            (SELECT COUNT(*) > 0 FROM post_likes WHERE post_likes.post_id = posts.id AND post_likes.user_id = ?) AS is_liked`
+	legacyCommentSelect = `comments.*,
+            (SELECT COUNT(*) FROM comment_likes WHERE comment_likes.comment_id = comments.id) AS like_count,
+            (SELECT COUNT(*) > 0 FROM comment_likes WHERE comment_likes.comment_id = comments.id AND comment_likes.user_id = ?) AS is_liked,
+            (SELECT COUNT(*) > 0 FROM comments AS replies WHERE replies.parent_comment_id = comments.id AND replies.deleted_at IS NULL) AS has_replies`
+)
+
+// seedEngagement creates users who each like and comment on every post, and like a share of the comments.
+func seedEngagement(b *testing.B, testDB *TestDatabase, users int, posts int) uuid.UUID {
+	b.Helper()
+
+	var viewer uuid.UUID
+	for i := 0; i < users; i++ {
+		user := newCommentTestUser(uuid.New(), fmt.Sprintf("bench-%d", i))
+		if err := testDB.DB.Create(&user).Error; err != nil {
+			b.Fatalf("create user: %v", err)
+		}
+		viewer = user.ID
+	}
+
+	steps := []string{
+		`INSERT INTO posts (id, author_id, title, content, created_at, updated_at)
+			SELECT gen_random_uuid(), ?, 'Benchmark post ' || n, 'Benchmark content', NOW() - n * INTERVAL '1 minute', NOW()
+			FROM generate_series(1, ?) AS n`,
+		`INSERT INTO post_likes (user_id, post_id, created_at) SELECT users.id, posts.id, NOW() FROM users CROSS JOIN posts`,
+		`INSERT INTO comments (user_id, post_id, description, created_at, updated_at)
+			SELECT users.id, posts.id, 'Benchmark comment', NOW(), NOW() FROM users CROSS JOIN posts`,
+		`INSERT INTO comment_likes (user_id, comment_id, created_at)
+			SELECT users.id, comments.id, NOW() FROM users CROSS JOIN comments WHERE comments.user_id <> users.id AND random() < 0.25`,
+	}
+	for i, step := range steps {
+		args := []any{}
+		if i == 0 {
+			args = append(args, viewer, posts)
+		}
+		if err := testDB.DB.Exec(step, args...).Error; err != nil {
+			b.Fatalf("seed step %d: %v", i, err)
+		}
+	}
+	testDB.RepairCounters(b)
+	return viewer
+}
+
+// BenchmarkCounterReads compares reading a page of posts and a post's comments with the counts
+// recomputed per row (subqueries) against reading the counter columns.
+func BenchmarkCounterReads(b *testing.B) {
+	testDB := SetupTestDB(b)
+	defer testDB.Teardown(b)
+	viewer := seedEngagement(b, testDB, 20, 200)
+
+	var postID uuid.UUID
+	if err := testDB.DB.Table("posts").Select("id").Order("created_at DESC").Limit(1).Scan(&postID).Error; err != nil {
+		b.Fatalf("pick post: %v", err)
+	}
+
+	readPosts := func(sel string) func(*testing.B) {
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var posts []models.Post
+				if err := testDB.DB.Table("posts").Select(sel, viewer).Order("created_at DESC").Limit(50).Find(&posts).Error; err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+	readComments := func(sel string) func(*testing.B) {
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var comments []models.Comment
+				if err := testDB.DB.Model(&models.Comment{}).Select(sel, viewer).Where("post_id = ? AND parent_comment_id IS NULL", postID).Order("created_at ASC").Find(&comments).Error; err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+
+	b.Run("posts/subquery_counts", readPosts(legacyPostSelect))
+	b.Run("posts/counter_columns", readPosts(post.POST_SELECT_QUERY))
+	b.Run("comments/subquery_counts", readComments(legacyCommentSelect))
+	b.Run("comments/counter_columns", readComments(comment.COMMENT_SELECT_QUERY))
+}
@@ -0,0 +1,372 @@
+package routeTests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"inside-athletics/internal/events"
+	"inside-athletics/internal/handlers/email"
+	stripehandler "inside-athletics/internal/handlers/stripe"
+	"inside-athletics/internal/mailer"
+	"inside-athletics/internal/models"
+	unitTests "inside-athletics/internal/tests/unit_tests"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v82/webhook"
+)
+
+// recordingSender keeps every email instead of sending it.
+type recordingSender struct {
+	mu     sync.Mutex
+	emails []mailer.Email
+}
+
+func (s *recordingSender) Send(_ context.Context, e mailer.Email) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emails = append(s.emails, e)
+	return nil
+}
+
+func (s *recordingSender) Emails() []mailer.Email {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]mailer.Email(nil), s.emails...)
+}
+
+func emailPreferences(t *testing.T, testDB *TestDatabase, userID uuid.UUID) models.EmailPreference {
+	t.Helper()
+	var preferences models.EmailPreference
+	if err := testDB.DB.FirstOrCreate(&preferences, models.EmailPreference{UserID: userID}).Error; err != nil {
+		t.Fatalf("get email preferences: %v", err)
+	}
+	return preferences
+}
+
+func TestDigestSendsNewPopularPostsFromFollows(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	reader, sport := seedUserAndSport(t, testDB, "digest-reader")
+	author := newCommentTestUser(uuid.New(), "digest-author")
+	loner := newCommentTestUser(uuid.New(), "digest-loner")
+	for _, u := range []*models.User{&author, &loner} {
+		if err := testDB.DB.Create(u).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	if err := testDB.DB.Create(&models.SportFollow{UserID: reader.ID, SportID: sport.ID}).Error; err != nil {
+		t.Fatalf("follow sport: %v", err)
+	}
+
+	seedFeedPost(t, testDB, author, "Fresh in followed sport", &sport.ID, time.Hour)
+	seedFeedPost(t, testDB, author, "Stale in followed sport", &sport.ID, 10*24*time.Hour)
+	seedFeedPost(t, testDB, author, "Fresh elsewhere", nil, time.Hour)
+	seedFeedPost(t, testDB, reader, "Reader's own post", &sport.ID, time.Hour)
+
+	sender := &recordingSender{}
+	m := mailer.New(testDB.DB, sender, mailer.DefaultConfig())
+	sent, err := m.SendDigests(context.Background())
+	if err != nil || sent != 1 {
+		t.Fatalf("expected one digest, sent %d (%v)", sent, err)
+	}
+
+	emails := sender.Emails()
+	if len(emails) != 1 || emails[0].To != reader.Email || emails[0].Subject != "Popular this week: Fresh in followed sport" {
+		t.Fatalf("expected the reader's digest led by the fresh post, got %+v", emails)
+	}
+	for _, title := range []string{"Stale in followed sport", "Fresh elsewhere", "Reader's own post"} {
+		if strings.Contains(emails[0].Text, title) {
+			t.Fatalf("expected %q to be left out of the digest, got %q", title, emails[0].Text)
+		}
+	}
+	preferences := emailPreferences(t, testDB, reader.ID)
+	if preferences.LastDigestAt == nil || !strings.Contains(emails[0].Headers["List-Unsubscribe"], "token="+preferences.UnsubscribeToken+"&") {
+		t.Fatalf("expected the digest to be recorded and link to the reader's unsubscribe token, got %+v / %v", preferences, emails[0].Headers)
+	}
+
+	// nobody is due again until a full period has passed
+	if sent, err := m.SendDigests(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("expected no digests on the second run, sent %d (%v)", sent, err)
+	}
+
+	// unsubscribed readers get no digest even once they're due
+	if err := testDB.DB.Model(&models.EmailPreference{}).Where("user_id = ?", reader.ID).
+		Updates(map[string]any{"digest": false, "last_digest_at": time.Now().Add(-8 * 24 * time.Hour)}).Error; err != nil {
+		t.Fatalf("unsubscribe: %v", err)
+	}
+	if sent, err := m.SendDigests(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("expected no digest for an unsubscribed reader, sent %d (%v)", sent, err)
+	}
+}
+
+// failingSender fails every send, like an SMTP server that is down.
+type failingSender struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *failingSender) Send(context.Context, mailer.Email) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return errors.New("connection refused")
+}
+
+func TestDigestRunStopsWhenMailIsDown(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	author, sport := seedUserAndSport(t, testDB, "digest-down-author")
+	seedFeedPost(t, testDB, author, "Fresh in followed sport", &sport.ID, time.Hour)
+
+	// more readers than fit in a batch
+	readers := make([]models.User, 150)
+	for i := range readers {
+		readers[i] = newCommentTestUser(uuid.New(), fmt.Sprintf("digest-down-%d", i))
+	}
+	if err := testDB.DB.Create(&readers).Error; err != nil {
+		t.Fatalf("create readers: %v", err)
+	}
+	for _, reader := range readers {
+		if err := testDB.DB.Create(&models.SportFollow{UserID: reader.ID, SportID: sport.ID}).Error; err != nil {
+			t.Fatalf("follow sport: %v", err)
+		}
+	}
+
+	sender := &failingSender{}
+	m := mailer.New(testDB.DB, sender, mailer.DefaultConfig())
+	sent, err := m.SendDigests(context.Background())
+	if err == nil || sent != 0 {
+		t.Fatalf("expected the run to fail without sending, sent %d (%v)", sent, err)
+	}
+	// the first batch fails as a whole, so the run gives up instead of claiming it again
+	if sender.calls != 100 {
+		t.Fatalf("expected one batch of sends, got %d", sender.calls)
+	}
+	if n := strings.Count(err.Error(), "connection refused"); n > 10 {
+		t.Fatalf("expected the errors to be capped, got %d", n)
+	}
+	if !strings.Contains(err.Error(), "more digest errors") {
+		t.Fatalf("expected the rest of the errors to be counted, got %v", err)
+	}
+
+	// nobody's digest was recorded, so they are all tried again on the next run
+	var recorded int64
+	if err := testDB.DB.Model(&models.EmailPreference{}).Where("last_digest_at IS NOT NULL").Count(&recorded).Error; err != nil {
+		t.Fatalf("count recorded digests: %v", err)
+	}
+	if recorded != 0 {
+		t.Fatalf("expected the failed digests to be released, %d were recorded", recorded)
+	}
+}
+
+func TestReplyAlertsSkipSelfRepliesAndAreThrottled(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	author, post := seedUserAndPost(t, testDB, "reply-alert-author")
+	users, _ := seedNotificationUsers(t, testDB, "reply-alert", 2)
+
+	parent := models.Comment{UserID: author.ID, PostID: post.ID, Description: "Anyone tried out as a walk-on?"}
+	if err := testDB.DB.Create(&parent).Error; err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	reply := func(user models.User, anonymous bool) models.Comment {
+		c := models.Comment{UserID: user.ID, PostID: post.ID, ParentCommentID: &parent.ID, IsAnonymous: anonymous, Description: "I did, <b>email</b> me"}
+		if err := testDB.DB.Create(&c).Error; err != nil {
+			t.Fatalf("create reply: %v", err)
+		}
+		return c
+	}
+
+	sender := &recordingSender{}
+	m := mailer.New(testDB.DB, sender, mailer.DefaultConfig())
+	ctx := context.Background()
+	for _, c := range []models.Comment{reply(author, false), reply(users[0], true), reply(users[1], false)} {
+		if err := m.SendReplyAlert(ctx, parent.ID, c.ID); err != nil {
+			t.Fatalf("send reply alert: %v", err)
+		}
+	}
+
+	// the self reply is skipped and the third reply falls inside the cooldown
+	emails := sender.Emails()
+	if len(emails) != 1 || emails[0].To != author.Email || emails[0].Subject != "Someone replied to your comment" {
+		t.Fatalf("expected one alert for the anonymous reply, got %+v", emails)
+	}
+	if !strings.Contains(emails[0].Text, "/posts/"+post.ID.String()) || !strings.Contains(emails[0].HTML, "&lt;b&gt;email&lt;/b&gt;") {
+		t.Fatalf("expected a link to the post and an escaped reply, got %q / %q", emails[0].Text, emails[0].HTML)
+	}
+}
+
+// blockingSender holds every send until release is closed, like a slow mail server.
+type blockingSender struct {
+	release chan struct{}
+	sent    chan mailer.Email
+}
+
+func (s *blockingSender) Send(ctx context.Context, e mailer.Email) error {
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	s.sent <- e
+	return nil
+}
+
+func TestReplyAlertsDontHoldUpPublishing(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	author, post := seedUserAndPost(t, testDB, "queued-alert-author")
+	users, _ := seedNotificationUsers(t, testDB, "queued-alert", 1)
+
+	parent := models.Comment{UserID: author.ID, PostID: post.ID, Description: "Anyone tried out as a walk-on?"}
+	if err := testDB.DB.Create(&parent).Error; err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	reply := models.Comment{UserID: users[0].ID, PostID: post.ID, ParentCommentID: &parent.ID, Description: "I did"}
+	if err := testDB.DB.Create(&reply).Error; err != nil {
+		t.Fatalf("create reply: %v", err)
+	}
+
+	sender := &blockingSender{release: make(chan struct{}), sent: make(chan mailer.Email, 1)}
+	m := mailer.New(testDB.DB, sender, mailer.DefaultConfig())
+	bus := events.NewBus()
+	m.Subscribe(bus)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.RunReplyAlerts(ctx)
+
+	// publishing returns while the mail server is still stuck on the alert
+	bus.Publish(context.Background(), events.CommentCreated{CommentID: reply.ID, PostID: post.ID, ParentCommentID: &parent.ID, UserID: users[0].ID})
+	close(sender.release)
+
+	select {
+	case e := <-sender.sent:
+		if e.To != author.Email {
+			t.Fatalf("expected the alert to go to the parent's author, got %s", e.To)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the queued reply alert to be sent")
+	}
+}
+
+func TestUnsubscribeLinkTurnsOffLists(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	user, _ := seedNotificationUsers(t, testDB, "unsubscribe", 1)
+	token := emailPreferences(t, testDB, user[0].ID).UnsubscribeToken
+
+	app := fiber.New()
+	email.RegisterUnsubscribeRoute(app, testDB.DB)
+	unsubscribe := func(method string, query string) int {
+		resp, err := app.Test(httptest.NewRequest(method, email.UnsubscribePath+"?"+query, nil))
+		if err != nil {
+			t.Fatalf("unsubscribe request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if code := unsubscribe(http.MethodGet, "token="+token+"&list=digest"); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if p := emailPreferences(t, testDB, user[0].ID); p.Digest || !p.ReplyAlerts {
+		t.Fatalf("expected only the digest to be turned off, got %+v", p)
+	}
+
+	// mail clients' one-click unsubscribe POSTs without a list, which means every list
+	if code := unsubscribe(http.MethodPost, "token="+token); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if p := emailPreferences(t, testDB, user[0].ID); p.Digest || p.ReplyAlerts {
+		t.Fatalf("expected every list to be turned off, got %+v", p)
+	}
+
+	if code := unsubscribe(http.MethodGet, "token=unknown"); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown token, got %d", code)
+	}
+	if code := unsubscribe(http.MethodGet, "token="+token+"&list=receipts"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a list that can't be unsubscribed from, got %d", code)
+	}
+
+	// signed in users can turn lists back on
+	authHeader := authHeaderFor(user[0].ID.String())
+	resp := testDB.API.Put("/api/v1/email/preferences", map[string]any{"reply_alerts": true}, authHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var preferences email.EmailPreferencesResponse
+	DecodeTo(&preferences, resp)
+	if preferences.Digest || !preferences.ReplyAlerts {
+		t.Fatalf("expected only reply alerts back on, got %+v", preferences)
+	}
+}
+
+// not parallel: sets the webhook secret in env
+func TestInvoicePaidWebhookEmailsReceipt(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	secret := "whsec_test"
+	t.Setenv("STRIPE_WEBHOOK_SECRET", secret)
+
+	customerID := "cus_receipt_" + uuid.NewString()[:8]
+	user := newCommentTestUser(uuid.New(), "receipt")
+	user.StripeCustomerID = &customerID
+	if err := testDB.DB.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	stub := unitTests.StartSMTPStub(t)
+	sender, err := mailer.NewSender(stub.Config())
+	if err != nil {
+		t.Fatalf("new sender: %v", err)
+	}
+	app := fiber.New()
+	stripehandler.RegisterWebhookRoute(app, testDB.DB, mailer.New(testDB.DB, sender, stub.Config()), nil)
+
+	payload, _ := json.Marshal(map[string]any{
+		"id":     "evt_receipt",
+		"object": "event",
+		"type":   "invoice.paid",
+		"data": map[string]any{"object": map[string]any{
+			"id":                 "in_receipt",
+			"object":             "invoice",
+			"customer":           customerID,
+			"amount_paid":        1499,
+			"currency":           "usd",
+			"number":             "INV-0042",
+			"hosted_invoice_url": "https://invoice.stripe.com/i/receipt",
+		}},
+	})
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: secret})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/stripe/webhook", strings.NewReader(string(signed.Payload)))
+	req.Header.Set("Stripe-Signature", signed.Header)
+	resp, err := app.Test(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the webhook to succeed, got %v (%v)", resp, err)
+	}
+
+	messages := stub.Messages()
+	if len(messages) != 1 || len(messages[0].To) != 1 || messages[0].To[0] != user.Email {
+		t.Fatalf("expected one receipt to %s, got %+v", user.Email, messages)
+	}
+	for _, want := range []string{"Subject: Your Inside Athletics receipt", "$14.99", "INV-0042"} {
+		if !strings.Contains(messages[0].Data, want) {
+			t.Fatalf("expected receipt to contain %q, got %s", want, messages[0].Data)
+		}
+	}
+	if strings.Contains(messages[0].Data, "List-Unsubscribe") {
+		t.Fatalf("expected receipts to have no unsubscribe link, got %s", messages[0].Data)
+	}
+}
@@ -0,0 +1,278 @@
+package routeTests
+
+import (
+	"context"
+	"fmt"
+	"inside-athletics/internal/handlers/feed"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/poststats"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// seedFeedUsers creates the user reading the feed (following soccer) and an author for the posts.
+func seedFeedUsers(t *testing.T, testDB *TestDatabase, role models.RoleName) (models.User, models.User) {
+	t.Helper()
+	reader := newCommentTestUser(uuid.New(), "feed-reader")
+	author := newCommentTestUser(uuid.New(), "feed-author")
+	for _, user := range []*models.User{&reader, &author} {
+		if err := testDB.DB.Create(user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	assignRoleToUser(t, testDB.DB, reader.ID, getRoleID(t, testDB.DB, role))
+
+	popularity := int32(100)
+	if err := testDB.DB.FirstOrCreate(&models.Sport{ID: SoccerID, Name: "Soccer", Popularity: &popularity}).Error; err != nil {
+		t.Fatalf("create sport: %v", err)
+	}
+	if err := testDB.DB.Create(&models.SportFollow{UserID: reader.ID, SportID: SoccerID}).Error; err != nil {
+		t.Fatalf("create sport follow: %v", err)
+	}
+	return reader, author
+}
+
+func seedFeedPost(t *testing.T, testDB *TestDatabase, author models.User, title string, sportID *uuid.UUID, age time.Duration) models.Post {
+	t.Helper()
+	post := models.Post{
+		AuthorID:  author.ID,
+		SportID:   sportID,
+		Title:     title,
+		Content:   "Feed content",
+		CreatedAt: time.Now().Add(-age),
+	}
+	if err := testDB.DB.Create(&post).Error; err != nil {
+		t.Fatalf("create post %q: %v", title, err)
+	}
+	return post
+}
+
+type feedPage struct {
+	Items []struct {
+		Source      feed.Source             `json:"source"`
+		Post        *struct{ ID uuid.UUID } `json:"post"`
+		PremiumPost *struct{ ID uuid.UUID } `json:"premium_post"`
+	} `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+func getFeedPage(t *testing.T, testDB *TestDatabase, authHeader string, query string) feedPage {
+	t.Helper()
+	resp := testDB.API.Get("/api/v1/feed/?"+query, authHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var page feedPage
+	DecodeTo(&page, resp)
+	return page
+}
+
+func TestGetFeedMixesFollowedTrendingAndExplore(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	reader, author := seedFeedUsers(t, testDB, models.RoleUser)
+
+	followedNew := seedFeedPost(t, testDB, author, "Followed new", &SoccerID, time.Hour)
+	followedOld := seedFeedPost(t, testDB, author, "Followed old", &SoccerID, 48*time.Hour)
+	viewed := seedFeedPost(t, testDB, author, "Followed but viewed", &SoccerID, 2*time.Hour)
+	trending := seedFeedPost(t, testDB, author, "Trending", nil, 3*time.Hour)
+	quiet := seedFeedPost(t, testDB, author, "Quiet", nil, 4*time.Hour)
+
+	if err := testDB.DB.Create(&models.ViewedPost{UserID: reader.ID, PostID: viewed.ID}).Error; err != nil {
+		t.Fatalf("create viewed post: %v", err)
+	}
+	if err := testDB.DB.Create(&models.PostLike{UserID: author.ID, PostID: trending.ID, CreatedAt: time.Now().Add(-time.Hour)}).Error; err != nil {
+		t.Fatalf("create like: %v", err)
+	}
+	if _, err := poststats.NewStore(testDB.DB, poststats.DefaultConfig()).Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh post stats: %v", err)
+	}
+	premium := models.PremiumPost{AuthorID: author.ID, SportID: &SoccerID, Title: "Premium", Content: "Premium content"}
+	if err := testDB.DB.Create(&premium).Error; err != nil {
+		t.Fatalf("create premium post: %v", err)
+	}
+
+	page := getFeedPage(t, testDB, authHeaderFor(reader.ID.String()), "limit=20")
+
+	want := map[uuid.UUID]feed.Source{
+		followedNew.ID: feed.SourceFollowed,
+		followedOld.ID: feed.SourceFollowed,
+		trending.ID:    feed.SourceTrending,
+		quiet.ID:       feed.SourceExplore,
+	}
+	if len(page.Items) != len(want) {
+		t.Fatalf("expected %d items, got %+v", len(want), page.Items)
+	}
+	for _, item := range page.Items {
+		if item.PremiumPost != nil {
+			t.Fatalf("expected no premium posts for a free user, got %s", item.PremiumPost.ID)
+		}
+		if source, ok := want[item.Post.ID]; !ok || source != item.Source {
+			t.Errorf("unexpected item %s from %s", item.Post.ID, item.Source)
+		}
+	}
+	// followed posts come newest first, and lead the page
+	if page.Items[0].Post.ID != followedNew.ID {
+		t.Fatalf("expected the newest followed post first, got %s", page.Items[0].Post.ID)
+	}
+	if page.NextCursor != nil {
+		t.Fatalf("expected no next cursor on the only page, got %s", *page.NextCursor)
+	}
+}
+
+func TestGetFeedPagesWithoutRepeats(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	reader, author := seedFeedUsers(t, testDB, models.RoleUser)
+
+	const total = 23
+	for i := 0; i < total; i++ {
+		var sportID *uuid.UUID
+		if i%2 == 0 {
+			sportID = &SoccerID
+		}
+		seedFeedPost(t, testDB, author, fmt.Sprintf("Post %d", i), sportID, time.Duration(i)*time.Minute)
+	}
+
+	authHeader := authHeaderFor(reader.ID.String())
+	seen := map[uuid.UUID]bool{}
+	query := "limit=5"
+	for pages := 0; ; pages++ {
+		if pages > total {
+			t.Fatal("feed never ran out of pages")
+		}
+		page := getFeedPage(t, testDB, authHeader, query)
+		for _, item := range page.Items {
+			if seen[item.Post.ID] {
+				t.Fatalf("post %s was returned twice", item.Post.ID)
+			}
+			seen[item.Post.ID] = true
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		if len(page.Items) != 5 {
+			t.Fatalf("expected full pages before the last one, got %d items", len(page.Items))
+		}
+		query = "limit=5&cursor=" + url.QueryEscape(*page.NextCursor)
+
+		// a post made while paging waits for the next feed
+		if pages == 0 {
+			seedFeedPost(t, testDB, author, "Made while paging", &SoccerID, 0)
+		}
+	}
+	if len(seen) != total {
+		t.Fatalf("expected all %d posts across the pages, got %d", total, len(seen))
+	}
+
+	resp := testDB.API.Get("/api/v1/feed/?cursor=not-a-cursor", authHeader)
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for a bad cursor, got %d", resp.Code)
+	}
+}
+
+func TestGetFeedTrendingIgnoresLikesMadeWhilePaging(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	reader, author := seedFeedUsers(t, testDB, models.RoleUser)
+	stats := poststats.NewStore(testDB.DB, poststats.DefaultConfig())
+
+	like := func(user models.User, post models.Post, at time.Time) {
+		t.Helper()
+		err := testDB.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&models.PostLike{UserID: user.ID, PostID: post.ID, CreatedAt: at}).Error; err != nil {
+				return err
+			}
+			return stats.Record(tx, post.ID, poststats.Like, at, 1)
+		})
+		if err != nil {
+			t.Fatalf("like post: %v", err)
+		}
+	}
+
+	// none of them are followed, and older likes leave each a little less trending than the last
+	const total = 8
+	posts := make([]models.Post, 0, total)
+	for i := 0; i < total; i++ {
+		post := seedFeedPost(t, testDB, author, fmt.Sprintf("Trending %d", i), nil, time.Hour)
+		like(author, post, time.Now().Add(-time.Duration(i+1)*time.Hour))
+		posts = append(posts, post)
+	}
+
+	authHeader := authHeaderFor(reader.ID.String())
+	seen := map[uuid.UUID]bool{}
+	query := "limit=3"
+	for pages := 0; ; pages++ {
+		if pages > total {
+			t.Fatal("feed never ran out of pages")
+		}
+		page := getFeedPage(t, testDB, authHeader, query)
+		for _, item := range page.Items {
+			if item.Source != feed.SourceTrending {
+				t.Fatalf("expected only trending posts, got %s from %s", item.Post.ID, item.Source)
+			}
+			if seen[item.Post.ID] {
+				t.Fatalf("post %s was returned twice", item.Post.ID)
+			}
+			seen[item.Post.ID] = true
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		query = "limit=3&cursor=" + url.QueryEscape(*page.NextCursor)
+
+		// likes on posts further down would lift them above the cursor if they counted
+		if pages == 0 {
+			for _, post := range posts {
+				if !seen[post.ID] {
+					like(reader, post, time.Now())
+				}
+			}
+		}
+	}
+	if len(seen) != total {
+		t.Fatalf("expected all %d posts across the pages, got %d", total, len(seen))
+	}
+}
+
+func TestGetFeedIncludesPremiumPostsForPremiumUsers(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	reader, author := seedFeedUsers(t, testDB, models.RolePremiumUser)
+
+	post := seedFeedPost(t, testDB, author, "Followed", &SoccerID, time.Hour)
+	premium := models.PremiumPost{AuthorID: author.ID, SportID: &SoccerID, Title: "Premium", Content: "Premium content"}
+	if err := testDB.DB.Create(&premium).Error; err != nil {
+		t.Fatalf("create premium post: %v", err)
+	}
+
+	page := getFeedPage(t, testDB, authHeaderFor(reader.ID.String()), "")
+	if len(page.Items) != 2 {
+		t.Fatalf("expected the post and the premium post, got %+v", page.Items)
+	}
+	// the premium post is newer, so it comes first
+	if page.Items[0].PremiumPost == nil || page.Items[0].PremiumPost.ID != premium.ID || page.Items[0].Source != feed.SourceFollowed {
+		t.Fatalf("expected the followed premium post first, got %+v", page.Items[0])
+	}
+	if page.Items[1].Post == nil || page.Items[1].Post.ID != post.ID {
+		t.Fatalf("expected the followed post second, got %+v", page.Items[1])
+	}
+
+	// viewing a post as a premium user takes it out of the feed too
+	if resp := testDB.API.Get("/api/v1/post/"+post.ID.String(), authHeaderFor(reader.ID.String())); resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 viewing the post, got %d: %s", resp.Code, resp.Body.String())
+	}
+	page = getFeedPage(t, testDB, authHeaderFor(reader.ID.String()), "")
+	if len(page.Items) != 1 || page.Items[0].PremiumPost == nil {
+		t.Fatalf("expected only the premium post after viewing the other, got %+v", page.Items)
+	}
+}
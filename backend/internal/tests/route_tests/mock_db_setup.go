@@ -3,9 +3,11 @@ package routeTests
 import (
 	"context"
 	"encoding/json"
-	"inside-athletics/internal/models"
 	"fmt"
+	"inside-athletics/internal/counters"
 	"inside-athletics/internal/handlers/content"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
 	"inside-athletics/internal/s3"
 	"inside-athletics/internal/server"
 	unitTests "inside-athletics/internal/tests/unit_tests"
@@ -35,7 +37,7 @@ type TestDatabase struct {
 }
 
 // SetupTestDB creates a new PostgreSQL container and returns a connection
-func SetupTestDB(t *testing.T) *TestDatabase {
+func SetupTestDB(t testing.TB) *TestDatabase {
 	ctx := context.Background()
 
 	stripe.Key = os.Getenv("STRIPE_SECRET_KEY")
@@ -85,7 +87,7 @@ func SetupTestDB(t *testing.T) *TestDatabase {
 }
 
 // Teardown cleans up the test database
-func (td *TestDatabase) Teardown(t *testing.T) {
+func (td *TestDatabase) Teardown(t testing.TB) {
 	ctx := context.Background()
 
 	if td.DB != nil {
@@ -106,7 +108,7 @@ func (td *TestDatabase) Teardown(t *testing.T) {
 	}
 }
 
-func (td *TestDatabase) RunMigrations(t *testing.T) {
+func (td *TestDatabase) RunMigrations(t testing.TB) {
 	ctx := context.Background()
 
 	// Get the connection string for Atlas
@@ -135,7 +137,7 @@ func (td *TestDatabase) RunMigrations(t *testing.T) {
 	}
 }
 
-func (td *TestDatabase) SeedDefaultRoles(t *testing.T) {
+func (td *TestDatabase) SeedDefaultRoles(t testing.TB) {
 	t.Helper()
 
 	roleNames := []models.RoleName{
@@ -154,6 +156,16 @@ func (td *TestDatabase) SeedDefaultRoles(t *testing.T) {
 	}
 }
 
+// RepairCounters brings the like/comment counters in line with rows seeded straight into the DB
+// instead of through the DB layer.
+func (td *TestDatabase) RepairCounters(t testing.TB) {
+	t.Helper()
+
+	if _, err := counters.Repair(context.Background(), td.DB); err != nil {
+		t.Fatalf("failed to repair counters: %v", err)
+	}
+}
+
 // GENERIC HELPER FUNCS
 
 // Decode the given response JSON into the given struct entity.
@@ -166,39 +178,42 @@ func DecodeTo[T any](entity *T, resp *httptest.ResponseRecorder) {
 }
 
 // Create API routing with test DB connection based on given dbUrl
-func SetupTestAPI(t *testing.T, dbUrl string) (humatest.TestAPI, *gorm.DB) {
-	_, api := humatest.New(t) // setup test API
-
-	api.UseMiddleware(MockAuthMiddleware(api))
+func SetupTestAPI(t testing.TB, dbUrl string) (humatest.TestAPI, *gorm.DB) {
+	api := newTestAPI(t) // setup test API
 
 	db, err := gorm.Open(gormPostgres.Open(dbUrl), &gorm.Config{})
 	if err != nil {
 		t.Errorf("Unable to connect to DB: %v", err)
 	}
 
-	server.CreateRoutes(db, api)
+	_, grants := server.CreateRoutes(db, api)
 	registerStripeRoutesWithMock(api, db)
-	registerContentRoutesWithMock(api, db)
+	registerContentRoutesWithMock(api, db, grants)
 
 	return api, db
 }
 
+// Builds the real app (middleware included) with tokens verified against the test issuer.
+func newTestAPI(t testing.TB) humatest.TestAPI {
+	app := server.NewApp(testIssuer.Verifier())
+	return humatest.Wrap(t, fiberTestAPI{API: app.Api, app: app.Server})
+}
+
 func registerStripeRoutesWithMock(api humatest.TestAPI, db *gorm.DB) {
 	stripehandler.RouteWithClient(api, db, stripehandler.NewMockStripeClient())
 }
 
-// Returns an API with only content routes and mock S3.
+// Returns an API with only content routes and mock S3, without the permission middleware.
 func RegisterContentTestAPI(t *testing.T) humatest.TestAPI {
-	_, api := humatest.New(t)
-	api.UseMiddleware(MockAuthMiddleware(api))
-	registerContentRoutesWithMock(api, nil)
+	api := newTestAPI(t)
+	registerContentRoutesWithMock(api, nil, nil)
 	return api
 }
 
-func registerContentRoutesWithMock(api humatest.TestAPI, db *gorm.DB) {
+func registerContentRoutesWithMock(api humatest.TestAPI, db *gorm.DB, grants *permcache.Cache) {
 	mockS3 := unitTests.NewMockS3Client()
 	mockS3.HeadObjectResponse.Size = 1024
 	mockS3.HeadObjectResponse.Metadata = map[string]string{"filename": "photo.jpg"}
 	s3Svc := s3.NewService(mockS3, s3.Config{Bucket: "test", Region: "us-east-1", PresignedURLExpiry: time.Hour})
-	content.Route(api, db, s3Svc)
+	content.Route(api, db, s3Svc, grants)
 }
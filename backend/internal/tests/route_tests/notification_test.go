@@ -0,0 +1,183 @@
+package routeTests
+
+import (
+	"inside-athletics/internal/handlers/notification"
+	"inside-athletics/internal/models"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// seedNotificationUsers creates n users who may like, comment and post.
+func seedNotificationUsers(t *testing.T, testDB *TestDatabase, unique string, n int) ([]models.User, []string) {
+	t.Helper()
+	users := make([]models.User, n)
+	headers := make([]string, n)
+	for i := range users {
+		users[i] = newCommentTestUser(uuid.New(), unique+"-"+string(rune('a'+i)))
+		if err := testDB.DB.Create(&users[i]).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		headers[i] = authHeaderWithPermissionsGivenUser(t, testDB.DB, []permissionSpec{
+			{Action: models.PermissionCreate, Resource: "like"},
+			{Action: models.PermissionCreate, Resource: "post"},
+			{Action: models.PermissionCreate, Resource: "comment"},
+		}, users[i].ID)
+	}
+	return users, headers
+}
+
+func getNotifications(t *testing.T, testDB *TestDatabase, authHeader string, query string) notification.GetNotificationsResponse {
+	t.Helper()
+	resp := testDB.API.Get("/api/v1/notifications/?"+query, authHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var result notification.GetNotificationsResponse
+	DecodeTo(&result, resp)
+	return result
+}
+
+func mustPost(t *testing.T, testDB *TestDatabase, path string, body any, authHeader string) {
+	t.Helper()
+	if resp := testDB.API.Post(path, body, authHeader); resp.Code != http.StatusOK {
+		t.Fatalf("POST %s: expected status 200, got %d: %s", path, resp.Code, resp.Body.String())
+	}
+}
+
+func TestNotificationsGroupActivityUntilRead(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	author, post := seedUserAndPost(t, testDB, "notify-author")
+	users, headers := seedNotificationUsers(t, testDB, "notify", 3)
+	authorHeader := authHeaderWithPermissionsGivenUser(t, testDB.DB, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "like"},
+	}, author.ID)
+
+	// liking your own post doesn't notify you
+	mustPost(t, testDB, "/api/v1/post/like", map[string]any{"post_id": post.ID}, authorHeader)
+	mustPost(t, testDB, "/api/v1/post/like", map[string]any{"post_id": post.ID}, headers[0])
+	mustPost(t, testDB, "/api/v1/post/like", map[string]any{"post_id": post.ID}, headers[1])
+	mustPost(t, testDB, "/api/v1/comment/", map[string]any{"post_id": post.ID, "description": "Nice", "is_anonymous": true}, headers[2])
+
+	result := getNotifications(t, testDB, authorHeader, "")
+	if len(result.Notifications) != 2 || result.UnreadCount != 2 {
+		t.Fatalf("expected a like and a comment notification, got %+v", result)
+	}
+	comment, likes := result.Notifications[0], result.Notifications[1]
+	if comment.Type != models.NotificationPostComment || comment.ActorID != nil || comment.Message != "Someone commented on your post" {
+		t.Fatalf("expected the anonymous comment first with its actor hidden, got %+v", comment)
+	}
+	if likes.Type != models.NotificationPostLike || likes.ActorCount != 2 || likes.SubjectID != post.ID {
+		t.Fatalf("expected both likes grouped on the post, got %+v", likes)
+	}
+	if want := users[1].Username + " and 1 other liked your post"; likes.Message != want {
+		t.Fatalf("expected message %q, got %q", want, likes.Message)
+	}
+
+	// other users can't read someone else's notification
+	if resp := testDB.API.Post("/api/v1/notifications/"+likes.ID.String()+"/read", map[string]any{}, headers[0]); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 for another user's notification, got %d", resp.Code)
+	}
+	mustPost(t, testDB, "/api/v1/notifications/"+likes.ID.String()+"/read", map[string]any{}, authorHeader)
+
+	// a like after the group was read starts a new notification
+	mustPost(t, testDB, "/api/v1/post/like", map[string]any{"post_id": post.ID}, headers[2])
+	result = getNotifications(t, testDB, authorHeader, "unread_only=true")
+	if len(result.Notifications) != 2 || result.UnreadCount != 2 {
+		t.Fatalf("expected the new like and the comment unread, got %+v", result)
+	}
+	if n := result.Notifications[0]; n.Type != models.NotificationPostLike || n.ActorCount != 1 || n.Read {
+		t.Fatalf("expected a new unread like notification, got %+v", n)
+	}
+
+	resp := testDB.API.Post("/api/v1/notifications/read", map[string]any{}, authorHeader)
+	var marked notification.MarkAllReadResponse
+	DecodeTo(&marked, resp)
+	if resp.Code != http.StatusOK || marked.Updated != 2 {
+		t.Fatalf("expected 2 notifications marked read, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if result = getNotifications(t, testDB, authorHeader, "limit=2"); result.UnreadCount != 0 || len(result.Notifications) != 2 || result.NextCursor == nil {
+		t.Fatalf("expected two read notifications and a next page, got %+v", result)
+	}
+}
+
+func TestNotificationRepliesAndCommentLikes(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	_, post := seedUserAndPost(t, testDB, "notify-reply")
+	users, headers := seedNotificationUsers(t, testDB, "notify-reply", 2)
+
+	parent := models.Comment{UserID: users[0].ID, PostID: post.ID, Description: "Parent"}
+	if err := testDB.DB.Create(&parent).Error; err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	mustPost(t, testDB, "/api/v1/comment/", map[string]any{"post_id": post.ID, "parent_comment_id": parent.ID, "description": "Reply"}, headers[1])
+	mustPost(t, testDB, "/api/v1/comment/like", map[string]any{"comment_id": parent.ID}, headers[1])
+
+	result := getNotifications(t, testDB, headers[0], "")
+	if len(result.Notifications) != 2 {
+		t.Fatalf("expected a reply and a like notification, got %+v", result.Notifications)
+	}
+	like, reply := result.Notifications[0], result.Notifications[1]
+	if like.Type != models.NotificationCommentLike || like.SubjectID != parent.ID || like.PostID == nil || *like.PostID != post.ID {
+		t.Fatalf("expected a like on the parent comment, got %+v", like)
+	}
+	if reply.Type != models.NotificationCommentReply || reply.Message != users[1].Username+" replied to your comment" {
+		t.Fatalf("expected a reply notification, got %+v", reply)
+	}
+}
+
+func TestNotificationPreferencesAndFollowedTags(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	users, headers := seedNotificationUsers(t, testDB, "notify-tag", 2)
+	follower, author := users[0], users[1]
+
+	tag := models.Tag{Name: "Recruiting", Type: models.TagTypeRecruitingLogistics}
+	if err := testDB.DB.Create(&tag).Error; err != nil {
+		t.Fatalf("create tag: %v", err)
+	}
+	if err := testDB.DB.Create(&models.TagFollow{UserID: follower.ID, TagID: tag.ID}).Error; err != nil {
+		t.Fatalf("create tag follow: %v", err)
+	}
+	newPost := map[string]any{"title": "Tagged", "content": "Tagged content", "tags": []map[string]any{{"id": tag.ID}}}
+
+	mustPost(t, testDB, "/api/v1/post/", newPost, headers[1])
+	result := getNotifications(t, testDB, headers[0], "")
+	if len(result.Notifications) != 1 || result.Notifications[0].Type != models.NotificationTagPost || result.Notifications[0].SubjectID != tag.ID {
+		t.Fatalf("expected a notification for the followed tag, got %+v", result.Notifications)
+	}
+	if want := author.Username + " posted in a tag you follow"; result.Notifications[0].Message != want {
+		t.Fatalf("expected message %q, got %q", want, result.Notifications[0].Message)
+	}
+
+	resp := testDB.API.Put("/api/v1/notifications/preferences", map[string]any{
+		"preferences": []map[string]any{{"type": models.NotificationTagPost, "enabled": false}},
+	}, headers[0])
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	resp = testDB.API.Get("/api/v1/notifications/preferences", headers[0])
+	var preferences notification.NotificationPreferencesResponse
+	DecodeTo(&preferences, resp)
+	if len(preferences.Preferences) != len(models.NotificationTypes) {
+		t.Fatalf("expected every notification type, got %+v", preferences.Preferences)
+	}
+	for _, p := range preferences.Preferences {
+		if p.Enabled != (p.Type != models.NotificationTagPost) {
+			t.Errorf("expected only tag posts turned off, got %+v", p)
+		}
+	}
+
+	// the follower turned tag posts off, so the unread notification isn't bumped
+	mustPost(t, testDB, "/api/v1/post/", newPost, headers[1])
+	result = getNotifications(t, testDB, headers[0], "")
+	if len(result.Notifications) != 1 || result.Notifications[0].ActorCount != 1 || !result.Notifications[0].UpdatedAt.Equal(result.Notifications[0].CreatedAt) {
+		t.Fatalf("expected no new tag activity after turning it off, got %+v", result.Notifications)
+	}
+}
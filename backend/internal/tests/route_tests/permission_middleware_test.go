@@ -42,7 +42,7 @@ func TestPermissionMiddleware_DeniesWithoutRolePermission(t *testing.T) {
 		"popularity": int32(100000),
 	}
 
-	resp := api.Post("/api/v1/sport/", body, "Authorization: Bearer "+userID.String())
+	resp := api.Post("/api/v1/sport/", body, authHeaderFor(userID.String()))
 	if resp.Code != http.StatusForbidden {
 		t.Fatalf("expected status 403, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -89,7 +89,7 @@ func TestPermissionMiddleware_AllowsWithRolePermission(t *testing.T) {
 		"popularity": int32(100000),
 	}
 
-	resp := api.Post("/api/v1/sport/", body, "Authorization: Bearer "+userID.String())
+	resp := api.Post("/api/v1/sport/", body, authHeaderFor(userID.String()))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -34,7 +34,7 @@ func TestPermissionCRUD(t *testing.T) {
 		Action:   "create",
 		Resource: "post",
 	}
-	createResp := api.Post("/api/v1/permission/", createBody, "Authorization: Bearer "+adminUserID.String())
+	createResp := api.Post("/api/v1/permission/", createBody, authHeaderFor(adminUserID.String()))
 	var created permission.PermissionResponse
 	switch createResp.Code {
 	case http.StatusOK:
@@ -56,7 +56,7 @@ func TestPermissionCRUD(t *testing.T) {
 		t.Fatalf("expected status 200 or 409, got %d: %s", createResp.Code, createResp.Body.String())
 	}
 
-	getResp := api.Get("/api/v1/permission/"+created.ID.String(), "Authorization: Bearer "+adminUserID.String())
+	getResp := api.Get("/api/v1/permission/"+created.ID.String(), authHeaderFor(adminUserID.String()))
 	if getResp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", getResp.Code, getResp.Body.String())
 	}
@@ -73,7 +73,7 @@ func TestPermissionCRUD(t *testing.T) {
 		Action:   &updateAction,
 		Resource: &updateResource,
 	}
-	updateResp := api.Patch("/api/v1/permission/"+created.ID.String(), updateBody, "Authorization: Bearer "+adminUserID.String())
+	updateResp := api.Patch("/api/v1/permission/"+created.ID.String(), updateBody, authHeaderFor(adminUserID.String()))
 	var updated permission.PermissionResponse
 	switch updateResp.Code {
 	case http.StatusOK:
@@ -95,7 +95,7 @@ func TestPermissionCRUD(t *testing.T) {
 		t.Fatalf("expected status 200 or 409, got %d: %s", updateResp.Code, updateResp.Body.String())
 	}
 
-	listResp := api.Get("/api/v1/permissions/", "Authorization: Bearer "+adminUserID.String())
+	listResp := api.Get("/api/v1/permissions/", authHeaderFor(adminUserID.String()))
 	if listResp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", listResp.Code, listResp.Body.String())
 	}
@@ -106,7 +106,7 @@ func TestPermissionCRUD(t *testing.T) {
 		t.Fatalf("expected at least one permission, got %+v", list)
 	}
 
-	deleteResp := api.Delete("/api/v1/permission/"+created.ID.String(), "Authorization: Bearer "+adminUserID.String())
+	deleteResp := api.Delete("/api/v1/permission/"+created.ID.String(), authHeaderFor(adminUserID.String()))
 	if deleteResp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", deleteResp.Code, deleteResp.Body.String())
 	}
@@ -87,6 +87,7 @@ func TestGetPostLikeInfo(t *testing.T) {
 	if err := testDB.DB.Create(&models.PostLike{UserID: user.ID, PostID: post.ID}).Error; err != nil {
 		t.Fatalf("create like: %v", err)
 	}
+	testDB.RepairCounters(t)
 
 	authHeader := authHeaderWithPermissionsGivenUser(t, testDB.DB, []permissionSpec{
 		{Action: models.PermissionCreate, Resource: "like"},
@@ -1,8 +1,11 @@
 package routeTests
 
 import (
+	"context"
 	"fmt"
 	"inside-athletics/internal/models"
+	"inside-athletics/internal/poststats"
+	"math"
 	"net/http"
 	"testing"
 	"time"
@@ -181,6 +184,11 @@ func TestGetPopularPostsOrdersByEngagementRecencyAndRelevance(t *testing.T) {
 		t.Fatalf("create old like: %v", err)
 	}
 
+	// likes and comments above were written straight to the DB, so run the trending job once
+	if _, err := poststats.NewStore(testDB.DB, poststats.DefaultConfig()).Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh post stats: %v", err)
+	}
+
 	authHeader := authHeaderWithPermissionsGivenUser(t, testDB.DB, nil, currentUser.ID)
 
 	resp := api.Get("/api/v1/posts/popular?limit=3&window_hours=72", authHeader)
@@ -222,3 +230,89 @@ func TestGetPopularPostsOrdersByEngagementRecencyAndRelevance(t *testing.T) {
 		t.Fatalf("expected top post score %.2f to exceed second post score %.2f", result.Posts[0].PopularityScore, result.Posts[1].PopularityScore)
 	}
 }
+
+func TestPostStatsFollowLikesAndComments(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
+	// seedUserAndPost is from comment_test
+	user, post := seedUserAndPost(t, testDB, "post-stats")
+
+	authHeader := authHeaderWithPermissionsGivenUser(t, testDB.DB, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "like"},
+		{Action: models.PermissionCreate, Resource: "user"},
+		{Action: models.PermissionCreate, Resource: "post"},
+		{Action: models.PermissionDelete, Resource: "post"},
+		{Action: models.PermissionDeleteOwn, Resource: "post"},
+		{Action: models.PermissionCreate, Resource: "comment"},
+	},
+		user.ID,
+	)
+
+	statsOf := func() models.PostStats {
+		var stats models.PostStats
+		if err := testDB.DB.First(&stats, "post_id = ?", post.ID).Error; err != nil {
+			t.Fatalf("load post stats: %v", err)
+		}
+		return stats
+	}
+
+	resp := api.Post("/api/v1/post/like", map[string]any{"post_id": post.ID.String()}, authHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200 liking post, got %d: %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Post("/api/v1/comment/", map[string]any{"post_id": post.ID.String(), "description": "Great thread"}, authHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200 commenting, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	stats := statsOf()
+	if stats.LikeCount != 1 || stats.CommentCount != 1 {
+		t.Fatalf("expected 1 like and 1 comment without waiting for the job, got %+v", stats)
+	}
+	weights := poststats.DefaultWeights
+	if want := weights.TrendingLike + weights.TrendingComment; math.Abs(stats.TrendingScore-want) > want*0.01 {
+		t.Fatalf("expected a fresh trending score of about %.2f, got %.4f", want, stats.TrendingScore)
+	}
+
+	resp = api.Delete("/api/v1/post/like/"+post.ID.String(), authHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200 unliking post, got %d: %s", resp.Code, resp.Body.String())
+	}
+	stats = statsOf()
+	if stats.LikeCount != 0 || stats.CommentCount != 1 {
+		t.Fatalf("expected the like to be taken back, got %+v", stats)
+	}
+
+	// the job agrees with what the hooks kept track of
+	if _, err := poststats.NewStore(testDB.DB, poststats.DefaultConfig()).Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh post stats: %v", err)
+	}
+	if refreshed := statsOf(); refreshed.LikeCount != 0 || refreshed.CommentCount != 1 {
+		t.Fatalf("expected refresh to keep the counts, got %+v", refreshed)
+	}
+}
+
+func TestRefreshOnlyRewritesChangedStats(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	author, post := seedUserAndPost(t, testDB, "refresh-rewrites")
+	if err := testDB.DB.Create(&models.PostLike{
+		ID:        uuid.New(),
+		UserID:    author.ID,
+		PostID:    post.ID,
+		CreatedAt: time.Now().Add(-time.Hour),
+	}).Error; err != nil {
+		t.Fatalf("create like: %v", err)
+	}
+
+	store := poststats.NewStore(testDB.DB, poststats.DefaultConfig())
+	if written, err := store.Refresh(context.Background()); err != nil || written != 1 {
+		t.Fatalf("expected the drifted stats to be written, got %d: %v", written, err)
+	}
+	if written, err := store.Refresh(context.Background()); err != nil || written != 0 {
+		t.Fatalf("expected nothing to be rewritten when nothing changed, got %d: %v", written, err)
+	}
+}
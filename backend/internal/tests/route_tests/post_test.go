@@ -47,7 +47,7 @@ func TestCreatePost(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 
 	CreateUserAndSport(testDB, t)
@@ -101,7 +101,7 @@ func TestCreatePostWithoutTagsThrowsError(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 
 	CreateUserAndSport(testDB, t)
@@ -131,7 +131,7 @@ func TestCreatePostWithTags(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 
 	CreateUserAndSport(testDB, t)
@@ -177,7 +177,7 @@ func TestGetPostById(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 	postDB := post.NewPostDB(testDB.DB)
 
@@ -236,7 +236,7 @@ func TestGetPostByIdWithLikes(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 	postDB := post.NewPostDB(testDB.DB)
 
@@ -290,6 +290,7 @@ func TestGetPostByIdWithLikes(t *testing.T) {
 	if err := testDB.DB.Create(&reply).Error; err != nil {
 		t.Fatalf("failed to create reply: %v", err)
 	}
+	testDB.RepairCounters(t)
 
 	resp := api.Get("/api/v1/post/"+createdPost.ID.String(), authHeader)
 	if resp.Code != http.StatusOK {
@@ -333,7 +334,7 @@ func TestGetPostByIdNotFound(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 
 	authHeader := authHeaderWithPermissions(t, testDB.DB, nil)
@@ -353,7 +354,7 @@ func TestBadValidation(t *testing.T) {
 		t.Fatalf("failed to migrate posts table: %v", err)
 	}
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 
 	authHeader := authHeaderWithPermissions(t, testDB.DB, nil)
@@ -379,7 +380,7 @@ func TestGetPostByAuthorId(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 	postDB := post.NewPostDB(testDB.DB)
 
@@ -404,6 +405,7 @@ func TestGetPostByAuthorId(t *testing.T) {
 	if err := testDB.DB.Create(&like).Error; err != nil {
 		t.Fatalf("failed to create like: %v", err)
 	}
+	testDB.RepairCounters(t)
 
 	_, err2 := postDB.CreatePost(&models.Post{
 		AuthorID: JohnID, SportID: &SoccerID,
@@ -442,7 +444,7 @@ func TestGetPostsBySportId(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 	postDB := post.NewPostDB(testDB.DB)
 
@@ -490,7 +492,7 @@ func TestGetAllPosts(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 	postDB := post.NewPostDB(testDB.DB)
 
@@ -533,12 +535,104 @@ func TestGetAllPosts(t *testing.T) {
 	}
 }
 
+func TestGetAllPostsCursorPagination(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+
+	api := testDB.API
+
+	postDB := post.NewPostDB(testDB.DB)
+
+	authHeader := authHeaderWithPermissions(t, testDB.DB, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "post"},
+	})
+
+	CreateUserAndSport(testDB, t)
+
+	created := make(map[uuid.UUID]bool)
+	for i := range 5 {
+		p, err := postDB.CreatePost(&models.Post{
+			AuthorID: JohnID, SportID: &SoccerID,
+			Title: fmt.Sprintf("Post %d", i), Content: "Paging through posts", IsAnonymous: false,
+		}, []post.TagRequest{})
+		if err != nil {
+			t.Fatalf("failed to create post: %v", err)
+		}
+		created[p.ID] = true
+	}
+
+	var pages []post.GetAllPostsResponse
+	url := "/api/v1/posts/?limit=2"
+	for len(pages) < 5 {
+		resp := api.Get(url, authHeader)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("Expected 200 got %d %s", resp.Code, resp.Body.String())
+		}
+		var page post.GetAllPostsResponse
+		DecodeTo(&page, resp)
+		pages = append(pages, page)
+		if page.NextCursor == nil {
+			break
+		}
+		url = "/api/v1/posts/?limit=2&cursor=" + *page.NextCursor
+	}
+
+	if len(pages) != 3 {
+		t.Fatalf("Expected 5 posts over 3 pages, got %d pages", len(pages))
+	}
+	if pages[0].Total != 5 || pages[1].Total != 0 {
+		t.Errorf("Expected the total only on the first page, got %d and %d", pages[0].Total, pages[1].Total)
+	}
+	if pages[0].PrevCursor != nil || pages[2].PrevCursor == nil {
+		t.Error("Expected a prev_cursor on every page but the first")
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	for _, page := range pages {
+		for _, p := range page.Posts {
+			if seen[p.ID] || !created[p.ID] {
+				t.Fatalf("Post %s returned twice or unexpectedly", p.ID)
+			}
+			seen[p.ID] = true
+		}
+	}
+	if len(seen) != 5 {
+		t.Fatalf("Expected to page through all 5 posts, saw %d", len(seen))
+	}
+
+	// a post created while paging shows up on the first page, not as a duplicate further down
+	if _, err := postDB.CreatePost(&models.Post{
+		AuthorID: JohnID, SportID: &SoccerID,
+		Title: "Newest post", Content: "Written mid scroll", IsAnonymous: false,
+	}, []post.TagRequest{}); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+	resp := api.Get("/api/v1/posts/?limit=2&cursor="+*pages[0].NextCursor, authHeader)
+	var second post.GetAllPostsResponse
+	DecodeTo(&second, resp)
+	if second.Posts[0].ID != pages[1].Posts[0].ID {
+		t.Errorf("Expected the second page to be stable under new writes")
+	}
+
+	resp = api.Get("/api/v1/posts/?limit=2&cursor="+*second.PrevCursor, authHeader)
+	var back post.GetAllPostsResponse
+	DecodeTo(&back, resp)
+	if len(back.Posts) != 2 || back.Posts[0].ID != pages[0].Posts[0].ID || back.Posts[1].ID != pages[0].Posts[1].ID {
+		t.Errorf("Expected prev_cursor to return the first page")
+	}
+
+	resp = api.Get("/api/v1/posts/?cursor=garbage", authHeader)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid cursor, got %d", resp.Code)
+	}
+}
+
 func TestUpdatePost(t *testing.T) {
 	t.Parallel()
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 	postDB := post.NewPostDB(testDB.DB)
 
@@ -588,7 +682,7 @@ func TestUpdatePostNotFound(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 
 	authHeader := authHeaderWithPermissions(t, testDB.DB, []permissionSpec{
@@ -607,7 +701,7 @@ func TestDeletePost(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 	postDB := post.NewPostDB(testDB.DB)
 
@@ -642,7 +736,7 @@ func TestDeletePostNotFound(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 
 	authHeader := authHeaderWithPermissions(t, testDB.DB, []permissionSpec{
@@ -661,7 +755,7 @@ func TestFreeUserCannotCreateSecondPost(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 
 	CreateUserAndSport(testDB, t)
@@ -757,7 +851,7 @@ func TestFreeUserGetPostReturns403AfterMaxViews(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 	postDB := post.NewPostDB(testDB.DB)
 
@@ -898,7 +992,7 @@ func TestPremiumUserCanCreateMultiplePosts(t *testing.T) {
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 
-	post.Route(testDB.API, testDB.DB, nil)
+	post.Route(testDB.API, testDB.DB, nil, nil)
 	api := testDB.API
 
 	CreateUserAndSport(testDB, t)
@@ -1261,6 +1355,144 @@ func TestModelsReturnedInFilter(t *testing.T) {
 	}
 }
 
+func TestPostSearchMatchesContentAndTags(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+
+	api := testDB.API
+
+	postDB := post.NewPostDB(testDB.DB)
+
+	authHeader := authHeaderWithPermissions(t, testDB.DB, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "post"},
+	})
+
+	CreateUserAndSport(testDB, t)
+
+	recruiting := models.Tag{ID: uuid.New(), Name: "Recruiting"}
+	if err := testDB.DB.Create(&recruiting).Error; err != nil {
+		t.Fatalf("failed to create tag: %v", err)
+	}
+
+	contentPost, err := postDB.CreatePost(&models.Post{
+		AuthorID: JohnID, SportID: &SoccerID,
+		Title: "Thoughts on walking on?", Content: "The fencing coaches were <b>really</b> welcoming at the open practice", IsAnonymous: false,
+	}, []post.TagRequest{})
+	if err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+
+	tagPost, err := postDB.CreatePost(&models.Post{
+		AuthorID: JohnID, SportID: &SoccerID,
+		Title: "When do coaches reach out?", Content: "Asking for a friend", IsAnonymous: false,
+	}, []post.TagRequest{{ID: recruiting.ID}})
+	if err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+
+	resp := api.Get("/api/v1/posts/search?search_str=fencing", authHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	var searchResp post.GetSearchResponse
+	DecodeTo(&searchResp, resp)
+
+	if searchResp.Count != 1 || searchResp.Posts[0].ID != contentPost.ID {
+		t.Fatalf("Expected only the post mentioning fencing in its content, got %d results", searchResp.Count)
+	}
+	if !strings.Contains(searchResp.Posts[0].Snippet, "<mark>fencing</mark>") {
+		t.Errorf("Expected snippet to highlight the match, got %q", searchResp.Posts[0].Snippet)
+	}
+	if !strings.Contains(searchResp.Posts[0].Snippet, "&lt;b&gt;really&lt;/b&gt;") {
+		t.Errorf("Expected the post's own markup to be escaped in the snippet, got %q", searchResp.Posts[0].Snippet)
+	}
+
+	resp = api.Get("/api/v1/posts/search?search_str=recruiting", authHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	DecodeTo(&searchResp, resp)
+
+	if searchResp.Count != 1 || searchResp.Posts[0].ID != tagPost.ID {
+		t.Fatalf("Expected only the post tagged Recruiting, got %d results", searchResp.Count)
+	}
+
+	// renaming a tag should update the search vector of every post using it
+	if err := testDB.DB.Model(&recruiting).Update("name", "Scholarships").Error; err != nil {
+		t.Fatalf("failed to rename tag: %v", err)
+	}
+	resp = api.Get("/api/v1/posts/search?search_str=scholarships", authHeader)
+	DecodeTo(&searchResp, resp)
+
+	if searchResp.Count != 1 || searchResp.Posts[0].ID != tagPost.ID {
+		t.Fatalf("Expected renamed tag to be searchable, got %d results", searchResp.Count)
+	}
+}
+
+func TestPostSearchWithFilters(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+
+	api := testDB.API
+
+	postDB := post.NewPostDB(testDB.DB)
+
+	authHeader := authHeaderWithPermissions(t, testDB.DB, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "post"},
+	})
+
+	CreateUserAndSport(testDB, t)
+
+	sportDB := sport.NewSportDB(testDB.DB)
+	popularity := int32(1)
+	fencing, err := sportDB.CreateSport("Fencing", &popularity)
+	if err != nil {
+		t.Fatalf("failed to create sport: %v", err)
+	}
+
+	soccerPost, err := postDB.CreatePost(&models.Post{
+		AuthorID: JohnID, SportID: &SoccerID,
+		Title: "How competitive is the team?", Content: "Looking at walking on next fall", IsAnonymous: false,
+	}, []post.TagRequest{})
+	if err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+
+	_, err = postDB.CreatePost(&models.Post{
+		AuthorID: JohnID, SportID: &fencing.ID,
+		Title: "Is the team competitive?", Content: "Thinking about walking on", IsAnonymous: false,
+	}, []post.TagRequest{})
+	if err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+
+	resp := api.Get("/api/v1/posts/search?search_str=competitive", authHeader)
+	var searchResp post.GetSearchResponse
+	DecodeTo(&searchResp, resp)
+
+	if searchResp.Count != 2 {
+		t.Fatalf("Expected 2 posts without filters but got %d", searchResp.Count)
+	}
+
+	resp = api.Get("/api/v1/posts/search?search_str=competitive&sport_ids="+SoccerID.String(), authHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	DecodeTo(&searchResp, resp)
+
+	if searchResp.Count != 1 || searchResp.Posts[0].ID != soccerPost.ID {
+		t.Fatalf("Expected only the soccer post but got %d results", searchResp.Count)
+	}
+	if searchResp.Posts[0].TitleHighlight != "How <mark>competitive</mark> is the team?" {
+		t.Errorf("Expected highlighted title, got %q", searchResp.Posts[0].TitleHighlight)
+	}
+
+	resp = api.Get("/api/v1/posts/search?search_str=competitive&sport_ids=not-a-uuid", authHeader)
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for malformed sport_ids got %d", resp.Code)
+	}
+}
+
 func uuidDereference(v *uuid.UUID) uuid.UUID {
 	return *v
 }
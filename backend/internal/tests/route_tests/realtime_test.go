@@ -0,0 +1,304 @@
+package routeTests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/realtime"
+	"inside-athletics/internal/server"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const realtimeTimeout = 10 * time.Second
+
+// startRealtimeServer serves the whole app on a local port with its realtime listener running, and
+// returns its base URL and a func that stops it.
+func startRealtimeServer(t *testing.T, testDB *TestDatabase) (string, func()) {
+	t.Helper()
+	app := server.CreateApp(testDB.DB, testIssuer.Verifier())
+	ctx, cancel := context.WithCancel(context.Background())
+	go app.Realtime.Run(ctx)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = app.Server.Listener(ln) }()
+	stop := func() {
+		cancel()
+		_ = app.Server.ShutdownWithTimeout(time.Second)
+	}
+
+	select {
+	case <-app.Realtime.Listening():
+	case <-time.After(realtimeTimeout):
+		stop()
+		t.Fatal("realtime listener never started")
+	}
+	return "http://" + ln.Addr().String(), stop
+}
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+// openStream opens a realtime stream for the user and reads its events in the background.
+func openStream(t *testing.T, baseURL string, userID uuid.UUID, topics string) (<-chan sseEvent, func()) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, baseURL+realtime.StreamPath+"?topics="+url.QueryEscape(topics), nil)
+	req.Header.Set("Authorization", "Bearer "+testIssuer.Token(userID.String()))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		resp.Body.Close()
+		t.Fatalf("expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	events := make(chan sseEvent, 32)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var event sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.data = strings.TrimPrefix(line, "data: ")
+			case line == "" && event.name != "":
+				events <- event
+				event = sseEvent{}
+			}
+		}
+	}()
+	return events, func() { resp.Body.Close() }
+}
+
+// waitForEvents reads events until one of each name has arrived, and returns them by name.
+func waitForEvents(t *testing.T, events <-chan sseEvent, names ...string) map[string]realtime.Message {
+	t.Helper()
+	got := map[string]realtime.Message{}
+	timeout := time.After(realtimeTimeout)
+	for len(got) < len(names) {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("stream closed while waiting for %v, got %v", names, got)
+			}
+			for _, name := range names {
+				if event.name == name {
+					var msg realtime.Message
+					if err := json.Unmarshal([]byte(event.data), &msg); err != nil {
+						t.Fatalf("decode %s event: %v", name, err)
+					}
+					got[name] = msg
+				}
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %v, got %v", names, got)
+		}
+	}
+	return got
+}
+
+func postJSON(t *testing.T, baseURL string, path string, body any, authHeader string) {
+	t.Helper()
+	raw, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, baseURL+path, bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", strings.TrimPrefix(authHeader, "Authorization: "))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST %s: %v", path, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST %s: expected status 200, got %d", path, resp.StatusCode)
+	}
+}
+
+func TestPGBrokerDeliversAcrossInstances(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hubA, hubB := realtime.NewHub(realtime.DefaultConfig()), realtime.NewHub(realtime.DefaultConfig())
+	brokerA, brokerB := realtime.NewPGBroker(testDB.DB, hubA), realtime.NewPGBroker(testDB.DB, hubB)
+	go brokerA.Run(ctx)
+	go brokerB.Run(ctx)
+	for _, broker := range []*realtime.PGBroker{brokerA, brokerB} {
+		select {
+		case <-broker.Listening():
+		case <-time.After(realtimeTimeout):
+			t.Fatal("realtime listener never started")
+		}
+	}
+
+	topic := realtime.PostLikesTopic(uuid.New())
+	onA, onB := hubA.Subscribe(topic), hubB.Subscribe(topic)
+	defer onA.Close()
+	defer onB.Close()
+
+	sent := realtime.Message{Topic: topic, Event: realtime.EventPostLikes, Data: json.RawMessage(`{"like_count":3}`)}
+	if err := brokerA.Publish(ctx, sent); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	// the publishing instance gets its own message back through Postgres too
+	for name, sub := range map[string]*realtime.Subscription{"publishing": onA, "other": onB} {
+		select {
+		case got := <-sub.Messages():
+			if got.Topic != topic || got.Event != sent.Event || string(got.Data) != string(sent.Data) {
+				t.Fatalf("expected %+v on the %s instance, got %+v", sent, name, got)
+			}
+		case <-time.After(realtimeTimeout):
+			t.Fatalf("message never reached the %s instance", name)
+		}
+	}
+
+	tooLarge := realtime.Message{Topic: topic, Event: "test", Data: json.RawMessage(`"` + strings.Repeat("x", 8000) + `"`)}
+	if err := brokerA.Publish(ctx, tooLarge); !errors.Is(err, realtime.ErrPayloadTooLarge) {
+		t.Fatalf("expected ErrPayloadTooLarge, got %v", err)
+	}
+}
+
+func TestRealtimeStreamSendsCommentsLikesAndNotifications(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	author, post := seedUserAndPost(t, testDB, "realtime-author")
+	users, headers := seedNotificationUsers(t, testDB, "realtime", 1)
+	baseURL, stop := startRealtimeServer(t, testDB)
+	defer stop()
+
+	events, closeStream := openStream(t, baseURL, author.ID, "notifications,post:"+post.ID.String()+":comments,post:"+post.ID.String()+":likes")
+	defer closeStream()
+	waitForEvents(t, events, "ready")
+
+	postJSON(t, baseURL, "/api/v1/comment/", map[string]any{"post_id": post.ID, "description": "Live", "is_anonymous": true}, headers[0])
+	got := waitForEvents(t, events, realtime.EventCommentCreated, realtime.EventNotificationSent)
+	var created realtime.CommentCreatedData
+	if err := json.Unmarshal(got[realtime.EventCommentCreated].Data, &created); err != nil || created.PostID != post.ID {
+		t.Fatalf("expected the new comment on the post, got %s", got[realtime.EventCommentCreated].Data)
+	}
+	if strings.Contains(string(got[realtime.EventCommentCreated].Data), users[0].ID.String()) {
+		t.Fatal("expected the anonymous commenter to be left out")
+	}
+	var notified realtime.NotificationSentData
+	if err := json.Unmarshal(got[realtime.EventNotificationSent].Data, &notified); err != nil || notified.Type != models.NotificationPostComment {
+		t.Fatalf("expected a comment notification, got %s", got[realtime.EventNotificationSent].Data)
+	}
+
+	postJSON(t, baseURL, "/api/v1/post/like", map[string]any{"post_id": post.ID}, headers[0])
+	got = waitForEvents(t, events, realtime.EventPostLikes)
+	var likes realtime.PostLikesData
+	if err := json.Unmarshal(got[realtime.EventPostLikes].Data, &likes); err != nil || likes.PostID != post.ID || likes.LikeCount != 1 {
+		t.Fatalf("expected the post's like count to be 1, got %s", got[realtime.EventPostLikes].Data)
+	}
+}
+
+func TestRealtimeStreamLeavesOutShadowBannedComments(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	author, post := seedUserAndPost(t, testDB, "realtime-shadow-author")
+	users, headers := seedNotificationUsers(t, testDB, "realtime-shadow", 2)
+	if err := testDB.DB.Create(&models.Sanction{UserID: users[0].ID, Type: models.SanctionShadowBan, Reason: "Spam"}).Error; err != nil {
+		t.Fatalf("failed to shadow-ban user: %v", err)
+	}
+	baseURL, stop := startRealtimeServer(t, testDB)
+	defer stop()
+
+	events, closeStream := openStream(t, baseURL, author.ID, "post:"+post.ID.String()+":comments")
+	defer closeStream()
+	waitForEvents(t, events, "ready")
+
+	postJSON(t, baseURL, "/api/v1/comment/", map[string]any{"post_id": post.ID, "description": "Hidden", "is_anonymous": false}, headers[0])
+	postJSON(t, baseURL, "/api/v1/comment/", map[string]any{"post_id": post.ID, "description": "Shown", "is_anonymous": false}, headers[1])
+	got := waitForEvents(t, events, realtime.EventCommentCreated)
+	var created realtime.CommentCreatedData
+	if err := json.Unmarshal(got[realtime.EventCommentCreated].Data, &created); err != nil {
+		t.Fatalf("decode comment event: %v", err)
+	}
+	var shown models.Comment
+	if err := testDB.DB.First(&shown, "id = ?", created.CommentID).Error; err != nil || shown.UserID != users[1].ID {
+		t.Fatalf("expected only the comment by the user in good standing to be streamed, got %s", got[realtime.EventCommentCreated].Data)
+	}
+}
+
+func TestRealtimeStreamRejectsBadTopics(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	baseURL, stop := startRealtimeServer(t, testDB)
+	defer stop()
+
+	token := testIssuer.Token(uuid.NewString())
+	for _, topics := range []string{"", "post:not-a-uuid:comments", "user:" + uuid.NewString() + ":notifications", "post:" + uuid.NewString() + ":views"} {
+		req, _ := http.NewRequest(http.MethodGet, baseURL+realtime.StreamPath+"?topics="+url.QueryEscape(topics), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("open stream: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status 400 for topics %q, got %d", topics, resp.StatusCode)
+		}
+	}
+
+	resp, err := http.Get(baseURL + realtime.StreamPath + "?topics=notifications")
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 without a token, got %d", resp.StatusCode)
+	}
+}
+
+func TestRealtimeStreamRejectsBannedAndSuspendedUsers(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	users, _ := seedNotificationUsers(t, testDB, "realtime-sanctioned", 2)
+	until := time.Now().Add(time.Hour)
+	for _, s := range []models.Sanction{
+		{UserID: users[0].ID, Type: models.SanctionBan, Reason: "Spam"},
+		{UserID: users[1].ID, Type: models.SanctionSuspend, Reason: "Spam", ExpiresAt: &until},
+	} {
+		if err := testDB.DB.Create(&s).Error; err != nil {
+			t.Fatalf("failed to sanction user: %v", err)
+		}
+	}
+	baseURL, stop := startRealtimeServer(t, testDB)
+	defer stop()
+
+	for _, user := range users {
+		req, _ := http.NewRequest(http.MethodGet, baseURL+realtime.StreamPath+"?topics=notifications", nil)
+		req.Header.Set("Authorization", "Bearer "+testIssuer.Token(user.ID.String()))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("open stream: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected status 403 for a sanctioned user, got %d", resp.StatusCode)
+		}
+	}
+}
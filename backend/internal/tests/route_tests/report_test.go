@@ -0,0 +1,161 @@
+package routeTests
+
+import (
+	"inside-athletics/internal/handlers/report"
+	"inside-athletics/internal/models"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// seedReporters creates n users with the user role who may report content.
+func seedReporters(t *testing.T, testDB *TestDatabase, unique string, n int) []string {
+	t.Helper()
+	headers := make([]string, n)
+	for i := range headers {
+		user := newCommentTestUser(uuid.New(), unique+"-"+string(rune('a'+i)))
+		if err := testDB.DB.Create(&user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		headers[i] = authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, []permissionSpec{
+			{Action: models.PermissionCreate, Resource: "report"},
+		}, user.ID)
+	}
+	return headers
+}
+
+// seedModerator creates a moderator who may work the queue with every action.
+func seedModerator(t *testing.T, testDB *TestDatabase, unique string) string {
+	t.Helper()
+	user := newCommentTestUser(uuid.New(), unique)
+	if err := testDB.DB.Create(&user).Error; err != nil {
+		t.Fatalf("create moderator: %v", err)
+	}
+	perms := []permissionSpec{{Action: models.PermissionCreate, Resource: "report"}, {Action: models.PermissionReview, Resource: "report"}}
+	for _, action := range []models.PermissionAction{models.PermissionDismiss, models.PermissionRemove, models.PermissionWarn, models.PermissionSuspend} {
+		perms = append(perms, permissionSpec{Action: action, Resource: "report"})
+	}
+	return authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleModerator, perms, user.ID)
+}
+
+func reportPost(t *testing.T, testDB *TestDatabase, postID uuid.UUID, authHeader string) int {
+	t.Helper()
+	return testDB.API.Post("/api/v1/report/", map[string]any{
+		"content_type": "post",
+		"content_id":   postID,
+		"reason":       "spam",
+	}, authHeader).Code
+}
+
+func getQueue(t *testing.T, testDB *TestDatabase, authHeader string) report.GetQueueResponse {
+	t.Helper()
+	resp := testDB.API.Get("/api/v1/report/queue", authHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var result report.GetQueueResponse
+	DecodeTo(&result, resp)
+	return result
+}
+
+func TestReportsHideContentUntilDismissed(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	author, post := seedUserAndPost(t, testDB, "report-author")
+	authorHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "report"},
+	}, author.ID)
+	reporters := seedReporters(t, testDB, "reporter", report.DefaultHideThreshold)
+	moderator := seedModerator(t, testDB, "report-moderator")
+	postPath := "/api/v1/post/" + post.ID.String()
+
+	if code := reportPost(t, testDB, post.ID, authorHeader); code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 reporting your own post, got %d", code)
+	}
+	for i, header := range reporters {
+		if code := reportPost(t, testDB, post.ID, header); code != http.StatusOK {
+			t.Fatalf("report %d: expected status 200, got %d", i, code)
+		}
+		// the post stays up until the last report reaches the threshold
+		visible := testDB.API.Get(postPath, authorHeader).Code == http.StatusOK
+		if last := i == len(reporters)-1; visible == last {
+			t.Fatalf("after %d reports expected visible=%v", i+1, !last)
+		}
+	}
+	if code := reportPost(t, testDB, post.ID, reporters[0]); code != http.StatusConflict {
+		t.Fatalf("expected status 409 for a second report, got %d", code)
+	}
+
+	if resp := testDB.API.Get("/api/v1/report/queue", reporters[0]); resp.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a user reading the queue, got %d", resp.Code)
+	}
+	queue := getQueue(t, testDB, moderator)
+	if len(queue.Items) != 1 || queue.Total != 1 {
+		t.Fatalf("expected one queued item, got %+v", queue)
+	}
+	item := queue.Items[0]
+	if item.ContentID != post.ID || item.ReportCount != int64(len(reporters)) || len(item.Reports) != len(reporters) {
+		t.Fatalf("expected the post with all its reports, got %+v", item)
+	}
+	if len(item.Reasons) != 1 || item.Reasons[0].Reason != models.ReportReasonSpam || item.Reasons[0].Count != int64(len(reporters)) {
+		t.Fatalf("expected every report counted as spam, got %+v", item.Reasons)
+	}
+	if item.Content == nil || !item.Content.Hidden || item.Content.Body != post.Content || item.Author == nil || item.Author.ID != author.ID {
+		t.Fatalf("expected the hidden post and its author, got %+v %+v", item.Content, item.Author)
+	}
+
+	resp := testDB.API.Post("/api/v1/report/queue/post/"+post.ID.String()+"/actions", map[string]any{"action": "dismiss"}, moderator)
+	var action models.ModerationAction
+	DecodeTo(&action, resp)
+	if resp.Code != http.StatusOK || action.ReportsResolved != int64(len(reporters)) {
+		t.Fatalf("expected the reports dismissed, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if code := testDB.API.Get(postPath, authorHeader).Code; code != http.StatusOK {
+		t.Fatalf("expected the dismissed post visible again, got %d", code)
+	}
+	if queue = getQueue(t, testDB, moderator); len(queue.Items) != 0 {
+		t.Fatalf("expected an empty queue, got %+v", queue)
+	}
+}
+
+func TestSuspendRemovesContentAndBlocksAuthor(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	author, post := seedUserAndPost(t, testDB, "suspend-author")
+	authorHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "comment"},
+	}, author.ID)
+	reporters := seedReporters(t, testDB, "suspend-reporter", 1)
+	moderator := seedModerator(t, testDB, "suspend-moderator")
+
+	if code := reportPost(t, testDB, post.ID, reporters[0]); code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	actionsPath := "/api/v1/report/queue/post/" + post.ID.String() + "/actions"
+	if resp := testDB.API.Post(actionsPath, map[string]any{"action": "suspend"}, reporters[0]); resp.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a user taking action, got %d", resp.Code)
+	}
+	mustPost(t, testDB, actionsPath, map[string]any{"action": "suspend", "note": "Spam", "suspend_days": 2}, moderator)
+
+	if code := testDB.API.Get("/api/v1/post/"+post.ID.String(), authorHeader).Code; code != http.StatusNotFound {
+		t.Fatalf("expected the post removed, got %d", code)
+	}
+	resp := testDB.API.Post("/api/v1/comment/", map[string]any{"post_id": post.ID, "description": "Still here"}, authorHeader)
+	if resp.Code != http.StatusForbidden || !strings.Contains(resp.Body.String(), "suspended") {
+		t.Fatalf("expected a suspended author to be blocked, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp = testDB.API.Get("/api/v1/report/actions?target_user_id="+author.ID.String(), moderator)
+	var actions report.GetActionsResponse
+	DecodeTo(&actions, resp)
+	if resp.Code != http.StatusOK || len(actions.Actions) != 1 {
+		t.Fatalf("expected one recorded action, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if a := actions.Actions[0]; a.Action != models.ModerationSuspend || a.ReportsResolved != 1 || a.SuspendedUntil == nil || a.Note == nil || *a.Note != "Spam" {
+		t.Fatalf("expected the suspension in the audit trail, got %+v", a)
+	}
+}
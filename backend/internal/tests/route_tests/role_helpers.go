@@ -20,5 +20,5 @@ func getRoleID(t *testing.T, db *gorm.DB, name models.RoleName) uuid.UUID {
 }
 
 func authHeader() string {
-	return "Authorization: Bearer " + uuid.NewString()
+	return authHeaderFor(uuid.NewString())
 }
@@ -32,7 +32,7 @@ func TestRoleCRUD(t *testing.T) {
 
 	roleName := "coach_" + uuid.NewString()
 	createBody := role.CreateRoleRequest{Name: roleName}
-	createResp := api.Post("/api/v1/role/", createBody, "Authorization: Bearer "+adminUserID.String())
+	createResp := api.Post("/api/v1/role/", createBody, authHeaderFor(adminUserID.String()))
 	if createResp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", createResp.Code, createResp.Body.String())
 	}
@@ -43,7 +43,7 @@ func TestRoleCRUD(t *testing.T) {
 		t.Fatalf("unexpected role name: %+v", created)
 	}
 
-	getResp := api.Get("/api/v1/role/"+created.ID.String(), "Authorization: Bearer "+adminUserID.String())
+	getResp := api.Get("/api/v1/role/"+created.ID.String(), authHeaderFor(adminUserID.String()))
 	if getResp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", getResp.Code, getResp.Body.String())
 	}
@@ -56,7 +56,7 @@ func TestRoleCRUD(t *testing.T) {
 
 	updateName := "assistant_coach"
 	updateBody := role.UpdateRoleRequest{Name: &updateName}
-	updateResp := api.Patch("/api/v1/role/"+created.ID.String(), updateBody, "Authorization: Bearer "+adminUserID.String())
+	updateResp := api.Patch("/api/v1/role/"+created.ID.String(), updateBody, authHeaderFor(adminUserID.String()))
 	if updateResp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", updateResp.Code, updateResp.Body.String())
 	}
@@ -67,7 +67,7 @@ func TestRoleCRUD(t *testing.T) {
 		t.Fatalf("unexpected updated role: %+v", updated)
 	}
 
-	listResp := api.Get("/api/v1/roles/", "Authorization: Bearer "+adminUserID.String())
+	listResp := api.Get("/api/v1/roles/", authHeaderFor(adminUserID.String()))
 	if listResp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", listResp.Code, listResp.Body.String())
 	}
@@ -78,7 +78,7 @@ func TestRoleCRUD(t *testing.T) {
 		t.Fatalf("expected at least one role, got %+v", list)
 	}
 
-	deleteResp := api.Delete("/api/v1/role/"+created.ID.String(), "Authorization: Bearer "+adminUserID.String())
+	deleteResp := api.Delete("/api/v1/role/"+created.ID.String(), authHeaderFor(adminUserID.String()))
 	if deleteResp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", deleteResp.Code, deleteResp.Body.String())
 	}
@@ -108,12 +108,12 @@ func TestCreateRoleDuplicateReturnsConflict(t *testing.T) {
 	roleName := "dup_role_" + uuid.NewString()[:8]
 	createBody := role.CreateRoleRequest{Name: roleName}
 
-	firstResp := api.Post("/api/v1/role/", createBody, "Authorization: Bearer "+adminUserID.String())
+	firstResp := api.Post("/api/v1/role/", createBody, authHeaderFor(adminUserID.String()))
 	if firstResp.Code != http.StatusOK {
 		t.Fatalf("expected first create status 200, got %d: %s", firstResp.Code, firstResp.Body.String())
 	}
 
-	secondResp := api.Post("/api/v1/role/", createBody, "Authorization: Bearer "+adminUserID.String())
+	secondResp := api.Post("/api/v1/role/", createBody, authHeaderFor(adminUserID.String()))
 	if secondResp.Code != http.StatusConflict {
 		t.Fatalf("expected duplicate create status 409, got %d: %s", secondResp.Code, secondResp.Body.String())
 	}
@@ -0,0 +1,152 @@
+package routeTests
+
+import (
+	"inside-athletics/internal/handlers/comment"
+	"inside-athletics/internal/handlers/sanction"
+	"inside-athletics/internal/models"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// seedSanctionAdmin creates an admin who may apply, read and lift sanctions.
+func seedSanctionAdmin(t *testing.T, testDB *TestDatabase, unique string) string {
+	t.Helper()
+	admin := newCommentTestUser(uuid.New(), unique)
+	if err := testDB.DB.Create(&admin).Error; err != nil {
+		t.Fatalf("create admin: %v", err)
+	}
+	return authHeaderWithPermissionsGivenUser(t, testDB.DB, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "sanction"},
+		{Action: models.PermissionRead, Resource: "sanction"},
+		{Action: models.PermissionDelete, Resource: "sanction"},
+	}, admin.ID)
+}
+
+func applySanction(t *testing.T, testDB *TestDatabase, adminHeader string, body map[string]any) models.Sanction {
+	t.Helper()
+	resp := testDB.API.Post("/api/v1/sanctions/", body, adminHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 applying %v, got %d: %s", body["type"], resp.Code, resp.Body.String())
+	}
+	var result models.Sanction
+	DecodeTo(&result, resp)
+	return result
+}
+
+func TestBansAndSuspensionsAreEnforcedUntilLifted(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	target, post := seedUserAndPost(t, testDB, "sanction-target")
+	targetHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "post"},
+	}, target.ID)
+	adminHeader := seedSanctionAdmin(t, testDB, "sanction-admin")
+	postPath := "/api/v1/post/" + post.ID.String()
+	like := func() int {
+		return testDB.API.Post("/api/v1/post/like", map[string]any{"post_id": post.ID}, targetHeader).Code
+	}
+
+	if resp := testDB.API.Post("/api/v1/sanctions/", map[string]any{"user_id": target.ID, "type": "suspend", "reason": "Spam"}, adminHeader); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for a suspension without an expiry, got %d", resp.Code)
+	}
+	if resp := testDB.API.Get("/api/v1/sanctions/", targetHeader); resp.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a user listing sanctions, got %d", resp.Code)
+	}
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	suspension := applySanction(t, testDB, adminHeader, map[string]any{"user_id": target.ID, "type": "suspend", "reason": "Spam", "expires_at": expiresAt})
+	if code := testDB.API.Get(postPath, targetHeader).Code; code != http.StatusOK {
+		t.Fatalf("expected a suspended user to still read, got %d", code)
+	}
+	resp := testDB.API.Post("/api/v1/post/like", map[string]any{"post_id": post.ID}, targetHeader)
+	if resp.Code != http.StatusForbidden || !strings.Contains(resp.Body.String(), "suspended until") {
+		t.Fatalf("expected a suspended user blocked from writing, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	ban := applySanction(t, testDB, adminHeader, map[string]any{"user_id": target.ID, "type": "ban", "reason": "Ban evasion"})
+	resp = testDB.API.Get(postPath, targetHeader)
+	if resp.Code != http.StatusForbidden || !strings.Contains(resp.Body.String(), "banned") {
+		t.Fatalf("expected a banned user blocked from reading, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp = testDB.API.Get("/api/v1/sanctions/users/"+target.ID.String(), adminHeader)
+	var state sanction.AccountState
+	DecodeTo(&state, resp)
+	if resp.Code != http.StatusOK || state.Status != models.AccountBanned || state.Until != nil || len(state.Sanctions) != 2 {
+		t.Fatalf("expected a permanent ban over the suspension, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	if resp = testDB.API.Delete("/api/v1/sanctions/"+ban.ID.String(), adminHeader, map[string]any{"reason": "Appeal accepted"}); resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 lifting the ban, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp = testDB.API.Delete("/api/v1/sanctions/"+ban.ID.String(), adminHeader, map[string]any{}); resp.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 lifting the ban twice, got %d", resp.Code)
+	}
+	if code := testDB.API.Get(postPath, targetHeader).Code; code != http.StatusOK {
+		t.Fatalf("expected reads allowed once the ban is lifted, got %d", code)
+	}
+	if code := like(); code != http.StatusForbidden {
+		t.Fatalf("expected the suspension to outlast the ban, got %d", code)
+	}
+
+	if resp = testDB.API.Delete("/api/v1/sanctions/"+suspension.ID.String(), adminHeader, map[string]any{}); resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 lifting the suspension, got %d", resp.Code)
+	}
+	if code := like(); code != http.StatusOK {
+		t.Fatalf("expected writes allowed once every sanction is lifted, got %d", code)
+	}
+}
+
+func TestShadowBannedContentIsOnlyVisibleToItsAuthor(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	banned, bannedPost := seedUserAndPost(t, testDB, "shadow-banned")
+	bannedHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, banned.ID)
+	_, thread := seedUserAndPost(t, testDB, "shadow-thread")
+	viewer := newCommentTestUser(uuid.New(), "shadow-viewer")
+	if err := testDB.DB.Create(&viewer).Error; err != nil {
+		t.Fatalf("create viewer: %v", err)
+	}
+	viewerHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, viewer.ID)
+	adminHeader := seedSanctionAdmin(t, testDB, "shadow-admin")
+
+	reply := models.Comment{UserID: banned.ID, PostID: thread.ID, Description: "Only I can see this"}
+	if err := testDB.DB.Create(&reply).Error; err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	applySanction(t, testDB, adminHeader, map[string]any{"user_id": banned.ID, "type": "shadow_ban", "reason": "Spam"})
+
+	postPath := "/api/v1/post/" + bannedPost.ID.String()
+	if code := testDB.API.Get(postPath, bannedHeader).Code; code != http.StatusOK {
+		t.Fatalf("expected the author to see their own post, got %d", code)
+	}
+	if code := testDB.API.Get(postPath, viewerHeader).Code; code != http.StatusNotFound {
+		t.Fatalf("expected the post hidden from everyone else, got %d", code)
+	}
+
+	commentCount := func(header string) int {
+		t.Helper()
+		if code := testDB.API.Get("/api/v1/post/"+thread.ID.String(), header).Code; code != http.StatusOK {
+			t.Fatalf("expected status 200 viewing the thread, got %d", code)
+		}
+		resp := testDB.API.Get("/api/v1/post/"+thread.ID.String()+"/comments", header)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status 200 listing comments, got %d: %s", resp.Code, resp.Body.String())
+		}
+		var comments []comment.CommentResponse
+		DecodeTo(&comments, resp)
+		return len(comments)
+	}
+	if n := commentCount(bannedHeader); n != 1 {
+		t.Fatalf("expected the author to see their comment, got %d comments", n)
+	}
+	if n := commentCount(viewerHeader); n != 0 {
+		t.Fatalf("expected the comment hidden from everyone else, got %d comments", n)
+	}
+}
@@ -2,6 +2,8 @@ package routeTests
 
 import (
 	s "inside-athletics/internal/handlers/stripe"
+	"inside-athletics/internal/models"
+	"net/http"
 	"testing"
 
 	"github.com/google/uuid"
@@ -16,7 +18,7 @@ func TestCreateProduct(t *testing.T) {
 	resp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name:        "Premium Plan",
 		Description: "Get premium content with this subscription",
-	}, "Authorization: Bearer "+uuid.NewString())
+	}, stripeAdminHeader(t, testDB))
 	var prod s.StripeProductResponse
 	DecodeTo(&prod, resp)
 
@@ -34,7 +36,7 @@ func TestGetProductByID(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
 	name := "Premium Plan"
 	description := "Get premium content with this subscription"
 
@@ -67,7 +69,7 @@ func TestUpdateStripeProduct(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
 	createResp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name:        "Premium Plan",
 		Description: "Get premium content with this subscription",
@@ -99,7 +101,7 @@ func TestArchiveStripeProduct(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
 	createResp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name:        "Premium Plan",
 		Description: "Get premium content with this subscription",
@@ -126,7 +128,7 @@ func TestGetAllProducts(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
 	api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name: "Premium Plan", Description: "Get premium content with this subscription",
 	}, auth)
@@ -149,7 +151,7 @@ func TestCreatePrice(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
 	createProdResp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name: "Premium Plan", Description: "Get premium content with this subscription",
 	}, auth)
@@ -192,7 +194,7 @@ func TestGetStripePriceByID(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
 	createProdResp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name: "Premium Plan", Description: "Get premium content with this subscription",
 	}, auth)
@@ -239,7 +241,7 @@ func TestUpdateStripePrice(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
 	createProdResp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name: "Premium Plan", Description: "Get premium content with this subscription",
 	}, auth)
@@ -290,7 +292,7 @@ func TestArchiveStripePrice(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
 	createProdResp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name: "Premium Plan", Description: "Get premium content with this subscription",
 	}, auth)
@@ -325,7 +327,7 @@ func TestGetAllStripePrices(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
 	createProdResp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name: "Premium Plan", Description: "Get premium content with this subscription",
 	}, auth)
@@ -356,7 +358,7 @@ func TestGetCustomer(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := authHeaderFor(uuid.NewString())
 	name := "Suli"
 	email := "suli@gmail.com"
 	phone := "888 420 6769"
@@ -389,7 +391,7 @@ func TestGetCustomerByEmail(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := authHeaderFor(uuid.NewString())
 	name := "Suli"
 	email := "suli_newemail@gmail.com"
 	phone := "888 420 6769"
@@ -417,7 +419,7 @@ func TestRegisterCustomer(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := authHeaderFor(uuid.NewString())
 	name := "Suli"
 	email := "suli@gmail.com"
 	phone := "888 420 6769"
@@ -454,7 +456,9 @@ func TestUpdateCustomer(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	auth := "Authorization: Bearer " + uuid.NewString()
+	userID, auth := seedUserWithRoleAndPermissions(t, testDB.DB, models.RoleUser, []permissionSpec{
+		{Action: models.PermissionUpdateOwn, Resource: "stripe_customer"},
+	})
 	name := "Suli"
 	email := "suli@gmail.com"
 	phone := "888 420 6769"
@@ -468,6 +472,9 @@ func TestUpdateCustomer(t *testing.T) {
 	}, auth)
 	var created s.RegisterStripeCustomerResponse
 	DecodeTo(&created, createResp)
+	if err := testDB.DB.Model(&models.User{}).Where("id = ?", userID).Update("stripe_customer_id", created.ID).Error; err != nil {
+		t.Fatalf("failed to link customer to user: %v", err)
+	}
 
 	updatedName := "New Name"
 	updatedEmail := "updatedemail@gmail.com"
@@ -491,6 +498,47 @@ func TestUpdateCustomer(t *testing.T) {
 	}
 }
 
+func TestCustomerChangesNeedOwnership(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
+
+	email := "owner@gmail.com"
+	createResp := api.Post("/api/v1/stripe_customers/", s.RegisterStripeCustomerBody{
+		Email: &email,
+	}, authHeaderFor(uuid.NewString()))
+	var created s.RegisterStripeCustomerResponse
+	DecodeTo(&created, createResp)
+
+	_, auth := seedUserWithRoleAndPermissions(t, testDB.DB, models.RoleUser, []permissionSpec{
+		{Action: models.PermissionUpdateOwn, Resource: "stripe_customer"},
+		{Action: models.PermissionDeleteOwn, Resource: "stripe_customer"},
+	})
+	updatedEmail := "stolen@gmail.com"
+	if resp := api.Patch("/api/v1/stripe_customers/"+created.ID, s.UpdateStripeCustomerBody{Email: &updatedEmail}, auth); resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 updating someone else's customer, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := api.Delete("/api/v1/stripe_customers/"+created.ID, auth); resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 deleting someone else's customer, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestProductChangesNeedPermission(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
+
+	_, auth := seedUserWithRoleAndPermissions(t, testDB.DB, models.RoleUser, nil)
+	resp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
+		Name: "Free Plan", Description: "Not yours to make",
+	}, auth)
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 creating a product, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
 func TestCreateCheckoutSession(t *testing.T) {
 	t.Parallel()
 	testDB := SetupTestDB(t)
@@ -498,7 +546,8 @@ func TestCreateCheckoutSession(t *testing.T) {
 	api := testDB.API
 
 	user, _ := seedUserAndCollege(t, testDB, "create-checkout-session")
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
+	userAuth := authHeaderFor(user.ID.String())
 
 	createProdResp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name: "Premium Plan", Description: "Get premium content with this subscription",
@@ -521,7 +570,7 @@ func TestCreateCheckoutSession(t *testing.T) {
 		SuccessURL: "https://example.com/success",
 		CancelURL:  "https://example.com/cancel",
 		Quantity:   2,
-	}, auth)
+	}, userAuth)
 	var session s.StripeCheckoutSessionResponse
 	DecodeTo(&session, resp)
 
@@ -543,7 +592,8 @@ func TestGetStripeCheckoutSessionByID(t *testing.T) {
 	api := testDB.API
 
 	user, _ := seedUserAndCollege(t, testDB, "get-checkout-session-by-id")
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
+	userAuth := authHeaderFor(user.ID.String())
 
 	createProdResp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name: "Premium Plan", Description: "Get premium content with this subscription",
@@ -566,7 +616,7 @@ func TestGetStripeCheckoutSessionByID(t *testing.T) {
 		SuccessURL: "https://example.com/success",
 		CancelURL:  "https://example.com/cancel",
 		Quantity:   1,
-	}, auth)
+	}, userAuth)
 	var createdSession s.StripeCheckoutSessionResponse
 	DecodeTo(&createdSession, createSessionResp)
 
@@ -596,7 +646,8 @@ func TestDeleteStripeCheckoutSession(t *testing.T) {
 	api := testDB.API
 
 	user, _ := seedUserAndCollege(t, testDB, "delete-checkout-session")
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
+	userAuth := authHeaderFor(user.ID.String())
 
 	createProdResp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name: "Premium Plan", Description: "Get premium content with this subscription",
@@ -619,14 +670,14 @@ func TestDeleteStripeCheckoutSession(t *testing.T) {
 		SuccessURL: "https://example.com/success",
 		CancelURL:  "https://example.com/cancel",
 		Quantity:   1,
-	}, auth)
+	}, userAuth)
 	var createdSession s.StripeCheckoutSessionResponse
 	DecodeTo(&createdSession, createSessionResp)
 
 	resp := api.Delete(
 		"/api/v1/checkout/sessions/"+createdSession.ID,
 		s.DeleteCheckoutSessionRequest{ID: createdSession.ID},
-		auth,
+		userAuth,
 	)
 	var session s.StripeCheckoutSessionResponse
 	DecodeTo(&session, resp)
@@ -636,6 +687,43 @@ func TestDeleteStripeCheckoutSession(t *testing.T) {
 	}
 }
 
+func TestCheckoutSessionsAreTheUsersOwn(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
+
+	user, _ := seedUserAndCollege(t, testDB, "own-checkout-session")
+	other, _ := seedUserAndCollege(t, testDB, "other-checkout-session")
+	userAuth := authHeaderFor(user.ID.String())
+	otherAuth := authHeaderFor(other.ID.String())
+
+	request := s.CreateStripeCheckoutSessionRequest{
+		UserID:     user.ID.String(),
+		PriceID:    "price_1",
+		SuccessURL: "https://example.com/success",
+		CancelURL:  "https://example.com/cancel",
+		Quantity:   1,
+	}
+	resp := api.Post("/api/v1/checkout/sessions/", request, otherAuth)
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 checking out for another user, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp = api.Post("/api/v1/checkout/sessions/", request, userAuth)
+	var created s.StripeCheckoutSessionResponse
+	DecodeTo(&created, resp)
+
+	resp = api.Delete("/api/v1/checkout/sessions/"+created.ID, otherAuth)
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 cancelling another user's checkout, got %d: %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Delete("/api/v1/checkout/sessions/"+created.ID, userAuth)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200 cancelling your own checkout, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
 func TestGetAllStripeSessions(t *testing.T) {
 	t.Parallel()
 	testDB := SetupTestDB(t)
@@ -643,7 +731,8 @@ func TestGetAllStripeSessions(t *testing.T) {
 	api := testDB.API
 
 	user, _ := seedUserAndCollege(t, testDB, "get-all-sessions")
-	auth := "Authorization: Bearer " + uuid.NewString()
+	auth := stripeAdminHeader(t, testDB)
+	userAuth := authHeaderFor(user.ID.String())
 
 	createProdResp := api.Post("/api/v1/stripe_product/", s.CreateStripeProductRequest{
 		Name: "Standard Plan", Description: "Standard subscription plan",
@@ -666,7 +755,7 @@ func TestGetAllStripeSessions(t *testing.T) {
 		SuccessURL: "https://example.com/success",
 		CancelURL:  "https://example.com/cancel",
 		Quantity:   1,
-	}, auth)
+	}, userAuth)
 	var createdSession s.StripeCheckoutSessionResponse
 	DecodeTo(&createdSession, createSessionResp)
 
@@ -690,3 +779,15 @@ func TestGetAllStripeSessions(t *testing.T) {
 		t.Errorf("expected session %s to be in the list, but it was not found", createdSession.ID)
 	}
 }
+
+// stripeAdminHeader can manage the platform's Stripe products and prices.
+func stripeAdminHeader(t *testing.T, testDB *TestDatabase) string {
+	t.Helper()
+	var perms []permissionSpec
+	for _, resource := range []string{"stripe_product", "stripe_price"} {
+		for _, action := range []models.PermissionAction{models.PermissionCreate, models.PermissionUpdate, models.PermissionDelete} {
+			perms = append(perms, permissionSpec{Action: action, Resource: resource})
+		}
+	}
+	return authHeaderWithPermissions(t, testDB.DB, perms)
+}
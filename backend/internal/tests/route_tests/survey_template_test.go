@@ -0,0 +1,150 @@
+package routeTests
+
+import (
+	surveyPackage "inside-athletics/internal/handlers/survey"
+	"inside-athletics/internal/models"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSurveyTemplateVersions(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
+
+	college := seedCollege(t, testDB)
+	sport := seedSport(t, testDB)
+	first := seedSurveyAthlete(t, testDB, uuid.New(), college, sport)
+	second := seedSurveyAthlete(t, testDB, uuid.New(), college, sport)
+	firstHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, first.ID)
+	secondHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, second.ID)
+	adminHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleAdmin, nil, seedUser(t, testDB).ID)
+
+	template := map[string]any{
+		"name": "Recruiting",
+		"questions": []map[string]any{
+			{"key": "culture", "type": "scale", "prompt": "How good is the team culture?"},
+			{"key": "position_group", "type": "multiple_choice", "prompt": "What do you play?", "options": []string{"Offense", "Defense"}},
+			{"key": "advice", "type": "text", "prompt": "Any advice for recruits?", "required": false},
+		},
+	}
+	if code := api.Post("/api/v1/survey/templates", firstHeader, template).Code; code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a user defining a template, got %d", code)
+	}
+	resp := api.Post("/api/v1/survey/templates", adminHeader, template)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 defining a template, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var v1 surveyPackage.SurveyTemplateResponse
+	DecodeTo(&v1, resp)
+	if v1.Version != 1 || len(v1.Questions) != 3 || v1.Questions[1].Key != "position_group" {
+		t.Fatalf("expected version 1 with the questions in order, got %+v", v1)
+	}
+
+	// culture is a scale question in the default template, so it can't become a text question
+	retyped := map[string]any{"questions": []map[string]any{{"key": "culture", "type": "text", "prompt": "Describe the culture"}}}
+	versionsPath := "/api/v1/survey/templates/" + v1.ID.String() + "/versions"
+	if resp := api.Post(versionsPath, adminHeader, retyped); resp.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 changing a question key's type, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	culture, offense := int32(5), "Offense"
+	resp = api.Post("/api/v1/survey/", firstHeader, map[string]any{
+		"college_id": college.ID, "sport_id": sport.ID, "template_version_id": v1.VersionID,
+		"answers": []surveyPackage.AnswerInput{{QuestionKey: "culture", Scale: &culture}, {QuestionKey: "position_group", Choice: &offense}},
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 answering version 1, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp = api.Post(versionsPath, adminHeader, map[string]any{
+		"questions": []map[string]any{
+			{"key": "facilities", "type": "scale", "prompt": "How good are the facilities?"},
+			{"key": "culture", "type": "scale", "prompt": "How would you rate the team culture?"},
+			{"key": "position_group", "type": "multiple_choice", "prompt": "What do you play?", "options": []string{"Offense", "Defense", "Special teams"}},
+		},
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 publishing version 2, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var v2 surveyPackage.SurveyTemplateResponse
+	DecodeTo(&v2, resp)
+	if v2.Version != 2 || v2.VersionID == v1.VersionID {
+		t.Fatalf("expected a new version 2, got %+v", v2)
+	}
+
+	culture, facilities := int32(3), int32(4)
+	answers := []surveyPackage.AnswerInput{{QuestionKey: "facilities", Scale: &facilities}, {QuestionKey: "culture", Scale: &culture}, {QuestionKey: "position_group", Choice: &offense}}
+	outdated := map[string]any{"college_id": college.ID, "sport_id": sport.ID, "template_version_id": v1.VersionID, "answers": answers[1:]}
+	if code := api.Post("/api/v1/survey/", secondHeader, outdated).Code; code != http.StatusConflict {
+		t.Fatalf("expected status 409 answering an outdated version, got %d", code)
+	}
+	missing := map[string]any{"college_id": college.ID, "sport_id": sport.ID, "template_version_id": v2.VersionID, "answers": answers[1:]}
+	if code := api.Post("/api/v1/survey/", secondHeader, missing).Code; code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 leaving a required question unanswered, got %d", code)
+	}
+	resp = api.Post("/api/v1/survey/", secondHeader, map[string]any{
+		"college_id": college.ID, "sport_id": sport.ID, "template_version_id": v2.VersionID, "answers": answers,
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 answering version 2, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp = api.Get("/api/v1/survey/templates/"+v1.ID.String()+"?version=1", authHeaderFor(mockUUID))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 getting version 1, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var got surveyPackage.SurveyTemplateResponse
+	DecodeTo(&got, resp)
+	if got.VersionID != v1.VersionID || got.LatestVersion != 2 || len(got.Questions) != 3 {
+		t.Fatalf("expected version 1 unchanged by version 2, got %+v", got)
+	}
+
+	// culture is averaged across both versions; facilities was only asked in version 2
+	resp = api.Get("/api/v1/survey/averages?college_id="+college.ID.String(), authHeaderFor(mockUUID))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var averages surveyPackage.AverageRatingsResponse
+	DecodeTo(&averages, resp)
+	if len(averages.Averages) != 1 || averages.Averages[0].ResponseCount != 2 {
+		t.Fatalf("expected both responses averaged, got %+v", averages.Averages)
+	}
+	means := map[string]surveyPackage.QuestionAverage{}
+	for _, q := range averages.Averages[0].Questions {
+		means[q.Key] = q
+	}
+	if means["culture"].ResponseCount != 2 || means["culture"].Mean != 4 || means["facilities"].ResponseCount != 1 {
+		t.Fatalf("expected culture averaged across versions, got %+v", means)
+	}
+
+	resp = api.Get("/api/v1/survey/analytics?sport_id="+sport.ID.String()+"&college_id="+college.ID.String(), authHeaderFor(mockUUID))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var analytics surveyPackage.SurveyAnalyticsResponse
+	DecodeTo(&analytics, resp)
+	if analytics.ResponseCount != 2 || len(analytics.Dimensions) != 2 {
+		t.Fatalf("expected 2 responses over the 2 scale questions, got %+v", analytics)
+	}
+	if len(analytics.Choices) != 1 || analytics.Choices[0].Counts[offense] != 2 {
+		t.Fatalf("expected both picks of %s counted, got %+v", offense, analytics.Choices)
+	}
+
+	resp = api.Get("/api/v1/survey/templates", authHeaderFor(mockUUID))
+	var templates surveyPackage.GetSurveyTemplatesResponse
+	DecodeTo(&templates, resp)
+	versions := map[string]int{}
+	for _, tmpl := range templates.Templates {
+		versions[tmpl.Name] = tmpl.Version
+	}
+	if versions["Recruiting"] != 2 || versions["Athlete Experience"] != 1 {
+		t.Fatalf("expected every template at its latest version, got %v", versions)
+	}
+	if code := api.Get("/api/v1/survey/templates/"+v1.ID.String()+"?version=3", authHeaderFor(mockUUID)).Code; code != http.StatusNotFound {
+		t.Fatalf("expected status 404 for a version that wasn't published, got %d", code)
+	}
+}
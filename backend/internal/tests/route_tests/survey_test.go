@@ -57,21 +57,67 @@ func seedSport(t *testing.T, testDB *TestDatabase) *models.Sport {
 	return &sport
 }
 
-// seedSurvey inserts a Survey row. userID, collegeID and sportID must already exist in the DB.
+// defaultRatings are the answers seedSurvey gives the default template's scale questions, by key
+var defaultRatings = map[string]int32{
+	"player_dev":                   4,
+	"academics_athletics_priority": 3,
+	"academic_career_resources":    4,
+	"mental_health_priority":       3,
+	"environment":                  5,
+	"culture":                      4,
+	"transparency":                 3,
+}
+
+// ratingsWith is defaultRatings with the question with the key answered with rating instead.
+func ratingsWith(key string, rating int32) map[string]int32 {
+	ratings := make(map[string]int32, len(defaultRatings))
+	for k, r := range defaultRatings {
+		ratings[k] = r
+	}
+	ratings[key] = rating
+	return ratings
+}
+
+// defaultSurveyVersion loads the default survey template's latest version, which the migrations
+// seed with the seven scale questions surveys had before templates, with its questions.
+func defaultSurveyVersion(t *testing.T, testDB *TestDatabase) *models.SurveyTemplateVersion {
+	t.Helper()
+	var version models.SurveyTemplateVersion
+	err := testDB.DB.Preload("Questions").
+		Joins("JOIN survey_templates t ON t.id = survey_template_versions.template_id").
+		Where("t.name = ?", "Athlete Experience").
+		Order("version DESC").
+		First(&version).Error
+	if err != nil {
+		t.Fatalf("Unable to load the default survey template: %s", err.Error())
+	}
+	return &version
+}
+
+// surveyAnswers answers scale questions with the ratings, by key.
+func surveyAnswers(ratings map[string]int32) []surveyPackage.AnswerInput {
+	answers := make([]surveyPackage.AnswerInput, 0, len(ratings))
+	for key, rating := range ratings {
+		answers = append(answers, surveyPackage.AnswerInput{QuestionKey: key, Scale: &rating})
+	}
+	return answers
+}
+
+// seedSurvey inserts a Survey row answering the default template with defaultRatings. userID,
+// collegeID and sportID must already exist in the DB.
 func seedSurvey(t *testing.T, testDB *TestDatabase, userID, collegeID, sportID uuid.UUID) *models.Survey {
 	t.Helper()
+	version := defaultSurveyVersion(t, testDB)
 	survey := models.Survey{
-		ID:                         uuid.New(),
-		UserID:                     userID,
-		CollegeID:                  collegeID,
-		SportID:                    sportID,
-		PlayerDev:                  4,
-		AcademicsAthleticsPriority: 3,
-		AcademicCareerResources:    4,
-		MentalHealthPriority:       3,
-		Environment:                5,
-		Culture:                    4,
-		Transparency:               3,
+		ID:                uuid.New(),
+		UserID:            userID,
+		CollegeID:         collegeID,
+		SportID:           sportID,
+		TemplateVersionID: version.ID,
+	}
+	for _, q := range version.Questions {
+		rating := defaultRatings[q.Key]
+		survey.Answers = append(survey.Answers, models.SurveyAnswer{QuestionID: q.ID, Scale: &rating})
 	}
 	dbResp := testDB.DB.Create(&survey)
 	_, err := utils.HandleDBError(&survey, dbResp.Error)
@@ -81,42 +127,58 @@ func seedSurvey(t *testing.T, testDB *TestDatabase, userID, collegeID, sportID u
 	return &survey
 }
 
-func TestCreateSurvey(t *testing.T) {
-	t.Parallel()
-	testDB := SetupTestDB(t)
-	defer testDB.Teardown(t)
-	api := testDB.API
+// answerOf returns the survey's answer to the question with the key.
+func answerOf(t *testing.T, survey surveyPackage.SurveyResponse, key string) surveyPackage.AnswerResponse {
+	t.Helper()
+	for _, answer := range survey.Answers {
+		if answer.QuestionKey == key {
+			return answer
+		}
+	}
+	t.Fatalf("expected survey %s to answer %s, got %+v", survey.ID, key, survey.Answers)
+	return surveyPackage.AnswerResponse{}
+}
 
-	// mockUUID user must exist for the FK
+// seedSurveyAthlete inserts a user verified for sport at college, who may rate the program.
+func seedSurveyAthlete(t *testing.T, testDB *TestDatabase, id uuid.UUID, college *models.College, sport *models.Sport) *models.User {
+	t.Helper()
+	division := models.DivisionI
 	user := models.User{
-		ID:                      uuid.MustParse(mockUUID),
+		ID:                      id,
 		FirstName:               "Test",
-		LastName:                "User",
-		Email:                   "test@example.com",
-		Username:                "testuser-mock",
-		Verified_Athlete_Status: models.VerifiedAthleteStatusPending,
+		LastName:                "Athlete",
+		Email:                   uuid.NewString() + "@example.com",
+		Username:                "testathlete-" + uuid.NewString(),
+		Verified_Athlete_Status: models.VerifiedAthleteStatusVerified,
+		CollegeID:               &college.ID,
+		SportID:                 &sport.ID,
+		Division:                &division,
 	}
 	if err := testDB.DB.Create(&user).Error; err != nil {
-		t.Fatalf("failed to create user: %v", err)
+		t.Fatalf("failed to create athlete: %v", err)
 	}
+	return &user
+}
+
+func TestCreateSurvey(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
 
 	college := seedCollege(t, testDB)
 	sport := seedSport(t, testDB)
+	user := seedSurveyAthlete(t, testDB, uuid.MustParse(mockUUID), college, sport)
 
 	payload := surveyPackage.CreateSurveyRequest{
-		UserID:                     uuid.MustParse(mockUUID),
-		CollegeID:                  college.ID,
-		SportID:                    sport.ID,
-		PlayerDev:                  4,
-		AcademicsAthleticsPriority: 3,
-		AcademicCareerResources:    4,
-		MentalHealthPriority:       3,
-		Environment:                5,
-		Culture:                    4,
-		Transparency:               3,
-	}
-
-	resp := api.Post("/api/v1/survey/", "Authorization: Bearer "+mockUUID, payload)
+		CollegeID:         college.ID,
+		SportID:           sport.ID,
+		TemplateVersionID: defaultSurveyVersion(t, testDB).ID,
+		Answers:           surveyAnswers(defaultRatings),
+	}
+
+	header := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, user.ID)
+	resp := api.Post("/api/v1/survey/", header, payload)
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -124,11 +186,106 @@ func TestCreateSurvey(t *testing.T) {
 	var response surveyPackage.SurveyResponse
 	DecodeTo(&response, resp)
 
-	if response.PlayerDev != payload.PlayerDev {
-		t.Fatalf("unexpected player_dev: got %d, want %d", response.PlayerDev, payload.PlayerDev)
+	if len(response.Answers) != len(defaultRatings) || response.Answers[0].QuestionKey != "player_dev" || *answerOf(t, response, "player_dev").Scale != 4 {
+		t.Fatalf("expected every question answered in the order they're asked, got %+v", response.Answers)
+	}
+	if response.UserID != user.ID {
+		t.Fatalf("unexpected user_id: got %s, want %s", response.UserID, user.ID)
+	}
+	if response.AuthorTrust == nil || !response.AuthorTrust.MatchesCollege || !response.AuthorTrust.MatchesSport {
+		t.Fatalf("expected the respondent to be verified for the program, got %+v", response.AuthorTrust)
+	}
+
+	// submitting again edits the same response
+	payload.Answers = surveyAnswers(ratingsWith("player_dev", 2))
+	resp = api.Post("/api/v1/survey/", header, payload)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 editing a survey, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var edited surveyPackage.SurveyResponse
+	DecodeTo(&edited, resp)
+	if edited.ID != response.ID || *answerOf(t, edited, "player_dev").Scale != 2 {
+		t.Fatalf("expected survey %s to be edited in place, got %+v", response.ID, edited)
+	}
+	var count int64
+	testDB.DB.Model(&models.Survey{}).Where("user_id = ?", user.ID).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected one survey for the program, got %d", count)
+	}
+}
+
+func TestCreateSurveyRequiresVerifiedAthlete(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
+
+	college := seedCollege(t, testDB)
+	sport := seedSport(t, testDB)
+	otherSport := seedSport(t, testDB)
+	athlete := seedSurveyAthlete(t, testDB, uuid.New(), college, otherSport)
+	fan := seedUser(t, testDB)
+
+	payload := map[string]any{
+		"college_id": college.ID, "sport_id": sport.ID,
+		"template_version_id": defaultSurveyVersion(t, testDB).ID, "answers": surveyAnswers(defaultRatings),
 	}
-	if response.UserID != payload.UserID {
-		t.Fatalf("unexpected user_id: got %s, want %s", response.UserID, payload.UserID)
+	for _, user := range []*models.User{athlete, fan} {
+		header := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, user.ID)
+		if resp := api.Post("/api/v1/survey/", header, payload); resp.Code != http.StatusForbidden {
+			t.Fatalf("expected status 403 rating a program the user isn't verified for, got %d: %s", resp.Code, resp.Body.String())
+		}
+	}
+
+	payload["user_id"] = athlete.ID
+	header := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, fan.ID)
+	if code := api.Post("/api/v1/survey/", header, payload).Code; code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 submitting a survey for someone else, got %d", code)
+	}
+}
+
+func TestInvalidatedSurveysAreLeftOutOfAverages(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
+
+	college := seedCollege(t, testDB)
+	sport := seedSport(t, testDB)
+	seedSurvey(t, testDB, seedUser(t, testDB).ID, college.ID, sport.ID)
+	suspicious := seedSurvey(t, testDB, seedUser(t, testDB).ID, college.ID, sport.ID)
+
+	invalidatePath := "/api/v1/survey/" + suspicious.ID.String() + "/invalidate"
+	reason := map[string]any{"reason": "Account created to inflate the ratings"}
+	userHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, seedUser(t, testDB).ID)
+	if code := api.Post(invalidatePath, userHeader, reason).Code; code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a user invalidating a survey, got %d", code)
+	}
+	adminHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleAdmin, nil, seedUser(t, testDB).ID)
+	resp := api.Post(invalidatePath, adminHeader, reason)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 invalidating a survey, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var invalidated surveyPackage.SurveyResponse
+	DecodeTo(&invalidated, resp)
+	if invalidated.InvalidatedAt == nil || invalidated.InvalidationReason == nil {
+		t.Fatalf("expected the survey to be invalidated with its reason, got %+v", invalidated)
+	}
+	if code := api.Post(invalidatePath, adminHeader, reason).Code; code != http.StatusConflict {
+		t.Fatalf("expected status 409 invalidating a survey twice, got %d", code)
+	}
+
+	resp = api.Get("/api/v1/survey/averages?college_id="+college.ID.String(), authHeaderFor(mockUUID))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var response surveyPackage.AverageRatingsResponse
+	DecodeTo(&response, resp)
+	if len(response.Averages) != 1 || response.Averages[0].ResponseCount != 1 {
+		t.Fatalf("expected only the valid survey to be averaged, got %+v", response.Averages)
+	}
+	if questions := response.Averages[0].Questions; len(questions) != len(defaultRatings) || questions[0].Key != "player_dev" || questions[0].Mean != 4 {
+		t.Fatalf("expected the valid survey's answers to be averaged, got %+v", questions)
 	}
 }
 
@@ -142,19 +299,15 @@ func TestCreateSurveyInvalidRating(t *testing.T) {
 	sport := seedSport(t, testDB)
 
 	payload := surveyPackage.CreateSurveyRequest{
-		UserID:                     uuid.MustParse(mockUUID),
-		CollegeID:                  college.ID,
-		SportID:                    sport.ID,
-		PlayerDev:                  6, // out of range
-		AcademicsAthleticsPriority: 3,
-		AcademicCareerResources:    4,
-		MentalHealthPriority:       3,
-		Environment:                5,
-		Culture:                    4,
-		Transparency:               3,
-	}
-
-	resp := api.Post("/api/v1/survey/", "Authorization: Bearer "+mockUUID, payload)
+		CollegeID:         college.ID,
+		SportID:           sport.ID,
+		TemplateVersionID: defaultSurveyVersion(t, testDB).ID,
+		Answers:           surveyAnswers(ratingsWith("player_dev", 6)), // out of range
+	}
+
+	user := seedUser(t, testDB)
+	header := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, user.ID)
+	resp := api.Post("/api/v1/survey/", header, payload)
 	if resp.Code != http.StatusUnprocessableEntity {
 		t.Fatalf("expected status 422 for invalid rating, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -171,7 +324,14 @@ func TestDeleteSurvey(t *testing.T) {
 	sport := seedSport(t, testDB)
 	survey := seedSurvey(t, testDB, user.ID, college.ID, sport.ID)
 
-	resp := api.Delete("/api/v1/survey/"+survey.ID.String(), "Authorization: Bearer "+mockUUID)
+	other := seedUser(t, testDB)
+	otherHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, other.ID)
+	if code := api.Delete("/api/v1/survey/"+survey.ID.String(), otherHeader).Code; code != http.StatusForbidden {
+		t.Fatalf("expected status 403 deleting someone else's survey, got %d", code)
+	}
+
+	header := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, user.ID)
+	resp := api.Delete("/api/v1/survey/"+survey.ID.String(), header)
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -190,7 +350,7 @@ func TestDeleteSurveyNotFound(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	resp := api.Delete("/api/v1/survey/"+uuid.New().String(), "Authorization: Bearer "+mockUUID)
+	resp := api.Delete("/api/v1/survey/"+uuid.New().String(), authHeaderWithPermissions(t, testDB.DB, nil))
 	if resp.Code != http.StatusNotFound {
 		t.Fatalf("expected status 404 for missing survey, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -212,7 +372,7 @@ func TestGetSurveysByUser(t *testing.T) {
 	seedSurvey(t, testDB, user.ID, college1.ID, sport1.ID)
 	seedSurvey(t, testDB, user.ID, college2.ID, sport2.ID)
 
-	resp := api.Get("/api/v1/survey/user/"+user.ID.String(), "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/survey/user/"+user.ID.String(), authHeaderFor(mockUUID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -234,7 +394,7 @@ func TestGetSurveysByUserEmpty(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	resp := api.Get("/api/v1/survey/user/"+uuid.New().String(), "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/survey/user/"+uuid.New().String(), authHeaderFor(mockUUID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200 for empty result, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -261,7 +421,7 @@ func TestGetAverageRatingsNoFilter(t *testing.T) {
 	seedSurvey(t, testDB, user1.ID, college.ID, sport.ID)
 	seedSurvey(t, testDB, user2.ID, college.ID, sport.ID)
 
-	resp := api.Get("/api/v1/survey/averages", "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/survey/averages", authHeaderFor(mockUUID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -289,7 +449,7 @@ func TestGetAverageRatingsFilterBySport(t *testing.T) {
 	seedSurvey(t, testDB, user1.ID, college.ID, sport.ID)
 	seedSurvey(t, testDB, user2.ID, college.ID, otherSport.ID) // should not appear
 
-	resp := api.Get("/api/v1/survey/averages?sport_id="+sport.ID.String(), "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/survey/averages?sport_id="+sport.ID.String(), authHeaderFor(mockUUID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -320,7 +480,7 @@ func TestGetAverageRatingsFilterByCollege(t *testing.T) {
 	seedSurvey(t, testDB, user1.ID, college.ID, sport.ID)
 	seedSurvey(t, testDB, user2.ID, otherCollege.ID, sport.ID) // should not appear
 
-	resp := api.Get("/api/v1/survey/averages?college_id="+college.ID.String(), "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/survey/averages?college_id="+college.ID.String(), authHeaderFor(mockUUID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -354,7 +514,7 @@ func TestGetAverageRatingsFilterBySportAndCollege(t *testing.T) {
 	seedSurvey(t, testDB, user2.ID, noiseCollege.ID, noiseSport.ID)
 
 	url := "/api/v1/survey/averages?sport_id=" + sport.ID.String() + "&college_id=" + college.ID.String()
-	resp := api.Get(url, "Authorization: Bearer "+mockUUID)
+	resp := api.Get(url, authHeaderFor(mockUUID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -373,3 +533,43 @@ func TestGetAverageRatingsFilterBySportAndCollege(t *testing.T) {
 		t.Fatalf("expected response_count 1, got %d", row.ResponseCount)
 	}
 }
+
+func TestGetSurveyAnalytics(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
+
+	college := seedCollege(t, testDB)
+	divisionII := seedCollege(t, testDB)
+	if err := testDB.DB.Model(divisionII).Update("division_rank", models.DivisionII).Error; err != nil {
+		t.Fatalf("failed to move college to division II: %v", err)
+	}
+	sport := seedSport(t, testDB)
+	seedSurvey(t, testDB, seedUser(t, testDB).ID, college.ID, sport.ID)
+	seedSurvey(t, testDB, seedUser(t, testDB).ID, college.ID, sport.ID)
+	seedSurvey(t, testDB, seedUser(t, testDB).ID, divisionII.ID, sport.ID)
+
+	resp := api.Get("/api/v1/survey/analytics?sport_id="+sport.ID.String()+"&college_id="+uuid.NewString(), authHeaderFor(mockUUID))
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 for a missing college, got %d: %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Get("/api/v1/survey/analytics?sport_id="+sport.ID.String()+"&college_id="+college.ID.String(), authHeaderFor(mockUUID))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var analytics surveyPackage.SurveyAnalyticsResponse
+	DecodeTo(&analytics, resp)
+
+	if analytics.ResponseCount != 2 || analytics.Division != models.DivisionI || len(analytics.Dimensions) != len(defaultRatings) {
+		t.Fatalf("expected 2 responses in division I over every dimension, got %+v", analytics)
+	}
+	for _, d := range analytics.Dimensions {
+		if d.Histogram.Count() != 2 || d.Sport.ResponseCount != 3 || d.Division.ResponseCount != 2 {
+			t.Fatalf("expected the program's, sport's and division's ratings to be counted, got %+v", d)
+		}
+	}
+	if len(analytics.Trends) != 1 || analytics.Trends[0].ResponseCount != 2 {
+		t.Fatalf("expected this academic year's responses, got %+v", analytics.Trends)
+	}
+}
@@ -27,7 +27,7 @@ func TestGetTagByName(t *testing.T) {
 		t.Fatalf("Unable to add tag to table: %s", err.Error())
 	}
 
-	resp := api.Get("/api/v1/tag/name/Hockey", "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/tag/name/Hockey", authHeaderFor(mockUUID))
 
 	var response tagPackage.GetTagResponse
 
@@ -56,7 +56,7 @@ func TestGetTagByID(t *testing.T) {
 		t.Fatalf("Unable to add tag to table: %s", err.Error())
 	}
 
-	resp := api.Get("/api/v1/tag/"+newID.String(), "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/tag/"+newID.String(), authHeaderFor(mockUUID))
 
 	var response tagPackage.GetTagResponse
 
@@ -77,7 +77,11 @@ func TestCreateTag(t *testing.T) {
 		Name: "Basketball",
 	}
 
-	resp := api.Post("/api/v1/tag/", "Authorization: Bearer "+mockUUID, payload)
+	authHeader := authHeaderWithPermissions(t, testDB.DB, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "tag"},
+	})
+
+	resp := api.Post("/api/v1/tag/", authHeader, payload)
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -90,6 +94,32 @@ func TestCreateTag(t *testing.T) {
 	}
 }
 
+func TestTagChangesNeedPermission(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	api := testDB.API
+
+	tag := models.Tag{
+		ID:   uuid.New(),
+		Name: "Hockey",
+	}
+	if err := testDB.DB.Create(&tag).Error; err != nil {
+		t.Fatalf("Unable to add tag to table: %s", err.Error())
+	}
+	_, authHeader := seedUserWithRoleAndPermissions(t, testDB.DB, models.RoleUser, nil)
+
+	if resp := api.Post("/api/v1/tag/", authHeader, tagPackage.CreateTagBody{Name: "Basketball"}); resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 creating a tag, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := api.Patch("/api/v1/tag/"+tag.ID.String(), authHeader, tagPackage.UpdateTagBody{Name: "Renamed"}); resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 renaming a tag, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := api.Delete("/api/v1/tag/"+tag.ID.String(), authHeader); resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 deleting a tag, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
 func TestUpdateTag(t *testing.T) {
 	t.Parallel()
 	testDB := SetupTestDB(t)
@@ -110,7 +140,11 @@ func TestUpdateTag(t *testing.T) {
 		Name: "Updated",
 	}
 
-	resp := api.Patch("/api/v1/tag/"+tag.ID.String(), "Authorization: Bearer "+mockUUID, update)
+	authHeader := authHeaderWithPermissions(t, testDB.DB, []permissionSpec{
+		{Action: models.PermissionUpdate, Resource: "tag"},
+	})
+
+	resp := api.Patch("/api/v1/tag/"+tag.ID.String(), authHeader, update)
 
 	var response tagPackage.UpdateTagResponse
 	DecodeTo(&response, resp)
@@ -135,7 +169,11 @@ func TestDeleteTag(t *testing.T) {
 		t.Fatalf("Unable to add tag to table: %s", err.Error())
 	}
 
-	resp := api.Delete("/api/v1/tag/"+tag.ID.String(), "Authorization: Bearer "+mockUUID)
+	authHeader := authHeaderWithPermissions(t, testDB.DB, []permissionSpec{
+		{Action: models.PermissionDelete, Resource: "tag"},
+	})
+
+	resp := api.Delete("/api/v1/tag/"+tag.ID.String(), authHeader)
 
 	var response tagPackage.DeleteTagResponse
 	DecodeTo(&response, resp)
@@ -163,7 +201,7 @@ func TestListTags(t *testing.T) {
 		}
 	}
 
-	resp := api.Get("/api/v1/tag?limit=50&offset=0", "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/tag?limit=50&offset=0", authHeaderFor(mockUUID))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -222,7 +260,7 @@ func TestTagSearch(t *testing.T) {
 		t.Fatalf("unable to add tag to table: %s", err.Error())
 	}
 
-	resp := api.Get("/api/v1/tag/type/sports", "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/tag/type/sports", authHeaderFor(mockUUID))
 
 	var response []tagPackage.GetTagResponse
 	DecodeTo(&response, resp)
@@ -77,7 +77,7 @@ func TestGetPostsByTag(t *testing.T) {
 		t.Fatalf("Unable to add tag to table: %s", err.Error())
 	}
 
-	resp := api.Get("/api/v1/tag/"+HealthAndWellnessID.String()+"/posts", "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/tag/"+HealthAndWellnessID.String()+"/posts", authHeaderFor(mockUUID))
 
 	var response tagPackage.GetPostsByTagResponse
 	DecodeTo(&response, resp)
@@ -107,7 +107,7 @@ func TestGetTagpostByID(t *testing.T) {
 		t.Fatalf("Unable to add tag to table: %s", err.Error())
 	}
 
-	resp := api.Get("/api/v1/post/tag/"+newId.String(), "Authorization: Bearer "+mockUUID)
+	resp := api.Get("/api/v1/post/tag/"+newId.String(), authHeaderFor(mockUUID))
 
 	var response tagpostPackage.GetTagPostByIDResponse
 	DecodeTo(&response, resp)
@@ -0,0 +1,151 @@
+package routeTests
+
+import (
+	h "inside-athletics/internal/handlers/user"
+	"inside-athletics/internal/models"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// seedRoleAdmin creates an admin who may assign and remove roles.
+func seedRoleAdmin(t *testing.T, testDB *TestDatabase, unique string) (models.User, string) {
+	t.Helper()
+	admin := newCommentTestUser(uuid.New(), unique)
+	if err := testDB.DB.Create(&admin).Error; err != nil {
+		t.Fatalf("create admin: %v", err)
+	}
+	return admin, authHeaderWithPermissionsGivenUser(t, testDB.DB, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "user"},
+		{Action: models.PermissionDelete, Resource: "user"},
+		{Action: models.PermissionDeleteOwn, Resource: "user"},
+	}, admin.ID)
+}
+
+func getUserRoles(t *testing.T, testDB *TestDatabase, userID uuid.UUID, header string) []string {
+	t.Helper()
+	resp := testDB.API.Get("/api/v1/user/"+userID.String(), header)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 getting the user, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var user h.GetUserResponse
+	DecodeTo(&user, resp)
+	var roles []string
+	if user.Roles != nil {
+		for _, role := range *user.Roles {
+			name := string(role.Name)
+			if role.Scope != nil {
+				name += ":" + string(role.Scope.Type)
+			}
+			roles = append(roles, name)
+		}
+	}
+	return roles
+}
+
+func TestUsersHoldSeveralRoles(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	admin, adminHeader := seedRoleAdmin(t, testDB, "roles-admin")
+	target := newCommentTestUser(uuid.New(), "roles-target")
+	if err := testDB.DB.Create(&target).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	targetHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, target.ID)
+	moderatorID := getRoleID(t, testDB.DB, models.RoleModerator)
+	premiumID := getRoleID(t, testDB.DB, models.RolePremiumUser)
+	rolesPath := "/api/v1/user/" + target.ID.String() + "/roles"
+
+	if code := testDB.API.Post(rolesPath, map[string]any{"role_id": moderatorID}, targetHeader).Code; code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a user assigning themselves a role, got %d", code)
+	}
+	for _, roleID := range []uuid.UUID{premiumID, moderatorID, moderatorID} {
+		if resp := testDB.API.Post(rolesPath, map[string]any{"role_id": roleID}, adminHeader); resp.Code != http.StatusOK {
+			t.Fatalf("expected status 200 assigning a role, got %d: %s", resp.Code, resp.Body.String())
+		}
+	}
+	if roles := getUserRoles(t, testDB, target.ID, targetHeader); len(roles) != 3 {
+		t.Fatalf("expected the user, premium_user and moderator roles once each, got %v", roles)
+	}
+
+	moderatorPath := rolesPath + "/" + moderatorID.String()
+	if resp := testDB.API.Delete(moderatorPath, adminHeader); resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 removing a role, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if code := testDB.API.Delete(moderatorPath, adminHeader).Code; code != http.StatusNotFound {
+		t.Fatalf("expected status 404 removing a role the user doesn't have, got %d", code)
+	}
+	adminRolePath := "/api/v1/user/" + admin.ID.String() + "/roles/" + getRoleID(t, testDB.DB, models.RoleAdmin).String()
+	if code := testDB.API.Delete(adminRolePath, adminHeader).Code; code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 removing your own role, got %d", code)
+	}
+	if roles := getUserRoles(t, testDB, target.ID, targetHeader); len(roles) != 2 {
+		t.Fatalf("expected the user and premium_user roles to be left, got %v", roles)
+	}
+}
+
+func TestScopedModeratorsOnlyActInTheirScope(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	_, adminHeader := seedRoleAdmin(t, testDB, "scope-admin")
+	author, soccerPost := seedUserAndPost(t, testDB, "scope-author")
+	popularity := int32(10)
+	basketball := models.Sport{Name: "Basketball", Popularity: &popularity}
+	if err := testDB.DB.Create(&basketball).Error; err != nil {
+		t.Fatalf("create sport: %v", err)
+	}
+	basketballPost := models.Post{AuthorID: author.ID, SportID: &basketball.ID, Title: "Hoops", Content: "Off topic"}
+	if err := testDB.DB.Create(&basketballPost).Error; err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+
+	moderatorRoleID := getRoleID(t, testDB.DB, models.RoleModerator)
+	for _, perm := range []permissionSpec{
+		{Action: models.PermissionDelete, Resource: "post"},
+		{Action: models.PermissionUpdate, Resource: "sport"},
+		{Action: models.PermissionReview, Resource: "report"},
+	} {
+		ensurePermissionForRole(t, testDB.DB, moderatorRoleID, perm.Action, perm.Resource)
+	}
+	moderator := newCommentTestUser(uuid.New(), "scope-moderator")
+	if err := testDB.DB.Create(&moderator).Error; err != nil {
+		t.Fatalf("create moderator: %v", err)
+	}
+	moderatorHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, moderator.ID)
+	resp := testDB.API.Post("/api/v1/user/"+moderator.ID.String()+"/roles", map[string]any{
+		"role_id": moderatorRoleID,
+		"scope":   map[string]any{"type": "sport", "id": SoccerID},
+	}, adminHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 assigning a scoped role, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if roles := getUserRoles(t, testDB, moderator.ID, moderatorHeader); len(roles) != 2 || roles[0] != "moderator:sport" {
+		t.Fatalf("expected a moderator role scoped to a sport, got %v", roles)
+	}
+
+	reporter := seedReporters(t, testDB, "scope-reporter", 1)[0]
+	for _, postID := range []uuid.UUID{soccerPost.ID, basketballPost.ID} {
+		if code := reportPost(t, testDB, postID, reporter); code != http.StatusOK {
+			t.Fatalf("expected status 200 reporting a post, got %d", code)
+		}
+	}
+	if queue := getQueue(t, testDB, moderatorHeader); len(queue.Items) != 1 || queue.Items[0].ContentID != soccerPost.ID {
+		t.Fatalf("expected only the soccer post in the queue, got %+v", queue.Items)
+	}
+
+	if code := testDB.API.Patch("/api/v1/sport/"+basketball.ID.String(), map[string]any{"name": "Hoops"}, moderatorHeader).Code; code != http.StatusForbidden {
+		t.Fatalf("expected status 403 editing another sport, got %d", code)
+	}
+	if resp := testDB.API.Patch("/api/v1/sport/"+SoccerID.String(), map[string]any{"name": "Football"}, moderatorHeader); resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 editing their sport, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if code := testDB.API.Delete("/api/v1/post/"+basketballPost.ID.String(), moderatorHeader).Code; code != http.StatusForbidden {
+		t.Fatalf("expected status 403 deleting a post in another sport, got %d", code)
+	}
+	if resp := testDB.API.Delete("/api/v1/post/"+soccerPost.ID.String(), moderatorHeader); resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 deleting a post in their sport, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
@@ -160,7 +160,7 @@ func TestGetCurrentUserID(t *testing.T) {
 	}
 	assignRoleToUser(t, testDB.DB, user.ID, getRoleID(t, testDB.DB, models.RoleUser))
 
-	resp := api.Get("/api/v1/user/current", "Authorization: Bearer "+userID.String())
+	resp := api.Get("/api/v1/user/current", authHeaderFor(userID.String()))
 
 	var u h.GetUserResponse
 	DecodeTo(&u, resp)
@@ -181,23 +181,17 @@ func TestCreateUser(t *testing.T) {
 	defer testDB.Teardown(t)
 	api := testDB.API
 
-	college, sport := addCollegeAndSport(t, testDB)
-
 	userID := uuid.NewString()
 	payload := h.CreateUserBody{
-		FirstName:             "Suli",
-		LastName:              "Test",
-		Email:                 "suli@example.com",
-		Username:              "suli",
-		Bio:                   strPtr("My bio"),
-		SportID:               &sport.ID,
-		ExpectedGradYear:      2027,
-		VerifiedAthleteStatus: models.VerifiedAthleteStatusVerified,
-		CollegeID:             &college.ID,
-		Division:              divisionPtr(models.DivisionI),
+		FirstName:        "Suli",
+		LastName:         "Test",
+		Email:            "suli@example.com",
+		Username:         "suli",
+		Bio:              strPtr("My bio"),
+		ExpectedGradYear: 2027,
 	}
 
-	resp := api.Post("/api/v1/user", "Authorization: Bearer "+userID, payload)
+	resp := api.Post("/api/v1/user", authHeaderFor(userID), payload)
 
 	var u h.CreateUserResponse
 	DecodeTo(&u, resp)
@@ -206,29 +200,24 @@ func TestCreateUser(t *testing.T) {
 	}
 }
 
-func TestCreateUserWithNoneStatus(t *testing.T) {
+func TestCreateUserCannotVerifyThemselves(t *testing.T) {
 	t.Parallel()
 	testDB := SetupTestDB(t)
 	defer testDB.Teardown(t)
 	api := testDB.API
 
 	userID := uuid.NewString()
-	payload := h.CreateUserBody{
-		FirstName:             "Suli",
-		LastName:              "Test",
-		Email:                 "suli@example.com",
-		Username:              "suli",
-		Bio:                   strPtr("My bio"),
-		ExpectedGradYear:      2027,
-		VerifiedAthleteStatus: models.VerifiedAthleteStatusNone,
+	payload := map[string]any{
+		"first_name":              "Suli",
+		"last_name":               "Test",
+		"email":                   "suli@example.com",
+		"username":                "suli",
+		"verified_athlete_status": models.VerifiedAthleteStatusVerified,
 	}
 
-	resp := api.Post("/api/v1/user", "Authorization: Bearer "+userID, payload)
-
-	var u h.CreateUserResponse
-	DecodeTo(&u, resp)
-	if u.ID.String() != userID || u.Name != "Suli" {
-		t.Fatalf("Unexpected response: %+v", u)
+	resp := api.Post("/api/v1/user", authHeaderFor(userID), payload)
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 for a user setting their own verification status, got %d: %s", resp.Code, resp.Body.String())
 	}
 }
 
@@ -347,7 +336,7 @@ func TestAssignRoleToUser(t *testing.T) {
 		"role_id": moderatorRoleID,
 	}
 
-	resp := api.Post("/api/v1/user/"+targetUserID.String()+"/roles", body, "Authorization: Bearer "+adminUserID.String())
+	resp := api.Post("/api/v1/user/"+targetUserID.String()+"/roles", body, authHeaderFor(adminUserID.String()))
 	if resp.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
 	}
@@ -0,0 +1,128 @@
+package routeTests
+
+import (
+	"inside-athletics/internal/handlers/post"
+	"inside-athletics/internal/handlers/userblock"
+	"inside-athletics/internal/models"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// seedBlockingUser creates a user with the user role who may mute, block, comment and like posts
+// and comments.
+func seedBlockingUser(t *testing.T, testDB *TestDatabase, unique string) (models.User, string) {
+	t.Helper()
+	user := newCommentTestUser(uuid.New(), unique)
+	if err := testDB.DB.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user, authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "userblock"},
+		{Action: models.PermissionDeleteOwn, Resource: "userblock"},
+		{Action: models.PermissionCreate, Resource: "comment"},
+		{Action: models.PermissionCreate, Resource: "post"},
+	}, user.ID)
+}
+
+func TestMutedUsersDropOutOfPostsAndComments(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	author, authorPost := seedUserAndPost(t, testDB, "muted-author")
+	_, thread := seedUserAndPost(t, testDB, "muted-thread")
+	viewer, viewerHeader := seedBlockingUser(t, testDB, "muter")
+	reply := models.Comment{UserID: author.ID, PostID: thread.ID, Description: "Muted reply"}
+	if err := testDB.DB.Create(&reply).Error; err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+
+	postPath := "/api/v1/post/" + authorPost.ID.String()
+	byAuthorPath := "/api/v1/posts/by-author/" + author.ID.String()
+	commentsPath := "/api/v1/post/" + thread.ID.String() + "/comments"
+	if code := testDB.API.Get("/api/v1/post/"+thread.ID.String(), viewerHeader).Code; code != http.StatusOK {
+		t.Fatalf("expected status 200 viewing the thread, got %d", code)
+	}
+	countVisible := func() (posts int, comments int) {
+		t.Helper()
+		var byAuthor post.GetPostsByAuthorIDResponse
+		DecodeTo(&byAuthor, testDB.API.Get(byAuthorPath, viewerHeader))
+		var threadComments []map[string]any
+		DecodeTo(&threadComments, testDB.API.Get(commentsPath, viewerHeader))
+		return len(byAuthor.Posts), len(threadComments)
+	}
+	if posts, comments := countVisible(); posts != 1 || comments != 1 {
+		t.Fatalf("expected the author's post and comment before muting, got %d posts and %d comments", posts, comments)
+	}
+
+	if resp := testDB.API.Post("/api/v1/user/blocks/", map[string]any{"user_id": viewer.ID, "type": "mute"}, viewerHeader); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 muting yourself, got %d", resp.Code)
+	}
+	mustPost(t, testDB, "/api/v1/user/blocks/", map[string]any{"user_id": author.ID, "type": "mute"}, viewerHeader)
+	if posts, comments := countVisible(); posts != 0 || comments != 0 {
+		t.Fatalf("expected the muted author's post and comment left out, got %d posts and %d comments", posts, comments)
+	}
+	if code := testDB.API.Get(postPath, viewerHeader).Code; code != http.StatusNotFound {
+		t.Fatalf("expected the muted author's post hidden, got %d", code)
+	}
+
+	resp := testDB.API.Get("/api/v1/user/blocks/", viewerHeader)
+	var blocks userblock.GetUserBlocksResponse
+	DecodeTo(&blocks, resp)
+	if resp.Code != http.StatusOK || len(blocks.Blocks) != 1 || blocks.Blocks[0].TargetID != author.ID || blocks.Blocks[0].Type != models.UserBlockMute {
+		t.Fatalf("expected the mute listed, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	if resp := testDB.API.Delete("/api/v1/user/blocks/"+author.ID.String(), viewerHeader); resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 unmuting, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := testDB.API.Delete("/api/v1/user/blocks/"+author.ID.String(), viewerHeader); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 unmuting twice, got %d", resp.Code)
+	}
+	if posts, comments := countVisible(); posts != 1 || comments != 1 {
+		t.Fatalf("expected the author's post and comment back after unmuting, got %d posts and %d comments", posts, comments)
+	}
+}
+
+func TestBlockedUsersCannotReplyLikeOrMention(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	blocker, blockerPost := seedUserAndPost(t, testDB, "blocker")
+	blockerHeader := authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, []permissionSpec{
+		{Action: models.PermissionCreate, Resource: "userblock"},
+	}, blocker.ID)
+	_, otherPost := seedUserAndPost(t, testDB, "blocker-bystander")
+	blocked, blockedHeader := seedBlockingUser(t, testDB, "blocked")
+	blockerComment := models.Comment{UserID: blocker.ID, PostID: otherPost.ID, Description: "Leave me alone"}
+	if err := testDB.DB.Create(&blockerComment).Error; err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+
+	// muting first and then blocking turns the mute into a block
+	mustPost(t, testDB, "/api/v1/user/blocks/", map[string]any{"user_id": blocked.ID, "type": "mute"}, blockerHeader)
+	mustPost(t, testDB, "/api/v1/user/blocks/", map[string]any{"user_id": blocked.ID, "type": "block"}, blockerHeader)
+	var count int64
+	testDB.DB.Model(&models.UserBlock{}).Where("user_id = ? AND type = ?", blocker.ID, models.UserBlockBlock).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected a single block, got %d", count)
+	}
+
+	forbidden := func(name, path string, body map[string]any, message string) {
+		t.Helper()
+		resp := testDB.API.Post(path, body, blockedHeader)
+		if resp.Code != http.StatusForbidden || !strings.Contains(resp.Body.String(), message) {
+			t.Fatalf("%s: expected status 403, got %d: %s", name, resp.Code, resp.Body.String())
+		}
+	}
+	forbidden("comment on the blocker's post", "/api/v1/comment/", map[string]any{"post_id": blockerPost.ID, "description": "Hey"}, "reply")
+	forbidden("reply to the blocker's comment", "/api/v1/comment/", map[string]any{"post_id": otherPost.ID, "parent_comment_id": blockerComment.ID, "description": "Hey"}, "reply")
+	forbidden("mention the blocker", "/api/v1/comment/", map[string]any{"post_id": otherPost.ID, "description": "Hey @" + blocker.Username}, "mention")
+	forbidden("like the blocker's post", "/api/v1/post/like", map[string]any{"post_id": blockerPost.ID}, "blocked")
+	forbidden("like the blocker's comment", "/api/v1/comment/like", map[string]any{"comment_id": blockerComment.ID}, "blocked")
+
+	// everyone else can still be replied to
+	mustPost(t, testDB, "/api/v1/comment/", map[string]any{"post_id": otherPost.ID, "description": "Nice post"}, blockedHeader)
+}
@@ -0,0 +1,147 @@
+package routeTests
+
+import (
+	h "inside-athletics/internal/handlers/verification"
+	"inside-athletics/internal/models"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// seedApplicant creates a user who isn't verified, with the user role.
+func seedApplicant(t *testing.T, testDB *TestDatabase, unique string) (models.User, string) {
+	t.Helper()
+	applicant := newCommentTestUser(uuid.New(), unique)
+	applicant.Verified_Athlete_Status = models.VerifiedAthleteStatusNone
+	if err := testDB.DB.Create(&applicant).Error; err != nil {
+		t.Fatalf("create applicant: %v", err)
+	}
+	return applicant, authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleUser, nil, applicant.ID)
+}
+
+// seedVerificationAdmin creates an admin, who may review verification requests.
+func seedVerificationAdmin(t *testing.T, testDB *TestDatabase, unique string) string {
+	t.Helper()
+	admin := newCommentTestUser(uuid.New(), unique)
+	if err := testDB.DB.Create(&admin).Error; err != nil {
+		t.Fatalf("create admin: %v", err)
+	}
+	return authHeaderWithPermissionsGivenUserForRole(t, testDB.DB, models.RoleAdmin, nil, admin.ID)
+}
+
+func requestVerification(t *testing.T, testDB *TestDatabase, header string) models.VerificationRequest {
+	t.Helper()
+	resp := testDB.API.Post("/api/v1/verification/", map[string]any{
+		"sport_id":   SoccerID,
+		"college_id": NortheasternID,
+		"roster_url": "https://nuhuskies.com/sports/womens-soccer/roster",
+	}, header)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 requesting verification, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var request models.VerificationRequest
+	DecodeTo(&request, resp)
+	return request
+}
+
+func getStoredUser(t *testing.T, testDB *TestDatabase, userID uuid.UUID) models.User {
+	t.Helper()
+	var user models.User
+	if err := testDB.DB.First(&user, "id = ?", userID).Error; err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	return user
+}
+
+func TestAthletesAreVerifiedOnApproval(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	addCollegeAndSport(t, testDB)
+	applicant, applicantHeader := seedApplicant(t, testDB, "verify-applicant")
+	adminHeader := seedVerificationAdmin(t, testDB, "verify-admin")
+
+	noEvidence := map[string]any{"sport_id": SoccerID, "college_id": NortheasternID}
+	if code := testDB.API.Post("/api/v1/verification/", noEvidence, applicantHeader).Code; code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 for a request without evidence, got %d", code)
+	}
+	request := requestVerification(t, testDB, applicantHeader)
+	if request.Status != models.VerificationPending || request.Division != models.DivisionI {
+		t.Fatalf("expected a pending request in the college's division, got %+v", request)
+	}
+	if status := getStoredUser(t, testDB, applicant.ID).Verified_Athlete_Status; status != models.VerifiedAthleteStatusPending {
+		t.Fatalf("expected the user to be pending verification, got %s", status)
+	}
+	evidence := map[string]any{"sport_id": SoccerID, "college_id": NortheasternID, "team_email": "suli@northeastern.edu"}
+	if code := testDB.API.Post("/api/v1/verification/", evidence, applicantHeader).Code; code != http.StatusConflict {
+		t.Fatalf("expected status 409 for a second pending request, got %d", code)
+	}
+
+	if code := testDB.API.Get("/api/v1/verification/queue", applicantHeader).Code; code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a user reading the review queue, got %d", code)
+	}
+	resp := testDB.API.Get("/api/v1/verification/queue", adminHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 reading the review queue, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var queue h.GetVerificationQueueResponse
+	DecodeTo(&queue, resp)
+	if len(queue.Requests) != 1 || queue.Requests[0].ID != request.ID || queue.Requests[0].Applicant.Username != applicant.Username {
+		t.Fatalf("expected the request in the queue with its applicant, got %+v", queue.Requests)
+	}
+
+	reviewPath := "/api/v1/verification/" + request.ID.String() + "/review"
+	if code := testDB.API.Post(reviewPath, map[string]any{"decision": "approve"}, applicantHeader).Code; code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a user approving their own request, got %d", code)
+	}
+	// the college moved divisions while the request waited
+	if err := testDB.DB.Model(&models.College{}).Where("id = ?", NortheasternID).Update("division_rank", models.DivisionII).Error; err != nil {
+		t.Fatalf("update college division: %v", err)
+	}
+	if resp := testDB.API.Post(reviewPath, map[string]any{"decision": "approve"}, adminHeader); resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 approving a request, got %d: %s", resp.Code, resp.Body.String())
+	}
+	user := getStoredUser(t, testDB, applicant.ID)
+	if user.Verified_Athlete_Status != models.VerifiedAthleteStatusVerified || user.SportID == nil || *user.SportID != SoccerID ||
+		user.CollegeID == nil || *user.CollegeID != NortheasternID || user.Division == nil || *user.Division != models.DivisionII {
+		t.Fatalf("expected the user to be a verified athlete of the request's team in the college's division, got %+v", user)
+	}
+	if code := testDB.API.Post(reviewPath, map[string]any{"decision": "reject", "reason": "Too late"}, adminHeader).Code; code != http.StatusConflict {
+		t.Fatalf("expected status 409 reviewing a request twice, got %d", code)
+	}
+}
+
+func TestRejectedAthletesSeeWhy(t *testing.T) {
+	t.Parallel()
+	testDB := SetupTestDB(t)
+	defer testDB.Teardown(t)
+	addCollegeAndSport(t, testDB)
+	applicant, applicantHeader := seedApplicant(t, testDB, "reject-applicant")
+	adminHeader := seedVerificationAdmin(t, testDB, "reject-admin")
+	request := requestVerification(t, testDB, applicantHeader)
+
+	reviewPath := "/api/v1/verification/" + request.ID.String() + "/review"
+	if code := testDB.API.Post(reviewPath, map[string]any{"decision": "reject"}, adminHeader).Code; code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 rejecting without a reason, got %d", code)
+	}
+	reject := map[string]any{"decision": "reject", "reason": "The roster doesn't list this athlete"}
+	if resp := testDB.API.Post(reviewPath, reject, adminHeader); resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 rejecting a request, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if status := getStoredUser(t, testDB, applicant.ID).Verified_Athlete_Status; status != models.VerifiedAthleteStatusNone {
+		t.Fatalf("expected a rejected user not to be verified, got %s", status)
+	}
+
+	resp := testDB.API.Get("/api/v1/verification/", applicantHeader)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200 listing your requests, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var requests h.GetVerificationRequestsResponse
+	DecodeTo(&requests, resp)
+	if len(requests.Requests) != 1 || requests.Requests[0].Status != models.VerificationRejected ||
+		requests.Requests[0].ReviewReason == nil || *requests.Requests[0].ReviewReason != "The roster doesn't list this athlete" {
+		t.Fatalf("expected the rejected request with its reason, got %+v", requests.Requests)
+	}
+	requestVerification(t, testDB, applicantHeader)
+}
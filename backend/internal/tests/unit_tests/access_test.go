@@ -0,0 +1,113 @@
+package unitTests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"inside-athletics/internal/handlers/content"
+	"inside-athletics/internal/handlers/stripe"
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/s3"
+	"inside-athletics/internal/server"
+	"inside-athletics/internal/utils"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/google/uuid"
+)
+
+type thingOutput struct{}
+
+func handleThing(context.Context, *struct{}) (*thingOutput, error) {
+	return &thingOutput{}, nil
+}
+
+func TestEveryMutatingRouteDeclaresAccess(t *testing.T) {
+	t.Parallel()
+	_, api := humatest.New(t)
+	db := dryRunDB(t)
+	server.CreateRoutes(db, api)
+	stripe.RouteWithClient(api, db, stripe.NewMockStripeClient())
+	content.Route(api, db, s3.NewService(NewMockS3Client(), s3.Config{Bucket: "test", PresignedURLExpiry: time.Hour}), nil)
+
+	if err := server.CheckAccessDeclared(api); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckAccessDeclaredNamesUndeclaredRoutes(t *testing.T) {
+	t.Parallel()
+	_, api := humatest.New(t)
+	huma.Get(api, "/things", handleThing)
+	huma.Post(api, "/things", handleThing, utils.Requires(utils.Permission(models.PermissionCreate, "thing")))
+	huma.Patch(api, "/things/{id}", handleThing, utils.Requires(utils.Authenticated))
+	huma.Delete(api, "/things/{id}", handleThing)
+	huma.Put(api, "/others", handleThing)
+
+	err := server.CheckAccessDeclared(api)
+	if err == nil {
+		t.Fatal("expected an error for the undeclared routes")
+	}
+	for _, want := range []string{"DELETE /things/{id}", "PUT /others"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err)
+		}
+	}
+	for _, declared := range []string{"GET /things", "POST /things", "PATCH"} {
+		if strings.Contains(err.Error(), declared) {
+			t.Errorf("didn't expect %q in %q", declared, err)
+		}
+	}
+}
+
+func TestAccessDeclarations(t *testing.T) {
+	t.Parallel()
+	op := &huma.Operation{}
+	if _, ok := utils.AccessOf(op); ok {
+		t.Fatal("expected no access on an operation that didn't declare any")
+	}
+
+	utils.Requires(utils.Permission(models.PermissionDelete, "survey").WithIDParam("survey_id"))(op)
+	access, ok := utils.AccessOf(op)
+	if !ok || access.Action != models.PermissionDelete || access.Resource != "survey" || access.IDParamName() != "survey_id" {
+		t.Fatalf("unexpected access %+v", access)
+	}
+
+	cases := map[models.PermissionAction]models.PermissionAction{
+		models.PermissionUpdate: models.PermissionUpdateOwn,
+		models.PermissionDelete: models.PermissionDeleteOwn,
+		models.PermissionCreate: models.PermissionCreate,
+	}
+	for action, own := range cases {
+		if got := utils.Permission(action, "post").OwnAction(); got != own {
+			t.Errorf("expected owners to need %s for %s, got %s", own, action, got)
+		}
+	}
+	keyed := utils.Permission(models.PermissionUpdate, "stripe_customer").OwnedByKey(utils.UserKey("stripe_customer_id"))
+	if keyed.KeyOwner == nil || keyed.Owner != nil {
+		t.Errorf("expected only a key owner, got %+v", keyed)
+	}
+	if name := utils.Authenticated.IDParamName(); name != "id" {
+		t.Errorf("expected the id parameter by default, got %q", name)
+	}
+}
+
+func TestKeyUnder(t *testing.T) {
+	t.Parallel()
+	userID := uuid.New()
+	owner := utils.KeyUnder(s3.ContentPrefix)
+
+	cases := map[string]bool{
+		s3.ContentPrefix(userID) + "premium/photo.jpg":     true,
+		s3.ContentPrefix(uuid.New()) + "premium/photo.jpg": false,
+		s3.VerificationPrefix(userID) + "id.pdf":           false,
+		"premium/image/photo.jpg":                          false,
+	}
+	for key, want := range cases {
+		if owned, err := owner(nil, userID, key); err != nil || owned != want {
+			t.Errorf("expected owning %q to be %v, got %v (%v)", key, want, owned, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package unitTests
+
+import (
+	"encoding/json"
+	"inside-athletics/internal/audit"
+	"testing"
+	"time"
+)
+
+func TestAuditDiffOnlyKeepsChangedFields(t *testing.T) {
+	t.Parallel()
+
+	before := json.RawMessage(`{"id":"1","name":"Soccer","popularity":10,"updated_at":"2026-01-01"}`)
+	after := json.RawMessage(`{"id":"1","name":"Football","popularity":10,"updated_at":"2026-02-01","logo":"a.png"}`)
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if len(changes) != 2 || changes["name"].Before != "Soccer" || changes["name"].After != "Football" {
+		t.Fatalf("expected the name and logo to change, got %+v", changes)
+	}
+	if changes["logo"].Before != nil || changes["logo"].After != "a.png" {
+		t.Fatalf("expected the logo to be added, got %+v", changes["logo"])
+	}
+
+	changes, err = audit.Diff(before, nil)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if len(changes) != 3 || changes["id"].After != nil {
+		t.Fatalf("expected every field but updated_at removed on delete, got %+v", changes)
+	}
+}
+
+func TestAuditActionFor(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		method string
+		target audit.Target
+		want   string
+	}{
+		{"POST", audit.Target{}, "create"},
+		{"PATCH", audit.Target{}, "update"},
+		{"PUT", audit.Target{}, "update"},
+		{"DELETE", audit.Target{}, "delete"},
+		{"DELETE", audit.Target{Action: "lift"}, "lift"},
+	}
+	for _, c := range cases {
+		if got := audit.ActionFor(c.method, c.target); got != c.want {
+			t.Errorf("ActionFor(%s, %+v) = %s, want %s", c.method, c.target, got, c.want)
+		}
+	}
+}
+
+func TestAuditModerationResolvesContentType(t *testing.T) {
+	t.Parallel()
+
+	target := audit.Operations["POST /api/v1/report/queue/{content_type}/{content_id}/actions"]
+	params := map[string]string{"content_type": "comment"}
+	resolved, ok := target.Resolve(func(name string) string { return params[name] })
+	if !ok || resolved.Resource != "comment" || resolved.IDParam != "content_id" || resolved.AuthorField != "" {
+		t.Fatalf("expected a comment moderated regardless of author, got %+v", resolved)
+	}
+	params["content_type"] = "sport"
+	if _, ok := target.Resolve(func(name string) string { return params[name] }); ok {
+		t.Fatal("expected content types that can't be reported to be left unaudited")
+	}
+}
+
+func TestAuditConfigFromEnv(t *testing.T) {
+	t.Setenv(audit.EnvRetentionDays, "30")
+	t.Setenv(audit.EnvPruneIntervalSec, "-5")
+
+	cfg := audit.LoadConfigFromEnv()
+	if cfg.Retention != 30*24*time.Hour {
+		t.Fatalf("expected 30 days of retention, got %s", cfg.Retention)
+	}
+	if cfg.PruneInterval != audit.DefaultPruneInterval {
+		t.Fatalf("expected invalid intervals to fall back to the default, got %s", cfg.PruneInterval)
+	}
+}
@@ -0,0 +1,161 @@
+package unitTests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+var testPostKeyset = utils.Keyset{KeyColumn: "posts.created_at", IDColumn: "posts.id", Descending: true}
+
+func postPosition(p *models.Post) utils.Cursor[time.Time] {
+	return utils.Cursor[time.Time]{Key: p.CreatedAt, ID: p.ID}
+}
+
+// newestFirst returns n posts a minute apart, newest first.
+func newestFirst(n int) []models.Post {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	posts := make([]models.Post, n)
+	for i := range posts {
+		posts[i] = models.Post{ID: uuid.New(), CreatedAt: start.Add(-time.Duration(i) * time.Minute)}
+	}
+	return posts
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	cursor := utils.Cursor[time.Time]{Key: time.Date(2026, 5, 1, 12, 0, 0, 123456000, time.UTC), ID: uuid.New(), Before: true}
+	decoded, err := utils.DecodeCursor[time.Time](cursor.Encode())
+	if err != nil {
+		t.Fatalf("failed to decode cursor: %v", err)
+	}
+	if !decoded.Key.Equal(cursor.Key) || decoded.ID != cursor.ID || !decoded.Before {
+		t.Fatalf("expected %+v after round trip, got %+v", cursor, *decoded)
+	}
+
+	if decoded, err := utils.DecodeCursor[time.Time](""); err != nil || decoded != nil {
+		t.Fatalf("expected an empty token to mean no cursor, got %v %v", decoded, err)
+	}
+	for _, token := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		if _, err := utils.DecodeCursor[time.Time](token); err == nil {
+			t.Fatalf("expected %q to be rejected", token)
+		}
+	}
+}
+
+func TestPageWalksForwardAndBack(t *testing.T) {
+	t.Parallel()
+
+	all := newestFirst(5)
+
+	// first page: one extra row is fetched to know there is more
+	first, err := utils.NewPage[time.Time](testPostKeyset, "", 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.CountTotal() {
+		t.Fatal("expected the first page to count the total")
+	}
+	rows, cursors := utils.Results(first, append([]models.Post{}, all[:3]...), postPosition)
+	if len(rows) != 2 || rows[0].ID != all[0].ID || cursors.NextCursor == nil || cursors.PrevCursor != nil {
+		t.Fatalf("unexpected first page: %d rows, cursors %+v", len(rows), cursors)
+	}
+
+	// second page: the DB returns the rows after the cursor
+	second, err := utils.NewPage[time.Time](testPostKeyset, *cursors.NextCursor, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.CountTotal() {
+		t.Fatal("expected cursor pages to skip the total")
+	}
+	rows, cursors = utils.Results(second, append([]models.Post{}, all[2:5]...), postPosition)
+	if len(rows) != 2 || rows[0].ID != all[2].ID || cursors.NextCursor == nil || cursors.PrevCursor == nil {
+		t.Fatalf("unexpected second page: %d rows, cursors %+v", len(rows), cursors)
+	}
+
+	// going back: the DB walks the list in reverse, Results restores the order
+	back, err := utils.NewPage[time.Time](testPostKeyset, *cursors.PrevCursor, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, cursors = utils.Results(back, []models.Post{all[1], all[0]}, postPosition)
+	if len(rows) != 2 || rows[0].ID != all[0].ID || rows[1].ID != all[1].ID {
+		t.Fatalf("expected the first page back in order, got %v", rows)
+	}
+	if cursors.NextCursor == nil || cursors.PrevCursor != nil {
+		t.Fatalf("expected only a next cursor on the first page, got %+v", cursors)
+	}
+
+	// last page
+	last, err := utils.NewPage[time.Time](testPostKeyset, utils.Cursor[time.Time]{Key: all[3].CreatedAt, ID: all[3].ID}.Encode(), 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, cursors = utils.Results(last, []models.Post{all[4]}, postPosition)
+	if len(rows) != 1 || cursors.NextCursor != nil || cursors.PrevCursor == nil {
+		t.Fatalf("unexpected last page: %d rows, cursors %+v", len(rows), cursors)
+	}
+}
+
+func TestPageSeeksPastCursor(t *testing.T) {
+	t.Parallel()
+
+	at := newestFirst(1)[0]
+	next := postPosition(&at)
+	prev := next
+	prev.Before = true
+
+	cases := []struct {
+		name  string
+		token string
+		want  []string
+	}{
+		{"first page", "", []string{`ORDER BY "posts"."created_at" DESC,"posts"."id" DESC LIMIT $1`}},
+		{"next page", next.Encode(), []string{`("posts"."created_at", "posts"."id") < ($1, $2)`, `ORDER BY "posts"."created_at" DESC,"posts"."id" DESC`}},
+		{"previous page", prev.Encode(), []string{`("posts"."created_at", "posts"."id") > ($1, $2)`, `ORDER BY "posts"."created_at","posts"."id"`}},
+	}
+	for _, c := range cases {
+		page, err := utils.NewPage[time.Time](testPostKeyset, c.token, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var posts []models.Post
+		stmt := page.Apply(dryRunDB(t).Model(&models.Post{})).Find(&posts).Statement
+		sql := stmt.SQL.String()
+		for _, want := range c.want {
+			if !strings.Contains(sql, want) {
+				t.Fatalf("%s: expected %q in %s", c.name, want, sql)
+			}
+		}
+		if stmt.Vars[len(stmt.Vars)-1] != 11 {
+			t.Fatalf("%s: expected one extra row to be fetched, got vars %v", c.name, stmt.Vars)
+		}
+	}
+}
+
+func TestPageOffsetFallback(t *testing.T) {
+	t.Parallel()
+
+	page, err := utils.NewPage[time.Time](testPostKeyset, "", 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var posts []models.Post
+	sql := page.Apply(dryRunDB(t).Model(&models.Post{})).Find(&posts).Statement.SQL.String()
+	if !strings.Contains(sql, "OFFSET") {
+		t.Fatalf("expected offset to still be applied, got %s", sql)
+	}
+
+	all := newestFirst(3)
+	_, cursors := utils.Results(page, all, postPosition)
+	if cursors.NextCursor == nil || cursors.PrevCursor == nil {
+		t.Fatalf("expected offset pages to hand out cursors, got %+v", cursors)
+	}
+}
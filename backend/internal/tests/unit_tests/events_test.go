@@ -0,0 +1,44 @@
+package unitTests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"inside-athletics/internal/events"
+
+	"github.com/google/uuid"
+)
+
+func TestBusDeliversEventsByType(t *testing.T) {
+	t.Parallel()
+
+	bus := events.NewBus()
+	var calls []string
+	events.Subscribe(bus, func(ctx context.Context, e events.PostLiked) error {
+		calls = append(calls, "first")
+		return errors.New("handler failed")
+	})
+	events.Subscribe(bus, func(ctx context.Context, e events.PostLiked) error {
+		calls = append(calls, "second")
+		return nil
+	})
+	events.Subscribe(bus, func(ctx context.Context, e events.CommentLiked) error {
+		calls = append(calls, "comment")
+		return nil
+	})
+
+	bus.Publish(context.Background(), events.PostLiked{PostID: uuid.New(), UserID: uuid.New()})
+
+	// a failing handler doesn't stop the ones after it, and other event types aren't delivered
+	if len(calls) != 2 || calls[0] != "first" || calls[1] != "second" {
+		t.Fatalf("expected both post like handlers in order, got %v", calls)
+	}
+}
+
+func TestNilBusDropsEvents(t *testing.T) {
+	t.Parallel()
+
+	var bus *events.Bus
+	bus.Publish(context.Background(), events.PostLiked{PostID: uuid.New(), UserID: uuid.New()})
+}
@@ -0,0 +1,237 @@
+package unitTests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"inside-athletics/internal/server"
+	"inside-athletics/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// jwksStandIn serves a JWKS document from a local server in place of Supabase.
+type jwksStandIn struct {
+	srv  *httptest.Server
+	hits atomic.Int32
+
+	mu     sync.Mutex
+	keys   map[string]*ecdsa.PrivateKey
+	failed bool
+	// delay is how long each fetch takes, like a real identity provider
+	delay time.Duration
+}
+
+func newJWKSStandIn(t *testing.T) *jwksStandIn {
+	t.Helper()
+	s := &jwksStandIn{keys: map[string]*ecdsa.PrivateKey{}}
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		time.Sleep(s.delay)
+		if s.failed {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		jwks := map[string][]map[string]string{"keys": {}}
+		for kid, key := range s.keys {
+			jwks["keys"] = append(jwks["keys"], map[string]string{
+				"kty": "EC",
+				"crv": "P-256",
+				"alg": "ES256",
+				"use": "sig",
+				"kid": kid,
+				"x":   base64.RawURLEncoding.EncodeToString(key.PublicKey.X.FillBytes(make([]byte, 32))),
+				"y":   base64.RawURLEncoding.EncodeToString(key.PublicKey.Y.FillBytes(make([]byte, 32))),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(jwks)
+	}))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+func (s *jwksStandIn) addKey(t *testing.T, kid string) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[kid] = key
+	return key
+}
+
+func (s *jwksStandIn) setDelay(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = delay
+}
+
+func (s *jwksStandIn) setFailed(failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = failed
+}
+
+func signToken(t *testing.T, key *ecdsa.PrivateKey, kid string, sub string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"sub": sub,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func newAuthTestApp(t *testing.T, url string) *fiber.App {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	keys, err := server.NewKeySet(ctx, server.JWKSConfig{
+		URL:                       url,
+		RefreshInterval:           time.Hour,
+		UnknownKIDRefreshInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to create key set: %v", err)
+	}
+
+	app := fiber.New()
+	app.Use(server.AuthMiddleware(server.NewSupabaseVerifier(keys, server.VerifierConfig{})))
+	app.Get("/", func(c *fiber.Ctx) error {
+		userID, err := utils.GetCurrentUserID(c.UserContext())
+		if err != nil {
+			return err
+		}
+		return c.SendString(userID.String())
+	})
+	return app
+}
+
+func doAuthRequest(t *testing.T, app *fiber.App, token string) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAuthMiddlewareCachesKeys(t *testing.T) {
+	t.Parallel()
+	jwks := newJWKSStandIn(t)
+	key := jwks.addKey(t, "key-1")
+	app := newAuthTestApp(t, jwks.srv.URL)
+
+	token := signToken(t, key, "key-1", uuid.NewString())
+	for i := 0; i < 5; i++ {
+		if status := doAuthRequest(t, app, token); status != http.StatusOK {
+			t.Fatalf("expected 200, got %d", status)
+		}
+	}
+	if hits := jwks.hits.Load(); hits != 1 {
+		t.Fatalf("expected JWKS to be fetched once, got %d", hits)
+	}
+}
+
+func TestAuthMiddlewareRefreshesOnUnknownKID(t *testing.T) {
+	t.Parallel()
+	jwks := newJWKSStandIn(t)
+	jwks.addKey(t, "key-1")
+	app := newAuthTestApp(t, jwks.srv.URL)
+
+	rotated := jwks.addKey(t, "key-2")
+	if status := doAuthRequest(t, app, signToken(t, rotated, "key-2", uuid.NewString())); status != http.StatusOK {
+		t.Fatalf("expected 200 after key rotation, got %d", status)
+	}
+	if hits := jwks.hits.Load(); hits != 2 {
+		t.Fatalf("expected one refresh for the unknown kid, got %d fetches", hits)
+	}
+}
+
+func TestAuthMiddlewareRefreshesFromSlowProvider(t *testing.T) {
+	t.Parallel()
+	jwks := newJWKSStandIn(t)
+	jwks.addKey(t, "key-1")
+	app := newAuthTestApp(t, jwks.srv.URL)
+
+	jwks.setDelay(50 * time.Millisecond)
+	rotated := jwks.addKey(t, "key-2")
+	if status := doAuthRequest(t, app, signToken(t, rotated, "key-2", uuid.NewString())); status != http.StatusOK {
+		t.Fatalf("expected 200 after key rotation on a slow provider, got %d", status)
+	}
+}
+
+func TestAuthMiddlewareStatusCodes(t *testing.T) {
+	t.Parallel()
+	jwks := newJWKSStandIn(t)
+	key := jwks.addKey(t, "key-1")
+	app := newAuthTestApp(t, jwks.srv.URL)
+
+	stranger, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{name: "valid token", token: signToken(t, key, "key-1", uuid.NewString()), want: http.StatusOK},
+		{name: "missing header", token: "", want: http.StatusUnauthorized},
+		{name: "malformed token", token: "not-a-jwt", want: http.StatusUnauthorized},
+		{name: "wrong signing key", token: signToken(t, stranger, "key-1", uuid.NewString()), want: http.StatusUnauthorized},
+		{name: "unknown kid", token: signToken(t, stranger, "key-9", uuid.NewString()), want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		if status := doAuthRequest(t, app, tt.token); status != tt.want {
+			t.Fatalf("%s: expected %d, got %d", tt.name, tt.want, status)
+		}
+	}
+}
+
+func TestAuthMiddlewareKeysUnavailable(t *testing.T) {
+	t.Parallel()
+	jwks := newJWKSStandIn(t)
+	key := jwks.addKey(t, "key-1")
+	jwks.setFailed(true)
+	app := newAuthTestApp(t, jwks.srv.URL)
+
+	token := signToken(t, key, "key-1", uuid.NewString())
+	if status := doAuthRequest(t, app, token); status != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while keys cannot be loaded, got %d", status)
+	}
+
+	jwks.setFailed(false)
+	if status := doAuthRequest(t, app, token); status != http.StatusOK {
+		t.Fatalf("expected 200 once keys are reachable again, got %d", status)
+	}
+}
@@ -0,0 +1,142 @@
+package unitTests
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"inside-athletics/internal/mailer"
+)
+
+// readParts returns the decoded body of each part of a multipart/alternative message, by content type.
+func readParts(t *testing.T, raw string) (*mail.Message, map[string]string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next part: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+	return msg, parts
+}
+
+func TestSMTPSenderDeliversMultipartMessage(t *testing.T) {
+	t.Parallel()
+	stub := StartSMTPStub(t)
+
+	sender, err := mailer.NewSender(stub.Config())
+	if err != nil {
+		t.Fatalf("new sender: %v", err)
+	}
+	email, err := mailer.ReceiptEmail(mailer.Recipient{Email: "suli@example.com", FirstName: "Suli"}, mailer.Receipt{
+		Amount:     999,
+		Currency:   "usd",
+		Number:     "INV-0001",
+		InvoiceURL: "https://invoice.stripe.com/i/test",
+	})
+	if err != nil {
+		t.Fatalf("render receipt: %v", err)
+	}
+	if err := sender.Send(context.Background(), email); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	messages := stub.Messages()
+	if len(messages) != 1 || messages[0].From != "no-reply@insideathletics.app" || len(messages[0].To) != 1 || messages[0].To[0] != "suli@example.com" {
+		t.Fatalf("expected one message from the default sender to suli, got %+v", messages)
+	}
+	msg, parts := readParts(t, messages[0].Data)
+	if msg.Header.Get("Subject") != "Your Inside Athletics receipt" || msg.Header.Get("List-Unsubscribe") != "" {
+		t.Fatalf("expected a receipt without an unsubscribe header, got %v", msg.Header)
+	}
+	for _, contentType := range []string{"text/plain", "text/html"} {
+		if !strings.Contains(parts[contentType], "$9.99") || !strings.Contains(parts[contentType], "INV-0001") {
+			t.Fatalf("expected %s part to show the amount and invoice number, got %q", contentType, parts[contentType])
+		}
+	}
+}
+
+func TestOptionalEmailsLinkToUnsubscribe(t *testing.T) {
+	t.Parallel()
+
+	to := mailer.Recipient{Email: "suli@example.com", FirstName: "Suli", UnsubscribeURL: "http://localhost:8080/api/v1/email/unsubscribe?token=abc"}
+	email, err := mailer.ReplyAlertEmail(to, mailer.ReplyAlert{
+		ReplierName: "coach",
+		Excerpt:     "<script>alert(1)</script> Great point",
+		PostTitle:   "Walk-on tryouts",
+		PostURL:     "http://localhost:3000/posts/1",
+	})
+	if err != nil {
+		t.Fatalf("render reply alert: %v", err)
+	}
+
+	if email.Headers["List-Unsubscribe"] != "<"+to.UnsubscribeURL+">" || email.Headers["List-Unsubscribe-Post"] != "List-Unsubscribe=One-Click" {
+		t.Fatalf("expected one-click unsubscribe headers, got %v", email.Headers)
+	}
+	if strings.Contains(email.HTML, "<script>") || !strings.Contains(email.HTML, "&lt;script&gt;") {
+		t.Fatalf("expected the reply to be escaped in HTML, got %q", email.HTML)
+	}
+	if !strings.Contains(email.Text, "coach replied to your comment on \"Walk-on tryouts\"") || !strings.Contains(email.Text, "Unsubscribe: "+to.UnsubscribeURL) {
+		t.Fatalf("expected the text part to describe the reply and link to unsubscribe, got %q", email.Text)
+	}
+
+	if _, err := mailer.DigestEmail(to, mailer.Digest{}); err == nil {
+		t.Fatal("expected an empty digest to be rejected")
+	}
+}
+
+func TestFileSenderWritesOneFilePerEmail(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender := mailer.NewFileSender(mailer.DefaultFrom, dir)
+
+	for _, to := range []string{"a@example.com", "b@example.com"} {
+		email, err := mailer.DigestEmail(mailer.Recipient{Email: to, FirstName: "Test"}, mailer.Digest{Posts: []mailer.DigestPost{
+			{Title: "Best D3 soccer programs", Excerpt: "Looking for advice", URL: "http://localhost:3000/posts/1", LikeCount: 4, CommentCount: 2},
+		}})
+		if err != nil {
+			t.Fatalf("render digest: %v", err)
+		}
+		if err := sender.Send(context.Background(), email); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("expected two .eml files, got %v (%v)", files, err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	msg, parts := readParts(t, string(raw))
+	if msg.Header.Get("Subject") != "Popular this week: Best D3 soccer programs" {
+		t.Fatalf("expected the top post in the subject, got %q", msg.Header.Get("Subject"))
+	}
+	if !strings.Contains(parts["text/plain"], "4 likes · 2 comments") {
+		t.Fatalf("expected post stats in the text part, got %q", parts["text/plain"])
+	}
+}
@@ -0,0 +1,184 @@
+package unitTests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/permcache"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestGrantsAllowInTheirScope(t *testing.T) {
+	t.Parallel()
+
+	soccer := models.RoleScope{Type: models.RoleScopeSport, ID: uuid.New()}
+	hockey := models.RoleScope{Type: models.RoleScopeSport, ID: uuid.New()}
+	grants := &permcache.Grants{Grants: []permcache.Grant{
+		{Action: models.PermissionCreate, Resource: "post"},
+		{Action: models.PermissionDelete, Resource: "post", Scope: &soccer},
+	}}
+
+	if !grants.Allows(models.PermissionCreate, "post") {
+		t.Fatalf("expected an unscoped grant to allow its action")
+	}
+	if grants.Allows(models.PermissionDelete, "post") || grants.Allows(models.PermissionDelete, "post", hockey) {
+		t.Fatalf("expected a scoped grant to only allow its action in its scope")
+	}
+	if !grants.Allows(models.PermissionDelete, "post", hockey, soccer) {
+		t.Fatalf("expected a scoped grant to allow its action in its scope")
+	}
+	if grants.Allows(models.PermissionDelete, "comment", soccer) {
+		t.Fatalf("expected a grant to only allow its resource")
+	}
+}
+
+func TestGrantsScopes(t *testing.T) {
+	t.Parallel()
+
+	soccer := models.RoleScope{Type: models.RoleScopeSport, ID: uuid.New()}
+	grants := &permcache.Grants{Grants: []permcache.Grant{
+		{Action: models.PermissionReview, Resource: "report", Scope: &soccer},
+		{Action: models.PermissionRead, Resource: "report"},
+	}}
+
+	if everywhere, scopes := grants.Scopes(models.PermissionReview, "report"); everywhere || len(scopes) != 1 || scopes[0] != soccer {
+		t.Fatalf("expected a scoped grant's scope, got %v %v", everywhere, scopes)
+	}
+	if everywhere, _ := grants.Scopes(models.PermissionRead, "report"); !everywhere {
+		t.Fatalf("expected an unscoped grant to apply everywhere")
+	}
+	if everywhere, scopes := grants.Scopes(models.PermissionDelete, "report"); everywhere || len(scopes) != 0 {
+		t.Fatalf("expected no scopes without a grant, got %v %v", everywhere, scopes)
+	}
+}
+
+func TestGrantsRestrictSanctionedAccounts(t *testing.T) {
+	t.Parallel()
+
+	until := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	suspended := &permcache.Grants{Status: models.AccountSuspended, StatusUntil: &until}
+	if msg := suspended.Restriction(true); msg != "" {
+		t.Fatalf("expected a suspended user to still read, got %q", msg)
+	}
+	if msg := suspended.Restriction(false); msg != "Account suspended until 2030-01-02T03:04:05Z" {
+		t.Fatalf("unexpected restriction %q", msg)
+	}
+	if msg := (&permcache.Grants{Status: models.AccountBanned}).Restriction(true); msg != "Account banned" {
+		t.Fatalf("expected a banned user blocked from reading, got %q", msg)
+	}
+	for _, grants := range []*permcache.Grants{nil, {Status: models.AccountShadowBanned}, {Status: models.AccountActive}} {
+		if msg := grants.Restriction(false); msg != "" {
+			t.Fatalf("expected no restriction for %+v, got %q", grants, msg)
+		}
+	}
+}
+
+func TestPermissionCacheDropsStatusWhenItEnds(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	loads := 0
+	load := func(context.Context, uuid.UUID) (*permcache.Grants, error) {
+		loads++
+		until := time.Now().Add(-time.Second)
+		return &permcache.Grants{Status: models.AccountSuspended, StatusUntil: &until}, nil
+	}
+	cache := permcache.New(load, permcache.NewMemory(10), permcache.Config{TTL: time.Hour})
+
+	userID := uuid.New()
+	cache.Get(ctx, userID)
+	cache.Get(ctx, userID)
+	if loads != 2 {
+		t.Fatalf("expected a status that has ended not to be cached, got %d loads", loads)
+	}
+}
+
+func TestPermissionCacheLoadsOnMiss(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	userID, missingID := uuid.New(), uuid.New()
+	loads := map[uuid.UUID]int{}
+	load := func(_ context.Context, id uuid.UUID) (*permcache.Grants, error) {
+		loads[id]++
+		if id == missingID {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return &permcache.Grants{Grants: []permcache.Grant{{Action: models.PermissionCreate, Resource: "post"}}}, nil
+	}
+	cache := permcache.New(load, permcache.NewMemory(10), permcache.Config{TTL: time.Hour})
+
+	for range 3 {
+		grants, err := cache.Get(ctx, userID)
+		if err != nil || !grants.Allows(models.PermissionCreate, "post") {
+			t.Fatalf("expected the user's grants, got %+v, %v", grants, err)
+		}
+	}
+	if loads[userID] != 1 {
+		t.Fatalf("expected the grants to be loaded once, got %d", loads[userID])
+	}
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 || stats.HitRate < 0.66 || stats.HitRate > 0.67 {
+		t.Fatalf("expected 2 hits and 1 miss, got %+v", stats)
+	}
+
+	cache.Invalidate(ctx, userID)
+	cache.Get(ctx, userID)
+	cache.InvalidateAll(ctx)
+	cache.Get(ctx, userID)
+	if loads[userID] != 3 {
+		t.Fatalf("expected invalidating to reload the grants, got %d loads", loads[userID])
+	}
+
+	for range 2 {
+		if _, err := cache.Get(ctx, missingID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expected a missing user to be not found, got %v", err)
+		}
+	}
+	if loads[missingID] != 2 {
+		t.Fatalf("expected a missing user not to be cached, got %d loads", loads[missingID])
+	}
+}
+
+func TestMemoryBackendExpiresAndEvicts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	memory := permcache.NewMemory(2)
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+	grants := &permcache.Grants{}
+
+	if err := memory.Set(ctx, first, grants, -time.Second); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if _, ok, _ := memory.Get(ctx, first); ok || memory.Len() != 0 {
+		t.Fatalf("expected expired grants to be dropped, got %d entries", memory.Len())
+	}
+
+	memory.Set(ctx, first, grants, -time.Second)
+	memory.Set(ctx, second, grants, time.Hour)
+	memory.Set(ctx, third, grants, time.Hour)
+	if _, ok, _ := memory.Get(ctx, second); !ok || memory.Len() != 2 {
+		t.Fatalf("expected a full backend to evict the expired grants first, got %d entries", memory.Len())
+	}
+
+	memory.Set(ctx, first, grants, time.Hour)
+	if _, ok, _ := memory.Get(ctx, first); !ok || memory.Len() != 1 {
+		t.Fatalf("expected a full backend without expired grants to start over, got %d entries", memory.Len())
+	}
+}
+
+func TestNilPermissionCacheIsANoOp(t *testing.T) {
+	t.Parallel()
+
+	var cache *permcache.Cache
+	cache.Invalidate(context.Background(), uuid.New())
+	cache.InvalidateAll(context.Background())
+	if stats := cache.Stats(); stats != (permcache.Stats{}) {
+		t.Fatalf("expected a nil cache to have no stats, got %+v", stats)
+	}
+}
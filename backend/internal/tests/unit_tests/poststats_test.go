@@ -0,0 +1,72 @@
+package unitTests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/poststats"
+)
+
+func TestPopularityConfigFromEnv(t *testing.T) {
+	t.Setenv(poststats.EnvTagFollowWeight, "20")
+	t.Setenv(poststats.EnvRecencyWeight, "0.5")
+	t.Setenv(poststats.EnvTrendingHalfLifeSec, "3600")
+	// invalid values fall back to the defaults
+	t.Setenv(poststats.EnvLikeWeight, "-1")
+	t.Setenv(poststats.EnvCommentWeight, "lots")
+	t.Setenv(poststats.EnvRefreshIntervalSec, "0")
+
+	cfg := poststats.LoadConfigFromEnv()
+	if cfg.Weights.TagFollow != 20 || cfg.Weights.RecencyPerHour != 0.5 || cfg.TrendingHalfLife != time.Hour {
+		t.Fatalf("expected env overrides to apply, got %+v", cfg)
+	}
+	if cfg.Weights.Like != poststats.DefaultWeights.Like || cfg.Weights.Comment != poststats.DefaultWeights.Comment {
+		t.Fatalf("expected invalid weights to fall back to defaults, got %+v", cfg.Weights)
+	}
+	if cfg.RefreshInterval != poststats.DefaultRefreshInterval {
+		t.Fatalf("expected default refresh interval, got %s", cfg.RefreshInterval)
+	}
+	if cfg.Weights.SportFollow != poststats.DefaultWeights.SportFollow {
+		t.Fatalf("expected unset weights to keep their defaults, got %+v", cfg.Weights)
+	}
+}
+
+func TestTrendingScoreDecaysOnRead(t *testing.T) {
+	t.Parallel()
+
+	cfg := poststats.DefaultConfig()
+	cfg.TrendingHalfLife = 2 * time.Hour
+	store := poststats.NewStore(dryRunDB(t), cfg)
+
+	var stats []models.PostStats
+	stmt := dryRunDB(t).Table("post_stats AS ps").Select("?", store.TrendingScore("ps")).Find(&stats).Statement
+	sql := stmt.SQL.String()
+	if !strings.Contains(sql, "ps.trending_score * POWER(0.5") || !strings.Contains(sql, "NOW() - ps.scored_at") {
+		t.Fatalf("expected the stored score to be decayed since scored_at, got %s", sql)
+	}
+	if len(stmt.Vars) != 1 || stmt.Vars[0] != float64(7200) {
+		t.Fatalf("expected the half-life in seconds to be bound, got %v", stmt.Vars)
+	}
+}
+
+func TestTrendingScoreAtLeavesOutLaterActivity(t *testing.T) {
+	t.Parallel()
+
+	store := poststats.NewStore(dryRunDB(t), poststats.DefaultConfig())
+	at := time.Now().UTC()
+
+	var stats []models.PostStats
+	stmt := dryRunDB(t).Table("post_stats AS ps").Select("?", store.TrendingScoreAt("ps", at)).Find(&stats).Statement
+	sql := stmt.SQL.String()
+	for _, table := range []string{"post_likes", "comments"} {
+		if !strings.Contains(sql, "FROM "+table+" AS activity WHERE activity.post_id = ps.post_id AND activity.created_at > CAST($") {
+			t.Fatalf("expected %s made after the cursor's time to be taken back out, got %s", table, sql)
+		}
+	}
+	weights := poststats.DefaultWeights
+	if len(stmt.Vars) != 10 || stmt.Vars[0] != at || stmt.Vars[2] != weights.TrendingLike || stmt.Vars[6] != weights.TrendingComment {
+		t.Fatalf("expected the time and weights to be bound, got %v", stmt.Vars)
+	}
+}
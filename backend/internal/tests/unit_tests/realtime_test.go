@@ -0,0 +1,83 @@
+package unitTests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"inside-athletics/internal/realtime"
+
+	"github.com/google/uuid"
+)
+
+func realtimeMessage(topic string, n int) realtime.Message {
+	data, _ := json.Marshal(map[string]int{"n": n})
+	return realtime.Message{Topic: topic, Event: "test", Data: data}
+}
+
+func TestHubDeliversToTopicSubscribers(t *testing.T) {
+	t.Parallel()
+
+	hub := realtime.NewHub(realtime.Config{BufferSize: 4})
+	comments := realtime.PostCommentsTopic(uuid.New())
+	likes := realtime.PostLikesTopic(uuid.New())
+	both := hub.Subscribe(comments, likes)
+	defer both.Close()
+	onlyLikes := hub.Subscribe(likes)
+	defer onlyLikes.Close()
+
+	if err := hub.Publish(context.Background(), realtimeMessage(comments, 1)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	hub.Deliver(realtimeMessage(likes, 2))
+
+	for _, want := range []realtime.Message{realtimeMessage(comments, 1), realtimeMessage(likes, 2)} {
+		if got := <-both.Messages(); got.Topic != want.Topic || string(got.Data) != string(want.Data) {
+			t.Fatalf("expected %+v, got %+v", want, got)
+		}
+	}
+	if got := <-onlyLikes.Messages(); got.Topic != likes {
+		t.Fatalf("expected only the likes message, got %+v", got)
+	}
+	if len(onlyLikes.Messages()) != 0 || len(both.Messages()) != 0 {
+		t.Fatal("expected no more messages")
+	}
+}
+
+func TestHubDropsSlowSubscribers(t *testing.T) {
+	t.Parallel()
+
+	hub := realtime.NewHub(realtime.Config{BufferSize: 2})
+	topic := realtime.NotificationsTopic(uuid.New())
+	slow := hub.Subscribe(topic)
+	fast := hub.Subscribe(topic)
+	defer fast.Close()
+
+	for i := 0; i < 3; i++ {
+		hub.Deliver(realtimeMessage(topic, i))
+		<-fast.Messages()
+	}
+
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("expected the subscriber that stopped reading to be dropped")
+	}
+	if !errors.Is(slow.Err(), realtime.ErrSlowConsumer) {
+		t.Fatalf("expected ErrSlowConsumer, got %v", slow.Err())
+	}
+	// what was buffered before the drop can still be read
+	if len(slow.Messages()) != 2 {
+		t.Fatalf("expected the 2 buffered messages, got %d", len(slow.Messages()))
+	}
+	if n := hub.Subscribers(topic); n != 1 {
+		t.Fatalf("expected only the reading subscriber left, got %d", n)
+	}
+
+	fast.Close()
+	fast.Close()
+	if fast.Err() != nil || hub.Subscribers(topic) != 0 {
+		t.Fatalf("expected a clean close with no subscribers left, got %v and %d", fast.Err(), hub.Subscribers(topic))
+	}
+}
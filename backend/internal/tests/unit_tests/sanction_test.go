@@ -0,0 +1,43 @@
+package unitTests
+
+import (
+	"testing"
+	"time"
+
+	"inside-athletics/internal/handlers/sanction"
+	"inside-athletics/internal/models"
+)
+
+func TestAccountStateOfSanctions(t *testing.T) {
+	t.Parallel()
+
+	soon := time.Now().Add(time.Hour)
+	later := soon.Add(24 * time.Hour)
+	suspend := func(until time.Time) models.Sanction {
+		return models.Sanction{Type: models.SanctionSuspend, ExpiresAt: &until}
+	}
+
+	if state := sanction.StateOf(nil); state.Status != models.AccountActive || state.Until != nil {
+		t.Fatalf("expected an active account without sanctions, got %+v", state)
+	}
+
+	state := sanction.StateOf([]models.Sanction{suspend(soon), suspend(later)})
+	if state.Status != models.AccountSuspended || state.Until == nil || !state.Until.Equal(later) {
+		t.Fatalf("expected the longest suspension to win, got %+v", state)
+	}
+
+	state = sanction.StateOf([]models.Sanction{{Type: models.SanctionShadowBan}, suspend(soon), {Type: models.SanctionBan, ExpiresAt: &later}})
+	if state.Status != models.AccountBanned || state.Until == nil || !state.Until.Equal(later) || len(state.Sanctions) != 3 {
+		t.Fatalf("expected a ban to outrank other sanctions, got %+v", state)
+	}
+
+	state = sanction.StateOf([]models.Sanction{{Type: models.SanctionBan, ExpiresAt: &later}, {Type: models.SanctionBan}})
+	if state.Status != models.AccountBanned || state.Until != nil {
+		t.Fatalf("expected a ban without an expiry to be permanent, got %+v", state)
+	}
+
+	state = sanction.StateOf([]models.Sanction{{Type: models.SanctionShadowBan}})
+	if state.Status != models.AccountShadowBanned || state.Until != nil {
+		t.Fatalf("expected a permanent shadow ban, got %+v", state)
+	}
+}
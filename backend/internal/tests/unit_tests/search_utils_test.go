@@ -0,0 +1,144 @@
+package unitTests
+
+import (
+	"strings"
+	"testing"
+
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB builds SQL without a database so the generated statements can be inspected.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("failed to open dry run db: %v", err)
+	}
+	return db
+}
+
+func TestFuzzySearchBindsUserInput(t *testing.T) {
+	t.Parallel()
+
+	inputs := []string{
+		"'; DROP TABLE colleges; --",
+		"erm') OR 1=1 --",
+		`\'; SELECT pg_sleep(10); --`,
+		"$1 $$ ?",
+		"Northeastern University",
+	}
+
+	for _, input := range inputs {
+		search := utils.NewFuzzySearch(input, utils.Column("name"), utils.WeightedColumn("city", 0.8)).
+			WithThreshold(0.4).
+			Paginate(10, 20)
+		var colleges []models.College
+		stmt := search.Apply(dryRunDB(t).Model(&models.College{})).Find(&colleges).Statement
+		sql := stmt.SQL.String()
+
+		if strings.Contains(sql, input) {
+			t.Fatalf("search string %q was interpolated into SQL: %s", input, sql)
+		}
+		if !strings.Contains(sql, `word_similarity($2, "name")`) || !strings.Contains(sql, `word_similarity($4, "city")`) {
+			t.Fatalf("expected quoted columns with bound search params, got: %s", sql)
+		}
+		if !strings.Contains(sql, "GREATEST(") || !strings.Contains(sql, "LIMIT $10 OFFSET $11") {
+			t.Fatalf("expected weighted score with pagination, got: %s", sql)
+		}
+
+		bound := 0
+		for _, v := range stmt.Vars {
+			if v == input {
+				bound++
+			}
+		}
+		// once for the WHERE and once for the ORDER BY, per column
+		if bound != 4 {
+			t.Fatalf("expected search string to be bound 4 times, got %d (vars: %v)", bound, stmt.Vars)
+		}
+		if stmt.Vars[4] != 0.4 || stmt.Vars[9] != 10 || stmt.Vars[10] != 20 {
+			t.Fatalf("expected threshold, limit and offset to be bound, got %v", stmt.Vars)
+		}
+	}
+}
+
+func TestFuzzySearchAcceptsZeroThreshold(t *testing.T) {
+	t.Parallel()
+
+	search := utils.NewFuzzySearch("north", utils.Column("name")).WithThreshold(0)
+	var colleges []models.College
+	stmt := search.Apply(dryRunDB(t).Model(&models.College{})).Find(&colleges).Statement
+
+	if stmt.Vars[2] != 0.0 {
+		t.Fatalf("expected an explicit 0 threshold to be bound, got %v", stmt.Vars)
+	}
+
+	search = utils.NewFuzzySearch("north", utils.Column("name")).WithThreshold(-1)
+	stmt = search.Apply(dryRunDB(t).Model(&models.College{})).Find(&colleges).Statement
+
+	if stmt.Vars[2] != utils.DefaultSimilarityThreshold {
+		t.Fatalf("expected a negative threshold to keep the default, got %v", stmt.Vars)
+	}
+}
+
+func TestFuzzySearchQuotesColumns(t *testing.T) {
+	t.Parallel()
+
+	search := utils.NewFuzzySearch("north", utils.Column("posts.title"))
+	var posts []models.Post
+	sql := search.Apply(dryRunDB(t).Model(&models.Post{})).Find(&posts).Statement.SQL.String()
+
+	if !strings.Contains(sql, `word_similarity($2, "posts"."title")`) {
+		t.Fatalf("expected table qualified column to be quoted, got: %s", sql)
+	}
+	if strings.Contains(sql, "GREATEST(") {
+		t.Fatalf("expected single column search to skip GREATEST, got: %s", sql)
+	}
+}
+
+func TestTextSearchBindsUserInput(t *testing.T) {
+	t.Parallel()
+
+	input := `"fencing" or -recruiting'); DROP TABLE posts; --`
+	text := utils.NewTextSearch(input, "posts.search_vector")
+	filters := utils.PostFilters{SportIDs: []uuid.UUID{uuid.New()}, TagIDs: []uuid.UUID{uuid.New()}}
+
+	var posts []models.Post
+	stmt := filters.Apply(dryRunDB(t).Model(&models.Post{}), "posts", "post").
+		Select("posts.*, ? AS snippet", text.Headline("posts.content", utils.SnippetHeadlineOptions)).
+		Where(text.Matches()).
+		Find(&posts).Statement
+	sql := stmt.SQL.String()
+
+	if strings.Contains(sql, "DROP TABLE") {
+		t.Fatalf("search string was interpolated into SQL: %s", sql)
+	}
+	if !strings.Contains(sql, `"posts"."search_vector" @@ websearch_to_tsquery('english', $`) {
+		t.Fatalf("expected quoted vector column matched against a bound tsquery, got: %s", sql)
+	}
+	if !strings.Contains(sql, `ts_headline('english', replace(replace(replace(replace(replace("posts"."content", '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;'), websearch_to_tsquery('english', $1), $2)`) {
+		t.Fatalf("expected headline over the HTML escaped content column, got: %s", sql)
+	}
+	if !strings.Contains(sql, `"posts"."sport_id" IN ($`) || !strings.Contains(sql, "tag_posts.tag_id IN ($") {
+		t.Fatalf("expected filters to be bound, got: %s", sql)
+	}
+}
+
+func TestFuzzySearchIsAlwaysLimited(t *testing.T) {
+	t.Parallel()
+
+	for _, limit := range []int{0, -1} {
+		search := utils.NewFuzzySearch("north", utils.Column("name")).Paginate(limit, 0)
+		var colleges []models.College
+		stmt := search.Apply(dryRunDB(t).Model(&models.College{})).Find(&colleges).Statement
+
+		if !strings.Contains(stmt.SQL.String(), "LIMIT $") || stmt.Vars[len(stmt.Vars)-1] != utils.DefaultSearchLimit {
+			t.Fatalf("expected a limit of %d to keep the default, got: %s %v", limit, stmt.SQL.String(), stmt.Vars)
+		}
+	}
+}
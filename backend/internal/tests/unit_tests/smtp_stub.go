@@ -0,0 +1,114 @@
+package unitTests
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"inside-athletics/internal/mailer"
+)
+
+// SMTPMessage is one message an SMTPStub accepted.
+type SMTPMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+// SMTPStub is a local SMTP server that accepts every message and keeps it, so the SMTP sender can
+// be tested without a real mail server. It speaks just enough SMTP for net/smtp.
+type SMTPStub struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []SMTPMessage
+}
+
+// StartSMTPStub starts an SMTPStub on a free port. It's stopped when the test ends.
+func StartSMTPStub(t testing.TB) *SMTPStub {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	stub := &SMTPStub{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go stub.serve(conn)
+		}
+	}()
+	return stub
+}
+
+// Config returns DefaultConfig set to send through the stub.
+func (s *SMTPStub) Config() mailer.Config {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	cfg := mailer.DefaultConfig()
+	cfg.Sender = mailer.SenderSMTP
+	cfg.SMTPHost = addr.IP.String()
+	cfg.SMTPPort = addr.Port
+	return cfg
+}
+
+// Messages returns every message accepted so far.
+func (s *SMTPStub) Messages() []SMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SMTPMessage(nil), s.messages...)
+}
+
+func (s *SMTPStub) serve(conn net.Conn) {
+	text := textproto.NewConn(conn)
+	defer text.Close()
+
+	var msg SMTPMessage
+	_ = text.PrintfLine("220 localhost SMTP stub")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.Fields(line + " ")[0])
+		switch verb {
+		case "EHLO", "HELO":
+			_ = text.PrintfLine("250 localhost")
+		case "MAIL":
+			msg = SMTPMessage{From: addressOf(line)}
+			_ = text.PrintfLine("250 OK")
+		case "RCPT":
+			msg.To = append(msg.To, addressOf(line))
+			_ = text.PrintfLine("250 OK")
+		case "DATA":
+			_ = text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			_ = text.PrintfLine("250 OK")
+		case "QUIT":
+			_ = text.PrintfLine("221 Bye")
+			return
+		default:
+			_ = text.PrintfLine("250 OK")
+		}
+	}
+}
+
+// addressOf returns the address in a MAIL FROM:<...> or RCPT TO:<...> line.
+func addressOf(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
@@ -0,0 +1,97 @@
+package unitTests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"inside-athletics/internal/handlers/survey"
+	"inside-athletics/internal/models"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+func expectUnprocessable(t *testing.T, err error, what string) {
+	t.Helper()
+	var status huma.StatusError
+	if !errors.As(err, &status) || status.GetStatus() != http.StatusUnprocessableEntity {
+		t.Fatalf("expected a 422 for %s, got %v", what, err)
+	}
+}
+
+func TestValidateQuestions(t *testing.T) {
+	t.Parallel()
+
+	valid := []survey.QuestionInput{
+		{Key: "culture", Type: models.SurveyQuestionScale, Prompt: "How good is the team culture?"},
+		{Key: "position_group", Type: models.SurveyQuestionChoice, Prompt: "What do you play?", Options: []string{"Offense", "Defense"}},
+		{Key: "advice", Type: models.SurveyQuestionText, Prompt: "Any advice for recruits?"},
+	}
+	if err := survey.ValidateQuestions(valid); err != nil {
+		t.Fatalf("expected the questions to be valid, got %v", err)
+	}
+
+	cases := map[string][]survey.QuestionInput{
+		"a repeated key":     {valid[0], valid[0]},
+		"options on a scale": {{Key: "culture", Type: models.SurveyQuestionScale, Options: []string{"Good", "Bad"}}},
+		"a single option":    {{Key: "position_group", Type: models.SurveyQuestionChoice, Options: []string{"Offense"}}},
+		"a repeated option":  {{Key: "position_group", Type: models.SurveyQuestionChoice, Options: []string{"Offense", "Offense"}}},
+		"a blank option":     {{Key: "position_group", Type: models.SurveyQuestionChoice, Options: []string{"Offense", " "}}},
+	}
+	for what, questions := range cases {
+		expectUnprocessable(t, survey.ValidateQuestions(questions), what)
+	}
+}
+
+func TestCheckQuestionKeyTypes(t *testing.T) {
+	t.Parallel()
+
+	existing := map[string]models.SurveyQuestionType{"culture": models.SurveyQuestionScale}
+	kept := []survey.QuestionInput{{Key: "culture", Type: models.SurveyQuestionScale}, {Key: "advice", Type: models.SurveyQuestionText}}
+	if err := survey.CheckQuestionKeyTypes(kept, existing); err != nil {
+		t.Fatalf("expected keys keeping their type and new keys to be allowed, got %v", err)
+	}
+	retyped := []survey.QuestionInput{{Key: "culture", Type: models.SurveyQuestionText}}
+	expectUnprocessable(t, survey.CheckQuestionKeyTypes(retyped, existing), "a key changing type")
+}
+
+func TestAnswersFor(t *testing.T) {
+	t.Parallel()
+
+	questions := []models.SurveyQuestion{
+		{ID: uuid.New(), Key: "culture", Type: models.SurveyQuestionScale, Required: true},
+		{ID: uuid.New(), Key: "position_group", Type: models.SurveyQuestionChoice, Required: true, Options: []string{"Offense", "Defense"}},
+		{ID: uuid.New(), Key: "advice", Type: models.SurveyQuestionText},
+	}
+	four, six, offense, goalie, advice := int32(4), int32(6), "Offense", "Goalie", "Visit in the fall"
+
+	answers, err := survey.AnswersFor(questions, []survey.AnswerInput{
+		{QuestionKey: "position_group", Choice: &offense},
+		{QuestionKey: "culture", Scale: &four},
+		{QuestionKey: "advice", Text: &advice},
+	})
+	if err != nil {
+		t.Fatalf("expected the answers to be valid, got %v", err)
+	}
+	if len(answers) != 3 || answers[0].QuestionID != questions[1].ID || *answers[1].Scale != 4 || *answers[2].Text != advice {
+		t.Fatalf("expected each answer matched to its question, got %+v", answers)
+	}
+	if _, err := survey.AnswersFor(questions, []survey.AnswerInput{{QuestionKey: "culture", Scale: &four}, {QuestionKey: "position_group", Choice: &offense}}); err != nil {
+		t.Fatalf("expected optional questions to be skippable, got %v", err)
+	}
+
+	cases := map[string][]survey.AnswerInput{
+		"a required question left out":  {{QuestionKey: "culture", Scale: &four}},
+		"an unknown question":           {{QuestionKey: "culture", Scale: &four}, {QuestionKey: "position_group", Choice: &offense}, {QuestionKey: "facilities", Scale: &four}},
+		"a question answered twice":     {{QuestionKey: "culture", Scale: &four}, {QuestionKey: "culture", Scale: &four}, {QuestionKey: "position_group", Choice: &offense}},
+		"a scale out of range":          {{QuestionKey: "culture", Scale: &six}, {QuestionKey: "position_group", Choice: &offense}},
+		"a choice that isn't an option": {{QuestionKey: "culture", Scale: &four}, {QuestionKey: "position_group", Choice: &goalie}},
+		"the wrong kind of answer":      {{QuestionKey: "culture", Text: &advice}, {QuestionKey: "position_group", Choice: &offense}},
+		"more than one kind of answer":  {{QuestionKey: "culture", Scale: &four, Choice: &offense}, {QuestionKey: "position_group", Choice: &offense}},
+	}
+	for what, input := range cases {
+		_, err := survey.AnswersFor(questions, input)
+		expectUnprocessable(t, err, what)
+	}
+}
@@ -0,0 +1,89 @@
+package unitTests
+
+import (
+	"math"
+	"testing"
+
+	"inside-athletics/internal/handlers/survey"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestHistogramStats(t *testing.T) {
+	t.Parallel()
+
+	var h survey.Histogram
+	h.Add(2, 1)
+	h.Add(4, 2)
+	h.Add(5, 1)
+	h.Add(0, 3) // out of range ratings aren't counted
+
+	if h.Count() != 4 || h != (survey.Histogram{0, 1, 0, 2, 1}) {
+		t.Fatalf("expected 4 ratings of 2, 4, 4 and 5, got %v", h)
+	}
+	if !almostEqual(h.Mean(), 3.75) {
+		t.Fatalf("expected mean 3.75, got %v", h.Mean())
+	}
+	// squared deviations 3.0625 + 0.0625*2 + 1.5625 = 4.75, over n-1 = 3
+	if !almostEqual(h.StdDev(), math.Sqrt(4.75/3)) {
+		t.Fatalf("expected the sample standard deviation, got %v", h.StdDev())
+	}
+	low, high := h.ConfidenceInterval()
+	margin := 1.96 * math.Sqrt(4.75/3) / 2
+	if !almostEqual(low, 3.75-margin) || !almostEqual(high, 3.75+margin) {
+		t.Fatalf("expected the 95%% interval around the mean, got [%v, %v]", low, high)
+	}
+
+	var high5 survey.Histogram
+	high5.Add(5, 3)
+	high5.Add(4, 1)
+	if _, high := high5.ConfidenceInterval(); high != 5 {
+		t.Fatalf("expected the interval clamped to the scale, got a high of %v", high)
+	}
+}
+
+func TestHistogramWithoutSpread(t *testing.T) {
+	t.Parallel()
+
+	var empty survey.Histogram
+	if empty.Mean() != 0 || empty.StdDev() != 0 {
+		t.Fatalf("expected no mean or spread without ratings, got %v and %v", empty.Mean(), empty.StdDev())
+	}
+	var one survey.Histogram
+	one.Add(5, 1)
+	if low, high := one.ConfidenceInterval(); low != 1 || high != 5 {
+		t.Fatalf("expected one rating to say nothing about the spread, got [%v, %v]", low, high)
+	}
+}
+
+func TestSmoothKeepsFewResponsesNearThePrior(t *testing.T) {
+	t.Parallel()
+
+	few := survey.Smooth(5, 2, 3, 5)
+	many := survey.Smooth(4.5, 100, 3, 5)
+	if !almostEqual(few, (10.0+15)/7) {
+		t.Fatalf("expected 2 perfect ratings to be pulled towards 3, got %v", few)
+	}
+	if many <= few {
+		t.Fatalf("expected 100 ratings averaging 4.5 (%v) to outrank 2 perfect ones (%v)", many, few)
+	}
+	if got := survey.Smooth(4, 10, 3, 0); got != 4 {
+		t.Fatalf("expected no smoothing without a prior weight, got %v", got)
+	}
+	if got := survey.Smooth(0, 0, 3, 0); got != 3 {
+		t.Fatalf("expected the prior without responses or weight, got %v", got)
+	}
+}
+
+func TestAcademicYear(t *testing.T) {
+	t.Parallel()
+
+	if got := survey.AcademicYear(2025); got != "2025-26" {
+		t.Fatalf("expected 2025-26, got %s", got)
+	}
+	if got := survey.AcademicYear(2099); got != "2099-00" {
+		t.Fatalf("expected 2099-00, got %s", got)
+	}
+}
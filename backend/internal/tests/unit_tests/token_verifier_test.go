@@ -0,0 +1,105 @@
+package unitTests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"inside-athletics/internal/server"
+	"inside-athletics/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// keysDown is a KeyProvider with no keys loaded, as when the JWKS endpoint can't be reached.
+type keysDown struct{ *TokenIssuer }
+
+func (keysDown) Available(ctx context.Context) bool { return false }
+
+func TestSupabaseVerifier(t *testing.T) {
+	t.Parallel()
+	issuer := NewTokenIssuer()
+	verifier := issuer.Verifier()
+
+	claims, err := verifier.Verify(context.Background(), issuer.Token("user-1", WithRole("authenticated"), WithEmail("a@b.com")))
+	if err != nil {
+		t.Fatalf("expected token to verify: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "a@b.com" || claims.Role != "authenticated" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "expired", token: issuer.Token("user-1", WithExpiry(time.Now().Add(-time.Minute)))},
+		{name: "other signing key", token: NewTokenIssuer().Token("user-1")},
+		{name: "garbage", token: "not-a-jwt"},
+		{name: "no expiry", token: issuer.Token("user-1", func(c *server.Claims) { c.ExpiresAt = nil })},
+		{name: "wrong issuer", token: issuer.Token("user-1", WithIssuer("https://evil.example.com/auth/v1"))},
+		{name: "wrong audience", token: issuer.Token("user-1", WithAudience("anon"))},
+	}
+	for _, tt := range tests {
+		_, err := verifier.Verify(context.Background(), tt.token)
+		if err == nil {
+			t.Fatalf("%s: expected verification to fail", tt.name)
+		}
+		if errors.Is(err, server.ErrKeysUnavailable) {
+			t.Fatalf("%s: expected invalid token error, got %v", tt.name, err)
+		}
+	}
+
+	_, err = server.NewSupabaseVerifier(keysDown{issuer}, server.VerifierConfig{}).Verify(context.Background(), NewTokenIssuer().Token("user-1"))
+	if !errors.Is(err, server.ErrKeysUnavailable) {
+		t.Fatalf("expected ErrKeysUnavailable, got %v", err)
+	}
+}
+
+func TestAuthMiddlewareSetsPrincipal(t *testing.T) {
+	t.Parallel()
+	issuer := NewTokenIssuer()
+
+	var got *utils.Principal
+	app := fiber.New()
+	app.Use(server.AuthMiddleware(issuer.Verifier()))
+	app.Get("/", func(c *fiber.Ctx) error {
+		principal, err := utils.GetCurrentPrincipal(c.UserContext())
+		if err != nil {
+			return err
+		}
+		got = principal
+		return c.SendStatus(http.StatusOK)
+	})
+
+	userID := uuid.New()
+	exp := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+	token := issuer.Token(userID.String(),
+		WithEmail("athlete@example.com"),
+		WithSessionID("session-123"),
+		WithAuthMethod("oauth"),
+		WithExpiry(exp),
+	)
+	if status := doAuthRequest(t, app, token); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+
+	want := utils.Principal{
+		UserID:     userID,
+		Email:      "athlete@example.com",
+		SessionID:  "session-123",
+		AuthMethod: "oauth",
+		ExpiresAt:  exp,
+	}
+	if got == nil || got.UserID != want.UserID || got.Email != want.Email || got.SessionID != want.SessionID ||
+		got.AuthMethod != want.AuthMethod || !got.ExpiresAt.Equal(want.ExpiresAt) {
+		t.Fatalf("expected principal %+v, got %+v", want, got)
+	}
+
+	if status := doAuthRequest(t, app, issuer.Token("not-a-uuid")); status != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for non-UUID subject, got %d", status)
+	}
+}
@@ -0,0 +1,74 @@
+package unitTests
+
+import (
+	"strings"
+	"testing"
+
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+func verifiedAthlete(collegeID, sportID uuid.UUID) *models.User {
+	division := models.DivisionI
+	return &models.User{
+		Verified_Athlete_Status: models.VerifiedAthleteStatusVerified,
+		CollegeID:               &collegeID,
+		SportID:                 &sportID,
+		Division:                &division,
+	}
+}
+
+func TestTrustOfComparesTheAuthorsTeam(t *testing.T) {
+	t.Parallel()
+
+	collegeID, sportID, otherSportID := uuid.New(), uuid.New(), uuid.New()
+	author := verifiedAthlete(collegeID, sportID)
+
+	trust := utils.TrustOf(author, &collegeID, &otherSportID, false)
+	if !trust.IsVerifiedAthlete || !trust.MatchesCollege || trust.MatchesSport {
+		t.Fatalf("expected a verified author matching only the college, got %+v", trust)
+	}
+	if trust.CollegeID == nil || *trust.CollegeID != collegeID || trust.SportID == nil || *trust.SportID != sportID {
+		t.Fatalf("expected the author's team, got %+v", trust)
+	}
+	if trust := utils.TrustOf(author, nil, nil, false); trust.MatchesCollege || trust.MatchesSport {
+		t.Fatalf("expected content about no team not to match, got %+v", trust)
+	}
+
+	author.Verified_Athlete_Status = models.VerifiedAthleteStatusPending
+	if trust := utils.TrustOf(author, &collegeID, &sportID, false); trust.IsVerifiedAthlete || trust.MatchesCollege || trust.CollegeID != nil {
+		t.Fatalf("expected an author pending verification not to be trusted, got %+v", trust)
+	}
+}
+
+func TestTrustOfHidesAnonymousAuthors(t *testing.T) {
+	t.Parallel()
+
+	collegeID, sportID := uuid.New(), uuid.New()
+	trust := utils.TrustOf(verifiedAthlete(collegeID, sportID), &collegeID, &sportID, true)
+	if !trust.IsVerifiedAthlete {
+		t.Fatalf("expected anonymous content to still say its author is verified, got %+v", trust)
+	}
+	if trust.CollegeID != nil || trust.SportID != nil || trust.Division != nil || trust.MatchesCollege || trust.MatchesSport {
+		t.Fatalf("expected nothing identifying about an anonymous author, got %+v", trust)
+	}
+}
+
+func TestVerifiedOnlyNarrowsOtherFilters(t *testing.T) {
+	t.Parallel()
+
+	filters := utils.PostFilters{CollegeIDs: []uuid.UUID{uuid.New()}, SportIDs: []uuid.UUID{uuid.New()}, VerifiedOnly: true}
+	var posts []models.PremiumPost
+	stmt := filters.Apply(dryRunDB(t).Model(&models.PremiumPost{}), "premium_posts", "premium_post").
+		Find(&posts).Statement
+	sql := stmt.SQL.String()
+
+	if !strings.Contains(sql, "va.id = premium_posts.author_id AND va.verified_athlete_status = $1)) AND ((") {
+		t.Fatalf("expected verified authors to be required alongside the other filters, got: %s", sql)
+	}
+	if stmt.Vars[0] != models.VerifiedAthleteStatusVerified {
+		t.Fatalf("expected the verified status to be bound, got %v", stmt.Vars)
+	}
+}
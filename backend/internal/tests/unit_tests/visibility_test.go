@@ -0,0 +1,49 @@
+package unitTests
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"inside-athletics/internal/models"
+	"inside-athletics/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+func TestVisibleToLeavesOutMutedAuthors(t *testing.T) {
+	t.Parallel()
+
+	viewerID := uuid.New()
+	var comments []models.Comment
+	stmt := dryRunDB(t).Model(&models.Comment{}).
+		Scopes(utils.VisibleTo(utils.CommentsTable, viewerID)).
+		Find(&comments).Statement
+	sql := stmt.SQL.String()
+
+	for _, want := range []string{
+		"comments.hidden_at IS NULL",
+		"comments.user_id = $1 OR NOT (EXISTS",
+		"ub.user_id = $3 AND ub.target_id = comments.user_id",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Fatalf("expected %q in: %s", want, sql)
+		}
+	}
+	if len(stmt.Vars) != 3 || stmt.Vars[0] != viewerID || stmt.Vars[1] != models.SanctionShadowBan || stmt.Vars[2] != viewerID {
+		t.Fatalf("expected the viewer bound for their own content and their mutes, got %v", stmt.Vars)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	t.Parallel()
+
+	got := utils.Mentions("@coach_k great game. cc @jane.doe and @coach_k.", "email me at fan@example.com, @@twice")
+	want := []string{"coach_k", "jane.doe"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got := utils.Mentions(utils.SetTexts(nil, nil)...); len(got) != 0 {
+		t.Fatalf("expected no mentions in no text, got %v", got)
+	}
+}
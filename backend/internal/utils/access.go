@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"inside-athletics/internal/models"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// accessKey is the huma.Operation metadata key an operation's Access is stored under.
+const accessKey = "access"
+
+// OwnerFunc reports whether the user owns the resource with id.
+type OwnerFunc func(db *gorm.DB, userID, id uuid.UUID) (bool, error)
+
+// KeyOwnerFunc reports whether the user owns the resource with key, for resources identified by
+// something other than a UUID.
+type KeyOwnerFunc func(db *gorm.DB, userID uuid.UUID, key string) (bool, error)
+
+// ScopeFunc returns the sports and colleges the resource with id is in.
+type ScopeFunc func(db *gorm.DB, id uuid.UUID) ([]models.RoleScope, error)
+
+// Access is what an operation requires of the caller. Every operation that changes something
+// declares one with Requires, and the permission middleware enforces it before the handler runs.
+type Access struct {
+	// Action on Resource is the permission the caller needs. With no action any signed-in user
+	// may call the operation, and the handler checks anything else.
+	Action   models.PermissionAction
+	Resource string
+	// IDParam is the path parameter holding the resource's ID, "id" when empty.
+	IDParam string
+	// IDQuery, when set, is the query parameter holding the resource's ID instead, for resources
+	// like S3 objects whose keys can't go in a path.
+	IDQuery string
+	// Owner, when set, resolves whether the caller owns the resource. Owners need the _own
+	// variant of Action instead, such as delete_own for delete.
+	Owner OwnerFunc
+	// KeyOwner is Owner for resources whose ID in the path isn't a UUID.
+	KeyOwner KeyOwnerFunc
+	// Scopes, when set, resolves the sports and colleges the resource is in, so roles scoped to
+	// them grant Action too.
+	Scopes ScopeFunc
+	// AnyScope lets roles scoped to any sport or college grant Action, for operations whose
+	// handler narrows what the caller sees down to their scopes.
+	AnyScope bool
+}
+
+// Authenticated lets any signed-in user call an operation.
+var Authenticated = Access{}
+
+// Permission requires action on resource.
+func Permission(action models.PermissionAction, resource string) Access {
+	return Access{Action: action, Resource: resource}
+}
+
+// OwnedBy returns a copy of a whose owners are resolved by owner.
+func (a Access) OwnedBy(owner OwnerFunc) Access {
+	a.Owner = owner
+	return a
+}
+
+// OwnedByKey returns a copy of a whose owners are resolved by owner, from the raw path ID.
+func (a Access) OwnedByKey(owner KeyOwnerFunc) Access {
+	a.KeyOwner = owner
+	return a
+}
+
+// InScopes returns a copy of a that roles scoped to the resource's sports and colleges grant.
+func (a Access) InScopes(scopes ScopeFunc) Access {
+	a.Scopes = scopes
+	return a
+}
+
+// InAnyScope returns a copy of a that roles scoped to any sport or college grant.
+func (a Access) InAnyScope() Access {
+	a.AnyScope = true
+	return a
+}
+
+// WithIDParam returns a copy of a that reads the resource's ID from param.
+func (a Access) WithIDParam(param string) Access {
+	a.IDParam = param
+	return a
+}
+
+// WithIDQuery returns a copy of a that reads the resource's ID from the query parameter param.
+func (a Access) WithIDQuery(param string) Access {
+	a.IDQuery = param
+	return a
+}
+
+// ResourceID is the ID of the resource the request in ctx is for.
+func (a Access) ResourceID(ctx huma.Context) string {
+	if a.IDQuery != "" {
+		return ctx.Query(a.IDQuery)
+	}
+	return ctx.Param(a.IDParamName())
+}
+
+// IDParamName is the path parameter holding the resource's ID.
+func (a Access) IDParamName() string {
+	if a.IDParam == "" {
+		return "id"
+	}
+	return a.IDParam
+}
+
+// OwnAction is the action owners of the resource need instead of a.Action.
+func (a Access) OwnAction() models.PermissionAction {
+	switch a.Action {
+	case models.PermissionUpdate:
+		return models.PermissionUpdateOwn
+	case models.PermissionDelete:
+		return models.PermissionDeleteOwn
+	}
+	return a.Action
+}
+
+// Requires declares the access an operation needs, for use as a huma operation handler:
+//
+//	huma.Delete(grp, "/{id}", svc.DeleteSport, utils.Requires(utils.Permission(models.PermissionDelete, "sport")))
+func Requires(access Access) func(o *huma.Operation) {
+	return func(o *huma.Operation) {
+		if o.Metadata == nil {
+			o.Metadata = map[string]any{}
+		}
+		o.Metadata[accessKey] = access
+	}
+}
+
+// AccessOf returns the access op declared, if it declared any.
+func AccessOf(op *huma.Operation) (Access, bool) {
+	if op == nil {
+		return Access{}, false
+	}
+	access, ok := op.Metadata[accessKey].(Access)
+	return access, ok
+}
+
+// OwnedBy resolves owners by the column of table holding the owner's ID.
+func OwnedBy(table, column string) OwnerFunc {
+	return func(db *gorm.DB, userID, id uuid.UUID) (bool, error) {
+		var count int64
+		err := db.Table(table).
+			Where("id = ? AND "+column+" = ?", id, userID).
+			Count(&count).Error
+		return count > 0, err
+	}
+}
+
+// AuthorOf resolves the author of a row of table as its owner.
+func AuthorOf(table ContentTable) OwnerFunc {
+	return OwnedBy(table.Name, table.AuthorColumn)
+}
+
+// Self treats users as the owners of themselves, for operations on a user.
+func Self(_ *gorm.DB, userID, id uuid.UUID) (bool, error) {
+	return userID == id, nil
+}
+
+// UserKey treats users as the owners of the key stored in their column, such as the Stripe
+// customer a user was given at checkout.
+func UserKey(column string) KeyOwnerFunc {
+	return func(db *gorm.DB, userID uuid.UUID, key string) (bool, error) {
+		var count int64
+		err := db.Table("users").
+			Where("id = ? AND "+column+" = ?", userID, key).
+			Count(&count).Error
+		return count > 0, err
+	}
+}
+
+// KeyUnder treats users as the owners of the keys under the prefix they are given, such as the S3
+// objects they uploaded.
+func KeyUnder(prefix func(userID uuid.UUID) string) KeyOwnerFunc {
+	return func(_ *gorm.DB, userID uuid.UUID, key string) (bool, error) {
+		return strings.HasPrefix(key, prefix(userID)), nil
+	}
+}
+
+// ScopesOfContent resolves the scopes of a row of table, see ContentScopes.
+func ScopesOfContent(table ContentTable) ScopeFunc {
+	return func(db *gorm.DB, id uuid.UUID) ([]models.RoleScope, error) {
+		return ContentScopes(db, table, id)
+	}
+}
+
+// IsScope is for sports and colleges themselves, which are in their own scope.
+func IsScope(scopeType models.RoleScopeType) ScopeFunc {
+	return func(_ *gorm.DB, id uuid.UUID) ([]models.RoleScope, error) {
+		return []models.RoleScope{{Type: scopeType, ID: id}}, nil
+	}
+}
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultPageLimit is used when a list endpoint is called without a positive limit.
+const DefaultPageLimit = 50
+
+// Cursor is a position in a list ordered by (sort key, id). The id breaks ties between rows
+// with the same sort key, so every row has exactly one position. Clients only ever see the
+// encoded token and pass it back as-is.
+type Cursor[K any] struct {
+	Key K         `json:"k"`
+	ID  uuid.UUID `json:"id"`
+	// Before marks a cursor for the page ending just before this position (a prev_cursor).
+	Before bool `json:"b,omitempty"`
+}
+
+// Encode returns the cursor as an opaque, URL safe token.
+func (c Cursor[K]) Encode() string {
+	return EncodeToken(c)
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode. An empty token means no cursor.
+func DecodeCursor[K any](token string) (*Cursor[K], error) {
+	cursor, err := DecodeToken[Cursor[K]](token)
+	if err != nil || cursor == nil {
+		return nil, err
+	}
+	if cursor.ID == uuid.Nil {
+		return nil, huma.Error400BadRequest("Invalid cursor")
+	}
+	return cursor, nil
+}
+
+// EncodeToken returns v as an opaque, URL safe token. Lists whose position is more than a single
+// Cursor (like the feed or the comment tree) use it for their own cursor types.
+func EncodeToken(v any) string {
+	raw, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeToken parses a token produced by EncodeToken. An empty token decodes to nil.
+func DecodeToken[T any](token string) (*T, error) {
+	if token == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, huma.Error400BadRequest("Invalid cursor")
+	}
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, huma.Error400BadRequest("Invalid cursor")
+	}
+	return &v, nil
+}
+
+// Keyset describes the order a list is paged in: by KeyColumn, then IDColumn, both in the same direction.
+type Keyset struct {
+	KeyColumn  string
+	IDColumn   string
+	Descending bool
+}
+
+// Page is one page of a keyset-ordered list. It is reached either with a cursor or, for
+// clients that have not moved over yet, with an offset. Offset paging is deprecated and
+// will be removed in the next release.
+type Page[K any] struct {
+	keyset Keyset
+	cursor *Cursor[K]
+	limit  int
+	offset int
+}
+
+// NewPage builds the page for a list request. A cursor takes precedence over an offset.
+func NewPage[K any](keyset Keyset, cursor string, limit int, offset int) (*Page[K], error) {
+	decoded, err := DecodeCursor[K](cursor)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if decoded != nil || offset < 0 {
+		offset = 0
+	}
+	return &Page[K]{keyset: keyset, cursor: decoded, limit: limit, offset: offset}, nil
+}
+
+// NewPageAt builds the page starting at an already decoded cursor, for lists that keep their
+// cursors inside a token of their own. A nil cursor is the first page.
+func NewPageAt[K any](keyset Keyset, cursor *Cursor[K], limit int) *Page[K] {
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	return &Page[K]{keyset: keyset, cursor: cursor, limit: limit}
+}
+
+// CountTotal reports whether the list's total should be counted for this page. Totals are only
+// returned with the first page (and in offset mode) so deep pages never run a COUNT(*).
+func (p *Page[K]) CountTotal() bool {
+	return p.cursor == nil
+}
+
+func (p *Page[K]) backward() bool {
+	return p.cursor != nil && p.cursor.Before
+}
+
+// Apply orders the query by the keyset, seeks past the cursor and limits it to the page.
+// One extra row is fetched so Results can tell whether there is a next page.
+func (p *Page[K]) Apply(db *gorm.DB) *gorm.DB {
+	key, id := toColumn(p.keyset.KeyColumn), toColumn(p.keyset.IDColumn)
+
+	// a prev_cursor walks the list in reverse, Results flips the rows back
+	desc := p.keyset.Descending != p.backward()
+	if p.cursor != nil {
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		db = db.Where(clause.Expr{SQL: "(?, ?) " + op + " (?, ?)", Vars: []any{key, id, p.cursor.Key, p.cursor.ID}})
+	}
+
+	db = db.Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: key, Desc: desc},
+		{Column: id, Desc: desc},
+	}}).Limit(p.limit + 1)
+	if p.offset > 0 {
+		db = db.Offset(p.offset)
+	}
+	return db
+}
+
+// PageCursors are the tokens for the pages either side of the current one. They are left
+// out of the response when there is no such page.
+type PageCursors struct {
+	NextCursor *string `json:"next_cursor,omitempty" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCJ9" doc:"Pass as cursor to get the next page"`
+	PrevCursor *string `json:"prev_cursor,omitempty" example:"eyJrIjoiMjAyNi0wNS0wMVQxMjowMDowMFoiLCJpZCI6IjEyM2U0NTY3LWU4OWItMTJkMy1hNDU2LTQyNjYxNDE3NDAwMCIsImIiOnRydWV9" doc:"Pass as cursor to get the previous page"`
+}
+
+// Results trims the rows fetched with Apply down to the page, in list order, and returns the
+// cursors for the neighbouring pages. cursorOf gives a row's position in the keyset.
+func Results[M any, K any](p *Page[K], rows []M, cursorOf func(*M) Cursor[K]) ([]M, PageCursors) {
+	hasMore := len(rows) > p.limit
+	if hasMore {
+		rows = rows[:p.limit]
+	}
+	if p.backward() {
+		slices.Reverse(rows)
+	}
+
+	var cursors PageCursors
+	if len(rows) == 0 {
+		return rows, cursors
+	}
+
+	hasNext, hasPrev := hasMore, p.cursor != nil || p.offset > 0
+	if p.backward() {
+		// we came back from a later page, so there is always a next one
+		hasNext, hasPrev = true, hasMore
+	}
+	if hasNext {
+		next := cursorOf(&rows[len(rows)-1])
+		next.Before = false
+		token := next.Encode()
+		cursors.NextCursor = &token
+	}
+	if hasPrev {
+		prev := cursorOf(&rows[0])
+		prev.Before = true
+		token := prev.Encode()
+		cursors.PrevCursor = &token
+	}
+	return rows, cursors
+}
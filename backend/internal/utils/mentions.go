@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// mentionPattern matches @username at the start of text or after anything but a word character,
+// so email addresses aren't taken for mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.]+)`)
+
+// Mentions returns the usernames @-mentioned in texts, each once, in the order they first appear.
+func Mentions(texts ...string) []string {
+	usernames := []string{}
+	for _, text := range texts {
+		for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+			// a mention can end a sentence
+			username := strings.TrimRight(match[1], ".")
+			if username != "" && !slices.Contains(usernames, username) {
+				usernames = append(usernames, username)
+			}
+		}
+	}
+	return usernames
+}
+
+// SetTexts returns the texts that aren't nil, such as the fields a partial update changes.
+func SetTexts(texts ...*string) []string {
+	set := []string{}
+	for _, text := range texts {
+		if text != nil {
+			set = append(set, *text)
+		}
+	}
+	return set
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"inside-athletics/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HasPermission reports whether any of the user's roles grants action on resource. Roles scoped
+// to a sport or college only count when the resource is in one of scopes.
+func HasPermission(db *gorm.DB, userID uuid.UUID, action models.PermissionAction, resource string, scopes ...models.RoleScope) (bool, error) {
+	var count int64
+	err := grantingRoles(db, userID, action, resource).
+		Where(inScopes(scopes)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// PermissionScopes returns whether an unscoped role of the user grants action on resource, and
+// otherwise the scopes their scoped roles grant it in.
+func PermissionScopes(db *gorm.DB, userID uuid.UUID, action models.PermissionAction, resource string) (bool, []models.RoleScope, error) {
+	var roles []models.UserRole
+	if err := grantingRoles(db, userID, action, resource).
+		Select("user_roles.scope_type, user_roles.scope_id").
+		Find(&roles).Error; err != nil {
+		return false, nil, err
+	}
+	var scopes []models.RoleScope
+	for _, role := range roles {
+		scope := role.Scope()
+		if scope == nil {
+			return true, nil, nil
+		}
+		scopes = append(scopes, *scope)
+	}
+	return false, scopes, nil
+}
+
+func grantingRoles(db *gorm.DB, userID uuid.UUID, action models.PermissionAction, resource string) *gorm.DB {
+	return db.Table("user_roles").
+		Joins("JOIN role_permissions rp ON rp.role_id = user_roles.role_id").
+		Joins("JOIN permissions p ON p.id = rp.permission_id").
+		Where("user_roles.user_id = ? AND p.action = ? AND p.resource = ?", userID, action, resource)
+}
+
+func inScopes(scopes []models.RoleScope) clause.Expression {
+	conditions := []clause.Expression{clause.Expr{SQL: "user_roles.scope_id IS NULL"}}
+	for _, scope := range scopes {
+		conditions = append(conditions, clause.Expr{
+			SQL:  "(user_roles.scope_type = ? AND user_roles.scope_id = ?)",
+			Vars: []any{scope.Type, scope.ID},
+		})
+	}
+	return clause.Or(conditions...)
+}
+
+// HasRole reports whether the user holds any of roles without a scope.
+func HasRole(db *gorm.DB, userID uuid.UUID, roles ...models.RoleName) (bool, error) {
+	var count int64
+	err := db.Table("user_roles").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ? AND roles.name IN ? AND user_roles.scope_id IS NULL", userID, roles).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// scopeColumns select the sport and college of a post, premium post or comment row. Comments are
+// in the scope of their post.
+var scopeColumns = map[string]string{
+	PostsTable.Name:        "SELECT sport_id, college_id FROM posts WHERE id = ?",
+	PremiumPostsTable.Name: "SELECT sport_id, college_id FROM premium_posts WHERE id = ?",
+	CommentsTable.Name:     "SELECT p.sport_id, p.college_id FROM comments c JOIN posts p ON p.id = c.post_id WHERE c.id = ?",
+}
+
+// ContentScopes returns the sport and college the row of table with id belongs to, which scoped
+// roles are checked against. Content that doesn't exist or has neither is in no scope.
+func ContentScopes(db *gorm.DB, table ContentTable, id uuid.UUID) ([]models.RoleScope, error) {
+	var row struct {
+		SportID   *uuid.UUID
+		CollegeID *uuid.UUID
+	}
+	if err := db.Raw(scopeColumns[table.Name], id).Scan(&row).Error; err != nil {
+		return nil, err
+	}
+	var scopes []models.RoleScope
+	if row.SportID != nil {
+		scopes = append(scopes, models.RoleScope{Type: models.RoleScopeSport, ID: *row.SportID})
+	}
+	if row.CollegeID != nil {
+		scopes = append(scopes, models.RoleScope{Type: models.RoleScopeCollege, ID: *row.CollegeID})
+	}
+	return scopes, nil
+}
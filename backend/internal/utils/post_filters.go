@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var uuidListPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}(?:,[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})*$`)
+
+// PostFilters narrows a post or premium post query to the given colleges, sports and tags.
+// A post matching any one of them is kept. VerifiedOnly further keeps only posts by verified
+// athletes.
+type PostFilters struct {
+	CollegeIDs   []uuid.UUID
+	SportIDs     []uuid.UUID
+	TagIDs       []uuid.UUID
+	VerifiedOnly bool
+}
+
+// ParsePostFilters parses the comma separated uuid lists taken by the filter and search endpoints.
+func ParsePostFilters(collegeIDs string, sportIDs string, tagIDs string) (PostFilters, error) {
+	var filters PostFilters
+	var err error
+	if filters.CollegeIDs, err = parseUUIDList(collegeIDs, "college"); err != nil {
+		return filters, err
+	}
+	if filters.SportIDs, err = parseUUIDList(sportIDs, "sport"); err != nil {
+		return filters, err
+	}
+	if filters.TagIDs, err = parseUUIDList(tagIDs, "tag"); err != nil {
+		return filters, err
+	}
+	return filters, nil
+}
+
+func parseUUIDList(ids string, name string) ([]uuid.UUID, error) {
+	if ids == "" {
+		return []uuid.UUID{}, nil
+	}
+	if !uuidListPattern.MatchString(ids) {
+		return nil, huma.Error400BadRequest("Expected comma separated list of uuids with no spaces for " + name + " input uuid,uuid")
+	}
+	return MapList(strings.Split(ids, ","), uuid.MustParse), nil
+}
+
+// IsEmpty reports whether there are no colleges, sports or tags to filter by.
+func (f PostFilters) IsEmpty() bool {
+	return len(f.CollegeIDs) == 0 && len(f.SportIDs) == 0 && len(f.TagIDs) == 0
+}
+
+// Apply adds the filters to a query on table ("posts" or "premium_posts"), whose rows are tagged
+// in tag_posts with the given postable type. All ids are bound parameters.
+func (f PostFilters) Apply(db *gorm.DB, table string, postableType string) *gorm.DB {
+	db = VerifiedAuthors(ContentTable{Name: table, AuthorColumn: "author_id"}, f.VerifiedOnly)(db)
+	if f.IsEmpty() {
+		return db
+	}
+
+	conditions := make([]string, 0, 3)
+	vars := make([]any, 0, 6)
+	if len(f.CollegeIDs) > 0 {
+		conditions = append(conditions, "? IN ?")
+		vars = append(vars, clause.Column{Table: table, Name: "college_id"}, f.CollegeIDs)
+	}
+	if len(f.SportIDs) > 0 {
+		conditions = append(conditions, "? IN ?")
+		vars = append(vars, clause.Column{Table: table, Name: "sport_id"}, f.SportIDs)
+	}
+	if len(f.TagIDs) > 0 {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM tag_posts WHERE tag_posts.postable_id = ?
+			AND tag_posts.postable_type = ? AND tag_posts.deleted_at IS NULL AND tag_posts.tag_id IN ?)`)
+		vars = append(vars, clause.Column{Table: table, Name: "id"}, postableType, f.TagIDs)
+	}
+	return db.Where(clause.Expr{SQL: "(" + strings.Join(conditions, " OR ") + ")", Vars: vars})
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+// Principal is the authenticated caller, built from a verified access token by the auth middleware.
+type Principal struct {
+	UserID    uuid.UUID
+	Email     string
+	SessionID string
+	// AuthMethod is how the user signed in (e.g. "password", "oauth"), from the token's amr claim.
+	AuthMethod string
+	ExpiresAt  time.Time
+}
+
+// principalKey is the only context key the principal is stored under.
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the given principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored by the auth middleware, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// GetCurrentPrincipal returns the authenticated principal or a 401 if there is none.
+func GetCurrentPrincipal(ctx context.Context) (*Principal, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("User not authenticated")
+	}
+	return principal, nil
+}
@@ -1,62 +1,229 @@
 package utils
 
 import (
-	"fmt"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func FuzzySearchByQueries(colName string, searchStr string) (string, string, string) {
-	// Create query strings for Select, Where, and Order By arguments
-	selectQuery := fmt.Sprintf(
-		"word_similarity('%s', %s) as similarity",
-		searchStr,
-		colName,
-	)
-	whereQuery := fmt.Sprintf(
-		"word_similarity('%s', %s) >= show_limit()",
-		searchStr,
-		colName,
-	)
+// DefaultSimilarityThreshold matches pg_trgm's default similarity_threshold (what show_limit() returns).
+const DefaultSimilarityThreshold = 0.3
 
-	return selectQuery, whereQuery, "similarity DESC"
+// DefaultSearchLimit is how many results a FuzzySearch returns unless Paginate says otherwise.
+const DefaultSearchLimit = 20
+
+// SearchColumn is a column matched against the search string. Its similarity is scaled by Weight,
+// so a weaker field (e.g. a college's city) can still match without outranking a strong one (its name).
+type SearchColumn struct {
+	Name   string
+	Weight float64
 }
 
-func FuzzySearchForDB[ModelType any](searchStr string, limit int, db *gorm.DB, colName string, t ModelType) ([]ModelType, error) {
-	var searchResults []ModelType
+// Column returns a SearchColumn with a weight of 1.
+func Column(name string) SearchColumn {
+	return SearchColumn{Name: name, Weight: 1}
+}
+
+// WeightedColumn returns a SearchColumn with the given weight.
+func WeightedColumn(name string, weight float64) SearchColumn {
+	return SearchColumn{Name: name, Weight: weight}
+}
+
+// FuzzySearch builds a trigram (pg_trgm word_similarity) search over one or more weighted columns.
+// The search string is always passed as a bound parameter and column names are quoted by GORM,
+// so user input never ends up in the SQL text.
+type FuzzySearch struct {
+	query     string
+	columns   []SearchColumn
+	threshold float64
+	limit     int
+	offset    int
+}
+
+func NewFuzzySearch(query string, columns ...SearchColumn) *FuzzySearch {
+	return &FuzzySearch{
+		query:     query,
+		columns:   columns,
+		threshold: DefaultSimilarityThreshold,
+		limit:     DefaultSearchLimit,
+	}
+}
+
+// WithThreshold sets the minimum weighted similarity (0-1) a row needs to match.
+// A threshold of 0 matches every row; negative values keep the current threshold.
+func (f *FuzzySearch) WithThreshold(threshold float64) *FuzzySearch {
+	if threshold >= 0 {
+		f.threshold = threshold
+	}
+	return f
+}
+
+// Paginate sets the page returned by Apply. A limit of 0 or less keeps the current limit,
+// so a search is never unbounded.
+func (f *FuzzySearch) Paginate(limit int, offset int) *FuzzySearch {
+	if limit > 0 {
+		f.limit = limit
+	}
+	f.offset = offset
+	return f
+}
+
+// Score is the expression for a row's similarity: the best weighted similarity across all columns.
+func (f *FuzzySearch) Score() clause.Expr {
+	terms := make([]string, 0, len(f.columns))
+	vars := make([]any, 0, len(f.columns)*3)
+	for _, col := range f.columns {
+		weight := col.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		terms = append(terms, "? * word_similarity(?, ?)")
+		vars = append(vars, weight, f.query, toColumn(col.Name))
+	}
+	if len(terms) == 1 {
+		return clause.Expr{SQL: terms[0], Vars: vars}
+	}
+	return clause.Expr{SQL: "GREATEST(" + strings.Join(terms, ", ") + ")", Vars: vars}
+}
+
+// Matches is the condition for a row's score reaching the threshold.
+func (f *FuzzySearch) Matches() clause.Expr {
+	return clause.Expr{SQL: "? >= ?", Vars: []any{f.Score(), f.threshold}}
+}
+
+// Where filters the query down to rows whose score reaches the threshold.
+func (f *FuzzySearch) Where(db *gorm.DB) *gorm.DB {
+	return db.Where(f.Matches())
+}
+
+// Apply filters, orders by score (best first) and paginates the query.
+func (f *FuzzySearch) Apply(db *gorm.DB) *gorm.DB {
+	db = f.Where(db).Order(clause.OrderBy{Expression: clause.Expr{SQL: "? DESC", Vars: []any{f.Score()}}}).Limit(f.limit)
+	if f.offset > 0 {
+		db = db.Offset(f.offset)
+	}
+	return db
+}
+
+// Count returns how many rows match the search, ignoring pagination.
+func (f *FuzzySearch) Count(db *gorm.DB) (int64, error) {
+	var total int64
+	err := f.Where(db).Count(&total).Error
+	return total, err
+}
+
+// TextSearchConfig is the Postgres text search configuration used to build and query search vectors.
+const TextSearchConfig = "english"
+
+// Default ts_headline options: short fragments around the matches, wrapped in <mark> tags.
+const (
+	SnippetHeadlineOptions   = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2"
+	HighlightHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+)
+
+// TextSearch builds a Postgres full-text search against a tsvector column. The query uses
+// websearch syntax ("quoted phrases", or, -excluded) and is always passed as a bound parameter.
+type TextSearch struct {
+	query  string
+	vector clause.Column
+}
+
+func NewTextSearch(query string, vectorColumn string) *TextSearch {
+	return &TextSearch{query: query, vector: toColumn(vectorColumn)}
+}
+
+// TSQuery is the parsed tsquery for the search string.
+func (s *TextSearch) TSQuery() clause.Expr {
+	return clause.Expr{SQL: "websearch_to_tsquery('" + TextSearchConfig + "', ?)", Vars: []any{s.query}}
+}
+
+// Matches is the condition for a row's search vector matching the query.
+func (s *TextSearch) Matches() clause.Expr {
+	return clause.Expr{SQL: "? @@ ?", Vars: []any{s.vector, s.TSQuery()}}
+}
+
+// Rank scores how well a row matches the query (ts_rank), higher is better.
+func (s *TextSearch) Rank() clause.Expr {
+	return clause.Expr{SQL: "ts_rank(?, ?)", Vars: []any{s.vector, s.TSQuery()}}
+}
 
-	selectQuery, whereQuery, orderQuery := FuzzySearchByQueries(colName, searchStr)
+// Headline returns the given column with matches highlighted (ts_headline). The column's text is
+// HTML escaped first, so the only markup in the result is the highlight and it's safe to render.
+func (s *TextSearch) Headline(column string, options string) clause.Expr {
+	return clause.Expr{
+		SQL:  "ts_headline('" + TextSearchConfig + "', ?, ?, ?)",
+		Vars: []any{htmlEscape(toColumn(column)), s.TSQuery(), options},
+	}
+}
+
+// htmlEscape escapes column's text the way html.EscapeString does.
+func htmlEscape(column clause.Column) clause.Expr {
+	return clause.Expr{
+		SQL:  `replace(replace(replace(replace(replace(?, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;')`,
+		Vars: []any{column},
+	}
+}
+
+// toColumn turns "table.column" or "column" into a clause.Column so it gets quoted.
+func toColumn(name string) clause.Column {
+	if table, col, ok := strings.Cut(name, "."); ok {
+		return clause.Column{Table: table, Name: col}
+	}
+	return clause.Column{Name: name}
+}
 
-	if err := db.Select("*", selectQuery).Where(whereQuery).Order(orderQuery).Limit(limit).Model(&t).Scan(&searchResults).Error; err != nil {
-		return []ModelType{}, err
+// FuzzySearchForDB runs the search against the model's table and returns one page plus the total match count.
+func FuzzySearchForDB[ModelType any](db *gorm.DB, search *FuzzySearch) ([]ModelType, int64, error) {
+	var model ModelType
+	total, err := search.Count(db.Model(&model))
+	if err != nil {
+		return []ModelType{}, 0, err
+	}
+	if total == 0 {
+		return []ModelType{}, 0, nil
 	}
 
-	return searchResults, nil
+	var searchResults []ModelType
+	if err := search.Apply(db.Model(&model)).Find(&searchResults).Error; err != nil {
+		return []ModelType{}, 0, err
+	}
+	return searchResults, total, nil
 }
 
-func FuzzySearchService[ModelType any, RespType any](input *SearchParam, modelType ModelType, respType RespType, colName string, db *gorm.DB, toResp func(*ModelType) *RespType) (*ResponseBody[SearchResults[*RespType]], error) {
-	searchResults, err := FuzzySearchForDB(input.SearchStr, input.Limit, db, "name", modelType)
+// FuzzySearchService runs the given DB search and maps the results into the response type.
+func FuzzySearchService[ModelType any, RespType any](input *SearchParam, search func(*SearchParam) ([]ModelType, int64, error), toResp func(*ModelType) *RespType) (*ResponseBody[SearchResults[*RespType]], error) {
+	searchResults, total, err := search(input)
 	respBody := ResponseBody[SearchResults[*RespType]]{}
 	if err != nil {
 		return HandleDBError(&respBody, err)
 	}
 
 	// parse search results into response type
-	searchResponses := make([]*RespType, 0)
-	for _, m := range searchResults {
-		searchResponses = append(searchResponses, toResp(&m))
+	searchResponses := make([]*RespType, 0, len(searchResults))
+	for i := range searchResults {
+		searchResponses = append(searchResponses, toResp(&searchResults[i]))
 	}
 	respBody.Body = &SearchResults[*RespType]{
 		Results: searchResponses,
+		Total:   total,
 	}
 	return &respBody, nil
 }
 
 type SearchParam struct {
-	SearchStr string `query:"search_str" binding:"required" example:"Northeastern University" docs:"Search string to find colleges by"`
-	Limit     int    `query:"limit" default:"20" example:"20" docs:"Max number of search results to return"`
+	SearchStr string  `query:"search_str" binding:"required" example:"Northeastern University" doc:"Search string to find results by"`
+	Limit     int     `query:"limit" default:"20" minimum:"1" maximum:"100" example:"20" doc:"Max number of search results to return"`
+	Offset    int     `query:"offset" default:"0" example:"0" doc:"Number of results to skip for pagination"`
+	Threshold float64 `query:"threshold" default:"0.3" minimum:"0" maximum:"1" example:"0.3" doc:"Minimum similarity (0-1) a result must reach"`
+}
+
+// Search builds a FuzzySearch for these params over the given columns.
+func (p *SearchParam) Search(columns ...SearchColumn) *FuzzySearch {
+	return NewFuzzySearch(p.SearchStr, columns...).WithThreshold(p.Threshold).Paginate(p.Limit, p.Offset)
 }
 
 type SearchResults[T any] struct {
-	Results []T `json:"results" docs:"List of search results"`
+	Results []T   `json:"results" doc:"List of search results"`
+	Total   int64 `json:"total" example:"42" doc:"Total number of results matching the search"`
 }
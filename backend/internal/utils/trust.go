@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"inside-athletics/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuthorTrust is what a reader can go on to judge content by its author: whether they're a
+// verified athlete, the team they were verified for, and whether that's the team the content is
+// about. Only users verified by an admin count.
+type AuthorTrust struct {
+	IsVerifiedAthlete bool             `json:"is_verified_athlete" doc:"If the author is a verified athlete"`
+	CollegeID         *uuid.UUID       `json:"college_id,omitempty" doc:"College the author was verified at; omitted for anonymous content"`
+	SportID           *uuid.UUID       `json:"sport_id,omitempty" doc:"Sport the author was verified for; omitted for anonymous content"`
+	Division          *models.Division `json:"division,omitempty" doc:"Division the author plays in; omitted for anonymous content"`
+	MatchesCollege    bool             `json:"matches_college" doc:"If the author is verified at the college the content is about. Always false for anonymous content"`
+	MatchesSport      bool             `json:"matches_sport" doc:"If the author is verified for the sport the content is about. Always false for anonymous content"`
+}
+
+// TrustOf describes author as the author of content about collegeID and sportID, either of which
+// may be nil. When hideIdentity is set, as for anonymous content read by someone else, only
+// whether the author is verified is given, so their team can't be used to pick them out.
+func TrustOf(author *models.User, collegeID, sportID *uuid.UUID, hideIdentity bool) *AuthorTrust {
+	trust := &AuthorTrust{IsVerifiedAthlete: author.Verified_Athlete_Status == models.VerifiedAthleteStatusVerified}
+	if !trust.IsVerifiedAthlete || hideIdentity {
+		return trust
+	}
+	trust.CollegeID, trust.SportID, trust.Division = author.CollegeID, author.SportID, author.Division
+	trust.MatchesCollege = sameID(author.CollegeID, collegeID)
+	trust.MatchesSport = sameID(author.SportID, sportID)
+	return trust
+}
+
+func sameID(a, b *uuid.UUID) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+// VerifiedAuthors is a scope that, when only is set, keeps only rows of table written by verified
+// athletes. Anonymous rows are kept if their author is verified, without saying who they are.
+func VerifiedAuthors(table ContentTable, only bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !only {
+			return db
+		}
+		return db.Where(table.VerifiedAuthor())
+	}
+}
+
+// VerifiedAuthor is the condition VerifiedAuthors adds, for raw queries.
+func (t ContentTable) VerifiedAuthor() clause.Expr {
+	return gorm.Expr("EXISTS (SELECT 1 FROM users va WHERE va.id = "+t.Name+"."+t.AuthorColumn+" AND va.verified_athlete_status = ?)",
+		models.VerifiedAthleteStatusVerified)
+}
@@ -3,25 +3,14 @@ package utils
 import (
 	"context"
 
-	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
 )
 
+// GetCurrentUserID returns the authenticated user's ID or a 401 if there is none.
 func GetCurrentUserID(ctx context.Context) (uuid.UUID, error) {
-	rawID := ctx.Value("user_id")
-	if rawID == nil {
-		return uuid.Nil, huma.Error401Unauthorized("User not authenticated")
-	}
-
-	userID, ok := rawID.(string)
-	if !ok {
-		return uuid.Nil, huma.Error500InternalServerError("Invalid user ID in context")
-	}
-
-	parsedID, err := uuid.Parse(userID)
+	principal, err := GetCurrentPrincipal(ctx)
 	if err != nil {
-		return uuid.Nil, huma.Error400BadRequest("Invalid user ID", err)
+		return uuid.Nil, err
 	}
-
-	return parsedID, nil
+	return principal.UserID, nil
 }
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"inside-athletics/internal/models"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ContentTable is a table of user content and the column holding each row's author.
+type ContentTable struct {
+	Name         string
+	AuthorColumn string
+}
+
+var (
+	PostsTable        = ContentTable{Name: "posts", AuthorColumn: "author_id"}
+	PremiumPostsTable = ContentTable{Name: "premium_posts", AuthorColumn: "author_id"}
+	CommentsTable     = ContentTable{Name: "comments", AuthorColumn: "user_id"}
+)
+
+// NotHidden is a scope that leaves out rows of table (posts, premium_posts or comments) that are
+// hidden while their reports wait for a moderator.
+func NotHidden(table string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(table + ".hidden_at IS NULL")
+	}
+}
+
+// VisibleTo is a scope that leaves out rows of table that viewerID shouldn't see: rows hidden
+// pending review, rows by authors the viewer muted or blocked, and rows by shadow-banned authors
+// other than the viewer. Every query that lists or fetches content for users should apply it; the
+// moderation queue is the only place hidden content is shown.
+func VisibleTo(table ContentTable, viewerID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return NotHidden(table.Name)(db).Where(table.Visible(viewerID))
+	}
+}
+
+// Visible is the condition VisibleTo adds beyond NotHidden, for raw queries.
+func (t ContentTable) Visible(viewerID uuid.UUID) clause.Expr {
+	author := t.Name + "." + t.AuthorColumn
+	return gorm.Expr("("+author+" = ? OR NOT (? OR ?))", viewerID, ShadowBanned(author), MutedBy(viewerID, author))
+}
+
+// MutedBy is whether userID muted or blocked the user in column. A block mutes too.
+func MutedBy(userID uuid.UUID, column string) clause.Expr {
+	return gorm.Expr("EXISTS (SELECT 1 FROM user_blocks ub WHERE ub.user_id = ? AND ub.target_id = "+column+")", userID)
+}
+
+// HasMuted reports whether userID muted or blocked targetID, so shouldn't hear about their activity.
+func HasMuted(db *gorm.DB, userID, targetID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Table("user_blocks").
+		Where("user_id = ? AND target_id = ?", userID, targetID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// BlockedByAuthor reports whether the author of the row of table with id blocked userID, in which
+// case userID can't reply to or like it.
+func BlockedByAuthor(db *gorm.DB, table ContentTable, id, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Table("user_blocks ub").
+		Joins("JOIN "+table.Name+" ON "+table.Name+"."+table.AuthorColumn+" = ub.user_id").
+		Where(table.Name+".id = ? AND ub.target_id = ? AND ub.type = ?", id, userID, models.UserBlockBlock).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// BlockedByAnyOf reports whether any of the users with usernames blocked userID, in which case
+// userID can't mention them.
+func BlockedByAnyOf(db *gorm.DB, usernames []string, userID uuid.UUID) (bool, error) {
+	if len(usernames) == 0 {
+		return false, nil
+	}
+	var count int64
+	err := db.Table("user_blocks ub").
+		Joins("JOIN users u ON u.id = ub.user_id").
+		Where("u.username IN ? AND ub.target_id = ? AND ub.type = ?", usernames, userID, models.UserBlockBlock).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// BlockedMentionMessage is the error users get when they mention someone who blocked them.
+const BlockedMentionMessage = "You can't mention a user who blocked you"
+
+// CheckMentions returns a 403 when a user mentioned in texts blocked userID.
+func CheckMentions(db *gorm.DB, userID uuid.UUID, texts ...string) error {
+	blocked, err := BlockedByAnyOf(db, Mentions(texts...), userID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return huma.Error403Forbidden(BlockedMentionMessage)
+	}
+	return nil
+}
+
+// SanctionInForce is the condition for the sanctions row aliased s to be in force now.
+const SanctionInForce = "s.lifted_at IS NULL AND (s.expires_at IS NULL OR s.expires_at > NOW())"
+
+// ShadowBanned is whether the user in column (such as "posts.author_id") is shadow-banned.
+func ShadowBanned(column string) clause.Expr {
+	return gorm.Expr(`EXISTS (
+		SELECT 1 FROM sanctions s
+		WHERE s.user_id = `+column+` AND s.type = ? AND `+SanctionInForce+`
+	)`, models.SanctionShadowBan)
+}
+
+// IsShadowBanned reports whether the user is shadow-banned. Their activity shouldn't reach anyone
+// else, so notifications and emails about it are skipped.
+func IsShadowBanned(db *gorm.DB, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Table("sanctions AS s").
+		Where("s.user_id = ? AND s.type = ? AND "+SanctionInForce, userID, models.SanctionShadowBan).
+		Count(&count).Error
+	return count > 0, err
+}
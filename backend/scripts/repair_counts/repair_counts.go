@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"inside-athletics/internal/counters"
+	"log"
+	"os"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Recalculates the like/comment/reply counters from their source tables and reports every row that
+// has drifted. Nothing is written unless -fix is passed.
+func main() {
+	dbURL := flag.String("db", os.Getenv("DEV_DB_CONNECTION_STRING"), "Database connection string (defaults to DEV_DB_CONNECTION_STRING)")
+	fix := flag.Bool("fix", false, "Reset drifted counters to their actual values")
+	flag.Parse()
+
+	if *dbURL == "" {
+		log.Fatal("ERROR: Database connection string is required. Set DEV_DB_CONNECTION_STRING or use -db flag")
+	}
+
+	db, err := gorm.Open(postgres.Open(*dbURL), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		log.Fatalf("ERROR: Failed to connect to database: %v", err)
+	}
+
+	ctx := context.Background()
+	var drift []counters.Drift
+	if *fix {
+		drift, err = counters.Repair(ctx, db)
+	} else {
+		drift, err = counters.Check(ctx, db)
+	}
+	if err != nil {
+		log.Fatalf("ERROR: Failed to check counters: %v", err)
+	}
+
+	for _, d := range drift {
+		log.Printf("DRIFT: %s on %s: stored %d, actual %d", d.Counter, d.ID, d.Stored, d.Actual)
+	}
+	switch {
+	case len(drift) == 0:
+		log.Println("✓ All counters match their source tables")
+	case *fix:
+		log.Printf("✓ Repaired %d drifted counters", len(drift))
+	default:
+		log.Printf("Found %d drifted counters, run with -fix to repair them", len(drift))
+		os.Exit(1)
+	}
+}